@@ -21,8 +21,29 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/m3db/m3cluster/placement"
+	"github.com/m3db/m3cluster/services"
+	"github.com/m3db/m3db/src/coordinator/api/v1/handler/influxdb"
+	promremote "github.com/m3db/m3db/src/coordinator/api/v1/handler/prometheus/remote"
+	"github.com/m3db/m3db/src/coordinator/cost"
+	"github.com/m3db/m3db/src/coordinator/downsample"
+	"github.com/m3db/m3db/src/coordinator/executor/cache"
+	"github.com/m3db/m3db/src/coordinator/parser/promql"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/storage/fanout"
+	"github.com/m3db/m3db/src/coordinator/storage/ingest"
+	"github.com/m3db/m3db/src/coordinator/tsdb/remote"
 	"github.com/m3db/m3db/src/dbnode/client"
+	"github.com/m3db/m3metrics/policy"
 	"github.com/m3db/m3x/instrument"
+	xtime "github.com/m3db/m3x/time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // Configuration is the configuration for the coordinator.
@@ -41,8 +62,557 @@ type Configuration struct {
 
 	// DBNamespace is the namespace string to use for reads and writes
 	DBNamespace string `yaml:"dbNamespace"`
+
+	// Namespaces is the M3DB namespaces to read from, each holding data at a
+	// different resolution and retention. If set DBNamespace is ignored.
+	Namespaces []NamespaceConfiguration `yaml:"namespaces"`
+
+	// LookbackDuration is how far back to look for a datapoint when
+	// consolidating series into fixed steps.
+	LookbackDuration *time.Duration `yaml:"lookbackDuration"`
+
+	// Limits is the configuration of the query resource limits.
+	Limits LimitsConfiguration `yaml:"limits"`
+
+	// RequireExhaustive fails queries which would otherwise return partial
+	// results, such as when the series limit is reached or a remote store
+	// fails, rather than returning them with warnings.
+	RequireExhaustive bool `yaml:"requireExhaustive"`
+
+	// Downsample is the configuration of the downsampling of written series
+	// into the aggregated namespaces.
+	Downsample *DownsampleConfiguration `yaml:"downsample"`
+
+	// Fanout is the configuration of reads across the local and remote stores.
+	Fanout FanoutConfiguration `yaml:"fanout"`
+
+	// Carbon is the configuration of the ingestion of Graphite metrics with
+	// the carbon protocols.
+	Carbon *CarbonConfiguration `yaml:"carbon"`
+
+	// RemoteWrite is the configuration of the Prometheus remote write
+	// endpoint.
+	RemoteWrite RemoteWriteConfiguration `yaml:"remoteWrite"`
+
+	// InfluxDBWrite is the configuration of the InfluxDB line protocol write
+	// endpoint.
+	InfluxDBWrite InfluxDBWriteConfiguration `yaml:"influxdbWrite"`
+
+	// Ingest is the configuration of the validation and rewriting of the
+	// tags of written series.
+	Ingest *IngestConfiguration `yaml:"ingest"`
+
+	// Cache is the configuration of the cache of range query results.
+	Cache *CacheConfiguration `yaml:"cache"`
+
+	// Rules is the configuration of the evaluation of Prometheus recording
+	// and alerting rules.
+	Rules *RulesConfiguration `yaml:"rules"`
+}
+
+const (
+	defaultCacheMaxBytes = 256 << 20
+
+	defaultRulesEvaluationInterval = time.Minute
+)
+
+// CacheConfiguration is the configuration of the cache of range query
+// results, which holds the results of intervals which can no longer change.
+type CacheConfiguration struct {
+	// MaxBytes is the size of the cached results, estimated for results held
+	// in memory and of their files for results held on disk.
+	MaxBytes int64 `yaml:"maxBytes" validate:"min=0"`
+
+	// Path is the directory to hold the cached results in, they are held in
+	// memory if empty.
+	Path string `yaml:"path"`
+
+	// IntervalSteps is the number of steps of the intervals results are
+	// cached for.
+	IntervalSteps int `yaml:"intervalSteps" validate:"min=0"`
+
+	// BufferPast is the duration after which the datapoints of a time can no
+	// longer be written, for the namespaces without their own buffer past.
+	BufferPast time.Duration `yaml:"bufferPast" validate:"min=0"`
+}
+
+// MaxBytesOrDefault returns the size of the cached results or its default.
+func (c CacheConfiguration) MaxBytesOrDefault() int64 {
+	if c.MaxBytes > 0 {
+		return c.MaxBytes
+	}
+
+	return defaultCacheMaxBytes
+}
+
+// Options returns the options of the cache.
+func (c CacheConfiguration) Options() cache.Options {
+	return cache.Options{
+		IntervalSteps: c.IntervalSteps,
+		BufferPast:    c.BufferPast,
+	}
+}
+
+// RulesConfiguration is the configuration of the evaluation of Prometheus
+// recording and alerting rules.
+type RulesConfiguration struct {
+	// Files are the paths of the Prometheus rule files, which may contain
+	// globs.
+	Files []string `yaml:"files" validate:"nonzero"`
+
+	// EvaluationInterval is the interval of the groups without one. Defaults
+	// to a minute.
+	EvaluationInterval time.Duration `yaml:"evaluationInterval" validate:"min=0"`
+
+	// Alertmanager is the configuration of the webhook alerts are sent to,
+	// alerts are not sent if unset.
+	Alertmanager *AlertmanagerConfiguration `yaml:"alertmanager"`
+
+	// Election has the coordinators elect a leader for each group through
+	// the cluster services, which alone evaluates the group. Every
+	// coordinator evaluates every group if unset.
+	Election *RulesElectionConfiguration `yaml:"election"`
+}
+
+// EvaluationIntervalOrDefault returns the interval of the groups without one.
+func (c RulesConfiguration) EvaluationIntervalOrDefault() time.Duration {
+	if c.EvaluationInterval > 0 {
+		return c.EvaluationInterval
+	}
+
+	return defaultRulesEvaluationInterval
+}
+
+// AlertmanagerConfiguration is the configuration of an Alertmanager
+// compatible webhook.
+type AlertmanagerConfiguration struct {
+	// URL is the URL alerts are posted to, such as the /api/v1/alerts
+	// endpoint of Alertmanager.
+	URL string `yaml:"url" validate:"nonzero"`
+
+	// Timeout is the timeout of requests to the webhook.
+	Timeout time.Duration `yaml:"timeout" validate:"min=0"`
+}
+
+// RulesElectionConfiguration is the configuration of the election of the
+// coordinator evaluating each rule group.
+type RulesElectionConfiguration struct {
+	// Service is the name of the service the groups are elected within,
+	// shared by the coordinators evaluating the same rules.
+	Service string `yaml:"service" validate:"nonzero"`
+
+	// Environment is the environment of the service.
+	Environment string `yaml:"environment" validate:"nonzero"`
+
+	// Zone is the zone of the cluster services the service is registered with.
+	Zone string `yaml:"zone" validate:"nonzero"`
+
+	// LeaderValue identifies this coordinator as the leader of groups.
+	// Defaults to the hostname.
+	LeaderValue string `yaml:"leaderValue"`
+
+	// TTLSeconds is how long a coordinator which stopped responding remains
+	// the leader of its groups.
+	TTLSeconds int `yaml:"ttlSeconds" validate:"min=0"`
+}
+
+// ServiceID returns the ID of the service the groups are elected within.
+func (c RulesElectionConfiguration) ServiceID() services.ServiceID {
+	return services.NewServiceID().
+		SetName(c.Service).
+		SetEnvironment(c.Environment).
+		SetZone(c.Zone)
+}
+
+// ElectionOptions returns the options of the elections of groups.
+func (c RulesElectionConfiguration) ElectionOptions() services.ElectionOptions {
+	opts := services.NewElectionOptions()
+	if c.TTLSeconds > 0 {
+		opts = opts.SetTTLSecs(c.TTLSeconds)
+	}
+
+	return opts
+}
+
+// CampaignOptions returns the options of the campaigns of this coordinator.
+func (c RulesElectionConfiguration) CampaignOptions() (services.CampaignOptions, error) {
+	opts, err := services.NewCampaignOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.LeaderValue != "" {
+		opts = opts.SetLeaderValue(c.LeaderValue)
+	}
+
+	return opts, nil
+}
+
+// IngestConfiguration is the configuration of the validation and rewriting
+// of the tags of written series, a zero value for any limit disables it.
+type IngestConfiguration struct {
+	// MaxTags is the maximum number of tags of a series.
+	MaxTags int `yaml:"maxTags" validate:"min=0"`
+
+	// MaxTagNameLength is the maximum length in bytes of tag names.
+	MaxTagNameLength int `yaml:"maxTagNameLength" validate:"min=0"`
+
+	// MaxTagValueLength is the maximum length in bytes of tag values.
+	MaxTagValueLength int `yaml:"maxTagValueLength" validate:"min=0"`
+
+	// TruncateTags truncates tag names and values over their maximum length
+	// rather than rejecting the series.
+	TruncateTags bool `yaml:"truncateTags"`
+
+	// NormalizeTagNames replaces the characters of tag names which are not
+	// valid in Prometheus label names with underscores.
+	NormalizeTagNames bool `yaml:"normalizeTagNames"`
+
+	// Rules are the rules applied in order to the tags of written series,
+	// before the limits are enforced.
+	Rules []IngestRuleConfiguration `yaml:"rules"`
+}
+
+// IngestRuleConfiguration is the configuration of a rule rewriting or
+// dropping the series matching its filter.
+type IngestRuleConfiguration struct {
+	// Name identifies the rule in the metrics of its hits.
+	Name string `yaml:"name"`
+
+	// Filter is a series selector such as {job="api"}, matching the series
+	// the rule applies to.
+	Filter string `yaml:"filter" validate:"nonzero"`
+
+	// Action is one of drop, drop_tags, rename_tags or add_tags.
+	Action string `yaml:"action" validate:"nonzero"`
+
+	// Tags are the names of the tags dropped by drop_tags.
+	Tags []string `yaml:"tags"`
+
+	// Rename maps the names of the tags renamed by rename_tags to their new
+	// names.
+	Rename map[string]string `yaml:"rename"`
+
+	// Add is the tags set by add_tags.
+	Add map[string]string `yaml:"add"`
+}
+
+// Options returns the ingest processor options described by the configuration.
+func (c IngestConfiguration) Options() (ingest.Options, error) {
+	rules := make([]ingest.Rule, 0, len(c.Rules))
+	for _, ruleCfg := range c.Rules {
+		filter, err := promql.ParseMatchers(ruleCfg.Filter)
+		if err != nil {
+			return ingest.Options{}, err
+		}
+
+		action, err := ingest.ParseAction(ruleCfg.Action)
+		if err != nil {
+			return ingest.Options{}, err
+		}
+
+		var tags map[string]string
+		switch action {
+		case ingest.ActionDropTags:
+			tags = make(map[string]string, len(ruleCfg.Tags))
+			for _, name := range ruleCfg.Tags {
+				tags[name] = ""
+			}
+		case ingest.ActionRenameTags:
+			tags = ruleCfg.Rename
+		case ingest.ActionAddTags:
+			tags = ruleCfg.Add
+		}
+
+		rules = append(rules, ingest.Rule{
+			Name:   ruleCfg.Name,
+			Filter: filter,
+			Action: action,
+			Tags:   tags,
+		})
+	}
+
+	return ingest.Options{
+		Rules: rules,
+		Limits: ingest.Limits{
+			MaxTags:        c.MaxTags,
+			MaxNameLength:  c.MaxTagNameLength,
+			MaxValueLength: c.MaxTagValueLength,
+			Truncate:       c.TruncateTags,
+		},
+		NormalizeNames: c.NormalizeTagNames,
+	}, nil
+}
+
+// RemoteWriteConfiguration is the configuration of the limits of the
+// Prometheus remote write endpoint, limits which are zero take their default
+// value and those which are negative are disabled.
+type RemoteWriteConfiguration struct {
+	// MaxRequestBytes is the maximum size of a decompressed write request,
+	// larger requests are rejected.
+	MaxRequestBytes int `yaml:"maxRequestBytes"`
+
+	// MaxOutstandingWrites is the maximum number of datapoints being written
+	// to M3DB at once across all requests.
+	MaxOutstandingWrites int `yaml:"maxOutstandingWrites"`
+
+	// MaxConcurrentSeries is the maximum number of series of a request being
+	// written to M3DB at once.
+	MaxConcurrentSeries int `yaml:"maxConcurrentSeries"`
+
+	// MaxInFlightBytes is the maximum total size of the decompressed write
+	// requests being handled at once.
+	MaxInFlightBytes int64 `yaml:"maxInFlightBytes"`
+
+	// QueueTimeout is how long writes wait for the limits to be freed by
+	// other writes before being rejected with a 429.
+	QueueTimeout time.Duration `yaml:"queueTimeout"`
+
+	// RetryAfter is how long rejected clients are asked to wait before
+	// retrying.
+	RetryAfter time.Duration `yaml:"retryAfter"`
+}
+
+// Options returns the write endpoint options described by the configuration.
+func (c RemoteWriteConfiguration) Options() promremote.PromWriteOptions {
+	opts := promremote.NewPromWriteOptions()
+	if c.MaxRequestBytes != 0 {
+		opts.MaxRequestBytes = c.MaxRequestBytes
+	}
+
+	if c.MaxOutstandingWrites != 0 {
+		opts.MaxOutstandingWrites = c.MaxOutstandingWrites
+	}
+
+	if c.MaxConcurrentSeries != 0 {
+		opts.MaxConcurrentSeries = c.MaxConcurrentSeries
+	}
+
+	if c.MaxInFlightBytes != 0 {
+		opts.MaxInFlightBytes = c.MaxInFlightBytes
+	}
+
+	if c.QueueTimeout != 0 {
+		opts.QueueTimeout = c.QueueTimeout
+	}
+
+	if c.RetryAfter != 0 {
+		opts.RetryAfter = c.RetryAfter
+	}
+
+	return opts
+}
+
+// InfluxDBWriteConfiguration is the configuration of the limits of the
+// InfluxDB write endpoint, limits which are zero take their default value
+// and those which are negative are disabled.
+type InfluxDBWriteConfiguration struct {
+	// MaxRequestBytes is the maximum size of a decompressed write request,
+	// larger requests are rejected.
+	MaxRequestBytes int `yaml:"maxRequestBytes"`
+}
+
+// Options returns the write endpoint options described by the configuration.
+func (c InfluxDBWriteConfiguration) Options() influxdb.InfluxWriteOptions {
+	opts := influxdb.NewInfluxWriteOptions()
+	if c.MaxRequestBytes != 0 {
+		opts.MaxRequestBytes = c.MaxRequestBytes
+	}
+
+	return opts
 }
 
+// CarbonConfiguration is the configuration of the carbon listeners, which
+// write Graphite metrics with their path nodes mapped to __g0__, __g1__...
+// tags. At least one of the listen addresses must be set.
+type CarbonConfiguration struct {
+	// ListenAddress is the address of the plaintext protocol listeners,
+	// accepting both TCP and UDP.
+	ListenAddress string `yaml:"listenAddress"`
+
+	// PickleListenAddress is the address of the pickle protocol listeners,
+	// accepting both TCP and UDP.
+	PickleListenAddress string `yaml:"pickleListenAddress"`
+
+	// ReadTimeout closes connections sending nothing for longer.
+	ReadTimeout time.Duration `yaml:"readTimeout"`
+}
+
+// FanoutConfiguration is the configuration of reads across stores.
+type FanoutConfiguration struct {
+	// ConflictResolution decides the datapoints returned for series which
+	// several stores return, one of prefer_local, prefer_highest_resolution,
+	// union or disjoint. Defaults to prefer_local. Disjoint asserts that each
+	// series is held by a single store, and is required by RPC pushdown.
+	ConflictResolution string `yaml:"conflictResolution"`
+
+	// LocalTimeout is the timeout of requests to the local store.
+	LocalTimeout time.Duration `yaml:"localTimeout"`
+
+	// RemoteTimeout is the timeout of requests to remote stores.
+	RemoteTimeout time.Duration `yaml:"remoteTimeout"`
+
+	// AllowPartialResults returns results with warnings when a remote store
+	// fails rather than failing the query. Defaults to true.
+	AllowPartialResults *bool `yaml:"allowPartialResults"`
+}
+
+// Options returns the fanout storage options described by the configuration.
+func (c FanoutConfiguration) Options() (fanout.Options, error) {
+	opts := fanout.NewOptions()
+	if c.ConflictResolution != "" {
+		resolution, err := fanout.ParseConflictResolution(c.ConflictResolution)
+		if err != nil {
+			return fanout.Options{}, err
+		}
+
+		opts.ConflictResolution = resolution
+	}
+
+	opts.StoreTimeouts = map[storage.Type]time.Duration{
+		storage.TypeLocalDC:  c.LocalTimeout,
+		storage.TypeRemoteDC: c.RemoteTimeout,
+	}
+
+	if c.AllowPartialResults != nil {
+		opts.AllowPartialResults = *c.AllowPartialResults
+	}
+
+	return opts, nil
+}
+
+// LimitsConfiguration is the configuration of the resource limits enforced
+// when executing queries, a zero value for any limit disables it.
+type LimitsConfiguration struct {
+	// MaxConcurrentQueries is the maximum number of queries run at once.
+	MaxConcurrentQueries int `yaml:"maxConcurrentQueries" validate:"min=0"`
+
+	// PerQuery is the limits applied to each query.
+	PerQuery QueryLimitsConfiguration `yaml:"perQuery"`
+
+	// Global is the limits applied across all running queries.
+	Global QueryLimitsConfiguration `yaml:"global"`
+}
+
+// QueryLimitsConfiguration is the configuration of the fetched resource limits.
+type QueryLimitsConfiguration struct {
+	// MaxFetchedSeries is the maximum number of series fetched.
+	MaxFetchedSeries int64 `yaml:"maxFetchedSeries" validate:"min=0"`
+
+	// MaxFetchedDatapoints is the maximum number of datapoints decoded.
+	MaxFetchedDatapoints int64 `yaml:"maxFetchedDatapoints" validate:"min=0"`
+}
+
+// AsLimits returns the limits described by the configuration.
+func (c QueryLimitsConfiguration) AsLimits() cost.Limits {
+	return cost.Limits{
+		MaxFetchedSeries:     c.MaxFetchedSeries,
+		MaxFetchedDatapoints: c.MaxFetchedDatapoints,
+	}
+}
+
+// NamespaceConfiguration is the configuration of an M3DB namespace.
+type NamespaceConfiguration struct {
+	// Namespace is the name of the namespace.
+	Namespace string `yaml:"namespace" validate:"nonzero"`
+
+	// Resolution is the resolution of the data in the namespace, zero for
+	// the unaggregated namespace which writes go to.
+	Resolution time.Duration `yaml:"resolution"`
+
+	// Retention is how long the namespace retains data.
+	Retention time.Duration `yaml:"retention" validate:"nonzero"`
+
+	// BufferPast is how long after a time its datapoints may still be
+	// written to the namespace, past the end of their window for aggregated
+	// namespaces. Defaults to the buffer past of the cache.
+	BufferPast time.Duration `yaml:"bufferPast" validate:"min=0"`
+}
+
+// StoragePolicy returns the storage policy of the data in the namespace.
+func (c NamespaceConfiguration) StoragePolicy() policy.StoragePolicy {
+	return policy.NewStoragePolicy(c.Resolution, xtime.Second, c.Retention)
+}
+
+// DownsampleConfiguration is the configuration of the downsampler.
+type DownsampleConfiguration struct {
+	// Rules is the mapping rules selecting the series to downsample.
+	Rules []MappingRuleConfiguration `yaml:"rules" validate:"nonzero"`
+
+	// BufferPast is how long to wait for late datapoints before writing the
+	// aggregations of a window.
+	BufferPast time.Duration `yaml:"bufferPast"`
+
+	// FlushInterval is how often complete windows are written.
+	FlushInterval time.Duration `yaml:"flushInterval"`
+}
+
+// MappingRuleConfiguration is the configuration of a downsampling mapping rule.
+type MappingRuleConfiguration struct {
+	// Filter is a series selector such as {__name__=~"http_.*"}, matching
+	// the series the rule applies to.
+	Filter string `yaml:"filter" validate:"nonzero"`
+
+	// Aggregations is the aggregations to produce, such as last, sum or p99.
+	Aggregations []string `yaml:"aggregations"`
+
+	// Namespaces is the names of the aggregated namespaces to downsample to.
+	Namespaces []string `yaml:"namespaces" validate:"nonzero"`
+}
+
+// MappingRules returns the mapping rules described by the configuration,
+// resolving the namespaces they downsample to against the configured ones.
+func (c DownsampleConfiguration) MappingRules(
+	namespaces []NamespaceConfiguration,
+) ([]downsample.MappingRule, error) {
+	policies := make(map[string]NamespaceConfiguration, len(namespaces))
+	for _, ns := range namespaces {
+		policies[ns.Namespace] = ns
+	}
+
+	rules := make([]downsample.MappingRule, 0, len(c.Rules))
+	for _, ruleCfg := range c.Rules {
+		filter, err := promql.ParseMatchers(ruleCfg.Filter)
+		if err != nil {
+			return nil, err
+		}
+
+		aggregations := make([]downsample.Aggregation, 0, len(ruleCfg.Aggregations))
+		for _, str := range ruleCfg.Aggregations {
+			agg, err := downsample.ParseAggregation(str)
+			if err != nil {
+				return nil, err
+			}
+
+			aggregations = append(aggregations, agg)
+		}
+
+		storagePolicies := make([]policy.StoragePolicy, 0, len(ruleCfg.Namespaces))
+		for _, name := range ruleCfg.Namespaces {
+			ns, ok := policies[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown downsample namespace: %s", name)
+			}
+
+			if ns.Resolution == 0 {
+				return nil, fmt.Errorf("cannot downsample to unaggregated namespace: %s", name)
+			}
+
+			storagePolicies = append(storagePolicies, ns.StoragePolicy())
+		}
+
+		rules = append(rules, downsample.MappingRule{
+			Filter:          filter,
+			Aggregations:    aggregations,
+			StoragePolicies: storagePolicies,
+		})
+	}
+
+	return rules, nil
+}
+
+var errAuthWithoutTLS = errors.New("rpc bearer tokens require tls unless auth is insecure")
+
 // RPCConfiguration is the RPC configuration for the coordinator for
 // the GRPC server used for remote coordinator to coordinator calls.
 type RPCConfiguration struct {
@@ -55,4 +625,166 @@ type RPCConfiguration struct {
 	// RemoteListenAddresses is the remote listen addresses to call for remote
 	// coordinator calls.
 	RemoteListenAddresses []string `yaml:"remoteListenAddresses"`
+
+	// TLS secures the server and the connections to remote coordinators.
+	TLS *remote.TLSConfiguration `yaml:"tls"`
+
+	// Auth is the bearer token authentication of remote coordinator calls.
+	Auth *remote.AuthConfiguration `yaml:"auth"`
+
+	// Timeout is the deadline of remote coordinator calls.
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Keepalive is the keepalive configuration of the connections.
+	Keepalive *remote.KeepaliveConfiguration `yaml:"keepalive"`
+
+	// MaxBackoff is the maximum delay between attempts to reconnect to
+	// remote coordinators.
+	MaxBackoff time.Duration `yaml:"maxBackoff"`
+
+	// Discovery discovers the remote coordinators through the cluster
+	// services in addition to RemoteListenAddresses.
+	Discovery *RemoteDiscoveryConfiguration `yaml:"discovery"`
+
+	// Pushdown has the remote coordinators execute the selectors, range
+	// functions and aggregations of queries which only need the series of a
+	// single coordinator, returning their results rather than the raw series.
+	// Remote coordinators also need it enabled to execute them. The partial
+	// results of each coordinator are merged without matching up series, so
+	// this requires the disjoint fanout conflict resolution, each series
+	// being held by a single coordinator.
+	Pushdown bool `yaml:"pushdown"`
+}
+
+// RemoteDiscoveryConfiguration is the configuration of the discovery of remote
+// coordinators, which are instances of a cluster service grouped by zone.
+type RemoteDiscoveryConfiguration struct {
+	// Service is the name of the service of the coordinators of all zones.
+	Service string `yaml:"service" validate:"nonzero"`
+
+	// Environment is the environment of the service.
+	Environment string `yaml:"environment" validate:"nonzero"`
+
+	// Zone is the zone of the cluster services the service is registered with.
+	Zone string `yaml:"zone" validate:"nonzero"`
+
+	// LocalZone is the zone of this coordinator, whose coordinators are not
+	// remote. Defaults to Zone.
+	LocalZone string `yaml:"localZone"`
+
+	// IncludeUnhealthy includes coordinators without a recent heartbeat.
+	IncludeUnhealthy bool `yaml:"includeUnhealthy"`
+
+	// InstanceID is the ID this coordinator advertises itself under, which
+	// sends heartbeats so other coordinators see it as healthy. This
+	// coordinator is not advertised if unset.
+	InstanceID string `yaml:"instanceID"`
+
+	// Endpoint is the RPC endpoint this coordinator advertises.
+	Endpoint string `yaml:"endpoint"`
+}
+
+// ServiceID returns the ID of the service of the coordinators.
+func (c RemoteDiscoveryConfiguration) ServiceID() services.ServiceID {
+	return services.NewServiceID().
+		SetName(c.Service).
+		SetEnvironment(c.Environment).
+		SetZone(c.Zone)
+}
+
+// LocalZoneOrDefault returns the zone of this coordinator.
+func (c RemoteDiscoveryConfiguration) LocalZoneOrDefault() string {
+	if c.LocalZone != "" {
+		return c.LocalZone
+	}
+
+	return c.Zone
+}
+
+// QueryOptions returns the options querying the coordinators.
+func (c RemoteDiscoveryConfiguration) QueryOptions() services.QueryOptions {
+	return services.NewQueryOptions().SetIncludeUnhealthy(c.IncludeUnhealthy)
+}
+
+// Advertisement returns the advertisement of this coordinator, or nil if it
+// is not advertised.
+func (c RemoteDiscoveryConfiguration) Advertisement() services.Advertisement {
+	if c.InstanceID == "" {
+		return nil
+	}
+
+	instance := placement.NewInstance().
+		SetID(c.InstanceID).
+		SetEndpoint(c.Endpoint).
+		SetZone(c.LocalZoneOrDefault())
+
+	return services.NewAdvertisement().
+		SetServiceID(c.ServiceID()).
+		SetPlacementInstance(instance)
+}
+
+// ServerOptions returns the options of the RPC server.
+func (c RPCConfiguration) ServerOptions() ([]grpc.ServerOption, error) {
+	var opts []grpc.ServerOption
+	if c.TLS != nil {
+		tlsConfig, err := c.TLS.ServerTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	if c.Auth != nil && len(c.Auth.AllowedTokens) > 0 {
+		if c.TLS == nil && !c.Auth.Insecure {
+			return nil, errAuthWithoutTLS
+		}
+
+		authOpts, err := remote.NewBearerTokenServerOptions(c.Auth.AllowedTokens)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, authOpts...)
+	}
+
+	if c.Keepalive != nil {
+		opts = append(opts,
+			grpc.KeepaliveParams(c.Keepalive.ServerParameters()),
+			grpc.KeepaliveEnforcementPolicy(c.Keepalive.EnforcementPolicy()))
+	}
+
+	return opts, nil
+}
+
+// ClientOptions returns the options of the clients of remote coordinators.
+func (c RPCConfiguration) ClientOptions() (remote.ClientOptions, error) {
+	opts := remote.ClientOptions{Timeout: c.Timeout}
+	if c.TLS != nil {
+		tlsConfig, err := c.TLS.ClientTLSConfig()
+		if err != nil {
+			return remote.ClientOptions{}, err
+		}
+
+		opts.TLS = tlsConfig
+	}
+
+	if c.Auth != nil && c.Auth.Token != "" {
+		if opts.TLS == nil && !c.Auth.Insecure {
+			return remote.ClientOptions{}, errAuthWithoutTLS
+		}
+
+		creds := remote.NewBearerTokenCredentials(c.Auth.Token, opts.TLS != nil)
+		opts.DialOptions = append(opts.DialOptions, grpc.WithPerRPCCredentials(creds))
+	}
+
+	if c.Keepalive != nil {
+		opts.DialOptions = append(opts.DialOptions, grpc.WithKeepaliveParams(c.Keepalive.ClientParameters()))
+	}
+
+	if c.MaxBackoff > 0 {
+		opts.DialOptions = append(opts.DialOptions, grpc.WithBackoffMaxDelay(c.MaxBackoff))
+	}
+
+	return opts, nil
 }
@@ -23,6 +23,7 @@
 package main_test
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"sync"
@@ -230,7 +231,7 @@ func TestIndexEnabledServer(t *testing.T) {
 
 	reQuery, err := m3ninxidx.NewRegexpQuery([]byte("foo"), []byte("b.*"))
 	assert.NoError(t, err)
-	iters, exhaustive, err := session.FetchTagged(ident.StringID(namespaceID), index.Query{reQuery}, index.QueryOptions{
+	iters, exhaustive, err := session.FetchTagged(context.Background(), ident.StringID(namespaceID), index.Query{reQuery}, index.QueryOptions{
 		StartInclusive: fetchStart,
 		EndExclusive:   fetchEnd,
 	})
@@ -252,7 +253,7 @@ func TestIndexEnabledServer(t *testing.T) {
 		assert.Equal(t, v.unit, unit)
 	}
 
-	resultsIter, resultsExhaustive, err := session.FetchTaggedIDs(ident.StringID(namespaceID), index.Query{reQuery}, index.QueryOptions{
+	resultsIter, resultsExhaustive, err := session.FetchTaggedIDs(context.Background(), ident.StringID(namespaceID), index.Query{reQuery}, index.QueryOptions{
 		StartInclusive: fetchStart,
 		EndExclusive:   fetchEnd,
 	})
@@ -25,6 +25,7 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/m3db/m3db/src/coordinator/storage"
 	"github.com/m3db/m3db/src/coordinator/util/logging"
 
 	"github.com/golang/protobuf/jsonpb"
@@ -50,6 +51,14 @@ func WriteJSONResponse(w http.ResponseWriter, data interface{}, logger *zap.Logg
 	w.Write(jsonData)
 }
 
+// AddWarningsHeader adds the warnings describing why the results of the
+// response may be incomplete to its headers
+func AddWarningsHeader(w http.ResponseWriter, warnings storage.Warnings) {
+	for _, warning := range warnings {
+		w.Header().Add(WarningsHeader, warning.String())
+	}
+}
+
 // WriteProtoMsgJSONResponse writes a protobuf message to the ResponseWriter. This uses jsonpb
 // for json marshalling, which encodes fields with default values, even with the omitempty tag.
 func WriteProtoMsgJSONResponse(w http.ResponseWriter, data proto.Message, logger *zap.Logger) {
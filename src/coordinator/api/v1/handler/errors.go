@@ -25,6 +25,8 @@ import (
 	"errors"
 	"net/http"
 	"strings"
+
+	coordErrors "github.com/m3db/m3db/src/coordinator/errors"
 )
 
 var (
@@ -42,6 +44,22 @@ func Error(w http.ResponseWriter, err error, code int) {
 	})
 }
 
+// ExecutionErrorCode returns the HTTP status code for an error returned while
+// executing a query. Queries exceeding their own resource limits are client
+// errors, while those rejected by global limits or failed for returning
+// partial results may succeed when retried.
+func ExecutionErrorCode(err error) int {
+	limitErr, ok := err.(*coordErrors.ResourceLimitError)
+	switch {
+	case ok && limitErr.Global(), coordErrors.IsPartialResultError(err):
+		return http.StatusServiceUnavailable
+	case ok:
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 // ParseError is the error from parsing requests
 type ParseError struct {
 	inner error
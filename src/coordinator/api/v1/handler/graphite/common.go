@@ -0,0 +1,159 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/api/v1/handler/prometheus"
+	"github.com/m3db/m3db/src/coordinator/executor"
+	"github.com/m3db/m3db/src/coordinator/graphite"
+	"github.com/m3db/m3db/src/coordinator/storage"
+)
+
+const (
+	fromParam  = "from"
+	untilParam = "until"
+
+	// defaultFrom is the start of requests not giving one, as in Graphite
+	defaultFrom = "-24h"
+)
+
+// timeRange is the time range of a Graphite request
+type timeRange struct {
+	start time.Time
+	end   time.Time
+}
+
+// parseTimeRange parses the from and until parameters of a request
+func parseTimeRange(r *http.Request, now time.Time) (timeRange, error) {
+	from := r.FormValue(fromParam)
+	if from == "" {
+		from = defaultFrom
+	}
+
+	start, err := graphite.ParseTime(from, now)
+	if err != nil {
+		return timeRange{}, fmt.Errorf("invalid parameter '%s': %v", fromParam, err)
+	}
+
+	end := now
+	if until := r.FormValue(untilParam); until != "" {
+		end, err = graphite.ParseTime(until, now)
+		if err != nil {
+			return timeRange{}, fmt.Errorf("invalid parameter '%s': %v", untilParam, err)
+		}
+	}
+
+	if !end.After(start) {
+		return timeRange{}, fmt.Errorf("'%s' must be after '%s'", untilParam, fromParam)
+	}
+
+	return timeRange{start: start, end: end}, nil
+}
+
+func parseTimeout(r *http.Request) (time.Duration, error) {
+	params, err := prometheus.ParseRequestParams(r)
+	if err != nil {
+		return 0, err
+	}
+
+	return params.Timeout, nil
+}
+
+// newFetchOptions returns the options of the fetches of a request, holding
+// them to the resource limits of the engine and interrupting them once the
+// context of the request is done. The returned function must be called once
+// the request completes.
+func newFetchOptions(ctx context.Context, engine *executor.Engine) (*storage.FetchOptions, func()) {
+	opts, release := engine.FetchOptions()
+	var (
+		killChan = make(chan struct{})
+		doneChan = make(chan struct{})
+	)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			close(killChan)
+		case <-doneChan:
+		}
+	}()
+
+	opts.KillChan = killChan
+	return opts, func() {
+		close(doneChan)
+		release()
+	}
+}
+
+// storageFetcher fetches the series of Graphite paths from the storage,
+// consolidating them into the steps of the request
+type storageFetcher struct {
+	store    storage.Storage
+	options  *storage.FetchOptions
+	start    time.Time
+	end      time.Time
+	step     time.Duration
+	warnings storage.Warnings
+}
+
+func (f *storageFetcher) FetchSeries(ctx context.Context, path string) ([]*graphite.Series, error) {
+	matchers, err := graphite.MatchersForPath(path, true)
+	if err != nil {
+		return nil, err
+	}
+
+	query := &storage.FetchQuery{
+		Raw:         path,
+		TagMatchers: matchers,
+		Start:       f.start,
+		End:         f.end,
+		Interval:    f.step,
+	}
+
+	result, err := f.store.Fetch(ctx, query, f.options)
+	if err != nil {
+		return nil, err
+	}
+
+	f.warnings = append(f.warnings, result.Warnings...)
+	series := make([]*graphite.Series, 0, len(result.SeriesList))
+	for _, s := range result.SeriesList {
+		name, ok := graphite.TagsToPath(s.Tags)
+		if !ok {
+			name = s.Name()
+		}
+
+		series = append(series, graphite.NewConsolidatedSeries(name, s.Values(), f.start, f.end, f.step))
+	}
+
+	// Graphite returns the series matching globs sorted by path
+	sort.Slice(series, func(i, j int) bool {
+		return series[i].Name < series[j].Name
+	})
+
+	return series, nil
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/graphite"
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/ts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Unix(1500000000, 0)
+
+// testSeries is a series held by the test storage
+type testSeries struct {
+	path       string
+	datapoints ts.Datapoints
+}
+
+// testStorage returns the series whose tags match the queries, treating
+// series without a tag as not matching equal and regexp matchers as the M3DB
+// index does
+type testStorage struct {
+	storage.Storage
+	series   []testSeries
+	warnings storage.Warnings
+	queries  []*storage.FetchQuery
+	options  []*storage.FetchOptions
+}
+
+func matches(matchers models.Matchers, tags models.Tags) bool {
+	for _, m := range matchers {
+		value, ok := tags[m.Name]
+		if !ok {
+			if m.Type == models.MatchEqual || m.Type == models.MatchRegexp {
+				return false
+			}
+
+			continue
+		}
+
+		if !m.Matches(value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *testStorage) matching(query *storage.FetchQuery) ([]models.Tags, []testSeries) {
+	s.queries = append(s.queries, query)
+	var (
+		tags   []models.Tags
+		series []testSeries
+	)
+
+	for _, ts := range s.series {
+		seriesTags, err := graphite.PathToTags(ts.path)
+		if err != nil {
+			panic(err)
+		}
+
+		if matches(query.TagMatchers, seriesTags) {
+			tags = append(tags, seriesTags)
+			series = append(series, ts)
+		}
+	}
+
+	return tags, series
+}
+
+func (s *testStorage) Fetch(
+	_ context.Context, query *storage.FetchQuery, options *storage.FetchOptions) (*storage.FetchResult, error) {
+	s.options = append(s.options, options)
+	tags, series := s.matching(query)
+	result := &storage.FetchResult{Warnings: s.warnings}
+	for i, matched := range series {
+		result.SeriesList = append(result.SeriesList, ts.NewSeries(matched.path, matched.datapoints, tags[i]))
+	}
+
+	return result, nil
+}
+
+func (s *testStorage) FetchTags(
+	_ context.Context, query *storage.FetchQuery, options *storage.FetchOptions) (*storage.SearchResults, error) {
+	s.options = append(s.options, options)
+	tags, series := s.matching(query)
+	result := &storage.SearchResults{Warnings: s.warnings}
+	for i, matched := range series {
+		result.Metrics = append(result.Metrics, &models.Metric{ID: matched.path, Tags: tags[i]})
+	}
+
+	return result, nil
+}
+
+func newTestRequest(t *testing.T, path string, params url.Values) *http.Request {
+	req, err := http.NewRequest("GET", path+"?"+params.Encode(), nil)
+	require.NoError(t, err)
+	return req
+}
+
+func TestParseTimeRange(t *testing.T) {
+	req := newTestRequest(t, RenderURL, url.Values{fromParam: {"-1h"}, untilParam: {"-10min"}})
+	timeRange, err := parseTimeRange(req, testNow)
+	require.NoError(t, err)
+	assert.Equal(t, testNow.Add(-time.Hour), timeRange.start)
+	assert.Equal(t, testNow.Add(-10*time.Minute), timeRange.end)
+
+	req = newTestRequest(t, RenderURL, url.Values{})
+	timeRange, err = parseTimeRange(req, testNow)
+	require.NoError(t, err)
+	assert.Equal(t, testNow.Add(-24*time.Hour), timeRange.start)
+	assert.Equal(t, testNow, timeRange.end)
+}
+
+func TestParseTimeRangeInvalid(t *testing.T) {
+	for _, params := range []url.Values{
+		{fromParam: {"yesterday"}},
+		{untilParam: {"tomorrow"}},
+		{fromParam: {"-1h"}, untilParam: {"-2h"}},
+	} {
+		req := newTestRequest(t, RenderURL, params)
+		_, err := parseTimeRange(req, testNow)
+		assert.Error(t, err, params.Encode())
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/api/v1/handler"
+	"github.com/m3db/m3db/src/coordinator/executor"
+	"github.com/m3db/m3db/src/coordinator/graphite"
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/util/logging"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// FindURL is the url for the Graphite metrics find handler
+	FindURL = handler.RoutePrefixV1 + "/graphite/metrics/find"
+
+	queryParam = "query"
+)
+
+var errNoQuery = errors.New("no query provided")
+
+// FindHandler represents a handler for the Graphite metrics find endpoint,
+// which lists the nodes matching a path as used to browse metrics
+type FindHandler struct {
+	store  storage.Storage
+	engine *executor.Engine
+	nowFn  func() time.Time
+}
+
+// NewFindHandler returns a new instance of handler
+func NewFindHandler(store storage.Storage, engine *executor.Engine) http.Handler {
+	return &FindHandler{
+		store:  store,
+		engine: engine,
+		nowFn:  time.Now,
+	}
+}
+
+// findNode is a node in the Graphite tree JSON format. A path may be both a
+// leaf and a branch, in which case it is listed once as each.
+type findNode struct {
+	Text          string `json:"text"`
+	ID            string `json:"id"`
+	Leaf          int    `json:"leaf"`
+	Expandable    int    `json:"expandable"`
+	AllowChildren int    `json:"allowChildren"`
+}
+
+func (h *FindHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.WithContext(ctx)
+
+	query := r.FormValue(queryParam)
+	if query == "" {
+		handler.Error(w, errNoQuery, http.StatusBadRequest)
+		return
+	}
+
+	matchers, err := graphite.MatchersForPath(query, false)
+	if err != nil {
+		handler.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	timeRange, err := parseTimeRange(r, h.nowFn())
+	if err != nil {
+		handler.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	timeout, err := parseTimeout(r)
+	if err != nil {
+		handler.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	fetchQuery := &storage.FetchQuery{
+		Raw:         query,
+		TagMatchers: matchers,
+		Start:       timeRange.start,
+		End:         timeRange.end,
+	}
+
+	options, release := newFetchOptions(ctx, h.engine)
+	defer release()
+
+	result, err := h.store.FetchTags(ctx, fetchQuery, options)
+	if err != nil {
+		logger.Error("unable to find metrics", zap.String("query", query), zap.Any("error", err))
+		handler.Error(w, err, handler.ExecutionErrorCode(err))
+		return
+	}
+
+	depth := len(strings.Split(query, graphite.PathSeparator))
+	handler.AddWarningsHeader(w, result.Warnings)
+	handler.WriteJSONResponse(w, findNodes(result.Metrics, depth), logger)
+}
+
+// findNodes returns the nodes at the given depth of the paths of the metrics
+func findNodes(metrics models.Metrics, depth int) []findNode {
+	type nodeKey struct {
+		id   string
+		leaf bool
+	}
+
+	seen := make(map[nodeKey]struct{})
+	nodes := make([]findNode, 0, len(metrics))
+	for _, m := range metrics {
+		pathNodes := make([]string, 0, depth)
+		for i := 0; i < depth; i++ {
+			node, ok := m.Tags[graphite.TagName(i)]
+			if !ok {
+				break
+			}
+
+			pathNodes = append(pathNodes, node)
+		}
+
+		if len(pathNodes) != depth {
+			continue
+		}
+
+		_, hasChildren := m.Tags[graphite.TagName(depth)]
+		key := nodeKey{
+			id:   strings.Join(pathNodes, graphite.PathSeparator),
+			leaf: !hasChildren,
+		}
+
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+		node := findNode{
+			Text: pathNodes[depth-1],
+			ID:   key.id,
+		}
+
+		if key.leaf {
+			node.Leaf = 1
+		} else {
+			node.Expandable = 1
+			node.AllowChildren = 1
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].ID != nodes[j].ID {
+			return nodes[i].ID < nodes[j].ID
+		}
+
+		// List branches before leaves
+		return nodes[i].Leaf < nodes[j].Leaf
+	})
+
+	return nodes
+}
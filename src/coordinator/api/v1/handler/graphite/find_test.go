@@ -0,0 +1,92 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/executor"
+	"github.com/m3db/m3db/src/coordinator/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFind(t *testing.T) {
+	store := &testStorage{
+		series: []testSeries{
+			{path: "servers.host1.cpu"},
+			{path: "servers.host1.disk"},
+			{path: "servers.host2.cpu"},
+			{path: "servers.host2"},
+			{path: "services.api.requests"},
+		},
+	}
+
+	req := newTestRequest(t, FindURL, url.Values{queryParam: {"serv*.host?"}})
+	res := httptest.NewRecorder()
+	handler := NewFindHandler(store, executor.NewEngine(store)).(*FindHandler)
+	handler.nowFn = func() time.Time { return testNow }
+	handler.ServeHTTP(res, req)
+
+	require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+	assert.Equal(t, `[`+
+		`{"text":"host1","id":"servers.host1","leaf":0,"expandable":1,"allowChildren":1},`+
+		`{"text":"host2","id":"servers.host2","leaf":0,"expandable":1,"allowChildren":1},`+
+		`{"text":"host2","id":"servers.host2","leaf":1,"expandable":0,"allowChildren":0}`+
+		`]`, res.Body.String())
+
+	require.Len(t, store.queries, 1)
+	assert.Len(t, store.queries[0].TagMatchers, 2)
+	assert.Equal(t, testNow.Add(-24*time.Hour), store.queries[0].Start)
+
+	require.Len(t, store.options, 1)
+	assert.NotNil(t, store.options[0].Enforcer)
+	assert.NotNil(t, store.options[0].KillChan)
+}
+
+func newTestFindHandler(store storage.Storage) http.Handler {
+	return NewFindHandler(store, executor.NewEngine(store))
+}
+
+func TestFindNoMatches(t *testing.T) {
+	req := newTestRequest(t, FindURL, url.Values{queryParam: {"missing.*"}})
+	res := httptest.NewRecorder()
+	newTestFindHandler(&testStorage{}).ServeHTTP(res, req)
+	require.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "[]", res.Body.String())
+}
+
+func TestFindInvalid(t *testing.T) {
+	for _, params := range []url.Values{
+		{},
+		{queryParam: {"servers..cpu"}},
+		{queryParam: {"servers.*"}, fromParam: {"yesterday"}},
+	} {
+		res := httptest.NewRecorder()
+		newTestFindHandler(&testStorage{}).ServeHTTP(res, newTestRequest(t, FindURL, params))
+		assert.Equal(t, http.StatusBadRequest, res.Code, params.Encode())
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/api/v1/handler"
+	"github.com/m3db/m3db/src/coordinator/executor"
+	"github.com/m3db/m3db/src/coordinator/graphite"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/util/logging"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// RenderURL is the url for the Graphite render handler
+	RenderURL = handler.RoutePrefixV1 + "/graphite/render"
+
+	targetParam        = "target"
+	formatParam        = "format"
+	maxDataPointsParam = "maxDataPoints"
+
+	jsonFormat = "json"
+
+	// minStep is the finest resolution of rendered series
+	minStep = 10 * time.Second
+
+	// defaultMaxDataPoints bounds the points of series rendered without a
+	// maxDataPoints parameter, matching the Prometheus range query limit
+	defaultMaxDataPoints = 11000
+)
+
+var errNoTarget = errors.New("no target provided")
+
+// RenderHandler represents a handler for the Graphite render endpoint
+type RenderHandler struct {
+	store  storage.Storage
+	engine *executor.Engine
+	nowFn  func() time.Time
+}
+
+// NewRenderHandler returns a new instance of handler
+func NewRenderHandler(store storage.Storage, engine *executor.Engine) http.Handler {
+	return &RenderHandler{
+		store:  store,
+		engine: engine,
+		nowFn:  time.Now,
+	}
+}
+
+// renderParams are the parameters of a render request
+type renderParams struct {
+	targets   []*graphite.Expression
+	timeRange timeRange
+	step      time.Duration
+	timeout   time.Duration
+}
+
+func (h *RenderHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.WithContext(ctx)
+
+	params, err := parseRenderParams(r, h.nowFn())
+	if err != nil {
+		logger.Error("unable to parse request", zap.Any("error", err))
+		handler.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, params.timeout)
+	defer cancel()
+
+	options, release := newFetchOptions(ctx, h.engine)
+	defer release()
+
+	fetcher := &storageFetcher{
+		store:   h.store,
+		options: options,
+		start:   params.timeRange.start,
+		end:     params.timeRange.end,
+		step:    params.step,
+	}
+
+	results := make([]renderSeries, 0, len(params.targets))
+	for _, target := range params.targets {
+		series, err := target.Evaluate(ctx, fetcher)
+		if err != nil {
+			logger.Error("unable to render target", zap.Stringer("target", target), zap.Any("error", err))
+			handler.Error(w, err, handler.ExecutionErrorCode(err))
+			return
+		}
+
+		for _, s := range series {
+			results = append(results, newRenderSeries(s))
+		}
+	}
+
+	handler.AddWarningsHeader(w, fetcher.warnings)
+	handler.WriteJSONResponse(w, results, logger)
+}
+
+func parseRenderParams(r *http.Request, now time.Time) (renderParams, error) {
+	if err := r.ParseForm(); err != nil {
+		return renderParams{}, err
+	}
+
+	if format := r.Form.Get(formatParam); format != "" && format != jsonFormat {
+		return renderParams{}, fmt.Errorf("unsupported format %q, only %s is supported", format, jsonFormat)
+	}
+
+	rawTargets := r.Form[targetParam]
+	if len(rawTargets) == 0 {
+		return renderParams{}, errNoTarget
+	}
+
+	targets := make([]*graphite.Expression, 0, len(rawTargets))
+	for _, rawTarget := range rawTargets {
+		target, err := graphite.Parse(rawTarget)
+		if err != nil {
+			return renderParams{}, err
+		}
+
+		if target.Type != graphite.PathExpression && target.Type != graphite.CallExpression {
+			return renderParams{}, fmt.Errorf("invalid target %s, expected a path or function call", target)
+		}
+
+		targets = append(targets, target)
+	}
+
+	timeRange, err := parseTimeRange(r, now)
+	if err != nil {
+		return renderParams{}, err
+	}
+
+	maxDataPoints := defaultMaxDataPoints
+	if raw := r.Form.Get(maxDataPointsParam); raw != "" {
+		maxDataPoints, err = strconv.Atoi(raw)
+		if err != nil || maxDataPoints <= 0 {
+			return renderParams{}, fmt.Errorf("invalid parameter '%s': %q", maxDataPointsParam, raw)
+		}
+	}
+
+	timeout, err := parseTimeout(r)
+	if err != nil {
+		return renderParams{}, err
+	}
+
+	step := renderStep(timeRange, maxDataPoints)
+	// Align the steps of all the series rendered so they can be combined
+	startNanos := timeRange.start.UnixNano()
+	timeRange.start = time.Unix(0, startNanos-startNanos%int64(step))
+	return renderParams{
+		targets:   targets,
+		timeRange: timeRange,
+		step:      step,
+		timeout:   timeout,
+	}, nil
+}
+
+// renderStep returns the step of the rendered series, the finest resolution
+// which keeps them within maxDataPoints
+func renderStep(timeRange timeRange, maxDataPoints int) time.Duration {
+	step := minStep
+	if graphite.NumSteps(timeRange.start, timeRange.end, step) <= maxDataPoints {
+		return step
+	}
+
+	step = timeRange.end.Sub(timeRange.start) / time.Duration(maxDataPoints)
+	// Round up to whole seconds, the resolution of Graphite timestamps
+	return (step + time.Second - 1).Truncate(time.Second)
+}
+
+// renderSeries is a series in the Graphite JSON format
+type renderSeries struct {
+	Target     string            `json:"target"`
+	Datapoints []renderDatapoint `json:"datapoints"`
+}
+
+func newRenderSeries(s *graphite.Series) renderSeries {
+	datapoints := make([]renderDatapoint, 0, s.Len())
+	for i, v := range s.Values {
+		datapoints = append(datapoints, renderDatapoint{
+			value:     v,
+			timestamp: s.Timestamp(i).Unix(),
+		})
+	}
+
+	return renderSeries{
+		Target:     s.Name,
+		Datapoints: datapoints,
+	}
+}
+
+// renderDatapoint is a datapoint in the Graphite JSON format, which is a pair
+// of the value, or null for missing values, and the unix timestamp
+type renderDatapoint struct {
+	value     float64
+	timestamp int64
+}
+
+func (d renderDatapoint) MarshalJSON() ([]byte, error) {
+	var value interface{}
+	if !math.IsNaN(d.value) && !math.IsInf(d.value, 0) {
+		value = d.value
+	}
+
+	return json.Marshal([]interface{}{value, d.timestamp})
+}
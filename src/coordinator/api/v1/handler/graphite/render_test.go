@@ -0,0 +1,143 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/api/v1/handler"
+	"github.com/m3db/m3db/src/coordinator/executor"
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/ts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRenderHandler(store storage.Storage) *RenderHandler {
+	return &RenderHandler{
+		store:  store,
+		engine: executor.NewEngine(store),
+		nowFn:  func() time.Time { return testNow },
+	}
+}
+
+func unixParam(t time.Time) string {
+	return strconv.FormatInt(t.Unix(), 10)
+}
+
+func TestRender(t *testing.T) {
+	store := &testStorage{
+		series: []testSeries{
+			{path: "servers.host1.cpu", datapoints: ts.Datapoints{
+				{Timestamp: testNow, Value: 1},
+				{Timestamp: testNow.Add(20 * time.Second), Value: 3},
+			}},
+			{path: "servers.host2.cpu", datapoints: ts.Datapoints{
+				{Timestamp: testNow.Add(5 * time.Second), Value: 2},
+			}},
+			{path: "servers.host2.cpu.user", datapoints: ts.Datapoints{
+				{Timestamp: testNow, Value: 100},
+			}},
+		},
+		warnings: storage.Warnings{storage.NewNonExhaustiveWarning(10)},
+	}
+
+	req := newTestRequest(t, RenderURL, url.Values{
+		targetParam: {"servers.*.cpu", "sumSeries(servers.*.cpu)"},
+		fromParam:   {unixParam(testNow)},
+		untilParam:  {unixParam(testNow.Add(30 * time.Second))},
+	})
+
+	res := httptest.NewRecorder()
+	newTestRenderHandler(store).ServeHTTP(res, req)
+	require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+	assert.Equal(t, `[`+
+		`{"target":"servers.host1.cpu","datapoints":[[1,1500000000],[null,1500000010],[3,1500000020]]},`+
+		`{"target":"servers.host2.cpu","datapoints":[[2,1500000000],[null,1500000010],[null,1500000020]]},`+
+		`{"target":"sumSeries(servers.*.cpu)","datapoints":[[3,1500000000],[null,1500000010],[3,1500000020]]}`+
+		`]`, res.Body.String())
+	assert.Len(t, res.Header()[handler.WarningsHeader], 2)
+
+	require.Len(t, store.queries, 2)
+	query := store.queries[0]
+	assert.Equal(t, "servers.*.cpu", query.Raw)
+	assert.Equal(t, testNow, query.Start)
+	assert.Equal(t, testNow.Add(30*time.Second), query.End)
+	require.Len(t, query.TagMatchers, 4)
+	assert.Equal(t, models.MatchNotRegexp, query.TagMatchers[3].Type)
+
+	// Both targets are held to the limits of the same request
+	require.Len(t, store.options, 2)
+	assert.NotNil(t, store.options[0].Enforcer)
+	assert.Equal(t, store.options[0], store.options[1])
+}
+
+func TestRenderAlignsStart(t *testing.T) {
+	store := &testStorage{}
+	req := newTestRequest(t, RenderURL, url.Values{
+		targetParam:        {"servers.*.cpu"},
+		fromParam:          {unixParam(testNow.Add(-time.Hour + 5*time.Second))},
+		maxDataPointsParam: {"60"},
+	})
+
+	res := httptest.NewRecorder()
+	newTestRenderHandler(store).ServeHTTP(res, req)
+	require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+	assert.Equal(t, "[]", res.Body.String())
+
+	require.Len(t, store.queries, 1)
+	assert.Equal(t, testNow.Add(-time.Hour), store.queries[0].Start)
+	assert.Equal(t, time.Minute, store.queries[0].Interval)
+}
+
+func TestRenderInvalid(t *testing.T) {
+	tests := map[string]url.Values{
+		"no target":          {},
+		"invalid target":     {targetParam: {"sumSeries(servers.*"}},
+		"number target":      {targetParam: {"1"}},
+		"unsupported format": {targetParam: {"servers.*"}, formatParam: {"pickle"}},
+		"invalid from":       {targetParam: {"servers.*"}, fromParam: {"yesterday"}},
+		"invalid points":     {targetParam: {"servers.*"}, maxDataPointsParam: {"0"}},
+		"invalid timeout":    {targetParam: {"servers.*"}, "timeout": {"forever"}},
+	}
+
+	for name, params := range tests {
+		t.Run(name, func(t *testing.T) {
+			res := httptest.NewRecorder()
+			newTestRenderHandler(&testStorage{}).ServeHTTP(res, newTestRequest(t, RenderURL, params))
+			assert.Equal(t, http.StatusBadRequest, res.Code)
+		})
+	}
+}
+
+func TestRenderStep(t *testing.T) {
+	day := timeRange{start: testNow.Add(-24 * time.Hour), end: testNow}
+	assert.Equal(t, minStep, renderStep(day, defaultMaxDataPoints))
+	assert.Equal(t, time.Minute, renderStep(day, 1440))
+	assert.Equal(t, 61*time.Second, renderStep(day, 1439))
+}
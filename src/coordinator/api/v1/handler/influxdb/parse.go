@@ -0,0 +1,312 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package influxdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+)
+
+var (
+	errNoFields        = errors.New("missing fields")
+	errNoMeasurement   = errors.New("missing measurement")
+	errUnbalancedQuote = errors.New("unbalanced quotes")
+
+	// precisions are the units of timestamps accepted by the precision
+	// parameter of InfluxDB writes
+	precisions = map[string]time.Duration{
+		"":   time.Nanosecond,
+		"n":  time.Nanosecond,
+		"ns": time.Nanosecond,
+		"u":  time.Microsecond,
+		"us": time.Microsecond,
+		"µ":  time.Microsecond,
+		"ms": time.Millisecond,
+		"s":  time.Second,
+		"m":  time.Minute,
+		"h":  time.Hour,
+	}
+)
+
+// Point is a line of the InfluxDB line protocol, holding the numeric fields
+// of a measurement with a tag set at a point in time
+type Point struct {
+	Measurement string
+	Tags        models.Tags
+	Fields      []Field
+	Timestamp   time.Time
+}
+
+// Field is a numeric field of a point, booleans are held as 1 and 0
+type Field struct {
+	Key   string
+	Value float64
+}
+
+// ParsePrecision parses the precision of the timestamps of a write
+func ParsePrecision(s string) (time.Duration, error) {
+	precision, ok := precisions[s]
+	if !ok {
+		return 0, fmt.Errorf("invalid precision %q", s)
+	}
+
+	return precision, nil
+}
+
+// ParseLine parses a line of the InfluxDB line protocol, formatted as
+// "<measurement>[,<tag>=<value>...] <field>=<value>[,<field>=<value>...] [timestamp]".
+// String fields are skipped as they cannot be stored, and points without a
+// timestamp are given the default timestamp.
+func ParseLine(line []byte, precision time.Duration, defaultTimestamp time.Time) (Point, error) {
+	// Quotes are only meaningful in field values so the series is split off
+	// before looking for the fields and timestamp
+	s := strings.TrimSpace(string(line))
+	idx := indexUnescaped(s, ' ')
+	if idx < 0 {
+		return Point{}, errNoFields
+	}
+
+	sections, err := splitUnescaped(s[idx+1:], ' ', true)
+	if err != nil {
+		return Point{}, err
+	}
+
+	sections = removeEmpty(sections)
+	if len(sections) == 0 {
+		return Point{}, errNoFields
+	}
+
+	if len(sections) > 2 {
+		return Point{}, fmt.Errorf("expected fields and an optional timestamp after the series, got %d sections", len(sections))
+	}
+
+	point := Point{Timestamp: defaultTimestamp}
+	if err := point.parseSeries(s[:idx]); err != nil {
+		return Point{}, err
+	}
+
+	if err := point.parseFields(sections[0]); err != nil {
+		return Point{}, err
+	}
+
+	if len(sections) == 2 {
+		if point.Timestamp, err = parseTimestamp(sections[1], precision); err != nil {
+			return Point{}, err
+		}
+	}
+
+	return point, nil
+}
+
+// parseSeries parses the measurement and tag set of a line
+func (p *Point) parseSeries(section string) error {
+	parts, err := splitUnescaped(section, ',', false)
+	if err != nil {
+		return err
+	}
+
+	p.Measurement = unescape(parts[0], ", ")
+	if p.Measurement == "" {
+		return errNoMeasurement
+	}
+
+	p.Tags = make(models.Tags, len(parts)-1)
+	for _, tag := range parts[1:] {
+		key, value, err := splitKeyValue(tag)
+		if err != nil {
+			return fmt.Errorf("invalid tag %q: %v", tag, err)
+		}
+
+		p.Tags[key] = value
+	}
+
+	return nil
+}
+
+// parseFields parses the numeric fields of a line
+func (p *Point) parseFields(section string) error {
+	fields, err := splitUnescaped(section, ',', true)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		key, value, err := splitKeyValue(field)
+		if err != nil {
+			return fmt.Errorf("invalid field %q: %v", field, err)
+		}
+
+		if strings.HasPrefix(value, `"`) {
+			// String fields cannot be stored as datapoints
+			continue
+		}
+
+		v, err := parseFieldValue(value)
+		if err != nil {
+			return fmt.Errorf("invalid field %q: %v", key, err)
+		}
+
+		p.Fields = append(p.Fields, Field{Key: key, Value: v})
+	}
+
+	return nil
+}
+
+func parseFieldValue(value string) (float64, error) {
+	switch value {
+	case "t", "T", "true", "True", "TRUE":
+		return 1, nil
+	case "f", "F", "false", "False", "FALSE":
+		return 0, nil
+	}
+
+	switch {
+	case strings.HasSuffix(value, "i"):
+		i, err := strconv.ParseInt(strings.TrimSuffix(value, "i"), 10, 64)
+		return float64(i), err
+	case strings.HasSuffix(value, "u"):
+		u, err := strconv.ParseUint(strings.TrimSuffix(value, "u"), 10, 64)
+		return float64(u), err
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("unsupported value %s", value)
+	}
+
+	return f, nil
+}
+
+func parseTimestamp(s string, precision time.Duration) (time.Time, error) {
+	ts, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+	}
+
+	if ts > math.MaxInt64/int64(precision) || ts < math.MinInt64/int64(precision) {
+		return time.Time{}, fmt.Errorf("timestamp %q out of range", s)
+	}
+
+	return time.Unix(0, ts*int64(precision)), nil
+}
+
+// splitKeyValue splits a tag or field on its first unescaped equals sign,
+// unescaping the key, and the value unless it is a field string
+func splitKeyValue(s string) (string, string, error) {
+	idx := indexUnescaped(s, '=')
+	if idx < 0 {
+		return "", "", errors.New("missing '='")
+	}
+
+	key, value := unescape(s[:idx], ", ="), s[idx+1:]
+	if !strings.HasPrefix(value, `"`) {
+		value = unescape(value, ", =")
+	}
+
+	if key == "" || value == "" {
+		return "", "", errors.New("empty key or value")
+	}
+
+	return key, value, nil
+}
+
+// splitUnescaped splits s on each occurrence of sep which is neither escaped
+// by a backslash nor, if quoted is true, within a double quoted string
+func splitUnescaped(s string, sep byte, quoted bool) ([]string, error) {
+	var (
+		parts   []string
+		start   int
+		inQuote bool
+	)
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\':
+			// Skip the escaped character
+			i++
+		case quoted && c == '"':
+			inQuote = !inQuote
+		case c == sep && !inQuote:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+
+	if inQuote {
+		return nil, errUnbalancedQuote
+	}
+
+	return append(parts, s[start:]), nil
+}
+
+func indexUnescaped(s string, c byte) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case c:
+			return i
+		}
+	}
+
+	return -1
+}
+
+// unescape removes the backslashes escaping any of the given characters,
+// leaving other backslashes in place as InfluxDB does
+func unescape(s string, chars string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && strings.IndexByte(chars, s[i+1]) >= 0 {
+			i++
+		}
+
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
+
+func removeEmpty(parts []string) []string {
+	nonEmpty := parts[:0]
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+
+	return nonEmpty
+}
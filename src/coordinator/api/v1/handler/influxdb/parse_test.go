@@ -0,0 +1,130 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package influxdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Unix(1500000000, 0)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected Point
+	}{
+		{
+			line: "cpu value=1",
+			expected: Point{
+				Measurement: "cpu",
+				Tags:        models.Tags{},
+				Fields:      []Field{{Key: "value", Value: 1}},
+				Timestamp:   testNow,
+			},
+		},
+		{
+			line: "cpu,host=a,region=us-west idle=90.5,busy=9i,total=100u 1500000001000000000",
+			expected: Point{
+				Measurement: "cpu",
+				Tags:        models.Tags{"host": "a", "region": "us-west"},
+				Fields:      []Field{{Key: "idle", Value: 90.5}, {Key: "busy", Value: 9}, {Key: "total", Value: 100}},
+				Timestamp:   time.Unix(1500000001, 0),
+			},
+		},
+		{
+			line: `disk\ io,path=/var\,lib,mount\=point=a\ b up=t,down=FALSE,name="a b, \"c\"=d",used=-1.5e3`,
+			expected: Point{
+				Measurement: "disk io",
+				Tags:        models.Tags{"path": "/var,lib", "mount=point": "a b"},
+				Fields:      []Field{{Key: "up", Value: 1}, {Key: "down", Value: 0}, {Key: "used", Value: -1500}},
+				Timestamp:   testNow,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		point, err := ParseLine([]byte(test.line), time.Nanosecond, testNow)
+		require.NoError(t, err, test.line)
+		assert.Equal(t, test.expected, point, test.line)
+	}
+}
+
+func TestParseLineTimestampPrecision(t *testing.T) {
+	for precision, expected := range map[string]time.Time{
+		"":   time.Unix(0, 2500000),
+		"ns": time.Unix(0, 2500000),
+		"u":  time.Unix(2, 500000000),
+		"ms": time.Unix(2500, 0),
+		"s":  time.Unix(2500000, 0),
+		"m":  time.Unix(2500000*60, 0),
+		"h":  time.Unix(2500000*3600, 0),
+	} {
+		p, err := ParsePrecision(precision)
+		require.NoError(t, err)
+
+		point, err := ParseLine([]byte("cpu value=1 2500000"), p, testNow)
+		require.NoError(t, err, precision)
+		assert.Equal(t, expected, point.Timestamp, precision)
+	}
+
+	_, err := ParsePrecision("d")
+	assert.Error(t, err)
+}
+
+func TestParseLineInvalid(t *testing.T) {
+	for _, line := range []string{
+		"cpu",
+		"cpu ",
+		",host=a value=1",
+		"cpu,host value=1",
+		"cpu,host= value=1",
+		"cpu value",
+		"cpu value=",
+		"cpu value=abc",
+		"cpu value=NaN",
+		"cpu value=Inf",
+		"cpu value=1.5i",
+		"cpu value=-1u",
+		`cpu value="unbalanced`,
+		"cpu value=1 abc",
+		"cpu value=1 1 1",
+		"cpu value=1,",
+		`"quoted,measurement" value=1`,
+	} {
+		_, err := ParseLine([]byte(line), time.Nanosecond, testNow)
+		assert.Error(t, err, line)
+	}
+
+	_, err := ParseLine([]byte("cpu value=1 9223372036854775807"), time.Second, testNow)
+	assert.Error(t, err)
+}
+
+func TestParseLineStringFieldsOnly(t *testing.T) {
+	point, err := ParseLine([]byte(`log message="started"`), time.Nanosecond, testNow)
+	require.NoError(t, err)
+	assert.Empty(t, point.Fields)
+}
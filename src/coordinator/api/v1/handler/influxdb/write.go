@@ -0,0 +1,348 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package influxdb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/api/v1/handler"
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/ts"
+	"github.com/m3db/m3db/src/coordinator/util/execution"
+	"github.com/m3db/m3db/src/coordinator/util/logging"
+
+	xtime "github.com/m3db/m3x/time"
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const (
+	// InfluxWriteURL is the url for the InfluxDB line protocol write handler
+	InfluxWriteURL = handler.RoutePrefixV1 + "/influxdb/write"
+
+	precisionParam = "precision"
+
+	// writeBatchSize is the number of series written concurrently, batches
+	// are written one after the other to bound the load on the storage
+	writeBatchSize = 128
+
+	defaultMaxRequestBytes = 16 << 20
+)
+
+// InfluxWriteOptions are the limits of the InfluxDB write endpoint, limits
+// which are zero or negative are disabled.
+type InfluxWriteOptions struct {
+	// MaxRequestBytes is the maximum size of a decompressed write request.
+	MaxRequestBytes int
+}
+
+// NewInfluxWriteOptions returns the default options of the write endpoint.
+func NewInfluxWriteOptions() InfluxWriteOptions {
+	return InfluxWriteOptions{
+		MaxRequestBytes: defaultMaxRequestBytes,
+	}
+}
+
+// InfluxWriteHandler represents a handler for the InfluxDB line protocol
+// write endpoint. Each numeric field of a line is written as a series named
+// after the measurement and the field, joined by an underscore.
+type InfluxWriteHandler struct {
+	store        storage.Storage
+	opts         InfluxWriteOptions
+	writeMetrics influxWriteMetrics
+	nowFn        func() time.Time
+}
+
+// NewInfluxWriteHandler returns a new instance of handler.
+func NewInfluxWriteHandler(
+	store storage.Storage,
+	opts InfluxWriteOptions,
+	scope tally.Scope,
+) http.Handler {
+	return &InfluxWriteHandler{
+		store:        store,
+		opts:         opts,
+		writeMetrics: newInfluxWriteMetrics(scope),
+		nowFn:        time.Now,
+	}
+}
+
+type influxWriteMetrics struct {
+	writeSuccess      tally.Counter
+	writeErrorsServer tally.Counter
+	writeErrorsClient tally.Counter
+}
+
+func newInfluxWriteMetrics(scope tally.Scope) influxWriteMetrics {
+	return influxWriteMetrics{
+		writeSuccess:      scope.Counter("write.success"),
+		writeErrorsServer: scope.Tagged(map[string]string{"code": "5XX"}).Counter("write.errors"),
+		writeErrorsClient: scope.Tagged(map[string]string{"code": "4XX"}).Counter("write.errors"),
+	}
+}
+
+// LineError is the error of a single line of a write
+type LineError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// WriteErrorResponse is returned when some lines of a write failed, the
+// lines which did not fail are still written
+type WriteErrorResponse struct {
+	Error string      `json:"error"`
+	Lines []LineError `json:"lines"`
+}
+
+// seriesWrite holds the datapoints of a series along with the lines they
+// were parsed from
+type seriesWrite struct {
+	query *storage.WriteQuery
+	lines []int
+}
+
+func (h *InfluxWriteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.WithContext(ctx)
+
+	precision, err := ParsePrecision(r.URL.Query().Get(precisionParam))
+	if err != nil {
+		h.writeMetrics.writeErrorsClient.Inc(1)
+		handler.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	body, rErr := readBody(r, h.opts.MaxRequestBytes)
+	if rErr != nil {
+		h.writeMetrics.writeErrorsClient.Inc(1)
+		handler.Error(w, rErr.Error(), rErr.Code())
+		return
+	}
+
+	series, parseErrors := parseLines(body, precision, h.nowFn())
+	writeErrors := h.write(ctx, series)
+
+	switch {
+	case len(writeErrors) > 0:
+		h.writeMetrics.writeErrorsServer.Inc(1)
+		logger.Error("write error", zap.Int("failedLines", len(writeErrors)), zap.String("err", writeErrors[0].Error))
+		writeLineErrors(w, http.StatusInternalServerError, "unable to write", append(parseErrors, writeErrors...))
+	case len(parseErrors) > 0:
+		h.writeMetrics.writeErrorsClient.Inc(1)
+		writeLineErrors(w, http.StatusBadRequest, "unable to parse", parseErrors)
+	default:
+		h.writeMetrics.writeSuccess.Inc(1)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// readBody reads the body of a write request, decompressing it if gzipped,
+// rejecting requests which decompress to more than maxBytes. A zero maxBytes
+// disables the limit.
+func readBody(r *http.Request, maxBytes int) ([]byte, *handler.ParseError) {
+	if r.Body == nil {
+		return nil, handler.NewParseError(fmt.Errorf("empty request body"), http.StatusBadRequest)
+	}
+
+	defer r.Body.Close()
+
+	var reader io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gzipReader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, handler.NewParseError(err, http.StatusBadRequest)
+		}
+
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	if maxBytes > 0 {
+		// Reading a byte past the limit tells larger requests apart, without
+		// decompressing any more of them
+		reader = io.LimitReader(reader, int64(maxBytes)+1)
+	}
+
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, handler.NewParseError(err, http.StatusBadRequest)
+	}
+
+	if maxBytes > 0 && len(body) > maxBytes {
+		err := fmt.Errorf("request exceeds the limit of %d bytes", maxBytes)
+		return nil, handler.NewParseError(err, http.StatusRequestEntityTooLarge)
+	}
+
+	return body, nil
+}
+
+// parseLines parses each line of the body, grouping the datapoints of the
+// fields by series so that each series is written once
+func parseLines(body []byte, precision time.Duration, now time.Time) ([]*seriesWrite, []LineError) {
+	var (
+		series      []*seriesWrite
+		byID        = make(map[string]*seriesWrite)
+		parseErrors []LineError
+		unit        = precisionUnit(precision)
+	)
+
+	for idx, line := range bytes.Split(body, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		lineNum := idx + 1
+		point, err := ParseLine(line, precision, now)
+		if err != nil {
+			parseErrors = append(parseErrors, LineError{Line: lineNum, Error: err.Error()})
+			continue
+		}
+
+		for _, field := range point.Fields {
+			tags := point.Tags.Clone()
+			tags[models.MetricName] = point.Measurement + "_" + field.Key
+
+			id := tags.ID()
+			s, ok := byID[id]
+			if !ok {
+				s = &seriesWrite{
+					query: &storage.WriteQuery{
+						Tags: tags,
+						Unit: unit,
+					},
+				}
+
+				byID[id] = s
+				series = append(series, s)
+			}
+
+			s.query.Datapoints = append(s.query.Datapoints, ts.Datapoint{Timestamp: point.Timestamp, Value: field.Value})
+			if n := len(s.lines); n == 0 || s.lines[n-1] != lineNum {
+				s.lines = append(s.lines, lineNum)
+			}
+		}
+	}
+
+	return series, parseErrors
+}
+
+// precisionUnit returns the unit the timestamps of a precision are written
+// with, precisions coarser than seconds being written in seconds
+func precisionUnit(precision time.Duration) xtime.Unit {
+	switch {
+	case precision >= time.Second:
+		return xtime.Second
+	case precision >= time.Millisecond:
+		return xtime.Millisecond
+	case precision >= time.Microsecond:
+		return xtime.Microsecond
+	default:
+		return xtime.Nanosecond
+	}
+}
+
+// write writes the series in batches, a failed series does not stop the
+// others from being written and is reported against each of its lines
+func (h *InfluxWriteHandler) write(ctx context.Context, series []*seriesWrite) []LineError {
+	var (
+		failed      = make(map[int]string)
+		requests    = make([]*localWriteRequest, 0, writeBatchSize)
+		execRequest = make([]execution.Request, 0, writeBatchSize)
+	)
+
+	for start := 0; start < len(series); start += writeBatchSize {
+		end := start + writeBatchSize
+		if end > len(series) {
+			end = len(series)
+		}
+
+		requests, execRequest = requests[:0], execRequest[:0]
+		for _, s := range series[start:end] {
+			req := &localWriteRequest{store: h.store, writeQuery: s.query}
+			requests = append(requests, req)
+			execRequest = append(execRequest, req)
+		}
+
+		// Requests never fail so that the rest of the batch is written
+		execution.ExecuteParallel(ctx, execRequest)
+		for i, req := range requests {
+			if req.err == nil {
+				continue
+			}
+
+			for _, line := range series[start+i].lines {
+				if _, ok := failed[line]; !ok {
+					failed[line] = req.err.Error()
+				}
+			}
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	writeErrors := make([]LineError, 0, len(failed))
+	for line, err := range failed {
+		writeErrors = append(writeErrors, LineError{Line: line, Error: err})
+	}
+
+	sort.Slice(writeErrors, func(i, j int) bool {
+		return writeErrors[i].Line < writeErrors[j].Line
+	})
+
+	return writeErrors
+}
+
+func writeLineErrors(w http.ResponseWriter, code int, msg string, lineErrors []LineError) {
+	sort.SliceStable(lineErrors, func(i, j int) bool {
+		return lineErrors[i].Line < lineErrors[j].Line
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(WriteErrorResponse{
+		Error: fmt.Sprintf("%s %d lines", msg, len(lineErrors)),
+		Lines: lineErrors,
+	})
+}
+
+type localWriteRequest struct {
+	store      storage.Storage
+	writeQuery *storage.WriteQuery
+	err        error
+}
+
+func (w *localWriteRequest) Process(ctx context.Context) error {
+	w.err = w.store.Write(ctx, w.writeQuery)
+	return nil
+}
@@ -0,0 +1,274 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package influxdb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/ts"
+	"github.com/m3db/m3db/src/coordinator/util/logging"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+type testStorage struct {
+	storage.Storage
+
+	sync.Mutex
+	writes []*storage.WriteQuery
+	failOn string
+}
+
+func (s *testStorage) Write(_ context.Context, query *storage.WriteQuery) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.failOn != "" && query.Tags[models.MetricName] == s.failOn {
+		return errors.New("write failed")
+	}
+
+	s.writes = append(s.writes, query)
+	return nil
+}
+
+// written returns the written queries sorted by series name
+func (s *testStorage) written() []*storage.WriteQuery {
+	s.Lock()
+	defer s.Unlock()
+
+	sort.Slice(s.writes, func(i, j int) bool {
+		return s.writes[i].Tags.ID() < s.writes[j].Tags.ID()
+	})
+
+	return s.writes
+}
+
+func newTestHandler(store storage.Storage) *InfluxWriteHandler {
+	h := NewInfluxWriteHandler(store, NewInfluxWriteOptions(), tally.NoopScope).(*InfluxWriteHandler)
+	h.nowFn = func() time.Time { return testNow }
+	return h
+}
+
+func serveWrite(h http.Handler, url, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", url, strings.NewReader(body))
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	return res
+}
+
+func TestInfluxWrite(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	store := &testStorage{}
+	res := serveWrite(newTestHandler(store), InfluxWriteURL+"?precision=s", strings.Join([]string{
+		"# comment",
+		"cpu,host=a idle=90,busy=10 1500000000",
+		"",
+		"cpu,host=a idle=80,busy=20 1500000010",
+		`cpu,host=b idle=70,state="running"`,
+	}, "\n"))
+	require.Equal(t, http.StatusNoContent, res.Code, res.Body.String())
+
+	written := store.written()
+	require.Len(t, written, 3)
+
+	assert.Equal(t, models.Tags{models.MetricName: "cpu_busy", "host": "a"}, written[0].Tags)
+	assert.Equal(t, ts.Datapoints{
+		{Timestamp: time.Unix(1500000000, 0), Value: 10},
+		{Timestamp: time.Unix(1500000010, 0), Value: 20},
+	}, written[0].Datapoints)
+
+	assert.Equal(t, models.Tags{models.MetricName: "cpu_idle", "host": "a"}, written[1].Tags)
+	assert.Equal(t, ts.Datapoints{
+		{Timestamp: time.Unix(1500000000, 0), Value: 90},
+		{Timestamp: time.Unix(1500000010, 0), Value: 80},
+	}, written[1].Datapoints)
+
+	assert.Equal(t, models.Tags{models.MetricName: "cpu_idle", "host": "b"}, written[2].Tags)
+	assert.Equal(t, ts.Datapoints{{Timestamp: testNow, Value: 70}}, written[2].Datapoints)
+}
+
+func TestInfluxWriteGzip(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte("mem free=1024i"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	store := &testStorage{}
+	req := httptest.NewRequest("POST", InfluxWriteURL, &buf)
+	req.Header.Set("Content-Encoding", "gzip")
+	res := httptest.NewRecorder()
+	newTestHandler(store).ServeHTTP(res, req)
+
+	require.Equal(t, http.StatusNoContent, res.Code, res.Body.String())
+	require.Len(t, store.written(), 1)
+	assert.Equal(t, "mem_free", store.written()[0].Tags[models.MetricName])
+}
+
+func TestInfluxWriteTooLarge(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	// A small gzipped body may decompress to far more than the limit
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	line := []byte("mem free=1024i\n")
+	for i := 0; i < 1<<16; i++ {
+		_, err := w.Write(line)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	store := &testStorage{}
+	h := newTestHandler(store)
+	h.opts.MaxRequestBytes = 1024
+
+	req := httptest.NewRequest("POST", InfluxWriteURL, bytes.NewReader(buf.Bytes()))
+	req.Header.Set("Content-Encoding", "gzip")
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.Code, res.Body.String())
+
+	res = serveWrite(h, InfluxWriteURL, strings.Repeat(string(line), 1<<10))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.Code, res.Body.String())
+	assert.Len(t, store.written(), 0)
+}
+
+func TestInfluxWriteManySeries(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	lines := make([]string, 3*writeBatchSize+1)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("requests,id=%d count=%di", i, i)
+	}
+
+	store := &testStorage{}
+	res := serveWrite(newTestHandler(store), InfluxWriteURL, strings.Join(lines, "\n"))
+	require.Equal(t, http.StatusNoContent, res.Code, res.Body.String())
+	assert.Len(t, store.written(), len(lines))
+}
+
+func TestInfluxWriteParseErrors(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	store := &testStorage{}
+	res := serveWrite(newTestHandler(store), InfluxWriteURL, strings.Join([]string{
+		"cpu value=1",
+		"cpu value=abc",
+		"mem value=2",
+		"disk",
+	}, "\n"))
+	require.Equal(t, http.StatusBadRequest, res.Code)
+
+	var resp WriteErrorResponse
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &resp))
+	assert.Equal(t, "unable to parse 2 lines", resp.Error)
+	require.Len(t, resp.Lines, 2)
+	assert.Equal(t, 2, resp.Lines[0].Line)
+	assert.Equal(t, 4, resp.Lines[1].Line)
+
+	// Valid lines are still written
+	assert.Len(t, store.written(), 2)
+}
+
+func TestInfluxWriteStorageErrors(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	store := &testStorage{failOn: "cpu_busy"}
+	res := serveWrite(newTestHandler(store), InfluxWriteURL, strings.Join([]string{
+		"cpu idle=90,busy=10",
+		"mem free=1",
+		"cpu busy=20",
+		"cpu value=",
+	}, "\n"))
+	require.Equal(t, http.StatusInternalServerError, res.Code)
+
+	var resp WriteErrorResponse
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &resp))
+	require.Len(t, resp.Lines, 3)
+	assert.Equal(t, LineError{Line: 1, Error: "write failed"}, resp.Lines[0])
+	assert.Equal(t, LineError{Line: 3, Error: "write failed"}, resp.Lines[1])
+	assert.Equal(t, 4, resp.Lines[2].Line)
+
+	assert.Len(t, store.written(), 2)
+}
+
+func TestInfluxWriteInvalidPrecision(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	store := &testStorage{}
+	res := serveWrite(newTestHandler(store), InfluxWriteURL+"?precision=d", "cpu value=1")
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	assert.Empty(t, store.written())
+}
+
+func TestInfluxWritePrecisionUnit(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	// Nanosecond timestamps within the same millisecond stay distinct
+	store := &testStorage{}
+	res := serveWrite(newTestHandler(store), InfluxWriteURL, strings.Join([]string{
+		"cpu value=1 1500000000000000001",
+		"cpu value=2 1500000000000000002",
+	}, "\n"))
+	require.Equal(t, http.StatusNoContent, res.Code, res.Body.String())
+
+	written := store.written()
+	require.Len(t, written, 1)
+	assert.Equal(t, xtime.Nanosecond, written[0].Unit)
+	assert.Equal(t, ts.Datapoints{
+		{Timestamp: time.Unix(0, 1500000000000000001), Value: 1},
+		{Timestamp: time.Unix(0, 1500000000000000002), Value: 2},
+	}, written[0].Datapoints)
+
+	for precision, unit := range map[string]xtime.Unit{
+		"s":  xtime.Second,
+		"ms": xtime.Millisecond,
+		"u":  xtime.Microsecond,
+		"m":  xtime.Second,
+		"h":  xtime.Second,
+	} {
+		store := &testStorage{}
+		res := serveWrite(newTestHandler(store), InfluxWriteURL+"?precision="+precision, "cpu value=1 1500")
+		require.Equal(t, http.StatusNoContent, res.Code, res.Body.String())
+		require.Len(t, store.written(), 1)
+		assert.Equal(t, unit, store.written()[0].Unit, precision)
+	}
+}
@@ -78,4 +78,5 @@ func RegisterRoutes(r *mux.Router, client clusterclient.Client) {
 	r.HandleFunc(GetURL, logged(NewGetHandler(client)).ServeHTTP).Methods("GET")
 	r.HandleFunc(AddURL, logged(NewAddHandler(client)).ServeHTTP).Methods("POST")
 	r.HandleFunc(DeleteURL, logged(NewDeleteHandler(client)).ServeHTTP).Methods("DELETE")
+	r.HandleFunc(UpdateURL, logged(NewUpdateHandler(client)).ServeHTTP).Methods("PUT", "PATCH")
 }
@@ -0,0 +1,352 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package namespace
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	clusterclient "github.com/m3db/m3cluster/client"
+	"github.com/m3db/m3cluster/kv"
+	"github.com/m3db/m3db/src/coordinator/api/v1/handler"
+	"github.com/m3db/m3db/src/coordinator/util/logging"
+	nsproto "github.com/m3db/m3db/src/dbnode/generated/proto/namespace"
+	"github.com/m3db/m3db/src/dbnode/storage/namespace"
+	xerrors "github.com/m3db/m3x/errors"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+var (
+	// UpdateURL is the url for the namespace update handler (with the PUT
+	// or PATCH method).
+	UpdateURL = fmt.Sprintf("%s/namespace/{%s}", handler.RoutePrefixV1, namespaceIDVar)
+)
+
+var (
+	errEmptyUpdateID = errors.New("must specify namespace ID to update")
+
+	// The block sizes determine how the data and index filesets and the
+	// commit logs of a namespace are laid out on the nodes. The coordinator
+	// cannot tell whether any node has written these yet, so each namespace
+	// in the registry is treated as if it had.
+	errBlockSizeImmutable = xerrors.NewInvalidParamsError(
+		errors.New("unable to change the block size of an existing namespace"))
+	errIndexBlockSizeImmutable = xerrors.NewInvalidParamsError(
+		errors.New("unable to change the index block size of a namespace with indexing enabled"))
+)
+
+// UpdateRequest is the request to update the options of a namespace, options
+// that are not set are left unchanged.
+type UpdateRequest struct {
+	Options UpdateOptions `json:"options"`
+
+	// DryRun returns the updated registry without committing it.
+	DryRun bool `json:"dryRun"`
+}
+
+// UpdateOptions are the namespace options to update.
+type UpdateOptions struct {
+	BootstrapEnabled  *bool                   `json:"bootstrapEnabled"`
+	FlushEnabled      *bool                   `json:"flushEnabled"`
+	WritesToCommitLog *bool                   `json:"writesToCommitLog"`
+	CleanupEnabled    *bool                   `json:"cleanupEnabled"`
+	RepairEnabled     *bool                   `json:"repairEnabled"`
+	SnapshotEnabled   *bool                   `json:"snapshotEnabled"`
+	RetentionOptions  *UpdateRetentionOptions `json:"retentionOptions"`
+	IndexOptions      *UpdateIndexOptions     `json:"indexOptions"`
+}
+
+// UpdateRetentionOptions are the retention options to update.
+type UpdateRetentionOptions struct {
+	RetentionPeriodNanos                     *int64 `json:"retentionPeriodNanos"`
+	BlockSizeNanos                           *int64 `json:"blockSizeNanos"`
+	BufferFutureNanos                        *int64 `json:"bufferFutureNanos"`
+	BufferPastNanos                          *int64 `json:"bufferPastNanos"`
+	BlockDataExpiry                          *bool  `json:"blockDataExpiry"`
+	BlockDataExpiryAfterNotAccessPeriodNanos *int64 `json:"blockDataExpiryAfterNotAccessPeriodNanos"`
+}
+
+// UpdateIndexOptions are the index options to update.
+type UpdateIndexOptions struct {
+	Enabled        *bool  `json:"enabled"`
+	BlockSizeNanos *int64 `json:"blockSizeNanos"`
+}
+
+// UpdateResponse is the response of a namespace update.
+type UpdateResponse struct {
+	// Registry is the namespace registry after the update.
+	Registry json.RawMessage `json:"registry"`
+
+	// DryRun is true if the registry was not committed.
+	DryRun bool `json:"dryRun"`
+
+	// Changes are the options of the namespace that changed.
+	Changes []OptionChange `json:"changes"`
+}
+
+// OptionChange is the change to a namespace option, durations are formatted
+// as Go durations.
+type OptionChange struct {
+	Option string `json:"option"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+type updateHandler Handler
+
+// NewUpdateHandler returns a new instance of a namespace update handler.
+func NewUpdateHandler(client clusterclient.Client) http.Handler {
+	return &updateHandler{client: client}
+}
+
+func (h *updateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.WithContext(ctx)
+	id := strings.TrimSpace(mux.Vars(r)[namespaceIDVar])
+	if id == "" {
+		logger.Error("no namespace ID to update", zap.Any("error", errEmptyUpdateID))
+		handler.Error(w, errEmptyUpdateID, http.StatusBadRequest)
+		return
+	}
+
+	updateReq, rErr := h.parseRequest(r)
+	if rErr != nil {
+		logger.Error("unable to parse request", zap.Any("error", rErr))
+		handler.Error(w, rErr.Error(), rErr.Code())
+		return
+	}
+
+	resp, err := h.update(id, updateReq)
+	if err != nil {
+		logger.Error("unable to update namespace", zap.Any("error", err))
+		switch {
+		case err == errNamespaceNotFound:
+			handler.Error(w, err, http.StatusNotFound)
+		case err == kv.ErrVersionMismatch:
+			handler.Error(w, fmt.Errorf("namespaces changed while updating: %v", err), http.StatusConflict)
+		case xerrors.IsInvalidParams(err):
+			handler.Error(w, err, http.StatusBadRequest)
+		default:
+			handler.Error(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	handler.WriteJSONResponse(w, resp, logger)
+}
+
+func (h *updateHandler) parseRequest(r *http.Request) (UpdateRequest, *handler.ParseError) {
+	var updateReq UpdateRequest
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return updateReq, handler.NewParseError(err, http.StatusBadRequest)
+	}
+	defer r.Body.Close()
+
+	if err := json.Unmarshal(body, &updateReq); err != nil {
+		return updateReq, handler.NewParseError(err, http.StatusBadRequest)
+	}
+
+	return updateReq, nil
+}
+
+func (h *updateHandler) update(id string, updateReq UpdateRequest) (*UpdateResponse, error) {
+	store, err := h.client.KV()
+	if err != nil {
+		return nil, err
+	}
+
+	metadatas, version, err := Metadata(store)
+	if err != nil {
+		return nil, err
+	}
+
+	nsMap, err := namespace.NewMap(metadatas)
+	if err != nil {
+		return nil, err
+	}
+
+	current, ok := namespace.ToProto(nsMap).Namespaces[id]
+	if !ok {
+		return nil, errNamespaceNotFound
+	}
+
+	updated, err := applyUpdate(current, updateReq.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	md, err := namespace.ToMetadata(id, updated)
+	if err != nil {
+		return nil, xerrors.NewInvalidParamsError(err)
+	}
+
+	for idx := range metadatas {
+		if metadatas[idx].ID().String() == id {
+			metadatas[idx] = md
+		}
+	}
+
+	nsMap, err = namespace.NewMap(metadatas)
+	if err != nil {
+		return nil, xerrors.NewInvalidParamsError(err)
+	}
+
+	protoRegistry := namespace.ToProto(nsMap)
+	if !updateReq.DryRun {
+		if _, err := store.CheckAndSet(M3DBNodeNamespacesKey, version, protoRegistry); err != nil {
+			if err == kv.ErrVersionMismatch {
+				return nil, err
+			}
+
+			return nil, fmt.Errorf("failed to update namespace: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	marshaler := jsonpb.Marshaler{EmitDefaults: true}
+	if err := marshaler.Marshal(&buf, protoRegistry); err != nil {
+		return nil, err
+	}
+
+	return &UpdateResponse{
+		Registry: buf.Bytes(),
+		DryRun:   updateReq.DryRun,
+		Changes:  diffOptions(current, protoRegistry.Namespaces[id]),
+	}, nil
+}
+
+// applyUpdate returns a copy of the namespace options with the update
+// applied, refusing updates that are unsafe for a namespace which may already
+// have data on the nodes
+func applyUpdate(
+	current *nsproto.NamespaceOptions,
+	update UpdateOptions,
+) (*nsproto.NamespaceOptions, error) {
+	var (
+		updated   = *current
+		retention = *current.RetentionOptions
+		index     = *current.IndexOptions
+	)
+
+	setBool := func(dst *bool, src *bool) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	setNanos := func(dst *int64, src *int64) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+
+	setBool(&updated.BootstrapEnabled, update.BootstrapEnabled)
+	setBool(&updated.FlushEnabled, update.FlushEnabled)
+	setBool(&updated.WritesToCommitLog, update.WritesToCommitLog)
+	setBool(&updated.CleanupEnabled, update.CleanupEnabled)
+	setBool(&updated.RepairEnabled, update.RepairEnabled)
+	setBool(&updated.SnapshotEnabled, update.SnapshotEnabled)
+
+	if ro := update.RetentionOptions; ro != nil {
+		setNanos(&retention.RetentionPeriodNanos, ro.RetentionPeriodNanos)
+		setNanos(&retention.BlockSizeNanos, ro.BlockSizeNanos)
+		setNanos(&retention.BufferFutureNanos, ro.BufferFutureNanos)
+		setNanos(&retention.BufferPastNanos, ro.BufferPastNanos)
+		setBool(&retention.BlockDataExpiry, ro.BlockDataExpiry)
+		setNanos(&retention.BlockDataExpiryAfterNotAccessPeriodNanos,
+			ro.BlockDataExpiryAfterNotAccessPeriodNanos)
+	}
+
+	if io := update.IndexOptions; io != nil {
+		setBool(&index.Enabled, io.Enabled)
+		setNanos(&index.BlockSizeNanos, io.BlockSizeNanos)
+	}
+
+	if retention.BlockSizeNanos != current.RetentionOptions.BlockSizeNanos {
+		return nil, errBlockSizeImmutable
+	}
+
+	// The index block size of a namespace that has not been indexing can
+	// change along with enabling the index, as no index filesets exist yet
+	if current.IndexOptions.Enabled &&
+		index.BlockSizeNanos != current.IndexOptions.BlockSizeNanos {
+		return nil, errIndexBlockSizeImmutable
+	}
+
+	updated.RetentionOptions = &retention
+	updated.IndexOptions = &index
+	return &updated, nil
+}
+
+// diffOptions returns the changes between namespace options
+func diffOptions(before, after *nsproto.NamespaceOptions) []OptionChange {
+	var changes []OptionChange
+	diffBool := func(option string, from, to bool) {
+		if from != to {
+			changes = append(changes, OptionChange{
+				Option: option,
+				From:   strconv.FormatBool(from),
+				To:     strconv.FormatBool(to),
+			})
+		}
+	}
+	diffNanos := func(option string, from, to int64) {
+		if from != to {
+			changes = append(changes, OptionChange{
+				Option: option,
+				From:   time.Duration(from).String(),
+				To:     time.Duration(to).String(),
+			})
+		}
+	}
+
+	diffBool("bootstrapEnabled", before.BootstrapEnabled, after.BootstrapEnabled)
+	diffBool("flushEnabled", before.FlushEnabled, after.FlushEnabled)
+	diffBool("writesToCommitLog", before.WritesToCommitLog, after.WritesToCommitLog)
+	diffBool("cleanupEnabled", before.CleanupEnabled, after.CleanupEnabled)
+	diffBool("repairEnabled", before.RepairEnabled, after.RepairEnabled)
+	diffBool("snapshotEnabled", before.SnapshotEnabled, after.SnapshotEnabled)
+
+	bro, aro := before.RetentionOptions, after.RetentionOptions
+	diffNanos("retentionOptions.retentionPeriodNanos", bro.RetentionPeriodNanos, aro.RetentionPeriodNanos)
+	diffNanos("retentionOptions.blockSizeNanos", bro.BlockSizeNanos, aro.BlockSizeNanos)
+	diffNanos("retentionOptions.bufferFutureNanos", bro.BufferFutureNanos, aro.BufferFutureNanos)
+	diffNanos("retentionOptions.bufferPastNanos", bro.BufferPastNanos, aro.BufferPastNanos)
+	diffBool("retentionOptions.blockDataExpiry", bro.BlockDataExpiry, aro.BlockDataExpiry)
+	diffNanos("retentionOptions.blockDataExpiryAfterNotAccessPeriodNanos",
+		bro.BlockDataExpiryAfterNotAccessPeriodNanos, aro.BlockDataExpiryAfterNotAccessPeriodNanos)
+
+	bio, aio := before.IndexOptions, after.IndexOptions
+	diffBool("indexOptions.enabled", bio.Enabled, aio.Enabled)
+	diffNanos("indexOptions.blockSizeNanos", bio.BlockSizeNanos, aio.BlockSizeNanos)
+
+	return changes
+}
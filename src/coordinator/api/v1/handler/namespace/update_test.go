@@ -0,0 +1,225 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package namespace
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/m3db/m3cluster/kv"
+	nsproto "github.com/m3db/m3db/src/dbnode/generated/proto/namespace"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUpdateRegistry() nsproto.Registry {
+	return nsproto.Registry{
+		Namespaces: map[string]*nsproto.NamespaceOptions{
+			"testNamespace": &nsproto.NamespaceOptions{
+				BootstrapEnabled:  true,
+				FlushEnabled:      true,
+				WritesToCommitLog: true,
+				CleanupEnabled:    false,
+				RepairEnabled:     false,
+				RetentionOptions: &nsproto.RetentionOptions{
+					RetentionPeriodNanos:                     172800000000000,
+					BlockSizeNanos:                           7200000000000,
+					BufferFutureNanos:                        600000000000,
+					BufferPastNanos:                          600000000000,
+					BlockDataExpiry:                          true,
+					BlockDataExpiryAfterNotAccessPeriodNanos: 3600000000000,
+				},
+				IndexOptions: &nsproto.IndexOptions{
+					Enabled:        true,
+					BlockSizeNanos: 7200000000000,
+				},
+			},
+		},
+	}
+}
+
+func newTestUpdateRequest(id, body string) *http.Request {
+	req := httptest.NewRequest("PATCH", "/namespace/"+id, strings.NewReader(body))
+	return mux.SetURLVars(req, map[string]string{"id": id})
+}
+
+func TestNamespaceUpdateHandler(t *testing.T) {
+	mockClient, mockKV, ctrl := SetupNamespaceTest(t)
+	updateHandler := NewUpdateHandler(mockClient)
+
+	w := httptest.NewRecorder()
+	req := newTestUpdateRequest("testNamespace", `
+        {
+            "options": {
+                "repairEnabled": true,
+                "retentionOptions": {
+                    "retentionPeriodNanos": 345600000000000,
+                    "blockSizeNanos": 7200000000000
+                }
+            }
+        }
+    `)
+
+	mockValue := kv.NewMockValue(ctrl)
+	mockValue.EXPECT().Unmarshal(gomock.Any()).Return(nil).SetArg(0, newTestUpdateRegistry())
+	mockValue.EXPECT().Version().Return(2)
+
+	var updated *nsproto.Registry
+	mockKV.EXPECT().Get(M3DBNodeNamespacesKey).Return(mockValue, nil)
+	mockKV.EXPECT().CheckAndSet(M3DBNodeNamespacesKey, 2, gomock.Any()).DoAndReturn(
+		func(_ string, _ int, reg *nsproto.Registry) (int, error) {
+			updated = reg
+			return 3, nil
+		})
+	updateHandler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	body, _ := ioutil.ReadAll(resp.Body)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	var updateResp UpdateResponse
+	require.NoError(t, json.Unmarshal(body, &updateResp))
+	assert.False(t, updateResp.DryRun)
+	assert.Equal(t, []OptionChange{
+		{Option: "repairEnabled", From: "false", To: "true"},
+		{Option: "retentionOptions.retentionPeriodNanos", From: "48h0m0s", To: "96h0m0s"},
+	}, updateResp.Changes)
+
+	require.NotNil(t, updated)
+	opts := updated.Namespaces["testNamespace"]
+	assert.True(t, opts.RepairEnabled)
+	assert.True(t, opts.FlushEnabled)
+	assert.Equal(t, int64(345600000000000), opts.RetentionOptions.RetentionPeriodNanos)
+	assert.Equal(t, int64(600000000000), opts.RetentionOptions.BufferPastNanos)
+}
+
+func TestNamespaceUpdateHandlerDryRun(t *testing.T) {
+	mockClient, mockKV, ctrl := SetupNamespaceTest(t)
+	updateHandler := NewUpdateHandler(mockClient)
+
+	w := httptest.NewRecorder()
+	req := newTestUpdateRequest("testNamespace", `
+        {
+            "options": {"indexOptions": {"enabled": false}},
+            "dryRun": true
+        }
+    `)
+
+	mockValue := kv.NewMockValue(ctrl)
+	mockValue.EXPECT().Unmarshal(gomock.Any()).Return(nil).SetArg(0, newTestUpdateRegistry())
+	mockValue.EXPECT().Version().Return(2)
+
+	mockKV.EXPECT().Get(M3DBNodeNamespacesKey).Return(mockValue, nil)
+	updateHandler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	body, _ := ioutil.ReadAll(resp.Body)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	var updateResp UpdateResponse
+	require.NoError(t, json.Unmarshal(body, &updateResp))
+	assert.True(t, updateResp.DryRun)
+	assert.Equal(t, []OptionChange{
+		{Option: "indexOptions.enabled", From: "true", To: "false"},
+	}, updateResp.Changes)
+}
+
+func TestNamespaceUpdateHandlerInvalid(t *testing.T) {
+	mockClient, mockKV, ctrl := SetupNamespaceTest(t)
+	updateHandler := NewUpdateHandler(mockClient)
+
+	tests := []struct {
+		name  string
+		id    string
+		body  string
+		code  int
+		error string
+	}{
+		{
+			name:  "unknown namespace",
+			id:    "nope",
+			body:  `{"options": {"repairEnabled": true}}`,
+			code:  http.StatusNotFound,
+			error: "unable to find a namespace with specified name",
+		},
+		{
+			name:  "block size",
+			id:    "testNamespace",
+			body:  `{"options": {"retentionOptions": {"blockSizeNanos": 14400000000000}}}`,
+			code:  http.StatusBadRequest,
+			error: "unable to change the block size of an existing namespace",
+		},
+		{
+			name:  "index block size",
+			id:    "testNamespace",
+			body:  `{"options": {"indexOptions": {"blockSizeNanos": 14400000000000}}}`,
+			code:  http.StatusBadRequest,
+			error: "unable to change the index block size of a namespace with indexing enabled",
+		},
+		{
+			name:  "retention shorter than block size",
+			id:    "testNamespace",
+			body:  `{"options": {"retentionOptions": {"retentionPeriodNanos": 3600000000000}}}`,
+			code:  http.StatusBadRequest,
+			error: "retention period must not be smaller than block size",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockValue := kv.NewMockValue(ctrl)
+			mockValue.EXPECT().Unmarshal(gomock.Any()).Return(nil).SetArg(0, newTestUpdateRegistry())
+			mockValue.EXPECT().Version().Return(2)
+			mockKV.EXPECT().Get(M3DBNodeNamespacesKey).Return(mockValue, nil)
+
+			w := httptest.NewRecorder()
+			updateHandler.ServeHTTP(w, newTestUpdateRequest(test.id, test.body))
+
+			resp := w.Result()
+			body, _ := ioutil.ReadAll(resp.Body)
+			assert.Equal(t, test.code, resp.StatusCode)
+			assert.Equal(t, "{\"error\":\""+test.error+"\"}\n", string(body))
+		})
+	}
+}
+
+func TestNamespaceUpdateHandlerConflict(t *testing.T) {
+	mockClient, mockKV, ctrl := SetupNamespaceTest(t)
+	updateHandler := NewUpdateHandler(mockClient)
+
+	mockValue := kv.NewMockValue(ctrl)
+	mockValue.EXPECT().Unmarshal(gomock.Any()).Return(nil).SetArg(0, newTestUpdateRegistry())
+	mockValue.EXPECT().Version().Return(2)
+
+	mockKV.EXPECT().Get(M3DBNodeNamespacesKey).Return(mockValue, nil)
+	mockKV.EXPECT().CheckAndSet(M3DBNodeNamespacesKey, 2, gomock.Any()).Return(0, kv.ErrVersionMismatch)
+
+	w := httptest.NewRecorder()
+	updateHandler.ServeHTTP(w, newTestUpdateRequest("testNamespace", `{"options": {"repairEnabled": true}}`))
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
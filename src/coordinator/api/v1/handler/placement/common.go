@@ -53,9 +53,20 @@ type Handler struct {
 
 // Service gets a placement service from m3cluster client
 func Service(clusterClient clusterclient.Client, cfg config.Configuration) (placement.Service, error) {
+	ps, _, err := ServiceWithOptions(clusterClient, cfg)
+	return ps, err
+}
+
+// ServiceWithOptions gets a placement service from m3cluster client along
+// with the placement options it uses, so that changes to the placement can be
+// computed the same way without committing them
+func ServiceWithOptions(
+	clusterClient clusterclient.Client,
+	cfg config.Configuration,
+) (placement.Service, placement.Options, error) {
 	cs, err := clusterClient.Services(services.NewOverrideOptions())
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	serviceName := DefaultServiceName
@@ -75,12 +86,13 @@ func Service(clusterClient clusterclient.Client, cfg config.Configuration) (plac
 		SetEnvironment(serviceEnvironment).
 		SetZone(serviceZone)
 
-	ps, err := cs.PlacementService(sid, placement.NewOptions().SetValidZone(serviceZone))
+	opts := placement.NewOptions().SetValidZone(serviceZone)
+	ps, err := cs.PlacementService(sid, opts)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return ps, nil
+	return ps, opts, nil
 }
 
 // ConvertInstancesProto converts a slice of protobuf `Instance`s to `placement.Instance`s
@@ -119,4 +131,7 @@ func RegisterRoutes(r *mux.Router, client clusterclient.Client, cfg config.Confi
 	r.HandleFunc(DeleteAllURL, logged(NewDeleteAllHandler(client, cfg)).ServeHTTP).Methods("DELETE")
 	r.HandleFunc(AddURL, logged(NewAddHandler(client, cfg)).ServeHTTP).Methods("POST")
 	r.HandleFunc(DeleteURL, logged(NewDeleteHandler(client, cfg)).ServeHTTP).Methods("DELETE")
+	r.HandleFunc(ReplaceURL, logged(NewReplaceHandler(client, cfg)).ServeHTTP).Methods("POST")
+	r.HandleFunc(RemoveURL, logged(NewRemoveHandler(client, cfg)).ServeHTTP).Methods("POST")
+	r.HandleFunc(MarkAvailableURL, logged(NewMarkAvailableHandler(client, cfg)).ServeHTTP).Methods("POST")
 }
@@ -0,0 +1,112 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package placement
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	clusterclient "github.com/m3db/m3cluster/client"
+	"github.com/m3db/m3cluster/placement"
+	"github.com/m3db/m3cluster/placement/algo"
+	"github.com/m3db/m3cluster/shard"
+	"github.com/m3db/m3db/src/cmd/services/m3coordinator/config"
+	"github.com/m3db/m3db/src/coordinator/api/v1/handler"
+)
+
+const (
+	// MarkAvailableURL is the url for the placement mark available handler (with the POST method).
+	MarkAvailableURL = handler.RoutePrefixV1 + "/placement/mark-available"
+)
+
+var (
+	errNoInstanceForShards = errors.New("must specify the instance of the shards to mark available")
+)
+
+// MarkAvailableRequest is the request to mark initializing shards of the
+// placement as available, completing their handoff from the instances they
+// are taken over from.
+type MarkAvailableRequest struct {
+	OperationRequest
+
+	// InstanceID is the ID of the instance whose shards are marked available,
+	// if empty the initializing shards of all instances are.
+	InstanceID string `json:"instanceId"`
+
+	// ShardIDs are the IDs of the shards of the instance to mark available,
+	// if empty all of its initializing shards are.
+	ShardIDs []uint32 `json:"shardIds"`
+}
+
+type markAvailableHandler Handler
+
+// NewMarkAvailableHandler returns a new instance of a placement mark available handler.
+func NewMarkAvailableHandler(client clusterclient.Client, cfg config.Configuration) http.Handler {
+	return &markAvailableHandler{client: client, cfg: cfg}
+}
+
+func (h *markAvailableHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req MarkAvailableRequest
+	if rErr := parseOperationRequest(r, &req); rErr != nil {
+		handler.Error(w, rErr.Error(), rErr.Code())
+		return
+	}
+
+	if req.InstanceID == "" && len(req.ShardIDs) > 0 {
+		handler.Error(w, errNoInstanceForShards, http.StatusBadRequest)
+		return
+	}
+
+	serveOperation(w, r, h.client, h.cfg, req.OperationRequest,
+		func(opts placement.Options, current placement.Placement) (placement.Placement, error) {
+			return markAvailable(algo.NewAlgorithm(opts), current, req.InstanceID, req.ShardIDs)
+		})
+}
+
+func markAvailable(
+	algo placement.Algorithm,
+	current placement.Placement,
+	instanceID string,
+	shardIDs []uint32,
+) (placement.Placement, error) {
+	if instanceID == "" {
+		p, _, err := algo.MarkAllShardsAvailable(current)
+		return p, err
+	}
+
+	if len(shardIDs) == 0 {
+		instance, ok := current.Instance(instanceID)
+		if !ok {
+			return nil, fmt.Errorf("instance %s is not in the placement", instanceID)
+		}
+
+		for _, s := range instance.Shards().ShardsForState(shard.Initializing) {
+			shardIDs = append(shardIDs, s.ID())
+		}
+
+		if len(shardIDs) == 0 {
+			return nil, fmt.Errorf("instance %s has no initializing shards", instanceID)
+		}
+	}
+
+	return algo.MarkShardsAvailable(current, instanceID, shardIDs...)
+}
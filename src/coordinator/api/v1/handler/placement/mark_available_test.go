@@ -0,0 +1,95 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package placement
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m3db/m3cluster/placement"
+	"github.com/m3db/m3cluster/shard"
+	"github.com/m3db/m3db/src/cmd/services/m3coordinator/config"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestHandoffPlacement returns a placement in which shard 0 is being
+// handed off from host1 to host2
+func newTestHandoffPlacement() placement.Placement {
+	p := newTestPlacement()
+	host1, _ := p.Instance("host1")
+	host1.SetShards(shard.NewShards([]shard.Shard{shard.NewShard(0).SetState(shard.Leaving)}))
+
+	host2, _ := p.Instance("host2")
+	host2.SetShards(shard.NewShards([]shard.Shard{
+		shard.NewShard(0).SetState(shard.Initializing).SetSourceID("host1"),
+		shard.NewShard(1).SetState(shard.Available),
+	}))
+
+	return p
+}
+
+func TestPlacementMarkAvailableHandler(t *testing.T) {
+	mockClient, mockPlacementService := SetupPlacementTest(t)
+	handler := NewMarkAvailableHandler(mockClient, config.Configuration{})
+
+	// Test marking the initializing shards of an instance available
+	w := httptest.NewRecorder()
+	req := newTestOperationRequest(t, MarkAvailableURL, MarkAvailableRequest{InstanceID: "host2"})
+	mockPlacementService.EXPECT().Placement().Return(newTestHandoffPlacement(), 5, nil)
+	mockPlacementService.EXPECT().CheckAndSet(gomock.Any(), 5).Return(nil)
+	handler.ServeHTTP(w, req)
+
+	resp := parseOperationResponse(t, w)
+	require.Len(t, resp.Changes, 2)
+	assert.Equal(t, "host1", resp.Changes[0].ID)
+	assert.Equal(t, []ShardChange{{ID: 0, From: "LEAVING"}}, resp.Changes[0].Shards)
+	assert.Equal(t, "host2", resp.Changes[1].ID)
+	assert.Equal(t, []ShardChange{{ID: 0, From: "INITIALIZING", To: "AVAILABLE", SourceID: "host1"}}, resp.Changes[1].Shards)
+
+	// Test dry run of marking all shards available
+	w = httptest.NewRecorder()
+	req = newTestOperationRequest(t, MarkAvailableURL, MarkAvailableRequest{
+		OperationRequest: OperationRequest{DryRun: true},
+	})
+	mockPlacementService.EXPECT().Placement().Return(newTestHandoffPlacement(), 5, nil)
+	handler.ServeHTTP(w, req)
+
+	resp = parseOperationResponse(t, w)
+	assert.True(t, resp.DryRun)
+	assert.Len(t, resp.Changes, 2)
+
+	// Test an instance without initializing shards
+	w = httptest.NewRecorder()
+	req = newTestOperationRequest(t, MarkAvailableURL, MarkAvailableRequest{InstanceID: "host1"})
+	mockPlacementService.EXPECT().Placement().Return(newTestHandoffPlacement(), 5, nil)
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Test shards without an instance
+	w = httptest.NewRecorder()
+	req = newTestOperationRequest(t, MarkAvailableURL, MarkAvailableRequest{ShardIDs: []uint32{0}})
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,289 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package placement
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+
+	clusterclient "github.com/m3db/m3cluster/client"
+	"github.com/m3db/m3cluster/generated/proto/placementpb"
+	"github.com/m3db/m3cluster/kv"
+	"github.com/m3db/m3cluster/placement"
+	"github.com/m3db/m3db/src/cmd/services/m3coordinator/config"
+	"github.com/m3db/m3db/src/coordinator/api/v1/handler"
+	"github.com/m3db/m3db/src/coordinator/util/logging"
+
+	"github.com/golang/protobuf/jsonpb"
+	"go.uber.org/zap"
+)
+
+// OperationRequest holds the fields common to the requests of the placement
+// operations, which are computed against the current placement and then
+// committed only if the placement has not changed in the meantime.
+type OperationRequest struct {
+	// Version is the version of the placement the operation was planned
+	// against, the operation fails with a conflict if the placement has
+	// changed since. Zero skips this check.
+	Version int `json:"version"`
+
+	// DryRun returns the placement the operation results in without
+	// committing it.
+	DryRun bool `json:"dryRun"`
+}
+
+// OperationResponse is the response of a placement operation.
+type OperationResponse struct {
+	// Placement is the placement resulting from the operation.
+	Placement json.RawMessage `json:"placement"`
+
+	// Version is the version of the placement, the version it was computed
+	// from for dry runs.
+	Version int `json:"version"`
+
+	// DryRun is true if the placement was not committed.
+	DryRun bool `json:"dryRun"`
+
+	// Changes are the changes to the instances of the placement.
+	Changes []InstanceChange `json:"changes"`
+}
+
+// InstanceChange is the change to an instance between two placements.
+type InstanceChange struct {
+	ID      string        `json:"id"`
+	Added   bool          `json:"added,omitempty"`
+	Removed bool          `json:"removed,omitempty"`
+	Shards  []ShardChange `json:"shards,omitempty"`
+}
+
+// ShardChange is the change to a shard of an instance between two
+// placements, the state before is empty for shards added to the instance
+// and the state after is empty for shards removed from it.
+type ShardChange struct {
+	ID       uint32 `json:"id"`
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+	SourceID string `json:"sourceId,omitempty"`
+}
+
+// operationFn computes the placement resulting from an operation, with the
+// options of the placement service
+type operationFn func(opts placement.Options, current placement.Placement) (placement.Placement, error)
+
+// operationError is an error of a placement operation with its status code
+type operationError struct {
+	err  error
+	code int
+}
+
+func (e *operationError) Error() string {
+	return e.err.Error()
+}
+
+func newOperationError(err error, code int) error {
+	return &operationError{err: err, code: code}
+}
+
+// parseOperationRequest decodes the JSON body of a placement operation
+func parseOperationRequest(r *http.Request, req interface{}) *handler.ParseError {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return handler.NewParseError(err, http.StatusBadRequest)
+	}
+
+	defer r.Body.Close()
+
+	if err := json.Unmarshal(body, req); err != nil {
+		return handler.NewParseError(err, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// applyOperation computes the placement resulting from the operation and,
+// unless this is a dry run, commits it if the placement is still at the
+// version it was computed from
+func applyOperation(
+	client clusterclient.Client,
+	cfg config.Configuration,
+	req OperationRequest,
+	fn operationFn,
+) (*OperationResponse, error) {
+	service, opts, err := ServiceWithOptions(client, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	current, version, err := service.Placement()
+	if err != nil {
+		return nil, newOperationError(fmt.Errorf("no placement found: %v", err), http.StatusNotFound)
+	}
+
+	if req.Version != 0 && req.Version != version {
+		err := fmt.Errorf("placement is at version %d rather than %d", version, req.Version)
+		return nil, newOperationError(err, http.StatusConflict)
+	}
+
+	// Take the proto of the current placement before the algorithm runs in
+	// case it modifies the placement in place
+	currentProto, err := current.Proto()
+	if err != nil {
+		return nil, err
+	}
+
+	newPlacement, err := fn(opts, current)
+	if err != nil {
+		return nil, newOperationError(err, http.StatusBadRequest)
+	}
+
+	newProto, err := newPlacement.Proto()
+	if err != nil {
+		return nil, err
+	}
+
+	if !req.DryRun {
+		if err := service.CheckAndSet(newPlacement, version); err != nil {
+			if err == kv.ErrVersionMismatch {
+				err := fmt.Errorf("placement changed while applying the operation: %v", err)
+				return nil, newOperationError(err, http.StatusConflict)
+			}
+
+			return nil, err
+		}
+
+		version++
+	}
+
+	var buf bytes.Buffer
+	marshaler := jsonpb.Marshaler{EmitDefaults: true}
+	if err := marshaler.Marshal(&buf, newProto); err != nil {
+		return nil, err
+	}
+
+	return &OperationResponse{
+		Placement: buf.Bytes(),
+		Version:   version,
+		DryRun:    req.DryRun,
+		Changes:   diffPlacements(currentProto, newProto),
+	}, nil
+}
+
+// serveOperation applies the operation and writes its response
+func serveOperation(
+	w http.ResponseWriter,
+	r *http.Request,
+	client clusterclient.Client,
+	cfg config.Configuration,
+	req OperationRequest,
+	fn operationFn,
+) {
+	logger := logging.WithContext(r.Context())
+	resp, err := applyOperation(client, cfg, req, fn)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if opErr, ok := err.(*operationError); ok {
+			code = opErr.code
+		} else {
+			logger.Error("unable to apply placement operation", zap.Any("error", err))
+		}
+
+		handler.Error(w, err, code)
+		return
+	}
+
+	handler.WriteJSONResponse(w, resp, logger)
+}
+
+// diffPlacements returns the changes to the instances between placements,
+// sorted by instance ID
+func diffPlacements(before, after *placementpb.Placement) []InstanceChange {
+	ids := make([]string, 0, len(after.Instances))
+	for id := range after.Instances {
+		ids = append(ids, id)
+	}
+
+	for id := range before.Instances {
+		if _, ok := after.Instances[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Strings(ids)
+
+	changes := make([]InstanceChange, 0, len(ids))
+	for _, id := range ids {
+		b, a := before.Instances[id], after.Instances[id]
+		change := InstanceChange{ID: id}
+		switch {
+		case b == nil:
+			change.Added = true
+			change.Shards = diffShards(nil, a.Shards)
+		case a == nil:
+			change.Removed = true
+			change.Shards = diffShards(b.Shards, nil)
+		default:
+			change.Shards = diffShards(b.Shards, a.Shards)
+		}
+
+		if change.Added || change.Removed || len(change.Shards) > 0 {
+			changes = append(changes, change)
+		}
+	}
+
+	return changes
+}
+
+// diffShards returns the changes to the shards of an instance, sorted by
+// shard ID
+func diffShards(before, after []*placementpb.Shard) []ShardChange {
+	byID := make(map[uint32]*ShardChange, len(after))
+	for _, s := range before {
+		byID[s.Id] = &ShardChange{ID: s.Id, From: s.State.String()}
+	}
+
+	for _, s := range after {
+		change, ok := byID[s.Id]
+		if !ok {
+			change = &ShardChange{ID: s.Id}
+			byID[s.Id] = change
+		}
+
+		change.To = s.State.String()
+		change.SourceID = s.SourceId
+	}
+
+	var changes []ShardChange
+	for _, change := range byID {
+		if change.From != change.To {
+			changes = append(changes, *change)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].ID < changes[j].ID
+	})
+
+	return changes
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package placement
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/m3db/m3cluster/generated/proto/placementpb"
+	"github.com/m3db/m3cluster/placement"
+	"github.com/m3db/m3cluster/shard"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPlacement returns a placement of two shards, each on one of two
+// instances
+func newTestPlacement() placement.Placement {
+	newInstance := func(id string, shardID uint32) placement.Instance {
+		return placement.NewInstance().
+			SetID(id).
+			SetIsolationGroup("rack-" + id).
+			SetZone(DefaultServiceZone).
+			SetWeight(1).
+			SetEndpoint(id + ":9000").
+			SetHostname(id).
+			SetPort(9000).
+			SetShards(shard.NewShards([]shard.Shard{shard.NewShard(shardID).SetState(shard.Available)}))
+	}
+
+	return placement.NewPlacement().
+		SetInstances([]placement.Instance{newInstance("host1", 0), newInstance("host2", 1)}).
+		SetShards([]uint32{0, 1}).
+		SetReplicaFactor(1).
+		SetIsSharded(true)
+}
+
+func newTestOperationRequest(t *testing.T, url string, req interface{}) *http.Request {
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+	return httptest.NewRequest("POST", url, strings.NewReader(string(body)))
+}
+
+func parseOperationResponse(t *testing.T, w *httptest.ResponseRecorder) OperationResponse {
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp OperationResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+func TestDiffPlacements(t *testing.T) {
+	before := &placementpb.Placement{
+		Instances: map[string]*placementpb.Instance{
+			"host1": {Id: "host1", Shards: []*placementpb.Shard{
+				{Id: 0, State: placementpb.ShardState_AVAILABLE},
+				{Id: 1, State: placementpb.ShardState_AVAILABLE},
+			}},
+			"host2": {Id: "host2", Shards: []*placementpb.Shard{
+				{Id: 2, State: placementpb.ShardState_AVAILABLE},
+			}},
+			"host3": {Id: "host3", Shards: []*placementpb.Shard{
+				{Id: 3, State: placementpb.ShardState_LEAVING},
+			}},
+		},
+	}
+	after := &placementpb.Placement{
+		Instances: map[string]*placementpb.Instance{
+			"host1": {Id: "host1", Shards: []*placementpb.Shard{
+				{Id: 0, State: placementpb.ShardState_AVAILABLE},
+				{Id: 1, State: placementpb.ShardState_LEAVING},
+			}},
+			"host2": {Id: "host2", Shards: []*placementpb.Shard{
+				{Id: 2, State: placementpb.ShardState_AVAILABLE},
+				{Id: 3, State: placementpb.ShardState_AVAILABLE, SourceId: "host3"},
+			}},
+			"host4": {Id: "host4", Shards: []*placementpb.Shard{
+				{Id: 1, State: placementpb.ShardState_INITIALIZING, SourceId: "host1"},
+			}},
+		},
+	}
+
+	assert.Equal(t, []InstanceChange{
+		{ID: "host1", Shards: []ShardChange{{ID: 1, From: "AVAILABLE", To: "LEAVING"}}},
+		{ID: "host2", Shards: []ShardChange{{ID: 3, To: "AVAILABLE", SourceID: "host3"}}},
+		{ID: "host3", Removed: true, Shards: []ShardChange{{ID: 3, From: "LEAVING"}}},
+		{ID: "host4", Added: true, Shards: []ShardChange{{ID: 1, To: "INITIALIZING", SourceID: "host1"}}},
+	}, diffPlacements(before, after))
+
+	assert.Empty(t, diffPlacements(before, before))
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package placement
+
+import (
+	"errors"
+	"net/http"
+
+	clusterclient "github.com/m3db/m3cluster/client"
+	"github.com/m3db/m3cluster/placement"
+	"github.com/m3db/m3cluster/placement/algo"
+	"github.com/m3db/m3db/src/cmd/services/m3coordinator/config"
+	"github.com/m3db/m3db/src/coordinator/api/v1/handler"
+)
+
+const (
+	// RemoveURL is the url for the placement remove handler (with the POST method).
+	RemoveURL = handler.RoutePrefixV1 + "/placement/remove"
+)
+
+var (
+	errNoInstancesToRemove = errors.New("must specify the IDs of the instances to remove")
+)
+
+// RemoveRequest is the request to remove instances from the placement. Their
+// shards are handed off to the remaining instances, the removed instances
+// leaving the placement once the handed off shards are marked available.
+type RemoveRequest struct {
+	OperationRequest
+
+	// InstanceIDs are the IDs of the instances to remove.
+	InstanceIDs []string `json:"instanceIds"`
+}
+
+type removeHandler Handler
+
+// NewRemoveHandler returns a new instance of a placement remove handler.
+func NewRemoveHandler(client clusterclient.Client, cfg config.Configuration) http.Handler {
+	return &removeHandler{client: client, cfg: cfg}
+}
+
+func (h *removeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req RemoveRequest
+	if rErr := parseOperationRequest(r, &req); rErr != nil {
+		handler.Error(w, rErr.Error(), rErr.Code())
+		return
+	}
+
+	if len(req.InstanceIDs) == 0 {
+		handler.Error(w, errNoInstancesToRemove, http.StatusBadRequest)
+		return
+	}
+
+	serveOperation(w, r, h.client, h.cfg, req.OperationRequest,
+		func(opts placement.Options, current placement.Placement) (placement.Placement, error) {
+			return algo.NewAlgorithm(opts).RemoveInstances(current, req.InstanceIDs)
+		})
+}
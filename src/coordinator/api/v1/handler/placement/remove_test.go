@@ -0,0 +1,125 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package placement
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m3db/m3cluster/kv"
+	"github.com/m3db/m3db/src/cmd/services/m3coordinator/config"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlacementRemoveHandler(t *testing.T) {
+	mockClient, mockPlacementService := SetupPlacementTest(t)
+	handler := NewRemoveHandler(mockClient, config.Configuration{})
+
+	// Test remove success, the shards of the removed instance are handed off
+	w := httptest.NewRecorder()
+	req := newTestOperationRequest(t, RemoveURL, RemoveRequest{
+		OperationRequest: OperationRequest{Version: 3},
+		InstanceIDs:      []string{"host1"},
+	})
+	mockPlacementService.EXPECT().Placement().Return(newTestPlacement(), 3, nil)
+	mockPlacementService.EXPECT().CheckAndSet(gomock.Any(), 3).Return(nil)
+	handler.ServeHTTP(w, req)
+
+	resp := parseOperationResponse(t, w)
+	assert.Equal(t, 4, resp.Version)
+	assert.False(t, resp.DryRun)
+	require.NotEmpty(t, resp.Changes)
+	assert.Equal(t, "host1", resp.Changes[0].ID)
+	assert.Equal(t, []ShardChange{{ID: 0, From: "AVAILABLE", To: "LEAVING"}}, resp.Changes[0].Shards)
+	assert.True(t, json.Valid(resp.Placement))
+
+	// Test dry run, which does not commit the placement
+	w = httptest.NewRecorder()
+	req = newTestOperationRequest(t, RemoveURL, RemoveRequest{
+		OperationRequest: OperationRequest{DryRun: true},
+		InstanceIDs:      []string{"host1"},
+	})
+	mockPlacementService.EXPECT().Placement().Return(newTestPlacement(), 3, nil)
+	handler.ServeHTTP(w, req)
+
+	resp = parseOperationResponse(t, w)
+	assert.Equal(t, 3, resp.Version)
+	assert.True(t, resp.DryRun)
+	assert.NotEmpty(t, resp.Changes)
+
+	// Test missing instances
+	w = httptest.NewRecorder()
+	req = newTestOperationRequest(t, RemoveURL, RemoveRequest{})
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Test unknown instance
+	w = httptest.NewRecorder()
+	req = newTestOperationRequest(t, RemoveURL, RemoveRequest{InstanceIDs: []string{"nope"}})
+	mockPlacementService.EXPECT().Placement().Return(newTestPlacement(), 3, nil)
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Test no placement
+	w = httptest.NewRecorder()
+	req = newTestOperationRequest(t, RemoveURL, RemoveRequest{InstanceIDs: []string{"host1"}})
+	mockPlacementService.EXPECT().Placement().Return(nil, 0, errors.New("key not found"))
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPlacementRemoveHandlerConflicts(t *testing.T) {
+	mockClient, mockPlacementService := SetupPlacementTest(t)
+	handler := NewRemoveHandler(mockClient, config.Configuration{})
+
+	// Test the placement changed since the operation was planned
+	w := httptest.NewRecorder()
+	req := newTestOperationRequest(t, RemoveURL, RemoveRequest{
+		OperationRequest: OperationRequest{Version: 2},
+		InstanceIDs:      []string{"host1"},
+	})
+	mockPlacementService.EXPECT().Placement().Return(newTestPlacement(), 3, nil)
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "{\"error\":\"placement is at version 3 rather than 2\"}\n", w.Body.String())
+
+	// Test the placement changed while the operation was applied
+	w = httptest.NewRecorder()
+	req = newTestOperationRequest(t, RemoveURL, RemoveRequest{InstanceIDs: []string{"host1"}})
+	mockPlacementService.EXPECT().Placement().Return(newTestPlacement(), 3, nil)
+	mockPlacementService.EXPECT().CheckAndSet(gomock.Any(), 3).Return(kv.ErrVersionMismatch)
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Test the placement failed to be written
+	w = httptest.NewRecorder()
+	req = newTestOperationRequest(t, RemoveURL, RemoveRequest{InstanceIDs: []string{"host1"}})
+	mockPlacementService.EXPECT().Placement().Return(newTestPlacement(), 3, nil)
+	mockPlacementService.EXPECT().CheckAndSet(gomock.Any(), 3).Return(errors.New("unavailable"))
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
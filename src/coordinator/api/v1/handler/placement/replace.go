@@ -0,0 +1,100 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package placement
+
+import (
+	"errors"
+	"net/http"
+
+	clusterclient "github.com/m3db/m3cluster/client"
+	"github.com/m3db/m3cluster/generated/proto/placementpb"
+	"github.com/m3db/m3cluster/placement"
+	"github.com/m3db/m3cluster/placement/algo"
+	"github.com/m3db/m3cluster/placement/selector"
+	"github.com/m3db/m3db/src/cmd/services/m3coordinator/config"
+	"github.com/m3db/m3db/src/coordinator/api/v1/handler"
+)
+
+const (
+	// ReplaceURL is the url for the placement replace handler (with the POST method).
+	ReplaceURL = handler.RoutePrefixV1 + "/placement/replace"
+)
+
+var (
+	errNoLeavingInstances = errors.New("must specify the IDs of the instances to replace")
+	errNoCandidates       = errors.New("must specify the instances replacing them")
+)
+
+// ReplaceRequest is the request to replace instances of the placement, the
+// shards of the leaving instances are handed off to the new instances.
+type ReplaceRequest struct {
+	OperationRequest
+
+	// LeavingInstanceIDs are the IDs of the instances to replace.
+	LeavingInstanceIDs []string `json:"leavingInstanceIds"`
+
+	// Candidates are the instances replacing them.
+	Candidates []*placementpb.Instance `json:"candidates"`
+}
+
+type replaceHandler Handler
+
+// NewReplaceHandler returns a new instance of a placement replace handler.
+func NewReplaceHandler(client clusterclient.Client, cfg config.Configuration) http.Handler {
+	return &replaceHandler{client: client, cfg: cfg}
+}
+
+func (h *replaceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req ReplaceRequest
+	if rErr := parseOperationRequest(r, &req); rErr != nil {
+		handler.Error(w, rErr.Error(), rErr.Code())
+		return
+	}
+
+	switch {
+	case len(req.LeavingInstanceIDs) == 0:
+		handler.Error(w, errNoLeavingInstances, http.StatusBadRequest)
+		return
+	case len(req.Candidates) == 0:
+		handler.Error(w, errNoCandidates, http.StatusBadRequest)
+		return
+	}
+
+	candidates, err := ConvertInstancesProto(req.Candidates)
+	if err != nil {
+		handler.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	serveOperation(w, r, h.client, h.cfg, req.OperationRequest,
+		func(opts placement.Options, current placement.Placement) (placement.Placement, error) {
+			// Select the candidates as the placement service does, matching
+			// the isolation groups and weights of the leaving instances and
+			// leaving out any extra candidates
+			selected, err := selector.NewInstanceSelector(opts).
+				SelectReplaceInstances(candidates, req.LeavingInstanceIDs, current)
+			if err != nil {
+				return nil, err
+			}
+
+			return algo.NewAlgorithm(opts).ReplaceInstances(current, req.LeavingInstanceIDs, selected)
+		})
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package placement
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m3db/m3cluster/generated/proto/placementpb"
+	"github.com/m3db/m3db/src/cmd/services/m3coordinator/config"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlacementReplaceHandler(t *testing.T) {
+	mockClient, mockPlacementService := SetupPlacementTest(t)
+	handler := NewReplaceHandler(mockClient, config.Configuration{})
+
+	candidate := &placementpb.Instance{
+		Id:             "host3",
+		IsolationGroup: "rack-host3",
+		Zone:           DefaultServiceZone,
+		Weight:         1,
+		Endpoint:       "host3:9000",
+		Hostname:       "host3",
+		Port:           9000,
+	}
+
+	// Test replace success, the new instance takes over the shards
+	w := httptest.NewRecorder()
+	req := newTestOperationRequest(t, ReplaceURL, ReplaceRequest{
+		LeavingInstanceIDs: []string{"host1"},
+		Candidates:         []*placementpb.Instance{candidate},
+	})
+	mockPlacementService.EXPECT().Placement().Return(newTestPlacement(), 1, nil)
+	mockPlacementService.EXPECT().CheckAndSet(gomock.Any(), 1).Return(nil)
+	handler.ServeHTTP(w, req)
+
+	resp := parseOperationResponse(t, w)
+	assert.Equal(t, 2, resp.Version)
+
+	var added *InstanceChange
+	for i := range resp.Changes {
+		if resp.Changes[i].ID == "host3" {
+			added = &resp.Changes[i]
+		}
+	}
+
+	require.NotNil(t, added)
+	assert.True(t, added.Added)
+	assert.Equal(t, []ShardChange{{ID: 0, To: "INITIALIZING", SourceID: "host1"}}, added.Shards)
+
+	// Test the candidates are selected as by the placement service, leaving
+	// out the extra candidate
+	extra := *candidate
+	extra.Id, extra.IsolationGroup, extra.Hostname, extra.Endpoint = "host4", "rack-host4", "host4", "host4:9000"
+	w = httptest.NewRecorder()
+	req = newTestOperationRequest(t, ReplaceURL, ReplaceRequest{
+		OperationRequest:   OperationRequest{DryRun: true},
+		LeavingInstanceIDs: []string{"host1"},
+		Candidates:         []*placementpb.Instance{candidate, &extra},
+	})
+	mockPlacementService.EXPECT().Placement().Return(newTestPlacement(), 1, nil)
+	handler.ServeHTTP(w, req)
+
+	resp = parseOperationResponse(t, w)
+	var numAdded int
+	for _, change := range resp.Changes {
+		if change.Added {
+			numAdded++
+		}
+	}
+
+	assert.Equal(t, 1, numAdded)
+
+	// Test candidates outside of the zone of the service are rejected
+	otherZone := *candidate
+	otherZone.Zone = "other"
+	w = httptest.NewRecorder()
+	req = newTestOperationRequest(t, ReplaceURL, ReplaceRequest{
+		LeavingInstanceIDs: []string{"host1"},
+		Candidates:         []*placementpb.Instance{&otherZone},
+	})
+	mockPlacementService.EXPECT().Placement().Return(newTestPlacement(), 1, nil)
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Test missing leaving instances and candidates
+	for _, r := range []ReplaceRequest{
+		{Candidates: []*placementpb.Instance{candidate}},
+		{LeavingInstanceIDs: []string{"host1"}},
+	} {
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, newTestOperationRequest(t, ReplaceURL, r))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	}
+}
@@ -22,6 +22,7 @@ package prometheus
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"time"
@@ -43,14 +44,33 @@ type RequestParams struct {
 
 // ParsePromCompressedRequest parses a snappy compressed request from Prometheus
 func ParsePromCompressedRequest(r *http.Request) ([]byte, *handler.ParseError) {
+	compressed, err := ReadPromCompressedRequest(r, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodePromCompressedRequest(compressed)
+}
+
+// ReadPromCompressedRequest reads the body of a snappy compressed request
+// from Prometheus, rejecting requests which decompress to more than maxBytes.
+// A zero maxBytes disables the limit.
+func ReadPromCompressedRequest(r *http.Request, maxBytes int) ([]byte, *handler.ParseError) {
 	body := r.Body
 	if r.Body == nil {
 		err := fmt.Errorf("empty request body")
 		return nil, handler.NewParseError(err, http.StatusBadRequest)
 	}
 	defer body.Close()
-	compressed, err := ioutil.ReadAll(body)
 
+	var reader io.Reader = body
+	if maxBytes > 0 {
+		// Snappy never expands incompressible data by more than a sixth, so
+		// larger bodies cannot decompress to within the limit
+		reader = io.LimitReader(body, int64(snappy.MaxEncodedLen(maxBytes))+1)
+	}
+
+	compressed, err := ioutil.ReadAll(reader)
 	if err != nil {
 		return nil, handler.NewParseError(err, http.StatusInternalServerError)
 	}
@@ -59,6 +79,23 @@ func ParsePromCompressedRequest(r *http.Request) ([]byte, *handler.ParseError) {
 		return nil, handler.NewParseError(fmt.Errorf("empty request body"), http.StatusBadRequest)
 	}
 
+	if maxBytes > 0 {
+		size, err := snappy.DecodedLen(compressed)
+		if err != nil {
+			return nil, handler.NewParseError(err, http.StatusBadRequest)
+		}
+
+		if size > maxBytes || len(compressed) > snappy.MaxEncodedLen(maxBytes) {
+			err := fmt.Errorf("request exceeds the limit of %d bytes", maxBytes)
+			return nil, handler.NewParseError(err, http.StatusRequestEntityTooLarge)
+		}
+	}
+
+	return compressed, nil
+}
+
+// DecodePromCompressedRequest decompresses a snappy compressed request body
+func DecodePromCompressedRequest(compressed []byte) ([]byte, *handler.ParseError) {
 	reqBuf, err := snappy.Decode(nil, compressed)
 	if err != nil {
 		return nil, handler.NewParseError(err, http.StatusBadRequest)
@@ -71,6 +108,11 @@ func ParsePromCompressedRequest(r *http.Request) ([]byte, *handler.ParseError) {
 func ParseRequestParams(r *http.Request) (*RequestParams, error) {
 	var params RequestParams
 	timeout := r.Header.Get("timeout")
+	if timeout == "" {
+		// Prometheus API clients pass the timeout as a URL parameter
+		timeout = r.URL.Query().Get("timeout")
+	}
+
 	if timeout != "" {
 		duration, err := time.ParseDuration(timeout)
 		if err != nil {
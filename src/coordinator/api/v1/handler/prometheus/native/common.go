@@ -0,0 +1,251 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/api/v1/handler/prometheus"
+	coordErrors "github.com/m3db/m3db/src/coordinator/errors"
+	"github.com/m3db/m3db/src/coordinator/models"
+
+	"github.com/prometheus/common/model"
+)
+
+const (
+	queryParam = "query"
+	timeParam  = "time"
+	startParam = "start"
+	endParam   = "end"
+	stepParam  = "step"
+	matchParam = "match[]"
+
+	// explainParam returns how the query would be executed instead of its result
+	explainParam = "explain"
+	// traceParam returns how the query was executed along with its result
+	traceParam = "trace"
+
+	// maxPointsPerSeries is the maximum resolution of a range query, matching Prometheus
+	maxPointsPerSeries = 11000
+
+	// instantQueryStep is the consolidation step used for instant queries
+	instantQueryStep = time.Second
+)
+
+var (
+	errNoQuery        = errors.New("no query provided")
+	errNoMatchers     = errors.New("no match[] parameter provided")
+	errNoLabelName    = errors.New("no label name provided")
+	errEndBeforeStart = errors.New("end timestamp must not be before start time")
+	errInvalidStep    = errors.New("zero or negative query resolution step widths are not accepted, try a positive integer")
+	errTooManyPoints  = fmt.Errorf("exceeded maximum resolution of %d points per timeseries, try decreasing the query resolution (?step=XX)", maxPointsPerSeries)
+
+	// minTime is the default start of metadata queries
+	minTime = time.Unix(0, 0)
+)
+
+// parseInstantParams parses the parameters of an instant query
+func parseInstantParams(r *http.Request, now time.Time) (models.RequestParams, error) {
+	query := r.FormValue(queryParam)
+	if query == "" {
+		return models.RequestParams{}, errNoQuery
+	}
+
+	t, err := parseTimeParam(r, timeParam, now)
+	if err != nil {
+		return models.RequestParams{}, err
+	}
+
+	timeout, err := parseTimeout(r)
+	if err != nil {
+		return models.RequestParams{}, err
+	}
+
+	return models.RequestParams{
+		Start:   t,
+		End:     t.Add(instantQueryStep),
+		Now:     now,
+		Step:    instantQueryStep,
+		Timeout: timeout,
+		Query:   query,
+	}, nil
+}
+
+// parseRangeParams parses the parameters of a range query
+func parseRangeParams(r *http.Request, now time.Time) (models.RequestParams, error) {
+	query := r.FormValue(queryParam)
+	if query == "" {
+		return models.RequestParams{}, errNoQuery
+	}
+
+	start, err := parseRequiredTimeParam(r, startParam)
+	if err != nil {
+		return models.RequestParams{}, err
+	}
+
+	end, err := parseRequiredTimeParam(r, endParam)
+	if err != nil {
+		return models.RequestParams{}, err
+	}
+
+	if end.Before(start) {
+		return models.RequestParams{}, errEndBeforeStart
+	}
+
+	step, err := parseDuration(r.FormValue(stepParam))
+	if err != nil {
+		return models.RequestParams{}, fmt.Errorf("invalid parameter '%s': %v", stepParam, err)
+	}
+
+	if step <= 0 {
+		return models.RequestParams{}, errInvalidStep
+	}
+
+	if end.Sub(start)/step > maxPointsPerSeries {
+		return models.RequestParams{}, errTooManyPoints
+	}
+
+	timeout, err := parseTimeout(r)
+	if err != nil {
+		return models.RequestParams{}, err
+	}
+
+	return models.RequestParams{
+		Start: start,
+		// The end of a Prometheus range query is inclusive
+		End:     end.Add(step),
+		Now:     now,
+		Step:    step,
+		Timeout: timeout,
+		Query:   query,
+	}, nil
+}
+
+func parseTimeout(r *http.Request) (time.Duration, error) {
+	params, err := prometheus.ParseRequestParams(r)
+	if err != nil {
+		return 0, err
+	}
+
+	return params.Timeout, nil
+}
+
+func parseRequiredTimeParam(r *http.Request, key string) (time.Time, error) {
+	value := r.FormValue(key)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("missing parameter '%s'", key)
+	}
+
+	t, err := parseTime(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid parameter '%s': %v", key, err)
+	}
+
+	return t, nil
+}
+
+func parseTimeParam(r *http.Request, key string, defaultTime time.Time) (time.Time, error) {
+	value := r.FormValue(key)
+	if value == "" {
+		return defaultTime, nil
+	}
+
+	t, err := parseTime(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid parameter '%s': %v", key, err)
+	}
+
+	return t, nil
+}
+
+// parseBoolParam parses an optional boolean parameter, false when missing
+func parseBoolParam(r *http.Request, key string) (bool, error) {
+	value := r.FormValue(key)
+	if value == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid parameter '%s': %v", key, err)
+	}
+
+	return b, nil
+}
+
+// parseTime parses either a unix timestamp with optional fractional seconds
+// or an RFC3339 timestamp
+func parseTime(s string) (time.Time, error) {
+	if t, err := strconv.ParseFloat(s, 64); err == nil {
+		seconds, fraction := math.Modf(t)
+		return time.Unix(int64(seconds), int64(fraction*float64(time.Second))), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("cannot parse %q to a valid timestamp", s)
+}
+
+// parseDuration parses either a number of seconds or a Prometheus duration
+func parseDuration(s string) (time.Duration, error) {
+	if d, err := strconv.ParseFloat(s, 64); err == nil {
+		ts := d * float64(time.Second)
+		if ts > float64(math.MaxInt64) || ts < float64(math.MinInt64) {
+			return 0, fmt.Errorf("cannot parse %q to a valid duration, it overflows int64", s)
+		}
+
+		return time.Duration(ts), nil
+	}
+
+	if d, err := model.ParseDuration(s); err == nil {
+		return time.Duration(d), nil
+	}
+
+	return 0, fmt.Errorf("cannot parse %q to a valid duration", s)
+}
+
+// executionErrorType determines the Prometheus error type of a failed query
+func executionErrorType(err error) prometheus.ErrorType {
+	if limitErr, ok := err.(*coordErrors.ResourceLimitError); ok && limitErr.Global() {
+		return prometheus.ErrorUnavailable
+	}
+
+	if coordErrors.IsPartialResultError(err) {
+		return prometheus.ErrorUnavailable
+	}
+
+	switch err {
+	case context.DeadlineExceeded:
+		return prometheus.ErrorTimeout
+	case context.Canceled, coordErrors.ErrQueryInterrupted:
+		return prometheus.ErrorCanceled
+	default:
+		return prometheus.ErrorExec
+	}
+}
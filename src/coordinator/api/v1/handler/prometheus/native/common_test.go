@@ -0,0 +1,139 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/api/v1/handler/prometheus"
+	coordErrors "github.com/m3db/m3db/src/coordinator/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFormRequest(t *testing.T, values url.Values) *http.Request {
+	req, err := http.NewRequest("GET", PromQueryRangeURL+"?"+values.Encode(), nil)
+	require.NoError(t, err)
+	return req
+}
+
+func TestParseRangeParams(t *testing.T) {
+	now := time.Now()
+	req := newFormRequest(t, url.Values{
+		queryParam: []string{"up"},
+		startParam: []string{"1500000000"},
+		endParam:   []string{"2017-07-14T02:50:00Z"},
+		stepParam:  []string{"10s"},
+	})
+
+	params, err := parseRangeParams(req, now)
+	require.NoError(t, err)
+	assert.Equal(t, "up", params.Query)
+	assert.Equal(t, time.Unix(1500000000, 0), params.Start)
+	assert.Equal(t, time.Unix(1500000600, 0).Add(10*time.Second).Unix(), params.End.Unix())
+	assert.Equal(t, 10*time.Second, params.Step)
+	assert.Equal(t, now, params.Now)
+}
+
+func TestParseRangeParamsErrors(t *testing.T) {
+	valid := func() url.Values {
+		return url.Values{
+			queryParam: []string{"up"},
+			startParam: []string{"100"},
+			endParam:   []string{"200"},
+			stepParam:  []string{"15"},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(url.Values)
+	}{
+		{"missing query", func(v url.Values) { v.Del(queryParam) }},
+		{"missing start", func(v url.Values) { v.Del(startParam) }},
+		{"bad end", func(v url.Values) { v.Set(endParam, "tomorrow") }},
+		{"end before start", func(v url.Values) { v.Set(endParam, "50") }},
+		{"zero step", func(v url.Values) { v.Set(stepParam, "0") }},
+		{"bad step", func(v url.Values) { v.Set(stepParam, "often") }},
+		{"too many points", func(v url.Values) { v.Set(stepParam, "0.001") }},
+		{"bad timeout", func(v url.Values) { v.Set("timeout", "soon") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := valid()
+			tt.modify(values)
+			_, err := parseRangeParams(newFormRequest(t, values), time.Now())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseInstantParams(t *testing.T) {
+	now := time.Now()
+	req := newFormRequest(t, url.Values{queryParam: []string{"up"}})
+
+	params, err := parseInstantParams(req, now)
+	require.NoError(t, err)
+	assert.Equal(t, now, params.Start)
+	assert.Equal(t, now.Add(instantQueryStep), params.End)
+	assert.Equal(t, instantQueryStep, params.Step)
+
+	req = newFormRequest(t, url.Values{
+		queryParam: []string{"up"},
+		timeParam:  []string{"1500000000.5"},
+	})
+
+	params, err = parseInstantParams(req, now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(1500000000, int64(500*time.Millisecond)), params.Start)
+}
+
+func TestParseDuration(t *testing.T) {
+	d, err := parseDuration("1.5")
+	require.NoError(t, err)
+	assert.Equal(t, 1500*time.Millisecond, d)
+
+	d, err = parseDuration("5m")
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, d)
+
+	_, err = parseDuration("1e20")
+	assert.Error(t, err)
+}
+
+func TestExecutionErrorType(t *testing.T) {
+	assert.Equal(t, prometheus.ErrorTimeout, executionErrorType(context.DeadlineExceeded))
+	assert.Equal(t, prometheus.ErrorCanceled, executionErrorType(coordErrors.ErrQueryInterrupted))
+	assert.Equal(t, prometheus.ErrorExec, executionErrorType(errors.New("bad query")))
+	assert.Equal(t, prometheus.ErrorExec, executionErrorType(coordErrors.NewResourceLimitError(
+		coordErrors.ResourceSeries, coordErrors.LimitScopeQuery, 10)))
+	assert.Equal(t, prometheus.ErrorUnavailable, executionErrorType(
+		coordErrors.ErrMaxConcurrentQueriesLimitExceeded(10)))
+	assert.Equal(t, prometheus.ErrorUnavailable, executionErrorType(
+		coordErrors.NewPartialResultError([]string{"store_failed: remote store failed"})))
+}
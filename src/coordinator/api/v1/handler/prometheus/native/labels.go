@@ -0,0 +1,158 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/m3db/m3db/src/coordinator/api/v1/handler"
+	"github.com/m3db/m3db/src/coordinator/api/v1/handler/prometheus"
+	"github.com/m3db/m3db/src/coordinator/executor"
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/util/logging"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// PromLabelsURL is the url for the Prometheus label names handler
+	PromLabelsURL = handler.RoutePrefixV1 + "/labels"
+
+	// PromLabelValuesURL is the url for the Prometheus label values handler
+	PromLabelValuesURL = handler.RoutePrefixV1 + "/label/{" + labelNameVar + "}/values"
+
+	labelNameVar = "name"
+
+	anyValueRegex = ".+"
+)
+
+// PromLabelsHandler represents a handler for the Prometheus label names endpoint
+type PromLabelsHandler struct {
+	store  storage.Storage
+	engine *executor.Engine
+}
+
+// NewPromLabelsHandler returns a new instance of handler
+func NewPromLabelsHandler(store storage.Storage, engine *executor.Engine) http.Handler {
+	return &PromLabelsHandler{store: store, engine: engine}
+}
+
+func (h *PromLabelsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.WithContext(ctx)
+
+	start, end, err := parseMetadataRange(r)
+	if err != nil {
+		prometheus.WriteError(w, prometheus.ErrorBadData, err, logger)
+		return
+	}
+
+	matcher, err := models.NewMatcher(models.MatchRegexp, models.MetricName, anyValueRegex)
+	if err != nil {
+		prometheus.WriteError(w, prometheus.ErrorInternal, err, logger)
+		return
+	}
+
+	timeout, err := parseTimeout(r)
+	if err != nil {
+		prometheus.WriteError(w, prometheus.ErrorBadData, err, logger)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	opts, release := h.engine.FetchOptions()
+	defer release()
+
+	result, err := storage.CompleteTags(ctx, h.store, &storage.CompleteTagsQuery{
+		TagMatchers: models.Matchers{matcher},
+		Start:       start,
+		End:         end,
+	}, opts)
+	if err != nil {
+		prometheus.WriteError(w, executionErrorType(err), err, logger)
+		return
+	}
+
+	prometheus.WriteSuccessWithWarnings(w, result.Terms, result.Warnings, logger)
+}
+
+// PromLabelValuesHandler represents a handler for the Prometheus label values endpoint
+type PromLabelValuesHandler struct {
+	store  storage.Storage
+	engine *executor.Engine
+}
+
+// NewPromLabelValuesHandler returns a new instance of handler
+func NewPromLabelValuesHandler(store storage.Storage, engine *executor.Engine) http.Handler {
+	return &PromLabelValuesHandler{store: store, engine: engine}
+}
+
+func (h *PromLabelValuesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.WithContext(ctx)
+
+	name := mux.Vars(r)[labelNameVar]
+	if name == "" {
+		prometheus.WriteError(w, prometheus.ErrorBadData, errNoLabelName, logger)
+		return
+	}
+
+	start, end, err := parseMetadataRange(r)
+	if err != nil {
+		prometheus.WriteError(w, prometheus.ErrorBadData, err, logger)
+		return
+	}
+
+	matcher, err := models.NewMatcher(models.MatchRegexp, name, anyValueRegex)
+	if err != nil {
+		prometheus.WriteError(w, prometheus.ErrorBadData, err, logger)
+		return
+	}
+
+	timeout, err := parseTimeout(r)
+	if err != nil {
+		prometheus.WriteError(w, prometheus.ErrorBadData, err, logger)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	opts, release := h.engine.FetchOptions()
+	defer release()
+
+	result, err := storage.CompleteTags(ctx, h.store, &storage.CompleteTagsQuery{
+		TagMatchers: models.Matchers{matcher},
+		TagName:     name,
+		Start:       start,
+		End:         end,
+	}, opts)
+	if err != nil {
+		prometheus.WriteError(w, executionErrorType(err), err, logger)
+		return
+	}
+
+	prometheus.WriteSuccessWithWarnings(w, result.Terms, result.Warnings, logger)
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m3db/m3db/src/coordinator/cost"
+	"github.com/m3db/m3db/src/coordinator/executor"
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/test/local"
+	"github.com/m3db/m3db/src/coordinator/util/logging"
+	"github.com/m3db/m3db/src/dbnode/client"
+	"github.com/m3db/m3db/src/dbnode/storage/index"
+	"github.com/m3db/m3x/ident"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func newTaggedIDsIter(ctrl *gomock.Controller, tags ...models.Tags) client.TaggedIDsIterator {
+	iter := client.NewMockTaggedIDsIterator(ctrl)
+	for _, t := range tags {
+		iter.EXPECT().Next().Return(true)
+		iter.EXPECT().Current().Return(
+			ident.StringID("metrics"),
+			ident.StringID(t.ID()),
+			storage.TagsToIdentTagIterator(t),
+		)
+	}
+
+	iter.EXPECT().Next().Return(false)
+	return iter
+}
+
+func TestPromLabelValues(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store, session := local.NewStorageAndSession(ctrl)
+	session.EXPECT().Aggregate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, _ ident.ID, _ index.Query, opts index.AggregateQueryOptions) {
+			assert.Equal(t, []byte("job"), opts.FieldName)
+		}).
+		Return([]index.AggregateTerm{
+			{Term: []byte("a"), Count: 2},
+			{Term: []byte("b"), Count: 1},
+		}, true, nil)
+
+	router := mux.NewRouter()
+	router.Handle(PromLabelValuesURL, NewPromLabelValuesHandler(store, executor.NewEngine(store)))
+
+	req, err := http.NewRequest("GET", "/api/v1/label/job/values", nil)
+	require.NoError(t, err)
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+	require.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, `{"status":"success","data":["a","b"]}`, res.Body.String())
+}
+
+func TestPromLabels(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store, session := local.NewStorageAndSession(ctrl)
+	session.EXPECT().Aggregate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, _ ident.ID, _ index.Query, opts index.AggregateQueryOptions) {
+			assert.Nil(t, opts.FieldName)
+		}).
+		Return([]index.AggregateTerm{
+			{Term: []byte("__name__"), Count: 2},
+			{Term: []byte("instance"), Count: 1},
+			{Term: []byte("job"), Count: 1},
+		}, true, nil)
+
+	req, err := http.NewRequest("GET", PromLabelsURL, nil)
+	require.NoError(t, err)
+
+	res := httptest.NewRecorder()
+	NewPromLabelsHandler(store, executor.NewEngine(store)).ServeHTTP(res, req)
+	require.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, `{"status":"success","data":["__name__","instance","job"]}`, res.Body.String())
+}
+
+func TestPromSeriesNoMatchers(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store, _ := local.NewStorageAndSession(ctrl)
+	req, err := http.NewRequest("GET", PromSeriesURL, nil)
+	require.NoError(t, err)
+
+	res := httptest.NewRecorder()
+	NewPromSeriesHandler(store, executor.NewEngine(store)).ServeHTTP(res, req)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+	assert.Contains(t, res.Body.String(), errNoMatchers.Error())
+}
+
+func TestPromSeriesEnforcesLimits(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store, session := local.NewStorageAndSession(ctrl)
+	iter := newTaggedIDsIter(ctrl,
+		models.Tags{"__name__": "up", "job": "a"},
+		models.Tags{"__name__": "up", "job": "b"},
+	)
+	session.EXPECT().FetchTaggedIDs(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(iter, true, nil)
+
+	engine := executor.NewEngineWithLimits(store, executor.Limits{
+		PerQuery: cost.Limits{MaxFetchedSeries: 1},
+	}, tally.NoopScope)
+	req, err := http.NewRequest("GET", PromSeriesURL+"?match[]=up", nil)
+	require.NoError(t, err)
+
+	res := httptest.NewRecorder()
+	NewPromSeriesHandler(store, engine).ServeHTTP(res, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+}
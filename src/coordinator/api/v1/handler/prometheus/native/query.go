@@ -0,0 +1,264 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/api/v1/handler"
+	"github.com/m3db/m3db/src/coordinator/api/v1/handler/prometheus"
+	"github.com/m3db/m3db/src/coordinator/executor"
+	"github.com/m3db/m3db/src/coordinator/functions/utils"
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/parser/promql"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/util/logging"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// PromQueryURL is the url for the Prometheus instant query handler
+	PromQueryURL = handler.RoutePrefixV1 + "/query"
+
+	// PromQueryRangeURL is the url for the Prometheus range query handler
+	PromQueryRangeURL = handler.RoutePrefixV1 + "/query_range"
+
+	resultTypeMatrix = "matrix"
+	resultTypeVector = "vector"
+)
+
+// PromQueryHandler represents a handler for the Prometheus query endpoints
+type PromQueryHandler struct {
+	engine  *executor.Engine
+	instant bool
+}
+
+// NewPromQueryHandler returns a new handler for instant queries
+func NewPromQueryHandler(engine *executor.Engine) http.Handler {
+	return &PromQueryHandler{engine: engine, instant: true}
+}
+
+// NewPromQueryRangeHandler returns a new handler for range queries
+func NewPromQueryRangeHandler(engine *executor.Engine) http.Handler {
+	return &PromQueryHandler{engine: engine}
+}
+
+func (h *PromQueryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.WithContext(ctx)
+
+	var (
+		params models.RequestParams
+		err    error
+	)
+
+	now := time.Now()
+	if h.instant {
+		params, err = parseInstantParams(r, now)
+	} else {
+		params, err = parseRangeParams(r, now)
+	}
+
+	if err != nil {
+		prometheus.WriteError(w, prometheus.ErrorBadData, err, logger)
+		return
+	}
+
+	explain, err := parseBoolParam(r, explainParam)
+	if err != nil {
+		prometheus.WriteError(w, prometheus.ErrorBadData, err, logger)
+		return
+	}
+
+	trace, err := parseBoolParam(r, traceParam)
+	if err != nil {
+		prometheus.WriteError(w, prometheus.ErrorBadData, err, logger)
+		return
+	}
+
+	parser, err := promql.Parse(params.Query)
+	if err != nil {
+		prometheus.WriteError(w, prometheus.ErrorBadData, err, logger)
+		return
+	}
+
+	if explain {
+		explanation, err := h.engine.Explain(parser, params)
+		if err != nil {
+			prometheus.WriteError(w, prometheus.ErrorBadData, err, logger)
+			return
+		}
+
+		prometheus.WriteSuccessWithWarnings(w, explanation, nil, logger)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, params.Timeout)
+	defer cancel()
+
+	// Detect clients closing connections
+	_, closing := handler.CloseWatcher(ctx, w)
+	opts := &executor.EngineOptions{AbortCh: closing}
+	if trace {
+		opts.Trace = executor.NewTrace()
+	}
+
+	blocks, warnings, err := h.engine.ExecuteExpr(ctx, parser, opts, params)
+	if err != nil {
+		logger.Error("unable to execute query", zap.Any("error", err))
+		prometheus.WriteError(w, executionErrorType(err), err, logger)
+		return
+	}
+
+	defer closeBlocks(blocks, logger)
+
+	var data queryData
+	if h.instant {
+		data = renderVector(blocks)
+	} else {
+		data = renderMatrix(blocks)
+	}
+
+	if opts.Trace != nil {
+		result := opts.Trace.Result()
+		data.Trace = &result
+	}
+
+	prometheus.WriteSuccessWithWarnings(w, data, warnings, logger)
+}
+
+func closeBlocks(blocks []storage.Block, logger *zap.Logger) {
+	for _, b := range blocks {
+		if err := b.Close(); err != nil {
+			logger.Warn("unable to close block", zap.Any("error", err))
+		}
+	}
+}
+
+// queryData is the data section of a query response
+type queryData struct {
+	ResultType string      `json:"resultType"`
+	Result     interface{} `json:"result"`
+	// Trace is how the query was executed, only set when requested.
+	Trace *executor.TraceResult `json:"trace,omitempty"`
+}
+
+type matrixSeries struct {
+	Metric models.Tags `json:"metric"`
+	Values []point     `json:"values"`
+}
+
+type vectorSample struct {
+	Metric models.Tags `json:"metric"`
+	Value  point       `json:"value"`
+}
+
+// point is a single sample, marshalled as [<unix seconds>, "<value>"]
+type point struct {
+	t time.Time
+	v float64
+}
+
+func (p point) MarshalJSON() ([]byte, error) {
+	seconds := float64(p.t.UnixNano()) / float64(time.Second)
+	return json.Marshal([]interface{}{
+		json.Number(strconv.FormatFloat(seconds, 'f', -1, 64)),
+		strconv.FormatFloat(p.v, 'f', -1, 64),
+	})
+}
+
+// renderMatrix converts blocks into a Prometheus matrix, dropping missing values
+func renderMatrix(blocks []storage.Block) queryData {
+	result := make([]matrixSeries, 0)
+	for _, b := range blocks {
+		seriesMeta := utils.FlattenMetadata(b.Meta(), b.SeriesMeta())
+		series := make([]matrixSeries, len(seriesMeta))
+		for i, m := range seriesMeta {
+			series[i].Metric = nonNilTags(m.Tags)
+			series[i].Values = make([]point, 0)
+		}
+
+		iter := b.StepIter()
+		for iter.Next() {
+			step := iter.Current()
+			for i, v := range step.Values() {
+				if math.IsNaN(v) || i >= len(series) {
+					continue
+				}
+
+				series[i].Values = append(series[i].Values, point{t: step.Time(), v: v})
+			}
+		}
+
+		for _, s := range series {
+			if len(s.Values) > 0 {
+				result = append(result, s)
+			}
+		}
+	}
+
+	return queryData{ResultType: resultTypeMatrix, Result: result}
+}
+
+// renderVector converts the last step of blocks into a Prometheus vector
+func renderVector(blocks []storage.Block) queryData {
+	result := make([]vectorSample, 0)
+	for _, b := range blocks {
+		seriesMeta := utils.FlattenMetadata(b.Meta(), b.SeriesMeta())
+
+		var last storage.Step
+		iter := b.StepIter()
+		for iter.Next() {
+			last = iter.Current()
+		}
+
+		if last == nil {
+			continue
+		}
+
+		for i, v := range last.Values() {
+			if math.IsNaN(v) || i >= len(seriesMeta) {
+				continue
+			}
+
+			result = append(result, vectorSample{
+				Metric: nonNilTags(seriesMeta[i].Tags),
+				Value:  point{t: last.Time(), v: v},
+			})
+		}
+	}
+
+	return queryData{ResultType: resultTypeVector, Result: result}
+}
+
+func nonNilTags(tags models.Tags) models.Tags {
+	if tags == nil {
+		return models.Tags{}
+	}
+
+	return tags
+}
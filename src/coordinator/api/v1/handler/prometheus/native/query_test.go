@@ -0,0 +1,170 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/executor"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/test"
+	"github.com/m3db/m3db/src/coordinator/test/local"
+	"github.com/m3db/m3db/src/coordinator/util/logging"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBlocks() []storage.Block {
+	bounds := test.NewBounds(time.Unix(100, 0), 10*time.Second, 3)
+	return []storage.Block{test.NewBlockFromValues(bounds, [][]float64{
+		{1, math.NaN(), 3.5},
+		{math.NaN(), math.NaN(), math.NaN()},
+	})}
+}
+
+func TestRenderMatrix(t *testing.T) {
+	b, err := json.Marshal(renderMatrix(testBlocks()))
+	require.NoError(t, err)
+
+	expected := `{"resultType":"matrix","result":[` +
+		`{"metric":{"__name__":"dummy_0"},"values":[[100,"1"],[120,"3.5"]]}]}`
+	assert.Equal(t, expected, string(b))
+}
+
+func TestRenderVector(t *testing.T) {
+	b, err := json.Marshal(renderVector(testBlocks()))
+	require.NoError(t, err)
+
+	expected := `{"resultType":"vector","result":[` +
+		`{"metric":{"__name__":"dummy_0"},"value":[120,"3.5"]}]}`
+	assert.Equal(t, expected, string(b))
+}
+
+func TestPromQueryBadData(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	store, _ := local.NewStorageAndSession(ctrl)
+	h := NewPromQueryRangeHandler(executor.NewEngine(store))
+
+	tests := []url.Values{
+		{queryParam: []string{"up"}},
+		{
+			queryParam: []string{"sum(up"},
+			startParam: []string{"100"},
+			endParam:   []string{"200"},
+			stepParam:  []string{"10"},
+		},
+		{
+			queryParam:   []string{"up"},
+			startParam:   []string{"100"},
+			endParam:     []string{"200"},
+			stepParam:    []string{"10"},
+			explainParam: []string{"maybe"},
+		},
+	}
+
+	for _, values := range tests {
+		req, err := http.NewRequest("GET", PromQueryRangeURL+"?"+values.Encode(), nil)
+		require.NoError(t, err)
+
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, req)
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Contains(t, res.Body.String(), `"errorType":"bad_data"`)
+	}
+}
+
+func TestPromQueryExplain(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	store, _ := local.NewStorageAndSession(ctrl)
+	h := NewPromQueryRangeHandler(executor.NewEngine(store))
+
+	values := url.Values{
+		queryParam:   []string{"sum(up)"},
+		startParam:   []string{"100"},
+		endParam:     []string{"200"},
+		stepParam:    []string{"10"},
+		explainParam: []string{"true"},
+	}
+	req, err := http.NewRequest("GET", PromQueryRangeURL+"?"+values.Encode(), nil)
+	require.NoError(t, err)
+
+	// The session expects no fetches as the query is not executed
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+	var body struct {
+		Status string               `json:"status"`
+		Data   executor.Explanation `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &body))
+	assert.Equal(t, "success", body.Status)
+	assert.Equal(t, "sum(up)", body.Data.Query)
+	require.Len(t, body.Data.Nodes, 2)
+	assert.Equal(t, "fetch", body.Data.Nodes[0].Op)
+	assert.Equal(t, "sum", body.Data.Nodes[1].Op)
+}
+
+func TestPromQueryTrace(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	store, session := local.NewStorageAndSession(ctrl)
+	session.EXPECT().FetchTagged(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(test.NewMockSeriesIters(ctrl, test.GenerateTag()), true, nil)
+	h := NewPromQueryRangeHandler(executor.NewEngine(store))
+
+	values := url.Values{
+		queryParam: []string{"up"},
+		startParam: []string{"100"},
+		endParam:   []string{"200"},
+		stepParam:  []string{"10"},
+		traceParam: []string{"true"},
+	}
+	req, err := http.NewRequest("GET", PromQueryRangeURL+"?"+values.Encode(), nil)
+	require.NoError(t, err)
+
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+	var body struct {
+		Data struct {
+			Trace *executor.TraceResult `json:"trace"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &body))
+	require.NotNil(t, body.Data.Trace)
+	require.Len(t, body.Data.Trace.Nodes, 1)
+	assert.Equal(t, "fetch", body.Data.Trace.Nodes[0].Op)
+	assert.Equal(t, 1, body.Data.Trace.Nodes[0].Blocks)
+	require.Len(t, body.Data.Trace.Spans, 1)
+	assert.Equal(t, "query", body.Data.Trace.Spans[0].Operation)
+}
@@ -83,7 +83,7 @@ func (h *PromReadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	result, err := h.read(ctx, w, req, params)
 	if err != nil {
 		logger.Error("unable to fetch data", zap.Any("error", err))
-		handler.Error(w, err, http.StatusInternalServerError)
+		handler.Error(w, err, handler.ExecutionErrorCode(err))
 		return
 	}
 
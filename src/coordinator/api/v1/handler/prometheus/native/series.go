@@ -0,0 +1,170 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/api/v1/handler"
+	"github.com/m3db/m3db/src/coordinator/api/v1/handler/prometheus"
+	"github.com/m3db/m3db/src/coordinator/executor"
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/parser/promql"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/util/logging"
+)
+
+const (
+	// PromSeriesURL is the url for the Prometheus series metadata handler
+	PromSeriesURL = handler.RoutePrefixV1 + "/series"
+)
+
+// PromSeriesHandler represents a handler for the Prometheus series endpoint
+type PromSeriesHandler struct {
+	store  storage.Storage
+	engine *executor.Engine
+}
+
+// NewPromSeriesHandler returns a new instance of handler
+func NewPromSeriesHandler(store storage.Storage, engine *executor.Engine) http.Handler {
+	return &PromSeriesHandler{store: store, engine: engine}
+}
+
+func (h *PromSeriesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.WithContext(ctx)
+
+	if err := r.ParseForm(); err != nil {
+		prometheus.WriteError(w, prometheus.ErrorBadData, err, logger)
+		return
+	}
+
+	selectors := r.Form[matchParam]
+	if len(selectors) == 0 {
+		prometheus.WriteError(w, prometheus.ErrorBadData, errNoMatchers, logger)
+		return
+	}
+
+	matcherSets := make([]models.Matchers, 0, len(selectors))
+	for _, s := range selectors {
+		matchers, err := promql.ParseMatchers(s)
+		if err != nil {
+			prometheus.WriteError(w, prometheus.ErrorBadData, err, logger)
+			return
+		}
+
+		matcherSets = append(matcherSets, matchers)
+	}
+
+	start, end, err := parseMetadataRange(r)
+	if err != nil {
+		prometheus.WriteError(w, prometheus.ErrorBadData, err, logger)
+		return
+	}
+
+	timeout, err := parseTimeout(r)
+	if err != nil {
+		prometheus.WriteError(w, prometheus.ErrorBadData, err, logger)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	opts, release := h.engine.FetchOptions()
+	defer release()
+
+	metrics, warnings, err := fetchMetrics(ctx, h.store, matcherSets, start, end, opts)
+	if err != nil {
+		prometheus.WriteError(w, executionErrorType(err), err, logger)
+		return
+	}
+
+	result := make([]models.Tags, 0, len(metrics))
+	for _, m := range metrics {
+		result = append(result, nonNilTags(m.Tags))
+	}
+
+	prometheus.WriteSuccessWithWarnings(w, result, warnings, logger)
+}
+
+// parseMetadataRange parses the optional start and end of a metadata query
+func parseMetadataRange(r *http.Request) (time.Time, time.Time, error) {
+	start, err := parseTimeParam(r, startParam, minTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	end, err := parseTimeParam(r, endParam, time.Now())
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, errEndBeforeStart
+	}
+
+	return start, end, nil
+}
+
+// fetchMetrics returns the distinct metrics matching any of the matcher sets
+func fetchMetrics(
+	ctx context.Context,
+	store storage.Storage,
+	matcherSets []models.Matchers,
+	start, end time.Time,
+	options *storage.FetchOptions,
+) (models.Metrics, storage.Warnings, error) {
+	var (
+		seen     = make(map[string]struct{})
+		metrics  models.Metrics
+		warnings storage.Warnings
+	)
+
+	for _, matchers := range matcherSets {
+		query := &storage.FetchQuery{
+			TagMatchers: matchers,
+			Start:       start,
+			End:         end,
+		}
+
+		results, err := store.FetchTags(ctx, query, options)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		warnings = append(warnings, results.Warnings...)
+
+		for _, m := range results.Metrics {
+			id := m.Tags.ID()
+			if _, ok := seen[id]; ok {
+				continue
+			}
+
+			seen[id] = struct{}{}
+			metrics = append(metrics, m)
+		}
+	}
+
+	return metrics, warnings, nil
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remote
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var errLimiterTimeout = errors.New("timed out waiting for resources held by other writes")
+
+// limiter bounds a resource held by writes across all requests, such as
+// the number of datapoints being written. Writes wait in turn for the
+// resource to be released, for up to a timeout. A nil limiter never blocks.
+type limiter struct {
+	sync.Mutex
+
+	size    int64
+	used    int64
+	timeout time.Duration
+	waiters list.List
+}
+
+type limiterWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+// newLimiter returns a limiter of the given size, or nil if size is zero.
+func newLimiter(size int64, timeout time.Duration) *limiter {
+	if size <= 0 {
+		return nil
+	}
+
+	return &limiter{size: size, timeout: timeout}
+}
+
+// acquire takes n units of the resource, waiting for them to be released
+// by other writes if need be. Requests for more than the size of the
+// limiter take all of it. The returned value must be passed to release.
+func (l *limiter) acquire(ctx context.Context, n int64) (int64, error) {
+	if l == nil {
+		return 0, nil
+	}
+
+	if n > l.size {
+		n = l.size
+	}
+
+	l.Lock()
+	if l.used+n <= l.size && l.waiters.Len() == 0 {
+		l.used += n
+		l.Unlock()
+		return n, nil
+	}
+
+	w := limiterWaiter{n: n, ready: make(chan struct{})}
+	elem := l.waiters.PushBack(w)
+	l.Unlock()
+
+	var timeout <-chan time.Time
+	if l.timeout > 0 {
+		timer := time.NewTimer(l.timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	var err error
+	select {
+	case <-w.ready:
+		return n, nil
+	case <-timeout:
+		err = errLimiterTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	l.Lock()
+	defer l.Unlock()
+	select {
+	case <-w.ready:
+		// Acquired while timing out, hand the units back
+		l.used -= n
+	default:
+		l.waiters.Remove(elem)
+	}
+
+	l.notifyWithLock()
+	return 0, err
+}
+
+// release returns n units acquired from the limiter.
+func (l *limiter) release(n int64) {
+	if l == nil || n == 0 {
+		return
+	}
+
+	l.Lock()
+	l.used -= n
+	l.notifyWithLock()
+	l.Unlock()
+}
+
+// notifyWithLock wakes up the waiters in turn for as long as their units
+// are available, so that large acquisitions are not starved by small ones.
+func (l *limiter) notifyWithLock() {
+	for {
+		next := l.waiters.Front()
+		if next == nil {
+			return
+		}
+
+		w := next.Value.(limiterWaiter)
+		if l.used+w.n > l.size {
+			return
+		}
+
+		l.used += w.n
+		l.waiters.Remove(next)
+		close(w.ready)
+	}
+}
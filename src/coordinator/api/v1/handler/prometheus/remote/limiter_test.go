@@ -0,0 +1,135 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remote
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiterAcquireRelease(t *testing.T) {
+	l := newLimiter(10, 10*time.Millisecond)
+
+	n, err := l.acquire(context.Background(), 4)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+
+	_, err = l.acquire(context.Background(), 6)
+	require.NoError(t, err)
+
+	_, err = l.acquire(context.Background(), 1)
+	assert.Equal(t, errLimiterTimeout, err)
+
+	l.release(4)
+	n, err = l.acquire(context.Background(), 3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.Equal(t, int64(9), l.used)
+}
+
+func TestLimiterCapsAtSize(t *testing.T) {
+	l := newLimiter(10, time.Second)
+
+	n, err := l.acquire(context.Background(), 25)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), n)
+
+	l.release(n)
+	assert.Equal(t, int64(0), l.used)
+}
+
+func TestLimiterWaitsInTurn(t *testing.T) {
+	l := newLimiter(10, time.Second)
+	held, err := l.acquire(context.Background(), 10)
+	require.NoError(t, err)
+
+	large := make(chan struct{})
+	go func() {
+		_, err := l.acquire(context.Background(), 8)
+		assert.NoError(t, err)
+		close(large)
+	}()
+
+	require.True(t, waitForWaiters(l, 1))
+
+	small := make(chan struct{})
+	go func() {
+		_, err := l.acquire(context.Background(), 1)
+		assert.NoError(t, err)
+		close(small)
+	}()
+
+	require.True(t, waitForWaiters(l, 2))
+
+	// Releasing enough for the small write but not the large one holds both
+	// back as the large one is first in line
+	l.release(2)
+	select {
+	case <-small:
+		require.FailNow(t, "small write acquired out of turn")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	l.release(held - 2)
+	<-large
+	<-small
+	assert.Equal(t, int64(9), l.used)
+}
+
+func TestLimiterContextCancelled(t *testing.T) {
+	l := newLimiter(1, 0)
+	_, err := l.acquire(context.Background(), 1)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = l.acquire(ctx, 1)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 0, l.waiters.Len())
+}
+
+func TestNilLimiter(t *testing.T) {
+	l := newLimiter(0, time.Second)
+	require.Nil(t, l)
+
+	n, err := l.acquire(context.Background(), 100)
+	require.NoError(t, err)
+	l.release(n)
+}
+
+func waitForWaiters(l *limiter, n int) bool {
+	for i := 0; i < 100; i++ {
+		l.Lock()
+		waiting := l.waiters.Len()
+		l.Unlock()
+		if waiting == n {
+			return true
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	return false
+}
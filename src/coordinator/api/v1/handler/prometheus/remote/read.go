@@ -89,11 +89,17 @@ func (h *PromReadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := h.read(ctx, w, req, params)
+	result, warnings, err := h.read(ctx, w, req, params)
 	if err != nil {
-		h.promReadMetrics.fetchErrorsServer.Inc(1)
+		code := handler.ExecutionErrorCode(err)
+		if code >= http.StatusInternalServerError {
+			h.promReadMetrics.fetchErrorsServer.Inc(1)
+		} else {
+			h.promReadMetrics.fetchErrorsClient.Inc(1)
+		}
+
 		logger.Error("unable to fetch data", zap.Any("error", err))
-		handler.Error(w, err, http.StatusInternalServerError)
+		handler.Error(w, err, code)
 		return
 	}
 
@@ -109,6 +115,7 @@ func (h *PromReadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	handler.AddWarningsHeader(w, warnings)
 	w.Header().Set("Content-Type", "application/x-protobuf")
 	w.Header().Set("Content-Encoding", "snappy")
 
@@ -137,10 +144,15 @@ func (h *PromReadHandler) parseRequest(r *http.Request) (*prompb.ReadRequest, *h
 	return &req, nil
 }
 
-func (h *PromReadHandler) read(reqCtx context.Context, w http.ResponseWriter, r *prompb.ReadRequest, params *prometheus.RequestParams) ([]*prompb.QueryResult, error) {
+func (h *PromReadHandler) read(
+	reqCtx context.Context,
+	w http.ResponseWriter,
+	r *prompb.ReadRequest,
+	params *prometheus.RequestParams,
+) ([]*prompb.QueryResult, storage.Warnings, error) {
 	// TODO: Handle multi query use case
 	if len(r.Queries) != 1 {
-		return nil, fmt.Errorf("prometheus read endpoint currently only supports one query at a time")
+		return nil, nil, fmt.Errorf("prometheus read endpoint currently only supports one query at a time")
 	}
 
 	ctx, cancel := context.WithTimeout(reqCtx, params.Timeout)
@@ -148,7 +160,7 @@ func (h *PromReadHandler) read(reqCtx context.Context, w http.ResponseWriter, r
 	promQuery := r.Queries[0]
 	query, err := storage.PromReadQueryToM3(promQuery)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Results is closed by execute
@@ -161,15 +173,20 @@ func (h *PromReadHandler) read(reqCtx context.Context, w http.ResponseWriter, r
 
 	go h.engine.Execute(ctx, query, opts, closingCh, results)
 
-	promResults := make([]*prompb.QueryResult, 0, 1)
+	var (
+		promResults = make([]*prompb.QueryResult, 0, 1)
+		warnings    storage.Warnings
+	)
+
 	for result := range results {
 		if result.Err != nil {
-			return nil, result.Err
+			return nil, nil, result.Err
 		}
 
 		promRes := storage.FetchResultToPromResult(result.FetchResult)
 		promResults = append(promResults, promRes)
+		warnings = append(warnings, result.FetchResult.Warnings...)
 	}
 
-	return promResults, nil
+	return promResults, warnings, nil
 }
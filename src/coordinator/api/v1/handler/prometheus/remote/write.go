@@ -17,21 +17,28 @@
 // LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
 // OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
 // THE SOFTWARE.
-
 package remote
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"math"
 	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/m3db/m3db/src/coordinator/api/v1/handler"
 	"github.com/m3db/m3db/src/coordinator/api/v1/handler/prometheus"
 	"github.com/m3db/m3db/src/coordinator/generated/proto/prompb"
 	"github.com/m3db/m3db/src/coordinator/storage"
-	"github.com/m3db/m3db/src/coordinator/util/execution"
 	"github.com/m3db/m3db/src/coordinator/util/logging"
+	"github.com/m3db/m3db/src/dbnode/client"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
 	"github.com/uber-go/tally"
 	"go.uber.org/zap"
 )
@@ -39,19 +46,78 @@ import (
 const (
 	// PromWriteURL is the url for the prom write handler
 	PromWriteURL = handler.RoutePrefixV1 + "/prom/remote/write"
+
+	defaultMaxRequestBytes      = 16 << 20
+	defaultMaxOutstandingWrites = 16384
+	defaultMaxInFlightBytes     = 256 << 20
+	defaultMaxConcurrentSeries  = 64
+	defaultQueueTimeout         = 5 * time.Second
+	defaultRetryAfter           = 5 * time.Second
+
+	// maxReportedSeries is the maximum number of failed series listed in
+	// the response of a write
+	maxReportedSeries = 100
 )
 
+// PromWriteOptions are the limits of the Prometheus write endpoint, limits
+// which are zero or negative are disabled.
+type PromWriteOptions struct {
+	// MaxRequestBytes is the maximum size of a decompressed write request.
+	MaxRequestBytes int
+
+	// MaxOutstandingWrites is the maximum number of datapoints being written
+	// to the storage at once across all requests.
+	MaxOutstandingWrites int
+
+	// MaxConcurrentSeries is the maximum number of series of a request
+	// being written to the storage at once.
+	MaxConcurrentSeries int
+
+	// MaxInFlightBytes is the maximum total size of the decompressed write
+	// requests being handled at once.
+	MaxInFlightBytes int64
+
+	// QueueTimeout is how long writes wait for outstanding writes to
+	// complete before the request is rejected as overloaded.
+	QueueTimeout time.Duration
+
+	// RetryAfter is how long clients are asked to wait before retrying
+	// overloaded writes.
+	RetryAfter time.Duration
+}
+
+// NewPromWriteOptions returns the default options of the write endpoint.
+func NewPromWriteOptions() PromWriteOptions {
+	return PromWriteOptions{
+		MaxRequestBytes:      defaultMaxRequestBytes,
+		MaxOutstandingWrites: defaultMaxOutstandingWrites,
+		MaxConcurrentSeries:  defaultMaxConcurrentSeries,
+		MaxInFlightBytes:     defaultMaxInFlightBytes,
+		QueueTimeout:         defaultQueueTimeout,
+		RetryAfter:           defaultRetryAfter,
+	}
+}
+
 // PromWriteHandler represents a handler for prometheus write endpoint.
+// Requests are held back once the writes in flight reach their limits, and
+// rejected with a 429 and a Retry-After header if the limits are not freed
+// in time so that Prometheus backs off rather than dropping samples.
 type PromWriteHandler struct {
-	store            storage.Storage
-	promWriteMetrics promWriteMetrics
+	store             storage.Storage
+	opts              PromWriteOptions
+	inFlightBytes     *limiter
+	outstandingWrites *limiter
+	promWriteMetrics  promWriteMetrics
 }
 
 // NewPromWriteHandler returns a new instance of handler.
-func NewPromWriteHandler(store storage.Storage, scope tally.Scope) http.Handler {
+func NewPromWriteHandler(store storage.Storage, opts PromWriteOptions, scope tally.Scope) http.Handler {
 	return &PromWriteHandler{
-		store:            store,
-		promWriteMetrics: newPromWriteMetrics(scope),
+		store:             store,
+		opts:              opts,
+		inFlightBytes:     newLimiter(opts.MaxInFlightBytes, opts.QueueTimeout),
+		outstandingWrites: newLimiter(int64(opts.MaxOutstandingWrites), opts.QueueTimeout),
+		promWriteMetrics:  newPromWriteMetrics(scope),
 	}
 }
 
@@ -59,6 +125,8 @@ type promWriteMetrics struct {
 	writeSuccess      tally.Counter
 	writeErrorsServer tally.Counter
 	writeErrorsClient tally.Counter
+	writeOverloaded   tally.Counter
+	writeSeriesErrors tally.Counter
 }
 
 func newPromWriteMetrics(scope tally.Scope) promWriteMetrics {
@@ -66,20 +134,46 @@ func newPromWriteMetrics(scope tally.Scope) promWriteMetrics {
 		writeSuccess:      scope.Counter("write.success"),
 		writeErrorsServer: scope.Tagged(map[string]string{"code": "5XX"}).Counter("write.errors"),
 		writeErrorsClient: scope.Tagged(map[string]string{"code": "4XX"}).Counter("write.errors"),
+		writeOverloaded:   scope.Counter("write.overloaded"),
+		writeSeriesErrors: scope.Counter("write.series.errors"),
 	}
 }
 
 func (h *PromWriteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	req, rErr := h.parseRequest(r)
+	ctx := r.Context()
+	compressed, rErr := prometheus.ReadPromCompressedRequest(r, h.opts.MaxRequestBytes)
 	if rErr != nil {
 		h.promWriteMetrics.writeErrorsClient.Inc(1)
 		handler.Error(w, rErr.Error(), rErr.Code())
 		return
 	}
-	if err := h.write(r.Context(), req); err != nil {
-		h.promWriteMetrics.writeErrorsServer.Inc(1)
-		logging.WithContext(r.Context()).Error("Write error", zap.Any("err", err))
-		handler.Error(w, err, http.StatusInternalServerError)
+
+	// Hold the decompressed size of the request until it is written
+	size, err := snappy.DecodedLen(compressed)
+	if err != nil {
+		h.promWriteMetrics.writeErrorsClient.Inc(1)
+		handler.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	acquired, err := h.inFlightBytes.acquire(ctx, int64(size))
+	if err != nil {
+		h.promWriteMetrics.writeOverloaded.Inc(1)
+		h.writeOverloaded(w, err)
+		return
+	}
+
+	defer h.inFlightBytes.release(acquired)
+
+	req, rErr := h.decodeRequest(compressed)
+	if rErr != nil {
+		h.promWriteMetrics.writeErrorsClient.Inc(1)
+		handler.Error(w, rErr.Error(), rErr.Code())
+		return
+	}
+
+	if err := h.write(ctx, req); err != nil {
+		h.writeError(w, r, err)
 		return
 	}
 
@@ -87,7 +181,16 @@ func (h *PromWriteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *PromWriteHandler) parseRequest(r *http.Request) (*prompb.WriteRequest, *handler.ParseError) {
-	reqBuf, err := prometheus.ParsePromCompressedRequest(r)
+	compressed, err := prometheus.ReadPromCompressedRequest(r, h.opts.MaxRequestBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.decodeRequest(compressed)
+}
+
+func (h *PromWriteHandler) decodeRequest(compressed []byte) (*prompb.WriteRequest, *handler.ParseError) {
+	reqBuf, err := prometheus.DecodePromCompressedRequest(compressed)
 	if err != nil {
 		return nil, err
 	}
@@ -100,26 +203,202 @@ func (h *PromWriteHandler) parseRequest(r *http.Request) (*prompb.WriteRequest,
 	return &req, nil
 }
 
+// write writes each series of the request, taking as many outstanding
+// writes as the series has datapoints. A series failing to write does not
+// stop the others from being written, but once the outstanding writes stay
+// at their limit for too long the remaining series are rejected.
 func (h *PromWriteHandler) write(ctx context.Context, r *prompb.WriteRequest) error {
-	requests := make([]execution.Request, len(r.Timeseries))
-	for idx, t := range r.Timeseries {
-		requests[idx] = newLocalWriteRequest(storage.PromWriteTSToM3(t), h.store)
+	var (
+		queries = groupBySeries(r.Timeseries)
+		errs    = &writeError{total: len(queries)}
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		running chan struct{}
+	)
+
+	if h.opts.MaxConcurrentSeries > 0 {
+		running = make(chan struct{}, h.opts.MaxConcurrentSeries)
 	}
-	return execution.ExecuteParallel(ctx, requests)
+
+	for idx, query := range queries {
+		if running != nil {
+			running <- struct{}{}
+		}
+
+		acquired, err := h.outstandingWrites.acquire(ctx, int64(len(query.Datapoints)))
+		if err != nil {
+			if running != nil {
+				<-running
+			}
+
+			// Writes still in flight report their errors concurrently
+			mu.Lock()
+			errs.overloaded = true
+			for _, rejected := range queries[idx:] {
+				errs.add(rejected, err)
+			}
+			mu.Unlock()
+
+			break
+		}
+
+		wg.Add(1)
+		go func(query *storage.WriteQuery) {
+			defer wg.Done()
+
+			err := h.store.Write(ctx, query)
+			h.outstandingWrites.release(acquired)
+			if running != nil {
+				<-running
+			}
+
+			if err != nil {
+				mu.Lock()
+				errs.add(query, err)
+				mu.Unlock()
+			}
+		}(query)
+	}
+
+	wg.Wait()
+	if len(errs.series) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+func (h *PromWriteHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	writeErr, ok := err.(*writeError)
+	if !ok {
+		h.promWriteMetrics.writeErrorsServer.Inc(1)
+		logging.WithContext(r.Context()).Error("Write error", zap.Any("err", err))
+		handler.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	h.promWriteMetrics.writeSeriesErrors.Inc(int64(len(writeErr.series)))
+	code := writeErr.code()
+	switch code {
+	case http.StatusTooManyRequests:
+		h.promWriteMetrics.writeOverloaded.Inc(1)
+		w.Header().Set("Retry-After", h.retryAfter())
+	case http.StatusBadRequest:
+		h.promWriteMetrics.writeErrorsClient.Inc(1)
+	default:
+		h.promWriteMetrics.writeErrorsServer.Inc(1)
+		logging.WithContext(r.Context()).Error("Write error", zap.Any("err", err))
+	}
+
+	sort.Slice(writeErr.series, func(i, j int) bool {
+		return writeErr.series[i].Series < writeErr.series[j].Series
+	})
+
+	reported := writeErr.series
+	if len(reported) > maxReportedSeries {
+		reported = reported[:maxReportedSeries]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(WriteErrorResponse{
+		Error:  writeErr.Error(),
+		Series: reported,
+	})
 }
 
-func (w *localWriteRequest) Process(ctx context.Context) error {
-	return w.store.Write(ctx, w.writeQuery)
+func (h *PromWriteHandler) writeOverloaded(w http.ResponseWriter, err error) {
+	w.Header().Set("Retry-After", h.retryAfter())
+	handler.Error(w, err, http.StatusTooManyRequests)
+}
+
+// retryAfter returns the Retry-After header value in whole seconds
+func (h *PromWriteHandler) retryAfter() string {
+	seconds := int(math.Ceil(h.opts.RetryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	return strconv.Itoa(seconds)
+}
+
+// groupBySeries converts the timeseries of a request to write queries,
+// merging the samples of timeseries which appear several times
+func groupBySeries(timeseries []*prompb.TimeSeries) []*storage.WriteQuery {
+	var (
+		queries = make([]*storage.WriteQuery, 0, len(timeseries))
+		byID    = make(map[string]*storage.WriteQuery, len(timeseries))
+	)
+
+	for _, t := range timeseries {
+		query := storage.PromWriteTSToM3(t)
+		id := query.Tags.ID()
+		if existing, ok := byID[id]; ok {
+			existing.Datapoints = append(existing.Datapoints, query.Datapoints...)
+			continue
+		}
+
+		byID[id] = query
+		queries = append(queries, query)
+	}
+
+	for _, query := range queries {
+		sort.SliceStable(query.Datapoints, func(i, j int) bool {
+			return query.Datapoints[i].Timestamp.Before(query.Datapoints[j].Timestamp)
+		})
+	}
+
+	return queries
+}
+
+// SeriesError is the error writing a series
+type SeriesError struct {
+	Series string `json:"series"`
+	Error  string `json:"error"`
+}
+
+// WriteErrorResponse is returned when some series of a write failed, the
+// series which did not fail are still written. Only the first failed series
+// are listed.
+type WriteErrorResponse struct {
+	Error  string        `json:"error"`
+	Series []SeriesError `json:"series"`
+}
+
+// writeError is the error of a write in which some series failed
+type writeError struct {
+	total      int
+	series     []SeriesError
+	overloaded bool
+	retryable  bool
+}
+
+func (e *writeError) add(query *storage.WriteQuery, err error) {
+	// Series rejected by the storage as invalid fail again when retried,
+	// while series rejected by overloaded nodes are retried after backing off
+	switch {
+	case client.IsOverloadedError(err):
+		e.overloaded = true
+	case !client.IsBadRequestError(err):
+		e.retryable = true
+	}
+
+	e.series = append(e.series, SeriesError{Series: query.Tags.ID(), Error: err.Error()})
 }
 
-type localWriteRequest struct {
-	store      storage.Storage
-	writeQuery *storage.WriteQuery
+func (e *writeError) Error() string {
+	return fmt.Sprintf("failed to write %d of %d series", len(e.series), e.total)
 }
 
-func newLocalWriteRequest(writeQuery *storage.WriteQuery, store storage.Storage) execution.Request {
-	return &localWriteRequest{
-		store:      store,
-		writeQuery: writeQuery,
+// code returns the status of the write, asking clients to retry unless all
+// the series failed as bad requests
+func (e *writeError) code() int {
+	switch {
+	case e.overloaded:
+		return http.StatusTooManyRequests
+	case e.retryable:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
 	}
 }
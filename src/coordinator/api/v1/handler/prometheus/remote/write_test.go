@@ -23,21 +23,32 @@ package remote
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sort"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/m3db/m3db/src/coordinator/generated/proto/prompb"
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/storage"
 	"github.com/m3db/m3db/src/coordinator/test/local"
+	"github.com/m3db/m3db/src/coordinator/ts"
 	"github.com/m3db/m3db/src/coordinator/util/logging"
+	"github.com/m3db/m3db/src/dbnode/generated/thrift/rpc"
 	"github.com/m3db/m3db/src/dbnode/x/metrics"
 	xclock "github.com/m3db/m3x/clock"
+	xerrors "github.com/m3db/m3x/errors"
 
 	"github.com/golang/mock/gomock"
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/uber-go/tally"
 )
@@ -134,3 +145,299 @@ func TestWriteErrorMetricCount(t *testing.T) {
 	}, 5*time.Second)
 	require.True(t, foundMetric)
 }
+
+type testWriteStorage struct {
+	storage.Storage
+
+	sync.Mutex
+	writes     []*storage.WriteQuery
+	errs       map[string]error
+	blockCh    chan struct{}
+	running    int
+	maxRunning int
+}
+
+func (s *testWriteStorage) Write(_ context.Context, query *storage.WriteQuery) error {
+	s.Lock()
+	s.running++
+	if s.running > s.maxRunning {
+		s.maxRunning = s.running
+	}
+	s.Unlock()
+
+	if s.blockCh != nil {
+		<-s.blockCh
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.running--
+
+	if err := s.errs[query.Tags[models.MetricName]]; err != nil {
+		return err
+	}
+
+	s.writes = append(s.writes, query)
+	return nil
+}
+
+func (s *testWriteStorage) written() []*storage.WriteQuery {
+	s.Lock()
+	defer s.Unlock()
+
+	sort.Slice(s.writes, func(i, j int) bool {
+		return s.writes[i].Tags.ID() < s.writes[j].Tags.ID()
+	})
+
+	return s.writes
+}
+
+func newTestWriteBody(t *testing.T, timeseries ...*prompb.TimeSeries) io.Reader {
+	data, err := proto.Marshal(&prompb.WriteRequest{Timeseries: timeseries})
+	require.NoError(t, err)
+	return bytes.NewReader(snappy.Encode(nil, data))
+}
+
+func newTestTimeSeries(name string, timestamps ...int64) *prompb.TimeSeries {
+	samples := make([]*prompb.Sample, 0, len(timestamps))
+	for _, timestamp := range timestamps {
+		samples = append(samples, &prompb.Sample{Value: float64(timestamp), Timestamp: timestamp})
+	}
+
+	return &prompb.TimeSeries{
+		Labels:  []*prompb.Label{{Name: models.MetricName, Value: name}},
+		Samples: samples,
+	}
+}
+
+func serveTestWrite(h http.Handler, body io.Reader) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", PromWriteURL, body)
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	return res
+}
+
+func TestPromWriteGroupsSeries(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	store := &testWriteStorage{}
+	h := NewPromWriteHandler(store, NewPromWriteOptions(), tally.NoopScope)
+	res := serveTestWrite(h, newTestWriteBody(t,
+		newTestTimeSeries("foo", 3000, 1000),
+		newTestTimeSeries("bar", 1000),
+		newTestTimeSeries("foo", 2000),
+	))
+	require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+	written := store.written()
+	require.Len(t, written, 2)
+	assert.Equal(t, "bar", written[0].Tags[models.MetricName])
+	assert.Equal(t, "foo", written[1].Tags[models.MetricName])
+	assert.Equal(t, ts.Datapoints{
+		{Timestamp: time.Unix(1, 0), Value: 1000},
+		{Timestamp: time.Unix(2, 0), Value: 2000},
+		{Timestamp: time.Unix(3, 0), Value: 3000},
+	}, written[1].Datapoints)
+}
+
+func TestPromWriteSeriesErrors(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	store := &testWriteStorage{errs: map[string]error{
+		"bar": xerrors.NewInvalidParamsError(errors.New("invalid series")),
+		"baz": errors.New("write failed"),
+	}}
+
+	h := NewPromWriteHandler(store, NewPromWriteOptions(), tally.NoopScope)
+	res := serveTestWrite(h, newTestWriteBody(t,
+		newTestTimeSeries("foo", 1000),
+		newTestTimeSeries("bar", 1000),
+		newTestTimeSeries("baz", 1000),
+	))
+
+	// Series which may succeed when retried make the whole write retried
+	require.Equal(t, http.StatusInternalServerError, res.Code)
+
+	var resp WriteErrorResponse
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &resp))
+	assert.Equal(t, "failed to write 2 of 3 series", resp.Error)
+	require.Len(t, resp.Series, 2)
+	assert.Equal(t, "__name__=bar,", resp.Series[0].Series)
+	assert.Equal(t, "__name__=baz,", resp.Series[1].Series)
+	assert.Equal(t, "write failed", resp.Series[1].Error)
+
+	require.Len(t, store.written(), 1)
+	assert.Equal(t, "foo", store.written()[0].Tags[models.MetricName])
+
+	// Series which are invalid are not retried
+	res = serveTestWrite(h, newTestWriteBody(t, newTestTimeSeries("bar", 1000)))
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+}
+
+func TestPromWriteOverloaded(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	store := &testWriteStorage{blockCh: make(chan struct{})}
+	opts := NewPromWriteOptions()
+	opts.MaxOutstandingWrites = 2
+	opts.QueueTimeout = 10 * time.Millisecond
+	opts.RetryAfter = 1500 * time.Millisecond
+	h := NewPromWriteHandler(store, opts, tally.NoopScope)
+
+	// The first series takes all of the outstanding writes until the storage
+	// is unblocked, so the second is rejected
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		res := serveTestWrite(h, newTestWriteBody(t,
+			newTestTimeSeries("foo", 1000, 2000),
+			newTestTimeSeries("bar", 1000),
+		))
+		assert.Equal(t, http.StatusTooManyRequests, res.Code)
+		assert.Equal(t, "2", res.Header().Get("Retry-After"))
+
+		var resp WriteErrorResponse
+		assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &resp))
+		assert.Equal(t, "failed to write 1 of 2 series", resp.Error)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	close(store.blockCh)
+	<-done
+
+	require.Len(t, store.written(), 1)
+	assert.Equal(t, "foo", store.written()[0].Tags[models.MetricName])
+
+	// The outstanding writes are released once written
+	res := serveTestWrite(h, newTestWriteBody(t, newTestTimeSeries("bar", 1000)))
+	assert.Equal(t, http.StatusOK, res.Code)
+}
+
+func TestPromWriteOverloadedWhileWriting(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	var (
+		timeseries []*prompb.TimeSeries
+		errs       = make(map[string]error)
+	)
+
+	for i := 0; i < 4; i++ {
+		name := fmt.Sprintf("inflight%d", i)
+		errs[name] = errors.New("write failed")
+		timeseries = append(timeseries, newTestTimeSeries(name, 1000))
+	}
+
+	for i := 0; i < 4; i++ {
+		timeseries = append(timeseries, newTestTimeSeries(fmt.Sprintf("rejected%d", i), 1000))
+	}
+
+	store := &testWriteStorage{blockCh: make(chan struct{}), errs: errs}
+	opts := NewPromWriteOptions()
+	opts.MaxOutstandingWrites = 4
+	opts.QueueTimeout = 10 * time.Millisecond
+	h := NewPromWriteHandler(store, opts, tally.NoopScope)
+
+	// The writes in flight fail after the remaining series were rejected,
+	// both are reported
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(store.blockCh)
+	}()
+
+	res := serveTestWrite(h, newTestWriteBody(t, timeseries...))
+	require.Equal(t, http.StatusTooManyRequests, res.Code)
+
+	var resp WriteErrorResponse
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &resp))
+	assert.Equal(t, "failed to write 8 of 8 series", resp.Error)
+	assert.Len(t, resp.Series, 8)
+}
+
+func TestPromWriteNodesOverloaded(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	store := &testWriteStorage{errs: map[string]error{
+		"bar": &rpc.Error{
+			Type:    rpc.ErrorType_INTERNAL_ERROR,
+			Message: "commit log queue is full",
+		},
+		"baz": xerrors.NewInvalidParamsError(errors.New("invalid series")),
+	}}
+
+	h := NewPromWriteHandler(store, NewPromWriteOptions(), tally.NoopScope)
+	res := serveTestWrite(h, newTestWriteBody(t,
+		newTestTimeSeries("foo", 1000),
+		newTestTimeSeries("bar", 1000),
+		newTestTimeSeries("baz", 1000),
+	))
+
+	// Series rejected by overloaded nodes make the client back off
+	assert.Equal(t, http.StatusTooManyRequests, res.Code)
+	assert.Equal(t, "5", res.Header().Get("Retry-After"))
+	require.Len(t, store.written(), 1)
+}
+
+func TestPromWriteMaxConcurrentSeries(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	store := &testWriteStorage{blockCh: make(chan struct{})}
+	opts := NewPromWriteOptions()
+	opts.MaxConcurrentSeries = 2
+	h := NewPromWriteHandler(store, opts, tally.NoopScope)
+
+	timeseries := make([]*prompb.TimeSeries, 0, 10)
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+		timeseries = append(timeseries, newTestTimeSeries(name, 1000))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		res := serveTestWrite(h, newTestWriteBody(t, timeseries...))
+		assert.Equal(t, http.StatusOK, res.Code)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	close(store.blockCh)
+	<-done
+
+	assert.Len(t, store.written(), 10)
+	assert.Equal(t, 2, store.maxRunning)
+}
+
+func TestPromWriteInFlightBytesExceeded(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	opts := NewPromWriteOptions()
+	opts.MaxInFlightBytes = 1
+	opts.QueueTimeout = 10 * time.Millisecond
+	h := NewPromWriteHandler(&testWriteStorage{}, opts, tally.NoopScope).(*PromWriteHandler)
+
+	// Hold the in flight bytes as a concurrent request would
+	held, err := h.inFlightBytes.acquire(context.Background(), 1)
+	require.NoError(t, err)
+
+	res := serveTestWrite(h, newTestWriteBody(t, newTestTimeSeries("foo", 1000)))
+	assert.Equal(t, http.StatusTooManyRequests, res.Code)
+	assert.Equal(t, "5", res.Header().Get("Retry-After"))
+
+	h.inFlightBytes.release(held)
+	res = serveTestWrite(h, newTestWriteBody(t, newTestTimeSeries("foo", 1000)))
+	assert.Equal(t, http.StatusOK, res.Code)
+}
+
+func TestPromWriteRequestTooLarge(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	opts := NewPromWriteOptions()
+	opts.MaxRequestBytes = 16
+	store := &testWriteStorage{}
+	h := NewPromWriteHandler(store, opts, tally.NoopScope)
+
+	res := serveTestWrite(h, newTestWriteBody(t, newTestTimeSeries("foo", 1000, 2000, 3000)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.Code)
+	assert.Empty(t, store.written())
+}
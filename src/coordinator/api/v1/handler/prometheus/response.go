@@ -0,0 +1,126 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package prometheus
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/m3db/m3db/src/coordinator/api/v1/handler"
+	"github.com/m3db/m3db/src/coordinator/storage"
+
+	"go.uber.org/zap"
+)
+
+const (
+	statusSuccess = "success"
+	statusError   = "error"
+)
+
+// ErrorType is the type of a failed Prometheus API call
+type ErrorType string
+
+const (
+	// ErrorTimeout is returned when the query timed out
+	ErrorTimeout ErrorType = "timeout"
+	// ErrorCanceled is returned when the query was canceled
+	ErrorCanceled ErrorType = "canceled"
+	// ErrorExec is returned when the query failed to execute
+	ErrorExec ErrorType = "execution"
+	// ErrorBadData is returned when the request parameters are invalid
+	ErrorBadData ErrorType = "bad_data"
+	// ErrorInternal is returned when an unexpected error occurred
+	ErrorInternal ErrorType = "internal"
+	// ErrorNotFound is returned when the requested resource does not exist
+	ErrorNotFound ErrorType = "not_found"
+	// ErrorUnavailable is returned when the query was rejected because the
+	// server is at capacity and may succeed if retried later
+	ErrorUnavailable ErrorType = "unavailable"
+)
+
+// StatusCode returns the HTTP status code used for the error type
+func (e ErrorType) StatusCode() int {
+	switch e {
+	case ErrorBadData:
+		return http.StatusBadRequest
+	case ErrorExec:
+		return http.StatusUnprocessableEntity
+	case ErrorCanceled, ErrorTimeout, ErrorUnavailable:
+		return http.StatusServiceUnavailable
+	case ErrorNotFound:
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Response is the JSON envelope of all Prometheus API responses
+type Response struct {
+	Status    string      `json:"status"`
+	Data      interface{} `json:"data,omitempty"`
+	ErrorType ErrorType   `json:"errorType,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	Warnings  []string    `json:"warnings,omitempty"`
+}
+
+// WriteSuccess writes a successful Prometheus API response
+func WriteSuccess(w http.ResponseWriter, data interface{}, logger *zap.Logger) {
+	writeResponse(w, http.StatusOK, &Response{
+		Status: statusSuccess,
+		Data:   data,
+	}, logger)
+}
+
+// WriteSuccessWithWarnings writes a successful Prometheus API response whose
+// data may be incomplete, the warnings are set on both the response and the
+// warnings header
+func WriteSuccessWithWarnings(w http.ResponseWriter, data interface{}, warnings storage.Warnings, logger *zap.Logger) {
+	handler.AddWarningsHeader(w, warnings)
+	writeResponse(w, http.StatusOK, &Response{
+		Status:   statusSuccess,
+		Data:     data,
+		Warnings: warnings.Strings(),
+	}, logger)
+}
+
+// WriteError writes a failed Prometheus API response
+func WriteError(w http.ResponseWriter, errType ErrorType, err error, logger *zap.Logger) {
+	writeResponse(w, errType.StatusCode(), &Response{
+		Status:    statusError,
+		ErrorType: errType,
+		Error:     err.Error(),
+	}, logger)
+}
+
+func writeResponse(w http.ResponseWriter, code int, resp *Response, logger *zap.Logger) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		logger.Error("unable to marshal prometheus response", zap.Any("error", err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if _, err := w.Write(data); err != nil {
+		logger.Error("unable to write prometheus response", zap.Any("error", err))
+	}
+}
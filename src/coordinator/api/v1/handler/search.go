@@ -68,6 +68,7 @@ func (h *SearchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	AddWarningsHeader(w, results.Warnings)
 	WriteJSONResponse(w, results, logger)
 }
 
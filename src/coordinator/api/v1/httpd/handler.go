@@ -28,6 +28,8 @@ import (
 	m3clusterClient "github.com/m3db/m3cluster/client"
 	"github.com/m3db/m3db/src/cmd/services/m3coordinator/config"
 	"github.com/m3db/m3db/src/coordinator/api/v1/handler"
+	"github.com/m3db/m3db/src/coordinator/api/v1/handler/graphite"
+	"github.com/m3db/m3db/src/coordinator/api/v1/handler/influxdb"
 	"github.com/m3db/m3db/src/coordinator/api/v1/handler/namespace"
 	"github.com/m3db/m3db/src/coordinator/api/v1/handler/openapi"
 	"github.com/m3db/m3db/src/coordinator/api/v1/handler/placement"
@@ -48,6 +50,7 @@ const (
 
 var (
 	remoteSource = map[string]string{"source": "remote"}
+	influxSource = map[string]string{"source": "influxdb"}
 )
 
 // Handler represents an HTTP handler.
@@ -90,9 +93,17 @@ func (h *Handler) RegisterRoutes() error {
 	h.Router.PathPrefix(openapi.StaticURLPrefix).Handler(logged(openapi.StaticHandler()))
 
 	h.Router.HandleFunc(remote.PromReadURL, logged(remote.NewPromReadHandler(h.engine, h.scope.Tagged(remoteSource))).ServeHTTP).Methods("POST")
-	h.Router.HandleFunc(remote.PromWriteURL, logged(remote.NewPromWriteHandler(h.storage, h.scope.Tagged(remoteSource))).ServeHTTP).Methods("POST")
+	h.Router.HandleFunc(remote.PromWriteURL, logged(remote.NewPromWriteHandler(h.storage, h.config.RemoteWrite.Options(), h.scope.Tagged(remoteSource))).ServeHTTP).Methods("POST")
+	h.Router.HandleFunc(influxdb.InfluxWriteURL, logged(influxdb.NewInfluxWriteHandler(h.storage, h.config.InfluxDBWrite.Options(), h.scope.Tagged(influxSource))).ServeHTTP).Methods("POST")
 	h.Router.HandleFunc(native.PromReadURL, logged(native.NewPromReadHandler(h.engine)).ServeHTTP).Methods("GET")
+	h.Router.HandleFunc(native.PromQueryURL, logged(native.NewPromQueryHandler(h.engine)).ServeHTTP).Methods("GET", "POST")
+	h.Router.HandleFunc(native.PromQueryRangeURL, logged(native.NewPromQueryRangeHandler(h.engine)).ServeHTTP).Methods("GET", "POST")
+	h.Router.HandleFunc(native.PromSeriesURL, logged(native.NewPromSeriesHandler(h.storage, h.engine)).ServeHTTP).Methods("GET", "POST")
+	h.Router.HandleFunc(native.PromLabelsURL, logged(native.NewPromLabelsHandler(h.storage, h.engine)).ServeHTTP).Methods("GET")
+	h.Router.HandleFunc(native.PromLabelValuesURL, logged(native.NewPromLabelValuesHandler(h.storage, h.engine)).ServeHTTP).Methods("GET")
 	h.Router.HandleFunc(handler.SearchURL, logged(handler.NewSearchHandler(h.storage)).ServeHTTP).Methods("POST")
+	h.Router.HandleFunc(graphite.RenderURL, logged(graphite.NewRenderHandler(h.storage, h.engine)).ServeHTTP).Methods("GET", "POST")
+	h.Router.HandleFunc(graphite.FindURL, logged(graphite.NewFindHandler(h.storage, h.engine)).ServeHTTP).Methods("GET", "POST")
 
 	h.registerProfileEndpoints()
 
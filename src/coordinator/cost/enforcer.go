@@ -0,0 +1,136 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cost
+
+import (
+	"sync/atomic"
+
+	"github.com/m3db/m3db/src/coordinator/errors"
+)
+
+// Limits are the resource limits applied to a single query, or across all
+// running queries. A limit of zero or less is disabled.
+type Limits struct {
+	// MaxFetchedSeries is the maximum number of series fetched from storage.
+	MaxFetchedSeries int64
+	// MaxFetchedDatapoints is the maximum number of datapoints decoded.
+	MaxFetchedDatapoints int64
+}
+
+// Enforcer accounts the resources used by queries and rejects them once a
+// limit has been exceeded. Per query enforcers are created from the global
+// enforcer with Child, and charge both their own limits and those of their
+// parent. All methods are safe to call on a nil Enforcer, which enforces
+// no limits.
+type Enforcer struct {
+	scope      string
+	limits     Limits
+	parent     *Enforcer
+	series     int64
+	datapoints int64
+}
+
+// NewEnforcer creates a new global enforcer with the given limits.
+func NewEnforcer(limits Limits) *Enforcer {
+	return &Enforcer{
+		scope:  errors.LimitScopeGlobal,
+		limits: limits,
+	}
+}
+
+// Child creates a per query enforcer with the given limits, which also
+// charges this enforcer. Release must be called once the query completes.
+func (e *Enforcer) Child(limits Limits) *Enforcer {
+	return &Enforcer{
+		scope:  errors.LimitScopeQuery,
+		limits: limits,
+		parent: e,
+	}
+}
+
+// Limits returns the limits of the enforcer.
+func (e *Enforcer) Limits() Limits {
+	if e == nil {
+		return Limits{}
+	}
+
+	return e.limits
+}
+
+// AddSeries charges the given number of fetched series, returning an error
+// if this or any parent enforcer is over its limit.
+func (e *Enforcer) AddSeries(n int) error {
+	if e == nil || n == 0 {
+		return nil
+	}
+
+	// NB: every enforcer in the chain is charged even once a limit has been
+	// exceeded so that Release returns the same amount to each of them.
+	var err error
+	for curr := e; curr != nil; curr = curr.parent {
+		total := atomic.AddInt64(&curr.series, int64(n))
+		if limit := curr.limits.MaxFetchedSeries; err == nil && limit > 0 && total > limit {
+			err = errors.NewResourceLimitError(errors.ResourceSeries, curr.scope, limit)
+		}
+	}
+
+	return err
+}
+
+// AddDatapoints charges the given number of decoded datapoints, returning an
+// error if this or any parent enforcer is over its limit.
+func (e *Enforcer) AddDatapoints(n int) error {
+	if e == nil || n == 0 {
+		return nil
+	}
+
+	var err error
+	for curr := e; curr != nil; curr = curr.parent {
+		total := atomic.AddInt64(&curr.datapoints, int64(n))
+		if limit := curr.limits.MaxFetchedDatapoints; err == nil && limit > 0 && total > limit {
+			err = errors.NewResourceLimitError(errors.ResourceDatapoints, curr.scope, limit)
+		}
+	}
+
+	return err
+}
+
+// Release returns all resources charged by this enforcer to its parents.
+func (e *Enforcer) Release() {
+	if e == nil {
+		return
+	}
+
+	e.parent.releaseSeries(atomic.SwapInt64(&e.series, 0))
+	e.parent.releaseDatapoints(atomic.SwapInt64(&e.datapoints, 0))
+}
+
+func (e *Enforcer) releaseSeries(n int64) {
+	for curr := e; curr != nil; curr = curr.parent {
+		atomic.AddInt64(&curr.series, -n)
+	}
+}
+
+func (e *Enforcer) releaseDatapoints(n int64) {
+	for curr := e; curr != nil; curr = curr.parent {
+		atomic.AddInt64(&curr.datapoints, -n)
+	}
+}
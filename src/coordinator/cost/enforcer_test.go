@@ -0,0 +1,82 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cost
+
+import (
+	"testing"
+
+	"github.com/m3db/m3db/src/coordinator/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNilEnforcerHasNoLimits(t *testing.T) {
+	var e *Enforcer
+	assert.NoError(t, e.AddSeries(100))
+	assert.NoError(t, e.AddDatapoints(100))
+	assert.Equal(t, Limits{}, e.Limits())
+	assert.Nil(t, e.Child(Limits{MaxFetchedSeries: 1}).parent)
+	e.Release()
+}
+
+func TestEnforcerQueryLimit(t *testing.T) {
+	global := NewEnforcer(Limits{})
+	query := global.Child(Limits{MaxFetchedSeries: 10, MaxFetchedDatapoints: 100})
+
+	require.NoError(t, query.AddSeries(10))
+	require.NoError(t, query.AddDatapoints(100))
+
+	err := query.AddSeries(1)
+	require.Error(t, err)
+	limitErr, ok := err.(*errors.ResourceLimitError)
+	require.True(t, ok)
+	assert.Equal(t, errors.ResourceSeries, limitErr.Resource)
+	assert.Equal(t, errors.LimitScopeQuery, limitErr.Scope)
+	assert.Equal(t, int64(10), limitErr.Limit)
+	assert.False(t, limitErr.Global())
+
+	err = query.AddDatapoints(1)
+	require.Error(t, err)
+	assert.Equal(t, "query limit of 100 datapoints exceeded", err.Error())
+
+	query.Release()
+	assert.Equal(t, int64(0), global.series)
+	assert.Equal(t, int64(0), global.datapoints)
+}
+
+func TestEnforcerGlobalLimit(t *testing.T) {
+	global := NewEnforcer(Limits{MaxFetchedDatapoints: 100})
+	first := global.Child(Limits{})
+	second := global.Child(Limits{MaxFetchedDatapoints: 80})
+
+	require.NoError(t, first.AddDatapoints(60))
+	err := second.AddDatapoints(50)
+	require.Error(t, err)
+	assert.True(t, errors.IsResourceLimitError(err))
+	assert.True(t, err.(*errors.ResourceLimitError).Global())
+
+	// Resources are returned to the global enforcer once queries complete
+	second.Release()
+	assert.NoError(t, first.AddDatapoints(40))
+	first.Release()
+	assert.Equal(t, int64(0), global.datapoints)
+}
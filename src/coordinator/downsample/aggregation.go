@@ -0,0 +1,153 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package downsample
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AggregationType is a type of aggregation of the datapoints in a window
+type AggregationType int
+
+const (
+	// AggregationLast is the last datapoint in the window
+	AggregationLast AggregationType = iota
+	// AggregationSum is the sum of the datapoints in the window
+	AggregationSum
+	// AggregationMin is the minimum of the datapoints in the window
+	AggregationMin
+	// AggregationMax is the maximum of the datapoints in the window
+	AggregationMax
+	// AggregationCount is the number of datapoints in the window
+	AggregationCount
+	// AggregationPercentile is a percentile of the datapoints in the window
+	AggregationPercentile
+)
+
+var aggregationTypeNames = map[string]AggregationType{
+	"last":  AggregationLast,
+	"sum":   AggregationSum,
+	"min":   AggregationMin,
+	"max":   AggregationMax,
+	"count": AggregationCount,
+}
+
+// Aggregation is an aggregation of the datapoints written to a series within
+// each resolution window
+type Aggregation struct {
+	Type AggregationType
+	// Quantile is the quantile in (0, 1) of percentile aggregations
+	Quantile float64
+	// name is the name the aggregation was parsed from
+	name string
+}
+
+// ParseAggregation parses an aggregation such as last, sum, min, max, count,
+// or a percentile such as p50, p99 or p999
+func ParseAggregation(str string) (Aggregation, error) {
+	name := strings.ToLower(str)
+	if t, ok := aggregationTypeNames[name]; ok {
+		return Aggregation{Type: t, name: name}, nil
+	}
+
+	if len(name) > 1 && name[0] == 'p' {
+		digits := name[1:]
+		if n, err := strconv.ParseUint(digits, 10, 32); err == nil && n > 0 {
+			quantile := float64(n) / math.Pow10(len(digits))
+			return Aggregation{Type: AggregationPercentile, Quantile: quantile, name: name}, nil
+		}
+	}
+
+	return Aggregation{}, fmt.Errorf("invalid aggregation: %s", str)
+}
+
+func (a Aggregation) String() string {
+	return a.name
+}
+
+// window accumulates the datapoints of a series within a resolution window
+type window struct {
+	last     float64
+	lastTime time.Time
+	sum      float64
+	min      float64
+	max      float64
+	count    int
+	// values are only kept for percentile aggregations
+	values []float64
+}
+
+func newWindow() *window {
+	return &window{min: math.Inf(1), max: math.Inf(-1)}
+}
+
+func (w *window) add(t time.Time, value float64, keepValues bool) {
+	if w.count == 0 || !t.Before(w.lastTime) {
+		w.last, w.lastTime = value, t
+	}
+
+	w.sum += value
+	w.min = math.Min(w.min, value)
+	w.max = math.Max(w.max, value)
+	w.count++
+	if keepValues {
+		w.values = append(w.values, value)
+	}
+}
+
+func (w *window) value(agg Aggregation) float64 {
+	switch agg.Type {
+	case AggregationLast:
+		return w.last
+	case AggregationSum:
+		return w.sum
+	case AggregationMin:
+		return w.min
+	case AggregationMax:
+		return w.max
+	case AggregationCount:
+		return float64(w.count)
+	case AggregationPercentile:
+		return percentile(w.values, agg.Quantile)
+	default:
+		return math.NaN()
+	}
+}
+
+// percentile returns the nearest rank percentile of the values, sorting them
+func percentile(values []float64, quantile float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+
+	sort.Float64s(values)
+	rank := int(math.Ceil(quantile*float64(len(values)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+
+	return values[rank]
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package downsample
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAggregation(t *testing.T) {
+	tests := []struct {
+		str      string
+		expected Aggregation
+	}{
+		{"last", Aggregation{Type: AggregationLast, name: "last"}},
+		{"Sum", Aggregation{Type: AggregationSum, name: "sum"}},
+		{"count", Aggregation{Type: AggregationCount, name: "count"}},
+		{"p50", Aggregation{Type: AggregationPercentile, Quantile: 0.5, name: "p50"}},
+		{"p999", Aggregation{Type: AggregationPercentile, Quantile: 0.999, name: "p999"}},
+	}
+
+	for _, tt := range tests {
+		agg, err := ParseAggregation(tt.str)
+		require.NoError(t, err, tt.str)
+		assert.Equal(t, tt.expected, agg, tt.str)
+	}
+
+	for _, str := range []string{"", "avg", "p", "p0", "px"} {
+		_, err := ParseAggregation(str)
+		assert.Error(t, err, str)
+	}
+}
+
+func TestWindowValue(t *testing.T) {
+	now := time.Now()
+	w := newWindow()
+	for i, v := range []float64{4, 1, 3, 2} {
+		w.add(now.Add(time.Duration(i)*time.Second), v, true)
+	}
+
+	// Out of order datapoints are not the last
+	w.add(now.Add(-time.Second), 10, true)
+
+	p50, err := ParseAggregation("p50")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2.0, w.value(Aggregation{Type: AggregationLast}))
+	assert.Equal(t, 20.0, w.value(Aggregation{Type: AggregationSum}))
+	assert.Equal(t, 1.0, w.value(Aggregation{Type: AggregationMin}))
+	assert.Equal(t, 10.0, w.value(Aggregation{Type: AggregationMax}))
+	assert.Equal(t, 5.0, w.value(Aggregation{Type: AggregationCount}))
+	assert.Equal(t, 3.0, w.value(p50))
+	assert.True(t, math.IsNaN(newWindow().value(p50)))
+}
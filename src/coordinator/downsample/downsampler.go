@@ -0,0 +1,359 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package downsample
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/ts"
+	"github.com/m3db/m3db/src/coordinator/util/logging"
+	"github.com/m3db/m3metrics/policy"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const (
+	// AggregationTagName is the tag added to the series of all but the first
+	// aggregation of a mapping rule, naming the aggregation
+	AggregationTagName = "__agg__"
+
+	defaultBufferPast    = time.Minute
+	defaultFlushInterval = 10 * time.Second
+
+	maxUnixNanos = math.MaxInt64
+)
+
+// Options are the options of a downsampler
+type Options struct {
+	// Rules are the mapping rules selecting the series to downsample
+	Rules []MappingRule
+	// Writer writes the aggregated datapoints
+	Writer Writer
+	// BufferPast is how long to wait for late datapoints before writing the
+	// aggregations of a window, datapoints arriving later are dropped
+	BufferPast time.Duration
+	// FlushInterval is how often windows are checked for being complete
+	FlushInterval time.Duration
+	// NowFn returns the current time, defaults to time.Now
+	NowFn func() time.Time
+	// Scope is the metrics scope, defaults to a noop scope
+	Scope tally.Scope
+}
+
+// Downsampler aggregates written datapoints into fixed resolution windows and
+// writes the aggregations of each window once it is complete
+type Downsampler struct {
+	sync.Mutex
+
+	opts    Options
+	series  map[seriesKey]*aggregatedSeries
+	metrics downsamplerMetrics
+	closeCh chan struct{}
+	doneCh  chan struct{}
+}
+
+type seriesKey struct {
+	id            string
+	storagePolicy policy.StoragePolicy
+}
+
+// aggregatedSeries is a series being downsampled to a storage policy
+type aggregatedSeries struct {
+	tags         models.Tags
+	aggregations []Aggregation
+	keepValues   bool
+	// windows are keyed by the unix nanoseconds of their start
+	windows map[int64]*window
+}
+
+type downsamplerMetrics struct {
+	late    tally.Counter
+	written tally.Counter
+	errors  tally.Counter
+}
+
+func newDownsamplerMetrics(scope tally.Scope) downsamplerMetrics {
+	return downsamplerMetrics{
+		late:    scope.Counter("datapoints.late"),
+		written: scope.Counter("datapoints.written"),
+		errors:  scope.Counter("write.errors"),
+	}
+}
+
+// NewDownsampler creates a new downsampler, which flushes complete windows in
+// the background until closed
+func NewDownsampler(opts Options) (*Downsampler, error) {
+	for _, rule := range opts.Rules {
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.BufferPast <= 0 {
+		opts.BufferPast = defaultBufferPast
+	}
+
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultFlushInterval
+	}
+
+	if opts.NowFn == nil {
+		opts.NowFn = time.Now
+	}
+
+	if opts.Scope == nil {
+		opts.Scope = tally.NoopScope
+	}
+
+	d := &Downsampler{
+		opts:    opts,
+		series:  make(map[seriesKey]*aggregatedSeries),
+		metrics: newDownsamplerMetrics(opts.Scope),
+		closeCh: make(chan struct{}),
+		doneCh:  make(chan struct{}),
+	}
+
+	go d.flushLoop()
+	return d, nil
+}
+
+// target is a storage policy a series is downsampled to, with the
+// aggregations to produce for it
+type target struct {
+	storagePolicy policy.StoragePolicy
+	aggregations  []Aggregation
+}
+
+// targets returns the storage policies the series with the given tags is
+// downsampled to by the mapping rules
+func (d *Downsampler) targets(tags models.Tags) []target {
+	var targets []target
+	for _, rule := range d.opts.Rules {
+		if !rule.matches(tags) {
+			continue
+		}
+
+		for _, sp := range rule.StoragePolicies {
+			targets = mergeTarget(targets, sp, rule.aggregations())
+		}
+	}
+
+	return targets
+}
+
+// mergeTarget adds the aggregations of a storage policy to the targets, as a
+// series may match several rules downsampling to the same storage policy
+func mergeTarget(targets []target, sp policy.StoragePolicy, aggregations []Aggregation) []target {
+	for i := range targets {
+		if targets[i].storagePolicy != sp {
+			continue
+		}
+
+		for _, agg := range aggregations {
+			if !containsAggregation(targets[i].aggregations, agg) {
+				targets[i].aggregations = append(targets[i].aggregations, agg)
+			}
+		}
+
+		return targets
+	}
+
+	return append(targets, target{
+		storagePolicy: sp,
+		aggregations:  append([]Aggregation(nil), aggregations...),
+	})
+}
+
+func containsAggregation(aggregations []Aggregation, agg Aggregation) bool {
+	for _, existing := range aggregations {
+		if existing == agg {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Write adds the datapoints of the write to the windows of the series it is
+// downsampled to, if any
+func (d *Downsampler) Write(query *storage.WriteQuery) {
+	targets := d.targets(query.Tags)
+	if len(targets) == 0 {
+		return
+	}
+
+	var (
+		id     = query.Tags.ID()
+		cutoff = d.opts.NowFn().Add(-d.opts.BufferPast)
+		late   int
+	)
+
+	d.Lock()
+	for _, t := range targets {
+		key := seriesKey{id: id, storagePolicy: t.storagePolicy}
+		series, ok := d.series[key]
+		if !ok {
+			series = &aggregatedSeries{
+				tags:         query.Tags,
+				aggregations: t.aggregations,
+				keepValues:   hasPercentile(t.aggregations),
+				windows:      make(map[int64]*window),
+			}
+			d.series[key] = series
+		}
+
+		resolution := t.storagePolicy.Resolution().Window
+		for _, dp := range query.Datapoints {
+			start := dp.Timestamp.Truncate(resolution)
+			if !start.Add(resolution).After(cutoff) {
+				// The window may already have been written
+				late++
+				continue
+			}
+
+			w, ok := series.windows[start.UnixNano()]
+			if !ok {
+				w = newWindow()
+				series.windows[start.UnixNano()] = w
+			}
+
+			w.add(dp.Timestamp, dp.Value, series.keepValues)
+		}
+	}
+	d.Unlock()
+
+	d.metrics.late.Inc(int64(late))
+}
+
+func hasPercentile(aggregations []Aggregation) bool {
+	for _, agg := range aggregations {
+		if agg.Type == AggregationPercentile {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d *Downsampler) flushLoop() {
+	defer close(d.doneCh)
+
+	ticker := time.NewTicker(d.opts.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.flush(d.opts.NowFn().Add(-d.opts.BufferPast))
+		case <-d.closeCh:
+			return
+		}
+	}
+}
+
+// flushed is an aggregated series to write to a storage policy
+type flushed struct {
+	storagePolicy policy.StoragePolicy
+	query         *storage.WriteQuery
+}
+
+// flush writes the aggregations of all windows ending before the cutoff
+func (d *Downsampler) flush(cutoff time.Time) {
+	var toWrite []flushed
+
+	d.Lock()
+	for key, series := range d.series {
+		resolution := key.storagePolicy.Resolution().Window
+		var complete []int64
+		for start := range series.windows {
+			end := time.Unix(0, start).Add(resolution)
+			if !end.After(cutoff) {
+				complete = append(complete, start)
+			}
+		}
+
+		if len(complete) == 0 {
+			continue
+		}
+
+		for i, agg := range series.aggregations {
+			datapoints := make(ts.Datapoints, 0, len(complete))
+			for _, start := range complete {
+				datapoints = append(datapoints, ts.Datapoint{
+					Timestamp: time.Unix(0, start).Add(resolution),
+					Value:     series.windows[start].value(agg),
+				})
+			}
+
+			tags := series.tags
+			if i > 0 {
+				tags = tags.Clone()
+				tags[AggregationTagName] = agg.String()
+			}
+
+			toWrite = append(toWrite, flushed{
+				storagePolicy: key.storagePolicy,
+				query: &storage.WriteQuery{
+					Tags:       tags,
+					Datapoints: datapoints,
+					Unit:       xtime.Millisecond,
+				},
+			})
+		}
+
+		for _, start := range complete {
+			delete(series.windows, start)
+		}
+
+		if len(series.windows) == 0 {
+			delete(d.series, key)
+		}
+	}
+	d.Unlock()
+
+	ctx := context.Background()
+	for _, f := range toWrite {
+		if err := d.opts.Writer.Write(ctx, f.storagePolicy, f.query); err != nil {
+			d.metrics.errors.Inc(1)
+			logging.WithContext(ctx).Error("unable to write downsampled series",
+				zap.String("storagePolicy", f.storagePolicy.String()), zap.Any("error", err))
+			continue
+		}
+
+		d.metrics.written.Inc(int64(len(f.query.Datapoints)))
+	}
+}
+
+// Close stops the downsampler, writing the aggregations of all open windows
+func (d *Downsampler) Close() error {
+	close(d.closeCh)
+	<-d.doneCh
+
+	d.flush(time.Unix(0, maxUnixNanos))
+	return nil
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package downsample
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/ts"
+	"github.com/m3db/m3metrics/policy"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type writtenSeries struct {
+	storagePolicy policy.StoragePolicy
+	tags          models.Tags
+	datapoints    ts.Datapoints
+}
+
+type testWriter struct {
+	sync.Mutex
+	written []writtenSeries
+}
+
+func (w *testWriter) Write(_ context.Context, sp policy.StoragePolicy, query *storage.WriteQuery) error {
+	w.Lock()
+	defer w.Unlock()
+	w.written = append(w.written, writtenSeries{
+		storagePolicy: sp,
+		tags:          query.Tags,
+		datapoints:    query.Datapoints,
+	})
+	return nil
+}
+
+func newTestDownsampler(t *testing.T, now *time.Time, rules ...MappingRule) (*Downsampler, *testWriter) {
+	writer := &testWriter{}
+	d, err := NewDownsampler(Options{
+		Rules:         rules,
+		Writer:        writer,
+		BufferPast:    time.Minute,
+		FlushInterval: time.Hour,
+		NowFn:         func() time.Time { return *now },
+	})
+	require.NoError(t, err)
+	return d, writer
+}
+
+func newTestMatchers(t *testing.T, name string) models.Matchers {
+	matcher, err := models.NewMatcher(models.MatchEqual, "__name__", name)
+	require.NoError(t, err)
+	return models.Matchers{matcher}
+}
+
+func TestNewDownsamplerInvalidRule(t *testing.T) {
+	_, err := NewDownsampler(Options{Rules: []MappingRule{{}}})
+	assert.Error(t, err)
+}
+
+func TestDownsamplerFlush(t *testing.T) {
+	var (
+		start  = time.Unix(0, 0).Add(time.Hour)
+		now    = start
+		sp     = policy.NewStoragePolicy(time.Minute, xtime.Second, 24*time.Hour)
+		sum, _ = ParseAggregation("sum")
+		max, _ = ParseAggregation("max")
+	)
+
+	d, writer := newTestDownsampler(t, &now, MappingRule{
+		Filter:          newTestMatchers(t, "foo"),
+		Aggregations:    []Aggregation{sum, max},
+		StoragePolicies: []policy.StoragePolicy{sp},
+	})
+
+	tags := models.Tags{"__name__": "foo", "a": "b"}
+	d.Write(&storage.WriteQuery{
+		Tags: tags,
+		Datapoints: ts.Datapoints{
+			{Timestamp: start, Value: 1},
+			{Timestamp: start.Add(10 * time.Second), Value: 2},
+			{Timestamp: start.Add(time.Minute), Value: 5},
+		},
+	})
+
+	// Series not matching any rule are not downsampled
+	d.Write(&storage.WriteQuery{
+		Tags:       models.Tags{"__name__": "bar"},
+		Datapoints: ts.Datapoints{{Timestamp: start, Value: 1}},
+	})
+
+	// The first window is only complete once the buffer past has elapsed
+	d.flush(start.Add(time.Minute).Add(-time.Second))
+	assert.Len(t, writer.written, 0)
+
+	d.flush(start.Add(time.Minute))
+	require.Len(t, writer.written, 2)
+	sort.Slice(writer.written, func(i, j int) bool {
+		return len(writer.written[i].tags) < len(writer.written[j].tags)
+	})
+
+	assert.Equal(t, sp, writer.written[0].storagePolicy)
+	assert.Equal(t, tags, writer.written[0].tags)
+	assert.Equal(t, ts.Datapoints{{Timestamp: start.Add(time.Minute), Value: 3}}, writer.written[0].datapoints)
+	assert.Equal(t, "max", writer.written[1].tags[AggregationTagName])
+	assert.Equal(t, ts.Datapoints{{Timestamp: start.Add(time.Minute), Value: 2}}, writer.written[1].datapoints)
+
+	// Datapoints for windows past the buffer are dropped
+	now = start.Add(2 * time.Minute)
+	d.Write(&storage.WriteQuery{
+		Tags:       tags,
+		Datapoints: ts.Datapoints{{Timestamp: start.Add(30 * time.Second), Value: 100}},
+	})
+
+	// Closing writes the remaining open windows
+	writer.written = nil
+	require.NoError(t, d.Close())
+	require.Len(t, writer.written, 2)
+	for _, written := range writer.written {
+		assert.Equal(t, ts.Datapoints{{Timestamp: start.Add(2 * time.Minute), Value: 5}}, written.datapoints)
+	}
+
+	assert.Len(t, d.series, 0)
+}
+
+func TestDownsamplerMergesRules(t *testing.T) {
+	var (
+		now    = time.Unix(0, 0)
+		sp     = policy.NewStoragePolicy(time.Minute, xtime.Second, 24*time.Hour)
+		min, _ = ParseAggregation("min")
+	)
+
+	d, _ := newTestDownsampler(t, &now,
+		MappingRule{Filter: newTestMatchers(t, "foo"), StoragePolicies: []policy.StoragePolicy{sp}},
+		MappingRule{Aggregations: []Aggregation{min}, StoragePolicies: []policy.StoragePolicy{sp}},
+	)
+	defer d.Close()
+
+	targets := d.targets(models.Tags{"__name__": "foo"})
+	require.Len(t, targets, 1)
+	assert.Equal(t, []string{"last", "min"}, aggregationNames(targets[0].aggregations))
+
+	targets = d.targets(models.Tags{"__name__": "bar"})
+	require.Len(t, targets, 1)
+	assert.Equal(t, []string{"min"}, aggregationNames(targets[0].aggregations))
+}
+
+func aggregationNames(aggregations []Aggregation) []string {
+	names := make([]string, 0, len(aggregations))
+	for _, agg := range aggregations {
+		names = append(names, agg.String())
+	}
+
+	return names
+}
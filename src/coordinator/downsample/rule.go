@@ -0,0 +1,72 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package downsample
+
+import (
+	"errors"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3metrics/policy"
+)
+
+var (
+	errNoStoragePolicies = errors.New("mapping rule has no storage policies")
+)
+
+// MappingRule downsamples the series matching its filter into the
+// aggregated namespaces of its storage policies
+type MappingRule struct {
+	// Filter matches the series the rule applies to
+	Filter models.Matchers
+	// Aggregations are the aggregations to produce, the first of which is
+	// written under the tags of the series so that queries read it
+	// seamlessly across namespaces. Defaults to the last datapoint
+	Aggregations []Aggregation
+	// StoragePolicies are the resolutions and retentions to downsample to
+	StoragePolicies []policy.StoragePolicy
+}
+
+// Validate validates the mapping rule
+func (r MappingRule) Validate() error {
+	if len(r.StoragePolicies) == 0 {
+		return errNoStoragePolicies
+	}
+
+	return nil
+}
+
+func (r MappingRule) matches(tags models.Tags) bool {
+	for _, matcher := range r.Filter {
+		if !matcher.Matches(tags[matcher.Name]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r MappingRule) aggregations() []Aggregation {
+	if len(r.Aggregations) == 0 {
+		return []Aggregation{{Type: AggregationLast, name: "last"}}
+	}
+
+	return r.Aggregations
+}
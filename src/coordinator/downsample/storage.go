@@ -0,0 +1,70 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package downsample
+
+import (
+	"context"
+
+	"github.com/m3db/m3db/src/coordinator/storage"
+)
+
+type downsampledStorage struct {
+	storage.Storage
+	downsampler *Downsampler
+}
+
+// NewStorage wraps a storage so that the series written to it are also
+// downsampled, closing the downsampler when the storage is closed
+func NewStorage(store storage.Storage, downsampler *Downsampler) storage.Storage {
+	return &downsampledStorage{
+		Storage:     store,
+		downsampler: downsampler,
+	}
+}
+
+func (s *downsampledStorage) Write(ctx context.Context, query *storage.WriteQuery) error {
+	if err := s.Storage.Write(ctx, query); err != nil {
+		return err
+	}
+
+	s.downsampler.Write(query)
+	return nil
+}
+
+// CompleteTags completes the tags from the underlying storage, so that its
+// index is used rather than the tags of every matching series
+func (s *downsampledStorage) CompleteTags(
+	ctx context.Context,
+	query *storage.CompleteTagsQuery,
+	options *storage.FetchOptions,
+) (*storage.CompleteTagsResult, error) {
+	return storage.CompleteTags(ctx, s.Storage, query, options)
+}
+
+func (s *downsampledStorage) Close() error {
+	// Close the downsampler first so the open windows are written while the
+	// underlying storage is still available
+	if err := s.downsampler.Close(); err != nil {
+		return err
+	}
+
+	return s.Storage.Close()
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package downsample
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/m3db/m3db/src/coordinator/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// completerStorage completes tags from its index and fails searches, which
+// the completion falls back to for stores without an index
+type completerStorage struct {
+	storage.Storage
+}
+
+func (s *completerStorage) FetchTags(
+	context.Context,
+	*storage.FetchQuery,
+	*storage.FetchOptions,
+) (*storage.SearchResults, error) {
+	return nil, errors.New("unexpected search")
+}
+
+func (s *completerStorage) CompleteTags(
+	context.Context,
+	*storage.CompleteTagsQuery,
+	*storage.FetchOptions,
+) (*storage.CompleteTagsResult, error) {
+	return &storage.CompleteTagsResult{Terms: []string{"a", "b"}}, nil
+}
+
+func TestStorageCompleteTags(t *testing.T) {
+	store := NewStorage(&completerStorage{}, nil)
+	_, ok := store.(storage.TagCompleter)
+	require.True(t, ok)
+
+	result, err := storage.CompleteTags(context.TODO(), store,
+		&storage.CompleteTagsQuery{TagName: "host"}, &storage.FetchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, result.Terms)
+}
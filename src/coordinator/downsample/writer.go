@@ -0,0 +1,78 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package downsample
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/dbnode/client"
+	"github.com/m3db/m3metrics/policy"
+	"github.com/m3db/m3x/ident"
+)
+
+// Writer writes downsampled series to the namespace of a storage policy
+type Writer interface {
+	Write(ctx context.Context, sp policy.StoragePolicy, query *storage.WriteQuery) error
+}
+
+type sessionWriter struct {
+	session    client.Session
+	namespaces map[policy.StoragePolicy]ident.ID
+}
+
+// NewSessionWriter creates a writer writing downsampled series with a dbnode
+// session to the namespaces of their storage policies
+func NewSessionWriter(session client.Session, namespaces map[policy.StoragePolicy]string) Writer {
+	ids := make(map[policy.StoragePolicy]ident.ID, len(namespaces))
+	for sp, namespace := range namespaces {
+		ids[sp] = ident.StringID(namespace)
+	}
+
+	return &sessionWriter{
+		session:    session,
+		namespaces: ids,
+	}
+}
+
+func (w *sessionWriter) Write(ctx context.Context, sp policy.StoragePolicy, query *storage.WriteQuery) error {
+	namespace, ok := w.namespaces[sp]
+	if !ok {
+		return fmt.Errorf("no namespace for storage policy %s", sp.String())
+	}
+
+	id := ident.StringID(query.Tags.ID())
+	for _, dp := range query.Datapoints {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		tags := storage.TagsToIdentTagIterator(query.Tags)
+		if err := w.session.WriteTagged(namespace, id, tags, dp.Timestamp, dp.Value, query.Unit, query.Annotation); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -23,6 +23,7 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 var (
@@ -50,8 +51,76 @@ var (
 	ErrNoClientAddresses = errors.New("no client addresses given")
 )
 
+const (
+	// ResourceSeries is the resource name for fetched series.
+	ResourceSeries = "series"
+	// ResourceDatapoints is the resource name for decoded datapoints.
+	ResourceDatapoints = "datapoints"
+	// ResourceQueries is the resource name for concurrently running queries.
+	ResourceQueries = "queries"
+
+	// LimitScopeQuery is the scope of limits applied to a single query.
+	LimitScopeQuery = "query"
+	// LimitScopeGlobal is the scope of limits applied across all running queries.
+	LimitScopeGlobal = "global"
+)
+
+// ResourceLimitError is returned when a query is rejected because it exceeded
+// a configured resource limit.
+type ResourceLimitError struct {
+	// Resource is the name of the resource that exceeded its limit.
+	Resource string
+	// Scope is either LimitScopeQuery or LimitScopeGlobal.
+	Scope string
+	// Limit is the configured limit for the resource.
+	Limit int64
+}
+
+// NewResourceLimitError creates a new resource limit error.
+func NewResourceLimitError(resource, scope string, limit int64) error {
+	return &ResourceLimitError{Resource: resource, Scope: scope, Limit: limit}
+}
+
+func (e *ResourceLimitError) Error() string {
+	return fmt.Sprintf("%s limit of %d %s exceeded", e.Scope, e.Limit, e.Resource)
+}
+
+// Global returns true if the limit applies across all running queries, in
+// which case the query may succeed if retried later.
+func (e *ResourceLimitError) Global() bool {
+	return e.Scope == LimitScopeGlobal
+}
+
+// IsResourceLimitError returns true if the error is a resource limit error.
+func IsResourceLimitError(err error) bool {
+	_, ok := err.(*ResourceLimitError)
+	return ok
+}
+
 // ErrMaxConcurrentQueriesLimitExceeded is an error when the query cannot be run
 // because the maximum number of queries has been reached.
-func ErrMaxConcurrentQueriesLimitExceeded(n, limit int) error {
-	return fmt.Errorf("max concurrent queries limit exceeded(%d, %d)", n, limit)
+func ErrMaxConcurrentQueriesLimitExceeded(limit int) error {
+	return NewResourceLimitError(ResourceQueries, LimitScopeGlobal, int64(limit))
+}
+
+// PartialResultError is returned instead of an incomplete result when
+// queries are configured to fail rather than return partial results.
+type PartialResultError struct {
+	// Warnings describe why the result was incomplete.
+	Warnings []string
+}
+
+// NewPartialResultError creates a new partial result error.
+func NewPartialResultError(warnings []string) error {
+	return &PartialResultError{Warnings: warnings}
+}
+
+func (e *PartialResultError) Error() string {
+	return fmt.Sprintf("partial results: %s", strings.Join(e.Warnings, "; "))
+}
+
+// IsPartialResultError returns true if the error is a partial result error.
+func IsPartialResultError(err error) bool {
+	_, ok := err.(*PartialResultError)
+	return ok
 }
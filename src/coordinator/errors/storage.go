@@ -45,4 +45,8 @@ var (
 
 	// ErrZeroInterval is an error returned when fetch interval is 0.
 	ErrZeroInterval = errors.New("interval cannot be 0")
+
+	// ErrSubPlanStoresNotDisjoint is returned when pushing down a sub plan to
+	// stores which may hold the same series, as its results can't be merged.
+	ErrSubPlanStoresNotDisjoint = errors.New("sub plans can only be pushed down to stores holding disjoint series")
 )
@@ -0,0 +1,192 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+
+	"github.com/uber-go/tally"
+)
+
+const (
+	// seriesOverheadBytes is the estimated size of a series besides its
+	// values and tags
+	seriesOverheadBytes = 64
+)
+
+// Entry is the result of a query over a single interval.
+type Entry struct {
+	Series []Series
+}
+
+// Series is a series of an entry, with a value for each step of the interval.
+type Series struct {
+	Name   string
+	Tags   models.Tags
+	Values []float64
+}
+
+// size returns the estimated size of the entry in bytes
+func (e *Entry) size() int64 {
+	var size int64
+	for _, s := range e.Series {
+		size += seriesOverheadBytes + int64(len(s.Name)) + int64(8*len(s.Values))
+		for k, v := range s.Tags {
+			size += int64(len(k) + len(v))
+		}
+	}
+
+	return size
+}
+
+// Backend stores the entries of the cache, bounded in size.
+type Backend interface {
+	// Get returns the entry of a key, if stored.
+	Get(key string) (*Entry, bool)
+
+	// Set stores the entry of a key, evicting the least recently used
+	// entries if the backend is full.
+	Set(key string, entry *Entry)
+}
+
+type backendMetrics struct {
+	evictions tally.Counter
+	errors    tally.Counter
+	bytes     tally.Gauge
+}
+
+func newBackendMetrics(scope tally.Scope) backendMetrics {
+	return backendMetrics{
+		evictions: scope.Counter("evictions"),
+		errors:    scope.Counter("errors"),
+		bytes:     scope.Gauge("bytes"),
+	}
+}
+
+type lruItem struct {
+	key   string
+	size  int64
+	entry *Entry
+}
+
+// lru is an index of items bounded by their total size, it is not safe for
+// concurrent use
+type lru struct {
+	maxBytes int64
+	bytes    int64
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+func newLRU(maxBytes int64) *lru {
+	return &lru{
+		maxBytes: maxBytes,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// get returns the item of a key and marks it as the most recently used
+func (l *lru) get(key string) (*lruItem, bool) {
+	elem, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	l.ll.MoveToFront(elem)
+	return elem.Value.(*lruItem), true
+}
+
+// add adds an item, replacing any item of the same key, and returns the
+// items evicted to make room for it. Items larger than the maximum size are
+// evicted straight away.
+func (l *lru) add(item *lruItem) []*lruItem {
+	if item.size > l.maxBytes {
+		l.remove(item.key)
+		return []*lruItem{item}
+	}
+
+	l.remove(item.key)
+	l.items[item.key] = l.ll.PushFront(item)
+	l.bytes += item.size
+
+	var evicted []*lruItem
+	for l.bytes > l.maxBytes {
+		oldest := l.ll.Back()
+		evicted = append(evicted, oldest.Value.(*lruItem))
+		l.removeElement(oldest)
+	}
+
+	return evicted
+}
+
+// remove removes the item of a key
+func (l *lru) remove(key string) {
+	if elem, ok := l.items[key]; ok {
+		l.removeElement(elem)
+	}
+}
+
+func (l *lru) removeElement(elem *list.Element) {
+	item := elem.Value.(*lruItem)
+	l.ll.Remove(elem)
+	delete(l.items, item.key)
+	l.bytes -= item.size
+}
+
+type memoryBackend struct {
+	sync.Mutex
+	lru     *lru
+	metrics backendMetrics
+}
+
+// NewMemoryBackend returns a backend which holds entries in memory, up to
+// the given estimated size in bytes.
+func NewMemoryBackend(maxBytes int64, scope tally.Scope) Backend {
+	return &memoryBackend{
+		lru:     newLRU(maxBytes),
+		metrics: newBackendMetrics(scope),
+	}
+}
+
+func (b *memoryBackend) Get(key string) (*Entry, bool) {
+	b.Lock()
+	defer b.Unlock()
+
+	item, ok := b.lru.get(key)
+	if !ok {
+		return nil, false
+	}
+
+	return item.entry, true
+}
+
+func (b *memoryBackend) Set(key string, entry *Entry) {
+	b.Lock()
+	defer b.Unlock()
+
+	evicted := b.lru.add(&lruItem{key: key, size: entry.size(), entry: entry})
+	b.metrics.evictions.Inc(int64(len(evicted)))
+	b.metrics.bytes.Update(float64(b.lru.bytes))
+}
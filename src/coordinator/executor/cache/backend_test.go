@@ -0,0 +1,79 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func newTestEntry(id string, values ...float64) *Entry {
+	return &Entry{Series: []Series{
+		{Name: "foo", Tags: models.Tags{"id": id}, Values: values},
+	}}
+}
+
+func TestEntrySize(t *testing.T) {
+	entry := newTestEntry("1", 1, 2, 3)
+	assert.Equal(t, int64(seriesOverheadBytes+3+24+3), entry.size())
+}
+
+func TestMemoryBackend(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	entrySize := newTestEntry("1", 1).size()
+	backend := NewMemoryBackend(3*entrySize, scope)
+
+	for i := 0; i < 4; i++ {
+		key := fmt.Sprint(i)
+		backend.Set(key, newTestEntry(key, float64(i)))
+	}
+
+	// The least recently used entry is evicted
+	_, ok := backend.Get("0")
+	assert.False(t, ok)
+
+	entry, ok := backend.Get("1")
+	require.True(t, ok)
+	assert.Equal(t, newTestEntry("1", 1), entry)
+
+	backend.Set("4", newTestEntry("4", 4))
+	_, ok = backend.Get("1")
+	assert.True(t, ok)
+	_, ok = backend.Get("2")
+	assert.False(t, ok)
+
+	// Entries larger than the backend are not stored
+	backend.Set("large", newTestEntry("large", make([]float64, 100)...))
+	_, ok = backend.Get("large")
+	assert.False(t, ok)
+
+	counters := scope.Snapshot().Counters()
+	assert.Equal(t, int64(3), counters["evictions+"].Value())
+
+	gauges := scope.Snapshot().Gauges()
+	assert.Equal(t, float64(3*entrySize), gauges["bytes+"].Value())
+}
@@ -0,0 +1,443 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package cache caches the results of range queries over the intervals of
+// their time range which can no longer change, so that queries repeated over
+// a moving time range only execute over the most recent data.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/functions/utils"
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/policy/resolver"
+	"github.com/m3db/m3db/src/coordinator/storage"
+
+	"github.com/m3db/m3metrics/policy"
+	"github.com/uber-go/tally"
+)
+
+const (
+	// DefaultIntervalSteps is the default number of steps of the intervals
+	// results are cached for.
+	DefaultIntervalSteps = 60
+
+	// DefaultBufferPast is the default duration after which the datapoints
+	// of a time can no longer be written.
+	DefaultBufferPast = 10 * time.Minute
+)
+
+// Options are the options of a cache.
+type Options struct {
+	// IntervalSteps is the number of steps of the intervals results are
+	// cached for, intervals are aligned to multiples of their duration.
+	IntervalSteps int
+
+	// BufferPast is the duration after which the datapoints of a time can no
+	// longer be written, it should be at least the buffer past of the
+	// namespaces queried. Intervals ending before this long ago are
+	// immutable and cached. With a resolver it is the default buffer past of
+	// the namespaces without one.
+	BufferPast time.Duration
+
+	// Resolver resolves the namespaces a query reads each part of its time
+	// range from, the data of a namespace can no longer change once its
+	// buffer past and resolution have passed. If nil, only BufferPast is used.
+	Resolver resolver.PolicyResolver
+
+	// BufferPasts is the buffer past of the namespaces by storage policy.
+	BufferPasts map[policy.StoragePolicy]time.Duration
+}
+
+// version identifies the options results are cached with, so that results
+// cached with different options, such as on disk by a previous process, are
+// never read.
+func (o Options) version() string {
+	policies := make([]string, 0, len(o.BufferPasts))
+	for sp, bufferPast := range o.BufferPasts {
+		policies = append(policies, fmt.Sprintf("%s:%d", sp.String(), bufferPast))
+	}
+
+	sort.Strings(policies)
+	return fmt.Sprintf("%d/%d/%v", o.IntervalSteps, o.BufferPast, policies)
+}
+
+// ExecuteFn executes a query over the time range of the params.
+type ExecuteFn func(
+	ctx context.Context,
+	params models.RequestParams,
+) ([]storage.Block, storage.Warnings, error)
+
+// Cache caches the results of range queries.
+type Cache struct {
+	backend Backend
+	opts    Options
+	version string
+	metrics cacheMetrics
+}
+
+type cacheMetrics struct {
+	hits        tally.Counter
+	misses      tally.Counter
+	uncacheable tally.Counter
+}
+
+// NewCache returns a new cache storing results in the given backend.
+func NewCache(backend Backend, opts Options, scope tally.Scope) *Cache {
+	if opts.IntervalSteps <= 0 {
+		opts.IntervalSteps = DefaultIntervalSteps
+	}
+
+	if opts.BufferPast <= 0 {
+		opts.BufferPast = DefaultBufferPast
+	}
+
+	return &Cache{
+		backend: backend,
+		opts:    opts,
+		version: opts.version(),
+		metrics: cacheMetrics{
+			hits:        scope.Counter("hits"),
+			misses:      scope.Counter("misses"),
+			uncacheable: scope.Counter("uncacheable"),
+		},
+	}
+}
+
+// Execute executes the query over the time range of the params, using the
+// cached results of the query identified by the key where possible. Only
+// range queries starting at a multiple of their step are cached, so that
+// their steps line up with the intervals.
+func (c *Cache) Execute(
+	ctx context.Context,
+	key string,
+	params models.RequestParams,
+	execute ExecuteFn,
+) ([]storage.Block, storage.Warnings, error) {
+	step := params.Step
+	if step <= 0 || params.End.Sub(params.Start) <= step ||
+		params.Start.UnixNano()%int64(step) != 0 {
+		c.metrics.uncacheable.Inc(1)
+		return execute(ctx, params)
+	}
+
+	cutoff, err := c.cutoff(ctx, params)
+	if err != nil {
+		c.metrics.uncacheable.Inc(1)
+		return execute(ctx, params)
+	}
+
+	var (
+		fingerprint  = fingerprint(c.version, key, step)
+		intervalSize = time.Duration(c.opts.IntervalSteps) * step
+		result       = newResultBuilder(params.Start, params.End, step)
+		warnings     storage.Warnings
+		missed       []time.Time
+		tailStart    = params.End
+	)
+
+	// Intervals are looked up until the first one which may still change,
+	// from which the query is executed without caching
+	startNanos := params.Start.UnixNano()
+	first := time.Unix(0, startNanos-startNanos%int64(intervalSize))
+	for start := first; start.Before(params.End); start = start.Add(intervalSize) {
+		end := start.Add(intervalSize)
+		if end.After(cutoff) {
+			tailStart = start
+			if tailStart.Before(params.Start) {
+				tailStart = params.Start
+			}
+			break
+		}
+
+		if entry, ok := c.backend.Get(entryKey(fingerprint, start)); ok {
+			c.metrics.hits.Inc(1)
+			result.addSeries(start, entry.Series)
+			continue
+		}
+
+		c.metrics.misses.Inc(1)
+		missed = append(missed, start)
+	}
+
+	// Queries entirely over data which may still change are not cached
+	if tailStart.Equal(params.Start) {
+		return execute(ctx, params)
+	}
+
+	// Consecutive missed intervals are executed at once
+	for len(missed) > 0 {
+		n := 1
+		for n < len(missed) && missed[n].Equal(missed[n-1].Add(intervalSize)) {
+			n++
+		}
+
+		w, err := c.executeIntervals(ctx, params, execute, fingerprint, missed[:n], result)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		warnings = append(warnings, w...)
+		missed = missed[n:]
+	}
+
+	if tailStart.Before(params.End) {
+		tailParams := params
+		tailParams.Start = tailStart
+		blocks, w, err := execute(ctx, tailParams)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		tail := newResultBuilder(tailStart, params.End, step)
+		if err := tail.addBlocks(blocks); err != nil {
+			return nil, nil, err
+		}
+
+		result.addSeries(tailStart, tail.series)
+		warnings = append(warnings, w...)
+	}
+
+	return []storage.Block{result.build()}, warnings, nil
+}
+
+// cutoff returns the time after which the results of the query may still
+// change, which is the latest time any of the namespaces it reads from may
+// still be written to
+func (c *Cache) cutoff(ctx context.Context, params models.RequestParams) (time.Time, error) {
+	if c.opts.Resolver == nil {
+		return params.Now.Add(-c.opts.BufferPast), nil
+	}
+
+	requests, err := c.opts.Resolver.Resolve(ctx, nil, params.Start, params.End)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	delay := c.opts.BufferPast
+	for _, request := range requests {
+		for _, r := range request.Ranges {
+			bufferPast, ok := c.opts.BufferPasts[r.StoragePolicy]
+			if !ok || bufferPast <= 0 {
+				bufferPast = c.opts.BufferPast
+			}
+
+			// An aggregated datapoint is only written once its whole window
+			// has passed
+			if d := bufferPast + r.StoragePolicy.Resolution().Window; d > delay {
+				delay = d
+			}
+		}
+	}
+
+	return params.Now.Add(-delay), nil
+}
+
+// executeIntervals executes the query over consecutive intervals and caches
+// their results, unless they may be incomplete
+func (c *Cache) executeIntervals(
+	ctx context.Context,
+	params models.RequestParams,
+	execute ExecuteFn,
+	fingerprint uint64,
+	starts []time.Time,
+	result *resultBuilder,
+) (storage.Warnings, error) {
+	var (
+		step         = params.Step
+		intervalSize = time.Duration(c.opts.IntervalSteps) * step
+		end          = starts[len(starts)-1].Add(intervalSize)
+	)
+
+	params.Start = starts[0]
+	params.End = end
+	blocks, warnings, err := execute(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	executed := newResultBuilder(starts[0], end, step)
+	if err := executed.addBlocks(blocks); err != nil {
+		return nil, err
+	}
+
+	for i, start := range starts {
+		entry := executed.entry(i*c.opts.IntervalSteps, c.opts.IntervalSteps)
+		if len(warnings) == 0 {
+			c.backend.Set(entryKey(fingerprint, start), entry)
+		}
+
+		result.addSeries(start, entry.Series)
+	}
+
+	return warnings, nil
+}
+
+// fingerprint identifies the results of a query at a step, cached with the
+// given version of the options
+func fingerprint(version, key string, step time.Duration) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s\x00%s\x00%d", version, key, step)
+	return h.Sum64()
+}
+
+func entryKey(fingerprint uint64, start time.Time) string {
+	return fmt.Sprintf("%016x-%d", fingerprint, start.UnixNano())
+}
+
+// resultBuilder merges series by their tags over a time range
+type resultBuilder struct {
+	start  time.Time
+	end    time.Time
+	step   time.Duration
+	steps  int
+	index  map[string]int
+	series []Series
+}
+
+func newResultBuilder(start, end time.Time, step time.Duration) *resultBuilder {
+	return &resultBuilder{
+		start: start,
+		end:   end,
+		step:  step,
+		steps: int(end.Sub(start) / step),
+		index: make(map[string]int),
+	}
+}
+
+func (r *resultBuilder) seriesFor(name string, tags models.Tags) *Series {
+	id := tags.ID()
+	idx, ok := r.index[id]
+	if !ok {
+		values := make([]float64, r.steps)
+		for i := range values {
+			values[i] = math.NaN()
+		}
+
+		idx = len(r.series)
+		r.index[id] = idx
+		r.series = append(r.series, Series{Name: name, Tags: tags, Values: values})
+	}
+
+	return &r.series[idx]
+}
+
+// addSeries adds series whose first value is at the given start, values
+// outside of the time range are dropped
+func (r *resultBuilder) addSeries(start time.Time, series []Series) {
+	offset := int(start.Sub(r.start) / r.step)
+	for _, s := range series {
+		values := r.seriesFor(s.Name, s.Tags).Values
+		for i, v := range s.Values {
+			idx := offset + i
+			if idx < 0 || idx >= len(values) || math.IsNaN(v) {
+				continue
+			}
+
+			values[idx] = v
+		}
+	}
+}
+
+// addBlocks adds the series of blocks and closes them
+func (r *resultBuilder) addBlocks(blocks []storage.Block) error {
+	var closeErr error
+	for _, b := range blocks {
+		seriesMeta := utils.FlattenMetadata(b.Meta(), b.SeriesMeta())
+		series := make([]*Series, len(seriesMeta))
+		for i, m := range seriesMeta {
+			series[i] = r.seriesFor(m.Name, m.Tags)
+		}
+
+		iter := b.StepIter()
+		for iter.Next() {
+			step := iter.Current()
+			t := step.Time()
+			if t.Before(r.start) || !t.Before(r.end) {
+				continue
+			}
+
+			idx := int(t.Sub(r.start) / r.step)
+			for i, v := range step.Values() {
+				if i < len(series) && !math.IsNaN(v) {
+					series[i].Values[idx] = v
+				}
+			}
+		}
+
+		if err := b.Close(); err != nil {
+			closeErr = err
+		}
+	}
+
+	return closeErr
+}
+
+// entry returns the series over the given steps, leaving out series
+// without any value over them
+func (r *resultBuilder) entry(offset, steps int) *Entry {
+	entry := &Entry{}
+	for _, s := range r.series {
+		values := s.Values[offset : offset+steps]
+		for _, v := range values {
+			if !math.IsNaN(v) {
+				entry.Series = append(entry.Series, Series{
+					Name:   s.Name,
+					Tags:   s.Tags,
+					Values: append([]float64(nil), values...),
+				})
+				break
+			}
+		}
+	}
+
+	return entry
+}
+
+// build returns a block of the series
+func (r *resultBuilder) build() storage.Block {
+	seriesMeta := make([]storage.SeriesMeta, len(r.series))
+	for i, s := range r.series {
+		seriesMeta[i] = storage.SeriesMeta{Name: s.Name, Tags: s.Tags}
+	}
+
+	meta := storage.BlockMetadata{
+		Bounds: storage.Bounds{Start: r.start, End: r.end, StepSize: r.step},
+	}
+
+	builder := storage.NewColumnBlockBuilder(meta, seriesMeta)
+	for i := 0; i < r.steps; i++ {
+		values := make([]float64, len(r.series))
+		for j, s := range r.series {
+			values[j] = s.Values[i]
+		}
+
+		// The index is always within the steps of the block
+		_ = builder.AppendValues(i, values)
+	}
+
+	return builder.Build()
+}
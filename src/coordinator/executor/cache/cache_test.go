@@ -0,0 +1,365 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/functions/utils"
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/policy/resolver"
+	"github.com/m3db/m3db/src/coordinator/storage"
+
+	"github.com/m3db/m3metrics/policy"
+	xtime "github.com/m3db/m3x/time"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+// testExecutor executes queries over two series, whose values are the
+// minutes since the epoch, with the second series only having values from
+// the given time
+type testExecutor struct {
+	secondFrom time.Time
+	warnings   storage.Warnings
+	executed   []models.RequestParams
+}
+
+func (e *testExecutor) execute(
+	_ context.Context,
+	params models.RequestParams,
+) ([]storage.Block, storage.Warnings, error) {
+	e.executed = append(e.executed, params)
+
+	bounds := storage.Bounds{Start: params.Start, End: params.End, StepSize: params.Step}
+	builder := storage.NewColumnBlockBuilder(storage.BlockMetadata{
+		Bounds: bounds,
+		Tags:   models.Tags{"common": "tag"},
+	}, []storage.SeriesMeta{
+		{Name: "first", Tags: models.Tags{"id": "1"}},
+		{Name: "second", Tags: models.Tags{"id": "2"}},
+	})
+
+	for i := 0; i < bounds.Steps(); i++ {
+		t, err := bounds.TimeForIndex(i)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		second := math.NaN()
+		if !t.Before(e.secondFrom) {
+			second = -testValue(t)
+		}
+
+		if err := builder.AppendValues(i, []float64{testValue(t), second}); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return []storage.Block{builder.Build()}, e.warnings, nil
+}
+
+func testValue(t time.Time) float64 {
+	return float64(t.Unix() / 60)
+}
+
+type testResult struct {
+	name   string
+	tags   models.Tags
+	values []float64
+}
+
+func blockResults(t *testing.T, blocks []storage.Block) []testResult {
+	require.Len(t, blocks, 1)
+
+	var (
+		results    []testResult
+		seriesMeta = utils.FlattenMetadata(blocks[0].Meta(), blocks[0].SeriesMeta())
+		iter       = blocks[0].SeriesIter()
+	)
+
+	for i := 0; iter.Next(); i++ {
+		series := iter.Current()
+		values := make([]float64, series.Len())
+		for j := range values {
+			values[j] = series.Values().ValueAt(j)
+		}
+
+		results = append(results, testResult{
+			name:   seriesMeta[i].Name,
+			tags:   seriesMeta[i].Tags,
+			values: values,
+		})
+	}
+
+	return results
+}
+
+func assertSameResults(t *testing.T, expected, actual []testResult) {
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		assert.Equal(t, expected[i].name, actual[i].name)
+		assert.Equal(t, expected[i].tags, actual[i].tags)
+		require.Len(t, actual[i].values, len(expected[i].values))
+		for j, v := range expected[i].values {
+			if math.IsNaN(v) {
+				assert.True(t, math.IsNaN(actual[i].values[j]), "expected NaN at %d", j)
+			} else {
+				assert.Equal(t, v, actual[i].values[j], "unexpected value at %d", j)
+			}
+		}
+	}
+}
+
+func TestCacheExecute(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	cache := NewCache(NewMemoryBackend(1<<20, scope), Options{
+		IntervalSteps: 60,
+		BufferPast:    10 * time.Minute,
+	}, scope)
+
+	now := time.Unix(0, 0).Add(1000 * time.Hour).Add(30 * time.Minute)
+	executor := &testExecutor{secondFrom: now.Add(-150 * time.Minute)}
+	params := models.RequestParams{
+		Start: now.Add(-3 * time.Hour),
+		End:   now.Add(time.Minute),
+		Now:   now,
+		Step:  time.Minute,
+		Query: "foo",
+	}
+
+	expected, _, err := executor.execute(context.TODO(), params)
+	require.NoError(t, err)
+	executor.executed = nil
+
+	// The intervals before the cutoff are executed at once and cached, the
+	// rest of the query is executed separately
+	blocks, warnings, err := cache.Execute(context.TODO(), "foo", params, executor.execute)
+	require.NoError(t, err)
+	assert.Len(t, warnings, 0)
+	assertSameResults(t, blockResults(t, expected), blockResults(t, blocks))
+
+	require.Len(t, executor.executed, 2)
+	assert.Equal(t, now.Add(-210*time.Minute), executor.executed[0].Start)
+	assert.Equal(t, now.Add(-30*time.Minute), executor.executed[0].End)
+	assert.Equal(t, now.Add(-30*time.Minute), executor.executed[1].Start)
+	assert.Equal(t, params.End, executor.executed[1].End)
+
+	// Moving the query forward only executes it from the cutoff
+	executor.executed = nil
+	params.Start = params.Start.Add(5 * time.Minute)
+	params.End = params.End.Add(5 * time.Minute)
+	params.Now = params.Now.Add(5 * time.Minute)
+
+	expected, _, err = executor.execute(context.TODO(), params)
+	require.NoError(t, err)
+	executor.executed = nil
+
+	blocks, _, err = cache.Execute(context.TODO(), "foo", params, executor.execute)
+	require.NoError(t, err)
+	assertSameResults(t, blockResults(t, expected), blockResults(t, blocks))
+
+	require.Len(t, executor.executed, 1)
+	assert.Equal(t, now.Add(-30*time.Minute), executor.executed[0].Start)
+
+	// A different query or step is not served from the cache
+	executor.executed = nil
+	_, _, err = cache.Execute(context.TODO(), "bar", params, executor.execute)
+	require.NoError(t, err)
+	assert.Len(t, executor.executed, 2)
+
+	counters := scope.Snapshot().Counters()
+	assert.Equal(t, int64(3), counters["hits+"].Value())
+	assert.Equal(t, int64(6), counters["misses+"].Value())
+}
+
+func TestCacheExecuteNamespaceCutoff(t *testing.T) {
+	now := time.Unix(0, 0).Add(1000 * time.Hour).Add(30 * time.Minute)
+	var (
+		raw        = policy.NewStoragePolicy(0, xtime.Second, 2*time.Hour)
+		aggregated = policy.NewStoragePolicy(time.Hour, xtime.Second, 720*time.Hour)
+	)
+
+	policyResolver, err := resolver.NewResolutionResolver(
+		[]policy.StoragePolicy{raw, aggregated},
+		func() time.Time { return now },
+	)
+	require.NoError(t, err)
+
+	cache := NewCache(NewMemoryBackend(1<<20, tally.NoopScope), Options{
+		IntervalSteps: 60,
+		BufferPast:    10 * time.Minute,
+		Resolver:      policyResolver,
+		BufferPasts:   map[policy.StoragePolicy]time.Duration{aggregated: 5 * time.Minute},
+	}, tally.NoopScope)
+
+	executor := &testExecutor{}
+	params := models.RequestParams{
+		Start: now.Add(-3 * time.Hour),
+		End:   now,
+		Now:   now,
+		Step:  time.Minute,
+	}
+
+	// The start of the query is read from the hourly namespace, whose
+	// datapoints are written up to its resolution and buffer past later
+	_, _, err = cache.Execute(context.TODO(), "foo", params, executor.execute)
+	require.NoError(t, err)
+
+	require.Len(t, executor.executed, 2)
+	assert.Equal(t, now.Add(-210*time.Minute), executor.executed[0].Start)
+	assert.Equal(t, now.Add(-90*time.Minute), executor.executed[0].End)
+	assert.Equal(t, now.Add(-90*time.Minute), executor.executed[1].Start)
+}
+
+func TestCacheExecuteVersioned(t *testing.T) {
+	var (
+		backend = NewMemoryBackend(1<<20, tally.NoopScope)
+		now     = time.Unix(0, 0).Add(1000 * time.Hour)
+		params  = models.RequestParams{
+			Start: now.Add(-3 * time.Hour),
+			End:   now,
+			Now:   now,
+			Step:  time.Minute,
+		}
+	)
+
+	executor := &testExecutor{}
+	cache := NewCache(backend, Options{BufferPast: 10 * time.Minute}, tally.NoopScope)
+	_, _, err := cache.Execute(context.TODO(), "foo", params, executor.execute)
+	require.NoError(t, err)
+	require.Len(t, executor.executed, 2)
+
+	// Results cached with the same options are read
+	executor.executed = nil
+	cache = NewCache(backend, Options{BufferPast: 10 * time.Minute}, tally.NoopScope)
+	_, _, err = cache.Execute(context.TODO(), "foo", params, executor.execute)
+	require.NoError(t, err)
+	require.Len(t, executor.executed, 1)
+
+	// Results cached with other options are not
+	executor.executed = nil
+	cache = NewCache(backend, Options{BufferPast: 20 * time.Minute}, tally.NoopScope)
+	_, _, err = cache.Execute(context.TODO(), "foo", params, executor.execute)
+	require.NoError(t, err)
+	require.Len(t, executor.executed, 2)
+}
+
+func TestCacheExecuteWarnings(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	cache := NewCache(NewMemoryBackend(1<<20, scope), Options{}, scope)
+
+	now := time.Unix(0, 0).Add(1000 * time.Hour)
+	executor := &testExecutor{
+		warnings: storage.Warnings{storage.NewNonExhaustiveWarning(10)},
+	}
+	params := models.RequestParams{
+		Start: now.Add(-3 * time.Hour),
+		End:   now,
+		Now:   now,
+		Step:  time.Minute,
+	}
+
+	// Results which may be incomplete are not cached
+	for i := 0; i < 2; i++ {
+		executor.executed = nil
+		_, warnings, err := cache.Execute(context.TODO(), "foo", params, executor.execute)
+		require.NoError(t, err)
+		assert.Len(t, warnings, 2)
+		assert.Len(t, executor.executed, 2)
+	}
+
+	counters := scope.Snapshot().Counters()
+	assert.Equal(t, int64(0), counters["hits+"].Value())
+}
+
+func TestCacheExecuteUncacheable(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	cache := NewCache(NewMemoryBackend(1<<20, scope), Options{}, scope)
+
+	now := time.Unix(0, 0).Add(1000 * time.Hour)
+	executor := &testExecutor{}
+	tests := []struct {
+		name   string
+		params models.RequestParams
+	}{
+		{
+			name: "unaligned start",
+			params: models.RequestParams{
+				Start: now.Add(-3*time.Hour + time.Second),
+				End:   now,
+				Now:   now,
+				Step:  time.Minute,
+			},
+		},
+		{
+			name: "instant",
+			params: models.RequestParams{
+				Start: now.Add(-3 * time.Hour),
+				End:   now.Add(-3*time.Hour + time.Minute),
+				Now:   now,
+				Step:  time.Minute,
+			},
+		},
+		{
+			name: "recent",
+			params: models.RequestParams{
+				Start: now.Add(-5 * time.Minute),
+				End:   now,
+				Now:   now,
+				Step:  time.Minute,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			executor.executed = nil
+			_, _, err := cache.Execute(context.TODO(), "foo", test.params, executor.execute)
+			require.NoError(t, err)
+			require.Len(t, executor.executed, 1)
+			assert.Equal(t, test.params, executor.executed[0])
+		})
+	}
+
+	counters := scope.Snapshot().Counters()
+	assert.Equal(t, int64(2), counters["uncacheable+"].Value())
+}
+
+func TestCacheExecuteError(t *testing.T) {
+	cache := NewCache(NewMemoryBackend(1<<20, tally.NoopScope), Options{}, tally.NoopScope)
+
+	now := time.Unix(0, 0).Add(1000 * time.Hour)
+	_, _, err := cache.Execute(context.TODO(), "foo", models.RequestParams{
+		Start: now.Add(-3 * time.Hour),
+		End:   now,
+		Now:   now,
+		Step:  time.Minute,
+	}, func(context.Context, models.RequestParams) ([]storage.Block, storage.Warnings, error) {
+		return nil, nil, errors.New("unable to execute")
+	})
+	assert.EqualError(t, err, "unable to execute")
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"encoding/gob"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/uber-go/tally"
+)
+
+const (
+	entryFileSuffix = ".entry"
+)
+
+type diskBackend struct {
+	sync.Mutex
+	dir     string
+	lru     *lru
+	metrics backendMetrics
+}
+
+// NewDiskBackend returns a backend which holds entries in files of the given
+// directory, up to the given size in bytes. Entries left in the directory by
+// a previous process are kept.
+func NewDiskBackend(dir string, maxBytes int64, scope tally.Scope) (Backend, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &diskBackend{
+		dir:     dir,
+		lru:     newLRU(maxBytes),
+		metrics: newBackendMetrics(scope),
+	}
+
+	// Add the oldest files first so they are the first to be evicted
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, entryFileSuffix) {
+			continue
+		}
+
+		key := strings.TrimSuffix(name, entryFileSuffix)
+		b.evict(b.lru.add(&lruItem{key: key, size: file.Size()}))
+	}
+
+	b.metrics.bytes.Update(float64(b.lru.bytes))
+	return b, nil
+}
+
+func (b *diskBackend) path(key string) string {
+	return filepath.Join(b.dir, key+entryFileSuffix)
+}
+
+func (b *diskBackend) Get(key string) (*Entry, bool) {
+	b.Lock()
+	_, ok := b.lru.get(key)
+	b.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	entry, err := b.read(key)
+	if err != nil {
+		// The file may have been evicted since, or be corrupt in which case
+		// it is dropped so that the entry is cached again
+		b.metrics.errors.Inc(1)
+		b.Lock()
+		b.lru.remove(key)
+		b.Unlock()
+		os.Remove(b.path(key))
+		return nil, false
+	}
+
+	return entry, true
+}
+
+func (b *diskBackend) read(key string) (*Entry, error) {
+	file, err := os.Open(b.path(key))
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	var entry Entry
+	if err := gob.NewDecoder(file).Decode(&entry); err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (b *diskBackend) Set(key string, entry *Entry) {
+	size, err := b.write(key, entry)
+	if err != nil {
+		b.metrics.errors.Inc(1)
+		return
+	}
+
+	b.Lock()
+	evicted := b.lru.add(&lruItem{key: key, size: size})
+	b.metrics.bytes.Update(float64(b.lru.bytes))
+	b.Unlock()
+
+	b.evict(evicted)
+}
+
+// write writes the file of an entry and returns its size, the file is
+// renamed into place so that readers never see a partial file
+func (b *diskBackend) write(key string, entry *Entry) (int64, error) {
+	file, err := ioutil.TempFile(b.dir, key)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := gob.NewEncoder(file).Encode(entry); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return 0, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return 0, err
+	}
+
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return 0, err
+	}
+
+	if err := os.Rename(file.Name(), b.path(key)); err != nil {
+		os.Remove(file.Name())
+		return 0, err
+	}
+
+	return info.Size(), nil
+}
+
+// evict removes the files of evicted items
+func (b *diskBackend) evict(evicted []*lruItem) {
+	for _, item := range evicted {
+		if err := os.Remove(b.path(item.key)); err != nil && !os.IsNotExist(err) {
+			b.metrics.errors.Inc(1)
+		}
+	}
+
+	b.metrics.evictions.Inc(int64(len(evicted)))
+}
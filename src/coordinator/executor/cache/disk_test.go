@@ -0,0 +1,102 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func TestDiskBackend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	scope := tally.NewTestScope("", nil)
+	backend, err := NewDiskBackend(dir, 1<<20, scope)
+	require.NoError(t, err)
+
+	_, ok := backend.Get("foo")
+	assert.False(t, ok)
+
+	backend.Set("foo", newTestEntry("1", 1, math.NaN(), 3))
+	entry, ok := backend.Get("foo")
+	require.True(t, ok)
+	require.Len(t, entry.Series, 1)
+	assert.Equal(t, "foo", entry.Series[0].Name)
+	assert.Equal(t, newTestEntry("1").Series[0].Tags, entry.Series[0].Tags)
+	assert.Equal(t, float64(1), entry.Series[0].Values[0])
+	assert.True(t, math.IsNaN(entry.Series[0].Values[1]))
+	assert.Equal(t, float64(3), entry.Series[0].Values[2])
+
+	// Entries are kept across backends of the same directory
+	backend, err = NewDiskBackend(dir, 1<<20, scope)
+	require.NoError(t, err)
+	_, ok = backend.Get("foo")
+	assert.True(t, ok)
+
+	// Corrupt entries are dropped
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "foo"+entryFileSuffix), []byte("bad"), 0644))
+	_, ok = backend.Get("foo")
+	assert.False(t, ok)
+	_, err = os.Stat(filepath.Join(dir, "foo"+entryFileSuffix))
+	assert.True(t, os.IsNotExist(err))
+
+	counters := scope.Snapshot().Counters()
+	assert.Equal(t, int64(1), counters["errors+"].Value())
+}
+
+func TestDiskBackendEviction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	backend, err := NewDiskBackend(dir, 1<<20, tally.NoopScope)
+	require.NoError(t, err)
+	backend.Set("foo", newTestEntry("1", 1))
+
+	info, err := os.Stat(filepath.Join(dir, "foo"+entryFileSuffix))
+	require.NoError(t, err)
+
+	// Reopening the directory with room for a single entry keeps it
+	backend, err = NewDiskBackend(dir, info.Size(), tally.NoopScope)
+	require.NoError(t, err)
+	_, ok := backend.Get("foo")
+	assert.True(t, ok)
+
+	// Adding another entry evicts it and removes its file
+	backend.Set("bar", newTestEntry("1", 2))
+	_, ok = backend.Get("foo")
+	assert.False(t, ok)
+	_, err = os.Stat(filepath.Join(dir, "foo"+entryFileSuffix))
+	assert.True(t, os.IsNotExist(err))
+
+	entry, ok := backend.Get("bar")
+	require.True(t, ok)
+	assert.Equal(t, newTestEntry("1", 2), entry)
+}
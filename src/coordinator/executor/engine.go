@@ -22,8 +22,20 @@ package executor
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"github.com/m3db/m3db/src/coordinator/cost"
+	"github.com/m3db/m3db/src/coordinator/errors"
+	"github.com/m3db/m3db/src/coordinator/executor/cache"
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/parser"
+	"github.com/m3db/m3db/src/coordinator/parser/promql"
+	"github.com/m3db/m3db/src/coordinator/plan"
 	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/util/tracing"
+
+	"github.com/uber-go/tally"
 )
 
 // Engine executes a Query.
@@ -32,23 +44,90 @@ type Engine struct {
 	tracker *Tracker
 	Stats   *QueryStatistics
 	store   storage.Storage
+	// Used for enforcing the resource limits of running queries.
+	enforcer *cost.Enforcer
+	limits   Limits
+	metrics  engineMetrics
+	// Used for pushing down parts of queries to remote stores, nil when
+	// remote stores only return raw series.
+	pushdown storage.SubPlanExecutor
+	// Used for caching the results of range queries, nil when disabled.
+	cache *cache.Cache
+}
+
+// Limits are the resource limits enforced by the engine, a zero value for
+// any limit disables it.
+type Limits struct {
+	// MaxConcurrentQueries is the maximum number of queries run at once.
+	MaxConcurrentQueries int
+	// PerQuery are the limits applied to each query.
+	PerQuery cost.Limits
+	// Global are the limits applied across all running queries.
+	Global cost.Limits
+}
+
+type engineMetrics struct {
+	scope tally.Scope
+}
+
+// rejected returns the counter of queries rejected for exceeding a limit.
+func (m engineMetrics) rejected(err *errors.ResourceLimitError) tally.Counter {
+	return m.scope.Tagged(map[string]string{
+		"resource": err.Resource,
+		"limit":    err.Scope,
+	}).Counter("query.rejected")
 }
 
 // EngineOptions can be used to pass custom flags to engine
 type EngineOptions struct {
 	// AbortCh is a channel that signals when results are no longer desired by the caller.
 	AbortCh <-chan bool
+	// Trace records the execution of the query when set.
+	Trace *Trace
 }
 
 // NewEngine returns a new instance of QueryExecutor.
 func NewEngine(store storage.Storage) *Engine {
+	return NewEngineWithLimits(store, Limits{}, tally.NoopScope)
+}
+
+// NewEngineWithLimits returns a new instance of QueryExecutor which rejects
+// queries exceeding the given limits.
+func NewEngineWithLimits(store storage.Storage, limits Limits, scope tally.Scope) *Engine {
+	tracker := NewTracker()
+	tracker.MaxConcurrentQueries = limits.MaxConcurrentQueries
 	return &Engine{
-		tracker: NewTracker(),
-		Stats:   &QueryStatistics{},
-		store:   store,
+		tracker:  tracker,
+		Stats:    &QueryStatistics{},
+		store:    store,
+		enforcer: cost.NewEnforcer(limits.Global),
+		limits:   limits,
+		metrics:  engineMetrics{scope: scope},
 	}
 }
 
+// EnablePushdown has the engine push down the parts of queries which remote
+// stores can execute to them through the given executor, it must be called
+// before the engine executes any query.
+func (e *Engine) EnablePushdown(pushdown storage.SubPlanExecutor) {
+	e.pushdown = pushdown
+}
+
+// EnableCache has the engine cache the results of range queries in the given
+// cache, it must be called before the engine executes any query.
+func (e *Engine) EnableCache(c *cache.Cache) {
+	e.cache = c
+}
+
+// FetchOptions returns the options of a fetch made outside of the engine on
+// behalf of a query, such as a metadata query, holding it to the per query and
+// global limits of the engine. The returned function releases the resources
+// charged to the limits once the fetch is done.
+func (e *Engine) FetchOptions() (*storage.FetchOptions, func()) {
+	enforcer := e.enforcer.Child(e.limits.PerQuery)
+	return &storage.FetchOptions{Enforcer: enforcer}, enforcer.Release
+}
+
 // QueryStatistics keeps statistics related to the QueryExecutor.
 type QueryStatistics struct {
 	ActiveQueries          int64
@@ -62,6 +141,7 @@ func (e *Engine) Execute(ctx context.Context, query *storage.FetchQuery, opts *E
 	defer close(results)
 	task, err := e.tracker.Track(query, closing)
 	if err != nil {
+		e.recordError(err)
 		select {
 		case results <- &storage.QueryResult{Err: err}:
 		case <-opts.AbortCh:
@@ -71,10 +151,18 @@ func (e *Engine) Execute(ctx context.Context, query *storage.FetchQuery, opts *E
 
 	defer e.tracker.DetachQuery(task.qid)
 
+	ctx, cancel := withTaskCancellation(ctx, task)
+	defer cancel()
+
+	enforcer := e.enforcer.Child(e.limits.PerQuery)
+	defer enforcer.Release()
+
 	result, err := e.store.Fetch(ctx, query, &storage.FetchOptions{
 		KillChan: task.closing,
+		Enforcer: enforcer,
 	})
 	if err != nil {
+		e.recordError(err)
 		results <- &storage.QueryResult{Err: err}
 		return
 	}
@@ -82,6 +170,310 @@ func (e *Engine) Execute(ctx context.Context, query *storage.FetchQuery, opts *E
 	results <- &storage.QueryResult{FetchResult: result}
 }
 
+// ExecuteExpr runs the query DAG produced by the parser over the time range
+// described by params, and returns the resulting blocks along with any
+// warnings describing why they may be incomplete
+func (e *Engine) ExecuteExpr(ctx context.Context, p parser.Parser, opts *EngineOptions, params models.RequestParams) ([]storage.Block, storage.Warnings, error) {
+	span, ctx := startQuerySpan(ctx, opts.Trace)
+	span.SetTag("query", p.String())
+	span.SetTag("start", params.Start)
+	span.SetTag("end", params.End)
+	span.SetTag("step", params.Step.String())
+	defer span.Finish()
+
+	execute := func(ctx context.Context, params models.RequestParams) ([]storage.Block, storage.Warnings, error) {
+		warnings := storage.NewWarningsCollector()
+		blocks, err := e.executeExpr(ctx, p, opts, params, warnings)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return blocks, warnings.Warnings(), nil
+	}
+
+	var (
+		blocks   []storage.Block
+		warnings storage.Warnings
+		err      error
+	)
+
+	// Queries are cached by their parsed form so that formatting differences
+	// do not matter
+	if e.cache != nil {
+		blocks, warnings, err = e.cache.Execute(ctx, p.String(), params, execute)
+	} else {
+		blocks, warnings, err = execute(ctx, params)
+	}
+
+	if err != nil {
+		span.SetTag("error", err.Error())
+		e.recordError(err)
+		return nil, nil, err
+	}
+
+	return blocks, warnings, nil
+}
+
+func (e *Engine) executeExpr(
+	ctx context.Context,
+	p parser.Parser,
+	opts *EngineOptions,
+	params models.RequestParams,
+	warnings *storage.WarningsCollector,
+) ([]storage.Block, error) {
+	task, err := e.tracker.Track(&storage.FetchQuery{
+		Raw:      params.Query,
+		Start:    params.Start,
+		End:      params.End,
+		Interval: params.Step,
+	}, opts.AbortCh)
+	if err != nil {
+		return nil, err
+	}
+
+	defer e.tracker.DetachQuery(task.qid)
+
+	ctx, cancel := withTaskCancellation(ctx, task)
+	defer cancel()
+
+	nodes, edges, err := p.DAG()
+	if err != nil {
+		return nil, err
+	}
+
+	logicalPlan, err := plan.NewLogicalPlan(nodes, edges)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remote stores parse the query again to execute the parts pushed down
+	// to them, which gives the same node IDs for the same query
+	if params.Query == "" {
+		params.Query = p.String()
+	}
+
+	return e.executePlan(ctx, logicalPlan, params, e.pushdown, e.enforcer, warnings, opts.Trace)
+}
+
+// ExecuteSubPlan executes the part of a query pushed down by another
+// coordinator over the series of the store of the engine, returning the
+// blocks produced by the node of the query identified by the sub plan query.
+// The resources used are charged to the enforcer of the options when set,
+// otherwise to the global enforcer of the engine.
+func (e *Engine) ExecuteSubPlan(
+	ctx context.Context,
+	query *storage.SubPlanQuery,
+	options *storage.FetchOptions,
+) (storage.BlockResult, error) {
+	enforcer := e.enforcer
+	if options != nil && options.Enforcer != nil {
+		enforcer = options.Enforcer
+	}
+
+	blocks, warnings, err := e.executeSubPlan(ctx, query, enforcer)
+	if err != nil {
+		e.recordError(err)
+		return storage.BlockResult{}, err
+	}
+
+	return storage.BlockResult{Blocks: blocks, Warnings: warnings}, nil
+}
+
+func (e *Engine) executeSubPlan(
+	ctx context.Context,
+	query *storage.SubPlanQuery,
+	enforcer *cost.Enforcer,
+) ([]storage.Block, storage.Warnings, error) {
+	task, err := e.tracker.Track(query, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer e.tracker.DetachQuery(task.qid)
+
+	ctx, cancel := withTaskCancellation(ctx, task)
+	defer cancel()
+
+	p, err := promql.Parse(query.Query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nodes, edges, err := p.DAG()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logicalPlan, err := plan.NewLogicalPlan(nodes, edges)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	subPlan, err := plan.NewSubPlan(logicalPlan, parser.NodeID(query.NodeID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	params := models.RequestParams{
+		Start: query.Start,
+		End:   query.End,
+		Now:   query.Now,
+		Step:  query.Interval,
+		Query: query.Query,
+	}
+
+	// The sub plan is never pushed down again, so that coordinators only
+	// execute the sub plans over the series they hold
+	warnings := storage.NewWarningsCollector()
+	blocks, err := e.executePlan(ctx, subPlan, params, nil, enforcer, warnings, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return blocks, warnings.Warnings(), nil
+}
+
+func (e *Engine) executePlan(
+	ctx context.Context,
+	logicalPlan plan.LogicalPlan,
+	params models.RequestParams,
+	pushdown storage.SubPlanExecutor,
+	parent *cost.Enforcer,
+	warnings *storage.WarningsCollector,
+	trace *Trace,
+) ([]storage.Block, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx, "execute")
+	span.SetTag("start", params.Start)
+	span.SetTag("end", params.End)
+	defer span.Finish()
+
+	physicalPlan, err := plan.NewPhysicalPlan(logicalPlan, e.store, params)
+	if err != nil {
+		return nil, err
+	}
+
+	enforcer := parent.Child(e.limits.PerQuery)
+	defer enforcer.Release()
+
+	state, err := GenerateExecutionState(physicalPlan, e.store, pushdown, enforcer, warnings, trace)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := state.Execute(ctx); err != nil {
+		return nil, err
+	}
+
+	return state.Result().Blocks(), nil
+}
+
+// Explanation describes how the engine executes a query without executing it.
+type Explanation struct {
+	Query string           `json:"query"`
+	Start time.Time        `json:"start"`
+	End   time.Time        `json:"end"`
+	Step  tracing.Duration `json:"step"`
+	// Result is the ID of the node producing the result of the query.
+	Result string `json:"result"`
+	// Nodes are the nodes of the physical plan in the order they are
+	// performed.
+	Nodes []NodeExplanation `json:"nodes"`
+}
+
+// NodeExplanation describes a node of a query.
+type NodeExplanation struct {
+	ID       string   `json:"id"`
+	Op       string   `json:"op"`
+	Params   string   `json:"params"`
+	Parents  []string `json:"parents"`
+	Children []string `json:"children"`
+	// Pushdown is whether the remote stores execute the sub plan producing
+	// the node rather than returning the series it is computed from.
+	Pushdown bool `json:"pushdown"`
+}
+
+// Explain returns how the engine would execute the query DAG produced by the
+// parser over the time range described by params.
+func (e *Engine) Explain(p parser.Parser, params models.RequestParams) (Explanation, error) {
+	nodes, edges, err := p.DAG()
+	if err != nil {
+		return Explanation{}, err
+	}
+
+	logicalPlan, err := plan.NewLogicalPlan(nodes, edges)
+	if err != nil {
+		return Explanation{}, err
+	}
+
+	if params.Query == "" {
+		params.Query = p.String()
+	}
+
+	physicalPlan, err := plan.NewPhysicalPlan(logicalPlan, e.store, params)
+	if err != nil {
+		return Explanation{}, err
+	}
+
+	pipeline := physicalPlan.Pipeline()
+	explanation := Explanation{
+		Query:  physicalPlan.Query,
+		Start:  params.Start,
+		End:    params.End,
+		Step:   tracing.Duration(params.Step),
+		Result: string(physicalPlan.ResultStep.Parent),
+		Nodes:  make([]NodeExplanation, 0, len(pipeline)),
+	}
+
+	for _, ID := range pipeline {
+		step, ok := physicalPlan.Step(ID)
+		if !ok {
+			return Explanation{}, fmt.Errorf("transform not found, %s", ID)
+		}
+
+		explanation.Nodes = append(explanation.Nodes, NodeExplanation{
+			ID:       string(ID),
+			Op:       step.Transform.Op.OpType(),
+			Params:   step.Transform.Op.String(),
+			Parents:  nodeIDStrings(step.Parents),
+			Children: nodeIDStrings(step.Children),
+			Pushdown: e.pushdown != nil && physicalPlan.PushdownRoot(ID),
+		})
+	}
+
+	return explanation, nil
+}
+
+func nodeIDStrings(IDs []parser.NodeID) []string {
+	strs := make([]string, len(IDs))
+	for i, ID := range IDs {
+		strs[i] = string(ID)
+	}
+
+	return strs
+}
+
+// withTaskCancellation returns a context which is cancelled once the task is
+// killed, abandoning any requests to storage still in flight for the query.
+func withTaskCancellation(ctx context.Context, task *QueryTask) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-task.closing:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
+
+// recordError records the queries rejected for exceeding a resource limit.
+func (e *Engine) recordError(err error) {
+	if limitErr, ok := err.(*errors.ResourceLimitError); ok {
+		e.metrics.rejected(limitErr).Inc(1)
+	}
+}
+
 // Close kills all running queries and prevents new queries from being attached.
 func (e *Engine) Close() error {
 	return e.tracker.Close()
@@ -24,20 +24,33 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/m3db/m3db/src/coordinator/cost"
+	"github.com/m3db/m3db/src/coordinator/errors"
+	"github.com/m3db/m3db/src/coordinator/executor/cache"
+	"github.com/m3db/m3db/src/coordinator/functions"
+	"github.com/m3db/m3db/src/coordinator/functions/aggregation"
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/parser/promql"
 	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/storage/mock"
+	"github.com/m3db/m3db/src/coordinator/test"
 	"github.com/m3db/m3db/src/coordinator/test/local"
 	"github.com/m3db/m3db/src/coordinator/util/logging"
+	"github.com/m3db/m3db/src/coordinator/util/tracing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
 )
 
 func TestExecute(t *testing.T) {
 	logging.InitWithCores(nil)
 	ctrl := gomock.NewController(t)
 	store, session := local.NewStorageAndSession(ctrl)
-	session.EXPECT().FetchTagged(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, false, fmt.Errorf("dummy"))
+	session.EXPECT().FetchTagged(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, false, fmt.Errorf("dummy"))
 
 	// Results is closed by execute
 	results := make(chan *storage.QueryResult)
@@ -48,3 +61,174 @@ func TestExecute(t *testing.T) {
 	<-results
 	assert.Equal(t, len(engine.tracker.queries), 1)
 }
+
+func TestExecuteExpr(t *testing.T) {
+	logging.InitWithCores(nil)
+	parser, err := promql.Parse("foo")
+	require.NoError(t, err)
+
+	engine := NewEngine(mock.NewMockStorage())
+	now := time.Now()
+	blocks, warnings, err := engine.ExecuteExpr(context.TODO(), parser, &EngineOptions{}, models.RequestParams{
+		Start: now.Add(-time.Hour),
+		End:   now,
+		Now:   now,
+		Step:  time.Minute,
+		Query: "foo",
+	})
+	require.NoError(t, err)
+	assert.Len(t, blocks, 0)
+	assert.Len(t, warnings, 0)
+	assert.Len(t, engine.tracker.queries, 0)
+}
+
+func TestExecuteExprCache(t *testing.T) {
+	logging.InitWithCores(nil)
+	parser, err := promql.Parse("foo")
+	require.NoError(t, err)
+
+	scope := tally.NewTestScope("", nil)
+	engine := NewEngine(mock.NewMockStorage())
+	engine.EnableCache(cache.NewCache(cache.NewMemoryBackend(1<<20, scope), cache.Options{}, scope))
+
+	now := time.Now().Truncate(time.Minute)
+	params := models.RequestParams{
+		Start: now.Add(-3 * time.Hour),
+		End:   now,
+		Now:   now,
+		Step:  time.Minute,
+		Query: "foo",
+	}
+
+	for i := 0; i < 2; i++ {
+		blocks, warnings, err := engine.ExecuteExpr(context.TODO(), parser, &EngineOptions{}, params)
+		require.NoError(t, err)
+		require.Len(t, blocks, 1)
+		assert.Equal(t, 180, blocks[0].StepIter().StepCount())
+		assert.Len(t, warnings, 0)
+	}
+
+	assert.Len(t, engine.tracker.queries, 0)
+
+	counters := scope.Snapshot().Counters()
+	assert.True(t, counters["hits+"].Value() > 0)
+}
+
+func TestExecuteExprTrace(t *testing.T) {
+	logging.InitWithCores(nil)
+	parser, err := promql.Parse("sum(foo)")
+	require.NoError(t, err)
+
+	engine := NewEngine(mock.NewMockStorage())
+	now := time.Now()
+	opts := &EngineOptions{Trace: NewTrace()}
+	_, _, err = engine.ExecuteExpr(context.TODO(), parser, opts, models.RequestParams{
+		Start: now.Add(-time.Hour),
+		End:   now,
+		Now:   now,
+		Step:  time.Minute,
+	})
+	require.NoError(t, err)
+
+	result := opts.Trace.Result()
+	ops := make([]string, 0, len(result.Nodes))
+	for _, node := range result.Nodes {
+		ops = append(ops, node.Op)
+	}
+
+	assert.ElementsMatch(t, []string{functions.FetchType, aggregation.SumType}, ops)
+
+	require.Len(t, result.Spans, 1)
+	query := result.Spans[0]
+	assert.Equal(t, "query", query.Operation)
+	assert.Equal(t, "sum(foo)", query.Tags["query"])
+	require.Len(t, query.Children, 1)
+	execute := query.Children[0]
+	assert.Equal(t, "execute", execute.Operation)
+	require.Len(t, execute.Children, 1)
+	assert.Equal(t, "source", execute.Children[0].Operation)
+	assert.Equal(t, functions.FetchType, execute.Children[0].Tags["op"])
+}
+
+func TestExplain(t *testing.T) {
+	parser, err := promql.Parse("sum(foo)")
+	require.NoError(t, err)
+
+	engine := NewEngine(mock.NewMockStorage())
+	now := time.Now()
+	explanation, err := engine.Explain(parser, models.RequestParams{
+		Start: now.Add(-time.Hour),
+		End:   now,
+		Now:   now,
+		Step:  time.Minute,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "sum(foo)", explanation.Query)
+	assert.Equal(t, tracing.Duration(time.Minute), explanation.Step)
+	require.Len(t, explanation.Nodes, 2)
+
+	fetch, sum := explanation.Nodes[0], explanation.Nodes[1]
+	assert.Equal(t, functions.FetchType, fetch.Op)
+	assert.Equal(t, aggregation.SumType, sum.Op)
+	assert.Equal(t, []string{sum.ID}, fetch.Children)
+	assert.Equal(t, []string{fetch.ID}, sum.Parents)
+	assert.Equal(t, sum.ID, explanation.Result)
+	assert.False(t, fetch.Pushdown)
+	assert.False(t, sum.Pushdown)
+
+	// Explaining a query does not execute it
+	assert.Len(t, engine.tracker.queries, 0)
+}
+
+func TestExecuteExprLimitExceeded(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	store, session := local.NewStorageAndSession(ctrl)
+	session.EXPECT().FetchTagged(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(test.NewMockSeriesIters(ctrl, test.GenerateTag()), true, nil)
+
+	parser, err := promql.Parse("foo")
+	require.NoError(t, err)
+
+	scope := tally.NewTestScope("", nil)
+	engine := NewEngineWithLimits(store, Limits{
+		PerQuery: cost.Limits{MaxFetchedDatapoints: 1},
+	}, scope)
+	now := time.Now()
+	_, _, err = engine.ExecuteExpr(context.TODO(), parser, &EngineOptions{}, models.RequestParams{
+		Start: now.Add(-time.Hour),
+		End:   now,
+		Now:   now,
+		Step:  time.Minute,
+		Query: "foo",
+	})
+	require.Error(t, err)
+	assert.True(t, errors.IsResourceLimitError(err))
+	assert.Len(t, engine.tracker.queries, 0)
+
+	counters := scope.Snapshot().Counters()
+	counter, ok := counters["query.rejected+limit=query,resource=datapoints"]
+	require.True(t, ok)
+	assert.Equal(t, int64(1), counter.Value())
+}
+
+func TestWithTaskCancellation(t *testing.T) {
+	task := &QueryTask{closing: make(chan struct{})}
+	ctx, cancel := withTaskCancellation(context.Background(), task)
+	defer cancel()
+
+	select {
+	case <-ctx.Done():
+		t.Fatal("context done before task was killed")
+	default:
+	}
+
+	close(task.closing)
+	select {
+	case <-ctx.Done():
+		assert.Equal(t, context.Canceled, ctx.Err())
+	case <-time.After(time.Second):
+		t.Fatal("context not cancelled after task was killed")
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/m3db/m3db/src/coordinator/cost"
+	"github.com/m3db/m3db/src/coordinator/executor/transform"
+	"github.com/m3db/m3db/src/coordinator/functions/aggregation"
+	"github.com/m3db/m3db/src/coordinator/functions/utils"
+	"github.com/m3db/m3db/src/coordinator/parser"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/util/execution"
+)
+
+// pushdownSource executes a sub plan over the local stores and has the remote
+// stores execute it in parallel, then merges their partial results into the
+// block passed on to the consumers of the sub plan
+type pushdownSource struct {
+	op         parser.Params
+	sources    []parser.Source
+	local      *blockCollector
+	remote     *blockCollector
+	controller *transform.Controller
+}
+
+// Execute runs the local and remote sub plans and merges their results
+func (s *pushdownSource) Execute(ctx context.Context) error {
+	requests := make([]execution.Request, len(s.sources))
+	for idx, source := range s.sources {
+		requests[idx] = sourceRequest{source}
+	}
+
+	if err := execution.ExecuteParallel(ctx, requests); err != nil {
+		return err
+	}
+
+	// Local results come first so that the order of the series is the same
+	// across executions
+	blocks := append(s.local.blocks, s.remote.blocks...)
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	block, err := mergePartialBlocks(s.op, blocks)
+	if err != nil {
+		return err
+	}
+
+	return s.controller.Process(block)
+}
+
+func (s *pushdownSource) String() string {
+	return fmt.Sprintf("pushdown: %s, sources: %s", s.controller.ID, s.sources)
+}
+
+// mergePartialBlocks merges the results of a sub plan executed over disjoint
+// sets of series. Aggregations are aggregated again, while the series of any
+// other sub plan are concatenated. Series are not matched up across blocks,
+// which is why the fanout storage only pushes down sub plans when its stores
+// are configured as disjoint.
+func mergePartialBlocks(op parser.Params, blocks []storage.Block) (storage.Block, error) {
+	if len(blocks) == 1 {
+		return blocks[0], nil
+	}
+
+	if aggregation.Mergeable(op) {
+		return aggregation.MergeBlocks(op, blocks)
+	}
+
+	bounds := blocks[0].Meta().Bounds
+	var seriesMetas []storage.SeriesMeta
+	for _, b := range blocks {
+		seriesMetas = append(seriesMetas, utils.FlattenMetadata(b.Meta(), b.SeriesMeta())...)
+	}
+
+	builder := storage.NewColumnBlockBuilder(storage.BlockMetadata{Bounds: bounds}, seriesMetas)
+	for _, b := range blocks {
+		if b.Meta().Bounds.Steps() != bounds.Steps() {
+			return nil, fmt.Errorf("cannot merge blocks with bounds %s and %s", bounds, b.Meta().Bounds)
+		}
+
+		stepIter := b.StepIter()
+		for index := 0; stepIter.Next(); index++ {
+			if err := builder.AppendValues(index, stepIter.Current().Values()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return builder.Build(), nil
+}
+
+// blockCollector buffers the blocks produced by a sub plan
+type blockCollector struct {
+	mu     sync.Mutex
+	blocks []storage.Block
+}
+
+// Process the block
+func (c *blockCollector) Process(ID parser.NodeID, block storage.Block) error {
+	c.mu.Lock()
+	c.blocks = append(c.blocks, block)
+	c.mu.Unlock()
+	return nil
+}
+
+// subPlanSource is the source of the blocks produced by the remote stores
+// executing a sub plan
+type subPlanSource struct {
+	query      *storage.SubPlanQuery
+	controller *transform.Controller
+	executor   storage.SubPlanExecutor
+	enforcer   *cost.Enforcer
+	warnings   *storage.WarningsCollector
+}
+
+func newSubPlanSource(
+	query *storage.SubPlanQuery,
+	controller *transform.Controller,
+	executor storage.SubPlanExecutor,
+	options transform.Options,
+) parser.Source {
+	return &subPlanSource{
+		query:      query,
+		controller: controller,
+		executor:   executor,
+		enforcer:   options.Enforcer,
+		warnings:   options.Warnings,
+	}
+}
+
+// Execute has the remote stores execute the sub plan
+func (s *subPlanSource) Execute(ctx context.Context) error {
+	result, err := s.executor.ExecuteSubPlan(ctx, s.query, &storage.FetchOptions{
+		Enforcer: s.enforcer,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.warnings.Add(result.Warnings)
+	for _, block := range result.Blocks {
+		if err := s.controller.Process(block); err != nil {
+			// Fail on first error
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/cost"
+	"github.com/m3db/m3db/src/coordinator/errors"
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/parser/promql"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/storage/mock"
+	"github.com/m3db/m3db/src/coordinator/test"
+	"github.com/m3db/m3db/src/coordinator/util/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seriesStorage returns series with a constant value for every step
+type seriesStorage struct {
+	storage.Storage
+	values []float64
+
+	mu        sync.Mutex
+	localOnly []bool
+}
+
+func newSeriesStorage(values ...float64) *seriesStorage {
+	return &seriesStorage{
+		Storage: mock.NewMockStorage(),
+		values:  values,
+	}
+}
+
+func (s *seriesStorage) FetchBlocks(
+	ctx context.Context, query *storage.FetchQuery, options *storage.FetchOptions) (storage.BlockResult, error) {
+	s.mu.Lock()
+	s.localOnly = append(s.localOnly, options.LocalOnly)
+	s.mu.Unlock()
+	if err := options.Enforcer.AddSeries(len(s.values)); err != nil {
+		return storage.BlockResult{}, err
+	}
+
+	bounds := storage.Bounds{Start: query.Start, End: query.End, StepSize: query.Interval}
+	metas := make([]storage.SeriesMeta, 0, len(s.values))
+	values := make([][]float64, 0, len(s.values))
+	for _, v := range s.values {
+		metas = append(metas, storage.SeriesMeta{
+			Name: "foo",
+			Tags: models.Tags{models.MetricName: "foo", "v": fmt.Sprint(v)},
+		})
+		series := make([]float64, bounds.Steps())
+		for i := range series {
+			series[i] = v
+		}
+
+		values = append(values, series)
+	}
+
+	return storage.BlockResult{
+		Blocks: []storage.Block{test.NewBlockFromValuesWithSeriesMeta(bounds, metas, values)},
+	}, nil
+}
+
+func executePushdown(t *testing.T, query string, local, remote storage.Storage) []storage.Block {
+	parser, err := promql.Parse(query)
+	require.NoError(t, err)
+
+	engine := NewEngine(local)
+	engine.EnablePushdown(NewEngine(remote))
+	now := time.Now()
+	blocks, _, err := engine.ExecuteExpr(context.TODO(), parser, &EngineOptions{}, models.RequestParams{
+		Start: now.Add(-time.Hour),
+		End:   now,
+		Now:   now,
+		Step:  time.Minute,
+	})
+	require.NoError(t, err)
+	return blocks
+}
+
+// firstStep returns the values of the first step of the block
+func firstStep(t *testing.T, block storage.Block) []float64 {
+	stepIter := block.StepIter()
+	require.True(t, stepIter.Next())
+	return stepIter.Current().Values()
+}
+
+func TestPushdownMergesAggregations(t *testing.T) {
+	logging.InitWithCores(nil)
+	tests := []struct {
+		query    string
+		expected []float64
+	}{
+		{"sum(foo)", []float64{6}},
+		{"count(foo)", []float64{3}},
+		{"min(foo)", []float64{1}},
+		{"max(foo)", []float64{3}},
+		{"sum(foo) + count(foo)", []float64{9}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			local, remote := newSeriesStorage(1, 2), newSeriesStorage(3)
+			blocks := executePushdown(t, tt.query, local, remote)
+			require.Len(t, blocks, 1)
+			assert.Equal(t, tt.expected, firstStep(t, blocks[0]))
+			for _, localOnly := range local.localOnly {
+				assert.True(t, localOnly)
+			}
+		})
+	}
+}
+
+func TestPushdownMergesTake(t *testing.T) {
+	logging.InitWithCores(nil)
+	blocks := executePushdown(t, "topk(1, foo)", newSeriesStorage(1, 2), newSeriesStorage(3))
+	require.Len(t, blocks, 1)
+
+	var taken []float64
+	for _, v := range firstStep(t, blocks[0]) {
+		if !math.IsNaN(v) {
+			taken = append(taken, v)
+		}
+	}
+
+	assert.Equal(t, []float64{3}, taken)
+}
+
+func TestPushdownConcatenatesSeries(t *testing.T) {
+	logging.InitWithCores(nil)
+	blocks := executePushdown(t, "foo", newSeriesStorage(1, 2), newSeriesStorage(3))
+	require.Len(t, blocks, 1)
+	assert.Len(t, blocks[0].SeriesMeta(), 3)
+	assert.Equal(t, []float64{1, 2, 3}, firstStep(t, blocks[0]))
+}
+
+func TestExecuteSubPlanEnforcer(t *testing.T) {
+	logging.InitWithCores(nil)
+	engine := NewEngine(newSeriesStorage(1, 2))
+	now := time.Now()
+	query := &storage.SubPlanQuery{
+		Query:    "foo",
+		NodeID:   "0",
+		Start:    now.Add(-time.Hour),
+		End:      now,
+		Now:      now,
+		Interval: time.Minute,
+	}
+
+	_, err := engine.ExecuteSubPlan(context.TODO(), query, &storage.FetchOptions{
+		Enforcer: cost.NewEnforcer(cost.Limits{MaxFetchedSeries: 1}),
+	})
+	require.Error(t, err)
+	assert.True(t, errors.IsResourceLimitError(err))
+
+	result, err := engine.ExecuteSubPlan(context.TODO(), query, &storage.FetchOptions{})
+	require.NoError(t, err)
+	assert.Len(t, result.Blocks, 1)
+}
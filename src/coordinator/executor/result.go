@@ -21,19 +21,35 @@
 package executor
 
 import (
+	"sync"
+
 	"github.com/m3db/m3db/src/coordinator/parser"
 	"github.com/m3db/m3db/src/coordinator/storage"
 )
 
 // Result provides the execution results
 type Result interface {
+	// Blocks returns the blocks produced by the query
+	Blocks() []storage.Block
 }
 
 // ResultNode is used to provide the results to the caller from the query execution
 type ResultNode struct {
+	mu     sync.Mutex
+	blocks []storage.Block
 }
 
 // Process the block
-func (r ResultNode) Process(ID parser.NodeID, block storage.Block) error {
+func (r *ResultNode) Process(ID parser.NodeID, block storage.Block) error {
+	r.mu.Lock()
+	r.blocks = append(r.blocks, block)
+	r.mu.Unlock()
 	return nil
 }
+
+// Blocks returns the blocks which have been processed
+func (r *ResultNode) Blocks() []storage.Block {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.blocks
+}
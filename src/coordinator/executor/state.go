@@ -24,6 +24,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/m3db/m3db/src/coordinator/cost"
 	"github.com/m3db/m3db/src/coordinator/executor/transform"
 	"github.com/m3db/m3db/src/coordinator/parser"
 	"github.com/m3db/m3db/src/coordinator/plan"
@@ -39,6 +40,8 @@ type ExecutionState struct {
 	sources    []parser.Source
 	resultNode Result
 	storage    storage.Storage
+	pushdown   storage.SubPlanExecutor
+	trace      *Trace
 }
 
 // CreateSource creates a source node
@@ -65,12 +68,25 @@ type SourceParams interface {
 	Node(controller *transform.Controller, storage storage.Storage, options transform.Options) parser.Source
 }
 
-// GenerateExecutionState creates an execution state from the physical plan
-func GenerateExecutionState(pplan plan.PhysicalPlan, storage storage.Storage) (*ExecutionState, error) {
+// GenerateExecutionState creates an execution state from the physical plan,
+// charging the resources used by its sources to the enforcer which may be nil.
+// When pushdown is set the sub plans which the remote stores can execute are
+// sent to them through it, rather than fetching their series. When trace is
+// set the execution of each node is recorded in it.
+func GenerateExecutionState(
+	pplan plan.PhysicalPlan,
+	storage storage.Storage,
+	pushdown storage.SubPlanExecutor,
+	enforcer *cost.Enforcer,
+	warnings *storage.WarningsCollector,
+	trace *Trace,
+) (*ExecutionState, error) {
 	result := pplan.ResultStep
 	state := &ExecutionState{
-		plan:    pplan,
-		storage: storage,
+		plan:     pplan,
+		storage:  storage,
+		pushdown: pushdown,
+		trace:    trace,
 	}
 
 	step, ok := pplan.Step(result.Parent)
@@ -79,7 +95,9 @@ func GenerateExecutionState(pplan plan.PhysicalPlan, storage storage.Storage) (*
 	}
 
 	options := transform.Options{
-		Now: pplan.Now,
+		TimeSpec: pplan.TimeSpec,
+		Enforcer: enforcer,
+		Warnings: warnings,
 	}
 	controller, err := state.createNode(step, options)
 	if err != nil {
@@ -90,7 +108,7 @@ func GenerateExecutionState(pplan plan.PhysicalPlan, storage storage.Storage) (*
 		return nil, errors.New("empty sources for the execution state")
 	}
 
-	rNode := ResultNode{}
+	rNode := &ResultNode{}
 	state.resultNode = rNode
 	controller.AddTransform(rNode)
 
@@ -100,10 +118,19 @@ func GenerateExecutionState(pplan plan.PhysicalPlan, storage storage.Storage) (*
 // createNode helps to create an execution node recursively
 // TODO: consider modifying this function so that ExecutionState can have a non pointer receiver
 func (s *ExecutionState) createNode(step plan.LogicalStep, options transform.Options) (*transform.Controller, error) {
+	if s.pushdown != nil && !options.LocalOnly && s.plan.PushdownRoot(step.ID()) && s.plan.Query != "" {
+		return s.createPushdownNode(step, options)
+	}
+
 	// TODO: consider using a registry instead of casting to an interface
 	sourceParams, ok := step.Transform.Op.(SourceParams)
 	if ok {
 		source, controller := CreateSource(step.ID(), sourceParams, s.storage, options)
+		if s.trace != nil {
+			controller.Stats = s.trace.stats(step)
+			source = s.traceSource(step, source)
+		}
+
 		s.sources = append(s.sources, source)
 		return controller, nil
 	}
@@ -114,6 +141,11 @@ func (s *ExecutionState) createNode(step plan.LogicalStep, options transform.Opt
 	}
 
 	transformNode, controller := CreateTransform(step.ID(), transformParams)
+	if s.trace != nil {
+		controller.Stats = s.trace.stats(step)
+		transformNode = tracedNode{OpNode: transformNode, stats: controller.Stats}
+	}
+
 	for _, parentID := range step.Parents {
 		parentStep, ok := s.plan.Step(parentID)
 		if !ok {
@@ -131,6 +163,66 @@ func (s *ExecutionState) createNode(step plan.LogicalStep, options transform.Opt
 	return controller, nil
 }
 
+// createPushdownNode creates the sub plan producing the step from the local
+// stores only, along with a source executing it and having the remote stores
+// execute the same sub plan, which passes the merge of their results on to
+// the consumers of the step
+func (s *ExecutionState) createPushdownNode(step plan.LogicalStep, options transform.Options) (*transform.Controller, error) {
+	// The local sub plan is executed by the pushdown source rather than with
+	// the other sources of the state
+	local := &ExecutionState{
+		plan:    s.plan,
+		storage: s.storage,
+		trace:   s.trace,
+	}
+
+	localOptions := options
+	localOptions.LocalOnly = true
+	localController, err := local.createNode(step, localOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	timeSpec := options.TimeSpec
+	query := &storage.SubPlanQuery{
+		Query:    s.plan.Query,
+		NodeID:   string(step.ID()),
+		Start:    timeSpec.Start,
+		End:      timeSpec.End,
+		Now:      timeSpec.Now,
+		Interval: timeSpec.Step,
+	}
+
+	remoteController := &transform.Controller{ID: step.ID()}
+	remoteSource := newSubPlanSource(query, remoteController, s.pushdown, options)
+	if s.trace != nil {
+		remoteSource = s.traceSource(step, remoteSource)
+	}
+
+	source := &pushdownSource{
+		op:         step.Transform.Op,
+		sources:    append(local.sources, remoteSource),
+		local:      &blockCollector{},
+		remote:     &blockCollector{},
+		controller: &transform.Controller{ID: step.ID()},
+	}
+
+	localController.AddTransform(source.local)
+	remoteController.AddTransform(source.remote)
+	s.sources = append(s.sources, source)
+	return source.controller, nil
+}
+
+// traceSource records the execution of a source of the step in the trace
+func (s *ExecutionState) traceSource(step plan.LogicalStep, source parser.Source) parser.Source {
+	return tracedSource{
+		Source: source,
+		id:     step.ID(),
+		op:     step.Transform.Op.OpType(),
+		stats:  s.trace.stats(step),
+	}
+}
+
 // Execute the sources in parallel and return the first error
 func (s *ExecutionState) Execute(ctx context.Context) error {
 	requests := make([]execution.Request, len(s.sources))
@@ -141,6 +233,11 @@ func (s *ExecutionState) Execute(ctx context.Context) error {
 	return execution.ExecuteParallel(ctx, requests)
 }
 
+// Result returns the results of the execution
+func (s *ExecutionState) Result() Result {
+	return s.resultNode
+}
+
 // String representation of the state
 func (s *ExecutionState) String() string {
 	return fmt.Sprintf("plan: %s\nsources: %s\nresult: %s", s.plan, s.sources, s.resultNode)
@@ -26,6 +26,9 @@ import (
 	"time"
 
 	"github.com/m3db/m3db/src/coordinator/functions"
+	"github.com/m3db/m3db/src/coordinator/functions/aggregation"
+	"github.com/m3db/m3db/src/coordinator/functions/binary"
+	"github.com/m3db/m3db/src/coordinator/models"
 	"github.com/m3db/m3db/src/coordinator/parser"
 	"github.com/m3db/m3db/src/coordinator/plan"
 	"github.com/m3db/m3db/src/coordinator/storage/mock"
@@ -36,7 +39,7 @@ import (
 
 func TestValidState(t *testing.T) {
 	fetchTransform := parser.NewTransformFromOperation(functions.FetchOp{}, 1)
-	countTransform := parser.NewTransformFromOperation(functions.CountOp{}, 2)
+	countTransform := parser.NewTransformFromOperation(newCountOp(t), 2)
 	transforms := parser.Nodes{fetchTransform, countTransform}
 	edges := parser.Edges{
 		parser.Edge{
@@ -48,9 +51,9 @@ func TestValidState(t *testing.T) {
 	lp, err := plan.NewLogicalPlan(transforms, edges)
 	require.NoError(t, err)
 	store := mock.NewMockStorage()
-	p, err := plan.NewPhysicalPlan(lp, store, time.Now())
+	p, err := plan.NewPhysicalPlan(lp, store, models.RequestParams{Now: time.Now()})
 	require.NoError(t, err)
-	state, err := GenerateExecutionState(p, store)
+	state, err := GenerateExecutionState(p, store, nil, nil, nil, nil)
 	require.NoError(t, err)
 	require.Len(t, state.sources, 1)
 	err = state.Execute(context.Background())
@@ -58,14 +61,14 @@ func TestValidState(t *testing.T) {
 }
 
 func TestWithoutSources(t *testing.T) {
-	countTransform := parser.NewTransformFromOperation(functions.CountOp{}, 2)
+	countTransform := parser.NewTransformFromOperation(newCountOp(t), 2)
 	transforms := parser.Nodes{countTransform}
 	edges := parser.Edges{}
 	lp, err := plan.NewLogicalPlan(transforms, edges)
 	require.NoError(t, err)
-	p, err := plan.NewPhysicalPlan(lp, nil, time.Now())
+	p, err := plan.NewPhysicalPlan(lp, nil, models.RequestParams{Now: time.Now()})
 	require.NoError(t, err)
-	_, err = GenerateExecutionState(p, nil)
+	_, err = GenerateExecutionState(p, nil, nil, nil, nil, nil)
 	assert.Error(t, err)
 }
 
@@ -75,35 +78,46 @@ func TestOnlySources(t *testing.T) {
 	edges := parser.Edges{}
 	lp, err := plan.NewLogicalPlan(transforms, edges)
 	require.NoError(t, err)
-	p, err := plan.NewPhysicalPlan(lp, nil, time.Now())
+	p, err := plan.NewPhysicalPlan(lp, nil, models.RequestParams{Now: time.Now()})
 	require.NoError(t, err)
-	state, err := GenerateExecutionState(p, nil)
+	state, err := GenerateExecutionState(p, nil, nil, nil, nil, nil)
 	assert.NoError(t, err)
 	require.Len(t, state.sources, 1)
 }
 
 func TestMultipleSources(t *testing.T) {
 	fetchTransform1 := parser.NewTransformFromOperation(functions.FetchOp{}, 1)
-	countTransform := parser.NewTransformFromOperation(functions.CountOp{}, 2)
 	fetchTransform2 := parser.NewTransformFromOperation(functions.FetchOp{}, 3)
-	transforms := parser.Nodes{fetchTransform1, fetchTransform2, countTransform}
+	binaryOp, err := binary.NewOp(binary.PlusType, binary.NodeParams{
+		LNode: fetchTransform1.ID,
+		RNode: fetchTransform2.ID,
+	})
+	require.NoError(t, err)
+	binaryTransform := parser.NewTransformFromOperation(binaryOp, 2)
+	transforms := parser.Nodes{fetchTransform1, fetchTransform2, binaryTransform}
 	edges := parser.Edges{
 		parser.Edge{
 			ParentID: fetchTransform1.ID,
-			ChildID:  countTransform.ID,
+			ChildID:  binaryTransform.ID,
 		},
 		parser.Edge{
 			ParentID: fetchTransform2.ID,
-			ChildID:  countTransform.ID,
+			ChildID:  binaryTransform.ID,
 		},
 	}
 
 	lp, err := plan.NewLogicalPlan(transforms, edges)
 	require.NoError(t, err)
-	p, err := plan.NewPhysicalPlan(lp, nil, time.Now())
+	p, err := plan.NewPhysicalPlan(lp, nil, models.RequestParams{Now: time.Now()})
 	require.NoError(t, err)
-	state, err := GenerateExecutionState(p, nil)
+	state, err := GenerateExecutionState(p, nil, nil, nil, nil, nil)
 	assert.NoError(t, err)
 	require.Len(t, state.sources, 2)
 	assert.Contains(t, state.String(), "sources")
 }
+
+func newCountOp(t *testing.T) parser.Params {
+	op, err := aggregation.NewAggregationOp(aggregation.CountType, aggregation.NodeParams{})
+	require.NoError(t, err)
+	return op
+}
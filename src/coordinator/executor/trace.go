@@ -0,0 +1,162 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package executor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/executor/transform"
+	"github.com/m3db/m3db/src/coordinator/parser"
+	"github.com/m3db/m3db/src/coordinator/plan"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/util/tracing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// Trace records how a query was executed: the stats of each node of the
+// query and the spans of its execution, down to the requests to each host.
+// Spans are also passed on to the global OpenTracing tracer.
+type Trace struct {
+	sync.Mutex
+	recorder *tracing.Recorder
+	nodes    map[parser.NodeID]*traceNode
+	order    []parser.NodeID
+}
+
+type traceNode struct {
+	op    string
+	stats *transform.NodeStats
+}
+
+// TraceResult is the recorded trace of a query.
+type TraceResult struct {
+	Nodes []NodeTrace           `json:"nodes"`
+	Spans []*tracing.SpanRecord `json:"spans"`
+}
+
+// NodeTrace is the trace of a node of a query, accumulated over each time
+// the node was executed.
+type NodeTrace struct {
+	ID string `json:"id"`
+	Op string `json:"op"`
+	// Duration is the time spent in the node, excluding the time spent in
+	// the nodes it output blocks to.
+	Duration tracing.Duration `json:"duration"`
+	Blocks   int              `json:"blocks"`
+	Series   int              `json:"series"`
+	Values   int              `json:"values"`
+}
+
+// NewTrace returns a new trace.
+func NewTrace() *Trace {
+	return &Trace{
+		recorder: tracing.NewRecorder(opentracing.GlobalTracer()),
+		nodes:    make(map[parser.NodeID]*traceNode),
+	}
+}
+
+// Result returns the trace recorded so far.
+func (t *Trace) Result() TraceResult {
+	t.Lock()
+	defer t.Unlock()
+
+	nodes := make([]NodeTrace, 0, len(t.order))
+	for _, id := range t.order {
+		node := t.nodes[id]
+		summary := node.stats.Summary()
+		nodes = append(nodes, NodeTrace{
+			ID:       string(id),
+			Op:       node.op,
+			Duration: tracing.Duration(summary.Duration),
+			Blocks:   summary.Blocks,
+			Series:   summary.Series,
+			Values:   summary.Values,
+		})
+	}
+
+	return TraceResult{Nodes: nodes, Spans: t.recorder.Spans()}
+}
+
+// stats returns the stats of the node of a step, the same for each
+// execution of the step
+func (t *Trace) stats(step plan.LogicalStep) *transform.NodeStats {
+	t.Lock()
+	defer t.Unlock()
+
+	id := step.ID()
+	node, ok := t.nodes[id]
+	if !ok {
+		node = &traceNode{op: step.Transform.Op.OpType(), stats: &transform.NodeStats{}}
+		t.nodes[id] = node
+		t.order = append(t.order, id)
+	}
+
+	return node.stats
+}
+
+// startQuerySpan starts the span of a query, recorded if the query is traced
+func startQuerySpan(ctx context.Context, trace *Trace) (opentracing.Span, context.Context) {
+	if trace == nil {
+		return tracing.StartSpanFromContext(ctx, "query")
+	}
+
+	return tracing.StartSpanWithTracer(ctx, trace.recorder, "query")
+}
+
+// tracedSource records the time spent executing a source, and the span of
+// its execution
+type tracedSource struct {
+	parser.Source
+	id    parser.NodeID
+	op    string
+	stats *transform.NodeStats
+}
+
+func (s tracedSource) Execute(ctx context.Context) error {
+	span, ctx := tracing.StartSpanFromContext(ctx, "source",
+		opentracing.Tags{"node": string(s.id), "op": s.op})
+	defer span.Finish()
+
+	start := time.Now()
+	err := s.Source.Execute(ctx)
+	s.stats.RecordDuration(time.Since(start))
+	if err != nil {
+		span.SetTag("error", err.Error())
+	}
+
+	return err
+}
+
+// tracedNode records the time spent processing blocks in a transform
+type tracedNode struct {
+	transform.OpNode
+	stats *transform.NodeStats
+}
+
+func (n tracedNode) Process(ID parser.NodeID, block storage.Block) error {
+	start := time.Now()
+	err := n.OpNode.Process(ID, block)
+	n.stats.RecordDuration(time.Since(start))
+	return err
+}
@@ -112,7 +112,7 @@ func (t *Tracker) Track(query storage.Query, connClosed <-chan bool) (*QueryTask
 	}
 
 	if t.MaxConcurrentQueries > 0 && len(t.queries) >= t.MaxConcurrentQueries {
-		return nil, errors.ErrMaxConcurrentQueriesLimitExceeded(len(t.queries), t.MaxConcurrentQueries)
+		return nil, errors.ErrMaxConcurrentQueriesLimitExceeded(t.MaxConcurrentQueries)
 	}
 
 	queryTask := &QueryTask{
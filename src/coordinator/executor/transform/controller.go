@@ -21,7 +21,9 @@
 package transform
 
 import (
-	"github.com/m3db/m3db/src/coordinator/errors"
+	"sync"
+	"time"
+
 	"github.com/m3db/m3db/src/coordinator/parser"
 	"github.com/m3db/m3db/src/coordinator/storage"
 )
@@ -30,6 +32,9 @@ import (
 type Controller struct {
 	ID         parser.NodeID
 	transforms []OpNode
+	// Stats records the blocks output by the node when the query is traced,
+	// nil otherwise.
+	Stats *NodeStats
 }
 
 // AddTransform adds a dependent transformation to the controller
@@ -39,6 +44,17 @@ func (t *Controller) AddTransform(node OpNode) {
 
 // Process performs processing on the underlying transforms
 func (t *Controller) Process(block storage.Block) error {
+	if t.Stats == nil {
+		return t.process(block)
+	}
+
+	start := time.Now()
+	err := t.process(block)
+	t.Stats.recordOutput(block, time.Since(start))
+	return err
+}
+
+func (t *Controller) process(block storage.Block) error {
 	for _, ts := range t.transforms {
 		err := ts.Process(t.ID, block)
 		if err != nil {
@@ -49,14 +65,70 @@ func (t *Controller) Process(block storage.Block) error {
 	return nil
 }
 
-// BlockBuilder returns a BlockBuilder instance with associated metadata
-// nolint: unparam
-func (t *Controller) BlockBuilder(blockMeta storage.BlockMetadata) (BlockBuilder, error) {
-	return nil, errors.ErrNotImplemented
+// NodeStats records the blocks output by a node of a query and the time
+// spent producing them.
+type NodeStats struct {
+	sync.Mutex
+	total      time.Duration
+	downstream time.Duration
+	blocks     int
+	series     int
+	values     int
+}
+
+// NodeSummary summarizes the stats of a node.
+type NodeSummary struct {
+	// Duration is the time spent in the node, excluding the time spent in
+	// the nodes it output blocks to.
+	Duration time.Duration
+	// Blocks is the number of blocks output.
+	Blocks int
+	// Series is the number of series across the blocks output.
+	Series int
+	// Values is the number of values, one per series and step, across the
+	// blocks output.
+	Values int
+}
+
+// RecordDuration records time spent in the node, including the time spent
+// in the nodes it output blocks to meanwhile.
+func (s *NodeStats) RecordDuration(d time.Duration) {
+	s.Lock()
+	s.total += d
+	s.Unlock()
 }
 
-// BlockBuilder builds a new block
-type BlockBuilder interface {
-	AppendValue(index int, value float64)
-	Build() storage.Block
+func (s *NodeStats) recordOutput(block storage.Block, downstream time.Duration) {
+	series := len(block.SeriesMeta())
+	steps := block.Meta().Bounds.Steps()
+
+	s.Lock()
+	s.downstream += downstream
+	s.blocks++
+	s.series += series
+	s.values += series * steps
+	s.Unlock()
+}
+
+// Summary returns the summary of the stats.
+func (s *NodeStats) Summary() NodeSummary {
+	s.Lock()
+	defer s.Unlock()
+
+	duration := s.total - s.downstream
+	if duration < 0 {
+		duration = 0
+	}
+
+	return NodeSummary{
+		Duration: duration,
+		Blocks:   s.blocks,
+		Series:   s.series,
+		Values:   s.values,
+	}
+}
+
+// BlockBuilder returns a BlockBuilder instance with associated metadata
+func (t *Controller) BlockBuilder(blockMeta storage.BlockMetadata, seriesMeta []storage.SeriesMeta) (storage.Builder, error) {
+	return storage.NewColumnBlockBuilder(blockMeta, seriesMeta), nil
 }
@@ -0,0 +1,90 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package transform
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/parser"
+	"github.com/m3db/m3db/src/coordinator/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sleepNode struct {
+	sleep time.Duration
+	err   error
+}
+
+func (n sleepNode) Process(ID parser.NodeID, block storage.Block) error {
+	time.Sleep(n.sleep)
+	return n.err
+}
+
+func newTestBlock(series, steps int) storage.Block {
+	now := time.Now()
+	seriesMeta := make([]storage.SeriesMeta, series)
+	for i := range seriesMeta {
+		seriesMeta[i].Tags = models.Tags{}
+	}
+
+	return storage.NewColumnBlockBuilder(storage.BlockMetadata{
+		Bounds: storage.Bounds{
+			Start:    now,
+			End:      now.Add(time.Duration(steps) * time.Minute),
+			StepSize: time.Minute,
+		},
+	}, seriesMeta).Build()
+}
+
+func TestControllerStats(t *testing.T) {
+	controller := &Controller{ID: "1", Stats: &NodeStats{}}
+	controller.AddTransform(sleepNode{sleep: 10 * time.Millisecond})
+
+	require.NoError(t, controller.Process(newTestBlock(2, 5)))
+	require.NoError(t, controller.Process(newTestBlock(1, 5)))
+
+	// Time spent in the node itself is recorded by its caller, the time
+	// spent in the nodes it outputs blocks to is excluded
+	controller.Stats.RecordDuration(time.Second)
+
+	summary := controller.Stats.Summary()
+	assert.True(t, summary.Duration <= time.Second-20*time.Millisecond)
+	assert.True(t, summary.Duration > 0)
+	assert.Equal(t, 2, summary.Blocks)
+	assert.Equal(t, 3, summary.Series)
+	assert.Equal(t, 15, summary.Values)
+
+	// Blocks failing downstream are still recorded
+	controller.AddTransform(sleepNode{err: errors.New("failed")})
+	assert.Error(t, controller.Process(newTestBlock(1, 1)))
+	assert.Equal(t, 3, controller.Stats.Summary().Blocks)
+}
+
+func TestControllerWithoutStats(t *testing.T) {
+	controller := &Controller{ID: "1"}
+	controller.AddTransform(sleepNode{})
+	assert.NoError(t, controller.Process(newTestBlock(1, 1)))
+}
@@ -3,16 +3,54 @@ package transform
 import (
 	"time"
 
+	"github.com/m3db/m3db/src/coordinator/cost"
 	"github.com/m3db/m3db/src/coordinator/parser"
 	"github.com/m3db/m3db/src/coordinator/storage"
 )
 
 // Options to create transform nodes
 type Options struct {
-	Now time.Time
+	TimeSpec TimeSpec
+	// Enforcer accounts the resources used by the query, it may be nil
+	Enforcer *cost.Enforcer
+	// Warnings collects the warnings of the fetches made by the query, it
+	// may be nil
+	Warnings *storage.WarningsCollector
+	// LocalOnly restricts the fetches to the local stores, for sub plans
+	// which the remote stores execute themselves
+	LocalOnly bool
 }
 
 // OpNode represents the execution node
 type OpNode interface {
 	Process(ID parser.NodeID, block storage.Block) error
 }
+
+// TimeSpec defines the time bounds for the query execution. End is exclusive
+type TimeSpec struct {
+	Start time.Time
+	End   time.Time
+	// Now captures the current time and fixes it throughout the request
+	Now  time.Time
+	Step time.Duration
+}
+
+// Bounds returns the block bounds for the time spec
+func (ts TimeSpec) Bounds() storage.Bounds {
+	return storage.Bounds{
+		Start:    ts.Start,
+		End:      ts.End,
+		StepSize: ts.Step,
+	}
+}
+
+// HistorySteps returns the number of steps before the first step of a query
+// which are needed to compute a range vector function over the given duration
+func HistorySteps(duration, step time.Duration) int {
+	if duration <= 0 || step <= 0 {
+		return 0
+	}
+
+	// The window (t - duration, t] contains ceil(duration / step) steps
+	return int((duration+step-1)/step) - 1
+}
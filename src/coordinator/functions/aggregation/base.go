@@ -0,0 +1,140 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aggregation
+
+import (
+	"fmt"
+
+	"github.com/m3db/m3db/src/coordinator/executor/transform"
+	"github.com/m3db/m3db/src/coordinator/functions/utils"
+	"github.com/m3db/m3db/src/coordinator/parser"
+	"github.com/m3db/m3db/src/coordinator/storage"
+)
+
+const (
+	// SumType adds all non nan elements in a list of series
+	SumType = "sum"
+	// MinType takes the minimum of all non nan elements in a list of series
+	MinType = "min"
+	// MaxType takes the maximum of all non nan elements in a list of series
+	MaxType = "max"
+	// AverageType averages all non nan elements in a list of series
+	AverageType = "avg"
+	// StandardDeviationType takes the population standard deviation of all non
+	// nan elements in a list of series
+	StandardDeviationType = "stddev"
+	// StandardVarianceType takes the population standard variance of all non
+	// nan elements in a list of series
+	StandardVarianceType = "stdvar"
+	// CountType counts all non nan elements in a list of series
+	CountType = "count"
+)
+
+// NodeParams contains additional parameters required for aggregation ops
+type NodeParams struct {
+	// MatchingTags is the set of tags by which the aggregation groups output series
+	MatchingTags []string
+	// Without indicates if series should use only the MatchingTags or if MatchingTags
+	// should be excluded from grouping
+	Without bool
+	// Parameter is the param value for the aggregation op when appropriate
+	Parameter float64
+	// StringParameter is the string representation for the aggregation op when appropriate
+	StringParameter string
+}
+
+// NewAggregationOp creates a new aggregation operation
+func NewAggregationOp(opType string, params NodeParams) (parser.Params, error) {
+	if opType == QuantileType {
+		return newBaseOp(params, opType, makeQuantileFn(params.Parameter)), nil
+	}
+
+	if fn, ok := aggregationFunctions[opType]; ok {
+		return newBaseOp(params, opType, fn), nil
+	}
+
+	return baseOp{}, fmt.Errorf("operator not supported: %s", opType)
+}
+
+// baseOp stores required properties for the aggregation
+type baseOp struct {
+	params NodeParams
+	opType string
+	aggFn  aggregationFn
+}
+
+func newBaseOp(params NodeParams, opType string, aggFn aggregationFn) baseOp {
+	return baseOp{
+		params: params,
+		opType: opType,
+		aggFn:  aggFn,
+	}
+}
+
+// OpType for the operator
+func (o baseOp) OpType() string {
+	return o.opType
+}
+
+// String representation
+func (o baseOp) String() string {
+	return fmt.Sprintf("type: %s, params: %+v", o.OpType(), o.params)
+}
+
+// Node creates an execution node
+func (o baseOp) Node(controller *transform.Controller) transform.OpNode {
+	return &baseNode{
+		op:         o,
+		controller: controller,
+	}
+}
+
+// baseNode is an execution node
+type baseNode struct {
+	op         baseOp
+	controller *transform.Controller
+}
+
+// Process the block
+func (n *baseNode) Process(ID parser.NodeID, b storage.Block) error {
+	params := n.op.params
+	meta := b.Meta()
+	seriesMetas := utils.FlattenMetadata(meta, b.SeriesMeta())
+	buckets, metas := collectSeries(params.MatchingTags, params.Without, n.op.opType, seriesMetas)
+
+	builder, err := n.controller.BlockBuilder(storage.BlockMetadata{Bounds: meta.Bounds}, metas)
+	if err != nil {
+		return err
+	}
+
+	stepIter := b.StepIter()
+	for index := 0; stepIter.Next(); index++ {
+		step := stepIter.Current()
+		values := step.Values()
+		for _, bucket := range buckets {
+			if err := builder.AppendValue(index, n.op.aggFn(values, bucket)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return n.controller.Process(builder.Build())
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aggregation
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/parser"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/test"
+	"github.com/m3db/m3db/src/coordinator/test/executor"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	seriesMetas = []storage.SeriesMeta{
+		{Tags: models.Tags{models.MetricName: "a", "a": "1", "b": "1"}},
+		{Tags: models.Tags{models.MetricName: "a", "a": "1", "b": "2"}},
+		{Tags: models.Tags{models.MetricName: "a", "a": "2", "b": "1"}},
+		{Tags: models.Tags{models.MetricName: "a", "a": "2", "b": "2"}},
+	}
+
+	values = [][]float64{
+		{1, 2, math.NaN()},
+		{3, 4, math.NaN()},
+		{5, math.NaN(), math.NaN()},
+		{7, 8, math.NaN()},
+	}
+
+	bounds = test.NewBounds(time.Now().Truncate(time.Minute), time.Minute, 3)
+)
+
+func processAggregationOp(t *testing.T, op parser.Params) *executor.SinkNode {
+	block := test.NewBlockFromValuesWithSeriesMeta(bounds, seriesMetas, values)
+	c, sink := executor.NewControllerWithSink(parser.NodeID("1"))
+	node := op.(baseOp).Node(c)
+	err := node.Process(parser.NodeID("0"), block)
+	require.NoError(t, err)
+	return sink
+}
+
+func TestAggregationByTags(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name     string
+		opType   string
+		expected [][]float64
+	}{
+		{"sum", SumType, [][]float64{{4, 6, nan}, {12, 8, nan}}},
+		{"avg", AverageType, [][]float64{{2, 3, nan}, {6, 8, nan}}},
+		{"count", CountType, [][]float64{{2, 2, nan}, {2, 1, nan}}},
+		{"min", MinType, [][]float64{{1, 2, nan}, {5, 8, nan}}},
+		{"max", MaxType, [][]float64{{3, 4, nan}, {7, 8, nan}}},
+		{"stdvar", StandardVarianceType, [][]float64{{1, 1, nan}, {1, 0, nan}}},
+		{"stddev", StandardDeviationType, [][]float64{{1, 1, nan}, {1, 0, nan}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op, err := NewAggregationOp(tt.opType, NodeParams{MatchingTags: []string{"a"}})
+			require.NoError(t, err)
+			sink := processAggregationOp(t, op)
+			test.EqualsWithNans(t, tt.expected, sink.Values)
+			require.Len(t, sink.Metas, 2)
+			assert.Equal(t, models.Tags{"a": "1"}, sink.Metas[0].Tags)
+			assert.Equal(t, models.Tags{"a": "2"}, sink.Metas[1].Tags)
+			assert.Equal(t, bounds, sink.Meta.Bounds)
+		})
+	}
+}
+
+func TestAggregationWithoutTags(t *testing.T) {
+	op, err := NewAggregationOp(SumType, NodeParams{MatchingTags: []string{"b"}, Without: true})
+	require.NoError(t, err)
+	sink := processAggregationOp(t, op)
+	test.EqualsWithNans(t, [][]float64{{4, 6, math.NaN()}, {12, 8, math.NaN()}}, sink.Values)
+	require.Len(t, sink.Metas, 2)
+	assert.Equal(t, models.Tags{"a": "1"}, sink.Metas[0].Tags)
+	assert.Equal(t, models.Tags{"a": "2"}, sink.Metas[1].Tags)
+}
+
+func TestAggregationNoGrouping(t *testing.T) {
+	op, err := NewAggregationOp(SumType, NodeParams{})
+	require.NoError(t, err)
+	sink := processAggregationOp(t, op)
+	test.EqualsWithNans(t, [][]float64{{16, 14, math.NaN()}}, sink.Values)
+	require.Len(t, sink.Metas, 1)
+	assert.Equal(t, models.Tags{}, sink.Metas[0].Tags)
+}
+
+func TestQuantile(t *testing.T) {
+	op, err := NewAggregationOp(QuantileType, NodeParams{MatchingTags: []string{"a"}, Parameter: 0.5})
+	require.NoError(t, err)
+	sink := processAggregationOp(t, op)
+	test.EqualsWithNans(t, [][]float64{{2, 3, math.NaN()}, {6, 8, math.NaN()}}, sink.Values)
+
+	assert.True(t, math.IsInf(bucketedQuantileFn(-1, []float64{1}, []int{0}), -1))
+	assert.True(t, math.IsInf(bucketedQuantileFn(2, []float64{1}, []int{0}), 1))
+	assert.Equal(t, 1.75, bucketedQuantileFn(0.25, []float64{1, 2, 3, 4}, []int{0, 1, 2, 3}))
+}
+
+func TestUnknownAggregation(t *testing.T) {
+	_, err := NewAggregationOp("bogus", NodeParams{})
+	assert.Error(t, err)
+}
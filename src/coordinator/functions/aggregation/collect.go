@@ -0,0 +1,67 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aggregation
+
+import (
+	"github.com/m3db/m3db/src/coordinator/functions/utils"
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/storage"
+)
+
+// collectSeries groups series by the given matching tags. It returns the
+// indices of the series belonging to each group, along with the metadata
+// describing each group. Groups are ordered by first appearance.
+func collectSeries(
+	matchingTags []string,
+	without bool,
+	opName string,
+	metas []storage.SeriesMeta,
+) ([][]int, []storage.SeriesMeta) {
+	var (
+		buckets     [][]int
+		bucketMetas []storage.SeriesMeta
+		lookup      = make(map[string]int)
+	)
+
+	for i, meta := range metas {
+		var tags models.Tags
+		if without {
+			tags = utils.TagsWithoutKeys(meta.Tags, matchingTags)
+		} else {
+			tags = utils.TagsWithKeys(meta.Tags, matchingTags)
+		}
+
+		id := tags.ID()
+		if idx, ok := lookup[id]; ok {
+			buckets[idx] = append(buckets[idx], i)
+			continue
+		}
+
+		lookup[id] = len(buckets)
+		buckets = append(buckets, []int{i})
+		bucketMetas = append(bucketMetas, storage.SeriesMeta{
+			Name: opName,
+			Tags: tags,
+		})
+	}
+
+	return buckets, bucketMetas
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aggregation
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/m3db/m3db/src/coordinator/executor/transform"
+	"github.com/m3db/m3db/src/coordinator/functions/utils"
+	"github.com/m3db/m3db/src/coordinator/parser"
+	"github.com/m3db/m3db/src/coordinator/storage"
+)
+
+const (
+	// CountValuesType counts the number of non nan elements with the same value
+	CountValuesType = "count_values"
+)
+
+// NewCountValuesOp creates a new count values operation
+func NewCountValuesOp(opType string, params NodeParams) (parser.Params, error) {
+	if opType != CountValuesType {
+		return countValuesOp{}, fmt.Errorf("operator not supported: %s", opType)
+	}
+
+	if params.StringParameter == "" {
+		return countValuesOp{}, fmt.Errorf("%s requires a non empty label name", opType)
+	}
+
+	return countValuesOp{params: params, opType: opType}, nil
+}
+
+// countValuesOp stores required properties for count values ops
+type countValuesOp struct {
+	params NodeParams
+	opType string
+}
+
+// OpType for the operator
+func (o countValuesOp) OpType() string {
+	return o.opType
+}
+
+// String representation
+func (o countValuesOp) String() string {
+	return fmt.Sprintf("type: %s, params: %+v", o.OpType(), o.params)
+}
+
+// Node creates an execution node
+func (o countValuesOp) Node(controller *transform.Controller) transform.OpNode {
+	return &countValuesNode{
+		op:         o,
+		controller: controller,
+	}
+}
+
+// countValuesNode is an execution node
+type countValuesNode struct {
+	op         countValuesOp
+	controller *transform.Controller
+}
+
+// bucketColumn holds, for a single group, the count of each distinct value
+// at every step
+type bucketColumn struct {
+	lookup map[float64]int
+	values []float64
+	counts [][]float64
+}
+
+// Process the block
+func (n *countValuesNode) Process(ID parser.NodeID, b storage.Block) error {
+	params := n.op.params
+	meta := b.Meta()
+	seriesMetas := utils.FlattenMetadata(meta, b.SeriesMeta())
+	buckets, metas := collectSeries(params.MatchingTags, params.Without, n.op.opType, seriesMetas)
+
+	numSteps := meta.Bounds.Steps()
+	columns := make([]bucketColumn, len(buckets))
+	for i := range columns {
+		columns[i].lookup = make(map[float64]int)
+	}
+
+	stepIter := b.StepIter()
+	for index := 0; stepIter.Next(); index++ {
+		if index >= numSteps {
+			return fmt.Errorf("step index %d out of bounds: %s", index, meta.Bounds)
+		}
+
+		values := stepIter.Current().Values()
+		for bucketIdx, bucket := range buckets {
+			column := &columns[bucketIdx]
+			for _, idx := range bucket {
+				v := values[idx]
+				if math.IsNaN(v) {
+					continue
+				}
+
+				seriesIdx, ok := column.lookup[v]
+				if !ok {
+					seriesIdx = len(column.values)
+					column.lookup[v] = seriesIdx
+					column.values = append(column.values, v)
+					column.counts = append(column.counts, newNaNSlice(numSteps))
+				}
+
+				counts := column.counts[seriesIdx]
+				if math.IsNaN(counts[index]) {
+					counts[index] = 0
+				}
+
+				counts[index]++
+			}
+		}
+	}
+
+	var outputMetas []storage.SeriesMeta
+	for bucketIdx, column := range columns {
+		for _, v := range column.values {
+			tags := metas[bucketIdx].Tags.Clone()
+			tags[params.StringParameter] = strconv.FormatFloat(v, 'f', -1, 64)
+			outputMetas = append(outputMetas, storage.SeriesMeta{
+				Name: n.op.opType,
+				Tags: tags,
+			})
+		}
+	}
+
+	builder, err := n.controller.BlockBuilder(storage.BlockMetadata{Bounds: meta.Bounds}, outputMetas)
+	if err != nil {
+		return err
+	}
+
+	for index := 0; index < numSteps; index++ {
+		for _, column := range columns {
+			for _, counts := range column.counts {
+				if err := builder.AppendValue(index, counts[index]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return n.controller.Process(builder.Build())
+}
+
+func newNaNSlice(length int) []float64 {
+	values := make([]float64, length)
+	for i := range values {
+		values[i] = math.NaN()
+	}
+
+	return values
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aggregation
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/parser"
+	"github.com/m3db/m3db/src/coordinator/test"
+	"github.com/m3db/m3db/src/coordinator/test/executor"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountValues(t *testing.T) {
+	op, err := NewCountValuesOp(CountValuesType, NodeParams{StringParameter: "value"})
+	require.NoError(t, err)
+
+	b := test.NewBounds(time.Now().Truncate(time.Minute), time.Minute, 2)
+	block := test.NewBlockFromValues(b, [][]float64{
+		{1, 2},
+		{1, math.NaN()},
+	})
+
+	c, sink := executor.NewControllerWithSink(parser.NodeID("1"))
+	node := op.(countValuesOp).Node(c)
+	require.NoError(t, node.Process(parser.NodeID("0"), block))
+
+	test.EqualsWithNans(t, [][]float64{{2, math.NaN()}, {math.NaN(), 1}}, sink.Values)
+	require.Len(t, sink.Metas, 2)
+	assert.Equal(t, models.Tags{"value": "1"}, sink.Metas[0].Tags)
+	assert.Equal(t, models.Tags{"value": "2"}, sink.Metas[1].Tags)
+}
+
+func TestCountValuesInvalidParams(t *testing.T) {
+	_, err := NewCountValuesOp(CountValuesType, NodeParams{})
+	assert.Error(t, err)
+
+	_, err = NewCountValuesOp(SumType, NodeParams{StringParameter: "value"})
+	assert.Error(t, err)
+}
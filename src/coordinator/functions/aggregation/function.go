@@ -0,0 +1,134 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aggregation
+
+import (
+	"math"
+)
+
+type aggregationFn func(values []float64, bucket []int) float64
+
+var aggregationFunctions = map[string]aggregationFn{
+	SumType:               sumFn,
+	MinType:               minFn,
+	MaxType:               maxFn,
+	AverageType:           averageFn,
+	StandardDeviationType: stddevFn,
+	StandardVarianceType:  varianceFn,
+	CountType:             countFn,
+}
+
+func sumAndCount(values []float64, bucket []int) (float64, float64) {
+	sum := 0.0
+	count := 0.0
+	for _, idx := range bucket {
+		v := values[idx]
+		if !math.IsNaN(v) {
+			sum += v
+			count++
+		}
+	}
+
+	return sum, count
+}
+
+func sumFn(values []float64, bucket []int) float64 {
+	sum, count := sumAndCount(values, bucket)
+	if count == 0 {
+		return math.NaN()
+	}
+
+	return sum
+}
+
+func averageFn(values []float64, bucket []int) float64 {
+	sum, count := sumAndCount(values, bucket)
+	// Cannot use a zero value here since it would return 0 instead of NaN
+	if count == 0 {
+		return math.NaN()
+	}
+
+	return sum / count
+}
+
+func countFn(values []float64, bucket []int) float64 {
+	_, count := sumAndCount(values, bucket)
+	if count == 0 {
+		return math.NaN()
+	}
+
+	return count
+}
+
+func minFn(values []float64, bucket []int) float64 {
+	min := math.NaN()
+	for _, idx := range bucket {
+		v := values[idx]
+		if math.IsNaN(min) || v < min {
+			min = v
+		}
+	}
+
+	return min
+}
+
+func maxFn(values []float64, bucket []int) float64 {
+	max := math.NaN()
+	for _, idx := range bucket {
+		v := values[idx]
+		if math.IsNaN(max) || v > max {
+			max = v
+		}
+	}
+
+	return max
+}
+
+func varianceFn(values []float64, bucket []int) float64 {
+	var (
+		count    float64
+		mean     float64
+		variance float64
+	)
+
+	// Welford's online algorithm, matching the Prometheus implementation
+	for _, idx := range bucket {
+		v := values[idx]
+		if math.IsNaN(v) {
+			continue
+		}
+
+		count++
+		delta := v - mean
+		mean += delta / count
+		variance += delta * (v - mean)
+	}
+
+	if count == 0 {
+		return math.NaN()
+	}
+
+	return variance / count
+}
+
+func stddevFn(values []float64, bucket []int) float64 {
+	return math.Sqrt(varianceFn(values, bucket))
+}
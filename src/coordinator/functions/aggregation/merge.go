@@ -0,0 +1,129 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aggregation
+
+import (
+	"fmt"
+
+	"github.com/m3db/m3db/src/coordinator/functions/utils"
+	"github.com/m3db/m3db/src/coordinator/parser"
+	"github.com/m3db/m3db/src/coordinator/storage"
+)
+
+// mergeFunctions merge the partial results of the aggregations computed over
+// disjoint sets of series into their result over all the series
+var mergeFunctions = map[string]aggregationFn{
+	SumType:   sumFn,
+	CountType: sumFn,
+	MinType:   minFn,
+	MaxType:   maxFn,
+}
+
+// Mergeable returns whether the partial results of the aggregation computed
+// over disjoint sets of series can be merged by MergeBlocks.
+func Mergeable(op parser.Params) bool {
+	switch o := op.(type) {
+	case baseOp:
+		_, ok := mergeFunctions[o.opType]
+		return ok
+	case takeOp:
+		return true
+	}
+
+	return false
+}
+
+// MergeBlocks merges the partial results of the aggregation computed over
+// disjoint sets of series into its result over all the series. The blocks
+// must have the same bounds.
+func MergeBlocks(op parser.Params, blocks []storage.Block) (storage.Block, error) {
+	bounds, seriesMetas, columns, err := concatBlocks(blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	switch o := op.(type) {
+	case baseOp:
+		fn, ok := mergeFunctions[o.opType]
+		if !ok {
+			break
+		}
+
+		// Partial results have the tags of their group, so series with the
+		// same tags are partial results of the same group
+		buckets, metas := collectSeries(nil, true, o.opType, seriesMetas)
+		builder := storage.NewColumnBlockBuilder(storage.BlockMetadata{Bounds: bounds}, metas)
+		for index, values := range columns {
+			for _, bucket := range buckets {
+				if err := builder.AppendValue(index, fn(values, bucket)); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		return builder.Build(), nil
+	case takeOp:
+		// Partial results are the candidates of each group, the values taken
+		// among them are the values taken among all the series
+		params := o.params
+		buckets, _ := collectSeries(params.MatchingTags, params.Without, o.opType, seriesMetas)
+		builder := storage.NewColumnBlockBuilder(storage.BlockMetadata{Bounds: bounds}, seriesMetas)
+		for index, values := range columns {
+			if err := builder.AppendValues(index, o.takeFn(values, buckets)); err != nil {
+				return nil, err
+			}
+		}
+
+		return builder.Build(), nil
+	}
+
+	return nil, fmt.Errorf("partial results of %s cannot be merged", op.OpType())
+}
+
+// concatBlocks returns the series of the blocks along with their values at
+// each step
+func concatBlocks(blocks []storage.Block) (storage.Bounds, []storage.SeriesMeta, [][]float64, error) {
+	if len(blocks) == 0 {
+		return storage.Bounds{}, nil, nil, fmt.Errorf("no blocks to merge")
+	}
+
+	bounds := blocks[0].Meta().Bounds
+	columns := make([][]float64, bounds.Steps())
+	var seriesMetas []storage.SeriesMeta
+	for _, b := range blocks {
+		meta := b.Meta()
+		if !sameBounds(meta.Bounds, bounds) {
+			return storage.Bounds{}, nil, nil, fmt.Errorf("cannot merge blocks with bounds %s and %s", bounds, meta.Bounds)
+		}
+
+		seriesMetas = append(seriesMetas, utils.FlattenMetadata(meta, b.SeriesMeta())...)
+		stepIter := b.StepIter()
+		for index := 0; stepIter.Next() && index < len(columns); index++ {
+			columns[index] = append(columns[index], stepIter.Current().Values()...)
+		}
+	}
+
+	return bounds, seriesMetas, columns, nil
+}
+
+func sameBounds(a, b storage.Bounds) bool {
+	return a.Start.Equal(b.Start) && a.End.Equal(b.End) && a.StepSize == b.StepSize
+}
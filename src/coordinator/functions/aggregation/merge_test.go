@@ -0,0 +1,134 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aggregation
+
+import (
+	"testing"
+
+	"github.com/m3db/m3db/src/coordinator/executor/transform"
+	"github.com/m3db/m3db/src/coordinator/parser"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/test"
+	"github.com/m3db/m3db/src/coordinator/test/executor"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// partialBlock computes the op over the series at the given indices
+func partialBlock(t *testing.T, op parser.Params, indices ...int) storage.Block {
+	var (
+		metas []storage.SeriesMeta
+		vals  [][]float64
+	)
+
+	for _, i := range indices {
+		metas = append(metas, seriesMetas[i])
+		vals = append(vals, values[i])
+	}
+
+	block := test.NewBlockFromValuesWithSeriesMeta(bounds, metas, vals)
+	c, sink := executor.NewControllerWithSink(parser.NodeID("1"))
+	var node transform.OpNode
+	switch o := op.(type) {
+	case baseOp:
+		node = o.Node(c)
+	case takeOp:
+		node = o.Node(c)
+	}
+
+	require.NoError(t, node.Process(parser.NodeID("0"), block))
+	return test.NewBlockFromValuesWithSeriesMeta(sink.Meta.Bounds, sink.Metas, sink.Values)
+}
+
+func TestMergeBlocks(t *testing.T) {
+	tests := []struct {
+		name   string
+		opType string
+	}{
+		{"sum", SumType},
+		{"count", CountType},
+		{"min", MinType},
+		{"max", MaxType},
+		{"topk", TopKType},
+		{"bottomk", BottomKType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := NodeParams{MatchingTags: []string{"a"}, Parameter: 1}
+			op, err := NewAggregationOp(tt.opType, params)
+			if err != nil {
+				op, err = NewTakeOp(tt.opType, params)
+			}
+			require.NoError(t, err)
+			require.True(t, Mergeable(op))
+
+			expected := partialBlock(t, op, 0, 1, 2, 3)
+			merged, err := MergeBlocks(op, []storage.Block{
+				partialBlock(t, op, 0, 2),
+				partialBlock(t, op, 1, 3),
+			})
+			require.NoError(t, err)
+
+			c, sink := executor.NewControllerWithSink(parser.NodeID("1"))
+			require.NoError(t, c.Process(merged))
+			c, expectedSink := executor.NewControllerWithSink(parser.NodeID("1"))
+			require.NoError(t, c.Process(expected))
+
+			// series of the merged results may be in a different order
+			require.Len(t, sink.Metas, len(expectedSink.Metas))
+			byID := make(map[string][]float64, len(sink.Metas))
+			for i, meta := range sink.Metas {
+				byID[meta.Tags.ID()] = sink.Values[i]
+			}
+
+			for i, meta := range expectedSink.Metas {
+				actual, ok := byID[meta.Tags.ID()]
+				require.True(t, ok, "missing series %s", meta.Tags.ID())
+				test.EqualsWithNans(t, expectedSink.Values[i], actual)
+			}
+		})
+	}
+}
+
+func TestMergeBlocksNotMergeable(t *testing.T) {
+	op, err := NewAggregationOp(AverageType, NodeParams{})
+	require.NoError(t, err)
+	assert.False(t, Mergeable(op))
+
+	block := test.NewBlockFromValuesWithSeriesMeta(bounds, seriesMetas, values)
+	_, err = MergeBlocks(op, []storage.Block{block})
+	assert.Error(t, err)
+}
+
+func TestMergeBlocksDifferentBounds(t *testing.T) {
+	op, err := NewAggregationOp(SumType, NodeParams{})
+	require.NoError(t, err)
+
+	other := bounds
+	other.StepSize *= 2
+	_, err = MergeBlocks(op, []storage.Block{
+		test.NewBlockFromValuesWithSeriesMeta(bounds, seriesMetas, values),
+		test.NewBlockFromValuesWithSeriesMeta(other, seriesMetas, values),
+	})
+	assert.Error(t, err)
+}
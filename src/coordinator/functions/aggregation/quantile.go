@@ -0,0 +1,72 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aggregation
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// QuantileType takes the n-th non nan quantile element in a list of series
+	// Special cases are:
+	// 	 n < 0 = -Inf
+	// 	 n > 1 = +Inf
+	QuantileType = "quantile"
+)
+
+// makeQuantileFn creates a quantile function for the given q
+func makeQuantileFn(q float64) aggregationFn {
+	return func(values []float64, bucket []int) float64 {
+		return bucketedQuantileFn(q, values, bucket)
+	}
+}
+
+func bucketedQuantileFn(q float64, values []float64, bucket []int) float64 {
+	if q < 0 {
+		return math.Inf(-1)
+	}
+
+	if q > 1 {
+		return math.Inf(1)
+	}
+
+	sorted := make([]float64, 0, len(bucket))
+	for _, idx := range bucket {
+		v := values[idx]
+		if !math.IsNaN(v) {
+			sorted = append(sorted, v)
+		}
+	}
+
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+
+	sort.Float64s(sorted)
+
+	// Linear interpolation between the two closest ranks, as done in Prometheus
+	rank := q * float64(len(sorted)-1)
+	lowerIdx := math.Max(0, math.Floor(rank))
+	upperIdx := math.Min(float64(len(sorted)-1), lowerIdx+1)
+	weight := rank - lowerIdx
+	return sorted[int(lowerIdx)]*(1-weight) + sorted[int(upperIdx)]*weight
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aggregation
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/m3db/m3db/src/coordinator/executor/transform"
+	"github.com/m3db/m3db/src/coordinator/functions/utils"
+	"github.com/m3db/m3db/src/coordinator/parser"
+	"github.com/m3db/m3db/src/coordinator/storage"
+)
+
+const (
+	// BottomKType gets the k minimum values in each group
+	BottomKType = "bottomk"
+	// TopKType gets the k maximum values in each group
+	TopKType = "topk"
+)
+
+type takeFunc func(values []float64, buckets [][]int) []float64
+
+// NewTakeOp creates a new takeK operation
+func NewTakeOp(opType string, params NodeParams) (parser.Params, error) {
+	var less func(a, b float64) bool
+	switch opType {
+	case BottomKType:
+		less = func(a, b float64) bool { return a < b }
+	case TopKType:
+		less = func(a, b float64) bool { return a > b }
+	default:
+		return baseOp{}, fmt.Errorf("operator not supported: %s", opType)
+	}
+
+	k := int(params.Parameter)
+	fn := func(values []float64, buckets [][]int) []float64 {
+		return takeFn(less, k, values, buckets)
+	}
+
+	return newTakeOp(params, opType, fn), nil
+}
+
+// takeOp stores required properties for the take ops
+type takeOp struct {
+	params NodeParams
+	opType string
+	takeFn takeFunc
+}
+
+// OpType for the operator
+func (o takeOp) OpType() string {
+	return o.opType
+}
+
+// String representation
+func (o takeOp) String() string {
+	return fmt.Sprintf("type: %s, params: %+v", o.OpType(), o.params)
+}
+
+// Node creates an execution node
+func (o takeOp) Node(controller *transform.Controller) transform.OpNode {
+	return &takeNode{
+		op:         o,
+		controller: controller,
+	}
+}
+
+func newTakeOp(params NodeParams, opType string, takeFn takeFunc) takeOp {
+	return takeOp{
+		params: params,
+		opType: opType,
+		takeFn: takeFn,
+	}
+}
+
+// takeNode is different since it produces as many series as available in the input
+type takeNode struct {
+	op         takeOp
+	controller *transform.Controller
+}
+
+// Process the block
+func (n *takeNode) Process(ID parser.NodeID, b storage.Block) error {
+	params := n.op.params
+	meta := b.Meta()
+	seriesMetas := utils.FlattenMetadata(meta, b.SeriesMeta())
+	buckets, _ := collectSeries(params.MatchingTags, params.Without, n.op.opType, seriesMetas)
+
+	// retain the original metadata since series are returned as is
+	builder, err := n.controller.BlockBuilder(storage.BlockMetadata{Bounds: meta.Bounds}, seriesMetas)
+	if err != nil {
+		return err
+	}
+
+	stepIter := b.StepIter()
+	for index := 0; stepIter.Next(); index++ {
+		step := stepIter.Current()
+		values := step.Values()
+		if err := builder.AppendValues(index, n.op.takeFn(values, buckets)); err != nil {
+			return err
+		}
+	}
+
+	return n.controller.Process(builder.Build())
+}
+
+// takeFn keeps at most k values from every bucket and sets the rest to NaN
+func takeFn(less func(a, b float64) bool, k int, values []float64, buckets [][]int) []float64 {
+	taken := newNaNSlice(len(values))
+	if k < 1 {
+		return taken
+	}
+
+	for _, bucket := range buckets {
+		candidates := make([]int, 0, len(bucket))
+		for _, idx := range bucket {
+			if !math.IsNaN(values[idx]) {
+				candidates = append(candidates, idx)
+			}
+		}
+
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return less(values[candidates[i]], values[candidates[j]])
+		})
+
+		if len(candidates) > k {
+			candidates = candidates[:k]
+		}
+
+		for _, idx := range candidates {
+			taken[idx] = values[idx]
+		}
+	}
+
+	return taken
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aggregation
+
+import (
+	"math"
+	"testing"
+
+	"github.com/m3db/m3db/src/coordinator/parser"
+	"github.com/m3db/m3db/src/coordinator/test"
+	"github.com/m3db/m3db/src/coordinator/test/executor"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func processTakeOp(t *testing.T, op parser.Params) *executor.SinkNode {
+	block := test.NewBlockFromValuesWithSeriesMeta(bounds, seriesMetas, values)
+	c, sink := executor.NewControllerWithSink(parser.NodeID("1"))
+	node := op.(takeOp).Node(c)
+	err := node.Process(parser.NodeID("0"), block)
+	require.NoError(t, err)
+	return sink
+}
+
+func TestTakeFn(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name     string
+		opType   string
+		k        float64
+		expected [][]float64
+	}{
+		{"topk", TopKType, 1, [][]float64{{nan, nan, nan}, {3, 4, nan}, {nan, nan, nan}, {7, 8, nan}}},
+		{"bottomk", BottomKType, 1, [][]float64{{1, 2, nan}, {nan, nan, nan}, {5, nan, nan}, {nan, 8, nan}}},
+		{"topk all", TopKType, 3, values},
+		{"topk none", TopKType, 0, [][]float64{{nan, nan, nan}, {nan, nan, nan}, {nan, nan, nan}, {nan, nan, nan}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op, err := NewTakeOp(tt.opType, NodeParams{MatchingTags: []string{"a"}, Parameter: tt.k})
+			require.NoError(t, err)
+			sink := processTakeOp(t, op)
+			test.EqualsWithNans(t, tt.expected, sink.Values)
+			assert.Equal(t, seriesMetas, sink.Metas)
+		})
+	}
+}
+
+func TestTakeUnknownOp(t *testing.T) {
+	_, err := NewTakeOp(SumType, NodeParams{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package binary
+
+import (
+	"math"
+)
+
+const (
+	// PlusType adds datapoints in both series
+	PlusType = "+"
+	// MinusType subtracts rhs from lhs
+	MinusType = "-"
+	// MultiplyType multiplies datapoints by series
+	MultiplyType = "*"
+	// DivType divides datapoints by series
+	DivType = "/"
+	// ExpType raises lhs to the power of rhs
+	ExpType = "^"
+	// ModType takes the modulo of lhs by rhs
+	ModType = "%"
+
+	// EqType checks that lhs is equal to rhs
+	EqType = "=="
+	// NotEqType checks that lhs is not equal to rhs
+	NotEqType = "!="
+	// GreaterType checks that lhs is greater than rhs
+	GreaterType = ">"
+	// LesserType checks that lhs is less than rhs
+	LesserType = "<"
+	// GreaterEqType checks that lhs is greater than or equal to rhs
+	GreaterEqType = ">="
+	// LesserEqType checks that lhs is less than or equal to rhs
+	LesserEqType = "<="
+
+	// AndType uses values from lhs for which there is a value in rhs
+	AndType = "and"
+	// OrType uses all values from lhs, followed by values from rhs
+	// which do not have a match in lhs
+	OrType = "or"
+	// UnlessType uses all values from lhs which do not have a match in rhs
+	UnlessType = "unless"
+)
+
+type binaryFn func(x, y float64) float64
+
+type comparisonFn func(x, y float64) bool
+
+var (
+	arithmeticFunctions = map[string]binaryFn{
+		PlusType:     func(x, y float64) float64 { return x + y },
+		MinusType:    func(x, y float64) float64 { return x - y },
+		MultiplyType: func(x, y float64) float64 { return x * y },
+		DivType:      func(x, y float64) float64 { return x / y },
+		ExpType:      math.Pow,
+		ModType:      math.Mod,
+	}
+
+	comparisonFunctions = map[string]comparisonFn{
+		EqType:        func(x, y float64) bool { return x == y },
+		NotEqType:     func(x, y float64) bool { return x != y },
+		GreaterType:   func(x, y float64) bool { return x > y },
+		LesserType:    func(x, y float64) bool { return x < y },
+		GreaterEqType: func(x, y float64) bool { return x >= y },
+		LesserEqType:  func(x, y float64) bool { return x <= y },
+	}
+)
+
+// makeComparisonFn wraps a comparison; with returnBool the result is 1 or 0,
+// otherwise the lhs value is kept when the comparison holds and NaN is
+// returned when it does not
+func makeComparisonFn(cmp comparisonFn, returnBool bool) binaryFn {
+	if returnBool {
+		return func(x, y float64) float64 {
+			if isNaN(x) || isNaN(y) {
+				return math.NaN()
+			}
+
+			if cmp(x, y) {
+				return 1
+			}
+
+			return 0
+		}
+	}
+
+	return func(x, y float64) float64 {
+		if isNaN(x) || isNaN(y) || !cmp(x, y) {
+			return math.NaN()
+		}
+
+		return x
+	}
+}
+
+func isNaN(v float64) bool {
+	return math.IsNaN(v)
+}
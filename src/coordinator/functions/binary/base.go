@@ -0,0 +1,299 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package binary
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/m3db/m3db/src/coordinator/executor/transform"
+	"github.com/m3db/m3db/src/coordinator/parser"
+	"github.com/m3db/m3db/src/coordinator/storage"
+)
+
+var (
+	errMismatchedBounds = errors.New("block bounds are mismatched")
+	errScalarLogicalOp  = errors.New("set operations are not allowed between scalars and vectors")
+	errBothScalars      = errors.New("binary operations between two scalars are not supported")
+)
+
+// NodeParams describes the operands of a binary operation
+type NodeParams struct {
+	// LNode and RNode are the IDs of the nodes producing the left and right
+	// hand side vectors. They are ignored for scalar operands
+	LNode, RNode parser.NodeID
+	// LIsScalar and RIsScalar indicate whether the operand is a scalar literal
+	LIsScalar, RIsScalar bool
+	// LScalar and RScalar are the values of scalar operands
+	LScalar, RScalar float64
+	// ReturnBool causes comparison operations to return 0 or 1 rather than
+	// filtering series
+	ReturnBool bool
+	// VectorMatching describes how series from each side are matched
+	VectorMatching *VectorMatching
+}
+
+// VectorMatchCardinality describes the cardinality relationship
+// of two vectors in a binary operation
+type VectorMatchCardinality int
+
+const (
+	// CardOneToOne is used for one-one relationship
+	CardOneToOne VectorMatchCardinality = iota
+	// CardManyToOne is used for many-one relationship
+	CardManyToOne
+	// CardOneToMany is used for one-many relationship
+	CardOneToMany
+	// CardManyToMany is used for many-many relationship
+	CardManyToMany
+)
+
+// VectorMatching describes how elements from two vectors in a binary
+// operation are supposed to be matched
+type VectorMatching struct {
+	// Card is the cardinality of the two vectors
+	Card VectorMatchCardinality
+	// MatchingLabels contains the labels which define equality of a pair of
+	// elements from the vectors
+	MatchingLabels []string
+	// On includes the given label names from matching,
+	// rather than excluding them
+	On bool
+	// Include contains additional labels that should be included in
+	// the result from the side with the lower cardinality
+	Include []string
+}
+
+// NewOp creates a new binary operation
+func NewOp(opType string, params NodeParams) (parser.Params, error) {
+	if params.LIsScalar && params.RIsScalar {
+		return baseOp{}, errBothScalars
+	}
+
+	if params.VectorMatching == nil {
+		params.VectorMatching = &VectorMatching{Card: CardOneToOne}
+	}
+
+	if _, ok := logicalFunctions[opType]; ok {
+		if params.LIsScalar || params.RIsScalar {
+			return baseOp{}, errScalarLogicalOp
+		}
+
+		return baseOp{opType: opType, params: params}, nil
+	}
+
+	if fn, ok := arithmeticFunctions[opType]; ok {
+		return baseOp{opType: opType, params: params, fn: fn}, nil
+	}
+
+	if cmp, ok := comparisonFunctions[opType]; ok {
+		return baseOp{
+			opType:       opType,
+			params:       params,
+			fn:           makeComparisonFn(cmp, params.ReturnBool),
+			isComparison: true,
+		}, nil
+	}
+
+	return baseOp{}, fmt.Errorf("operator not supported: %s", opType)
+}
+
+// baseOp stores required properties for binary operations
+type baseOp struct {
+	opType       string
+	params       NodeParams
+	fn           binaryFn
+	isComparison bool
+}
+
+// OpType for the operator
+func (o baseOp) OpType() string {
+	return o.opType
+}
+
+// String representation
+func (o baseOp) String() string {
+	return fmt.Sprintf("type: %s, lhs: %s, rhs: %s", o.OpType(), o.params.LNode, o.params.RNode)
+}
+
+// Node creates an execution node
+func (o baseOp) Node(controller *transform.Controller) transform.OpNode {
+	return &baseNode{
+		op:         o,
+		controller: controller,
+	}
+}
+
+// dropMetricName returns true if the operation changes the meaning of the series
+func (o baseOp) dropMetricName() bool {
+	if o.isComparison {
+		return o.params.ReturnBool
+	}
+
+	_, ok := arithmeticFunctions[o.opType]
+	return ok
+}
+
+// baseNode is an execution node which waits for blocks from both
+// its parents before processing
+type baseNode struct {
+	op         baseOp
+	controller *transform.Controller
+
+	mu  sync.Mutex
+	lhs []storage.Block
+	rhs []storage.Block
+}
+
+// Process the block
+func (n *baseNode) Process(ID parser.NodeID, block storage.Block) error {
+	params := n.op.params
+	if params.LIsScalar || params.RIsScalar {
+		return n.processScalar(block)
+	}
+
+	lhs, rhs, err := n.addBlock(ID, block)
+	if err != nil {
+		return err
+	}
+
+	// Still waiting on the other side
+	if lhs == nil || rhs == nil {
+		return nil
+	}
+
+	return n.processVectors(lhs, rhs)
+}
+
+// addBlock queues the block for the given parent, returning the oldest block
+// of each side once both sides have one. Parents emit a block for each of the
+// same consecutive bounds, so the nth block of one side is matched with the
+// nth block of the other
+func (n *baseNode) addBlock(ID parser.NodeID, block storage.Block) (storage.Block, storage.Block, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	params := n.op.params
+	switch ID {
+	case params.LNode:
+		n.lhs = append(n.lhs, block)
+	case params.RNode:
+		n.rhs = append(n.rhs, block)
+	default:
+		return nil, nil, fmt.Errorf("unexpected parent %s for binary node", ID)
+	}
+
+	if len(n.lhs) == 0 || len(n.rhs) == 0 {
+		return nil, nil, nil
+	}
+
+	lhs, rhs := n.lhs[0], n.rhs[0]
+	n.lhs[0], n.rhs[0] = nil, nil
+	n.lhs, n.rhs = n.lhs[1:], n.rhs[1:]
+	return lhs, rhs, nil
+}
+
+func (n *baseNode) processVectors(lhs, rhs storage.Block) error {
+	lBounds, rBounds := lhs.Meta().Bounds, rhs.Meta().Bounds
+	if !lBounds.Start.Equal(rBounds.Start) || !lBounds.End.Equal(rBounds.End) ||
+		lBounds.StepSize != rBounds.StepSize {
+		return errMismatchedBounds
+	}
+
+	var (
+		l = newVectorData(lhs)
+		r = newVectorData(rhs)
+
+		result vectorData
+		err    error
+	)
+
+	if fn, ok := logicalFunctions[n.op.opType]; ok {
+		result = fn(n.op.params.VectorMatching, l, r)
+	} else {
+		result, err = n.matchVectors(l, r)
+		if err != nil {
+			return err
+		}
+	}
+
+	return n.build(lBounds, result)
+}
+
+func (n *baseNode) processScalar(block storage.Block) error {
+	params := n.op.params
+	vector := newVectorData(block)
+	result := vectorData{
+		metas:  make([]storage.SeriesMeta, len(vector.metas)),
+		values: make([][]float64, len(vector.values)),
+	}
+
+	for i, series := range vector.values {
+		values := make([]float64, len(series))
+		for step, v := range series {
+			if isNaN(v) {
+				values[step] = math.NaN()
+				continue
+			}
+
+			var value float64
+			if params.LIsScalar {
+				value = n.op.fn(params.LScalar, v)
+			} else {
+				value = n.op.fn(v, params.RScalar)
+			}
+
+			// Filtering comparisons always return the vector value
+			if n.op.isComparison && !params.ReturnBool && !isNaN(value) {
+				value = v
+			}
+
+			values[step] = value
+		}
+
+		result.values[i] = values
+		result.metas[i] = vector.metas[i]
+		if n.op.dropMetricName() {
+			result.metas[i] = withoutMetricName(vector.metas[i])
+		}
+	}
+
+	return n.build(block.Meta().Bounds, result)
+}
+
+func (n *baseNode) build(bounds storage.Bounds, result vectorData) error {
+	builder, err := n.controller.BlockBuilder(storage.BlockMetadata{Bounds: bounds}, result.metas)
+	if err != nil {
+		return err
+	}
+
+	for step := 0; step < bounds.Steps(); step++ {
+		for _, values := range result.values {
+			if err := builder.AppendValue(step, values[step]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return n.controller.Process(builder.Build())
+}
@@ -0,0 +1,284 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package binary
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/parser"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/test"
+	"github.com/m3db/m3db/src/coordinator/test/executor"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	lhsID = parser.NodeID("0")
+	rhsID = parser.NodeID("1")
+
+	bounds = test.NewBounds(time.Now().Truncate(time.Minute), time.Minute, 3)
+)
+
+func vectorParams(matching *VectorMatching) NodeParams {
+	return NodeParams{LNode: lhsID, RNode: rhsID, VectorMatching: matching}
+}
+
+func processVectors(
+	t *testing.T,
+	op parser.Params,
+	lhsMetas []storage.SeriesMeta,
+	lhs [][]float64,
+	rhsMetas []storage.SeriesMeta,
+	rhs [][]float64,
+) (*executor.SinkNode, error) {
+	c, sink := executor.NewControllerWithSink(parser.NodeID("2"))
+	node := op.(baseOp).Node(c)
+	err := node.Process(lhsID, test.NewBlockFromValuesWithSeriesMeta(bounds, lhsMetas, lhs))
+	require.NoError(t, err)
+	require.Empty(t, sink.Values, "should wait for both sides")
+	err = node.Process(rhsID, test.NewBlockFromValuesWithSeriesMeta(bounds, rhsMetas, rhs))
+	return sink, err
+}
+
+func TestNewOpErrors(t *testing.T) {
+	_, err := NewOp(PlusType, NodeParams{LIsScalar: true, RIsScalar: true})
+	assert.Error(t, err)
+
+	_, err = NewOp(AndType, NodeParams{LNode: lhsID, RIsScalar: true})
+	assert.Error(t, err)
+
+	_, err = NewOp("atan2", vectorParams(nil))
+	assert.Error(t, err)
+}
+
+func TestScalarOps(t *testing.T) {
+	nan := math.NaN()
+	metas := []storage.SeriesMeta{{Tags: models.Tags{models.MetricName: "a", "a": "1"}}}
+	values := [][]float64{{1, 2, nan}}
+
+	tests := []struct {
+		name     string
+		opType   string
+		params   NodeParams
+		expected []float64
+		keepName bool
+	}{
+		{"vector + scalar", PlusType, NodeParams{LNode: lhsID, RIsScalar: true, RScalar: 2}, []float64{3, 4, nan}, false},
+		{"scalar - vector", MinusType, NodeParams{LIsScalar: true, LScalar: 10, RNode: lhsID}, []float64{9, 8, nan}, false},
+		{"vector ^ scalar", ExpType, NodeParams{LNode: lhsID, RIsScalar: true, RScalar: 2}, []float64{1, 4, nan}, false},
+		{"vector > scalar", GreaterType, NodeParams{LNode: lhsID, RIsScalar: true, RScalar: 1}, []float64{nan, 2, nan}, true},
+		{"scalar < vector", LesserType, NodeParams{LIsScalar: true, LScalar: 1, RNode: lhsID}, []float64{nan, 2, nan}, true},
+		{"vector > bool scalar", GreaterType, NodeParams{LNode: lhsID, RIsScalar: true, RScalar: 1, ReturnBool: true}, []float64{0, 1, nan}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op, err := NewOp(tt.opType, tt.params)
+			require.NoError(t, err)
+
+			c, sink := executor.NewControllerWithSink(parser.NodeID("1"))
+			node := op.(baseOp).Node(c)
+			err = node.Process(lhsID, test.NewBlockFromValuesWithSeriesMeta(bounds, metas, values))
+			require.NoError(t, err)
+
+			test.EqualsWithNans(t, [][]float64{tt.expected}, sink.Values)
+			require.Len(t, sink.Metas, 1)
+			_, hasName := sink.Metas[0].Tags[models.MetricName]
+			assert.Equal(t, tt.keepName, hasName)
+			assert.Equal(t, "1", sink.Metas[0].Tags["a"])
+		})
+	}
+}
+
+func TestOneToOneMatching(t *testing.T) {
+	nan := math.NaN()
+	lhsMetas := []storage.SeriesMeta{
+		{Tags: models.Tags{models.MetricName: "a", "a": "1"}},
+		{Tags: models.Tags{models.MetricName: "a", "a": "2"}},
+		{Tags: models.Tags{models.MetricName: "a", "a": "3"}},
+	}
+	rhsMetas := []storage.SeriesMeta{
+		{Tags: models.Tags{models.MetricName: "b", "a": "2"}},
+		{Tags: models.Tags{models.MetricName: "b", "a": "1"}},
+	}
+	lhs := [][]float64{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}
+	rhs := [][]float64{{10, nan, 10}, {1, 1, 1}}
+
+	op, err := NewOp(PlusType, vectorParams(nil))
+	require.NoError(t, err)
+	sink, err := processVectors(t, op, lhsMetas, lhs, rhsMetas, rhs)
+	require.NoError(t, err)
+
+	test.EqualsWithNans(t, [][]float64{{2, 3, 4}, {14, nan, 16}}, sink.Values)
+	require.Len(t, sink.Metas, 2)
+	assert.Equal(t, models.Tags{"a": "1"}, sink.Metas[0].Tags)
+	assert.Equal(t, models.Tags{"a": "2"}, sink.Metas[1].Tags)
+	assert.Equal(t, bounds, sink.Meta.Bounds)
+}
+
+func TestOneToOneComparisonKeepsName(t *testing.T) {
+	lhsMetas := []storage.SeriesMeta{{Tags: models.Tags{models.MetricName: "a", "a": "1"}}}
+	rhsMetas := []storage.SeriesMeta{{Tags: models.Tags{models.MetricName: "b", "a": "1"}}}
+
+	op, err := NewOp(GreaterEqType, vectorParams(nil))
+	require.NoError(t, err)
+	sink, err := processVectors(t, op, lhsMetas, [][]float64{{1, 2, 3}}, rhsMetas, [][]float64{{2, 2, 2}})
+	require.NoError(t, err)
+
+	test.EqualsWithNans(t, [][]float64{{math.NaN(), 2, 3}}, sink.Values)
+	assert.Equal(t, models.Tags{models.MetricName: "a", "a": "1"}, sink.Metas[0].Tags)
+}
+
+func TestOnMatching(t *testing.T) {
+	lhsMetas := []storage.SeriesMeta{{Tags: models.Tags{models.MetricName: "a", "a": "1", "b": "1"}}}
+	rhsMetas := []storage.SeriesMeta{{Tags: models.Tags{models.MetricName: "b", "a": "1", "b": "2"}}}
+
+	op, err := NewOp(MultiplyType, vectorParams(&VectorMatching{
+		Card:           CardOneToOne,
+		On:             true,
+		MatchingLabels: []string{"a"},
+	}))
+	require.NoError(t, err)
+	sink, err := processVectors(t, op, lhsMetas, [][]float64{{1, 2, 3}}, rhsMetas, [][]float64{{2, 2, 2}})
+	require.NoError(t, err)
+
+	test.EqualsWithNans(t, [][]float64{{2, 4, 6}}, sink.Values)
+	assert.Equal(t, models.Tags{"a": "1"}, sink.Metas[0].Tags)
+}
+
+func TestGroupLeft(t *testing.T) {
+	lhsMetas := []storage.SeriesMeta{
+		{Tags: models.Tags{models.MetricName: "a", "a": "1", "b": "1"}},
+		{Tags: models.Tags{models.MetricName: "a", "a": "1", "b": "2"}},
+		{Tags: models.Tags{models.MetricName: "a", "a": "2", "b": "1"}},
+	}
+	rhsMetas := []storage.SeriesMeta{{Tags: models.Tags{models.MetricName: "b", "a": "1", "c": "x"}}}
+	lhs := [][]float64{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}
+
+	op, err := NewOp(DivType, vectorParams(&VectorMatching{
+		Card:           CardManyToOne,
+		On:             true,
+		MatchingLabels: []string{"a"},
+		Include:        []string{"c"},
+	}))
+	require.NoError(t, err)
+	sink, err := processVectors(t, op, lhsMetas, lhs, rhsMetas, [][]float64{{2, 2, 2}})
+	require.NoError(t, err)
+
+	test.EqualsWithNans(t, [][]float64{{0.5, 1, 1.5}, {2, 2.5, 3}}, sink.Values)
+	require.Len(t, sink.Metas, 2)
+	assert.Equal(t, models.Tags{"a": "1", "b": "1", "c": "x"}, sink.Metas[0].Tags)
+	assert.Equal(t, models.Tags{"a": "1", "b": "2", "c": "x"}, sink.Metas[1].Tags)
+}
+
+func TestGroupRight(t *testing.T) {
+	lhsMetas := []storage.SeriesMeta{{Tags: models.Tags{models.MetricName: "a", "a": "1"}}}
+	rhsMetas := []storage.SeriesMeta{
+		{Tags: models.Tags{models.MetricName: "b", "a": "1", "b": "1"}},
+		{Tags: models.Tags{models.MetricName: "b", "a": "1", "b": "2"}},
+	}
+
+	op, err := NewOp(MinusType, vectorParams(&VectorMatching{
+		Card:           CardOneToMany,
+		On:             true,
+		MatchingLabels: []string{"a"},
+	}))
+	require.NoError(t, err)
+	sink, err := processVectors(t, op, lhsMetas, [][]float64{{10, 10, 10}}, rhsMetas, [][]float64{{1, 2, 3}, {4, 5, 6}})
+	require.NoError(t, err)
+
+	test.EqualsWithNans(t, [][]float64{{9, 8, 7}, {6, 5, 4}}, sink.Values)
+	require.Len(t, sink.Metas, 2)
+	assert.Equal(t, models.Tags{"a": "1", "b": "1"}, sink.Metas[0].Tags)
+	assert.Equal(t, models.Tags{"a": "1", "b": "2"}, sink.Metas[1].Tags)
+}
+
+func TestMatchingErrors(t *testing.T) {
+	many := []storage.SeriesMeta{
+		{Tags: models.Tags{"a": "1", "b": "1"}},
+		{Tags: models.Tags{"a": "1", "b": "2"}},
+	}
+	one := []storage.SeriesMeta{{Tags: models.Tags{"a": "1"}}}
+	manyValues := [][]float64{{1, 1, 1}, {2, 2, 2}}
+	oneValues := [][]float64{{1, 1, 1}}
+	onA := &VectorMatching{Card: CardOneToOne, On: true, MatchingLabels: []string{"a"}}
+
+	// Many-to-one matching must be explicit
+	op, err := NewOp(PlusType, vectorParams(onA))
+	require.NoError(t, err)
+	_, err = processVectors(t, op, many, manyValues, one, oneValues)
+	assert.Error(t, err)
+
+	// Duplicate series on the "one" side
+	op, err = NewOp(PlusType, vectorParams(&VectorMatching{
+		Card:           CardManyToOne,
+		On:             true,
+		MatchingLabels: []string{"a"},
+	}))
+	require.NoError(t, err)
+	_, err = processVectors(t, op, one, oneValues, many, manyValues)
+	assert.Error(t, err)
+}
+
+func TestMismatchedBounds(t *testing.T) {
+	op, err := NewOp(PlusType, vectorParams(nil))
+	require.NoError(t, err)
+
+	c, _ := executor.NewControllerWithSink(parser.NodeID("2"))
+	node := op.(baseOp).Node(c)
+	values := [][]float64{{1, 2, 3}}
+	require.NoError(t, node.Process(lhsID, test.NewBlockFromValues(bounds, values)))
+
+	shifted := bounds
+	shifted.Start = bounds.Start.Add(time.Minute)
+	shifted.End = bounds.End.Add(time.Minute)
+	err = node.Process(rhsID, test.NewBlockFromValues(shifted, values))
+	assert.Error(t, err)
+}
+
+func TestMultipleBlocks(t *testing.T) {
+	op, err := NewOp(PlusType, vectorParams(nil))
+	require.NoError(t, err)
+
+	c, sink := executor.NewControllerWithSink(parser.NodeID("2"))
+	node := op.(baseOp).Node(c)
+
+	next := bounds
+	next.Start = bounds.End
+	next.End = bounds.End.Add(bounds.End.Sub(bounds.Start))
+
+	require.NoError(t, node.Process(lhsID, test.NewBlockFromValues(bounds, [][]float64{{1, 2, 3}})))
+	require.NoError(t, node.Process(lhsID, test.NewBlockFromValues(next, [][]float64{{4, 5, 6}})))
+	require.Empty(t, sink.Values, "should wait for both sides")
+
+	require.NoError(t, node.Process(rhsID, test.NewBlockFromValues(bounds, [][]float64{{1, 1, 1}})))
+	assert.Equal(t, [][]float64{{2, 3, 4}}, sink.Values)
+	assert.Equal(t, bounds, sink.Meta.Bounds)
+
+	require.NoError(t, node.Process(rhsID, test.NewBlockFromValues(next, [][]float64{{2, 2, 2}})))
+	assert.Equal(t, [][]float64{{6, 7, 8}}, sink.Values)
+	assert.Equal(t, next, sink.Meta.Bounds)
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package binary
+
+import (
+	"math"
+)
+
+type logicalFn func(matching *VectorMatching, lhs, rhs vectorData) vectorData
+
+var logicalFunctions = map[string]logicalFn{
+	AndType:    andFn,
+	OrType:     orFn,
+	UnlessType: unlessFn,
+}
+
+// presence returns, for each signature, which steps have a value in any of
+// the series with that signature
+func presence(matching *VectorMatching, vector vectorData) map[string][]bool {
+	present := make(map[string][]bool, len(vector.metas))
+	for i, meta := range vector.metas {
+		sig := signature(matching, meta.Tags)
+		steps, ok := present[sig]
+		if !ok {
+			steps = make([]bool, len(vector.values[i]))
+			present[sig] = steps
+		}
+
+		for step, v := range vector.values[i] {
+			if !isNaN(v) {
+				steps[step] = true
+			}
+		}
+	}
+
+	return present
+}
+
+// filter keeps values from the lhs vector depending on whether a matching
+// rhs value exists at the same step
+func filter(matching *VectorMatching, lhs, rhs vectorData, keepMatched bool) vectorData {
+	var (
+		result  vectorData
+		present = presence(matching, rhs)
+	)
+
+	for i, meta := range lhs.metas {
+		steps := present[signature(matching, meta.Tags)]
+		values := make([]float64, len(lhs.values[i]))
+		hasValue := false
+		for step, v := range lhs.values[i] {
+			matched := steps != nil && steps[step]
+			if matched != keepMatched || isNaN(v) {
+				values[step] = math.NaN()
+				continue
+			}
+
+			values[step] = v
+			hasValue = true
+		}
+
+		if hasValue {
+			result.append(meta, values)
+		}
+	}
+
+	return result
+}
+
+func andFn(matching *VectorMatching, lhs, rhs vectorData) vectorData {
+	return filter(matching, lhs, rhs, true)
+}
+
+func unlessFn(matching *VectorMatching, lhs, rhs vectorData) vectorData {
+	return filter(matching, lhs, rhs, false)
+}
+
+func orFn(matching *VectorMatching, lhs, rhs vectorData) vectorData {
+	var result vectorData
+	for i, meta := range lhs.metas {
+		result.append(meta, lhs.values[i])
+	}
+
+	rhsOnly := filter(matching, rhs, lhs, false)
+	for i, meta := range rhsOnly.metas {
+		result.append(meta, rhsOnly.values[i])
+	}
+
+	return result
+}
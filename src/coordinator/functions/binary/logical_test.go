@@ -0,0 +1,93 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package binary
+
+import (
+	"math"
+	"testing"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	logicalLhsMetas = []storage.SeriesMeta{
+		{Tags: models.Tags{models.MetricName: "a", "a": "1"}},
+		{Tags: models.Tags{models.MetricName: "a", "a": "2"}},
+	}
+	logicalRhsMetas = []storage.SeriesMeta{
+		{Tags: models.Tags{models.MetricName: "b", "a": "2"}},
+		{Tags: models.Tags{models.MetricName: "b", "a": "3"}},
+	}
+)
+
+func TestLogicalOps(t *testing.T) {
+	nan := math.NaN()
+	lhs := [][]float64{{1, 2, 3}, {4, nan, 6}}
+	rhs := [][]float64{{nan, 10, 10}, {20, 20, nan}}
+
+	tests := []struct {
+		name     string
+		opType   string
+		expected [][]float64
+		names    []string
+	}{
+		{"and", AndType, [][]float64{{nan, nan, 6}}, []string{"a"}},
+		{"unless", UnlessType, [][]float64{{1, 2, 3}, {4, nan, nan}}, []string{"a", "a"}},
+		{"or", OrType, [][]float64{{1, 2, 3}, {4, nan, 6}, {nan, 10, nan}, {20, 20, nan}}, []string{"a", "a", "b", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op, err := NewOp(tt.opType, vectorParams(&VectorMatching{Card: CardManyToMany}))
+			require.NoError(t, err)
+			sink, err := processVectors(t, op, logicalLhsMetas, lhs, logicalRhsMetas, rhs)
+			require.NoError(t, err)
+
+			test.EqualsWithNans(t, tt.expected, sink.Values)
+			require.Len(t, sink.Metas, len(tt.names))
+			for i, name := range tt.names {
+				assert.Equal(t, name, sink.Metas[i].Tags[models.MetricName])
+			}
+		})
+	}
+}
+
+func TestLogicalOpsOnLabels(t *testing.T) {
+	lhsMetas := []storage.SeriesMeta{{Tags: models.Tags{"a": "1", "b": "1"}}}
+	rhsMetas := []storage.SeriesMeta{{Tags: models.Tags{"a": "1", "b": "2"}}}
+
+	op, err := NewOp(AndType, vectorParams(&VectorMatching{
+		Card:           CardManyToMany,
+		On:             true,
+		MatchingLabels: []string{"a"},
+	}))
+	require.NoError(t, err)
+	sink, err := processVectors(t, op, lhsMetas, [][]float64{{1, 2, 3}}, rhsMetas, [][]float64{{1, 1, 1}})
+	require.NoError(t, err)
+
+	test.EqualsWithNans(t, [][]float64{{1, 2, 3}}, sink.Values)
+	assert.Equal(t, lhsMetas[0].Tags, sink.Metas[0].Tags)
+}
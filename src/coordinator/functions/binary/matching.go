@@ -0,0 +1,171 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package binary
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/m3db/m3db/src/coordinator/functions/utils"
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/storage"
+)
+
+// vectorData holds the series of a block in series major order
+type vectorData struct {
+	metas  []storage.SeriesMeta
+	values [][]float64
+}
+
+func newVectorData(block storage.Block) vectorData {
+	metas := utils.FlattenMetadata(block.Meta(), block.SeriesMeta())
+	values := make([][]float64, len(metas))
+	steps := block.Meta().Bounds.Steps()
+	for i := range values {
+		values[i] = make([]float64, steps)
+	}
+
+	stepIter := block.StepIter()
+	for step := 0; stepIter.Next() && step < steps; step++ {
+		for i, v := range stepIter.Current().Values() {
+			values[i][step] = v
+		}
+	}
+
+	return vectorData{metas: metas, values: values}
+}
+
+func (v *vectorData) append(meta storage.SeriesMeta, values []float64) {
+	v.metas = append(v.metas, meta)
+	v.values = append(v.values, values)
+}
+
+// signature returns the matching signature for the given series tags
+func signature(matching *VectorMatching, tags models.Tags) string {
+	if matching.On {
+		return utils.TagsWithKeys(tags, matching.MatchingLabels).ID()
+	}
+
+	return utils.TagsWithoutKeys(tags, matching.MatchingLabels).ID()
+}
+
+func withoutMetricName(meta storage.SeriesMeta) storage.SeriesMeta {
+	tags := meta.Tags.Clone()
+	delete(tags, models.MetricName)
+	return storage.SeriesMeta{Name: meta.Name, Tags: tags}
+}
+
+// matchVectors applies the operation to each pair of matched series, following
+// the Prometheus vector matching rules
+func (n *baseNode) matchVectors(lhs, rhs vectorData) (vectorData, error) {
+	matching := n.op.params.VectorMatching
+	if matching.Card == CardManyToMany {
+		return vectorData{}, fmt.Errorf("many-to-many matching not allowed for operator %s", n.op.opType)
+	}
+
+	// The "one" side is always on the right after the swap
+	many, one := lhs, rhs
+	if matching.Card == CardOneToMany {
+		many, one = rhs, lhs
+	}
+
+	oneBySignature := make(map[string]int, len(one.metas))
+	for i, meta := range one.metas {
+		sig := signature(matching, meta.Tags)
+		if _, ok := oneBySignature[sig]; ok {
+			return vectorData{}, fmt.Errorf("found duplicate series for the match group %s "+
+				"on the side of the operation with lower cardinality", sig)
+		}
+
+		oneBySignature[sig] = i
+	}
+
+	var (
+		result  vectorData
+		matched = make(map[string]struct{}, len(many.metas))
+	)
+
+	for i, meta := range many.metas {
+		sig := signature(matching, meta.Tags)
+		idx, ok := oneBySignature[sig]
+		if !ok {
+			continue
+		}
+
+		if matching.Card == CardOneToOne {
+			if _, ok := matched[sig]; ok {
+				return vectorData{}, fmt.Errorf("multiple matches for labels %s: "+
+					"many-to-one matching must be explicit (group_left/group_right)", sig)
+			}
+
+			matched[sig] = struct{}{}
+		}
+
+		manyValues, oneValues := many.values[i], one.values[idx]
+		values := make([]float64, len(manyValues))
+		for step := range values {
+			l, r := manyValues[step], oneValues[step]
+			if isNaN(l) || isNaN(r) {
+				values[step] = math.NaN()
+				continue
+			}
+
+			if matching.Card == CardOneToMany {
+				l, r = r, l
+			}
+
+			values[step] = n.op.fn(l, r)
+		}
+
+		result.append(n.resultMeta(meta, one.metas[idx]), values)
+	}
+
+	return result, nil
+}
+
+// resultMeta computes the metadata of a series produced by matching two series
+func (n *baseNode) resultMeta(many, one storage.SeriesMeta) storage.SeriesMeta {
+	matching := n.op.params.VectorMatching
+	tags := many.Tags.Clone()
+	if n.op.dropMetricName() {
+		delete(tags, models.MetricName)
+	}
+
+	if matching.Card == CardOneToOne {
+		if matching.On {
+			tags = utils.TagsWithKeys(tags, matching.MatchingLabels)
+		} else {
+			for _, k := range matching.MatchingLabels {
+				delete(tags, k)
+			}
+		}
+	}
+
+	for _, k := range matching.Include {
+		if v, ok := one.Tags[k]; ok {
+			tags[k] = v
+		} else {
+			delete(tags, k)
+		}
+	}
+
+	return storage.SeriesMeta{Name: many.Name, Tags: tags}
+}
@@ -25,10 +25,12 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/m3db/m3db/src/coordinator/cost"
 	"github.com/m3db/m3db/src/coordinator/executor/transform"
 	"github.com/m3db/m3db/src/coordinator/models"
 	"github.com/m3db/m3db/src/coordinator/parser"
 	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/ts"
 )
 
 // FetchType gets the series from storage
@@ -47,7 +49,10 @@ type FetchNode struct {
 	op         FetchOp
 	controller *transform.Controller
 	storage    storage.Storage
-	now        time.Time
+	timespec   transform.TimeSpec
+	enforcer   *cost.Enforcer
+	warnings   *storage.WarningsCollector
+	localOnly  bool
 }
 
 // OpType for the operator
@@ -62,23 +67,48 @@ func (o FetchOp) String() string {
 
 // Node creates an execution node
 func (o FetchOp) Node(controller *transform.Controller, storage storage.Storage, options transform.Options) parser.Source {
-	return &FetchNode{op: o, controller: controller, storage: storage, now: options.Now}
+	return &FetchNode{
+		op:         o,
+		controller: controller,
+		storage:    storage,
+		timespec:   options.TimeSpec,
+		enforcer:   options.Enforcer,
+		warnings:   options.Warnings,
+		localOnly:  options.LocalOnly,
+	}
 }
 
 // Execute runs the fetch node operation
 func (n *FetchNode) Execute(ctx context.Context) error {
-	startTime := n.now.Add(-1 * n.op.Offset)
-	endTime := startTime.Add(n.op.Range)
+	if n.op.Range > 0 {
+		return n.executeRange(ctx)
+	}
+
+	timeSpec := n.timespec
+	startTime := timeSpec.Start.Add(-1 * n.op.Offset)
+	endTime := timeSpec.End.Add(-1 * n.op.Offset)
 	blockResult, err := n.storage.FetchBlocks(ctx, &storage.FetchQuery{
 		Start:       startTime,
 		End:         endTime,
+		Interval:    timeSpec.Step,
 		TagMatchers: n.op.Matchers,
-	}, &storage.FetchOptions{})
+	}, &storage.FetchOptions{
+		Enforcer:  n.enforcer,
+		LocalOnly: n.localOnly,
+	})
 	if err != nil {
 		return err
 	}
 
+	n.warnings.Add(blockResult.Warnings)
 	for _, block := range blockResult.Blocks {
+		if n.op.Offset != 0 {
+			block, err = n.shiftBlock(block)
+			if err != nil {
+				return err
+			}
+		}
+
 		if err := n.controller.Process(block); err != nil {
 			// Fail on first error
 			return err
@@ -87,3 +117,84 @@ func (n *FetchNode) Execute(ctx context.Context) error {
 
 	return nil
 }
+
+// executeRange fetches the raw datapoints of the series for a range vector
+// function, which evaluates its windows over the raw datapoints rather than
+// over the step values
+func (n *FetchNode) executeRange(ctx context.Context) error {
+	timeSpec := n.timespec
+	history := time.Duration(transform.HistorySteps(n.op.Range, timeSpec.Step)) * timeSpec.Step
+	bounds := storage.Bounds{
+		Start:    timeSpec.Start.Add(-1 * history),
+		End:      timeSpec.End,
+		StepSize: timeSpec.Step,
+	}
+
+	// The window of the first step reaches back by the range
+	result, err := n.storage.Fetch(ctx, &storage.FetchQuery{
+		Start:       bounds.Start.Add(-1 * (n.op.Offset + n.op.Range)),
+		End:         bounds.End.Add(-1 * n.op.Offset),
+		Interval:    timeSpec.Step,
+		TagMatchers: n.op.Matchers,
+	}, &storage.FetchOptions{
+		Enforcer:  n.enforcer,
+		LocalOnly: n.localOnly,
+	})
+	if err != nil {
+		return err
+	}
+
+	n.warnings.Add(result.Warnings)
+	seriesList := result.SeriesList
+	if n.op.Offset != 0 {
+		seriesList = shiftSeries(seriesList, n.op.Offset)
+	}
+
+	block, err := storage.NewUnconsolidatedBlock(seriesList, bounds, storage.NewConsolidationOptions())
+	if err != nil {
+		return err
+	}
+
+	return n.controller.Process(block)
+}
+
+// shiftSeries moves series fetched with an offset back to the query time range
+func shiftSeries(seriesList []*ts.Series, offset time.Duration) []*ts.Series {
+	shifted := make([]*ts.Series, len(seriesList))
+	for i, series := range seriesList {
+		values := series.Values()
+		datapoints := make(ts.Datapoints, values.Len())
+		for j := range datapoints {
+			dp := values.DatapointAt(j)
+			datapoints[j] = ts.Datapoint{Timestamp: dp.Timestamp.Add(offset), Value: dp.Value}
+		}
+
+		shifted[i] = ts.NewSeries(series.Name(), datapoints, series.Tags)
+	}
+
+	return shifted
+}
+
+// shiftBlock moves a block fetched with an offset back to the query time range
+func (n *FetchNode) shiftBlock(block storage.Block) (storage.Block, error) {
+	meta := block.Meta()
+	meta.Bounds.Start = meta.Bounds.Start.Add(n.op.Offset)
+	meta.Bounds.End = meta.Bounds.End.Add(n.op.Offset)
+	builder, err := n.controller.BlockBuilder(meta, block.SeriesMeta())
+	if err != nil {
+		return nil, err
+	}
+
+	stepIter := block.StepIter()
+	for index := 0; stepIter.Next(); index++ {
+		if err := builder.AppendValues(index, stepIter.Current().Values()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := block.Close(); err != nil {
+		return nil, err
+	}
+
+	return builder.Build(), nil
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package temporal
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/executor/transform"
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/parser"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/ts"
+)
+
+// temporalFn computes a single value from the datapoints which fall within
+// the window (windowStart, windowEnd]. Datapoints are never NaN.
+type temporalFn func(dps ts.Datapoints, windowStart, windowEnd time.Time) float64
+
+// NewTemporalOp creates a new range vector operation for the given duration.
+// Parameter is only used by ops which take a scalar argument, such as quantile_over_time
+func NewTemporalOp(opType string, duration time.Duration, parameter float64) (parser.Params, error) {
+	if duration <= 0 {
+		return baseOp{}, fmt.Errorf("invalid range %v for %s", duration, opType)
+	}
+
+	if opType == QuantileOverTimeType {
+		return newBaseOp(opType, duration, makeQuantileOverTimeFn(parameter)), nil
+	}
+
+	if fn, ok := rateFunctions[opType]; ok {
+		return newBaseOp(opType, duration, fn), nil
+	}
+
+	if fn, ok := overTimeFunctions[opType]; ok {
+		return newBaseOp(opType, duration, fn), nil
+	}
+
+	return baseOp{}, fmt.Errorf("operator not supported: %s", opType)
+}
+
+// baseOp stores required properties for range vector functions
+type baseOp struct {
+	opType   string
+	duration time.Duration
+	fn       temporalFn
+}
+
+func newBaseOp(opType string, duration time.Duration, fn temporalFn) baseOp {
+	return baseOp{
+		opType:   opType,
+		duration: duration,
+		fn:       fn,
+	}
+}
+
+// OpType for the operator
+func (o baseOp) OpType() string {
+	return o.opType
+}
+
+// String representation
+func (o baseOp) String() string {
+	return fmt.Sprintf("type: %s, duration: %v", o.OpType(), o.duration)
+}
+
+// Node creates an execution node
+func (o baseOp) Node(controller *transform.Controller) transform.OpNode {
+	return &baseNode{
+		op:         o,
+		controller: controller,
+	}
+}
+
+// baseNode is an execution node
+type baseNode struct {
+	op         baseOp
+	controller *transform.Controller
+}
+
+// Process the block. The leading steps of the block only provide the history
+// for the first window, so the resulting block starts after them. Windows are
+// evaluated over the raw datapoints of the series when the block keeps them,
+// and over the step values otherwise
+func (n *baseNode) Process(ID parser.NodeID, b storage.Block) error {
+	meta := b.Meta()
+	bounds := meta.Bounds
+	steps := bounds.Steps()
+	history := transform.HistorySteps(n.op.duration, bounds.StepSize)
+	if history > steps {
+		history = steps
+	}
+
+	var results [][]float64
+	if unconsolidated, ok := b.(storage.UnconsolidatedBlock); ok {
+		raw := unconsolidated.Unconsolidated()
+		results = make([][]float64, 0, len(raw))
+		for _, values := range raw {
+			results = append(results, n.processSeries(bounds, steps, values))
+		}
+	} else {
+		seriesIter := b.SeriesIter()
+		results = make([][]float64, 0, seriesIter.SeriesCount())
+		for seriesIter.Next() {
+			series := seriesIter.Current()
+			results = append(results, n.processSeries(bounds, steps, series.Values()))
+		}
+	}
+
+	resultMeta := meta
+	resultMeta.Bounds.Start = bounds.Start.Add(time.Duration(history) * bounds.StepSize)
+	builder, err := n.controller.BlockBuilder(resultMeta, dropMetricName(b.SeriesMeta()))
+	if err != nil {
+		return err
+	}
+
+	for index := history; index < steps; index++ {
+		for _, values := range results {
+			if err := builder.AppendValue(index-history, values[index]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return n.controller.Process(builder.Build())
+}
+
+// processSeries applies the function over a sliding window for every step
+func (n *baseNode) processSeries(bounds storage.Bounds, steps int, values ts.Values) []float64 {
+	var (
+		results = make([]float64, steps)
+		window  = make(ts.Datapoints, 0, values.Len())
+		next    = 0
+	)
+
+	for i := 0; i < steps; i++ {
+		windowEnd := bounds.Start.Add(time.Duration(i) * bounds.StepSize)
+		windowStart := windowEnd.Add(-n.op.duration)
+
+		// Add all datapoints which are not after the end of the window
+		for ; next < values.Len(); next++ {
+			dp := values.DatapointAt(next)
+			if dp.Timestamp.After(windowEnd) {
+				break
+			}
+
+			if !math.IsNaN(dp.Value) {
+				window = append(window, dp)
+			}
+		}
+
+		// Drop all datapoints which are not after the start of the window
+		dropped := 0
+		for ; dropped < len(window); dropped++ {
+			if window[dropped].Timestamp.After(windowStart) {
+				break
+			}
+		}
+
+		window = window[dropped:]
+		results[i] = n.op.fn(window, windowStart, windowEnd)
+	}
+
+	return results
+}
+
+// dropMetricName removes the metric name since range functions change the
+// meaning of the series
+func dropMetricName(metas []storage.SeriesMeta) []storage.SeriesMeta {
+	dropped := make([]storage.SeriesMeta, len(metas))
+	for i, m := range metas {
+		tags := m.Tags.Clone()
+		delete(tags, models.MetricName)
+		dropped[i] = storage.SeriesMeta{
+			Name: m.Name,
+			Tags: tags,
+		}
+	}
+
+	return dropped
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package temporal
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/ts"
+)
+
+const (
+	// AvgOverTimeType averages all values in the window
+	AvgOverTimeType = "avg_over_time"
+	// CountOverTimeType counts all values in the window
+	CountOverTimeType = "count_over_time"
+	// MinOverTimeType takes the minimum of all values in the window
+	MinOverTimeType = "min_over_time"
+	// MaxOverTimeType takes the maximum of all values in the window
+	MaxOverTimeType = "max_over_time"
+	// SumOverTimeType sums all values in the window
+	SumOverTimeType = "sum_over_time"
+	// StdDevOverTimeType takes the population standard deviation of all values in the window
+	StdDevOverTimeType = "stddev_over_time"
+	// StdVarOverTimeType takes the population standard variance of all values in the window
+	StdVarOverTimeType = "stdvar_over_time"
+	// QuantileOverTimeType takes the q-quantile of all values in the window
+	QuantileOverTimeType = "quantile_over_time"
+	// ResetsType counts the number of counter resets in the window
+	ResetsType = "resets"
+	// ChangesType counts the number of times the value changed in the window
+	ChangesType = "changes"
+)
+
+var overTimeFunctions = map[string]temporalFn{
+	AvgOverTimeType:    withValues(avgOverTime),
+	CountOverTimeType:  withValues(countOverTime),
+	MinOverTimeType:    withValues(minOverTime),
+	MaxOverTimeType:    withValues(maxOverTime),
+	SumOverTimeType:    withValues(sumOverTime),
+	StdDevOverTimeType: withValues(stddevOverTime),
+	StdVarOverTimeType: withValues(stdvarOverTime),
+	ResetsType:         withValues(resets),
+	ChangesType:        withValues(changes),
+}
+
+// withValues wraps a function which only relies on the datapoint values
+func withValues(fn func(values []float64) float64) temporalFn {
+	return func(dps ts.Datapoints, _, _ time.Time) float64 {
+		if len(dps) == 0 {
+			return math.NaN()
+		}
+
+		values := make([]float64, len(dps))
+		for i, dp := range dps {
+			values[i] = dp.Value
+		}
+
+		return fn(values)
+	}
+}
+
+func avgOverTime(values []float64) float64 {
+	return sumOverTime(values) / float64(len(values))
+}
+
+func countOverTime(values []float64) float64 {
+	return float64(len(values))
+}
+
+func minOverTime(values []float64) float64 {
+	min := values[0]
+	for _, v := range values[1:] {
+		if v < min {
+			min = v
+		}
+	}
+
+	return min
+}
+
+func maxOverTime(values []float64) float64 {
+	max := values[0]
+	for _, v := range values[1:] {
+		if v > max {
+			max = v
+		}
+	}
+
+	return max
+}
+
+func sumOverTime(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+
+	return sum
+}
+
+func stdvarOverTime(values []float64) float64 {
+	var count, mean, variance float64
+	for _, v := range values {
+		count++
+		delta := v - mean
+		mean += delta / count
+		variance += delta * (v - mean)
+	}
+
+	return variance / count
+}
+
+func stddevOverTime(values []float64) float64 {
+	return math.Sqrt(stdvarOverTime(values))
+}
+
+func resets(values []float64) float64 {
+	count := 0.0
+	prev := values[0]
+	for _, v := range values[1:] {
+		if v < prev {
+			count++
+		}
+
+		prev = v
+	}
+
+	return count
+}
+
+func changes(values []float64) float64 {
+	count := 0.0
+	prev := values[0]
+	for _, v := range values[1:] {
+		if v != prev {
+			count++
+		}
+
+		prev = v
+	}
+
+	return count
+}
+
+// makeQuantileOverTimeFn creates a quantile function for the given q
+func makeQuantileOverTimeFn(q float64) temporalFn {
+	return withValues(func(values []float64) float64 {
+		return quantile(q, values)
+	})
+}
+
+// quantile calculates the q-quantile using linear interpolation between
+// the two closest ranks, matching the Prometheus implementation
+func quantile(q float64, values []float64) float64 {
+	if q < 0 {
+		return math.Inf(-1)
+	}
+
+	if q > 1 {
+		return math.Inf(1)
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	rank := q * float64(len(sorted)-1)
+	lowerIdx := math.Max(0, math.Floor(rank))
+	upperIdx := math.Min(float64(len(sorted)-1), lowerIdx+1)
+	weight := rank - lowerIdx
+	return sorted[int(lowerIdx)]*(1-weight) + sorted[int(upperIdx)]*weight
+}
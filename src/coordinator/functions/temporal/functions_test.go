@@ -0,0 +1,144 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package temporal
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/parser"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/test"
+	"github.com/m3db/m3db/src/coordinator/test/executor"
+	"github.com/m3db/m3db/src/coordinator/ts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverTimeFunctions(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		opType   string
+		values   []float64
+		expected []float64
+	}{
+		{SumOverTimeType, []float64{1, 2, 3, 4, 5}, []float64{3, 5, 7, 9}},
+		{CountOverTimeType, []float64{1, 2, nan, 4, 5}, []float64{2, 1, 1, 2}},
+		{AvgOverTimeType, []float64{1, 2, 3, 4, 5}, []float64{1.5, 2.5, 3.5, 4.5}},
+		{MinOverTimeType, []float64{5, 4, 3, 2, 1}, []float64{4, 3, 2, 1}},
+		{MaxOverTimeType, []float64{5, 4, 3, 2, 1}, []float64{5, 4, 3, 2}},
+		{StdVarOverTimeType, []float64{1, 3, 3, 7, 7}, []float64{1, 0, 4, 0}},
+		{StdDevOverTimeType, []float64{1, 3, 3, 7, 7}, []float64{1, 0, 2, 0}},
+		{ResetsType, []float64{3, 1, 2, 0, 0}, []float64{1, 0, 1, 0}},
+		{ChangesType, []float64{1, 1, 2, 2, 3}, []float64{0, 1, 0, 1}},
+		{SumOverTimeType, []float64{nan, nan, 3, nan, nan}, []float64{nan, 3, 3, nan}},
+	}
+
+	bounds := test.NewBounds(time.Now().Truncate(time.Hour), time.Minute, 5)
+	for _, tt := range tests {
+		t.Run(tt.opType, func(t *testing.T) {
+			op, err := NewTemporalOp(tt.opType, 2*time.Minute, 0)
+			require.NoError(t, err)
+
+			sink := processTemporalOp(t, op, bounds, tt.values)
+			test.EqualsWithNansWithDelta(t, [][]float64{tt.expected}, sink.Values, 1e-9)
+		})
+	}
+}
+
+func TestQuantileOverTime(t *testing.T) {
+	bounds := test.NewBounds(time.Now().Truncate(time.Hour), time.Minute, 4)
+	op, err := NewTemporalOp(QuantileOverTimeType, 4*time.Minute, 0.5)
+	require.NoError(t, err)
+
+	sink := processTemporalOp(t, op, bounds, []float64{4, 1, 3, 2})
+	test.EqualsWithNans(t, [][]float64{{2.5}}, sink.Values)
+}
+
+func TestTemporalDropsMetricName(t *testing.T) {
+	bounds := test.NewBounds(time.Now().Truncate(time.Hour), time.Minute, 2)
+	op, err := NewTemporalOp(RateType, 2*time.Minute, 0)
+	require.NoError(t, err)
+
+	sink := processTemporalOp(t, op, bounds, []float64{1, 2})
+	require.Len(t, sink.Metas, 1)
+	_, ok := sink.Metas[0].Tags[models.MetricName]
+	assert.False(t, ok)
+}
+
+func TestTemporalDropsHistorySteps(t *testing.T) {
+	bounds := test.NewBounds(time.Now().Truncate(time.Hour), time.Minute, 5)
+	op, err := NewTemporalOp(SumOverTimeType, 150*time.Second, 0)
+	require.NoError(t, err)
+
+	sink := processTemporalOp(t, op, bounds, []float64{1, 2, 3, 4, 5})
+	test.EqualsWithNans(t, [][]float64{{6, 9, 12}}, sink.Values)
+	assert.Equal(t, bounds.Start.Add(2*time.Minute), sink.Meta.Bounds.Start)
+	assert.Equal(t, bounds.End, sink.Meta.Bounds.End)
+	assert.Equal(t, 3, sink.Meta.Bounds.Steps())
+}
+
+func TestTemporalUnconsolidated(t *testing.T) {
+	// A counter increasing by one a second is evaluated over its raw
+	// datapoints, which the hourly steps would otherwise skip
+	start := time.Now().Truncate(time.Hour)
+	bounds := test.NewBounds(start, time.Hour, 2)
+	dps := datapoints(start.Add(-10*time.Minute), 15*time.Second, make([]float64, 281)...)
+	for i := range dps {
+		dps[i].Value = float64(15 * i)
+	}
+
+	block, err := storage.NewUnconsolidatedBlock([]*ts.Series{
+		ts.NewSeries("foo", dps, models.Tags{models.MetricName: "foo"}),
+	}, bounds, storage.NewConsolidationOptions())
+	require.NoError(t, err)
+
+	op, err := NewTemporalOp(RateType, 5*time.Minute, 0)
+	require.NoError(t, err)
+
+	c, sink := executor.NewControllerWithSink(parser.NodeID("1"))
+	require.NoError(t, op.(baseOp).Node(c).Process(parser.NodeID("0"), block))
+	test.EqualsWithNansWithDelta(t, [][]float64{{1, 1}}, sink.Values, 1e-9)
+}
+
+func TestInvalidTemporalOp(t *testing.T) {
+	_, err := NewTemporalOp("bogus", time.Minute, 0)
+	assert.Error(t, err)
+
+	_, err = NewTemporalOp(RateType, 0, 0)
+	assert.Error(t, err)
+}
+
+func processTemporalOp(
+	t *testing.T,
+	op parser.Params,
+	bounds storage.Bounds,
+	values []float64,
+) *executor.SinkNode {
+	block := test.NewBlockFromValues(bounds, [][]float64{values})
+	c, sink := executor.NewControllerWithSink(parser.NodeID("1"))
+	node := op.(baseOp).Node(c)
+	require.NoError(t, node.Process(parser.NodeID("0"), block))
+	return sink
+}
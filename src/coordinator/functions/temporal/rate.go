@@ -0,0 +1,165 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package temporal
+
+import (
+	"math"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/ts"
+)
+
+const (
+	// RateType calculates the per-second average rate of increase of a counter
+	RateType = "rate"
+	// IRateType calculates the per-second rate of increase of a counter based
+	// on the last two datapoints
+	IRateType = "irate"
+	// IncreaseType calculates the increase of a counter
+	IncreaseType = "increase"
+	// DeltaType calculates the difference between the first and last value of a gauge
+	DeltaType = "delta"
+	// IDeltaType calculates the difference between the last two values of a gauge
+	IDeltaType = "idelta"
+)
+
+var rateFunctions = map[string]temporalFn{
+	RateType: func(dps ts.Datapoints, windowStart, windowEnd time.Time) float64 {
+		return extrapolatedRate(dps, windowStart, windowEnd, true, true)
+	},
+	IncreaseType: func(dps ts.Datapoints, windowStart, windowEnd time.Time) float64 {
+		return extrapolatedRate(dps, windowStart, windowEnd, true, false)
+	},
+	DeltaType: func(dps ts.Datapoints, windowStart, windowEnd time.Time) float64 {
+		return extrapolatedRate(dps, windowStart, windowEnd, false, false)
+	},
+	IRateType: func(dps ts.Datapoints, _, _ time.Time) float64 {
+		return instantValue(dps, true)
+	},
+	IDeltaType: func(dps ts.Datapoints, _, _ time.Time) float64 {
+		return instantValue(dps, false)
+	},
+}
+
+// extrapolatedRate is a port of the Prometheus implementation which
+// extrapolates the result to the boundaries of the window, accounting for
+// counter resets if isCounter is set
+func extrapolatedRate(
+	dps ts.Datapoints,
+	windowStart, windowEnd time.Time,
+	isCounter, isRate bool,
+) float64 {
+	if len(dps) < 2 {
+		return math.NaN()
+	}
+
+	var (
+		counterCorrection float64
+		lastValue         float64
+	)
+
+	for _, dp := range dps {
+		if isCounter && dp.Value < lastValue {
+			counterCorrection += lastValue
+		}
+
+		lastValue = dp.Value
+	}
+
+	first, last := dps[0], dps[len(dps)-1]
+	resultValue := lastValue - first.Value + counterCorrection
+
+	// Duration between first/last samples and boundary of range
+	durationToStart := first.Timestamp.Sub(windowStart).Seconds()
+	durationToEnd := windowEnd.Sub(last.Timestamp).Seconds()
+
+	sampledInterval := last.Timestamp.Sub(first.Timestamp).Seconds()
+	averageDurationBetweenSamples := sampledInterval / float64(len(dps)-1)
+
+	if isCounter && resultValue > 0 && first.Value >= 0 {
+		// Counters cannot be negative. If we have any slope at all
+		// (i.e. resultValue went up), we can extrapolate the zero point
+		// of the counter. If the duration to the zero point is shorter
+		// than the durationToStart, we take the zero point as the start
+		// of the series, thereby avoiding extrapolation to negative
+		// counter values.
+		durationToZero := sampledInterval * (first.Value / resultValue)
+		if durationToZero < durationToStart {
+			durationToStart = durationToZero
+		}
+	}
+
+	// If the first/last samples are close to the boundaries of the range,
+	// extrapolate the result. This is as we expect that another sample
+	// will exist given the spacing between samples we've seen thus far,
+	// with an allowance for noise.
+	extrapolationThreshold := averageDurationBetweenSamples * 1.1
+	extrapolateToInterval := sampledInterval
+
+	if durationToStart < extrapolationThreshold {
+		extrapolateToInterval += durationToStart
+	} else {
+		extrapolateToInterval += averageDurationBetweenSamples / 2
+	}
+
+	if durationToEnd < extrapolationThreshold {
+		extrapolateToInterval += durationToEnd
+	} else {
+		extrapolateToInterval += averageDurationBetweenSamples / 2
+	}
+
+	resultValue = resultValue * (extrapolateToInterval / sampledInterval)
+	if isRate {
+		resultValue = resultValue / windowEnd.Sub(windowStart).Seconds()
+	}
+
+	return resultValue
+}
+
+// instantValue calculates the difference between the last two datapoints,
+// as a per-second rate accounting for counter resets if isRate is set
+func instantValue(dps ts.Datapoints, isRate bool) float64 {
+	if len(dps) < 2 {
+		return math.NaN()
+	}
+
+	last, previous := dps[len(dps)-1], dps[len(dps)-2]
+
+	var resultValue float64
+	if isRate && last.Value < previous.Value {
+		// Counter reset
+		resultValue = last.Value
+	} else {
+		resultValue = last.Value - previous.Value
+	}
+
+	if isRate {
+		sampledInterval := last.Timestamp.Sub(previous.Timestamp)
+		if sampledInterval == 0 {
+			// Avoid dividing by 0
+			return math.NaN()
+		}
+
+		resultValue /= sampledInterval.Seconds()
+	}
+
+	return resultValue
+}
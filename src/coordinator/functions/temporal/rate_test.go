@@ -0,0 +1,80 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package temporal
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/ts"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func datapoints(start time.Time, step time.Duration, values ...float64) ts.Datapoints {
+	dps := make(ts.Datapoints, len(values))
+	for i, v := range values {
+		dps[i] = ts.Datapoint{Timestamp: start.Add(time.Duration(i) * step), Value: v}
+	}
+
+	return dps
+}
+
+func TestExtrapolatedRate(t *testing.T) {
+	start := time.Now().Truncate(time.Hour)
+	end := start.Add(5 * time.Minute)
+	dps := datapoints(start.Add(time.Minute), time.Minute, 60, 120, 180, 240, 300)
+
+	assert.InDelta(t, 1.0, rateFunctions[RateType](dps, start, end), 1e-9)
+	assert.InDelta(t, 300.0, rateFunctions[IncreaseType](dps, start, end), 1e-9)
+	assert.InDelta(t, 300.0, rateFunctions[DeltaType](dps, start, end), 1e-9)
+}
+
+func TestExtrapolatedRateCounterReset(t *testing.T) {
+	start := time.Now().Truncate(time.Hour)
+	end := start.Add(5 * time.Minute)
+	dps := datapoints(start.Add(time.Minute), time.Minute, 60, 120, 30, 90)
+
+	assert.InDelta(t, 250.0, rateFunctions[IncreaseType](dps, start, end), 1e-9)
+	assert.InDelta(t, 250.0/300, rateFunctions[RateType](dps, start, end), 1e-9)
+	// Gauges do not account for resets
+	assert.InDelta(t, 50.0, rateFunctions[DeltaType](dps, start, end), 1e-9)
+}
+
+func TestExtrapolatedRateNotEnoughPoints(t *testing.T) {
+	start := time.Now().Truncate(time.Hour)
+	dps := datapoints(start, time.Minute, 60)
+	assert.True(t, math.IsNaN(rateFunctions[RateType](dps, start, start.Add(time.Minute))))
+	assert.True(t, math.IsNaN(rateFunctions[IRateType](dps, start, start.Add(time.Minute))))
+}
+
+func TestInstantValue(t *testing.T) {
+	start := time.Now().Truncate(time.Hour)
+
+	dps := datapoints(start, time.Minute, 10, 30, 90)
+	assert.InDelta(t, 1.0, rateFunctions[IRateType](dps, start, start), 1e-9)
+	assert.InDelta(t, 60.0, rateFunctions[IDeltaType](dps, start, start), 1e-9)
+
+	reset := datapoints(start, time.Minute, 120, 30)
+	assert.InDelta(t, 0.5, rateFunctions[IRateType](reset, start, start), 1e-9)
+	assert.InDelta(t, -90.0, rateFunctions[IDeltaType](reset, start, start), 1e-9)
+}
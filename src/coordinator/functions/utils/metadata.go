@@ -0,0 +1,69 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package utils
+
+import (
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/storage"
+)
+
+// FlattenMetadata merges the common block tags into each series' tags
+func FlattenMetadata(meta storage.BlockMetadata, seriesMeta []storage.SeriesMeta) []storage.SeriesMeta {
+	if len(meta.Tags) == 0 {
+		return seriesMeta
+	}
+
+	flattened := make([]storage.SeriesMeta, len(seriesMeta))
+	for i, m := range seriesMeta {
+		tags := m.Tags.Clone()
+		for k, v := range meta.Tags {
+			tags[k] = v
+		}
+
+		flattened[i] = storage.SeriesMeta{Name: m.Name, Tags: tags}
+	}
+
+	return flattened
+}
+
+// TagsWithKeys returns only the tags which have the given keys
+func TagsWithKeys(tags models.Tags, keys []string) models.Tags {
+	filtered := make(models.Tags, len(keys))
+	for _, k := range keys {
+		if v, ok := tags[k]; ok {
+			filtered[k] = v
+		}
+	}
+
+	return filtered
+}
+
+// TagsWithoutKeys returns the tags without the given keys, the metric name is
+// always dropped
+func TagsWithoutKeys(tags models.Tags, keys []string) models.Tags {
+	filtered := tags.Clone()
+	delete(filtered, models.MetricName)
+	for _, k := range keys {
+		delete(filtered, k)
+	}
+
+	return filtered
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlattenMetadata(t *testing.T) {
+	meta := storage.BlockMetadata{Tags: models.Tags{"a": "1"}}
+	seriesMetas := []storage.SeriesMeta{
+		{Name: "foo", Tags: models.Tags{"b": "1"}},
+		{Name: "bar", Tags: models.Tags{"b": "2"}},
+	}
+
+	flattened := FlattenMetadata(meta, seriesMetas)
+	assert.Equal(t, []storage.SeriesMeta{
+		{Name: "foo", Tags: models.Tags{"a": "1", "b": "1"}},
+		{Name: "bar", Tags: models.Tags{"a": "1", "b": "2"}},
+	}, flattened)
+	assert.Equal(t, models.Tags{"b": "1"}, seriesMetas[0].Tags, "original tags should not be mutated")
+}
+
+func TestTagsWithKeys(t *testing.T) {
+	tags := models.Tags{models.MetricName: "foo", "a": "1", "b": "2"}
+	assert.Equal(t, models.Tags{"a": "1"}, TagsWithKeys(tags, []string{"a", "c"}))
+	assert.Equal(t, models.Tags{"b": "2"}, TagsWithoutKeys(tags, []string{"a", "c"}))
+}
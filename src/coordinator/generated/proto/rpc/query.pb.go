@@ -45,6 +45,12 @@
 		CompressedTag
 		CompressedDatapoints
 		Series
+		ExecuteMessage
+		ExecuteQuery
+		ExecuteResult
+		Block
+		BlockSeries
+		Warning
 */
 package rpc
 
@@ -526,6 +532,214 @@ func (m *Series) GetCompressed() *CompressedDatapoints {
 	return nil
 }
 
+type ExecuteMessage struct {
+	Query   *ExecuteQuery `protobuf:"bytes,1,opt,name=query" json:"query,omitempty"`
+	Options *FetchOptions `protobuf:"bytes,2,opt,name=options" json:"options,omitempty"`
+}
+
+func (m *ExecuteMessage) Reset()                    { *m = ExecuteMessage{} }
+func (m *ExecuteMessage) String() string            { return proto.CompactTextString(m) }
+func (*ExecuteMessage) ProtoMessage()               {}
+func (*ExecuteMessage) Descriptor() ([]byte, []int) { return fileDescriptorQuery, []int{17} }
+
+func (m *ExecuteMessage) GetQuery() *ExecuteQuery {
+	if m != nil {
+		return m.Query
+	}
+	return nil
+}
+
+func (m *ExecuteMessage) GetOptions() *FetchOptions {
+	if m != nil {
+		return m.Options
+	}
+	return nil
+}
+
+type ExecuteQuery struct {
+	Query  string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	NodeID string `protobuf:"bytes,2,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
+	Start  int64  `protobuf:"varint,3,opt,name=start,proto3" json:"start,omitempty"`
+	End    int64  `protobuf:"varint,4,opt,name=end,proto3" json:"end,omitempty"`
+	Now    int64  `protobuf:"varint,5,opt,name=now,proto3" json:"now,omitempty"`
+	Step   int64  `protobuf:"varint,6,opt,name=step,proto3" json:"step,omitempty"`
+}
+
+func (m *ExecuteQuery) Reset()                    { *m = ExecuteQuery{} }
+func (m *ExecuteQuery) String() string            { return proto.CompactTextString(m) }
+func (*ExecuteQuery) ProtoMessage()               {}
+func (*ExecuteQuery) Descriptor() ([]byte, []int) { return fileDescriptorQuery, []int{18} }
+
+func (m *ExecuteQuery) GetQuery() string {
+	if m != nil {
+		return m.Query
+	}
+	return ""
+}
+
+func (m *ExecuteQuery) GetNodeID() string {
+	if m != nil {
+		return m.NodeID
+	}
+	return ""
+}
+
+func (m *ExecuteQuery) GetStart() int64 {
+	if m != nil {
+		return m.Start
+	}
+	return 0
+}
+
+func (m *ExecuteQuery) GetEnd() int64 {
+	if m != nil {
+		return m.End
+	}
+	return 0
+}
+
+func (m *ExecuteQuery) GetNow() int64 {
+	if m != nil {
+		return m.Now
+	}
+	return 0
+}
+
+func (m *ExecuteQuery) GetStep() int64 {
+	if m != nil {
+		return m.Step
+	}
+	return 0
+}
+
+type ExecuteResult struct {
+	Blocks   []*Block   `protobuf:"bytes,1,rep,name=blocks" json:"blocks,omitempty"`
+	Warnings []*Warning `protobuf:"bytes,2,rep,name=warnings" json:"warnings,omitempty"`
+}
+
+func (m *ExecuteResult) Reset()                    { *m = ExecuteResult{} }
+func (m *ExecuteResult) String() string            { return proto.CompactTextString(m) }
+func (*ExecuteResult) ProtoMessage()               {}
+func (*ExecuteResult) Descriptor() ([]byte, []int) { return fileDescriptorQuery, []int{19} }
+
+func (m *ExecuteResult) GetBlocks() []*Block {
+	if m != nil {
+		return m.Blocks
+	}
+	return nil
+}
+
+func (m *ExecuteResult) GetWarnings() []*Warning {
+	if m != nil {
+		return m.Warnings
+	}
+	return nil
+}
+
+type Block struct {
+	Start  int64             `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	End    int64             `protobuf:"varint,2,opt,name=end,proto3" json:"end,omitempty"`
+	Step   int64             `protobuf:"varint,3,opt,name=step,proto3" json:"step,omitempty"`
+	Tags   map[string]string `protobuf:"bytes,4,rep,name=tags" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Series []*BlockSeries    `protobuf:"bytes,5,rep,name=series" json:"series,omitempty"`
+}
+
+func (m *Block) Reset()                    { *m = Block{} }
+func (m *Block) String() string            { return proto.CompactTextString(m) }
+func (*Block) ProtoMessage()               {}
+func (*Block) Descriptor() ([]byte, []int) { return fileDescriptorQuery, []int{20} }
+
+func (m *Block) GetStart() int64 {
+	if m != nil {
+		return m.Start
+	}
+	return 0
+}
+
+func (m *Block) GetEnd() int64 {
+	if m != nil {
+		return m.End
+	}
+	return 0
+}
+
+func (m *Block) GetStep() int64 {
+	if m != nil {
+		return m.Step
+	}
+	return 0
+}
+
+func (m *Block) GetTags() map[string]string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *Block) GetSeries() []*BlockSeries {
+	if m != nil {
+		return m.Series
+	}
+	return nil
+}
+
+type BlockSeries struct {
+	Name   string            `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Tags   map[string]string `protobuf:"bytes,2,rep,name=tags" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Values []float64         `protobuf:"fixed64,3,rep,packed,name=values" json:"values,omitempty"`
+}
+
+func (m *BlockSeries) Reset()                    { *m = BlockSeries{} }
+func (m *BlockSeries) String() string            { return proto.CompactTextString(m) }
+func (*BlockSeries) ProtoMessage()               {}
+func (*BlockSeries) Descriptor() ([]byte, []int) { return fileDescriptorQuery, []int{21} }
+
+func (m *BlockSeries) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *BlockSeries) GetTags() map[string]string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *BlockSeries) GetValues() []float64 {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type Warning struct {
+	Name    string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (m *Warning) Reset()                    { *m = Warning{} }
+func (m *Warning) String() string            { return proto.CompactTextString(m) }
+func (*Warning) ProtoMessage()               {}
+func (*Warning) Descriptor() ([]byte, []int) { return fileDescriptorQuery, []int{22} }
+
+func (m *Warning) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Warning) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*WriteMessage)(nil), "rpc.WriteMessage")
 	proto.RegisterType((*WriteQuery)(nil), "rpc.WriteQuery")
@@ -544,6 +758,12 @@ func init() {
 	proto.RegisterType((*CompressedTag)(nil), "rpc.CompressedTag")
 	proto.RegisterType((*CompressedDatapoints)(nil), "rpc.CompressedDatapoints")
 	proto.RegisterType((*Series)(nil), "rpc.Series")
+	proto.RegisterType((*ExecuteMessage)(nil), "rpc.ExecuteMessage")
+	proto.RegisterType((*ExecuteQuery)(nil), "rpc.ExecuteQuery")
+	proto.RegisterType((*ExecuteResult)(nil), "rpc.ExecuteResult")
+	proto.RegisterType((*Block)(nil), "rpc.Block")
+	proto.RegisterType((*BlockSeries)(nil), "rpc.BlockSeries")
+	proto.RegisterType((*Warning)(nil), "rpc.Warning")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -559,6 +779,7 @@ const _ = grpc.SupportPackageIsVersion4
 type QueryClient interface {
 	Fetch(ctx context.Context, in *FetchMessage, opts ...grpc.CallOption) (Query_FetchClient, error)
 	Write(ctx context.Context, opts ...grpc.CallOption) (Query_WriteClient, error)
+	Execute(ctx context.Context, in *ExecuteMessage, opts ...grpc.CallOption) (Query_ExecuteClient, error)
 }
 
 type queryClient struct {
@@ -635,11 +856,44 @@ func (x *queryWriteClient) CloseAndRecv() (*Error, error) {
 	return m, nil
 }
 
+func (c *queryClient) Execute(ctx context.Context, in *ExecuteMessage, opts ...grpc.CallOption) (Query_ExecuteClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Query_serviceDesc.Streams[2], c.cc, "/rpc.Query/Execute", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &queryExecuteClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Query_ExecuteClient interface {
+	Recv() (*ExecuteResult, error)
+	grpc.ClientStream
+}
+
+type queryExecuteClient struct {
+	grpc.ClientStream
+}
+
+func (x *queryExecuteClient) Recv() (*ExecuteResult, error) {
+	m := new(ExecuteResult)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Server API for Query service
 
 type QueryServer interface {
 	Fetch(*FetchMessage, Query_FetchServer) error
 	Write(Query_WriteServer) error
+	Execute(*ExecuteMessage, Query_ExecuteServer) error
 }
 
 func RegisterQueryServer(s *grpc.Server, srv QueryServer) {
@@ -693,6 +947,27 @@ func (x *queryWriteServer) Recv() (*WriteMessage, error) {
 	return m, nil
 }
 
+func _Query_Execute_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExecuteMessage)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(QueryServer).Execute(m, &queryExecuteServer{stream})
+}
+
+type Query_ExecuteServer interface {
+	Send(*ExecuteResult) error
+	grpc.ServerStream
+}
+
+type queryExecuteServer struct {
+	grpc.ServerStream
+}
+
+func (x *queryExecuteServer) Send(m *ExecuteResult) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _Query_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "rpc.Query",
 	HandlerType: (*QueryServer)(nil),
@@ -708,6 +983,11 @@ var _Query_serviceDesc = grpc.ServiceDesc{
 			Handler:       _Query_Write_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "Execute",
+			Handler:       _Query_Execute_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "github.com/m3db/m3db/src/coordinator/generated/proto/rpc/query.proto",
 }
@@ -1351,57 +1631,330 @@ func (m *Series) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
-func encodeVarintQuery(dAtA []byte, offset int, v uint64) int {
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+func (m *ExecuteMessage) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	dAtA[offset] = uint8(v)
-	return offset + 1
+	return dAtA[:n], nil
 }
-func (m *WriteMessage) Size() (n int) {
+
+func (m *ExecuteMessage) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
 	if m.Query != nil {
-		l = m.Query.Size()
-		n += 1 + l + sovQuery(uint64(l))
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(m.Query.Size()))
+		n8, err := m.Query.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n8
 	}
 	if m.Options != nil {
-		l = m.Options.Size()
-		n += 1 + l + sovQuery(uint64(l))
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(m.Options.Size()))
+		n9, err := m.Options.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n9
 	}
-	return n
+	return i, nil
 }
 
-func (m *WriteQuery) Size() (n int) {
+func (m *ExecuteQuery) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ExecuteQuery) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	if m.Unit != 0 {
-		n += 1 + sovQuery(uint64(m.Unit))
+	if len(m.Query) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Query)))
+		i += copy(dAtA[i:], m.Query)
 	}
-	l = len(m.Annotation)
-	if l > 0 {
-		n += 1 + l + sovQuery(uint64(l))
+	if len(m.NodeID) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.NodeID)))
+		i += copy(dAtA[i:], m.NodeID)
 	}
-	if len(m.Datapoints) > 0 {
-		for _, e := range m.Datapoints {
-			l = e.Size()
-			n += 1 + l + sovQuery(uint64(l))
-		}
+	if m.Start != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(m.Start))
 	}
-	if len(m.Tags) > 0 {
-		for k, v := range m.Tags {
-			_ = k
-			_ = v
-			mapEntrySize := 1 + len(k) + sovQuery(uint64(len(k))) + 1 + len(v) + sovQuery(uint64(len(v)))
-			n += mapEntrySize + 1 + sovQuery(uint64(mapEntrySize))
-		}
+	if m.End != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(m.End))
 	}
-	return n
+	if m.Now != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(m.Now))
+	}
+	if m.Step != 0 {
+		dAtA[i] = 0x30
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(m.Step))
+	}
+	return i, nil
 }
 
-func (m *WriteOptions) Size() (n int) {
+func (m *ExecuteResult) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ExecuteResult) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Blocks) > 0 {
+		for _, msg := range m.Blocks {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintQuery(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.Warnings) > 0 {
+		for _, msg := range m.Warnings {
+			dAtA[i] = 0x12
+			i++
+			i = encodeVarintQuery(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *Block) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Block) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Start != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(m.Start))
+	}
+	if m.End != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(m.End))
+	}
+	if m.Step != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(m.Step))
+	}
+	if len(m.Tags) > 0 {
+		for k, _ := range m.Tags {
+			dAtA[i] = 0x22
+			i++
+			v := m.Tags[k]
+			mapSize := 1 + len(k) + sovQuery(uint64(len(k))) + 1 + len(v) + sovQuery(uint64(len(v)))
+			i = encodeVarintQuery(dAtA, i, uint64(mapSize))
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintQuery(dAtA, i, uint64(len(k)))
+			i += copy(dAtA[i:], k)
+			dAtA[i] = 0x12
+			i++
+			i = encodeVarintQuery(dAtA, i, uint64(len(v)))
+			i += copy(dAtA[i:], v)
+		}
+	}
+	if len(m.Series) > 0 {
+		for _, msg := range m.Series {
+			dAtA[i] = 0x2a
+			i++
+			i = encodeVarintQuery(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *BlockSeries) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *BlockSeries) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Name) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Name)))
+		i += copy(dAtA[i:], m.Name)
+	}
+	if len(m.Tags) > 0 {
+		for k, _ := range m.Tags {
+			dAtA[i] = 0x12
+			i++
+			v := m.Tags[k]
+			mapSize := 1 + len(k) + sovQuery(uint64(len(k))) + 1 + len(v) + sovQuery(uint64(len(v)))
+			i = encodeVarintQuery(dAtA, i, uint64(mapSize))
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintQuery(dAtA, i, uint64(len(k)))
+			i += copy(dAtA[i:], k)
+			dAtA[i] = 0x12
+			i++
+			i = encodeVarintQuery(dAtA, i, uint64(len(v)))
+			i += copy(dAtA[i:], v)
+		}
+	}
+	if len(m.Values) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Values)*8))
+		for _, num := range m.Values {
+			f10 := math.Float64bits(float64(num))
+			binary.LittleEndian.PutUint64(dAtA[i:], uint64(f10))
+			i += 8
+		}
+	}
+	return i, nil
+}
+
+func (m *Warning) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Warning) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Name) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Name)))
+		i += copy(dAtA[i:], m.Name)
+	}
+	if len(m.Message) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintQuery(dAtA, i, uint64(len(m.Message)))
+		i += copy(dAtA[i:], m.Message)
+	}
+	return i, nil
+}
+
+func encodeVarintQuery(dAtA []byte, offset int, v uint64) int {
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return offset + 1
+}
+func (m *WriteMessage) Size() (n int) {
+	var l int
+	_ = l
+	if m.Query != nil {
+		l = m.Query.Size()
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	if m.Options != nil {
+		l = m.Options.Size()
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	return n
+}
+
+func (m *WriteQuery) Size() (n int) {
+	var l int
+	_ = l
+	if m.Unit != 0 {
+		n += 1 + sovQuery(uint64(m.Unit))
+	}
+	l = len(m.Annotation)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	if len(m.Datapoints) > 0 {
+		for _, e := range m.Datapoints {
+			l = e.Size()
+			n += 1 + l + sovQuery(uint64(l))
+		}
+	}
+	if len(m.Tags) > 0 {
+		for k, v := range m.Tags {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovQuery(uint64(len(k))) + 1 + len(v) + sovQuery(uint64(len(v)))
+			n += mapEntrySize + 1 + sovQuery(uint64(mapEntrySize))
+		}
+	}
+	return n
+}
+
+func (m *WriteOptions) Size() (n int) {
 	var l int
 	_ = l
 	l = len(m.Id)
@@ -1628,27 +2181,853 @@ func (m *Series) Size() (n int) {
 			n += mapEntrySize + 1 + sovQuery(uint64(mapEntrySize))
 		}
 	}
-	if m.Compressed != nil {
-		l = m.Compressed.Size()
-		n += 1 + l + sovQuery(uint64(l))
-	}
-	return n
-}
+	if m.Compressed != nil {
+		l = m.Compressed.Size()
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	return n
+}
+
+func (m *ExecuteMessage) Size() (n int) {
+	var l int
+	_ = l
+	if m.Query != nil {
+		l = m.Query.Size()
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	if m.Options != nil {
+		l = m.Options.Size()
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	return n
+}
+
+func (m *ExecuteQuery) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Query)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	l = len(m.NodeID)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	if m.Start != 0 {
+		n += 1 + sovQuery(uint64(m.Start))
+	}
+	if m.End != 0 {
+		n += 1 + sovQuery(uint64(m.End))
+	}
+	if m.Now != 0 {
+		n += 1 + sovQuery(uint64(m.Now))
+	}
+	if m.Step != 0 {
+		n += 1 + sovQuery(uint64(m.Step))
+	}
+	return n
+}
+
+func (m *ExecuteResult) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Blocks) > 0 {
+		for _, e := range m.Blocks {
+			l = e.Size()
+			n += 1 + l + sovQuery(uint64(l))
+		}
+	}
+	if len(m.Warnings) > 0 {
+		for _, e := range m.Warnings {
+			l = e.Size()
+			n += 1 + l + sovQuery(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *Block) Size() (n int) {
+	var l int
+	_ = l
+	if m.Start != 0 {
+		n += 1 + sovQuery(uint64(m.Start))
+	}
+	if m.End != 0 {
+		n += 1 + sovQuery(uint64(m.End))
+	}
+	if m.Step != 0 {
+		n += 1 + sovQuery(uint64(m.Step))
+	}
+	if len(m.Tags) > 0 {
+		for k, v := range m.Tags {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovQuery(uint64(len(k))) + 1 + len(v) + sovQuery(uint64(len(v)))
+			n += mapEntrySize + 1 + sovQuery(uint64(mapEntrySize))
+		}
+	}
+	if len(m.Series) > 0 {
+		for _, e := range m.Series {
+			l = e.Size()
+			n += 1 + l + sovQuery(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *BlockSeries) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	if len(m.Tags) > 0 {
+		for k, v := range m.Tags {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovQuery(uint64(len(k))) + 1 + len(v) + sovQuery(uint64(len(v)))
+			n += mapEntrySize + 1 + sovQuery(uint64(mapEntrySize))
+		}
+	}
+	if len(m.Values) > 0 {
+		n += 1 + sovQuery(uint64(len(m.Values)*8)) + len(m.Values)*8
+	}
+	return n
+}
+
+func (m *Warning) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	l = len(m.Message)
+	if l > 0 {
+		n += 1 + l + sovQuery(uint64(l))
+	}
+	return n
+}
+
+func sovQuery(x uint64) (n int) {
+	for {
+		n++
+		x >>= 7
+		if x == 0 {
+			break
+		}
+	}
+	return n
+}
+func sozQuery(x uint64) (n int) {
+	return sovQuery(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *WriteMessage) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: WriteMessage: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: WriteMessage: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Query", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Query == nil {
+				m.Query = &WriteQuery{}
+			}
+			if err := m.Query.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Options", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Options == nil {
+				m.Options = &WriteOptions{}
+			}
+			if err := m.Options.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *WriteQuery) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: WriteQuery: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: WriteQuery: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Unit", wireType)
+			}
+			m.Unit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Unit |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Annotation", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Annotation = append(m.Annotation[:0], dAtA[iNdEx:postIndex]...)
+			if m.Annotation == nil {
+				m.Annotation = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Datapoints", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Datapoints = append(m.Datapoints, &Datapoint{})
+			if err := m.Datapoints[len(m.Datapoints)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Tags", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Tags == nil {
+				m.Tags = make(map[string]string)
+			}
+			var mapkey string
+			var mapvalue string
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowQuery
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowQuery
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= (uint64(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthQuery
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var stringLenmapvalue uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowQuery
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapvalue |= (uint64(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapvalue := int(stringLenmapvalue)
+					if intStringLenmapvalue < 0 {
+						return ErrInvalidLengthQuery
+					}
+					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+					if postStringIndexmapvalue > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
+					iNdEx = postStringIndexmapvalue
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipQuery(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthQuery
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Tags[mapkey] = mapvalue
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *WriteOptions) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: WriteOptions: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: WriteOptions: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Id = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Datapoint) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Datapoint: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Datapoint: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", wireType)
+			}
+			m.Timestamp = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Timestamp |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.Value = float64(math.Float64frombits(v))
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Datapoints) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Datapoints: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Datapoints: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Datapoints", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Datapoints = append(m.Datapoints, &Datapoint{})
+			if err := m.Datapoints[len(m.Datapoints)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FixedResolution", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.FixedResolution = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Error) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Error: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Error: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Error = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
 
-func sovQuery(x uint64) (n int) {
-	for {
-		n++
-		x >>= 7
-		if x == 0 {
-			break
-		}
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
 	}
-	return n
-}
-func sozQuery(x uint64) (n int) {
-	return sovQuery(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+	return nil
 }
-func (m *WriteMessage) Unmarshal(dAtA []byte) error {
+func (m *FetchMessage) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1671,10 +3050,10 @@ func (m *WriteMessage) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: WriteMessage: wiretype end group for non-group")
+			return fmt.Errorf("proto: FetchMessage: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: WriteMessage: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: FetchMessage: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -1704,7 +3083,7 @@ func (m *WriteMessage) Unmarshal(dAtA []byte) error {
 				return io.ErrUnexpectedEOF
 			}
 			if m.Query == nil {
-				m.Query = &WriteQuery{}
+				m.Query = &FetchQuery{}
 			}
 			if err := m.Query.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
@@ -1737,7 +3116,7 @@ func (m *WriteMessage) Unmarshal(dAtA []byte) error {
 				return io.ErrUnexpectedEOF
 			}
 			if m.Options == nil {
-				m.Options = &WriteOptions{}
+				m.Options = &FetchOptions{}
 			}
 			if err := m.Options.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
@@ -1764,7 +3143,7 @@ func (m *WriteMessage) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *WriteQuery) Unmarshal(dAtA []byte) error {
+func (m *FetchQuery) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1787,17 +3166,17 @@ func (m *WriteQuery) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: WriteQuery: wiretype end group for non-group")
+			return fmt.Errorf("proto: FetchQuery: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: WriteQuery: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: FetchQuery: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Unit", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Start", wireType)
 			}
-			m.Unit = 0
+			m.Start = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -1807,16 +3186,16 @@ func (m *WriteQuery) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Unit |= (int32(b) & 0x7F) << shift
+				m.Start |= (int64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
 		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Annotation", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field End", wireType)
 			}
-			var byteLen int
+			m.End = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -1826,26 +3205,14 @@ func (m *WriteQuery) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= (int(b) & 0x7F) << shift
+				m.End |= (int64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
-				return ErrInvalidLengthQuery
-			}
-			postIndex := iNdEx + byteLen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Annotation = append(m.Annotation[:0], dAtA[iNdEx:postIndex]...)
-			if m.Annotation == nil {
-				m.Annotation = []byte{}
-			}
-			iNdEx = postIndex
 		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Datapoints", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field TagMatchers", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -1869,129 +3236,11 @@ func (m *WriteQuery) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Datapoints = append(m.Datapoints, &Datapoint{})
-			if err := m.Datapoints[len(m.Datapoints)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			m.TagMatchers = append(m.TagMatchers, &Matcher{})
+			if err := m.TagMatchers[len(m.TagMatchers)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 4:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Tags", wireType)
-			}
-			var msglen int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowQuery
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			if msglen < 0 {
-				return ErrInvalidLengthQuery
-			}
-			postIndex := iNdEx + msglen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			if m.Tags == nil {
-				m.Tags = make(map[string]string)
-			}
-			var mapkey string
-			var mapvalue string
-			for iNdEx < postIndex {
-				entryPreIndex := iNdEx
-				var wire uint64
-				for shift := uint(0); ; shift += 7 {
-					if shift >= 64 {
-						return ErrIntOverflowQuery
-					}
-					if iNdEx >= l {
-						return io.ErrUnexpectedEOF
-					}
-					b := dAtA[iNdEx]
-					iNdEx++
-					wire |= (uint64(b) & 0x7F) << shift
-					if b < 0x80 {
-						break
-					}
-				}
-				fieldNum := int32(wire >> 3)
-				if fieldNum == 1 {
-					var stringLenmapkey uint64
-					for shift := uint(0); ; shift += 7 {
-						if shift >= 64 {
-							return ErrIntOverflowQuery
-						}
-						if iNdEx >= l {
-							return io.ErrUnexpectedEOF
-						}
-						b := dAtA[iNdEx]
-						iNdEx++
-						stringLenmapkey |= (uint64(b) & 0x7F) << shift
-						if b < 0x80 {
-							break
-						}
-					}
-					intStringLenmapkey := int(stringLenmapkey)
-					if intStringLenmapkey < 0 {
-						return ErrInvalidLengthQuery
-					}
-					postStringIndexmapkey := iNdEx + intStringLenmapkey
-					if postStringIndexmapkey > l {
-						return io.ErrUnexpectedEOF
-					}
-					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
-					iNdEx = postStringIndexmapkey
-				} else if fieldNum == 2 {
-					var stringLenmapvalue uint64
-					for shift := uint(0); ; shift += 7 {
-						if shift >= 64 {
-							return ErrIntOverflowQuery
-						}
-						if iNdEx >= l {
-							return io.ErrUnexpectedEOF
-						}
-						b := dAtA[iNdEx]
-						iNdEx++
-						stringLenmapvalue |= (uint64(b) & 0x7F) << shift
-						if b < 0x80 {
-							break
-						}
-					}
-					intStringLenmapvalue := int(stringLenmapvalue)
-					if intStringLenmapvalue < 0 {
-						return ErrInvalidLengthQuery
-					}
-					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
-					if postStringIndexmapvalue > l {
-						return io.ErrUnexpectedEOF
-					}
-					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
-					iNdEx = postStringIndexmapvalue
-				} else {
-					iNdEx = entryPreIndex
-					skippy, err := skipQuery(dAtA[iNdEx:])
-					if err != nil {
-						return err
-					}
-					if skippy < 0 {
-						return ErrInvalidLengthQuery
-					}
-					if (iNdEx + skippy) > postIndex {
-						return io.ErrUnexpectedEOF
-					}
-					iNdEx += skippy
-				}
-			}
-			m.Tags[mapkey] = mapvalue
-			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQuery(dAtA[iNdEx:])
@@ -2013,7 +3262,7 @@ func (m *WriteQuery) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *WriteOptions) Unmarshal(dAtA []byte) error {
+func (m *FetchOptions) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2036,10 +3285,10 @@ func (m *WriteOptions) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: WriteOptions: wiretype end group for non-group")
+			return fmt.Errorf("proto: FetchOptions: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: WriteOptions: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: FetchOptions: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -2092,7 +3341,7 @@ func (m *WriteOptions) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Datapoint) Unmarshal(dAtA []byte) error {
+func (m *Matcher) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2115,17 +3364,17 @@ func (m *Datapoint) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Datapoint: wiretype end group for non-group")
+			return fmt.Errorf("proto: Matcher: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Datapoint: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Matcher: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
 			}
-			m.Timestamp = 0
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -2135,22 +3384,69 @@ func (m *Datapoint) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Timestamp |= (int64(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		case 2:
-			if wireType != 1 {
+			if wireType != 2 {
 				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
 			}
-			var v uint64
-			if (iNdEx + 8) > l {
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			v = uint64(binary.LittleEndian.Uint64(dAtA[iNdEx:]))
-			iNdEx += 8
-			m.Value = float64(math.Float64frombits(v))
+			m.Value = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			}
+			m.Type = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Type |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQuery(dAtA[iNdEx:])
@@ -2172,7 +3468,7 @@ func (m *Datapoint) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Datapoints) Unmarshal(dAtA []byte) error {
+func (m *FetchResult) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2195,15 +3491,15 @@ func (m *Datapoints) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Datapoints: wiretype end group for non-group")
+			return fmt.Errorf("proto: FetchResult: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Datapoints: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: FetchResult: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Datapoints", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Series", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -2227,31 +3523,11 @@ func (m *Datapoints) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Datapoints = append(m.Datapoints, &Datapoint{})
-			if err := m.Datapoints[len(m.Datapoints)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			m.Series = append(m.Series, &Series{})
+			if err := m.Series[len(m.Series)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 2:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field FixedResolution", wireType)
-			}
-			var v int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowQuery
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				v |= (int(b) & 0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			m.FixedResolution = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQuery(dAtA[iNdEx:])
@@ -2273,7 +3549,7 @@ func (m *Datapoints) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Error) Unmarshal(dAtA []byte) error {
+func (m *Segment) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2296,17 +3572,17 @@ func (m *Error) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Error: wiretype end group for non-group")
+			return fmt.Errorf("proto: Segment: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Error: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Segment: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Head", wireType)
 			}
-			var stringLen uint64
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -2316,21 +3592,92 @@ func (m *Error) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				byteLen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthQuery
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + byteLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Error = string(dAtA[iNdEx:postIndex])
+			m.Head = append(m.Head[:0], dAtA[iNdEx:postIndex]...)
+			if m.Head == nil {
+				m.Head = []byte{}
+			}
 			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Tail", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Tail = append(m.Tail[:0], dAtA[iNdEx:postIndex]...)
+			if m.Tail == nil {
+				m.Tail = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StartTime", wireType)
+			}
+			m.StartTime = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.StartTime |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BlockSize", wireType)
+			}
+			m.BlockSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BlockSize |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQuery(dAtA[iNdEx:])
@@ -2352,7 +3699,7 @@ func (m *Error) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *FetchMessage) Unmarshal(dAtA []byte) error {
+func (m *Segments) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2375,15 +3722,15 @@ func (m *FetchMessage) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: FetchMessage: wiretype end group for non-group")
+			return fmt.Errorf("proto: Segments: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: FetchMessage: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Segments: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Query", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Merged", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -2407,16 +3754,16 @@ func (m *FetchMessage) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Query == nil {
-				m.Query = &FetchQuery{}
+			if m.Merged == nil {
+				m.Merged = &Segment{}
 			}
-			if err := m.Query.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if err := m.Merged.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Options", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Unmerged", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -2440,10 +3787,8 @@ func (m *FetchMessage) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Options == nil {
-				m.Options = &FetchOptions{}
-			}
-			if err := m.Options.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			m.Unmerged = append(m.Unmerged, &Segment{})
+			if err := m.Unmerged[len(m.Unmerged)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -2468,7 +3813,7 @@ func (m *FetchMessage) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *FetchQuery) Unmarshal(dAtA []byte) error {
+func (m *CompressedValuesReplica) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2491,53 +3836,15 @@ func (m *FetchQuery) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: FetchQuery: wiretype end group for non-group")
+			return fmt.Errorf("proto: CompressedValuesReplica: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: FetchQuery: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: CompressedValuesReplica: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Start", wireType)
-			}
-			m.Start = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowQuery
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.Start |= (int64(b) & 0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-		case 2:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field End", wireType)
-			}
-			m.End = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowQuery
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.End |= (int64(b) & 0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TagMatchers", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Segments", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -2561,8 +3868,8 @@ func (m *FetchQuery) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.TagMatchers = append(m.TagMatchers, &Matcher{})
-			if err := m.TagMatchers[len(m.TagMatchers)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			m.Segments = append(m.Segments, &Segments{})
+			if err := m.Segments[len(m.Segments)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -2587,7 +3894,7 @@ func (m *FetchQuery) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *FetchOptions) Unmarshal(dAtA []byte) error {
+func (m *CompressedTag) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2610,17 +3917,17 @@ func (m *FetchOptions) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: FetchOptions: wiretype end group for non-group")
+			return fmt.Errorf("proto: CompressedTag: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: FetchOptions: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: CompressedTag: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
 			}
-			var stringLen uint64
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -2630,20 +3937,53 @@ func (m *FetchOptions) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				byteLen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthQuery
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = append(m.Name[:0], dAtA[iNdEx:postIndex]...)
+			if m.Name == nil {
+				m.Name = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + byteLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Id = string(dAtA[iNdEx:postIndex])
+			m.Value = append(m.Value[:0], dAtA[iNdEx:postIndex]...)
+			if m.Value == nil {
+				m.Value = []byte{}
+			}
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -2666,7 +4006,7 @@ func (m *FetchOptions) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Matcher) Unmarshal(dAtA []byte) error {
+func (m *CompressedDatapoints) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2689,17 +4029,17 @@ func (m *Matcher) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Matcher: wiretype end group for non-group")
+			return fmt.Errorf("proto: CompressedDatapoints: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Matcher: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: CompressedDatapoints: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Namespace", wireType)
 			}
-			var stringLen uint64
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -2709,26 +4049,28 @@ func (m *Matcher) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				byteLen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthQuery
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + byteLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Name = string(dAtA[iNdEx:postIndex])
+			m.Namespace = append(m.Namespace[:0], dAtA[iNdEx:postIndex]...)
+			if m.Namespace == nil {
+				m.Namespace = []byte{}
+			}
 			iNdEx = postIndex
 		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StartTime", wireType)
 			}
-			var stringLen uint64
+			m.StartTime = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -2738,26 +4080,16 @@ func (m *Matcher) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				m.StartTime |= (int64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthQuery
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Value = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
 		case 3:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field EndTime", wireType)
 			}
-			m.Type = 0
+			m.EndTime = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -2767,64 +4099,45 @@ func (m *Matcher) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Type |= (int64(b) & 0x7F) << shift
+				m.EndTime |= (int64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		default:
-			iNdEx = preIndex
-			skippy, err := skipQuery(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if skippy < 0 {
-				return ErrInvalidLengthQuery
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Tags", wireType)
 			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *FetchResult) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowQuery
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
 			}
-			if iNdEx >= l {
+			postIndex := iNdEx + msglen
+			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= (uint64(b) & 0x7F) << shift
-			if b < 0x80 {
-				break
+			m.Tags = append(m.Tags, &CompressedTag{})
+			if err := m.Tags[len(m.Tags)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: FetchResult: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: FetchResult: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
+			iNdEx = postIndex
+		case 5:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Series", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Replicas", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -2848,8 +4161,8 @@ func (m *FetchResult) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Series = append(m.Series, &Series{})
-			if err := m.Series[len(m.Series)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			m.Replicas = append(m.Replicas, &CompressedValuesReplica{})
+			if err := m.Replicas[len(m.Replicas)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -2874,7 +4187,7 @@ func (m *FetchResult) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Segment) Unmarshal(dAtA []byte) error {
+func (m *Series) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2897,15 +4210,15 @@ func (m *Segment) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Segment: wiretype end group for non-group")
+			return fmt.Errorf("proto: Series: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Segment: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Series: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Head", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
 			}
 			var byteLen int
 			for shift := uint(0); ; shift += 7 {
@@ -2929,16 +4242,16 @@ func (m *Segment) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Head = append(m.Head[:0], dAtA[iNdEx:postIndex]...)
-			if m.Head == nil {
-				m.Head = []byte{}
+			m.Id = append(m.Id[:0], dAtA[iNdEx:postIndex]...)
+			if m.Id == nil {
+				m.Id = []byte{}
 			}
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Tail", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Values", wireType)
 			}
-			var byteLen int
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -2948,28 +4261,30 @@ func (m *Segment) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= (int(b) & 0x7F) << shift
+				msglen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthQuery
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + msglen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Tail = append(m.Tail[:0], dAtA[iNdEx:postIndex]...)
-			if m.Tail == nil {
-				m.Tail = []byte{}
+			if m.Values == nil {
+				m.Values = &Datapoints{}
+			}
+			if err := m.Values.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
 			iNdEx = postIndex
 		case 3:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field StartTime", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Tags", wireType)
 			}
-			m.StartTime = 0
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -2979,16 +4294,115 @@ func (m *Segment) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.StartTime |= (int64(b) & 0x7F) << shift
+				msglen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Tags == nil {
+				m.Tags = make(map[string]string)
+			}
+			var mapkey string
+			var mapvalue string
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowQuery
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowQuery
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= (uint64(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthQuery
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var stringLenmapvalue uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowQuery
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapvalue |= (uint64(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapvalue := int(stringLenmapvalue)
+					if intStringLenmapvalue < 0 {
+						return ErrInvalidLengthQuery
+					}
+					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+					if postStringIndexmapvalue > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
+					iNdEx = postStringIndexmapvalue
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipQuery(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthQuery
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Tags[mapkey] = mapvalue
+			iNdEx = postIndex
 		case 4:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field BlockSize", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Compressed", wireType)
 			}
-			m.BlockSize = 0
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -2998,11 +4412,25 @@ func (m *Segment) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.BlockSize |= (int64(b) & 0x7F) << shift
+				msglen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Compressed == nil {
+				m.Compressed = &CompressedDatapoints{}
+			}
+			if err := m.Compressed.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQuery(dAtA[iNdEx:])
@@ -3024,7 +4452,7 @@ func (m *Segment) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Segments) Unmarshal(dAtA []byte) error {
+func (m *ExecuteMessage) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3047,15 +4475,15 @@ func (m *Segments) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Segments: wiretype end group for non-group")
+			return fmt.Errorf("proto: ExecuteMessage: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Segments: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ExecuteMessage: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Merged", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Query", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -3079,16 +4507,16 @@ func (m *Segments) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Merged == nil {
-				m.Merged = &Segment{}
+			if m.Query == nil {
+				m.Query = &ExecuteQuery{}
 			}
-			if err := m.Merged.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if err := m.Query.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Unmerged", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Options", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -3112,8 +4540,10 @@ func (m *Segments) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Unmerged = append(m.Unmerged, &Segment{})
-			if err := m.Unmerged[len(m.Unmerged)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if m.Options == nil {
+				m.Options = &FetchOptions{}
+			}
+			if err := m.Options.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -3138,7 +4568,7 @@ func (m *Segments) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *CompressedValuesReplica) Unmarshal(dAtA []byte) error {
+func (m *ExecuteQuery) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3161,17 +4591,17 @@ func (m *CompressedValuesReplica) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: CompressedValuesReplica: wiretype end group for non-group")
+			return fmt.Errorf("proto: ExecuteQuery: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: CompressedValuesReplica: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ExecuteQuery: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Segments", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Query", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -3181,23 +4611,126 @@ func (m *CompressedValuesReplica) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthQuery
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Segments = append(m.Segments, &Segments{})
-			if err := m.Segments[len(m.Segments)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
+			m.Query = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NodeID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
 			}
+			m.NodeID = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Start", wireType)
+			}
+			m.Start = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Start |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field End", wireType)
+			}
+			m.End = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.End |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Now", wireType)
+			}
+			m.Now = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Now |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Step", wireType)
+			}
+			m.Step = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Step |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQuery(dAtA[iNdEx:])
@@ -3219,7 +4752,7 @@ func (m *CompressedValuesReplica) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *CompressedTag) Unmarshal(dAtA []byte) error {
+func (m *ExecuteResult) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3242,17 +4775,17 @@ func (m *CompressedTag) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: CompressedTag: wiretype end group for non-group")
+			return fmt.Errorf("proto: ExecuteResult: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: CompressedTag: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ExecuteResult: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Blocks", wireType)
 			}
-			var byteLen int
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -3262,28 +4795,28 @@ func (m *CompressedTag) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= (int(b) & 0x7F) << shift
+				msglen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthQuery
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + msglen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Name = append(m.Name[:0], dAtA[iNdEx:postIndex]...)
-			if m.Name == nil {
-				m.Name = []byte{}
+			m.Blocks = append(m.Blocks, &Block{})
+			if err := m.Blocks[len(m.Blocks)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Warnings", wireType)
 			}
-			var byteLen int
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -3293,21 +4826,21 @@ func (m *CompressedTag) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= (int(b) & 0x7F) << shift
+				msglen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthQuery
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + msglen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Value = append(m.Value[:0], dAtA[iNdEx:postIndex]...)
-			if m.Value == nil {
-				m.Value = []byte{}
+			m.Warnings = append(m.Warnings, &Warning{})
+			if err := m.Warnings[len(m.Warnings)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
 			iNdEx = postIndex
 		default:
@@ -3331,7 +4864,7 @@ func (m *CompressedTag) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *CompressedDatapoints) Unmarshal(dAtA []byte) error {
+func (m *Block) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3354,17 +4887,17 @@ func (m *CompressedDatapoints) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: CompressedDatapoints: wiretype end group for non-group")
+			return fmt.Errorf("proto: Block: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: CompressedDatapoints: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Block: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Namespace", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Start", wireType)
 			}
-			var byteLen int
+			m.Start = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -3374,28 +4907,16 @@ func (m *CompressedDatapoints) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= (int(b) & 0x7F) << shift
+				m.Start |= (int64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
-				return ErrInvalidLengthQuery
-			}
-			postIndex := iNdEx + byteLen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Namespace = append(m.Namespace[:0], dAtA[iNdEx:postIndex]...)
-			if m.Namespace == nil {
-				m.Namespace = []byte{}
-			}
-			iNdEx = postIndex
 		case 2:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field StartTime", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field End", wireType)
 			}
-			m.StartTime = 0
+			m.End = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -3405,16 +4926,16 @@ func (m *CompressedDatapoints) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.StartTime |= (int64(b) & 0x7F) << shift
+				m.End |= (int64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
 		case 3:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field EndTime", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Step", wireType)
 			}
-			m.EndTime = 0
+			m.Step = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -3424,7 +4945,7 @@ func (m *CompressedDatapoints) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.EndTime |= (int64(b) & 0x7F) << shift
+				m.Step |= (int64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
@@ -3441,28 +4962,115 @@ func (m *CompressedDatapoints) Unmarshal(dAtA []byte) error {
 				if iNdEx >= l {
 					return io.ErrUnexpectedEOF
 				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
-				if b < 0x80 {
-					break
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Tags == nil {
+				m.Tags = make(map[string]string)
+			}
+			var mapkey string
+			var mapvalue string
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowQuery
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowQuery
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= (uint64(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthQuery
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var stringLenmapvalue uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowQuery
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapvalue |= (uint64(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapvalue := int(stringLenmapvalue)
+					if intStringLenmapvalue < 0 {
+						return ErrInvalidLengthQuery
+					}
+					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+					if postStringIndexmapvalue > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
+					iNdEx = postStringIndexmapvalue
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipQuery(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthQuery
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
 				}
 			}
-			if msglen < 0 {
-				return ErrInvalidLengthQuery
-			}
-			postIndex := iNdEx + msglen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Tags = append(m.Tags, &CompressedTag{})
-			if err := m.Tags[len(m.Tags)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Tags[mapkey] = mapvalue
 			iNdEx = postIndex
 		case 5:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Replicas", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Series", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -3486,8 +5094,8 @@ func (m *CompressedDatapoints) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Replicas = append(m.Replicas, &CompressedValuesReplica{})
-			if err := m.Replicas[len(m.Replicas)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			m.Series = append(m.Series, &BlockSeries{})
+			if err := m.Series[len(m.Series)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -3512,7 +5120,7 @@ func (m *CompressedDatapoints) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Series) Unmarshal(dAtA []byte) error {
+func (m *BlockSeries) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3535,17 +5143,17 @@ func (m *Series) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Series: wiretype end group for non-group")
+			return fmt.Errorf("proto: BlockSeries: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Series: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: BlockSeries: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
 			}
-			var byteLen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -3555,57 +5163,22 @@ func (m *Series) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= (int(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthQuery
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + intStringLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Id = append(m.Id[:0], dAtA[iNdEx:postIndex]...)
-			if m.Id == nil {
-				m.Id = []byte{}
-			}
+			m.Name = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Values", wireType)
-			}
-			var msglen int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowQuery
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			if msglen < 0 {
-				return ErrInvalidLengthQuery
-			}
-			postIndex := iNdEx + msglen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			if m.Values == nil {
-				m.Values = &Datapoints{}
-			}
-			if err := m.Values.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
-			iNdEx = postIndex
-		case 3:
 			if wireType != 2 {
 				return fmt.Errorf("proto: wrong wireType = %d for field Tags", wireType)
 			}
@@ -3723,11 +5296,107 @@ func (m *Series) Unmarshal(dAtA []byte) error {
 			}
 			m.Tags[mapkey] = mapvalue
 			iNdEx = postIndex
-		case 4:
+		case 3:
+			if wireType == 1 {
+				var v uint64
+				if (iNdEx + 8) > l {
+					return io.ErrUnexpectedEOF
+				}
+				v = uint64(binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+				iNdEx += 8
+				v2 := float64(math.Float64frombits(v))
+				m.Values = append(m.Values, v2)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowQuery
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= (int(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthQuery
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				for iNdEx < postIndex {
+					var v uint64
+					if (iNdEx + 8) > l {
+						return io.ErrUnexpectedEOF
+					}
+					v = uint64(binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+					iNdEx += 8
+					v2 := float64(math.Float64frombits(v))
+					m.Values = append(m.Values, v2)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Values", wireType)
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Warning) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Warning: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Warning: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Compressed", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowQuery
@@ -3737,24 +5406,49 @@ func (m *Series) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthQuery
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Compressed == nil {
-				m.Compressed = &CompressedDatapoints{}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
 			}
-			if err := m.Compressed.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQuery
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
 			}
+			m.Message = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -3777,6 +5471,7 @@ func (m *Series) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+
 func skipQuery(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
@@ -3887,56 +5582,69 @@ func init() {
 }
 
 var fileDescriptorQuery = []byte{
-	// 805 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x55, 0x4b, 0x6f, 0xe3, 0x36,
-	0x10, 0xae, 0x6c, 0xcb, 0x8f, 0xb1, 0xf2, 0x28, 0x91, 0xa2, 0x6a, 0x90, 0x1a, 0x81, 0xfa, 0x72,
-	0x50, 0x54, 0x2e, 0x9c, 0x43, 0x93, 0x5e, 0x0a, 0xb4, 0x4e, 0x7b, 0x0a, 0x8a, 0x32, 0x41, 0x0b,
-	0xf4, 0x46, 0x4b, 0x8c, 0x2c, 0xc4, 0x7a, 0x94, 0xa4, 0x8b, 0xa6, 0xbf, 0x62, 0x7f, 0xd6, 0x9e,
-	0x16, 0x7b, 0xdc, 0x63, 0x90, 0xfd, 0x23, 0x0b, 0x8e, 0xa8, 0x97, 0x13, 0xec, 0x2e, 0xf6, 0x22,
-	0x0c, 0x67, 0x3e, 0xce, 0x0c, 0xbf, 0x79, 0x08, 0x16, 0x51, 0xac, 0x56, 0x9b, 0xa5, 0x1f, 0x64,
-	0xc9, 0x2c, 0x39, 0x0d, 0x97, 0xc5, 0x47, 0x8a, 0x60, 0x16, 0x64, 0x99, 0x08, 0xe3, 0x94, 0xa9,
-	0x4c, 0xcc, 0x22, 0x9e, 0x72, 0xc1, 0x14, 0x0f, 0x67, 0xb9, 0xc8, 0x54, 0x36, 0x13, 0x79, 0x30,
-	0xfb, 0x67, 0xc3, 0xc5, 0x9d, 0x8f, 0x67, 0xd2, 0x15, 0x79, 0xe0, 0x2d, 0xc1, 0xf9, 0x4b, 0xc4,
-	0x8a, 0x5f, 0x72, 0x29, 0x59, 0xc4, 0xc9, 0x57, 0x60, 0x23, 0xc6, 0xb5, 0x8e, 0xad, 0xe9, 0x78,
-	0xbe, 0xe7, 0x8b, 0x3c, 0xf0, 0x11, 0xf1, 0x87, 0x56, 0xd3, 0xc2, 0x4a, 0xbe, 0x85, 0x41, 0x96,
-	0xab, 0x38, 0x4b, 0xa5, 0xdb, 0x41, 0xe0, 0xc7, 0x35, 0xf0, 0xf7, 0xc2, 0x40, 0x4b, 0x84, 0xf7,
-	0xca, 0x02, 0xa8, 0x5d, 0x10, 0x02, 0xbd, 0x4d, 0x1a, 0x2b, 0x8c, 0x60, 0x53, 0x94, 0xc9, 0x04,
-	0x80, 0xa5, 0x69, 0xa6, 0x98, 0xbe, 0x81, 0x2e, 0x1d, 0xda, 0xd0, 0x10, 0x1f, 0x20, 0x64, 0x8a,
-	0xe5, 0x59, 0x9c, 0x2a, 0xe9, 0x76, 0x8f, 0xbb, 0xd3, 0xf1, 0x7c, 0x17, 0x43, 0x2e, 0x4a, 0x35,
-	0x6d, 0x20, 0xc8, 0x77, 0xd0, 0x53, 0x2c, 0x92, 0x6e, 0x0f, 0x91, 0x9f, 0x6d, 0xbd, 0xc2, 0xbf,
-	0x66, 0x91, 0xbc, 0x48, 0x95, 0xb8, 0xa3, 0x08, 0x3b, 0xfc, 0x01, 0x46, 0x95, 0x8a, 0xec, 0x43,
-	0xf7, 0x96, 0x17, 0x04, 0x8c, 0xa8, 0x16, 0xc9, 0x01, 0xd8, 0xff, 0xb2, 0xf5, 0x86, 0x63, 0x62,
-	0x23, 0x5a, 0x1c, 0x7e, 0xec, 0x9c, 0x59, 0xde, 0xc4, 0xd0, 0x67, 0xde, 0x4c, 0x76, 0xa1, 0x13,
-	0x87, 0xe6, 0x6a, 0x27, 0x0e, 0xbd, 0x9f, 0x60, 0x54, 0x25, 0x48, 0x8e, 0x60, 0xa4, 0xe2, 0x84,
-	0x4b, 0xc5, 0x92, 0x1c, 0x31, 0x5d, 0x5a, 0x2b, 0xda, 0x41, 0x2c, 0x13, 0xc4, 0xbb, 0x01, 0x58,
-	0xd4, 0xcf, 0x6a, 0xd3, 0x60, 0xbd, 0x93, 0x86, 0x29, 0xec, 0xdd, 0xc4, 0xff, 0xf1, 0x90, 0x72,
-	0x99, 0xad, 0x37, 0x15, 0xb7, 0x43, 0xba, 0xad, 0xf6, 0x3e, 0x07, 0xfb, 0x42, 0x88, 0x4c, 0xe8,
-	0x34, 0xb8, 0x16, 0xcc, 0x23, 0x8a, 0x83, 0x6e, 0x93, 0x5f, 0xb9, 0x0a, 0x56, 0x6f, 0x6d, 0x13,
-	0x44, 0xbc, 0x4f, 0x9b, 0x20, 0xf0, 0x51, 0x9b, 0x84, 0x00, 0xb5, 0x07, 0x9d, 0x87, 0x54, 0x4c,
-	0x28, 0x43, 0x54, 0x71, 0xd0, 0xb5, 0xe1, 0x69, 0x88, 0xce, 0xba, 0x54, 0x8b, 0xc4, 0x87, 0xb1,
-	0x62, 0xd1, 0x25, 0x53, 0xc1, 0x8a, 0x8b, 0xb2, 0x35, 0x1c, 0x0c, 0x63, 0x94, 0xb4, 0x09, 0xd0,
-	0x15, 0x6b, 0x86, 0x7f, 0x54, 0xb1, 0xdf, 0x60, 0x60, 0xb0, 0xba, 0x51, 0x53, 0x96, 0x70, 0x63,
-	0x44, 0xf9, 0xe9, 0x56, 0xd0, 0x48, 0x75, 0x97, 0x73, 0xb7, 0x8b, 0x79, 0xa1, 0xec, 0xcd, 0x61,
-	0x8c, 0x81, 0x28, 0x97, 0x9b, 0xb5, 0x22, 0x5f, 0x40, 0x5f, 0x72, 0x11, 0xf3, 0xb2, 0x6c, 0x63,
-	0x4c, 0xf1, 0x0a, 0x55, 0xd4, 0x98, 0xbc, 0x04, 0x06, 0x57, 0x3c, 0x4a, 0x78, 0xaa, 0xb4, 0xcb,
-	0x15, 0x67, 0x45, 0x66, 0x0e, 0x45, 0x19, 0xc3, 0xb0, 0x78, 0x6d, 0xe6, 0x03, 0x65, 0xdd, 0x54,
-	0x48, 0xcd, 0x75, 0x9c, 0x94, 0xf1, 0x6b, 0x85, 0xb6, 0x2e, 0xd7, 0x59, 0x70, 0x7b, 0x15, 0xff,
-	0xcf, 0xdd, 0x5e, 0x61, 0xad, 0x14, 0xde, 0xdf, 0x30, 0x34, 0xe1, 0x24, 0xf9, 0x12, 0xfa, 0x09,
-	0x17, 0x11, 0x0f, 0x4d, 0x49, 0x1d, 0x93, 0x1f, 0x9a, 0xa9, 0xb1, 0x91, 0x29, 0x0c, 0x37, 0xa9,
-	0xc1, 0x75, 0x1a, 0x54, 0x97, 0xb8, 0xca, 0xea, 0x2d, 0xe0, 0xd3, 0x5f, 0xb2, 0x24, 0x17, 0x5c,
-	0x4a, 0x1e, 0xfe, 0xa9, 0x59, 0x92, 0x94, 0xe7, 0xeb, 0x38, 0x60, 0xe4, 0x04, 0x86, 0xd2, 0x84,
-	0x35, 0x64, 0xec, 0x34, 0x9d, 0x48, 0x5a, 0x99, 0xbd, 0x73, 0xd8, 0xa9, 0xbd, 0x5c, 0xb3, 0xa8,
-	0x55, 0x13, 0xe7, 0xa9, 0x9a, 0x38, 0xe5, 0xe4, 0xbc, 0xb0, 0xe0, 0xa0, 0xbe, 0xdb, 0x18, 0xa2,
-	0x23, 0x18, 0xe9, 0x6b, 0x32, 0x67, 0x41, 0xe9, 0xa7, 0x56, 0xb4, 0xf9, 0xec, 0x6c, 0xf3, 0xe9,
-	0xc2, 0x80, 0xa7, 0x61, 0x83, 0xeb, 0xf2, 0x48, 0xbe, 0x6e, 0x6d, 0x1c, 0x82, 0x0f, 0x6a, 0xa5,
-	0x5e, 0xac, 0x1a, 0x72, 0x06, 0x43, 0x51, 0xf0, 0x20, 0x5d, 0x1b, 0xb1, 0x47, 0x5b, 0xd8, 0x16,
-	0x59, 0xb4, 0x42, 0x7b, 0xf7, 0x16, 0xf4, 0x8b, 0x7e, 0x69, 0x34, 0xad, 0xa3, 0x9b, 0x96, 0x7c,
-	0x03, 0x7d, 0x7c, 0x74, 0x39, 0x66, 0x7b, 0xed, 0x9d, 0x20, 0xa9, 0x31, 0x93, 0x13, 0x93, 0x65,
-	0x31, 0x26, 0x9f, 0x34, 0x7a, 0x70, 0x7b, 0x27, 0x92, 0x73, 0x80, 0xa0, 0xca, 0x09, 0x7b, 0xa7,
-	0x5c, 0xa4, 0x4f, 0xb1, 0x4a, 0x1b, 0xe0, 0x0f, 0x5e, 0xa7, 0x73, 0x06, 0x76, 0x31, 0xfd, 0x3e,
-	0xd8, 0x38, 0x3c, 0xa4, 0xb1, 0x30, 0xcc, 0xee, 0x39, 0xdc, 0xaf, 0x55, 0xc5, 0x6c, 0x7d, 0x6f,
-	0x91, 0x29, 0xd8, 0xb8, 0x87, 0x49, 0xe3, 0x3f, 0x54, 0xe2, 0x01, 0x55, 0xb8, 0xdd, 0xa6, 0xd6,
-	0xcf, 0xfb, 0xcf, 0x1f, 0x26, 0xd6, 0xcb, 0x87, 0x89, 0x75, 0xff, 0x30, 0xb1, 0x9e, 0xbd, 0x9e,
-	0x7c, 0xb4, 0xec, 0xe3, 0xef, 0xf0, 0xf4, 0x4d, 0x00, 0x00, 0x00, 0xff, 0xff, 0xef, 0x9e, 0x99,
-	0x58, 0x56, 0x07, 0x00, 0x00,
+	// 1015 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x56, 0xcd, 0x6e, 0x23, 0x45,
+	0x10, 0x66, 0x3c, 0x19, 0x3b, 0x2e, 0xcf, 0x26, 0xa1, 0x09, 0xcb, 0x10, 0x05, 0x2b, 0x1a, 0x7e,
+	0xd6, 0x2b, 0x84, 0x8d, 0xb2, 0x48, 0xbb, 0xcb, 0x05, 0x69, 0x49, 0x40, 0x1c, 0x56, 0x88, 0x4e,
+	0xc4, 0x4a, 0x48, 0x1c, 0xda, 0x33, 0x9d, 0xc9, 0x68, 0x3d, 0x3f, 0x74, 0xb7, 0xc9, 0x86, 0x47,
+	0xe0, 0xc4, 0x8d, 0x3b, 0x4f, 0xc3, 0x09, 0xed, 0x91, 0xe3, 0x2a, 0xbc, 0x08, 0xea, 0xea, 0x9e,
+	0x3f, 0xdb, 0x82, 0x55, 0x2e, 0x56, 0x77, 0xd5, 0xd7, 0x55, 0x5f, 0x57, 0x7f, 0x55, 0x1e, 0x38,
+	0x49, 0x52, 0x75, 0xb9, 0x9c, 0x4f, 0xa3, 0x22, 0x9b, 0x65, 0x0f, 0xe2, 0xb9, 0xf9, 0x91, 0x22,
+	0x9a, 0x45, 0x45, 0x21, 0xe2, 0x34, 0x67, 0xaa, 0x10, 0xb3, 0x84, 0xe7, 0x5c, 0x30, 0xc5, 0xe3,
+	0x59, 0x29, 0x0a, 0x55, 0xcc, 0x44, 0x19, 0xcd, 0x7e, 0x5a, 0x72, 0x71, 0x3d, 0xc5, 0x3d, 0x71,
+	0x45, 0x19, 0x85, 0x73, 0xf0, 0x9f, 0x89, 0x54, 0xf1, 0xa7, 0x5c, 0x4a, 0x96, 0x70, 0xf2, 0x21,
+	0x78, 0x88, 0x09, 0x9c, 0x23, 0x67, 0x32, 0x3a, 0xde, 0x9d, 0x8a, 0x32, 0x9a, 0x22, 0xe2, 0x3b,
+	0x6d, 0xa6, 0xc6, 0x4b, 0x3e, 0x86, 0x41, 0x51, 0xaa, 0xb4, 0xc8, 0x65, 0xd0, 0x43, 0xe0, 0x9b,
+	0x0d, 0xf0, 0x5b, 0xe3, 0xa0, 0x15, 0x22, 0xfc, 0xdb, 0x01, 0x68, 0x42, 0x10, 0x02, 0x5b, 0xcb,
+	0x3c, 0x55, 0x98, 0xc1, 0xa3, 0xb8, 0x26, 0x63, 0x00, 0x96, 0xe7, 0x85, 0x62, 0xfa, 0x04, 0x86,
+	0xf4, 0x69, 0xcb, 0x42, 0xa6, 0x00, 0x31, 0x53, 0xac, 0x2c, 0xd2, 0x5c, 0xc9, 0xc0, 0x3d, 0x72,
+	0x27, 0xa3, 0xe3, 0x1d, 0x4c, 0x79, 0x52, 0x99, 0x69, 0x0b, 0x41, 0x3e, 0x81, 0x2d, 0xc5, 0x12,
+	0x19, 0x6c, 0x21, 0xf2, 0xdd, 0x95, 0x5b, 0x4c, 0xcf, 0x59, 0x22, 0x4f, 0x73, 0x25, 0xae, 0x29,
+	0xc2, 0x0e, 0x1e, 0xc2, 0xb0, 0x36, 0x91, 0x3d, 0x70, 0x9f, 0x73, 0x53, 0x80, 0x21, 0xd5, 0x4b,
+	0xb2, 0x0f, 0xde, 0xcf, 0x6c, 0xb1, 0xe4, 0x48, 0x6c, 0x48, 0xcd, 0xe6, 0xf3, 0xde, 0x23, 0x27,
+	0x1c, 0x83, 0xdf, 0xbe, 0x33, 0xd9, 0x81, 0x5e, 0x1a, 0xdb, 0xa3, 0xbd, 0x34, 0x0e, 0xbf, 0x80,
+	0x61, 0x4d, 0x90, 0x1c, 0xc2, 0x50, 0xa5, 0x19, 0x97, 0x8a, 0x65, 0x25, 0x62, 0x5c, 0xda, 0x18,
+	0xba, 0x49, 0x1c, 0x9b, 0x24, 0xbc, 0x00, 0x38, 0x69, 0xae, 0xd5, 0x2d, 0x83, 0xf3, 0xbf, 0x65,
+	0x98, 0xc0, 0xee, 0x45, 0xfa, 0x82, 0xc7, 0x94, 0xcb, 0x62, 0xb1, 0xac, 0x6b, 0xbb, 0x4d, 0x57,
+	0xcd, 0xe1, 0x7b, 0xe0, 0x9d, 0x0a, 0x51, 0x08, 0x4d, 0x83, 0xeb, 0x85, 0xbd, 0x84, 0xd9, 0x68,
+	0x99, 0x7c, 0xc5, 0x55, 0x74, 0xf9, 0x9f, 0x32, 0x41, 0xc4, 0xeb, 0xc8, 0x04, 0x81, 0x6b, 0x32,
+	0x89, 0x01, 0x9a, 0x08, 0x9a, 0x87, 0x54, 0x4c, 0x28, 0x5b, 0x28, 0xb3, 0xd1, 0x6f, 0xc3, 0xf3,
+	0x18, 0x83, 0xb9, 0x54, 0x2f, 0xc9, 0x14, 0x46, 0x8a, 0x25, 0x4f, 0x99, 0x8a, 0x2e, 0xb9, 0xa8,
+	0xa4, 0xe1, 0x63, 0x1a, 0x6b, 0xa4, 0x6d, 0x80, 0x7e, 0xb1, 0x76, 0xfa, 0xb5, 0x17, 0xfb, 0x1a,
+	0x06, 0x16, 0xab, 0x85, 0x9a, 0xb3, 0x8c, 0x5b, 0x27, 0xae, 0x37, 0x4b, 0x41, 0x23, 0xd5, 0x75,
+	0xc9, 0x03, 0x17, 0x79, 0xe1, 0x3a, 0x3c, 0x86, 0x11, 0x26, 0xa2, 0x5c, 0x2e, 0x17, 0x8a, 0xbc,
+	0x0f, 0x7d, 0xc9, 0x45, 0xca, 0xab, 0x67, 0x1b, 0x21, 0xc5, 0x33, 0x34, 0x51, 0xeb, 0x0a, 0x33,
+	0x18, 0x9c, 0xf1, 0x24, 0xe3, 0xb9, 0xd2, 0x21, 0x2f, 0x39, 0x33, 0xcc, 0x7c, 0x8a, 0x6b, 0x4c,
+	0xc3, 0xd2, 0x85, 0xed, 0x0f, 0x5c, 0x6b, 0x51, 0x61, 0x69, 0xce, 0xd3, 0xac, 0xca, 0xdf, 0x18,
+	0xb4, 0x77, 0xbe, 0x28, 0xa2, 0xe7, 0x67, 0xe9, 0x2f, 0x3c, 0xd8, 0x32, 0xde, 0xda, 0x10, 0xfe,
+	0x00, 0xdb, 0x36, 0x9d, 0x24, 0x1f, 0x40, 0x3f, 0xe3, 0x22, 0xe1, 0xb1, 0x7d, 0x52, 0xdf, 0xf2,
+	0x43, 0x37, 0xb5, 0x3e, 0x32, 0x81, 0xed, 0x65, 0x6e, 0x71, 0xbd, 0x23, 0x77, 0x0d, 0x57, 0x7b,
+	0xc3, 0x13, 0x78, 0xe7, 0xcb, 0x22, 0x2b, 0x05, 0x97, 0x92, 0xc7, 0xdf, 0xeb, 0x2a, 0x49, 0xca,
+	0xcb, 0x45, 0x1a, 0x31, 0x72, 0x1f, 0xb6, 0xa5, 0x4d, 0x6b, 0x8b, 0x71, 0xa7, 0x1d, 0x44, 0xd2,
+	0xda, 0x1d, 0x3e, 0x86, 0x3b, 0x4d, 0x94, 0x73, 0x96, 0x74, 0xde, 0xc4, 0xdf, 0xf4, 0x26, 0x7e,
+	0xd5, 0x39, 0x7f, 0x39, 0xb0, 0xdf, 0x9c, 0x6d, 0x35, 0xd1, 0x21, 0x0c, 0xf5, 0x31, 0x59, 0xb2,
+	0xa8, 0x8a, 0xd3, 0x18, 0xba, 0xf5, 0xec, 0xad, 0xd6, 0x33, 0x80, 0x01, 0xcf, 0xe3, 0x56, 0xad,
+	0xab, 0x2d, 0xf9, 0xa8, 0x33, 0x71, 0x08, 0x5e, 0xa8, 0x43, 0xdd, 0x8c, 0x1a, 0xf2, 0x08, 0xb6,
+	0x85, 0xa9, 0x83, 0x0c, 0x3c, 0xc4, 0x1e, 0xae, 0x60, 0x3b, 0xc5, 0xa2, 0x35, 0x3a, 0x7c, 0xe5,
+	0x40, 0xdf, 0xe8, 0xa5, 0x25, 0x5a, 0x5f, 0x8b, 0x96, 0xdc, 0x83, 0x3e, 0x5e, 0xba, 0x6a, 0xb3,
+	0xdd, 0xee, 0x4c, 0x90, 0xd4, 0xba, 0xc9, 0x7d, 0xcb, 0xd2, 0xb4, 0xc9, 0xdb, 0x2d, 0x0d, 0xae,
+	0xce, 0x44, 0xf2, 0x18, 0x20, 0xaa, 0x39, 0xa1, 0x76, 0xaa, 0x41, 0xba, 0xa9, 0xaa, 0xb4, 0x05,
+	0xbe, 0xfd, 0x38, 0xbd, 0x80, 0x9d, 0xd3, 0x17, 0x3c, 0x5a, 0x36, 0xff, 0x47, 0xf7, 0xba, 0x83,
+	0xc6, 0xcc, 0x0f, 0x8b, 0xb9, 0xfd, 0xa8, 0xf9, 0xd5, 0x01, 0xbf, 0x1d, 0x44, 0x53, 0x6a, 0xd2,
+	0x0c, 0xab, 0x98, 0x77, 0xa1, 0x9f, 0x17, 0x31, 0xff, 0xe6, 0xc4, 0x32, 0xb5, 0xbb, 0x66, 0x36,
+	0xb9, 0x1b, 0x66, 0xd3, 0x56, 0x33, 0x9b, 0xf6, 0xc0, 0xcd, 0x8b, 0xab, 0xc0, 0x33, 0x96, 0xbc,
+	0xb8, 0xd2, 0xf2, 0x95, 0x8a, 0x97, 0x41, 0x1f, 0x4d, 0xb8, 0x0e, 0x7f, 0x84, 0x3b, 0x96, 0x8b,
+	0x1d, 0x15, 0x21, 0xf4, 0xb1, 0x47, 0xab, 0xee, 0x00, 0xbc, 0xc9, 0x13, 0x6d, 0xa2, 0xd6, 0xa3,
+	0x1b, 0xf1, 0x8a, 0x89, 0x3c, 0xcd, 0x13, 0xd9, 0x69, 0xc4, 0x67, 0xc6, 0x48, 0x6b, 0x6f, 0xf8,
+	0xd2, 0x01, 0x0f, 0xcf, 0xbe, 0xf6, 0x48, 0xad, 0x48, 0xba, 0x0d, 0x49, 0x32, 0xe9, 0xc8, 0x7b,
+	0xbf, 0x61, 0xb4, 0xa6, 0x9b, 0x49, 0x3d, 0xe8, 0x8c, 0xbc, 0xf7, 0x1a, 0x6c, 0x77, 0xda, 0xdd,
+	0x5e, 0x26, 0x7f, 0x38, 0x30, 0x6a, 0x05, 0xdc, 0x38, 0xa8, 0xa7, 0x96, 0xb0, 0x29, 0xce, 0xc1,
+	0x2a, 0x89, 0x35, 0xda, 0x77, 0xeb, 0x16, 0xd2, 0xbd, 0xe1, 0x54, 0x1d, 0x73, 0x7b, 0x92, 0x0f,
+	0x61, 0x60, 0x1f, 0x63, 0x23, 0xbf, 0x00, 0x06, 0x99, 0xd1, 0xb8, 0x3d, 0x5a, 0x6d, 0x8f, 0x7f,
+	0x77, 0xc0, 0x33, 0xaa, 0x9c, 0x82, 0x87, 0xfa, 0x25, 0x2d, 0x2d, 0xdb, 0xc6, 0x38, 0xd8, 0x6b,
+	0x4c, 0x46, 0x36, 0x9f, 0x3a, 0x64, 0x02, 0x1e, 0x7e, 0x8d, 0x90, 0xd6, 0xd7, 0x58, 0x85, 0x37,
+	0x22, 0xc2, 0xff, 0xf8, 0x89, 0x43, 0x3e, 0x83, 0x81, 0xd5, 0x1c, 0x79, 0xab, 0xdd, 0x52, 0x15,
+	0x9a, 0xb4, 0x8d, 0x55, 0xfc, 0x27, 0x7b, 0x7f, 0xde, 0x8c, 0x9d, 0x97, 0x37, 0x63, 0xe7, 0xd5,
+	0xcd, 0xd8, 0xf9, 0xed, 0x9f, 0xf1, 0x1b, 0xf3, 0x3e, 0x7e, 0x4a, 0x3e, 0xf8, 0x77, 0x00, 0x6e,
+	0xc1, 0xb8, 0x52, 0x92, 0x0a, 0x00, 0x00,
 }
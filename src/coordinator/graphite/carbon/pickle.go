@@ -0,0 +1,611 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package carbon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxPickleMessageSize is the largest pickle message accepted, carbon
+	// senders batch at most a few thousand datapoints per message
+	maxPickleMessageSize = 1 << 20
+
+	pickleHeaderSize = 4
+)
+
+var (
+	errPickleMessageTooLarge = fmt.Errorf("carbon pickle message larger than %d bytes", maxPickleMessageSize)
+	errPickleTruncated       = errors.New("truncated carbon pickle")
+	errPickleStackUnderflow  = errors.New("carbon pickle stack underflow")
+	errPickleNoMark          = errors.New("carbon pickle missing mark")
+)
+
+// ReadPickleMessage reads a message of the carbon pickle protocol, which is
+// prefixed by its length as a 4 byte big endian integer
+func ReadPickleMessage(r io.Reader) ([]byte, error) {
+	var header [pickleHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header[:])
+	if size > maxPickleMessageSize {
+		return nil, errPickleMessageTooLarge
+	}
+
+	message := make([]byte, size)
+	if _, err := io.ReadFull(r, message); err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+// ParsePickle parses the metrics of a carbon pickle message, which is a
+// pickled list of (path, (timestamp, value)) tuples. Negative timestamps are
+// replaced by now.
+func ParsePickle(message []byte, now time.Time) ([]Metric, error) {
+	value, err := unpickle(message)
+	if err != nil {
+		return nil, err
+	}
+
+	items, ok := pickleSequence(value)
+	if !ok {
+		return nil, fmt.Errorf("carbon pickle is not a list of metrics, got %T", value)
+	}
+
+	metrics := make([]Metric, 0, len(items))
+	for _, item := range items {
+		metric, err := pickleMetric(item, now)
+		if err != nil {
+			return nil, err
+		}
+
+		metrics = append(metrics, metric)
+	}
+
+	return metrics, nil
+}
+
+func pickleMetric(item interface{}, now time.Time) (Metric, error) {
+	fields, ok := pickleSequence(item)
+	if !ok || len(fields) != 2 {
+		return Metric{}, fmt.Errorf("invalid carbon pickle metric %v", item)
+	}
+
+	path, ok := fields[0].(string)
+	if !ok {
+		return Metric{}, fmt.Errorf("invalid carbon pickle path %v", fields[0])
+	}
+
+	datapoint, ok := pickleSequence(fields[1])
+	if !ok || len(datapoint) != 2 {
+		return Metric{}, fmt.Errorf("invalid carbon pickle datapoint %v", fields[1])
+	}
+
+	timestamp, err := pickleNumber(datapoint[0])
+	if err != nil {
+		return Metric{}, fmt.Errorf("invalid carbon pickle timestamp: %v", err)
+	}
+
+	value, err := pickleNumber(datapoint[1])
+	if err != nil {
+		return Metric{}, fmt.Errorf("invalid carbon pickle value: %v", err)
+	}
+
+	return newMetric(path, timestamp, value, now)
+}
+
+// pickleSequence returns the items of unpickled lists and tuples
+func pickleSequence(value interface{}) ([]interface{}, bool) {
+	switch v := value.(type) {
+	case *pickleList:
+		return v.items, true
+	case []interface{}:
+		return v, true
+	default:
+		return nil, false
+	}
+}
+
+// pickleNumber returns the value of unpickled numbers, including numbers
+// sent as strings which carbon also accepts
+func pickleNumber(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	default:
+		return 0, fmt.Errorf("expected a number, got %v", value)
+	}
+}
+
+// pickleList is an unpickled list, which unlike tuples is appended to after
+// being created, so it is referenced by pointer to keep memoized references
+// to it up to date
+type pickleList struct {
+	items []interface{}
+}
+
+// pickleMark marks the start of the items of a list or tuple on the stack
+type pickleMark struct{}
+
+// Opcodes of the pickle protocols needed to unpickle carbon messages
+const (
+	opMark           = '('
+	opStop           = '.'
+	opPop            = '0'
+	opInt            = 'I'
+	opBinInt         = 'J'
+	opBinInt1        = 'K'
+	opBinInt2        = 'M'
+	opLong           = 'L'
+	opNone           = 'N'
+	opFloat          = 'F'
+	opBinFloat       = 'G'
+	opString         = 'S'
+	opBinString      = 'T'
+	opShortBinString = 'U'
+	opUnicode        = 'V'
+	opBinUnicode     = 'X'
+	opAppend         = 'a'
+	opAppends        = 'e'
+	opList           = 'l'
+	opEmptyList      = ']'
+	opTuple          = 't'
+	opEmptyTuple     = ')'
+	opGet            = 'g'
+	opBinGet         = 'h'
+	opLongBinGet     = 'j'
+	opPut            = 'p'
+	opBinPut         = 'q'
+	opLongBinPut     = 'r'
+	opBinBytes       = 'B'
+	opShortBinBytes  = 'C'
+
+	opProto           = 0x80
+	opTuple1          = 0x85
+	opTuple2          = 0x86
+	opTuple3          = 0x87
+	opNewTrue         = 0x88
+	opNewFalse        = 0x89
+	opLong1           = 0x8a
+	opShortBinUnicode = 0x8c
+	opMemoize         = 0x94
+	opFrame           = 0x95
+)
+
+// unpickler is a minimal unpickler, supporting the opcodes used to pickle
+// lists and tuples of strings and numbers with protocols 0 to 4
+type unpickler struct {
+	reader *bytes.Reader
+	stack  []interface{}
+	memo   map[int]interface{}
+}
+
+func unpickle(data []byte) (interface{}, error) {
+	u := &unpickler{
+		reader: bytes.NewReader(data),
+		memo:   make(map[int]interface{}),
+	}
+
+	for {
+		op, err := u.reader.ReadByte()
+		if err != nil {
+			return nil, errPickleTruncated
+		}
+
+		if op == opStop {
+			return u.pop()
+		}
+
+		if err := u.execute(op); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (u *unpickler) execute(op byte) error {
+	switch op {
+	case opProto:
+		_, err := u.readBytes(1)
+		return err
+	case opFrame:
+		_, err := u.readBytes(8)
+		return err
+	case opMark:
+		u.push(pickleMark{})
+	case opPop:
+		_, err := u.pop()
+		return err
+	case opNone:
+		u.push(nil)
+	case opNewTrue:
+		u.push(true)
+	case opNewFalse:
+		u.push(false)
+	case opInt:
+		return u.loadInt()
+	case opLong:
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+
+		return u.pushInt(strings.TrimSuffix(line, "L"))
+	case opBinInt:
+		b, err := u.readBytes(4)
+		if err != nil {
+			return err
+		}
+
+		u.push(int64(int32(binary.LittleEndian.Uint32(b))))
+	case opBinInt1:
+		b, err := u.readBytes(1)
+		if err != nil {
+			return err
+		}
+
+		u.push(int64(b[0]))
+	case opBinInt2:
+		b, err := u.readBytes(2)
+		if err != nil {
+			return err
+		}
+
+		u.push(int64(binary.LittleEndian.Uint16(b)))
+	case opLong1:
+		return u.loadLong1()
+	case opFloat:
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+
+		f, err := strconv.ParseFloat(line, 64)
+		if err != nil {
+			return fmt.Errorf("invalid carbon pickle float %q", line)
+		}
+
+		u.push(f)
+	case opBinFloat:
+		b, err := u.readBytes(8)
+		if err != nil {
+			return err
+		}
+
+		u.push(math.Float64frombits(binary.BigEndian.Uint64(b)))
+	case opString:
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+
+		if len(line) < 2 || (line[0] != '\'' && line[0] != '"') || line[len(line)-1] != line[0] {
+			return fmt.Errorf("invalid carbon pickle string %q", line)
+		}
+
+		u.push(line[1 : len(line)-1])
+	case opUnicode:
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+
+		u.push(line)
+	case opShortBinString, opShortBinUnicode, opShortBinBytes:
+		return u.loadSizedString(1)
+	case opBinString, opBinUnicode, opBinBytes:
+		return u.loadSizedString(4)
+	case opEmptyList:
+		u.push(&pickleList{})
+	case opList:
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+
+		u.push(&pickleList{items: items})
+	case opAppend:
+		value, err := u.pop()
+		if err != nil {
+			return err
+		}
+
+		return u.appendItems(value)
+	case opAppends:
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+
+		return u.appendItems(items...)
+	case opEmptyTuple:
+		u.push([]interface{}{})
+	case opTuple:
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+
+		u.push(items)
+	case opTuple1, opTuple2, opTuple3:
+		return u.loadTuple(int(op-opTuple1) + 1)
+	case opPut:
+		idx, err := u.readMemoIndex()
+		if err != nil {
+			return err
+		}
+
+		return u.put(idx)
+	case opBinPut:
+		b, err := u.readBytes(1)
+		if err != nil {
+			return err
+		}
+
+		return u.put(int(b[0]))
+	case opLongBinPut:
+		b, err := u.readBytes(4)
+		if err != nil {
+			return err
+		}
+
+		return u.put(int(binary.LittleEndian.Uint32(b)))
+	case opMemoize:
+		return u.put(len(u.memo))
+	case opGet:
+		idx, err := u.readMemoIndex()
+		if err != nil {
+			return err
+		}
+
+		return u.get(idx)
+	case opBinGet:
+		b, err := u.readBytes(1)
+		if err != nil {
+			return err
+		}
+
+		return u.get(int(b[0]))
+	case opLongBinGet:
+		b, err := u.readBytes(4)
+		if err != nil {
+			return err
+		}
+
+		return u.get(int(binary.LittleEndian.Uint32(b)))
+	default:
+		return fmt.Errorf("unsupported carbon pickle opcode 0x%x", op)
+	}
+
+	return nil
+}
+
+func (u *unpickler) push(value interface{}) {
+	u.stack = append(u.stack, value)
+}
+
+func (u *unpickler) pop() (interface{}, error) {
+	if len(u.stack) == 0 {
+		return nil, errPickleStackUnderflow
+	}
+
+	value := u.stack[len(u.stack)-1]
+	u.stack = u.stack[:len(u.stack)-1]
+	return value, nil
+}
+
+// popMark pops the items pushed since the last mark, along with the mark
+func (u *unpickler) popMark() ([]interface{}, error) {
+	for i := len(u.stack) - 1; i >= 0; i-- {
+		if _, ok := u.stack[i].(pickleMark); !ok {
+			continue
+		}
+
+		items := make([]interface{}, len(u.stack)-i-1)
+		copy(items, u.stack[i+1:])
+		u.stack = u.stack[:i]
+		return items, nil
+	}
+
+	return nil, errPickleNoMark
+}
+
+func (u *unpickler) appendItems(items ...interface{}) error {
+	if len(u.stack) == 0 {
+		return errPickleStackUnderflow
+	}
+
+	list, ok := u.stack[len(u.stack)-1].(*pickleList)
+	if !ok {
+		return fmt.Errorf("cannot append to carbon pickle %T", u.stack[len(u.stack)-1])
+	}
+
+	list.items = append(list.items, items...)
+	return nil
+}
+
+func (u *unpickler) loadTuple(size int) error {
+	if len(u.stack) < size {
+		return errPickleStackUnderflow
+	}
+
+	items := make([]interface{}, size)
+	copy(items, u.stack[len(u.stack)-size:])
+	u.stack = u.stack[:len(u.stack)-size]
+	u.push(items)
+	return nil
+}
+
+func (u *unpickler) loadInt() error {
+	line, err := u.readLine()
+	if err != nil {
+		return err
+	}
+
+	// Protocol 0 pickles booleans as integers
+	switch line {
+	case "00":
+		u.push(false)
+		return nil
+	case "01":
+		u.push(true)
+		return nil
+	}
+
+	return u.pushInt(line)
+}
+
+func (u *unpickler) pushInt(s string) error {
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid carbon pickle integer %q", s)
+	}
+
+	u.push(i)
+	return nil
+}
+
+func (u *unpickler) loadLong1() error {
+	size, err := u.readBytes(1)
+	if err != nil {
+		return err
+	}
+
+	if size[0] > 8 {
+		return fmt.Errorf("carbon pickle integer of %d bytes overflows int64", size[0])
+	}
+
+	b, err := u.readBytes(int(size[0]))
+	if err != nil {
+		return err
+	}
+
+	// Sign extend the little endian two's complement integer
+	var v uint64
+	if len(b) > 0 && b[len(b)-1]&0x80 != 0 {
+		v = math.MaxUint64
+	}
+
+	for i := len(b) - 1; i >= 0; i-- {
+		v = v<<8 | uint64(b[i])
+	}
+
+	u.push(int64(v))
+	return nil
+}
+
+func (u *unpickler) loadSizedString(sizeBytes int) error {
+	b, err := u.readBytes(sizeBytes)
+	if err != nil {
+		return err
+	}
+
+	size := int(b[0])
+	if sizeBytes == 4 {
+		size = int(binary.LittleEndian.Uint32(b))
+	}
+
+	s, err := u.readBytes(size)
+	if err != nil {
+		return err
+	}
+
+	u.push(string(s))
+	return nil
+}
+
+// readMemoIndex reads the memo index of the protocol 0 get and put opcodes
+func (u *unpickler) readMemoIndex() (int, error) {
+	line, err := u.readLine()
+	if err != nil {
+		return 0, err
+	}
+
+	idx, err := strconv.Atoi(line)
+	if err != nil {
+		return 0, fmt.Errorf("invalid carbon pickle memo index %q", line)
+	}
+
+	return idx, nil
+}
+
+func (u *unpickler) put(idx int) error {
+	if len(u.stack) == 0 {
+		return errPickleStackUnderflow
+	}
+
+	u.memo[idx] = u.stack[len(u.stack)-1]
+	return nil
+}
+
+func (u *unpickler) get(idx int) error {
+	value, ok := u.memo[idx]
+	if !ok {
+		return fmt.Errorf("carbon pickle memo index %d not found", idx)
+	}
+
+	u.push(value)
+	return nil
+}
+
+func (u *unpickler) readBytes(n int) ([]byte, error) {
+	if n < 0 || n > u.reader.Len() {
+		return nil, errPickleTruncated
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(u.reader, b); err != nil {
+		return nil, errPickleTruncated
+	}
+
+	return b, nil
+}
+
+func (u *unpickler) readLine() (string, error) {
+	var line bytes.Buffer
+	for {
+		c, err := u.reader.ReadByte()
+		if err != nil {
+			return "", errPickleTruncated
+		}
+
+		if c == '\n' {
+			return line.String(), nil
+		}
+
+		line.WriteByte(c)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package carbon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testNow = time.Unix(1600000000, 0)
+
+	// testPickleMessages pickle the same metrics with different protocols,
+	// as pickle.dumps(metrics, protocol=N) in Python
+	testPickleMessages = map[string]string{
+		"protocol 0": "(lp0\n(Vservers.host1.cpu\np1\n(I1500000000\nF1.5\ntp2\ntp3\na(Vservers.host2.cpu\np4\n(F1500000010.5\nI2\ntp5\ntp6\na.",
+		"protocol 2": "\x80\x02]q\x00(X\x11\x00\x00\x00servers.host1.cpuq\x01J\x00/hYG?\xf8\x00\x00\x00\x00\x00\x00\x86q\x02\x86q\x03X\x11\x00\x00\x00servers.host2.cpuq\x04GA\xd6Z\x0b\xc2\xa0\x00\x00K\x02\x86q\x05\x86q\x06e.",
+		"protocol 4": "\x80\x04\x95N\x00\x00\x00\x00\x00\x00\x00]\x94(\x8c\x11servers.host1.cpu\x94J\x00/hYG?\xf8\x00\x00\x00\x00\x00\x00\x86\x94\x86\x94\x8c\x11servers.host2.cpu\x94GA\xd6Z\x0b\xc2\xa0\x00\x00K\x02\x86\x94\x86\x94e.",
+		"python 2":   "(lp0\n(S'servers.host1.cpu'\np1\n(L1500000000L\nF1.5\ntp2\ntp3\na(S'servers.host2.cpu'\np4\n(F1500000010.5\nI2\ntp5\ntp6\na.",
+	}
+
+	testPickleMetrics = []Metric{
+		{Path: "servers.host1.cpu", Timestamp: time.Unix(1500000000, 0), Value: 1.5},
+		{Path: "servers.host2.cpu", Timestamp: time.Unix(1500000010, int64(500*time.Millisecond)), Value: 2},
+	}
+)
+
+func TestParsePickle(t *testing.T) {
+	for name, message := range testPickleMessages {
+		t.Run(name, func(t *testing.T) {
+			metrics, err := ParsePickle([]byte(message), testNow)
+			require.NoError(t, err)
+			assert.Equal(t, testPickleMetrics, metrics)
+		})
+	}
+}
+
+func TestParsePickleNegativeTimestamp(t *testing.T) {
+	// pickle.dumps([("a.b", (-1, "3"))], protocol=2)
+	message := "\x80\x02]q\x00X\x03\x00\x00\x00a.bq\x01J\xff\xff\xff\xffX\x01\x00\x00\x003q\x02\x86q\x03\x86q\x04a."
+	metrics, err := ParsePickle([]byte(message), testNow)
+	require.NoError(t, err)
+	assert.Equal(t, []Metric{{Path: "a.b", Timestamp: testNow, Value: 3}}, metrics)
+}
+
+func TestParsePickleInvalid(t *testing.T) {
+	tests := map[string]string{
+		"truncated":         "\x80\x02]q\x00(X\x11\x00\x00\x00servers",
+		"not a list":        "\x80\x02K\x01.",
+		"invalid metric":    "\x80\x02]q\x00K\x01a.",
+		"invalid datapoint": "\x80\x02]q\x00X\x03\x00\x00\x00a.bq\x01K\x01\x86q\x02a.",
+		"unsupported op":    "\x80\x02}q\x00.",
+		"missing mark":      "\x80\x02]q\x00e.",
+		"stack underflow":   "\x80\x02\x86.",
+	}
+
+	for name, message := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParsePickle([]byte(message), testNow)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func newPickleMessage(message string) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, uint32(len(message)))
+	buf.WriteString(message)
+	return buf.Bytes()
+}
+
+func TestReadPickleMessage(t *testing.T) {
+	message := testPickleMessages["protocol 2"]
+	r := bytes.NewReader(append(newPickleMessage(message), newPickleMessage(message)...))
+	for i := 0; i < 2; i++ {
+		read, err := ReadPickleMessage(r)
+		require.NoError(t, err)
+		assert.Equal(t, message, string(read))
+	}
+
+	_, err := ReadPickleMessage(r)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestReadPickleMessageTooLarge(t *testing.T) {
+	var header [pickleHeaderSize]byte
+	binary.BigEndian.PutUint32(header[:], maxPickleMessageSize+1)
+	_, err := ReadPickleMessage(bytes.NewReader(header[:]))
+	assert.Equal(t, errPickleMessageTooLarge, err)
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package carbon
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+var errNaNValue = errors.New("carbon value is not a number")
+
+// Metric is a datapoint of a Graphite path received by carbon
+type Metric struct {
+	Path      string
+	Timestamp time.Time
+	Value     float64
+}
+
+// ParseLine parses a line of the carbon plaintext protocol, formatted as
+// "<path> <value> <timestamp>". A negative timestamp is replaced by now.
+func ParseLine(line []byte, now time.Time) (Metric, error) {
+	fields := bytes.Fields(line)
+	if len(fields) != 3 {
+		return Metric{}, fmt.Errorf("invalid carbon line %q, expected path, value and timestamp", line)
+	}
+
+	value, err := strconv.ParseFloat(string(fields[1]), 64)
+	if err != nil {
+		return Metric{}, fmt.Errorf("invalid carbon value %q: %v", fields[1], err)
+	}
+
+	timestamp, err := strconv.ParseFloat(string(fields[2]), 64)
+	if err != nil {
+		return Metric{}, fmt.Errorf("invalid carbon timestamp %q: %v", fields[2], err)
+	}
+
+	return newMetric(string(fields[0]), timestamp, value, now)
+}
+
+func newMetric(path string, timestamp, value float64, now time.Time) (Metric, error) {
+	if math.IsNaN(value) {
+		return Metric{}, errNaNValue
+	}
+
+	t := now
+	if timestamp >= 0 {
+		seconds, fraction := math.Modf(timestamp)
+		t = time.Unix(int64(seconds), int64(fraction*float64(time.Second)))
+	}
+
+	return Metric{
+		Path:      path,
+		Timestamp: t,
+		Value:     value,
+	}, nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package carbon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected Metric
+	}{
+		{
+			line:     "servers.host1.cpu 1.5 1500000000",
+			expected: Metric{Path: "servers.host1.cpu", Timestamp: time.Unix(1500000000, 0), Value: 1.5},
+		},
+		{
+			line:     "  servers.host1.cpu\t-2   1500000000.25\r",
+			expected: Metric{Path: "servers.host1.cpu", Timestamp: time.Unix(1500000000, int64(250*time.Millisecond)), Value: -2},
+		},
+		{
+			line:     "servers.host1.cpu 3 -1",
+			expected: Metric{Path: "servers.host1.cpu", Timestamp: testNow, Value: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			metric, err := ParseLine([]byte(tt.line), testNow)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, metric)
+		})
+	}
+}
+
+func TestParseLineInvalid(t *testing.T) {
+	lines := []string{
+		"servers.host1.cpu 1.5",
+		"servers.host1.cpu 1.5 1500000000 extra",
+		"servers.host1.cpu abc 1500000000",
+		"servers.host1.cpu 1.5 abc",
+		"servers.host1.cpu nan 1500000000",
+	}
+
+	for _, line := range lines {
+		t.Run(line, func(t *testing.T) {
+			_, err := ParseLine([]byte(line), testNow)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,352 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package carbon
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/graphite"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/ts"
+	"github.com/m3db/m3db/src/coordinator/util/logging"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultReadTimeout = 2 * time.Minute
+
+	// maxLineSize is the longest plaintext line and largest UDP datagram read
+	maxLineSize = 64 * 1024
+)
+
+var errNoListenAddress = errors.New("carbon server requires a plaintext or pickle listen address")
+
+// Options are the options of a carbon server
+type Options struct {
+	// ListenAddress is the address of the plaintext protocol listeners,
+	// accepting both TCP and UDP
+	ListenAddress string
+	// PickleListenAddress is the address of the pickle protocol listeners,
+	// accepting both TCP and UDP
+	PickleListenAddress string
+	// Appender writes the received datapoints
+	Appender storage.Appender
+	// ReadTimeout closes TCP connections which send nothing for longer
+	ReadTimeout time.Duration
+	// NowFn returns the current time, defaults to time.Now
+	NowFn func() time.Time
+	// Scope is the metrics scope, defaults to a noop scope
+	Scope tally.Scope
+}
+
+// Server receives datapoints with the carbon plaintext and pickle protocols,
+// writing them with the Graphite path nodes mapped to tags
+type Server struct {
+	sync.Mutex
+
+	opts          Options
+	metrics       serverMetrics
+	listeners     []net.Listener
+	packetConns   []net.PacketConn
+	conns         map[net.Conn]struct{}
+	closed        bool
+	handlersGroup sync.WaitGroup
+}
+
+type serverMetrics struct {
+	received    tally.Counter
+	malformed   tally.Counter
+	writeErrors tally.Counter
+	connections tally.Counter
+}
+
+func newServerMetrics(scope tally.Scope) serverMetrics {
+	return serverMetrics{
+		received:    scope.Counter("datapoints.received"),
+		malformed:   scope.Counter("datapoints.malformed"),
+		writeErrors: scope.Counter("write.errors"),
+		connections: scope.Counter("connections.accepted"),
+	}
+}
+
+// handleFn handles the datapoints read from a TCP connection or a datagram
+type handleFn func(s *Server, r io.Reader)
+
+// NewServer creates a new carbon server, listening on the addresses given in
+// the options until closed
+func NewServer(opts Options) (*Server, error) {
+	if opts.ListenAddress == "" && opts.PickleListenAddress == "" {
+		return nil, errNoListenAddress
+	}
+
+	if opts.ReadTimeout <= 0 {
+		opts.ReadTimeout = defaultReadTimeout
+	}
+
+	if opts.NowFn == nil {
+		opts.NowFn = time.Now
+	}
+
+	if opts.Scope == nil {
+		opts.Scope = tally.NoopScope
+	}
+
+	s := &Server{
+		opts:    opts,
+		metrics: newServerMetrics(opts.Scope),
+		conns:   make(map[net.Conn]struct{}),
+	}
+
+	if opts.ListenAddress != "" {
+		if err := s.listen(opts.ListenAddress, (*Server).handlePlaintext); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+
+	if opts.PickleListenAddress != "" {
+		if err := s.listen(opts.PickleListenAddress, (*Server).handlePickle); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// listen starts accepting both TCP connections and UDP datagrams on address
+func (s *Server) listen(address string, handle handleFn) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	s.listeners = append(s.listeners, listener)
+	packetConn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return err
+	}
+
+	s.packetConns = append(s.packetConns, packetConn)
+	s.handlersGroup.Add(2)
+	go s.acceptLoop(listener, handle)
+	go s.readPacketLoop(packetConn, handle)
+	return nil
+}
+
+// TCPAddrs returns the addresses of the TCP listeners, the plaintext one
+// first when listening for both protocols
+func (s *Server) TCPAddrs() []net.Addr {
+	addrs := make([]net.Addr, 0, len(s.listeners))
+	for _, listener := range s.listeners {
+		addrs = append(addrs, listener.Addr())
+	}
+
+	return addrs
+}
+
+// UDPAddrs returns the addresses of the UDP listeners, the plaintext one
+// first when listening for both protocols
+func (s *Server) UDPAddrs() []net.Addr {
+	addrs := make([]net.Addr, 0, len(s.packetConns))
+	for _, packetConn := range s.packetConns {
+		addrs = append(addrs, packetConn.LocalAddr())
+	}
+
+	return addrs
+}
+
+func (s *Server) acceptLoop(listener net.Listener, handle handleFn) {
+	defer s.handlersGroup.Done()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			// Accept only fails permanently once the listener is closed
+			if netErr, ok := err.(net.Error); ok && netErr.Temporary() {
+				continue
+			}
+
+			return
+		}
+
+		if !s.trackConn(conn) {
+			conn.Close()
+			return
+		}
+
+		s.metrics.connections.Inc(1)
+		s.handlersGroup.Add(1)
+		go func() {
+			defer s.handlersGroup.Done()
+			defer s.untrackConn(conn)
+			handle(s, &deadlineReader{conn: conn, timeout: s.opts.ReadTimeout})
+		}()
+	}
+}
+
+func (s *Server) readPacketLoop(packetConn net.PacketConn, handle handleFn) {
+	defer s.handlersGroup.Done()
+	buf := make([]byte, maxLineSize)
+	for {
+		n, _, err := packetConn.ReadFrom(buf)
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Temporary() {
+				continue
+			}
+
+			return
+		}
+
+		handle(s, bytes.NewReader(buf[:n]))
+	}
+}
+
+func (s *Server) trackConn(conn net.Conn) bool {
+	s.Lock()
+	defer s.Unlock()
+	if s.closed {
+		return false
+	}
+
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+func (s *Server) untrackConn(conn net.Conn) {
+	s.Lock()
+	delete(s.conns, conn)
+	s.Unlock()
+	conn.Close()
+}
+
+// handlePlaintext writes the datapoints of each line read
+func (s *Server) handlePlaintext(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		metric, err := ParseLine(line, s.opts.NowFn())
+		if err != nil {
+			s.metrics.malformed.Inc(1)
+			continue
+		}
+
+		s.write(metric)
+	}
+}
+
+// handlePickle writes the datapoints of each pickle message read
+func (s *Server) handlePickle(r io.Reader) {
+	for {
+		message, err := ReadPickleMessage(r)
+		if err != nil {
+			if err != io.EOF {
+				s.metrics.malformed.Inc(1)
+			}
+
+			return
+		}
+
+		metrics, err := ParsePickle(message, s.opts.NowFn())
+		if err != nil {
+			s.metrics.malformed.Inc(1)
+			continue
+		}
+
+		for _, metric := range metrics {
+			s.write(metric)
+		}
+	}
+}
+
+func (s *Server) write(metric Metric) {
+	s.metrics.received.Inc(1)
+	tags, err := graphite.PathToTags(metric.Path)
+	if err != nil {
+		s.metrics.malformed.Inc(1)
+		return
+	}
+
+	ctx := context.Background()
+	query := &storage.WriteQuery{
+		Raw:        metric.Path,
+		Tags:       tags,
+		Datapoints: ts.Datapoints{{Timestamp: metric.Timestamp, Value: metric.Value}},
+		Unit:       xtime.Millisecond,
+	}
+
+	if err := s.opts.Appender.Write(ctx, query); err != nil {
+		s.metrics.writeErrors.Inc(1)
+		logging.WithContext(ctx).Error("unable to write carbon datapoint",
+			zap.String("path", metric.Path), zap.Any("error", err))
+	}
+}
+
+// Close stops listening, closes the open connections and waits for the
+// datapoints being handled to be written
+func (s *Server) Close() error {
+	s.Lock()
+	s.closed = true
+	for _, listener := range s.listeners {
+		listener.Close()
+	}
+
+	for _, packetConn := range s.packetConns {
+		packetConn.Close()
+	}
+
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.Unlock()
+
+	s.handlersGroup.Wait()
+	return nil
+}
+
+// deadlineReader extends the read deadline of a connection before each read,
+// so that idle connections are closed
+type deadlineReader struct {
+	conn    net.Conn
+	timeout time.Duration
+}
+
+func (r *deadlineReader) Read(p []byte) (int, error) {
+	if err := r.conn.SetReadDeadline(time.Now().Add(r.timeout)); err != nil {
+		return 0, err
+	}
+
+	return r.conn.Read(p)
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package carbon
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAppender struct {
+	sync.Mutex
+	queries []*storage.WriteQuery
+}
+
+func (a *testAppender) Write(_ context.Context, query *storage.WriteQuery) error {
+	a.Lock()
+	a.queries = append(a.queries, query)
+	a.Unlock()
+	return nil
+}
+
+func (a *testAppender) paths() []string {
+	a.Lock()
+	defer a.Unlock()
+	paths := make([]string, 0, len(a.queries))
+	for _, q := range a.queries {
+		paths = append(paths, q.Raw)
+	}
+
+	sort.Strings(paths)
+	return paths
+}
+
+func (a *testAppender) waitForWrites(t *testing.T, n int) []string {
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		if paths := a.paths(); len(paths) >= n {
+			return paths
+		}
+	}
+
+	require.FailNow(t, fmt.Sprintf("timed out waiting for %d writes, got %v", n, a.paths()))
+	return nil
+}
+
+func newTestServer(t *testing.T) (*Server, *testAppender) {
+	appender := &testAppender{}
+	server, err := NewServer(Options{
+		ListenAddress:       "127.0.0.1:0",
+		PickleListenAddress: "127.0.0.1:0",
+		Appender:            appender,
+		NowFn:               func() time.Time { return testNow },
+	})
+	require.NoError(t, err)
+	return server, appender
+}
+
+func TestServerPlaintextTCP(t *testing.T) {
+	server, appender := newTestServer(t)
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.TCPAddrs()[0].String())
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("servers.host1.cpu 1 1500000000\nmalformed\n\nservers.host2.cpu 2 -1\n"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	assert.Equal(t, []string{"servers.host1.cpu", "servers.host2.cpu"}, appender.waitForWrites(t, 2))
+
+	appender.Lock()
+	defer appender.Unlock()
+	query := appender.queries[0]
+	assert.Equal(t, models.Tags{"__g0__": "servers", "__g1__": "host1", "__g2__": "cpu"}, query.Tags)
+	require.Len(t, query.Datapoints, 1)
+	assert.Equal(t, time.Unix(1500000000, 0), query.Datapoints[0].Timestamp)
+	assert.Equal(t, 1.0, query.Datapoints[0].Value)
+}
+
+func TestServerPlaintextUDP(t *testing.T) {
+	server, appender := newTestServer(t)
+	defer server.Close()
+
+	conn, err := net.Dial("udp", server.UDPAddrs()[0].String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("servers.host1.cpu 1 1500000000\nservers.host2.cpu 2 1500000000"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"servers.host1.cpu", "servers.host2.cpu"}, appender.waitForWrites(t, 2))
+}
+
+func TestServerPickleTCP(t *testing.T) {
+	server, appender := newTestServer(t)
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.TCPAddrs()[1].String())
+	require.NoError(t, err)
+	_, err = conn.Write(newPickleMessage(testPickleMessages["protocol 2"]))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	assert.Equal(t, []string{"servers.host1.cpu", "servers.host2.cpu"}, appender.waitForWrites(t, 2))
+}
+
+func TestServerPickleUDP(t *testing.T) {
+	server, appender := newTestServer(t)
+	defer server.Close()
+
+	conn, err := net.Dial("udp", server.UDPAddrs()[1].String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write(newPickleMessage(testPickleMessages["protocol 4"]))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"servers.host1.cpu", "servers.host2.cpu"}, appender.waitForWrites(t, 2))
+}
+
+func TestServerCloseWithOpenConnection(t *testing.T) {
+	server, _ := newTestServer(t)
+	conn, err := net.Dial("tcp", server.TCPAddrs()[0].String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	assert.NoError(t, server.Close())
+}
+
+func TestNewServerNoListenAddress(t *testing.T) {
+	_, err := NewServer(Options{Appender: &testAppender{}})
+	assert.Equal(t, errNoListenAddress, err)
+}
@@ -0,0 +1,256 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ExpressionType is the type of a Graphite target expression
+type ExpressionType int
+
+const (
+	// PathExpression is a path, possibly holding globs
+	PathExpression ExpressionType = iota
+	// CallExpression is a call to a function
+	CallExpression
+	// NumberExpression is a number literal
+	NumberExpression
+	// StringExpression is a quoted string literal
+	StringExpression
+	// BoolExpression is a true or false literal
+	BoolExpression
+)
+
+// Expression is a parsed Graphite target expression
+type Expression struct {
+	Type ExpressionType
+	// Value is the path of path expressions and the value of strings
+	Value string
+	// Name is the function name of calls
+	Name string
+	// Args are the arguments of calls
+	Args   []*Expression
+	Number float64
+	Bool   bool
+}
+
+// String returns the canonical form of the expression, which names the
+// series it produces
+func (e *Expression) String() string {
+	switch e.Type {
+	case CallExpression:
+		args := make([]string, 0, len(e.Args))
+		for _, arg := range e.Args {
+			args = append(args, arg.String())
+		}
+
+		return e.Name + "(" + strings.Join(args, ",") + ")"
+	case NumberExpression:
+		return strconv.FormatFloat(e.Number, 'g', -1, 64)
+	case StringExpression:
+		return strconv.Quote(e.Value)
+	case BoolExpression:
+		return strconv.FormatBool(e.Bool)
+	default:
+		return e.Value
+	}
+}
+
+// Parse parses a Graphite target expression such as
+// sumSeries(servers.*.cpu.{user,system})
+func Parse(target string) (*Expression, error) {
+	p := &expressionParser{input: target}
+	expr, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+	if !p.done() {
+		return nil, p.errorf("unexpected %q", p.input[p.pos])
+	}
+
+	return expr, nil
+}
+
+type expressionParser struct {
+	input string
+	pos   int
+}
+
+func (p *expressionParser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *expressionParser) skipSpaces() {
+	for !p.done() && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *expressionParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid graphite expression %q at position %d: %s",
+		p.input, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *expressionParser) parseExpression() (*Expression, error) {
+	p.skipSpaces()
+	if p.done() {
+		return nil, p.errorf("unexpected end of expression")
+	}
+
+	if c := p.input[p.pos]; c == '\'' || c == '"' {
+		return p.parseString(c)
+	}
+
+	token, err := p.scanToken()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.done() && p.input[p.pos] == '(' {
+		return p.parseCall(token)
+	}
+
+	token = strings.TrimSpace(token)
+	switch token {
+	case "":
+		return nil, p.errorf("empty expression")
+	case "true", "false":
+		return &Expression{Type: BoolExpression, Bool: token == "true"}, nil
+	}
+
+	if number, err := strconv.ParseFloat(token, 64); err == nil {
+		return &Expression{Type: NumberExpression, Number: number}, nil
+	}
+
+	return &Expression{Type: PathExpression, Value: token}, nil
+}
+
+// scanToken scans a path or function name, stopping at the separators of
+// arguments outside of any glob alternatives or character classes
+func (p *expressionParser) scanToken() (string, error) {
+	var (
+		start      = p.pos
+		braceDepth int
+		inClass    bool
+	)
+
+	for ; !p.done(); p.pos++ {
+		c := p.input[p.pos]
+		switch {
+		case inClass:
+			inClass = c != ']'
+		case c == '[':
+			inClass = true
+		case c == '{':
+			braceDepth++
+		case c == '}':
+			braceDepth--
+		case braceDepth == 0 && (c == ',' || c == '(' || c == ')'):
+			return p.input[start:p.pos], nil
+		}
+	}
+
+	if braceDepth != 0 || inClass {
+		return "", p.errorf("unterminated glob")
+	}
+
+	return p.input[start:], nil
+}
+
+func (p *expressionParser) parseCall(name string) (*Expression, error) {
+	name = strings.TrimSpace(name)
+	if !isFunctionName(name) {
+		return nil, p.errorf("invalid function name %q", name)
+	}
+
+	// Skip the opening parenthesis
+	p.pos++
+	call := &Expression{Type: CallExpression, Name: name}
+	p.skipSpaces()
+	if !p.done() && p.input[p.pos] == ')' {
+		p.pos++
+		return call, nil
+	}
+
+	for {
+		arg, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		call.Args = append(call.Args, arg)
+		p.skipSpaces()
+		if p.done() {
+			return nil, p.errorf("missing ')' closing call to %s", name)
+		}
+
+		switch p.input[p.pos] {
+		case ',':
+			p.pos++
+		case ')':
+			p.pos++
+			return call, nil
+		default:
+			return nil, p.errorf("unexpected %q in call to %s", p.input[p.pos], name)
+		}
+	}
+}
+
+func (p *expressionParser) parseString(quote byte) (*Expression, error) {
+	var value bytes.Buffer
+	for p.pos++; !p.done(); p.pos++ {
+		c := p.input[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.input):
+			p.pos++
+			value.WriteByte(p.input[p.pos])
+		case c == quote:
+			p.pos++
+			return &Expression{Type: StringExpression, Value: value.String()}, nil
+		default:
+			value.WriteByte(c)
+		}
+	}
+
+	return nil, p.errorf("unterminated string")
+}
+
+func isFunctionName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, c := range name {
+		isLetter := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		isDigit := c >= '0' && c <= '9'
+		if !isLetter && (i == 0 || !isDigit) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		target   string
+		expected string
+	}{
+		{"servers.host1.cpu", "servers.host1.cpu"},
+		{"sumSeries(servers.*.cpu.{user,system})", "sumSeries(servers.*.cpu.{user,system})"},
+		{"alias( scale(servers.host[1-2].cpu , 2.5) , 'cpu, scaled' )", `alias(scale(servers.host[1-2].cpu,2.5),"cpu, scaled")`},
+		{`aliasByNode(servers.*.cpu, 1, -1)`, "aliasByNode(servers.*.cpu,1,-1)"},
+		{`alias(a.b, "it's")`, `alias(a.b,"it's")`},
+		{`alias(a.b, 'it\'s')`, `alias(a.b,"it's")`},
+		{"transformNull(a.b, true)", "transformNull(a.b,true)"},
+		{"sumSeries()", "sumSeries()"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			expr, err := Parse(tt.target)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, expr.String())
+		})
+	}
+}
+
+func TestParseTypes(t *testing.T) {
+	expr, err := Parse("scale(servers.*, 2, 'name', false)")
+	require.NoError(t, err)
+	assert.Equal(t, CallExpression, expr.Type)
+	assert.Equal(t, "scale", expr.Name)
+	require.Len(t, expr.Args, 4)
+	assert.Equal(t, &Expression{Type: PathExpression, Value: "servers.*"}, expr.Args[0])
+	assert.Equal(t, &Expression{Type: NumberExpression, Number: 2}, expr.Args[1])
+	assert.Equal(t, &Expression{Type: StringExpression, Value: "name"}, expr.Args[2])
+	assert.Equal(t, &Expression{Type: BoolExpression, Bool: false}, expr.Args[3])
+}
+
+func TestParseInvalid(t *testing.T) {
+	targets := []string{
+		"",
+		"sumSeries(a.b",
+		"sumSeries(a.b,)",
+		"sumSeries(a.b))",
+		"alias(a.b, 'name)",
+		"sum-series(a.b)",
+		"(a.b)",
+		"a.{b,c",
+		"sumSeries(a.b) c",
+	}
+
+	for _, target := range targets {
+		t.Run(target, func(t *testing.T) {
+			_, err := Parse(target)
+			assert.Error(t, err)
+		})
+	}
+}
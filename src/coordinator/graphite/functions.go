@@ -0,0 +1,526 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Fetcher fetches the series whose paths match a Graphite path
+type Fetcher interface {
+	// FetchSeries fetches the series matching the path, which may hold globs,
+	// consolidated into the steps of the request
+	FetchSeries(ctx context.Context, path string) ([]*Series, error)
+}
+
+// function evaluates a call to a Graphite function
+type function func(ctx context.Context, fetcher Fetcher, call *Expression) ([]*Series, error)
+
+// functions are the supported Graphite functions, keyed by name
+var functions map[string]function
+
+func init() {
+	functions = map[string]function{
+		"absolute":              transformFunction(absolute),
+		"alias":                 alias,
+		"aliasByNode":           aliasByNode,
+		"averageSeries":         combineFunction(averageValues),
+		"avg":                   combineFunction(averageValues),
+		"derivative":            transformFunction(derivative),
+		"keepLastValue":         keepLastValue,
+		"maxSeries":             combineFunction(maxValues),
+		"minSeries":             combineFunction(minValues),
+		"nonNegativeDerivative": nonNegativeDerivative,
+		"offset":                offset,
+		"perSecond":             perSecond,
+		"scale":                 scale,
+		"sum":                   combineFunction(sumValues),
+		"sumSeries":             combineFunction(sumValues),
+		"transformNull":         transformNull,
+	}
+}
+
+// Evaluate evaluates the expression, returning the series it produces
+func (e *Expression) Evaluate(ctx context.Context, fetcher Fetcher) ([]*Series, error) {
+	switch e.Type {
+	case PathExpression:
+		return fetcher.FetchSeries(ctx, e.Value)
+	case CallExpression:
+		fn, ok := functions[e.Name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported graphite function %s", e.Name)
+		}
+
+		return fn(ctx, fetcher, e)
+	default:
+		return nil, fmt.Errorf("expected series but found %s", e)
+	}
+}
+
+// seriesArgs evaluates the arguments of a call which are all series lists
+func seriesArgs(ctx context.Context, fetcher Fetcher, call *Expression) ([]*Series, error) {
+	if len(call.Args) == 0 {
+		return nil, fmt.Errorf("%s expects at least one series list", call.Name)
+	}
+
+	var series []*Series
+	for _, arg := range call.Args {
+		argSeries, err := arg.Evaluate(ctx, fetcher)
+		if err != nil {
+			return nil, err
+		}
+
+		series = append(series, argSeries...)
+	}
+
+	return series, nil
+}
+
+// firstSeriesArg evaluates the series list passed as the first argument of
+// a call, checking the number of arguments following it
+func firstSeriesArg(
+	ctx context.Context,
+	fetcher Fetcher,
+	call *Expression,
+	minArgs, maxArgs int,
+) ([]*Series, error) {
+	if len(call.Args) < minArgs || len(call.Args) > maxArgs {
+		if minArgs == maxArgs {
+			return nil, fmt.Errorf("%s expects %d arguments, got %d", call.Name, minArgs, len(call.Args))
+		}
+
+		return nil, fmt.Errorf("%s expects between %d and %d arguments, got %d",
+			call.Name, minArgs, maxArgs, len(call.Args))
+	}
+
+	return call.Args[0].Evaluate(ctx, fetcher)
+}
+
+func numberArg(call *Expression, i int) (float64, error) {
+	arg := call.Args[i]
+	if arg.Type != NumberExpression {
+		return 0, fmt.Errorf("%s expects a number as argument %d, got %s", call.Name, i+1, arg)
+	}
+
+	return arg.Number, nil
+}
+
+func stringArg(call *Expression, i int) (string, error) {
+	arg := call.Args[i]
+	if arg.Type != StringExpression {
+		return "", fmt.Errorf("%s expects a string as argument %d, got %s", call.Name, i+1, arg)
+	}
+
+	return arg.Value, nil
+}
+
+// optionalNumberArg returns the number passed as the given argument, or the
+// default value if the argument is missing or None
+func optionalNumberArg(call *Expression, i int, defaultValue float64) (float64, error) {
+	if i >= len(call.Args) || (call.Args[i].Type == PathExpression && call.Args[i].Value == "None") {
+		return defaultValue, nil
+	}
+
+	return numberArg(call, i)
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// callName names a series produced by a function applied to another series
+func callName(fn string, series *Series, args ...string) string {
+	return fn + "(" + strings.Join(append([]string{series.Name}, args...), ",") + ")"
+}
+
+// transformFunction returns a function transforming the values of each of
+// the series passed as its only argument
+func transformFunction(transform func(s *Series) []float64) function {
+	return func(ctx context.Context, fetcher Fetcher, call *Expression) ([]*Series, error) {
+		series, err := firstSeriesArg(ctx, fetcher, call, 1, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		results := make([]*Series, 0, len(series))
+		for _, s := range series {
+			results = append(results, s.withValues(callName(call.Name, s), transform(s)))
+		}
+
+		return results, nil
+	}
+}
+
+// combineFunction returns a function combining the values at each step of
+// all the series passed to it into a single series
+func combineFunction(combine func(values []float64) float64) function {
+	return func(ctx context.Context, fetcher Fetcher, call *Expression) ([]*Series, error) {
+		series, err := seriesArgs(ctx, fetcher, call)
+		if err != nil || len(series) == 0 {
+			return nil, err
+		}
+
+		first := series[0]
+		for _, s := range series[1:] {
+			if s.Len() != first.Len() || s.Step != first.Step || !s.Start.Equal(first.Start) {
+				return nil, fmt.Errorf("%s cannot combine series with different steps", call.Name)
+			}
+		}
+
+		combined := make([]float64, first.Len())
+		stepValues := make([]float64, 0, len(series))
+		for i := range combined {
+			stepValues = stepValues[:0]
+			for _, s := range series {
+				if v := s.Values[i]; !math.IsNaN(v) {
+					stepValues = append(stepValues, v)
+				}
+			}
+
+			if len(stepValues) == 0 {
+				combined[i] = math.NaN()
+				continue
+			}
+
+			combined[i] = combine(stepValues)
+		}
+
+		return []*Series{first.withValues(call.String(), combined)}, nil
+	}
+}
+
+func sumValues(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+
+	return sum
+}
+
+func averageValues(values []float64) float64 {
+	return sumValues(values) / float64(len(values))
+}
+
+func minValues(values []float64) float64 {
+	min := values[0]
+	for _, v := range values[1:] {
+		min = math.Min(min, v)
+	}
+
+	return min
+}
+
+func maxValues(values []float64) float64 {
+	max := values[0]
+	for _, v := range values[1:] {
+		max = math.Max(max, v)
+	}
+
+	return max
+}
+
+// mapValues applies fn to each value which is not NaN
+func mapValues(values []float64, fn func(v float64) float64) []float64 {
+	mapped := make([]float64, len(values))
+	for i, v := range values {
+		if math.IsNaN(v) {
+			mapped[i] = v
+			continue
+		}
+
+		mapped[i] = fn(v)
+	}
+
+	return mapped
+}
+
+func absolute(s *Series) []float64 {
+	return mapValues(s.Values, math.Abs)
+}
+
+// derivative returns the change from the previous step, which is NaN when
+// either step has no value
+func derivative(s *Series) []float64 {
+	return deltas(s.Values)
+}
+
+func deltas(values []float64) []float64 {
+	result := make([]float64, len(values))
+	prev := math.NaN()
+	for i, v := range values {
+		result[i] = v - prev
+		prev = v
+	}
+
+	return result
+}
+
+// nonNegativeDeltas returns the change of each value from the previous one.
+// Negative changes are treated as counters wrapping around when maxValue is
+// a number, and are dropped otherwise, as are values past maxValue.
+func nonNegativeDeltas(values []float64, maxValue float64) []float64 {
+	result := deltas(values)
+	for i, delta := range result {
+		switch {
+		case values[i] > maxValue:
+			result[i] = math.NaN()
+		case delta >= 0 || math.IsNaN(delta):
+		case math.IsNaN(maxValue):
+			result[i] = math.NaN()
+		default:
+			// The counter wrapped around its maximum value
+			result[i] = maxValue + delta + 1
+		}
+	}
+
+	return result
+}
+
+func nonNegativeDerivative(ctx context.Context, fetcher Fetcher, call *Expression) ([]*Series, error) {
+	series, err := firstSeriesArg(ctx, fetcher, call, 1, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	maxValue, err := optionalNumberArg(call, 1, math.NaN())
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*Series, 0, len(series))
+	for _, s := range series {
+		results = append(results, s.withValues(callName(call.Name, s), nonNegativeDeltas(s.Values, maxValue)))
+	}
+
+	return results, nil
+}
+
+func perSecond(ctx context.Context, fetcher Fetcher, call *Expression) ([]*Series, error) {
+	series, err := firstSeriesArg(ctx, fetcher, call, 1, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	maxValue, err := optionalNumberArg(call, 1, math.NaN())
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*Series, 0, len(series))
+	for _, s := range series {
+		seconds := s.Step.Seconds()
+		values := mapValues(nonNegativeDeltas(s.Values, maxValue), func(v float64) float64 {
+			return v / seconds
+		})
+
+		results = append(results, s.withValues(callName(call.Name, s), values))
+	}
+
+	return results, nil
+}
+
+func scale(ctx context.Context, fetcher Fetcher, call *Expression) ([]*Series, error) {
+	series, err := firstSeriesArg(ctx, fetcher, call, 2, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	factor, err := numberArg(call, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*Series, 0, len(series))
+	for _, s := range series {
+		values := mapValues(s.Values, func(v float64) float64 { return v * factor })
+		results = append(results, s.withValues(callName(call.Name, s, formatNumber(factor)), values))
+	}
+
+	return results, nil
+}
+
+func offset(ctx context.Context, fetcher Fetcher, call *Expression) ([]*Series, error) {
+	series, err := firstSeriesArg(ctx, fetcher, call, 2, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := numberArg(call, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*Series, 0, len(series))
+	for _, s := range series {
+		values := mapValues(s.Values, func(v float64) float64 { return v + amount })
+		results = append(results, s.withValues(callName(call.Name, s, formatNumber(amount)), values))
+	}
+
+	return results, nil
+}
+
+// keepLastValue replaces missing values with the last value, for at most
+// limit consecutive steps when a limit is given
+func keepLastValue(ctx context.Context, fetcher Fetcher, call *Expression) ([]*Series, error) {
+	series, err := firstSeriesArg(ctx, fetcher, call, 1, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, err := optionalNumberArg(call, 1, math.Inf(1))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*Series, 0, len(series))
+	for _, s := range series {
+		values := make([]float64, s.Len())
+		last, missing := math.NaN(), 0
+		for i, v := range s.Values {
+			if !math.IsNaN(v) {
+				last, missing = v, 0
+				values[i] = v
+				continue
+			}
+
+			missing++
+			if float64(missing) <= limit {
+				values[i] = last
+			} else {
+				values[i] = v
+			}
+		}
+
+		results = append(results, s.withValues(callName(call.Name, s), values))
+	}
+
+	return results, nil
+}
+
+// transformNull replaces missing values with a default value, zero unless
+// given
+func transformNull(ctx context.Context, fetcher Fetcher, call *Expression) ([]*Series, error) {
+	series, err := firstSeriesArg(ctx, fetcher, call, 1, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultValue, err := optionalNumberArg(call, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*Series, 0, len(series))
+	for _, s := range series {
+		values := make([]float64, s.Len())
+		for i, v := range s.Values {
+			if math.IsNaN(v) {
+				v = defaultValue
+			}
+
+			values[i] = v
+		}
+
+		results = append(results, s.withValues(callName(call.Name, s, formatNumber(defaultValue)), values))
+	}
+
+	return results, nil
+}
+
+func alias(ctx context.Context, fetcher Fetcher, call *Expression) ([]*Series, error) {
+	series, err := firstSeriesArg(ctx, fetcher, call, 2, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := stringArg(call, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*Series, 0, len(series))
+	for _, s := range series {
+		results = append(results, s.withValues(name, s.Values))
+	}
+
+	return results, nil
+}
+
+// aliasByNode names each series after the nodes of its path at the given
+// indexes, negative indexes counting back from the last node
+func aliasByNode(ctx context.Context, fetcher Fetcher, call *Expression) ([]*Series, error) {
+	if len(call.Args) < 2 {
+		return nil, fmt.Errorf("%s expects a series list and at least one node", call.Name)
+	}
+
+	series, err := call.Args[0].Evaluate(ctx, fetcher)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := make([]int, 0, len(call.Args)-1)
+	for i := 1; i < len(call.Args); i++ {
+		index, err := numberArg(call, i)
+		if err != nil {
+			return nil, err
+		}
+
+		indexes = append(indexes, int(index))
+	}
+
+	results := make([]*Series, 0, len(series))
+	for _, s := range series {
+		nodes := strings.Split(seriesPath(s.Name), PathSeparator)
+		aliasNodes := make([]string, 0, len(indexes))
+		for _, index := range indexes {
+			if index < 0 {
+				index += len(nodes)
+			}
+
+			if index >= 0 && index < len(nodes) {
+				aliasNodes = append(aliasNodes, nodes[index])
+			}
+		}
+
+		results = append(results, s.withValues(strings.Join(aliasNodes, PathSeparator), s.Values))
+	}
+
+	return results, nil
+}
+
+// seriesPath returns the path of a series named after the functions applied
+// to it, such as scale(servers.host.cpu,2)
+func seriesPath(name string) string {
+	if idx := strings.LastIndex(name, "("); idx >= 0 {
+		name = name[idx+1:]
+	}
+
+	if idx := strings.IndexAny(name, ",)"); idx >= 0 {
+		name = name[:idx]
+	}
+
+	return name
+}
@@ -0,0 +1,239 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	nan       = math.NaN()
+	testStart = time.Unix(1500000000, 0)
+	testStep  = 10 * time.Second
+)
+
+// testFetcher returns the series of the paths it holds, ignoring globs
+type testFetcher map[string][]*Series
+
+func (f testFetcher) FetchSeries(_ context.Context, path string) ([]*Series, error) {
+	return f[path], nil
+}
+
+func newTestSeries(name string, values ...float64) *Series {
+	return NewSeries(name, testStart, testStep, values)
+}
+
+func newTestFetcher() testFetcher {
+	return testFetcher{
+		"servers.*.cpu": {
+			newTestSeries("servers.host1.cpu", 1, nan, 3, 4),
+			newTestSeries("servers.host2.cpu", 10, nan, nan, -2),
+		},
+		"servers.host3.cpu": {
+			newTestSeries("servers.host3.cpu", 100, 200, nan, 50),
+		},
+		"servers.host4.cpu": {
+			NewSeries("servers.host4.cpu", testStart, time.Minute, []float64{1, 2, 3, 4}),
+		},
+	}
+}
+
+func evaluate(t *testing.T, target string) []*Series {
+	expr, err := Parse(target)
+	require.NoError(t, err)
+	series, err := expr.Evaluate(context.Background(), newTestFetcher())
+	require.NoError(t, err)
+	return series
+}
+
+func assertValues(t *testing.T, expected []float64, actual *Series) {
+	require.Equal(t, len(expected), actual.Len(), actual.Name)
+	for i, v := range expected {
+		if math.IsNaN(v) {
+			assert.True(t, math.IsNaN(actual.Values[i]), "%s: expected NaN at %d, got %v", actual.Name, i, actual.Values[i])
+			continue
+		}
+
+		assert.InDelta(t, v, actual.Values[i], 1e-9, "%s: value at %d", actual.Name, i)
+	}
+}
+
+func TestCombineFunctions(t *testing.T) {
+	tests := []struct {
+		target   string
+		expected []float64
+	}{
+		{"sumSeries(servers.*.cpu, servers.host3.cpu)", []float64{111, 200, 3, 52}},
+		{"sum(servers.*.cpu)", []float64{11, nan, 3, 2}},
+		{"averageSeries(servers.*.cpu, servers.host3.cpu)", []float64{37, 200, 3, 52.0 / 3}},
+		{"avg(servers.*.cpu)", []float64{5.5, nan, 3, 1}},
+		{"minSeries(servers.*.cpu)", []float64{1, nan, 3, -2}},
+		{"maxSeries(servers.*.cpu)", []float64{10, nan, 3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			series := evaluate(t, tt.target)
+			require.Len(t, series, 1)
+			assertValues(t, tt.expected, series[0])
+		})
+	}
+}
+
+func TestCombineFunctionNames(t *testing.T) {
+	series := evaluate(t, "sumSeries(servers.*.cpu, servers.host3.cpu)")
+	require.Len(t, series, 1)
+	assert.Equal(t, "sumSeries(servers.*.cpu,servers.host3.cpu)", series[0].Name)
+	assert.Empty(t, evaluate(t, "sumSeries(servers.missing)"))
+}
+
+func TestCombineDifferentSteps(t *testing.T) {
+	expr, err := Parse("sumSeries(servers.host3.cpu, servers.host4.cpu)")
+	require.NoError(t, err)
+	_, err = expr.Evaluate(context.Background(), newTestFetcher())
+	assert.Error(t, err)
+}
+
+func TestTransformFunctions(t *testing.T) {
+	tests := []struct {
+		target   string
+		names    []string
+		expected [][]float64
+	}{
+		{
+			target:   "scale(servers.*.cpu, 2)",
+			names:    []string{"scale(servers.host1.cpu,2)", "scale(servers.host2.cpu,2)"},
+			expected: [][]float64{{2, nan, 6, 8}, {20, nan, nan, -4}},
+		},
+		{
+			target:   "offset(servers.host3.cpu, -0.5)",
+			names:    []string{"offset(servers.host3.cpu,-0.5)"},
+			expected: [][]float64{{99.5, 199.5, nan, 49.5}},
+		},
+		{
+			target:   "absolute(servers.*.cpu)",
+			names:    []string{"absolute(servers.host1.cpu)", "absolute(servers.host2.cpu)"},
+			expected: [][]float64{{1, nan, 3, 4}, {10, nan, nan, 2}},
+		},
+		{
+			target:   "derivative(servers.host3.cpu)",
+			names:    []string{"derivative(servers.host3.cpu)"},
+			expected: [][]float64{{nan, 100, nan, nan}},
+		},
+		{
+			target:   "nonNegativeDerivative(servers.host4.cpu)",
+			names:    []string{"nonNegativeDerivative(servers.host4.cpu)"},
+			expected: [][]float64{{nan, 1, 1, 1}},
+		},
+		{
+			target:   "nonNegativeDerivative(servers.host3.cpu)",
+			names:    []string{"nonNegativeDerivative(servers.host3.cpu)"},
+			expected: [][]float64{{nan, 100, nan, nan}},
+		},
+		{
+			target:   "perSecond(servers.host4.cpu)",
+			names:    []string{"perSecond(servers.host4.cpu)"},
+			expected: [][]float64{{nan, 1.0 / 60, 1.0 / 60, 1.0 / 60}},
+		},
+		{
+			target:   "keepLastValue(servers.*.cpu)",
+			names:    []string{"keepLastValue(servers.host1.cpu)", "keepLastValue(servers.host2.cpu)"},
+			expected: [][]float64{{1, 1, 3, 4}, {10, 10, 10, -2}},
+		},
+		{
+			target:   "keepLastValue(servers.*.cpu, 1)",
+			names:    []string{"keepLastValue(servers.host1.cpu)", "keepLastValue(servers.host2.cpu)"},
+			expected: [][]float64{{1, 1, 3, 4}, {10, 10, nan, -2}},
+		},
+		{
+			target:   "transformNull(servers.host3.cpu)",
+			names:    []string{"transformNull(servers.host3.cpu,0)"},
+			expected: [][]float64{{100, 200, 0, 50}},
+		},
+		{
+			target:   "transformNull(servers.host3.cpu, -1)",
+			names:    []string{"transformNull(servers.host3.cpu,-1)"},
+			expected: [][]float64{{100, 200, -1, 50}},
+		},
+		{
+			target:   "alias(servers.host3.cpu, 'cpu')",
+			names:    []string{"cpu"},
+			expected: [][]float64{{100, 200, nan, 50}},
+		},
+		{
+			target:   "aliasByNode(scale(servers.*.cpu, 2), 1, -1)",
+			names:    []string{"host1.cpu", "host2.cpu"},
+			expected: [][]float64{{2, nan, 6, 8}, {20, nan, nan, -4}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			series := evaluate(t, tt.target)
+			require.Len(t, series, len(tt.names))
+			for i, s := range series {
+				assert.Equal(t, tt.names[i], s.Name)
+				assert.Equal(t, testStart, s.Start)
+				assertValues(t, tt.expected[i], s)
+			}
+		})
+	}
+}
+
+func TestNonNegativeDerivativeMaxValue(t *testing.T) {
+	// The counter wraps around from 255 to 0, and values past the maximum
+	// are dropped
+	fetcher := testFetcher{"counter": {newTestSeries("counter", 250, 254, 2, 300)}}
+	expr, err := Parse("nonNegativeDerivative(counter, 255)")
+	require.NoError(t, err)
+	series, err := expr.Evaluate(context.Background(), fetcher)
+	require.NoError(t, err)
+	require.Len(t, series, 1)
+	assertValues(t, []float64{nan, 4, 4, nan}, series[0])
+}
+
+func TestEvaluateInvalid(t *testing.T) {
+	targets := []string{
+		"unknownFunction(servers.*.cpu)",
+		"scale(servers.*.cpu)",
+		"scale(servers.*.cpu, 'two')",
+		"alias(servers.*.cpu, 2)",
+		"aliasByNode(servers.*.cpu)",
+		"sumSeries()",
+		"sumSeries(1)",
+		"transformNull(servers.*.cpu, 0, 1)",
+	}
+
+	for _, target := range targets {
+		t.Run(target, func(t *testing.T) {
+			expr, err := Parse(target)
+			require.NoError(t, err)
+			_, err = expr.Evaluate(context.Background(), newTestFetcher())
+			assert.Error(t, err)
+		})
+	}
+}
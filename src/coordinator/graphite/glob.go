@@ -0,0 +1,160 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+)
+
+// anyValuePattern matches any node, it is used to look for series having a
+// node at a given depth
+const anyValuePattern = ".*"
+
+// IsGlob returns true if the Graphite path or node contains glob characters
+func IsGlob(s string) bool {
+	return strings.ContainsAny(s, "*?[{")
+}
+
+// GlobToRegexPattern converts a glob matching a single node of a Graphite
+// path into a regular expression. Globs support * and ? wildcards, character
+// classes such as [a-z] or [!0-9], and alternatives such as {foo,bar*}.
+func GlobToRegexPattern(glob string) (string, error) {
+	var (
+		pattern    bytes.Buffer
+		braceDepth int
+		inClass    bool
+	)
+
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		if inClass {
+			switch c {
+			case ']':
+				inClass = false
+				pattern.WriteByte(c)
+			case '\\':
+				pattern.WriteString(`\\`)
+			default:
+				pattern.WriteByte(c)
+			}
+
+			continue
+		}
+
+		switch c {
+		case '*':
+			pattern.WriteString(".*")
+		case '?':
+			pattern.WriteByte('.')
+		case '[':
+			inClass = true
+			pattern.WriteByte('[')
+			if i+1 < len(glob) && glob[i+1] == '!' {
+				pattern.WriteByte('^')
+				i++
+			}
+		case '{':
+			braceDepth++
+			pattern.WriteString("(")
+		case '}':
+			if braceDepth == 0 {
+				return "", fmt.Errorf("unbalanced '}' in glob %q", glob)
+			}
+
+			braceDepth--
+			pattern.WriteString(")")
+		case ',':
+			if braceDepth > 0 {
+				pattern.WriteByte('|')
+			} else {
+				pattern.WriteByte(',')
+			}
+		default:
+			pattern.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	if inClass {
+		return "", fmt.Errorf("unterminated character class in glob %q", glob)
+	}
+
+	if braceDepth > 0 {
+		return "", fmt.Errorf("unbalanced '{' in glob %q", glob)
+	}
+
+	return pattern.String(), nil
+}
+
+// MatchersForPath returns the tag matchers selecting the series whose paths
+// match a Graphite path, which may hold globs in any of its nodes. Unless
+// exact is false, series with more nodes than the path are not selected.
+func MatchersForPath(path string, exact bool) (models.Matchers, error) {
+	if path == "" {
+		return nil, errEmptyPath
+	}
+
+	nodes := strings.Split(path, PathSeparator)
+	matchers := make(models.Matchers, 0, len(nodes)+1)
+	for i, node := range nodes {
+		if node == "" {
+			return nil, fmt.Errorf("empty node in graphite path %q", path)
+		}
+
+		var (
+			matcher *models.Matcher
+			err     error
+		)
+
+		if IsGlob(node) {
+			pattern, patternErr := GlobToRegexPattern(node)
+			if patternErr != nil {
+				return nil, patternErr
+			}
+
+			matcher, err = models.NewMatcher(models.MatchRegexp, TagName(i), pattern)
+		} else {
+			matcher, err = models.NewMatcher(models.MatchEqual, TagName(i), node)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		matchers = append(matchers, matcher)
+	}
+
+	if exact {
+		// Leave out the series having a node past the end of the path
+		matcher, err := models.NewMatcher(models.MatchNotRegexp, TagName(len(nodes)), anyValuePattern)
+		if err != nil {
+			return nil, err
+		}
+
+		matchers = append(matchers, matcher)
+	}
+
+	return matchers, nil
+}
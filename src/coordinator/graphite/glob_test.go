@@ -0,0 +1,93 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"testing"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobToRegexPattern(t *testing.T) {
+	tests := []struct {
+		glob    string
+		pattern string
+	}{
+		{"cpu", "cpu"},
+		{"cpu*", "cpu.*"},
+		{"c?u", "c.u"},
+		{"host[1-3]", "host[1-3]"},
+		{"host[!0-9]", "host[^0-9]"},
+		{"{user,sys*}", "(user|sys.*)"},
+		{"a+b|c", `a\+b\|c`},
+		{"a,b", "a,b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.glob, func(t *testing.T) {
+			pattern, err := GlobToRegexPattern(tt.glob)
+			require.NoError(t, err)
+			assert.Equal(t, tt.pattern, pattern)
+		})
+	}
+}
+
+func TestGlobToRegexPatternInvalid(t *testing.T) {
+	for _, glob := range []string{"{user,sys", "user}", "host[1-3"} {
+		_, err := GlobToRegexPattern(glob)
+		assert.Error(t, err, glob)
+	}
+}
+
+func TestMatchersForPath(t *testing.T) {
+	matchers, err := MatchersForPath("servers.host[12].{user,sys}", true)
+	require.NoError(t, err)
+	require.Len(t, matchers, 4)
+
+	assert.Equal(t, models.MatchEqual, matchers[0].Type)
+	assert.Equal(t, "__g0__", matchers[0].Name)
+	assert.Equal(t, "servers", matchers[0].Value)
+
+	assert.Equal(t, models.MatchRegexp, matchers[1].Type)
+	assert.True(t, matchers[1].Matches("host1"))
+	assert.False(t, matchers[1].Matches("host3"))
+
+	assert.True(t, matchers[2].Matches("sys"))
+	assert.False(t, matchers[2].Matches("idle"))
+
+	// Series with deeper paths are left out
+	assert.Equal(t, models.MatchNotRegexp, matchers[3].Type)
+	assert.Equal(t, "__g3__", matchers[3].Name)
+
+	matchers, err = MatchersForPath("servers.*", false)
+	require.NoError(t, err)
+	assert.Len(t, matchers, 2)
+}
+
+func TestMatchersForPathInvalid(t *testing.T) {
+	for _, path := range []string{"", "servers..cpu", "servers.{a"} {
+		_, err := MatchersForPath(path, true)
+		assert.Error(t, err, path)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"math"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/ts"
+)
+
+// Series is a Graphite series, holding a value for each fixed size step from
+// its start with NaN for the steps without any datapoints
+type Series struct {
+	Name   string
+	Start  time.Time
+	Step   time.Duration
+	Values []float64
+}
+
+// NewSeries creates a new series
+func NewSeries(name string, start time.Time, step time.Duration, values []float64) *Series {
+	return &Series{
+		Name:   name,
+		Start:  start,
+		Step:   step,
+		Values: values,
+	}
+}
+
+// NewConsolidatedSeries creates a series averaging the datapoints within each
+// step between start and end, which is how Graphite consolidates by default
+func NewConsolidatedSeries(
+	name string,
+	datapoints ts.Values,
+	start, end time.Time,
+	step time.Duration,
+) *Series {
+	steps := NumSteps(start, end, step)
+	sums := make([]float64, steps)
+	counts := make([]int, steps)
+	for i := 0; i < datapoints.Len(); i++ {
+		dp := datapoints.DatapointAt(i)
+		if dp.Timestamp.Before(start) || math.IsNaN(dp.Value) {
+			continue
+		}
+
+		idx := int(dp.Timestamp.Sub(start) / step)
+		if idx >= steps {
+			continue
+		}
+
+		sums[idx] += dp.Value
+		counts[idx]++
+	}
+
+	values := make([]float64, steps)
+	for i := range values {
+		if counts[i] == 0 {
+			values[i] = math.NaN()
+			continue
+		}
+
+		values[i] = sums[i] / float64(counts[i])
+	}
+
+	return NewSeries(name, start, step, values)
+}
+
+// NumSteps returns the number of steps needed to cover the time from start
+// up to end
+func NumSteps(start, end time.Time, step time.Duration) int {
+	if !end.After(start) {
+		return 0
+	}
+
+	return int((end.Sub(start) + step - 1) / step)
+}
+
+// Len returns the number of steps of the series
+func (s *Series) Len() int {
+	return len(s.Values)
+}
+
+// Timestamp returns the start of the step at the given index
+func (s *Series) Timestamp(i int) time.Time {
+	return s.Start.Add(time.Duration(i) * s.Step)
+}
+
+// withValues returns a copy of the series holding the given values
+func (s *Series) withValues(name string, values []float64) *Series {
+	return NewSeries(name, s.Start, s.Step, values)
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/ts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConsolidatedSeries(t *testing.T) {
+	start := time.Unix(1500000000, 0)
+	datapoints := ts.Datapoints{
+		{Timestamp: start.Add(-time.Second), Value: 100},
+		{Timestamp: start, Value: 1},
+		{Timestamp: start.Add(5 * time.Second), Value: 3},
+		{Timestamp: start.Add(25 * time.Second), Value: 5},
+		{Timestamp: start.Add(35 * time.Second), Value: math.NaN()},
+		{Timestamp: start.Add(40 * time.Second), Value: 100},
+	}
+
+	s := NewConsolidatedSeries("a.b", datapoints, start, start.Add(40*time.Second), 10*time.Second)
+	assert.Equal(t, "a.b", s.Name)
+	require.Equal(t, 4, s.Len())
+	assert.Equal(t, 2.0, s.Values[0])
+	assert.True(t, math.IsNaN(s.Values[1]))
+	assert.Equal(t, 5.0, s.Values[2])
+	assert.True(t, math.IsNaN(s.Values[3]))
+	assert.Equal(t, start.Add(20*time.Second), s.Timestamp(2))
+}
+
+func TestNumSteps(t *testing.T) {
+	start := time.Unix(0, 0)
+	assert.Equal(t, 0, NumSteps(start, start, time.Second))
+	assert.Equal(t, 1, NumSteps(start, start.Add(time.Millisecond), time.Second))
+	assert.Equal(t, 6, NumSteps(start, start.Add(time.Minute), 10*time.Second))
+}
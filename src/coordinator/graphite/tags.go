@@ -0,0 +1,104 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+)
+
+const (
+	// PathSeparator separates the nodes of a Graphite path
+	PathSeparator = "."
+
+	// maxPrecomputedTags is the number of path nodes whose tag names are
+	// computed ahead of time, deeper nodes format their names when needed
+	maxPrecomputedTags = 64
+)
+
+var (
+	errEmptyPath = errors.New("empty graphite path")
+
+	precomputedTagNames = func() []string {
+		names := make([]string, maxPrecomputedTags)
+		for i := range names {
+			names[i] = formatTagName(i)
+		}
+
+		return names
+	}()
+)
+
+func formatTagName(i int) string {
+	return fmt.Sprintf("__g%d__", i)
+}
+
+// TagName returns the name of the tag holding the node of a Graphite path at
+// the given index, __g0__ for the first node, __g1__ for the second and so on
+func TagName(i int) string {
+	if i < maxPrecomputedTags {
+		return precomputedTagNames[i]
+	}
+
+	return formatTagName(i)
+}
+
+// PathToTags maps the nodes of a dotted Graphite path to tags
+func PathToTags(path string) (models.Tags, error) {
+	if path == "" {
+		return nil, errEmptyPath
+	}
+
+	nodes := strings.Split(path, PathSeparator)
+	tags := make(models.Tags, len(nodes))
+	for i, node := range nodes {
+		if node == "" {
+			return nil, fmt.Errorf("empty node in graphite path %q", path)
+		}
+
+		tags[TagName(i)] = node
+	}
+
+	return tags, nil
+}
+
+// TagsToPath joins the nodes held in the tags of a series back into its
+// Graphite path, returning false if the tags do not describe a path
+func TagsToPath(tags models.Tags) (string, bool) {
+	var nodes []string
+	for i := 0; ; i++ {
+		node, ok := tags[TagName(i)]
+		if !ok {
+			break
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	if len(nodes) == 0 {
+		return "", false
+	}
+
+	return strings.Join(nodes, PathSeparator), true
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"testing"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagName(t *testing.T) {
+	assert.Equal(t, "__g0__", TagName(0))
+	assert.Equal(t, "__g12__", TagName(12))
+	assert.Equal(t, "__g100__", TagName(100))
+}
+
+func TestPathToTags(t *testing.T) {
+	tags, err := PathToTags("servers.host1.cpu")
+	require.NoError(t, err)
+	assert.Equal(t, models.Tags{"__g0__": "servers", "__g1__": "host1", "__g2__": "cpu"}, tags)
+
+	path, ok := TagsToPath(tags)
+	require.True(t, ok)
+	assert.Equal(t, "servers.host1.cpu", path)
+}
+
+func TestPathToTagsInvalid(t *testing.T) {
+	for _, path := range []string{"", "servers..cpu", ".servers", "servers."} {
+		_, err := PathToTags(path)
+		assert.Error(t, err, path)
+	}
+}
+
+func TestTagsToPathNotGraphite(t *testing.T) {
+	_, ok := TagsToPath(models.Tags{"__name__": "up", "__g1__": "host1"})
+	assert.False(t, ok)
+}
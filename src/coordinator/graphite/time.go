@@ -0,0 +1,142 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	nowValue = "now"
+
+	day = 24 * time.Hour
+)
+
+var (
+	// offsetUnits are the units of relative times, Graphite treats a month
+	// as 30 days and a year as 365 days
+	offsetUnits = map[string]time.Duration{
+		"s":       time.Second,
+		"sec":     time.Second,
+		"secs":    time.Second,
+		"second":  time.Second,
+		"seconds": time.Second,
+		"min":     time.Minute,
+		"mins":    time.Minute,
+		"minute":  time.Minute,
+		"minutes": time.Minute,
+		"h":       time.Hour,
+		"hour":    time.Hour,
+		"hours":   time.Hour,
+		"d":       day,
+		"day":     day,
+		"days":    day,
+		"w":       7 * day,
+		"week":    7 * day,
+		"weeks":   7 * day,
+		"mon":     30 * day,
+		"month":   30 * day,
+		"months":  30 * day,
+		"y":       365 * day,
+		"year":    365 * day,
+		"years":   365 * day,
+	}
+
+	absoluteTimeFormats = []string{
+		"15:04_20060102",
+		"20060102",
+	}
+)
+
+// ParseTime parses the from and until times of Graphite requests, which are
+// either "now", a time relative to now such as "-1h" or "now-30min", a unix
+// timestamp in seconds, or an absolute time formatted as HH:MM_YYYYMMDD or
+// YYYYMMDD in UTC
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == nowValue {
+		return now, nil
+	}
+
+	if strings.HasPrefix(s, nowValue) {
+		offset, err := ParseOffset(strings.TrimPrefix(s, nowValue))
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		return now.Add(offset), nil
+	}
+
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		offset, err := ParseOffset(s)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		return now.Add(offset), nil
+	}
+
+	// Absolute times formatted as YYYYMMDD are also valid integers, but
+	// timestamps of that magnitude are from 1970 so prefer the date
+	for _, format := range absoluteTimeFormats {
+		if t, err := time.Parse(format, s); err == nil {
+			return t, nil
+		}
+	}
+
+	if seconds, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+
+	return time.Time{}, fmt.Errorf("cannot parse %q to a valid graphite time", s)
+}
+
+// ParseOffset parses a signed offset such as "-1h" or "+30min"
+func ParseOffset(s string) (time.Duration, error) {
+	if len(s) < 2 || (s[0] != '-' && s[0] != '+') {
+		return 0, fmt.Errorf("invalid graphite offset %q", s)
+	}
+
+	sign := time.Duration(1)
+	if s[0] == '-' {
+		sign = -1
+	}
+
+	digits := 1
+	for digits < len(s) && s[digits] >= '0' && s[digits] <= '9' {
+		digits++
+	}
+
+	count, err := strconv.Atoi(s[1:digits])
+	if err != nil {
+		return 0, fmt.Errorf("invalid graphite offset %q", s)
+	}
+
+	unit, ok := offsetUnits[s[digits:]]
+	if !ok {
+		return 0, fmt.Errorf("invalid unit in graphite offset %q", s)
+	}
+
+	return sign * time.Duration(count) * unit, nil
+}
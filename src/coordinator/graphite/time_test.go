@@ -0,0 +1,62 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTime(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	tests := []struct {
+		value    string
+		expected time.Time
+	}{
+		{"now", now},
+		{"-1h", now.Add(-time.Hour)},
+		{"-30min", now.Add(-30 * time.Minute)},
+		{"-2days", now.Add(-48 * time.Hour)},
+		{"+10s", now.Add(10 * time.Second)},
+		{"now-1w", now.Add(-7 * 24 * time.Hour)},
+		{"1400000000", time.Unix(1400000000, 0)},
+		{"20170102", time.Date(2017, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"13:45_20170102", time.Date(2017, 1, 2, 13, 45, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			parsed, err := ParseTime(tt.value, now)
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Equal(parsed), "expected %v, got %v", tt.expected, parsed)
+		})
+	}
+}
+
+func TestParseTimeInvalid(t *testing.T) {
+	for _, value := range []string{"", "yesterday", "-1", "-h", "-1fortnight", "now+"} {
+		_, err := ParseTime(value, time.Now())
+		assert.Error(t, err, value)
+	}
+}
@@ -26,6 +26,9 @@ import (
 	"sort"
 )
 
+// MetricName is the name of the tag which holds the metric name
+const MetricName = "__name__"
+
 // Tags is a key/value map of metric tags.
 type Tags map[string]string
 
@@ -147,3 +150,13 @@ func (t Tags) ID() string {
 
 	return b
 }
+
+// Clone returns a copy of the tags
+func (t Tags) Clone() Tags {
+	cloned := make(Tags, len(t))
+	for k, v := range t {
+		cloned[k] = v
+	}
+
+	return cloned
+}
@@ -44,16 +44,47 @@ func Parse(q string) (parser.Parser, error) {
 }
 
 func (p *promParser) DAG() (parser.Nodes, parser.Edges, error) {
-	return walk(p.expr)
+	state := &parseState{}
+	if err := state.walk(p.expr); err != nil {
+		return nil, nil, err
+	}
+
+	return state.transforms, state.edges, nil
 }
 
 func (p *promParser) String() string {
 	return p.expr.String()
 }
 
-func walk(node pql.Node) (parser.Nodes, parser.Edges, error) {
+// parseState accumulates the transforms and edges of the DAG while walking
+// the expression tree. Transform IDs are assigned in the order that nodes are
+// completed, so parents always have a lower ID than their children.
+type parseState struct {
+	transforms parser.Nodes
+	edges      parser.Edges
+}
+
+// lastID returns the ID of the most recently added transform
+func (p *parseState) lastID() parser.NodeID {
+	return p.transforms[len(p.transforms)-1].ID
+}
+
+// addTransform adds the operation to the DAG, with edges from each given parent
+func (p *parseState) addTransform(op parser.Params, parents ...parser.NodeID) {
+	opTransform := parser.NewTransformFromOperation(op, len(p.transforms))
+	for _, parent := range parents {
+		p.edges = append(p.edges, parser.Edge{
+			ParentID: parent,
+			ChildID:  opTransform.ID,
+		})
+	}
+
+	p.transforms = append(p.transforms, opTransform)
+}
+
+func (p *parseState) walk(node pql.Node) error {
 	if node == nil {
-		return nil, nil, nil
+		return nil
 	}
 
 	switch n := node.(type) {
@@ -66,41 +97,104 @@ func walk(node pql.Node) (parser.Nodes, parser.Edges, error) {
 
 	case pql.Expressions:
 	case *pql.AggregateExpr:
-		transforms, edges, err := walk(n.Expr)
+		if err := p.walk(n.Expr); err != nil {
+			return err
+		}
+
+		op, err := NewAggregationOperator(n)
 		if err != nil {
-			return nil, nil, err
+			return err
 		}
 
-		opTransform := parser.NewTransformFromOperation(NewOperator(n.Op), len(transforms))
-		edges = append(edges, parser.Edge{
-			ParentID: transforms[len(transforms)-1].ID,
-			ChildID:  opTransform.ID,
-		})
-		transforms = append(transforms, opTransform)
-		// TODO: handle labels, params
-		return transforms, edges, nil
+		p.addTransform(op, p.lastID())
+		return nil
+
 	case *pql.BinaryExpr:
+		return p.walkBinary(n)
 
 	case *pql.Call:
+		op, argExpr, err := NewFunctionExpr(n)
+		if err != nil {
+			return err
+		}
+
+		if err := p.walk(argExpr); err != nil {
+			return err
+		}
+
+		p.addTransform(op, p.lastID())
+		return nil
 
 	case *pql.ParenExpr:
+		return p.walk(n.Expr)
 
 	case *pql.UnaryExpr:
 
 	case *pql.MatrixSelector:
-		operation := NewSelectorFromMatrix(n)
-		return []parser.Node{parser.NewTransformFromOperation(operation, 0)}, nil, nil
+		operation, err := NewSelectorFromMatrix(n)
+		if err != nil {
+			return err
+		}
+
+		p.addTransform(operation)
+		return nil
 
 	case *pql.VectorSelector:
-		operation := NewSelectorFromVector(n)
-		return []parser.Node{parser.NewTransformFromOperation(operation, 0)}, nil, nil
+		operation, err := NewSelectorFromVector(n)
+		if err != nil {
+			return err
+		}
+
+		p.addTransform(operation)
+		return nil
 
 	case *pql.NumberLiteral, *pql.StringLiteral:
 
 	default:
-		return nil, nil, fmt.Errorf("promql.Walk: unhandled node type %T", node)
+		return fmt.Errorf("promql.Walk: unhandled node type %T", node)
 	}
 
 	// TODO: This should go away once all cases have been implemented
-	return nil, nil, errors.ErrNotImplemented
+	return errors.ErrNotImplemented
+}
+
+// walkBinary walks both sides of a binary expression. Number literal operands
+// are folded into the binary operation rather than added to the DAG.
+func (p *parseState) walkBinary(n *pql.BinaryExpr) error {
+	lhs, err := p.walkOperand(n.LHS)
+	if err != nil {
+		return err
+	}
+
+	rhs, err := p.walkOperand(n.RHS)
+	if err != nil {
+		return err
+	}
+
+	op, err := NewBinaryOperator(n, lhs, rhs)
+	if err != nil {
+		return err
+	}
+
+	var parents []parser.NodeID
+	for _, operand := range []binaryOperand{lhs, rhs} {
+		if !operand.isScalar {
+			parents = append(parents, operand.id)
+		}
+	}
+
+	p.addTransform(op, parents...)
+	return nil
+}
+
+func (p *parseState) walkOperand(expr pql.Expr) (binaryOperand, error) {
+	if literal, ok := unwrapParenExpr(expr).(*pql.NumberLiteral); ok {
+		return binaryOperand{isScalar: true, scalar: literal.Val}, nil
+	}
+
+	if err := p.walk(expr); err != nil {
+		return binaryOperand{}, err
+	}
+
+	return binaryOperand{id: p.lastID()}, nil
 }
@@ -22,8 +22,13 @@ package promql
 
 import (
 	"testing"
+	"time"
 
 	"github.com/m3db/m3db/src/coordinator/functions"
+	"github.com/m3db/m3db/src/coordinator/functions/aggregation"
+	"github.com/m3db/m3db/src/coordinator/functions/binary"
+	"github.com/m3db/m3db/src/coordinator/functions/temporal"
+	"github.com/m3db/m3db/src/coordinator/models"
 	"github.com/m3db/m3db/src/coordinator/parser"
 
 	"github.com/stretchr/testify/assert"
@@ -43,5 +48,213 @@ func TestDAG(t *testing.T) {
 	assert.Len(t, edges, 1)
 	assert.Equal(t, edges[0].ParentID, parser.NodeID("0"), "fetch should be the parent")
 	assert.Equal(t, edges[0].ChildID, parser.NodeID("1"), "aggregation should be the child")
+}
+
+func TestMatchers(t *testing.T) {
+	q := "http_requests_total{method=\"GET\", code!=\"200\", service=~\"foo.*\", host!~\"bar.+\"} offset 5m"
+	p, err := Parse(q)
+	require.NoError(t, err)
+	transforms, _, err := p.DAG()
+	require.NoError(t, err)
+	require.Len(t, transforms, 1)
+
+	fetch, ok := transforms[0].Op.(functions.FetchOp)
+	require.True(t, ok)
+	assert.Equal(t, 5*time.Minute, fetch.Offset)
+
+	matchers := make(map[string]*models.Matcher, len(fetch.Matchers))
+	for _, m := range fetch.Matchers {
+		matchers[m.Name] = m
+	}
+
+	require.Len(t, matchers, 5)
+	assert.Equal(t, models.MatchEqual, matchers["__name__"].Type)
+	assert.Equal(t, "http_requests_total", matchers["__name__"].Value)
+	assert.Equal(t, models.MatchEqual, matchers["method"].Type)
+	assert.Equal(t, models.MatchNotEqual, matchers["code"].Type)
+	assert.Equal(t, models.MatchRegexp, matchers["service"].Type)
+	assert.True(t, matchers["service"].Matches("foobar"))
+	assert.Equal(t, models.MatchNotRegexp, matchers["host"].Type)
+	assert.True(t, matchers["host"].Matches("baz"))
+}
+
+func TestMatrixMatchers(t *testing.T) {
+	p, err := Parse("http_requests_total{method=\"GET\"}[5m]")
+	require.NoError(t, err)
+	transforms, _, err := p.DAG()
+	require.NoError(t, err)
+	require.Len(t, transforms, 1)
+
+	fetch, ok := transforms[0].Op.(functions.FetchOp)
+	require.True(t, ok)
+	assert.Equal(t, 5*time.Minute, fetch.Range)
+	assert.Len(t, fetch.Matchers, 2)
+}
+
+func TestAggregations(t *testing.T) {
+	tests := []struct {
+		q      string
+		opType string
+	}{
+		{"sum(up) by (service)", aggregation.SumType},
+		{"avg(up) without (service)", aggregation.AverageType},
+		{"min(up)", aggregation.MinType},
+		{"max(up)", aggregation.MaxType},
+		{"stddev(up)", aggregation.StandardDeviationType},
+		{"stdvar(up)", aggregation.StandardVarianceType},
+		{"count(up)", aggregation.CountType},
+		{"topk(3, up)", aggregation.TopKType},
+		{"bottomk((3), up)", aggregation.BottomKType},
+		{"quantile(0.9, up)", aggregation.QuantileType},
+		{"count_values(\"value\", up)", aggregation.CountValuesType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.q, func(t *testing.T) {
+			p, err := Parse(tt.q)
+			require.NoError(t, err)
+			transforms, edges, err := p.DAG()
+			require.NoError(t, err)
+			require.Len(t, transforms, 2)
+			assert.Equal(t, functions.FetchType, transforms[0].Op.OpType())
+			assert.Equal(t, tt.opType, transforms[1].Op.OpType())
+			assert.Len(t, edges, 1)
+		})
+	}
+}
+
+func TestRangeFunctions(t *testing.T) {
+	tests := []struct {
+		q      string
+		opType string
+	}{
+		{"rate(http_requests_total[5m])", temporal.RateType},
+		{"irate(http_requests_total[5m])", temporal.IRateType},
+		{"increase(http_requests_total[5m])", temporal.IncreaseType},
+		{"delta(cpu_temp[5m])", temporal.DeltaType},
+		{"idelta(cpu_temp[5m])", temporal.IDeltaType},
+		{"resets(http_requests_total[5m])", temporal.ResetsType},
+		{"changes(cpu_temp[5m])", temporal.ChangesType},
+		{"avg_over_time(cpu_temp[5m])", temporal.AvgOverTimeType},
+		{"quantile_over_time(0.9, cpu_temp[5m])", temporal.QuantileOverTimeType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.q, func(t *testing.T) {
+			p, err := Parse(tt.q)
+			require.NoError(t, err)
+			transforms, edges, err := p.DAG()
+			require.NoError(t, err)
+			require.Len(t, transforms, 2)
+			assert.Equal(t, functions.FetchType, transforms[0].Op.OpType())
+			assert.Equal(t, tt.opType, transforms[1].Op.OpType())
+			assert.Len(t, edges, 1)
+		})
+	}
+}
+
+func TestUnsupportedFunction(t *testing.T) {
+	p, err := Parse("abs(cpu_temp)")
+	require.NoError(t, err)
+	_, _, err = p.DAG()
+	assert.Error(t, err)
+}
+
+func TestBinaryVectors(t *testing.T) {
+	tests := []struct {
+		q      string
+		opType string
+	}{
+		{"up + down", binary.PlusType},
+		{"up - on(a) down", binary.MinusType},
+		{"up * ignoring(a) group_left(b) down", binary.MultiplyType},
+		{"up / on(a) group_right down", binary.DivType},
+		{"up % down", binary.ModType},
+		{"up ^ down", binary.ExpType},
+		{"up == down", binary.EqType},
+		{"up != bool down", binary.NotEqType},
+		{"up > down", binary.GreaterType},
+		{"up < down", binary.LesserType},
+		{"up >= down", binary.GreaterEqType},
+		{"up <= down", binary.LesserEqType},
+		{"up and down", binary.AndType},
+		{"up or on(a) down", binary.OrType},
+		{"up unless down", binary.UnlessType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.q, func(t *testing.T) {
+			p, err := Parse(tt.q)
+			require.NoError(t, err)
+			transforms, edges, err := p.DAG()
+			require.NoError(t, err)
+			require.Len(t, transforms, 3)
+			assert.Equal(t, functions.FetchType, transforms[0].Op.OpType())
+			assert.Equal(t, functions.FetchType, transforms[1].Op.OpType())
+			assert.Equal(t, tt.opType, transforms[2].Op.OpType())
+			require.Len(t, edges, 2)
+			assert.Equal(t, parser.NodeID("0"), edges[0].ParentID)
+			assert.Equal(t, parser.NodeID("1"), edges[1].ParentID)
+			assert.Equal(t, parser.NodeID("2"), edges[0].ChildID)
+			assert.Equal(t, parser.NodeID("2"), edges[1].ChildID)
+		})
+	}
+}
+
+func TestBinaryScalars(t *testing.T) {
+	for _, q := range []string{"up * 2", "2 - up", "up > (1)", "sum(up) / 10"} {
+		t.Run(q, func(t *testing.T) {
+			p, err := Parse(q)
+			require.NoError(t, err)
+			transforms, edges, err := p.DAG()
+			require.NoError(t, err)
+			assert.Equal(t, len(transforms)-1, len(edges))
+			last := transforms[len(transforms)-1]
+			assert.Equal(t, last.ID, edges[len(edges)-1].ChildID)
+			assert.Equal(t, transforms[len(transforms)-2].ID, edges[len(edges)-1].ParentID)
+		})
+	}
+}
+
+func TestNestedBinary(t *testing.T) {
+	p, err := Parse("rate(up[5m]) / on(a) sum by (a) (down) + 1")
+	require.NoError(t, err)
+	transforms, edges, err := p.DAG()
+	require.NoError(t, err)
+	require.Len(t, transforms, 6)
+	assert.Equal(t, temporal.RateType, transforms[1].Op.OpType())
+	assert.Equal(t, aggregation.SumType, transforms[3].Op.OpType())
+	assert.Equal(t, binary.DivType, transforms[4].Op.OpType())
+	assert.Equal(t, binary.PlusType, transforms[5].Op.OpType())
+	assert.Len(t, edges, 5)
+}
+
+func TestBinaryErrors(t *testing.T) {
+	for _, q := range []string{"1 + 2", "(1) * 2"} {
+		t.Run(q, func(t *testing.T) {
+			p, err := Parse(q)
+			require.NoError(t, err)
+			_, _, err = p.DAG()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseMatchers(t *testing.T) {
+	matchers, err := ParseMatchers(`http_requests_total{job=~"api.*",code!="500"}`)
+	require.NoError(t, err)
+	require.Len(t, matchers, 3)
+
+	byName := make(map[string]*models.Matcher, len(matchers))
+	for _, m := range matchers {
+		byName[m.Name] = m
+	}
+
+	assert.Equal(t, models.MatchEqual, byName[models.MetricName].Type)
+	assert.Equal(t, "http_requests_total", byName[models.MetricName].Value)
+	assert.Equal(t, models.MatchRegexp, byName["job"].Type)
+	assert.Equal(t, models.MatchNotEqual, byName["code"].Type)
 
+	_, err = ParseMatchers("rate(foo[5m])")
+	assert.Error(t, err)
 }
@@ -21,40 +21,313 @@
 package promql
 
 import (
+	"fmt"
+
 	"github.com/m3db/m3db/src/coordinator/functions"
+	"github.com/m3db/m3db/src/coordinator/functions/aggregation"
+	"github.com/m3db/m3db/src/coordinator/functions/binary"
+	"github.com/m3db/m3db/src/coordinator/functions/temporal"
+	"github.com/m3db/m3db/src/coordinator/models"
 	"github.com/m3db/m3db/src/coordinator/parser"
 	"github.com/m3db/m3db/src/coordinator/parser/common"
 
+	"github.com/prometheus/prometheus/pkg/labels"
 	"github.com/prometheus/prometheus/promql"
 )
 
 // NewSelectorFromVector creates a new fetchop
-func NewSelectorFromVector(n *promql.VectorSelector) parser.Params {
-	// TODO: convert n.LabelMatchers to Matchers
-	return functions.FetchOp{Name: n.Name, Offset: n.Offset, Matchers: nil}
+func NewSelectorFromVector(n *promql.VectorSelector) (parser.Params, error) {
+	matchers, err := labelMatchersToModelMatcher(n.LabelMatchers)
+	if err != nil {
+		return nil, err
+	}
+
+	return functions.FetchOp{Name: n.Name, Offset: n.Offset, Matchers: matchers}, nil
 }
 
 // NewSelectorFromMatrix creates a new fetchop
-func NewSelectorFromMatrix(n *promql.MatrixSelector) parser.Params {
-	// TODO: convert n.LabelMatchers to Matchers
-	return functions.FetchOp{Name: n.Name, Offset: n.Offset, Matchers: nil, Range: n.Range}
+func NewSelectorFromMatrix(n *promql.MatrixSelector) (parser.Params, error) {
+	matchers, err := labelMatchersToModelMatcher(n.LabelMatchers)
+	if err != nil {
+		return nil, err
+	}
+
+	return functions.FetchOp{Name: n.Name, Offset: n.Offset, Matchers: matchers, Range: n.Range}, nil
+}
+
+// ParseMatchers parses a series selector, such as those given to the
+// Prometheus series API, into matchers
+func ParseMatchers(selector string) (models.Matchers, error) {
+	labelMatchers, err := promql.ParseMetricSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	return labelMatchersToModelMatcher(labelMatchers)
+}
+
+// labelMatchersToModelMatcher converts prometheus label matchers, including
+// the implicit __name__ matcher, to coordinator matchers
+func labelMatchersToModelMatcher(lMatchers []*labels.Matcher) (models.Matchers, error) {
+	matchers := make(models.Matchers, 0, len(lMatchers))
+	for _, m := range lMatchers {
+		matchType, err := promTypeToM3(m.Type)
+		if err != nil {
+			return nil, err
+		}
+
+		match, err := models.NewMatcher(matchType, m.Name, m.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		matchers = append(matchers, match)
+	}
+
+	return matchers, nil
+}
+
+// promTypeToM3 converts a prometheus label type to m3 matcher type
+func promTypeToM3(labelType labels.MatchType) (models.MatchType, error) {
+	switch labelType {
+	case labels.MatchEqual:
+		return models.MatchEqual, nil
+	case labels.MatchNotEqual:
+		return models.MatchNotEqual, nil
+	case labels.MatchRegexp:
+		return models.MatchRegexp, nil
+	case labels.MatchNotRegexp:
+		return models.MatchNotRegexp, nil
+
+	default:
+		return 0, fmt.Errorf("unknown match type %v", labelType)
+	}
+}
+
+// NewAggregationOperator creates a new aggregation operator based on the type
+func NewAggregationOperator(expr *promql.AggregateExpr) (parser.Params, error) {
+	opType := getAggOpType(expr.Op)
+	nodeInformation := aggregation.NodeParams{
+		MatchingTags: expr.Grouping,
+		Without:      expr.Without,
+	}
+
+	switch opType {
+	case aggregation.BottomKType, aggregation.TopKType:
+		val, err := resolveScalarArgument(expr.Param)
+		if err != nil {
+			return nil, err
+		}
+
+		nodeInformation.Parameter = val
+		return aggregation.NewTakeOp(opType, nodeInformation)
+
+	case aggregation.QuantileType:
+		val, err := resolveScalarArgument(expr.Param)
+		if err != nil {
+			return nil, err
+		}
+
+		nodeInformation.Parameter = val
+
+	case aggregation.CountValuesType:
+		val, err := resolveStringArgument(expr.Param)
+		if err != nil {
+			return nil, err
+		}
+
+		nodeInformation.StringParameter = val
+		return aggregation.NewCountValuesOp(opType, nodeInformation)
+	}
+
+	return aggregation.NewAggregationOp(opType, nodeInformation)
+}
+
+// NewFunctionExpr creates a new operator for a function call. It also returns
+// the argument expression which provides the input series for the operator
+func NewFunctionExpr(call *promql.Call) (parser.Params, promql.Expr, error) {
+	name := call.Func.Name
+	switch name {
+	case temporal.RateType, temporal.IRateType, temporal.IncreaseType,
+		temporal.DeltaType, temporal.IDeltaType, temporal.ResetsType,
+		temporal.ChangesType, temporal.AvgOverTimeType, temporal.CountOverTimeType,
+		temporal.MinOverTimeType, temporal.MaxOverTimeType, temporal.SumOverTimeType,
+		temporal.StdDevOverTimeType, temporal.StdVarOverTimeType:
+		if len(call.Args) != 1 {
+			return nil, nil, fmt.Errorf("%s expects a single range vector argument", name)
+		}
+
+		return newTemporalOp(name, call.Args[0], 0)
+
+	case temporal.QuantileOverTimeType:
+		if len(call.Args) != 2 {
+			return nil, nil, fmt.Errorf("%s expects a scalar and a range vector argument", name)
+		}
+
+		q, err := resolveScalarArgument(call.Args[0])
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return newTemporalOp(name, call.Args[1], q)
+
+	default:
+		return nil, nil, fmt.Errorf("function not supported: %s", name)
+	}
+}
+
+func newTemporalOp(name string, arg promql.Expr, parameter float64) (parser.Params, promql.Expr, error) {
+	matrix, ok := unwrapParenExpr(arg).(*promql.MatrixSelector)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s expects a range vector argument, got %v", name, arg)
+	}
+
+	op, err := temporal.NewTemporalOp(name, matrix.Range, parameter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return op, matrix, nil
 }
 
-// NewOperator creates a new operator based on the type
-func NewOperator(opType promql.ItemType) parser.Params {
-	switch getOpType(opType) {
-	case functions.CountType:
-		return functions.CountOp{}
+// binaryOperand is one side of a binary expression, either the output of
+// another node or a scalar literal
+type binaryOperand struct {
+	id       parser.NodeID
+	isScalar bool
+	scalar   float64
+}
+
+// NewBinaryOperator creates a new binary operator for the given operands
+func NewBinaryOperator(expr *promql.BinaryExpr, lhs, rhs binaryOperand) (parser.Params, error) {
+	params := binary.NodeParams{
+		LNode:      lhs.id,
+		RNode:      rhs.id,
+		LIsScalar:  lhs.isScalar,
+		RIsScalar:  rhs.isScalar,
+		LScalar:    lhs.scalar,
+		RScalar:    rhs.scalar,
+		ReturnBool: expr.ReturnBool,
+	}
+
+	if matching := expr.VectorMatching; matching != nil {
+		params.VectorMatching = &binary.VectorMatching{
+			Card:           promCardToM3(matching.Card),
+			MatchingLabels: matching.MatchingLabels,
+			On:             matching.On,
+			Include:        matching.Include,
+		}
+	}
+
+	return binary.NewOp(getBinaryOpType(expr.Op), params)
+}
+
+func promCardToM3(card promql.VectorMatchCardinality) binary.VectorMatchCardinality {
+	switch card {
+	case promql.CardManyToOne:
+		return binary.CardManyToOne
+	case promql.CardOneToMany:
+		return binary.CardOneToMany
+	case promql.CardManyToMany:
+		return binary.CardManyToMany
+	default:
+		return binary.CardOneToOne
 	}
-	// TODO: handle other types
-	return nil
 }
 
-func getOpType(opType promql.ItemType) string {
+func getBinaryOpType(opType promql.ItemType) string {
 	switch opType {
+	case promql.ItemType(itemADD):
+		return binary.PlusType
+	case promql.ItemType(itemSUB):
+		return binary.MinusType
+	case promql.ItemType(itemMUL):
+		return binary.MultiplyType
+	case promql.ItemType(itemDIV):
+		return binary.DivType
+	case promql.ItemType(itemMOD):
+		return binary.ModType
+	case promql.ItemType(itemPOW):
+		return binary.ExpType
+	case promql.ItemType(itemEQL):
+		return binary.EqType
+	case promql.ItemType(itemNEQ):
+		return binary.NotEqType
+	case promql.ItemType(itemGTR):
+		return binary.GreaterType
+	case promql.ItemType(itemLSS):
+		return binary.LesserType
+	case promql.ItemType(itemGTE):
+		return binary.GreaterEqType
+	case promql.ItemType(itemLTE):
+		return binary.LesserEqType
+	case promql.ItemType(itemLAND):
+		return binary.AndType
+	case promql.ItemType(itemLOR):
+		return binary.OrType
+	case promql.ItemType(itemLUnless):
+		return binary.UnlessType
+	default:
+		return common.UnknownOpType
+	}
+}
+
+func getAggOpType(opType promql.ItemType) string {
+	switch opType {
+	case promql.ItemType(itemSum):
+		return aggregation.SumType
+	case promql.ItemType(itemMin):
+		return aggregation.MinType
+	case promql.ItemType(itemMax):
+		return aggregation.MaxType
+	case promql.ItemType(itemAvg):
+		return aggregation.AverageType
+	case promql.ItemType(itemStddev):
+		return aggregation.StandardDeviationType
+	case promql.ItemType(itemStdvar):
+		return aggregation.StandardVarianceType
 	case promql.ItemType(itemCount):
-		return functions.CountType
+		return aggregation.CountType
+	case promql.ItemType(itemTopK):
+		return aggregation.TopKType
+	case promql.ItemType(itemBottomK):
+		return aggregation.BottomKType
+	case promql.ItemType(itemQuantile):
+		return aggregation.QuantileType
+	case promql.ItemType(itemCountValues):
+		return aggregation.CountValuesType
 	default:
 		return common.UnknownOpType
 	}
 }
+
+// resolveScalarArgument extracts the value of a numeric literal parameter
+func resolveScalarArgument(expr promql.Expr) (float64, error) {
+	switch n := unwrapParenExpr(expr).(type) {
+	case *promql.NumberLiteral:
+		return n.Val, nil
+	default:
+		return 0, fmt.Errorf("expected a number literal parameter, got %v", expr)
+	}
+}
+
+// resolveStringArgument extracts the value of a string literal parameter
+func resolveStringArgument(expr promql.Expr) (string, error) {
+	switch n := unwrapParenExpr(expr).(type) {
+	case *promql.StringLiteral:
+		return n.Val, nil
+	default:
+		return "", fmt.Errorf("expected a string literal parameter, got %v", expr)
+	}
+}
+
+func unwrapParenExpr(expr promql.Expr) promql.Expr {
+	for {
+		paren, ok := expr.(*promql.ParenExpr)
+		if !ok {
+			return expr
+		}
+
+		expr = paren.Expr
+	}
+}
@@ -24,6 +24,8 @@ import (
 	"testing"
 
 	"github.com/m3db/m3db/src/coordinator/functions"
+	"github.com/m3db/m3db/src/coordinator/functions/aggregation"
+	"github.com/m3db/m3db/src/coordinator/functions/binary"
 	"github.com/m3db/m3db/src/coordinator/parser"
 
 	"github.com/stretchr/testify/assert"
@@ -32,7 +34,7 @@ import (
 
 func TestSingleChildParentRelation(t *testing.T) {
 	fetchTransform := parser.NewTransformFromOperation(functions.FetchOp{}, 1)
-	countTransform := parser.NewTransformFromOperation(functions.CountOp{}, 2)
+	countTransform := parser.NewTransformFromOperation(newCountOp(t), 2)
 	transforms := parser.Nodes{fetchTransform, countTransform}
 	edges := parser.Edges{
 		parser.Edge{
@@ -56,8 +58,8 @@ func TestSingleChildParentRelation(t *testing.T) {
 
 func TestSingleParentMultiChild(t *testing.T) {
 	fetchTransform := parser.NewTransformFromOperation(functions.FetchOp{}, 1)
-	countTransform1 := parser.NewTransformFromOperation(functions.CountOp{}, 2)
-	countTransform2 := parser.NewTransformFromOperation(functions.CountOp{}, 3)
+	countTransform1 := parser.NewTransformFromOperation(newCountOp(t), 2)
+	countTransform2 := parser.NewTransformFromOperation(newCountOp(t), 3)
 	transforms := parser.Nodes{fetchTransform, countTransform1, countTransform2}
 	edges := parser.Edges{
 		parser.Edge{
@@ -82,24 +84,34 @@ func TestSingleParentMultiChild(t *testing.T) {
 func TestMultiParent(t *testing.T) {
 	fetchTransform1 := parser.NewTransformFromOperation(functions.FetchOp{}, 1)
 	fetchTransform2 := parser.NewTransformFromOperation(functions.FetchOp{}, 2)
-	// TODO: change this to a real multi parent operation such as asPercent
-	countTransform := parser.NewTransformFromOperation(functions.CountOp{}, 3)
+	binaryOp, err := binary.NewOp(binary.PlusType, binary.NodeParams{
+		LNode: fetchTransform1.ID,
+		RNode: fetchTransform2.ID,
+	})
+	require.NoError(t, err)
+	binaryTransform := parser.NewTransformFromOperation(binaryOp, 3)
 
-	transforms := parser.Nodes{fetchTransform1, fetchTransform2, countTransform}
+	transforms := parser.Nodes{fetchTransform1, fetchTransform2, binaryTransform}
 	edges := parser.Edges{
 		parser.Edge{
 			ParentID: fetchTransform1.ID,
-			ChildID:  countTransform.ID,
+			ChildID:  binaryTransform.ID,
 		},
 		parser.Edge{
 			ParentID: fetchTransform2.ID,
-			ChildID:  countTransform.ID,
+			ChildID:  binaryTransform.ID,
 		},
 	}
 
 	lp, err := NewLogicalPlan(transforms, edges)
 	require.NoError(t, err)
-	assert.Len(t, lp.Steps[countTransform.ID].Parents, 2)
+	assert.Len(t, lp.Steps[binaryTransform.ID].Parents, 2)
 	assert.Len(t, lp.Steps[fetchTransform1.ID].Children, 1)
 	assert.Len(t, lp.Steps[fetchTransform2.ID].Children, 1)
 }
+
+func newCountOp(t *testing.T) parser.Params {
+	op, err := aggregation.NewAggregationOp(aggregation.CountType, aggregation.NodeParams{})
+	require.NoError(t, err)
+	return op
+}
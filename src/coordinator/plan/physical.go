@@ -22,8 +22,9 @@ package plan
 
 import (
 	"fmt"
-	"time"
 
+	"github.com/m3db/m3db/src/coordinator/executor/transform"
+	"github.com/m3db/m3db/src/coordinator/models"
 	"github.com/m3db/m3db/src/coordinator/parser"
 	"github.com/m3db/m3db/src/coordinator/storage"
 )
@@ -33,7 +34,11 @@ type PhysicalPlan struct {
 	steps      map[parser.NodeID]LogicalStep
 	pipeline   []parser.NodeID // Ordered list of steps to be performed
 	ResultStep ResultOp
-	Now        time.Time
+	TimeSpec   transform.TimeSpec
+	// Query is the query the plan was created from, which remote stores
+	// parse again to execute the sub plans pushed down to them
+	Query    string
+	pushdown map[parser.NodeID]bool
 }
 
 // ResultOp is resonsible for delivering results to the clients
@@ -44,13 +49,20 @@ type ResultOp struct {
 // NewPhysicalPlan is used to generate a physical plan. Its responsibilities include creating consolidation nodes, result nodes,
 // pushing down predicates, changing the ordering for nodes
 // nolint: unparam
-func NewPhysicalPlan(lp LogicalPlan, storage storage.Storage, now time.Time) (PhysicalPlan, error) {
+func NewPhysicalPlan(lp LogicalPlan, storage storage.Storage, params models.RequestParams) (PhysicalPlan, error) {
 	// generate a new physical plan after cloning the logical plan so that any changes here do not update the logical plan
 	cloned := lp.Clone()
 	p := PhysicalPlan{
 		steps:    cloned.Steps,
 		pipeline: cloned.Pipeline,
-		Now:      now,
+		TimeSpec: transform.TimeSpec{
+			Start: params.Start,
+			End:   params.End,
+			Now:   params.Now,
+			Step:  params.Step,
+		},
+		Query:    params.Query,
+		pushdown: pushdownRoots(cloned.Steps),
 	}
 
 	pl, err := p.createResultNode()
@@ -93,6 +105,19 @@ func (p PhysicalPlan) leafNode() (LogicalStep, error) {
 	return leaf, nil
 }
 
+// PushdownRoot returns whether the step produces the result of a sub plan
+// which remote stores can execute over the series they hold
+func (p PhysicalPlan) PushdownRoot(ID parser.NodeID) bool {
+	return p.pushdown[ID]
+}
+
+// Pipeline returns the IDs of the steps in the order they are performed
+func (p PhysicalPlan) Pipeline() []parser.NodeID {
+	pipeline := make([]parser.NodeID, len(p.pipeline))
+	copy(pipeline, p.pipeline)
+	return pipeline
+}
+
 // Step gets the logical step using its unique ID in the DAG
 func (p PhysicalPlan) Step(ID parser.NodeID) (LogicalStep, bool) {
 	// Editor complains when inlining the map get
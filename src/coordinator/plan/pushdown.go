@@ -0,0 +1,141 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package plan
+
+import (
+	"fmt"
+
+	"github.com/m3db/m3db/src/coordinator/functions"
+	"github.com/m3db/m3db/src/coordinator/functions/aggregation"
+	"github.com/m3db/m3db/src/coordinator/functions/temporal"
+	"github.com/m3db/m3db/src/coordinator/parser"
+)
+
+// pushdownRangeOps are the range functions which can be pushed down to the
+// remote stores, as they only need the datapoints of a single series
+var pushdownRangeOps = map[string]bool{
+	temporal.AvgOverTimeType:      true,
+	temporal.CountOverTimeType:    true,
+	temporal.MinOverTimeType:      true,
+	temporal.MaxOverTimeType:      true,
+	temporal.SumOverTimeType:      true,
+	temporal.StdDevOverTimeType:   true,
+	temporal.StdVarOverTimeType:   true,
+	temporal.QuantileOverTimeType: true,
+	temporal.ResetsType:           true,
+	temporal.ChangesType:          true,
+	temporal.RateType:             true,
+	temporal.IRateType:            true,
+	temporal.IncreaseType:         true,
+	temporal.DeltaType:            true,
+	temporal.IDeltaType:           true,
+}
+
+// pushdownRoots returns the nodes producing the results of the sub plans which
+// can be executed by the remote stores. A sub plan is a fetch followed by
+// any range functions and at most one aggregation whose partial results the
+// executor can merge, each having no other input.
+func pushdownRoots(steps map[parser.NodeID]LogicalStep) map[parser.NodeID]bool {
+	roots := make(map[parser.NodeID]bool)
+	for _, step := range steps {
+		if step.Transform.Op.OpType() != functions.FetchType {
+			continue
+		}
+
+		root := step
+		for len(root.Children) == 1 {
+			child, ok := steps[root.Children[0]]
+			if !ok || len(child.Parents) != 1 {
+				break
+			}
+
+			opType := child.Transform.Op.OpType()
+			if pushdownRangeOps[opType] {
+				root = child
+				continue
+			}
+
+			if aggregation.Mergeable(child.Transform.Op) {
+				root = child
+			}
+
+			break
+		}
+
+		roots[root.ID()] = true
+	}
+
+	return roots
+}
+
+// NewSubPlan returns the part of the plan which produces the results of the
+// given node, that is the node along with all the nodes it depends on
+func NewSubPlan(lp LogicalPlan, ID parser.NodeID) (LogicalPlan, error) {
+	if _, ok := lp.Steps[ID]; !ok {
+		return LogicalPlan{}, fmt.Errorf("sub plan node not found, %s", ID)
+	}
+
+	cloned := lp.Clone()
+	included := make(map[parser.NodeID]bool)
+	pending := []parser.NodeID{ID}
+	for len(pending) > 0 {
+		stepID := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if included[stepID] {
+			continue
+		}
+
+		step, ok := cloned.Steps[stepID]
+		if !ok {
+			return LogicalPlan{}, fmt.Errorf("incorrect parent reference, parentId: %s", stepID)
+		}
+
+		included[stepID] = true
+		pending = append(pending, step.Parents...)
+	}
+
+	sub := LogicalPlan{
+		Steps:    make(map[parser.NodeID]LogicalStep, len(included)),
+		Pipeline: make([]parser.NodeID, 0, len(included)),
+	}
+
+	for _, stepID := range cloned.Pipeline {
+		if !included[stepID] {
+			continue
+		}
+
+		// Drop the children outside of the sub plan, which leaves the node
+		// as its result
+		step := cloned.Steps[stepID]
+		children := step.Children[:0]
+		for _, childID := range step.Children {
+			if included[childID] {
+				children = append(children, childID)
+			}
+		}
+
+		step.Children = children
+		sub.Steps[stepID] = step
+		sub.Pipeline = append(sub.Pipeline, stepID)
+	}
+
+	return sub, nil
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package plan
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/m3db/m3db/src/coordinator/functions"
+	"github.com/m3db/m3db/src/coordinator/functions/aggregation"
+	"github.com/m3db/m3db/src/coordinator/functions/temporal"
+	"github.com/m3db/m3db/src/coordinator/parser"
+	"github.com/m3db/m3db/src/coordinator/parser/promql"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseLogicalPlan(t *testing.T, q string) LogicalPlan {
+	p, err := promql.Parse(q)
+	require.NoError(t, err)
+	nodes, edges, err := p.DAG()
+	require.NoError(t, err)
+	lp, err := NewLogicalPlan(nodes, edges)
+	require.NoError(t, err)
+	return lp
+}
+
+func pushdownOpTypes(lp LogicalPlan) []string {
+	var opTypes []string
+	for ID := range pushdownRoots(lp.Steps) {
+		opTypes = append(opTypes, lp.Steps[ID].Transform.Op.OpType())
+	}
+
+	sort.Strings(opTypes)
+	return opTypes
+}
+
+func TestPushdownRoots(t *testing.T) {
+	tests := []struct {
+		query   string
+		opTypes []string
+	}{
+		{"up", []string{functions.FetchType}},
+		{"rate(up[1m])", []string{temporal.RateType}},
+		{"sum(rate(up[1m]))", []string{aggregation.SumType}},
+		{"abs(sum(rate(up[1m])))", []string{aggregation.SumType}},
+		{"avg(up)", []string{functions.FetchType}},
+		{"count(max(up))", []string{aggregation.MaxType}},
+		{"sum(up) + count(down)", []string{aggregation.SumType, aggregation.CountType}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			lp := parseLogicalPlan(t, tt.query)
+			sort.Strings(tt.opTypes)
+			assert.Equal(t, tt.opTypes, pushdownOpTypes(lp))
+		})
+	}
+}
+
+func TestNewSubPlan(t *testing.T) {
+	lp := parseLogicalPlan(t, "abs(sum(rate(up[1m])))")
+	var sumID parser.NodeID
+	for ID := range pushdownRoots(lp.Steps) {
+		sumID = ID
+	}
+
+	sub, err := NewSubPlan(lp, sumID)
+	require.NoError(t, err)
+	require.Len(t, sub.Pipeline, 3)
+	assert.Len(t, sub.Steps, 3)
+	assert.Empty(t, sub.Steps[sumID].Children)
+
+	var opTypes []string
+	for _, ID := range sub.Pipeline {
+		opTypes = append(opTypes, sub.Steps[ID].Transform.Op.OpType())
+	}
+
+	assert.Equal(t, []string{functions.FetchType, temporal.RateType, aggregation.SumType}, opTypes)
+
+	// The original plan is left untouched
+	assert.Len(t, lp.Steps, 4)
+	assert.Len(t, lp.Steps[sumID].Children, 1)
+}
+
+func TestNewSubPlanMissingNode(t *testing.T) {
+	lp := parseLogicalPlan(t, "up")
+	_, err := NewSubPlan(lp, parser.NodeID("missing"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package resolver
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/tsdb"
+	"github.com/m3db/m3metrics/policy"
+	xtime "github.com/m3db/m3x/time"
+)
+
+var errNoStoragePolicies = errors.New("no storage policies to resolve")
+
+type resolutionResolver struct {
+	// policies are sorted from finest to coarsest resolution, with ties
+	// broken by the longest retention
+	policies []policy.StoragePolicy
+	nowFn    func() time.Time
+}
+
+// NewResolutionResolver creates a policy resolver which splits the queried
+// time range between the storage policies, serving each part of the range
+// from the finest resolution policy still retaining data for it.
+func NewResolutionResolver(policies []policy.StoragePolicy, nowFn func() time.Time) (PolicyResolver, error) {
+	if len(policies) == 0 {
+		return nil, errNoStoragePolicies
+	}
+
+	sorted := make([]policy.StoragePolicy, len(policies))
+	copy(sorted, policies)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ri, rj := sorted[i].Resolution().Window, sorted[j].Resolution().Window
+		if ri != rj {
+			return ri < rj
+		}
+
+		return sorted[i].Retention().Duration() > sorted[j].Retention().Duration()
+	})
+
+	return &resolutionResolver{policies: sorted, nowFn: nowFn}, nil
+}
+
+func (r *resolutionResolver) Resolve(
+	// Context needed here to satisfy PolicyResolver interface
+	ctx context.Context, // nolint: unparam
+	tagMatchers models.Matchers,
+	startTime, endTime time.Time,
+) ([]tsdb.FetchRequest, error) {
+	// The ranges only depend on the time range of the query, so a single
+	// request covers all series matched, which may use any type of matcher
+	return []tsdb.FetchRequest{{
+		Ranges: r.resolveRanges(startTime, endTime),
+	}}, nil
+}
+
+// resolveRanges walks back from the end of the range, assigning each part of
+// it to the finest resolution policy which retains data for it. The earliest
+// part of the range which no policy retains is assigned to the policy with the
+// longest retention. The ranges are returned in ascending order.
+func (r *resolutionResolver) resolveRanges(start, end time.Time) tsdb.FetchRanges {
+	var (
+		now    = r.nowFn()
+		ranges tsdb.FetchRanges
+		cursor = end
+	)
+
+	for cursor.After(start) {
+		sp, retainedFrom, ok := r.finestRetaining(now, cursor)
+		if !ok {
+			sp = r.longestRetention()
+			retainedFrom = start
+		}
+
+		if retainedFrom.Before(start) {
+			retainedFrom = start
+		}
+
+		if n := len(ranges); n > 0 && ranges[n-1].StoragePolicy == sp {
+			// Extend the previous range rather than splitting the policy
+			ranges[n-1].Start = retainedFrom
+		} else {
+			ranges = append(ranges, tsdb.FetchRange{
+				Range:         xtime.Range{Start: retainedFrom, End: cursor},
+				StoragePolicy: sp,
+			})
+		}
+
+		cursor = retainedFrom
+	}
+
+	if len(ranges) == 0 {
+		// Empty queries are served by the finest resolution policy
+		ranges = append(ranges, tsdb.FetchRange{
+			Range:         xtime.Range{Start: start, End: end},
+			StoragePolicy: r.policies[0],
+		})
+	}
+
+	for i, j := 0, len(ranges)-1; i < j; i, j = i+1, j-1 {
+		ranges[i], ranges[j] = ranges[j], ranges[i]
+	}
+
+	return ranges
+}
+
+// finestRetaining returns the finest resolution policy retaining any data
+// before the given time, along with the time it retains data from
+func (r *resolutionResolver) finestRetaining(now, before time.Time) (policy.StoragePolicy, time.Time, bool) {
+	for _, sp := range r.policies {
+		retainedFrom := now.Add(-sp.Retention().Duration())
+		if retainedFrom.Before(before) {
+			return sp, retainedFrom, true
+		}
+	}
+
+	return policy.StoragePolicy{}, time.Time{}, false
+}
+
+func (r *resolutionResolver) longestRetention() policy.StoragePolicy {
+	longest := r.policies[0]
+	for _, sp := range r.policies[1:] {
+		if sp.Retention().Duration() > longest.Retention().Duration() {
+			longest = sp
+		}
+	}
+
+	return longest
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package resolver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/tsdb"
+	"github.com/m3db/m3metrics/policy"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testNow = time.Unix(1500000000, 0)
+	raw     = policy.NewStoragePolicy(0, xtime.Second, 48*time.Hour)
+	oneMin  = policy.NewStoragePolicy(time.Minute, xtime.Second, 30*24*time.Hour)
+	oneHour = policy.NewStoragePolicy(time.Hour, xtime.Second, 365*24*time.Hour)
+)
+
+func newTestResolver(t *testing.T) PolicyResolver {
+	r, err := NewResolutionResolver([]policy.StoragePolicy{oneHour, raw, oneMin}, func() time.Time {
+		return testNow
+	})
+	require.NoError(t, err)
+	return r
+}
+
+func fetchRange(start, end time.Time, sp policy.StoragePolicy) tsdb.FetchRange {
+	return tsdb.FetchRange{Range: xtime.Range{Start: start, End: end}, StoragePolicy: sp}
+}
+
+func TestResolutionResolverNoPolicies(t *testing.T) {
+	_, err := NewResolutionResolver(nil, time.Now)
+	assert.Error(t, err)
+}
+
+func TestResolutionResolverResolve(t *testing.T) {
+	r := newTestResolver(t)
+	matchers := models.Matchers{{Type: models.MatchRegexp, Name: "foo", Value: "ba.*"}}
+
+	tests := []struct {
+		name     string
+		start    time.Time
+		expected tsdb.FetchRanges
+	}{
+		{
+			name:     "raw only",
+			start:    testNow.Add(-time.Hour),
+			expected: tsdb.FetchRanges{fetchRange(testNow.Add(-time.Hour), testNow, raw)},
+		},
+		{
+			name:  "raw and one minute",
+			start: testNow.Add(-7 * 24 * time.Hour),
+			expected: tsdb.FetchRanges{
+				fetchRange(testNow.Add(-7*24*time.Hour), testNow.Add(-48*time.Hour), oneMin),
+				fetchRange(testNow.Add(-48*time.Hour), testNow, raw),
+			},
+		},
+		{
+			name:  "past all retention",
+			start: testNow.Add(-2 * 365 * 24 * time.Hour),
+			expected: tsdb.FetchRanges{
+				fetchRange(testNow.Add(-2*365*24*time.Hour), testNow.Add(-30*24*time.Hour), oneHour),
+				fetchRange(testNow.Add(-30*24*time.Hour), testNow.Add(-48*time.Hour), oneMin),
+				fetchRange(testNow.Add(-48*time.Hour), testNow, raw),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests, err := r.Resolve(context.TODO(), matchers, tt.start, testNow)
+			require.NoError(t, err)
+			require.Len(t, requests, 1)
+			assert.Equal(t, tt.expected, requests[0].Ranges)
+		})
+	}
+}
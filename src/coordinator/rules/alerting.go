@@ -0,0 +1,235 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+)
+
+const (
+	// AlertNameLabel is the label holding the name of the rule of an alert.
+	AlertNameLabel = "alertname"
+
+	// templatePrefix defines the variables available to the templates of
+	// annotations, matching Prometheus
+	templatePrefix = "{{$labels := .Labels}}{{$value := .Value}}"
+)
+
+// AlertState is the state of an alert.
+type AlertState int
+
+const (
+	// StatePending is the state of an alert active for less than the hold
+	// duration of its rule.
+	StatePending AlertState = iota
+	// StateFiring is the state of an alert active for at least the hold
+	// duration of its rule.
+	StateFiring
+	// StateResolved is the state of an alert which is no longer active.
+	StateResolved
+)
+
+func (s AlertState) String() string {
+	switch s {
+	case StatePending:
+		return "pending"
+	case StateFiring:
+		return "firing"
+	case StateResolved:
+		return "resolved"
+	}
+
+	return "unknown"
+}
+
+// Alert is an alert raised by an alerting rule for a series.
+type Alert struct {
+	Labels      models.Tags
+	Annotations models.Tags
+	Value       float64
+	State       AlertState
+	// ActiveAt is when the series first matched the rule.
+	ActiveAt time.Time
+	// ResolvedAt is when the series stopped matching the rule, if resolved.
+	ResolvedAt time.Time
+}
+
+// AlertingRule raises an alert for each series of the result of a query,
+// which fires once the series remained in the result for the hold duration.
+type AlertingRule struct {
+	sync.Mutex
+
+	name        string
+	expr        string
+	hold        time.Duration
+	labels      models.Tags
+	annotations models.Tags
+	active      map[string]*Alert
+}
+
+// NewAlertingRule returns a rule raising alerts for the series of the result
+// of the expression, which fire once they remained in the result for the
+// hold duration.
+func NewAlertingRule(
+	name, expr string,
+	hold time.Duration,
+	labels, annotations map[string]string,
+) *AlertingRule {
+	return &AlertingRule{
+		name:        name,
+		expr:        expr,
+		hold:        hold,
+		labels:      tagsOf(labels),
+		annotations: tagsOf(annotations),
+		active:      make(map[string]*Alert),
+	}
+}
+
+// Name returns the name of the alerts raised by the rule.
+func (r *AlertingRule) Name() string {
+	return r.name
+}
+
+// Eval evaluates the rule at the given time, returning the firing alerts
+// along with the alerts resolved by this evaluation, which are to be sent.
+func (r *AlertingRule) Eval(ctx context.Context, t time.Time, query QueryFunc) ([]Alert, error) {
+	samples, err := query(ctx, r.expr, t)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	seen := make(map[string]struct{}, len(samples))
+	for _, s := range samples {
+		labels := s.Tags.Clone()
+		delete(labels, models.MetricName)
+		for k, v := range r.labels {
+			labels[k] = v
+		}
+
+		labels[AlertNameLabel] = r.name
+		annotations, err := r.expandAnnotations(labels, s.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		id := labels.ID()
+		if _, ok := seen[id]; ok {
+			return nil, fmt.Errorf("result of rule %s has multiple series with the same labels after applying rule labels", r.name)
+		}
+
+		seen[id] = struct{}{}
+		if alert, ok := r.active[id]; ok {
+			alert.Value = s.Value
+			alert.Annotations = annotations
+			continue
+		}
+
+		r.active[id] = &Alert{
+			Labels:      labels,
+			Annotations: annotations,
+			Value:       s.Value,
+			State:       StatePending,
+			ActiveAt:    t,
+		}
+	}
+
+	var alerts []Alert
+	for id, alert := range r.active {
+		if _, ok := seen[id]; !ok {
+			// Pending alerts are dropped silently as they were never sent
+			delete(r.active, id)
+			if alert.State == StateFiring {
+				alert.State = StateResolved
+				alert.ResolvedAt = t
+				alerts = append(alerts, *alert)
+			}
+
+			continue
+		}
+
+		if alert.State == StatePending && t.Sub(alert.ActiveAt) >= r.hold {
+			alert.State = StateFiring
+		}
+
+		if alert.State == StateFiring {
+			alerts = append(alerts, *alert)
+		}
+	}
+
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].Labels.ID() < alerts[j].Labels.ID()
+	})
+
+	return alerts, nil
+}
+
+// Active returns the alerts currently pending or firing.
+func (r *AlertingRule) Active() []Alert {
+	r.Lock()
+	defer r.Unlock()
+
+	alerts := make([]Alert, 0, len(r.active))
+	for _, alert := range r.active {
+		alerts = append(alerts, *alert)
+	}
+
+	return alerts
+}
+
+// expandAnnotations expands the templates of the annotations of the rule
+// with the labels and value of an alert
+func (r *AlertingRule) expandAnnotations(labels models.Tags, value float64) (models.Tags, error) {
+	data := struct {
+		Labels map[string]string
+		Value  float64
+	}{
+		Labels: labels,
+		Value:  value,
+	}
+
+	annotations := make(models.Tags, len(r.annotations))
+	for k, text := range r.annotations {
+		tmpl, err := template.New(k).Option("missingkey=zero").Parse(templatePrefix + text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template for annotation %s of rule %s: %v", k, r.name, err)
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("unable to expand annotation %s of rule %s: %v", k, r.name, err)
+		}
+
+		annotations[k] = buf.String()
+	}
+
+	return annotations, nil
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testQuery returns a query function returning the samples set for each
+// expression
+type testQuery map[string][]Sample
+
+func (q testQuery) fn() QueryFunc {
+	return func(_ context.Context, query string, _ time.Time) ([]Sample, error) {
+		return q[query], nil
+	}
+}
+
+func TestAlertingRuleLifecycle(t *testing.T) {
+	query := testQuery{}
+	rule := NewAlertingRule("HighLatency", "latency > 1", time.Minute,
+		map[string]string{"severity": "page"},
+		map[string]string{"summary": "{{ $labels.instance }} latency is {{ $value }}"})
+
+	start := time.Unix(1000, 0)
+	query["latency > 1"] = []Sample{{
+		Tags:  models.Tags{models.MetricName: "latency", "instance": "a"},
+		Value: 2,
+	}}
+
+	// Pending alerts are not sent
+	alerts, err := rule.Eval(context.TODO(), start, query.fn())
+	require.NoError(t, err)
+	assert.Len(t, alerts, 0)
+
+	active := rule.Active()
+	require.Len(t, active, 1)
+	assert.Equal(t, StatePending, active[0].State)
+
+	// Alerts fire once active for the hold duration
+	alerts, err = rule.Eval(context.TODO(), start.Add(time.Minute), query.fn())
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+
+	alert := alerts[0]
+	assert.Equal(t, StateFiring, alert.State)
+	assert.Equal(t, start, alert.ActiveAt)
+	assert.Equal(t, models.Tags{
+		AlertNameLabel: "HighLatency",
+		"instance":     "a",
+		"severity":     "page",
+	}, alert.Labels)
+	assert.Equal(t, models.Tags{"summary": "a latency is 2"}, alert.Annotations)
+
+	// Firing alerts are resolved once no longer in the result
+	query["latency > 1"] = nil
+	resolvedAt := start.Add(2 * time.Minute)
+	alerts, err = rule.Eval(context.TODO(), resolvedAt, query.fn())
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, StateResolved, alerts[0].State)
+	assert.Equal(t, resolvedAt, alerts[0].ResolvedAt)
+	assert.Len(t, rule.Active(), 0)
+
+	// Resolved alerts are only sent once
+	alerts, err = rule.Eval(context.TODO(), start.Add(3*time.Minute), query.fn())
+	require.NoError(t, err)
+	assert.Len(t, alerts, 0)
+}
+
+func TestAlertingRulePendingDropped(t *testing.T) {
+	query := testQuery{"up == 0": {{Tags: models.Tags{"instance": "a"}}}}
+	rule := NewAlertingRule("Down", "up == 0", time.Minute, nil, nil)
+
+	start := time.Unix(1000, 0)
+	alerts, err := rule.Eval(context.TODO(), start, query.fn())
+	require.NoError(t, err)
+	assert.Len(t, alerts, 0)
+
+	query["up == 0"] = nil
+	alerts, err = rule.Eval(context.TODO(), start.Add(30*time.Second), query.fn())
+	require.NoError(t, err)
+	assert.Len(t, alerts, 0)
+	assert.Len(t, rule.Active(), 0)
+}
+
+func TestAlertingRuleDuplicateLabels(t *testing.T) {
+	query := testQuery{"up == 0": {
+		{Tags: models.Tags{models.MetricName: "up", "instance": "a"}},
+		{Tags: models.Tags{models.MetricName: "down", "instance": "a"}},
+	}}
+	rule := NewAlertingRule("Down", "up == 0", 0, nil, nil)
+
+	_, err := rule.Eval(context.TODO(), time.Unix(1000, 0), query.fn())
+	assert.Error(t, err)
+}
+
+func TestAlertingRuleInvalidTemplate(t *testing.T) {
+	query := testQuery{"up == 0": {{Tags: models.Tags{"instance": "a"}}}}
+	rule := NewAlertingRule("Down", "up == 0", 0, nil, map[string]string{"summary": "{{ $labels"})
+
+	_, err := rule.Eval(context.TODO(), time.Unix(1000, 0), query.fn())
+	assert.Error(t, err)
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+
+	"github.com/prometheus/prometheus/pkg/rulefmt"
+)
+
+// Group is a group of rules evaluated in order at the same interval.
+type Group struct {
+	Name     string
+	File     string
+	Interval time.Duration
+	// Recording are the recording rules of the group.
+	Recording []*RecordingRule
+	// Alerting are the alerting rules of the group.
+	Alerting []*AlertingRule
+}
+
+// LoadGroups loads the groups of the Prometheus rule files matching the given
+// patterns, using the default interval for groups without one. Group names
+// must be unique across files as groups are leader-elected by name.
+func LoadGroups(patterns []string, defaultInterval time.Duration) ([]*Group, error) {
+	var (
+		groups []*Group
+		names  = make(map[string]string)
+	)
+
+	for _, pattern := range patterns {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule file pattern %q: %v", pattern, err)
+		}
+
+		for _, file := range files {
+			fileGroups, err := loadFile(file, defaultInterval)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, g := range fileGroups {
+				if other, ok := names[g.Name]; ok {
+					return nil, fmt.Errorf("group %q in %s is already defined in %s", g.Name, file, other)
+				}
+
+				names[g.Name] = file
+				groups = append(groups, g)
+			}
+		}
+	}
+
+	return groups, nil
+}
+
+func loadFile(file string, defaultInterval time.Duration) ([]*Group, error) {
+	ruleGroups, errs := rulefmt.ParseFile(file)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid rule file %s: %v", file, errs[0])
+	}
+
+	groups := make([]*Group, 0, len(ruleGroups.Groups))
+	for _, rg := range ruleGroups.Groups {
+		interval := time.Duration(rg.Interval)
+		if interval <= 0 {
+			interval = defaultInterval
+		}
+
+		g := &Group{Name: rg.Name, File: file, Interval: interval}
+		for _, r := range rg.Rules {
+			if r.Record != "" {
+				g.Recording = append(g.Recording, NewRecordingRule(r.Record, r.Expr, r.Labels))
+				continue
+			}
+
+			g.Alerting = append(g.Alerting, NewAlertingRule(r.Alert, r.Expr,
+				time.Duration(r.For), r.Labels, r.Annotations))
+		}
+
+		groups = append(groups, g)
+	}
+
+	return groups, nil
+}
+
+// tagsOf returns the tags of a map of labels, which may be nil.
+func tagsOf(labels map[string]string) models.Tags {
+	tags := make(models.Tags, len(labels))
+	for k, v := range labels {
+		tags[k] = v
+	}
+
+	return tags
+}
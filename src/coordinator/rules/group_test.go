@@ -0,0 +1,114 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRuleFile = `
+groups:
+- name: requests
+  interval: 30s
+  rules:
+  - record: job:requests:rate1m
+    expr: sum(rate(requests[1m])) by (job)
+    labels:
+      team: infra
+  - alert: HighErrorRate
+    expr: job:errors:rate1m / job:requests:rate1m > 0.1
+    for: 5m
+    labels:
+      severity: page
+    annotations:
+      summary: "{{ $labels.job }} has a high error rate"
+- name: availability
+  rules:
+  - alert: Down
+    expr: up == 0
+`
+
+func writeRuleFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoadGroups(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rules")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := writeRuleFile(t, dir, "rules.yml", testRuleFile)
+	groups, err := LoadGroups([]string{filepath.Join(dir, "*.yml")}, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+
+	requests := groups[0]
+	assert.Equal(t, "requests", requests.Name)
+	assert.Equal(t, file, requests.File)
+	assert.Equal(t, 30*time.Second, requests.Interval)
+	require.Len(t, requests.Recording, 1)
+	assert.Equal(t, "job:requests:rate1m", requests.Recording[0].Name())
+	require.Len(t, requests.Alerting, 1)
+	assert.Equal(t, "HighErrorRate", requests.Alerting[0].Name())
+	assert.Equal(t, 5*time.Minute, requests.Alerting[0].hold)
+
+	availability := groups[1]
+	assert.Equal(t, "availability", availability.Name)
+	assert.Equal(t, time.Minute, availability.Interval)
+	assert.Len(t, availability.Recording, 0)
+	require.Len(t, availability.Alerting, 1)
+}
+
+func TestLoadGroupsDuplicateName(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rules")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeRuleFile(t, dir, "a.yml", testRuleFile)
+	writeRuleFile(t, dir, "b.yml", testRuleFile)
+	_, err = LoadGroups([]string{filepath.Join(dir, "*.yml")}, time.Minute)
+	assert.Error(t, err)
+}
+
+func TestLoadGroupsInvalidFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rules")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := writeRuleFile(t, dir, "rules.yml", `
+groups:
+- name: invalid
+  rules:
+  - record: foo
+    expr: sum(
+`)
+	_, err = LoadGroups([]string{file}, time.Minute)
+	assert.Error(t, err)
+}
@@ -0,0 +1,445 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	clusterclient "github.com/m3db/m3cluster/client"
+	"github.com/m3db/m3cluster/services"
+	"github.com/m3db/m3cluster/services/leader/campaign"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/util/logging"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRetryInterval = 5 * time.Second
+
+	// resendFactor is how many evaluations firing alerts remain valid for
+	// without being sent again, so that alerts of a coordinator which stopped
+	// evaluating their group eventually resolve
+	resendFactor = 4
+)
+
+var (
+	errNoQueryFunc = errors.New("no query function")
+	errNoAppender  = errors.New("no appender")
+	errNoServiceID = errors.New("no service id for the election of rule groups")
+)
+
+// ManagerOptions are the options of a rule manager
+type ManagerOptions struct {
+	// Groups are the rule groups to evaluate
+	Groups []*Group
+	// QueryFunc evaluates the queries of the rules
+	QueryFunc QueryFunc
+	// Appender writes the series recorded by the recording rules
+	Appender storage.Appender
+	// Notifier sends the alerts of the alerting rules, which are not sent
+	// if nil
+	Notifier Notifier
+	// ClusterClient has the managers campaign to lead each group through its
+	// cluster services if set, so that only the leader of a group evaluates
+	// it. Every manager evaluates every group otherwise.
+	ClusterClient clusterclient.Client
+	// ServiceID is the service the groups are elected within
+	ServiceID services.ServiceID
+	// ElectionOptions are the options of the elections of groups
+	ElectionOptions services.ElectionOptions
+	// CampaignOptions are the options of the campaigns of this manager
+	CampaignOptions services.CampaignOptions
+	// RetryInterval is how long to wait before campaigning again when the
+	// cluster services are unavailable
+	RetryInterval time.Duration
+	// NowFn returns the current time, defaults to time.Now
+	NowFn func() time.Time
+	// Scope is the metrics scope, defaults to a noop scope
+	Scope tally.Scope
+}
+
+// Validate validates the manager options
+func (o ManagerOptions) Validate() error {
+	if o.QueryFunc == nil {
+		return errNoQueryFunc
+	}
+
+	if o.Appender == nil {
+		return errNoAppender
+	}
+
+	if o.ClusterClient != nil && o.ServiceID == nil {
+		return errNoServiceID
+	}
+
+	return nil
+}
+
+// Manager evaluates rule groups on schedule, writing the recorded series and
+// sending the alerts. The state of alerts is held by the manager evaluating
+// their group, so pending alerts start over when another manager is elected.
+type Manager struct {
+	sync.Mutex
+
+	opts      ManagerOptions
+	metrics   managerMetrics
+	leader    services.LeaderService
+	evaluated int
+	wg        sync.WaitGroup
+	closeCh   chan struct{}
+}
+
+type managerMetrics struct {
+	evaluations      tally.Counter
+	evaluationErrors tally.Counter
+	evaluationTime   tally.Timer
+	recorded         tally.Counter
+	writeErrors      tally.Counter
+	alertsSent       tally.Counter
+	notifyErrors     tally.Counter
+	groupsEvaluated  tally.Gauge
+}
+
+func newManagerMetrics(scope tally.Scope) managerMetrics {
+	return managerMetrics{
+		evaluations:      scope.Counter("evaluations"),
+		evaluationErrors: scope.Counter("evaluation.errors"),
+		evaluationTime:   scope.Timer("evaluation.latency"),
+		recorded:         scope.Counter("datapoints.recorded"),
+		writeErrors:      scope.Counter("write.errors"),
+		alertsSent:       scope.Counter("alerts.sent"),
+		notifyErrors:     scope.Counter("notify.errors"),
+		groupsEvaluated:  scope.Gauge("groups.evaluated"),
+	}
+}
+
+// NewManager creates a new rule manager, which evaluates the groups in the
+// background until closed
+func NewManager(opts ManagerOptions) (*Manager, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	if opts.ElectionOptions == nil {
+		opts.ElectionOptions = services.NewElectionOptions()
+	}
+
+	if opts.CampaignOptions == nil {
+		campaignOpts, err := services.NewCampaignOptions()
+		if err != nil {
+			return nil, err
+		}
+
+		opts.CampaignOptions = campaignOpts
+	}
+
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = defaultRetryInterval
+	}
+
+	if opts.NowFn == nil {
+		opts.NowFn = time.Now
+	}
+
+	if opts.Scope == nil {
+		opts.Scope = tally.NoopScope
+	}
+
+	m := &Manager{
+		opts:    opts,
+		metrics: newManagerMetrics(opts.Scope),
+		closeCh: make(chan struct{}),
+	}
+
+	if opts.ClusterClient == nil {
+		m.metrics.groupsEvaluated.Update(float64(len(opts.Groups)))
+		for _, g := range opts.Groups {
+			m.wg.Add(1)
+			go func(g *Group) {
+				defer m.wg.Done()
+				m.evaluateGroup(g, m.closeCh)
+			}(g)
+		}
+
+		return m, nil
+	}
+
+	m.wg.Add(1)
+	go m.campaignGroups()
+	return m, nil
+}
+
+// campaignGroups campaigns to lead each group once the leader service is
+// available
+func (m *Manager) campaignGroups() {
+	defer m.wg.Done()
+
+	leader, ok := m.leaderService()
+	if !ok {
+		return
+	}
+
+	for _, g := range m.opts.Groups {
+		m.wg.Add(1)
+		go m.campaign(g, leader)
+	}
+}
+
+// leaderService returns the leader service, retrying until the cluster
+// services are available or the manager is closed
+func (m *Manager) leaderService() (services.LeaderService, bool) {
+	logger := logging.WithContext(context.Background())
+	for {
+		leader, err := m.newLeaderService()
+		if err == nil {
+			m.Lock()
+			m.leader = leader
+			m.Unlock()
+			return leader, true
+		}
+
+		logger.Warn("unable to create the leader service of rule groups, retrying",
+			zap.String("service", m.opts.ServiceID.String()), zap.Any("error", err))
+		select {
+		case <-m.closeCh:
+			return nil, false
+		case <-time.After(m.opts.RetryInterval):
+		}
+	}
+}
+
+func (m *Manager) newLeaderService() (services.LeaderService, error) {
+	svcs, err := m.opts.ClusterClient.Services(services.NewOverrideOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	return svcs.LeaderService(m.opts.ServiceID, m.opts.ElectionOptions)
+}
+
+// campaign campaigns to lead the group until the manager is closed,
+// evaluating it while leading
+func (m *Manager) campaign(g *Group, leader services.LeaderService) {
+	defer m.wg.Done()
+
+	logger := logging.WithContext(context.Background())
+	for {
+		statusCh, err := leader.Campaign(g.Name, m.opts.CampaignOptions)
+		if err != nil {
+			logger.Warn("unable to campaign to lead rule group, retrying",
+				zap.String("group", g.Name), zap.Any("error", err))
+		} else if closed := m.follow(g, leader, statusCh); closed {
+			return
+		}
+
+		select {
+		case <-m.closeCh:
+			return
+		case <-time.After(m.opts.RetryInterval):
+		}
+	}
+}
+
+// follow evaluates the group while the campaign leads it, until the campaign
+// ends or the manager is closed, returning whether the manager was closed
+func (m *Manager) follow(g *Group, leader services.LeaderService, statusCh <-chan campaign.Status) bool {
+	logger := logging.WithContext(context.Background())
+
+	var stop func()
+	stopEvaluating := func() {
+		if stop != nil {
+			stop()
+			stop = nil
+			logger.Info("stopped evaluating rule group", zap.String("group", g.Name))
+		}
+	}
+
+	defer stopEvaluating()
+	for {
+		select {
+		case <-m.closeCh:
+			stopEvaluating()
+			// Drain the campaign while resigning so that it is not blocked
+			// sending the last statuses
+			go func() {
+				for range statusCh {
+				}
+			}()
+
+			if err := leader.Resign(g.Name); err != nil {
+				logger.Warn("unable to resign from rule group", zap.String("group", g.Name), zap.Any("error", err))
+			}
+
+			return true
+		case status, ok := <-statusCh:
+			if !ok {
+				return false
+			}
+
+			switch status.State {
+			case campaign.Leader:
+				if stop == nil {
+					logger.Info("elected leader of rule group, evaluating it", zap.String("group", g.Name))
+					stop = m.startEvaluating(g)
+				}
+			case campaign.Error:
+				logger.Warn("campaign to lead rule group failed", zap.String("group", g.Name), zap.Any("error", status.Err))
+				stopEvaluating()
+			default:
+				stopEvaluating()
+			}
+		}
+	}
+}
+
+// startEvaluating evaluates the group in the background, returning a
+// function stopping the evaluations
+func (m *Manager) startEvaluating(g *Group) func() {
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	m.metrics.groupsEvaluated.Update(float64(m.evaluatedGroups(1)))
+	go func() {
+		defer close(doneCh)
+		m.evaluateGroup(g, stopCh)
+	}()
+
+	return func() {
+		close(stopCh)
+		<-doneCh
+		m.metrics.groupsEvaluated.Update(float64(m.evaluatedGroups(-1)))
+	}
+}
+
+// evaluatedGroups adds delta to the number of groups evaluated by the
+// manager, returning it
+func (m *Manager) evaluatedGroups(delta int) int {
+	m.Lock()
+	defer m.Unlock()
+	m.evaluated += delta
+	return m.evaluated
+}
+
+// evaluateGroup evaluates the group at its interval until stopped
+func (m *Manager) evaluateGroup(g *Group, stopCh <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Abandon the running evaluation once stopped
+	go func() {
+		select {
+		case <-stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(g.Interval)
+	defer ticker.Stop()
+	for {
+		m.Evaluate(ctx, g)
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Evaluate evaluates each rule of the group once, writing the recorded
+// series and sending the alerts
+func (m *Manager) Evaluate(ctx context.Context, g *Group) {
+	ctx, cancel := context.WithTimeout(ctx, g.Interval)
+	defer cancel()
+
+	var (
+		logger = logging.WithContext(ctx)
+		start  = time.Now()
+		t      = m.opts.NowFn()
+	)
+
+	m.metrics.evaluations.Inc(1)
+	for _, r := range g.Recording {
+		queries, err := r.Eval(ctx, t, m.opts.QueryFunc)
+		if err != nil {
+			m.metrics.evaluationErrors.Inc(1)
+			logger.Error("unable to evaluate recording rule", zap.String("group", g.Name),
+				zap.String("rule", r.Name()), zap.Any("error", err))
+			continue
+		}
+
+		for _, query := range queries {
+			if err := m.opts.Appender.Write(ctx, query); err != nil {
+				m.metrics.writeErrors.Inc(1)
+				logger.Error("unable to write recorded series", zap.String("group", g.Name),
+					zap.String("rule", r.Name()), zap.Any("error", err))
+				continue
+			}
+
+			m.metrics.recorded.Inc(1)
+		}
+	}
+
+	var alerts []Alert
+	for _, r := range g.Alerting {
+		ruleAlerts, err := r.Eval(ctx, t, m.opts.QueryFunc)
+		if err != nil {
+			m.metrics.evaluationErrors.Inc(1)
+			logger.Error("unable to evaluate alerting rule", zap.String("group", g.Name),
+				zap.String("rule", r.Name()), zap.Any("error", err))
+			continue
+		}
+
+		alerts = append(alerts, ruleAlerts...)
+	}
+
+	if m.opts.Notifier != nil && len(alerts) > 0 {
+		validUntil := t.Add(resendFactor * g.Interval)
+		if err := m.opts.Notifier.Notify(ctx, alerts, validUntil); err != nil {
+			m.metrics.notifyErrors.Inc(1)
+			logger.Error("unable to send alerts", zap.String("group", g.Name), zap.Any("error", err))
+		} else {
+			m.metrics.alertsSent.Inc(int64(len(alerts)))
+		}
+	}
+
+	m.metrics.evaluationTime.Record(time.Since(start))
+}
+
+// Close stops evaluating the groups, resigning from the groups led
+func (m *Manager) Close() error {
+	close(m.closeCh)
+	m.wg.Wait()
+
+	m.Lock()
+	leader := m.leader
+	m.Unlock()
+
+	if leader != nil {
+		return leader.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	clusterclient "github.com/m3db/m3cluster/client"
+	"github.com/m3db/m3cluster/services"
+	"github.com/m3db/m3cluster/services/leader/campaign"
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/util/logging"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+type testAppender struct {
+	sync.Mutex
+	queries []*storage.WriteQuery
+}
+
+func (a *testAppender) Write(_ context.Context, query *storage.WriteQuery) error {
+	a.Lock()
+	a.queries = append(a.queries, query)
+	a.Unlock()
+	return nil
+}
+
+func (a *testAppender) written() int {
+	a.Lock()
+	defer a.Unlock()
+	return len(a.queries)
+}
+
+type testNotifier struct {
+	sync.Mutex
+	alerts []Alert
+}
+
+func (n *testNotifier) Notify(_ context.Context, alerts []Alert, _ time.Time) error {
+	n.Lock()
+	n.alerts = append(n.alerts, alerts...)
+	n.Unlock()
+	return nil
+}
+
+func newTestGroup() *Group {
+	return &Group{
+		Name:      "test",
+		Interval:  time.Hour,
+		Recording: []*RecordingRule{NewRecordingRule("job:up", "sum(up) by (job)", nil)},
+		Alerting:  []*AlertingRule{NewAlertingRule("Down", "up == 0", 0, nil, nil)},
+	}
+}
+
+func newTestQuery() testQuery {
+	return testQuery{
+		"sum(up) by (job)": {{Tags: models.Tags{"job": "api"}, Value: 2}},
+		"up == 0":          {{Tags: models.Tags{models.MetricName: "up", "job": "api"}}},
+	}
+}
+
+func waitForWrites(t *testing.T, appender *testAppender, n int) {
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(time.Millisecond) {
+		if appender.written() >= n {
+			return
+		}
+	}
+
+	require.FailNow(t, "timed out waiting for recorded series")
+}
+
+func TestManagerEvaluate(t *testing.T) {
+	logging.InitWithCores(nil)
+	appender := &testAppender{}
+	notifier := &testNotifier{}
+	scope := tally.NewTestScope("", nil)
+	m := &Manager{
+		opts: ManagerOptions{
+			QueryFunc: newTestQuery().fn(),
+			Appender:  appender,
+			Notifier:  notifier,
+			NowFn:     time.Now,
+		},
+		metrics: newManagerMetrics(scope),
+	}
+
+	m.Evaluate(context.TODO(), newTestGroup())
+
+	require.Len(t, appender.queries, 1)
+	assert.Equal(t, "job:up", appender.queries[0].Tags[models.MetricName])
+	require.Len(t, notifier.alerts, 1)
+	assert.Equal(t, StateFiring, notifier.alerts[0].State)
+
+	counters := scope.Snapshot().Counters()
+	assert.Equal(t, int64(1), counters["evaluations+"].Value())
+	assert.Equal(t, int64(1), counters["datapoints.recorded+"].Value())
+	assert.Equal(t, int64(1), counters["alerts.sent+"].Value())
+}
+
+func TestManagerWithoutElection(t *testing.T) {
+	logging.InitWithCores(nil)
+	appender := &testAppender{}
+	m, err := NewManager(ManagerOptions{
+		Groups:    []*Group{newTestGroup()},
+		QueryFunc: newTestQuery().fn(),
+		Appender:  appender,
+	})
+	require.NoError(t, err)
+
+	waitForWrites(t, appender, 1)
+	require.NoError(t, m.Close())
+}
+
+func TestManagerElection(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sid := services.NewServiceID().SetName("m3coordinator-rules")
+	statusCh := make(chan campaign.Status)
+	leader := services.NewMockLeaderService(ctrl)
+	leader.EXPECT().Campaign("test", gomock.Any()).Return((<-chan campaign.Status)(statusCh), nil)
+	leader.EXPECT().Resign("test").DoAndReturn(func(string) error {
+		close(statusCh)
+		return nil
+	})
+	leader.EXPECT().Close().Return(nil)
+
+	svcs := services.NewMockServices(ctrl)
+	svcs.EXPECT().LeaderService(sid, gomock.Any()).Return(leader, nil)
+	clusterClient := clusterclient.NewMockClient(ctrl)
+	clusterClient.EXPECT().Services(gomock.Any()).Return(svcs, nil)
+
+	appender := &testAppender{}
+	m, err := NewManager(ManagerOptions{
+		Groups:        []*Group{newTestGroup()},
+		QueryFunc:     newTestQuery().fn(),
+		Appender:      appender,
+		ClusterClient: clusterClient,
+		ServiceID:     sid,
+	})
+	require.NoError(t, err)
+
+	// Groups are only evaluated once elected
+	statusCh <- campaign.Status{State: campaign.Follower}
+	assert.Equal(t, 0, appender.written())
+
+	statusCh <- campaign.Status{State: campaign.Leader}
+	waitForWrites(t, appender, 1)
+
+	require.NoError(t, m.Close())
+}
+
+func TestManagerOptionsValidate(t *testing.T) {
+	appender := &testAppender{}
+	query := newTestQuery().fn()
+
+	assert.Equal(t, errNoQueryFunc, ManagerOptions{Appender: appender}.Validate())
+	assert.Equal(t, errNoAppender, ManagerOptions{QueryFunc: query}.Validate())
+	assert.Equal(t, errNoServiceID, ManagerOptions{
+		QueryFunc:     query,
+		Appender:      appender,
+		ClusterClient: clusterclient.NewMockClient(gomock.NewController(t)),
+	}.Validate())
+	assert.NoError(t, ManagerOptions{QueryFunc: query, Appender: appender}.Validate())
+}
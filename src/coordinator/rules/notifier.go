@@ -0,0 +1,119 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+)
+
+const (
+	defaultNotifyTimeout = 10 * time.Second
+	contentTypeJSON      = "application/json"
+)
+
+var errNoWebhookURL = errors.New("no alertmanager webhook url")
+
+// Notifier sends alerts.
+type Notifier interface {
+	// Notify sends the alerts of a group, valid until the given time unless
+	// resolved or sent again before.
+	Notify(ctx context.Context, alerts []Alert, validUntil time.Time) error
+}
+
+// webhookAlert is an alert in the format of the Alertmanager API
+type webhookAlert struct {
+	Labels       models.Tags `json:"labels"`
+	Annotations  models.Tags `json:"annotations"`
+	StartsAt     time.Time   `json:"startsAt"`
+	EndsAt       time.Time   `json:"endsAt"`
+	GeneratorURL string      `json:"generatorURL,omitempty"`
+}
+
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier returns a notifier posting alerts to an Alertmanager
+// compatible webhook, such as the /api/v1/alerts endpoint of Alertmanager.
+func NewWebhookNotifier(url string, timeout time.Duration) (Notifier, error) {
+	if url == "" {
+		return nil, errNoWebhookURL
+	}
+
+	if timeout <= 0 {
+		timeout = defaultNotifyTimeout
+	}
+
+	return &webhookNotifier{url: url, client: &http.Client{Timeout: timeout}}, nil
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, alerts []Alert, validUntil time.Time) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	body := make([]webhookAlert, 0, len(alerts))
+	for _, alert := range alerts {
+		endsAt := validUntil
+		if alert.State == StateResolved {
+			endsAt = alert.ResolvedAt
+		}
+
+		body = append(body, webhookAlert{
+			Labels:      alert.Labels,
+			Annotations: alert.Annotations,
+			StartsAt:    alert.ActiveAt,
+			EndsAt:      endsAt,
+		})
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, n.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", contentTypeJSON)
+	resp, err := n.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("alertmanager webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
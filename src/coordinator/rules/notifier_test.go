@@ -0,0 +1,92 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	var received []webhookAlert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, contentTypeJSON, r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	notifier, err := NewWebhookNotifier(server.URL+"/api/v1/alerts", time.Second)
+	require.NoError(t, err)
+
+	start := time.Unix(1000, 0).UTC()
+	validUntil := start.Add(4 * time.Minute)
+	resolvedAt := start.Add(time.Minute)
+	err = notifier.Notify(context.TODO(), []Alert{
+		{
+			Labels:      models.Tags{AlertNameLabel: "Down", "instance": "a"},
+			Annotations: models.Tags{"summary": "a is down"},
+			State:       StateFiring,
+			ActiveAt:    start,
+		},
+		{
+			Labels:     models.Tags{AlertNameLabel: "Down", "instance": "b"},
+			State:      StateResolved,
+			ActiveAt:   start,
+			ResolvedAt: resolvedAt,
+		},
+	}, validUntil)
+	require.NoError(t, err)
+
+	require.Len(t, received, 2)
+	assert.Equal(t, models.Tags{AlertNameLabel: "Down", "instance": "a"}, received[0].Labels)
+	assert.Equal(t, models.Tags{"summary": "a is down"}, received[0].Annotations)
+	assert.True(t, start.Equal(received[0].StartsAt))
+	assert.True(t, validUntil.Equal(received[0].EndsAt))
+	assert.True(t, resolvedAt.Equal(received[1].EndsAt))
+}
+
+func TestWebhookNotifierError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	notifier, err := NewWebhookNotifier(server.URL, time.Second)
+	require.NoError(t, err)
+
+	err = notifier.Notify(context.TODO(), []Alert{{State: StateFiring}}, time.Now())
+	assert.Error(t, err)
+}
+
+func TestWebhookNotifierNoURL(t *testing.T) {
+	_, err := NewWebhookNotifier("", time.Second)
+	assert.Equal(t, errNoWebhookURL, err)
+}
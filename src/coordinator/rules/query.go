@@ -0,0 +1,113 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/executor"
+	"github.com/m3db/m3db/src/coordinator/functions/utils"
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/parser/promql"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/util/logging"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// instantQueryStep is the consolidation step of the instant queries of
+	// rules, matching the query endpoints
+	instantQueryStep = time.Second
+)
+
+// Sample is the value of a series at the time a rule is evaluated.
+type Sample struct {
+	Tags  models.Tags
+	Value float64
+}
+
+// QueryFunc evaluates an instant query at the given time.
+type QueryFunc func(ctx context.Context, query string, t time.Time) ([]Sample, error)
+
+// EngineQueryFunc returns a function evaluating instant queries with the
+// engine.
+func EngineQueryFunc(engine *executor.Engine) QueryFunc {
+	return func(ctx context.Context, query string, t time.Time) ([]Sample, error) {
+		parser, err := promql.Parse(query)
+		if err != nil {
+			return nil, err
+		}
+
+		blocks, _, err := engine.ExecuteExpr(ctx, parser, &executor.EngineOptions{}, models.RequestParams{
+			Start: t,
+			End:   t.Add(instantQueryStep),
+			Now:   t,
+			Step:  instantQueryStep,
+			Query: query,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		defer closeBlocks(ctx, blocks)
+		return lastSamples(blocks), nil
+	}
+}
+
+// lastSamples returns the values of the last step of the blocks, dropping
+// missing values
+func lastSamples(blocks []storage.Block) []Sample {
+	var samples []Sample
+	for _, b := range blocks {
+		seriesMeta := utils.FlattenMetadata(b.Meta(), b.SeriesMeta())
+
+		var last storage.Step
+		iter := b.StepIter()
+		for iter.Next() {
+			last = iter.Current()
+		}
+
+		if last == nil {
+			continue
+		}
+
+		for i, v := range last.Values() {
+			if math.IsNaN(v) || i >= len(seriesMeta) {
+				continue
+			}
+
+			samples = append(samples, Sample{Tags: seriesMeta[i].Tags, Value: v})
+		}
+	}
+
+	return samples
+}
+
+func closeBlocks(ctx context.Context, blocks []storage.Block) {
+	for _, b := range blocks {
+		if err := b.Close(); err != nil {
+			logging.WithContext(ctx).Warn("unable to close block", zap.Any("error", err))
+		}
+	}
+}
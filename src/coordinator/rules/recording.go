@@ -0,0 +1,84 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/ts"
+	xtime "github.com/m3db/m3x/time"
+)
+
+// RecordingRule records the result of a query as new series.
+type RecordingRule struct {
+	name   string
+	expr   string
+	labels models.Tags
+}
+
+// NewRecordingRule returns a rule recording the result of the expression as
+// series with the given name, and the given labels added or replaced.
+func NewRecordingRule(name, expr string, labels map[string]string) *RecordingRule {
+	return &RecordingRule{name: name, expr: expr, labels: tagsOf(labels)}
+}
+
+// Name returns the name of the series recorded by the rule.
+func (r *RecordingRule) Name() string {
+	return r.name
+}
+
+// Eval evaluates the rule at the given time, returning the datapoints to
+// write for the series it records.
+func (r *RecordingRule) Eval(ctx context.Context, t time.Time, query QueryFunc) ([]*storage.WriteQuery, error) {
+	samples, err := query(ctx, r.expr, t)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := make([]*storage.WriteQuery, 0, len(samples))
+	seen := make(map[string]struct{}, len(samples))
+	for _, s := range samples {
+		tags := s.Tags.Clone()
+		tags[models.MetricName] = r.name
+		for k, v := range r.labels {
+			tags[k] = v
+		}
+
+		id := tags.ID()
+		if _, ok := seen[id]; ok {
+			return nil, fmt.Errorf("result of rule %s has multiple series with the same labels after applying rule labels", r.name)
+		}
+
+		seen[id] = struct{}{}
+		queries = append(queries, &storage.WriteQuery{
+			Raw:        r.name,
+			Tags:       tags,
+			Datapoints: ts.Datapoints{{Timestamp: t, Value: s.Value}},
+			Unit:       xtime.Millisecond,
+		})
+	}
+
+	return queries, nil
+}
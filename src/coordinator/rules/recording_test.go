@@ -0,0 +1,72 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordingRuleEval(t *testing.T) {
+	query := testQuery{"sum(rate(requests[1m])) by (job)": {
+		{Tags: models.Tags{"job": "api"}, Value: 1.5},
+		{Tags: models.Tags{"job": "web"}, Value: 3},
+	}}
+	rule := NewRecordingRule("job:requests:rate1m", "sum(rate(requests[1m])) by (job)",
+		map[string]string{"team": "infra"})
+
+	now := time.Unix(1000, 0)
+	queries, err := rule.Eval(context.TODO(), now, query.fn())
+	require.NoError(t, err)
+	require.Len(t, queries, 2)
+
+	for i, job := range []string{"api", "web"} {
+		q := queries[i]
+		assert.Equal(t, "job:requests:rate1m", q.Raw)
+		assert.Equal(t, models.Tags{
+			models.MetricName: "job:requests:rate1m",
+			"job":             job,
+			"team":            "infra",
+		}, q.Tags)
+		require.Len(t, q.Datapoints, 1)
+		assert.Equal(t, now, q.Datapoints[0].Timestamp)
+	}
+
+	assert.Equal(t, 1.5, queries[0].Datapoints[0].Value)
+	assert.Equal(t, 3.0, queries[1].Datapoints[0].Value)
+}
+
+func TestRecordingRuleDuplicateLabels(t *testing.T) {
+	query := testQuery{"up": {
+		{Tags: models.Tags{models.MetricName: "up", "job": "api"}},
+		{Tags: models.Tags{models.MetricName: "other", "job": "api"}},
+	}}
+	rule := NewRecordingRule("job:up", "up", nil)
+
+	_, err := rule.Eval(context.TODO(), time.Unix(1000, 0), query.fn())
+	assert.Error(t, err)
+}
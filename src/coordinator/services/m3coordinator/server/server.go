@@ -35,23 +35,39 @@ import (
 	"github.com/m3db/m3db/src/cmd/services/m3coordinator/config"
 	"github.com/m3db/m3db/src/coordinator/api/v1/httpd"
 	m3dbcluster "github.com/m3db/m3db/src/coordinator/cluster/m3db"
+	"github.com/m3db/m3db/src/coordinator/downsample"
 	"github.com/m3db/m3db/src/coordinator/executor"
+	"github.com/m3db/m3db/src/coordinator/executor/cache"
+	"github.com/m3db/m3db/src/coordinator/graphite/carbon"
 	"github.com/m3db/m3db/src/coordinator/policy/filter"
+	"github.com/m3db/m3db/src/coordinator/policy/resolver"
+	"github.com/m3db/m3db/src/coordinator/rules"
 	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/storage/exhaustive"
 	"github.com/m3db/m3db/src/coordinator/storage/fanout"
+	"github.com/m3db/m3db/src/coordinator/storage/ingest"
 	"github.com/m3db/m3db/src/coordinator/storage/local"
 	"github.com/m3db/m3db/src/coordinator/storage/remote"
 	"github.com/m3db/m3db/src/coordinator/stores/m3db"
 	tsdbRemote "github.com/m3db/m3db/src/coordinator/tsdb/remote"
 	"github.com/m3db/m3db/src/coordinator/util/logging"
 	"github.com/m3db/m3db/src/dbnode/client"
+	"github.com/m3db/m3metrics/policy"
 	xconfig "github.com/m3db/m3x/config"
+	"github.com/m3db/m3x/pool"
 
+	"github.com/uber-go/tally"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 )
 
-const defaultNamespace = "metrics"
+const (
+	defaultNamespace = "metrics"
+
+	// defaultValuesPoolCapacity is the capacity of pooled block columns, which
+	// hold a value for each series in a block
+	defaultValuesPoolCapacity = 1024
+)
 
 // RunOptions provides options for running the server
 // with backwards compatibility if only solely adding fields.
@@ -139,20 +155,44 @@ func Run(runOpts RunOptions) {
 		return <-dbClientCh, nil
 	}, nil)
 
-	fanoutStorage, storageCleanup := setupStorages(logger, session, cfg)
-	defer storageCleanup()
-
 	clusterClient := m3dbcluster.NewAsyncClient(func() (clusterclient.Client, error) {
 		return <-clusterClientCh, nil
 	}, nil)
 
-	handler, err := httpd.NewHandler(fanoutStorage, executor.NewEngine(fanoutStorage),
-		clusterClient, cfg, scope)
+	fanoutStorage, pushdown, storageCleanup := setupStorages(logger, session, clusterClient, cfg, scope)
+
+	engine := executor.NewEngineWithLimits(fanoutStorage, engineLimits(cfg), scope.SubScope("engine"))
+	if pushdown != nil {
+		logger.Info("query pushdown to remote coordinators enabled")
+		engine.EnablePushdown(pushdown)
+	}
+
+	if cfg.Cache != nil {
+		queryCache, err := newQueryCache(*cfg.Cache, cfg.Namespaces, scope.SubScope("cache"))
+		if err != nil {
+			logger.Fatal("unable to create query cache", zap.Any("error", err))
+		}
+
+		logger.Info("query result cache enabled")
+		engine.EnableCache(queryCache)
+	}
+
+	handler, err := httpd.NewHandler(fanoutStorage, engine, clusterClient, cfg, scope)
 	if err != nil {
 		logger.Fatal("unable to set up handlers", zap.Any("error", err))
 	}
 	handler.RegisterRoutes()
 
+	var carbonServer *carbon.Server
+	if cfg.Carbon != nil {
+		carbonServer = startCarbonServer(logger, fanoutStorage, cfg.Carbon, scope.SubScope("carbon"))
+	}
+
+	var ruleManager *rules.Manager
+	if cfg.Rules != nil {
+		ruleManager = startRuleManager(logger, engine, fanoutStorage, clusterClient, cfg.Rules, scope.SubScope("rules"))
+	}
+
 	logger.Info("starting server", zap.String("address", cfg.ListenAddress))
 	go func() {
 		if err := http.ListenAndServe(cfg.ListenAddress, handler.Router); err != nil {
@@ -165,29 +205,100 @@ func Run(runOpts RunOptions) {
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
 	<-sigChan
+	if carbonServer != nil {
+		if err := carbonServer.Close(); err != nil {
+			logger.Error("unable to close carbon server", zap.Any("error", err))
+		}
+	}
+
+	if ruleManager != nil {
+		if err := ruleManager.Close(); err != nil {
+			logger.Error("unable to close rule manager", zap.Any("error", err))
+		}
+	}
+
+	// Clean up the storages before closing the session so that pending
+	// downsampled datapoints are written
+	storageCleanup()
 	if err := session.Close(); err != nil {
 		logger.Fatal("unable to close m3db client session", zap.Any("error", err))
 	}
 }
 
-func setupStorages(logger *zap.Logger, session client.Session, cfg config.Configuration) (storage.Storage, func()) {
-	cleanup := func() {}
+func setupStorages(
+	logger *zap.Logger,
+	session client.Session,
+	clusterClient clusterclient.Client,
+	cfg config.Configuration,
+	scope tally.Scope,
+) (storage.Storage, storage.SubPlanExecutor, func()) {
+	var cleanups []func()
+	cleanup := func() {
+		for _, fn := range cleanups {
+			fn()
+		}
+	}
+
 	namespace := defaultNamespace
 	if cfg.DBNamespace != "" {
 		namespace = cfg.DBNamespace
 	}
-	localStorage := local.NewStorage(session, namespace)
+
+	consolidationOpts := storage.NewConsolidationOptions()
+	if cfg.LookbackDuration != nil {
+		consolidationOpts.LookbackDuration = *cfg.LookbackDuration
+	}
+
+	valuesPool := storage.NewValuesPool(pool.NewObjectPoolOptions(), defaultValuesPoolCapacity)
+	valuesPool.Init()
+	consolidationOpts.ValuesPool = valuesPool
+
+	var localStorage storage.Storage
+	if len(cfg.Namespaces) > 0 {
+		var err error
+		localStorage, err = newMultiNamespaceStorage(session, cfg.Namespaces, consolidationOpts)
+		if err != nil {
+			logger.Fatal("unable to create multi-namespace storage", zap.Any("error", err))
+		}
+	} else {
+		localStorage = local.NewStorage(session, namespace, consolidationOpts)
+	}
+
+	fanoutOpts, err := cfg.Fanout.Options()
+	if err != nil {
+		logger.Fatal("invalid fanout configuration", zap.Any("error", err))
+	}
+
+	fanoutOpts.Consolidation = consolidationOpts
+
 	stores := []storage.Storage{localStorage}
 	remoteEnabled := false
 	if cfg.RPC != nil && cfg.RPC.Enabled {
 		logger.Info("rpc enabled")
-		server := startGrpcServer(logger, localStorage, cfg.RPC)
-		cleanup = func() {
-			server.GracefulStop()
+		// Parts of queries pushed down by remote coordinators are executed
+		// over the local storage only, so they are never pushed down again
+		var subPlanExecutor storage.SubPlanExecutor
+		if cfg.RPC.Pushdown {
+			subPlanExecutor = executor.NewEngineWithLimits(localStorage, engineLimits(cfg), scope.SubScope("pushdown"))
+		}
+
+		server := startGrpcServer(logger, localStorage, subPlanExecutor, cfg)
+		cleanups = append(cleanups, server.GracefulStop)
+		if pushdownEngine, ok := subPlanExecutor.(*executor.Engine); ok {
+			cleanups = append(cleanups, func() {
+				if err := pushdownEngine.Close(); err != nil {
+					logger.Error("unable to close pushdown engine", zap.Any("error", err))
+				}
+			})
+		}
+
+		clientOpts, err := cfg.RPC.ClientOptions()
+		if err != nil {
+			logger.Fatal("invalid rpc client configuration", zap.Any("error", err))
 		}
 
 		if remotes := cfg.RPC.RemoteListenAddresses; len(remotes) > 0 {
-			client, err := tsdbRemote.NewGrpcClient(remotes)
+			client, err := tsdbRemote.NewGrpcClientWithOptions(remotes, clientOpts)
 			if err != nil {
 				logger.Fatal("unable to start remote clients for addresses", zap.Any("error", err))
 			}
@@ -195,6 +306,30 @@ func setupStorages(logger *zap.Logger, session client.Session, cfg config.Config
 			stores = append(stores, remote.NewStorage(client))
 			remoteEnabled = true
 		}
+
+		if discoveryCfg := cfg.RPC.Discovery; discoveryCfg != nil {
+			discovery, err := remote.NewZoneDiscovery(remote.DiscoveryOptions{
+				ClusterClient: clusterClient,
+				ServiceID:     discoveryCfg.ServiceID(),
+				QueryOptions:  discoveryCfg.QueryOptions(),
+				LocalZone:     discoveryCfg.LocalZoneOrDefault(),
+				Advertisement: discoveryCfg.Advertisement(),
+				NewClientFn: func(addresses []string) (tsdbRemote.Client, error) {
+					return tsdbRemote.NewGrpcClientWithOptions(addresses, clientOpts)
+				},
+			})
+			if err != nil {
+				logger.Fatal("unable to start remote zone discovery", zap.Any("error", err))
+			}
+
+			fanoutOpts.RemoteStores = discovery
+			remoteEnabled = true
+			cleanups = append(cleanups, func() {
+				if err := discovery.Close(); err != nil {
+					logger.Error("unable to close remote zone discovery", zap.Any("error", err))
+				}
+			})
+		}
 	}
 
 	readFilter := filter.LocalOnly
@@ -202,17 +337,254 @@ func setupStorages(logger *zap.Logger, session client.Session, cfg config.Config
 		readFilter = filter.AllowAll
 	}
 
-	fanoutStorage := fanout.NewStorage(stores, readFilter, filter.LocalOnly)
-	return fanoutStorage, cleanup
+	fanoutStorage := fanout.NewStorageWithOptions(stores, readFilter, filter.LocalOnly, fanoutOpts)
+	var pushdown storage.SubPlanExecutor
+	if remoteEnabled && cfg.RPC.Pushdown {
+		if fanoutOpts.ConflictResolution != fanout.Disjoint {
+			logger.Fatal("pushdown requires the disjoint fanout conflict resolution",
+				zap.String("conflictResolution", fanoutOpts.ConflictResolution.String()))
+		}
+
+		pushdown = fanoutStorage.(storage.SubPlanExecutor)
+	}
+
+	if cfg.Downsample != nil {
+		downsampler, err := newDownsampler(session, cfg, scope.SubScope("downsample"))
+		if err != nil {
+			logger.Fatal("unable to create downsampler", zap.Any("error", err))
+		}
+
+		fanoutStorage = downsample.NewStorage(fanoutStorage, downsampler)
+		cleanups = append(cleanups, func() {
+			if err := downsampler.Close(); err != nil {
+				logger.Error("unable to close downsampler", zap.Any("error", err))
+			}
+		})
+	}
+
+	if cfg.Ingest != nil {
+		processor, err := newIngestProcessor(cfg.Ingest, scope.SubScope("ingest"))
+		if err != nil {
+			logger.Fatal("unable to create ingest rules", zap.Any("error", err))
+		}
+
+		fanoutStorage = ingest.NewStorage(fanoutStorage, processor)
+	}
+
+	if cfg.RequireExhaustive {
+		fanoutStorage = exhaustive.NewStorage(fanoutStorage)
+		if pushdown != nil {
+			pushdown = exhaustive.NewSubPlanExecutor(pushdown)
+		}
+	}
+
+	return fanoutStorage, pushdown, cleanup
+}
+
+// engineLimits returns the resource limits of query engines
+func engineLimits(cfg config.Configuration) executor.Limits {
+	return executor.Limits{
+		MaxConcurrentQueries: cfg.Limits.MaxConcurrentQueries,
+		PerQuery:             cfg.Limits.PerQuery.AsLimits(),
+		Global:               cfg.Limits.Global.AsLimits(),
+	}
+}
+
+func newMultiNamespaceStorage(
+	session client.Session,
+	cfgs []config.NamespaceConfiguration,
+	consolidationOpts storage.ConsolidationOptions,
+) (storage.Storage, error) {
+	var (
+		namespaces = make([]local.Namespace, 0, len(cfgs))
+		policies   = make([]policy.StoragePolicy, 0, len(cfgs))
+	)
+
+	for _, cfg := range cfgs {
+		sp := cfg.StoragePolicy()
+		namespaces = append(namespaces, local.Namespace{
+			Name:          cfg.Namespace,
+			StoragePolicy: sp,
+			Unaggregated:  cfg.Resolution == 0,
+		})
+		policies = append(policies, sp)
+	}
+
+	policyResolver, err := resolver.NewResolutionResolver(policies, time.Now)
+	if err != nil {
+		return nil, err
+	}
+
+	return local.NewMultiNamespaceStorage(session, namespaces, policyResolver, consolidationOpts)
+}
+
+func newDownsampler(
+	session client.Session,
+	cfg config.Configuration,
+	scope tally.Scope,
+) (*downsample.Downsampler, error) {
+	rules, err := cfg.Downsample.MappingRules(cfg.Namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := make(map[policy.StoragePolicy]string, len(cfg.Namespaces))
+	for _, ns := range cfg.Namespaces {
+		namespaces[ns.StoragePolicy()] = ns.Namespace
+	}
+
+	return downsample.NewDownsampler(downsample.Options{
+		Rules:         rules,
+		Writer:        downsample.NewSessionWriter(session, namespaces),
+		BufferPast:    cfg.Downsample.BufferPast,
+		FlushInterval: cfg.Downsample.FlushInterval,
+		Scope:         scope,
+	})
+}
+
+func newIngestProcessor(cfg *config.IngestConfiguration, scope tally.Scope) (*ingest.Processor, error) {
+	opts, err := cfg.Options()
+	if err != nil {
+		return nil, err
+	}
+
+	opts.Scope = scope
+	return ingest.NewProcessor(opts)
+}
+
+// newQueryCache returns the cache of range query results, held on disk if
+// the configuration has a path and in memory otherwise. Results are cached
+// once the namespaces queried can no longer be written to.
+func newQueryCache(
+	cfg config.CacheConfiguration,
+	namespaces []config.NamespaceConfiguration,
+	scope tally.Scope,
+) (*cache.Cache, error) {
+	var (
+		backend cache.Backend
+		err     error
+	)
+
+	if cfg.Path != "" {
+		backend, err = cache.NewDiskBackend(cfg.Path, cfg.MaxBytesOrDefault(), scope)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		backend = cache.NewMemoryBackend(cfg.MaxBytesOrDefault(), scope)
+	}
+
+	opts := cfg.Options()
+	if len(namespaces) > 0 {
+		policies := make([]policy.StoragePolicy, 0, len(namespaces))
+		opts.BufferPasts = make(map[policy.StoragePolicy]time.Duration, len(namespaces))
+		for _, ns := range namespaces {
+			sp := ns.StoragePolicy()
+			policies = append(policies, sp)
+			opts.BufferPasts[sp] = ns.BufferPast
+		}
+
+		opts.Resolver, err = resolver.NewResolutionResolver(policies, time.Now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return cache.NewCache(backend, opts, scope), nil
+}
+
+func startCarbonServer(
+	logger *zap.Logger,
+	appender storage.Appender,
+	cfg *config.CarbonConfiguration,
+	scope tally.Scope,
+) *carbon.Server {
+	logger.Info("starting carbon server",
+		zap.String("plaintext", cfg.ListenAddress), zap.String("pickle", cfg.PickleListenAddress))
+	server, err := carbon.NewServer(carbon.Options{
+		ListenAddress:       cfg.ListenAddress,
+		PickleListenAddress: cfg.PickleListenAddress,
+		Appender:            appender,
+		ReadTimeout:         cfg.ReadTimeout,
+		Scope:               scope,
+	})
+	if err != nil {
+		logger.Fatal("unable to start carbon server", zap.Any("error", err))
+	}
+
+	return server
+}
+
+func startRuleManager(
+	logger *zap.Logger,
+	engine *executor.Engine,
+	appender storage.Appender,
+	clusterClient clusterclient.Client,
+	cfg *config.RulesConfiguration,
+	scope tally.Scope,
+) *rules.Manager {
+	groups, err := rules.LoadGroups(cfg.Files, cfg.EvaluationIntervalOrDefault())
+	if err != nil {
+		logger.Fatal("unable to load rule files", zap.Any("error", err))
+	}
+
+	opts := rules.ManagerOptions{
+		Groups:    groups,
+		QueryFunc: rules.EngineQueryFunc(engine),
+		Appender:  appender,
+		Scope:     scope,
+	}
+
+	if am := cfg.Alertmanager; am != nil {
+		opts.Notifier, err = rules.NewWebhookNotifier(am.URL, am.Timeout)
+		if err != nil {
+			logger.Fatal("invalid alertmanager configuration", zap.Any("error", err))
+		}
+	}
+
+	if election := cfg.Election; election != nil {
+		opts.ClusterClient = clusterClient
+		opts.ServiceID = election.ServiceID()
+		opts.ElectionOptions = election.ElectionOptions()
+		opts.CampaignOptions, err = election.CampaignOptions()
+		if err != nil {
+			logger.Fatal("invalid rule election configuration", zap.Any("error", err))
+		}
+	}
+
+	logger.Info("starting rule manager", zap.Int("groups", len(groups)),
+		zap.Bool("elected", cfg.Election != nil))
+	manager, err := rules.NewManager(opts)
+	if err != nil {
+		logger.Fatal("unable to start rule manager", zap.Any("error", err))
+	}
+
+	return manager
 }
 
-func startGrpcServer(logger *zap.Logger, storage storage.Storage, cfg *config.RPCConfiguration) *grpc.Server {
+func startGrpcServer(
+	logger *zap.Logger,
+	storage storage.Storage,
+	executor storage.SubPlanExecutor,
+	cfg config.Configuration,
+) *grpc.Server {
 	logger.Info("creating gRPC server")
-	server := tsdbRemote.CreateNewGrpcServer(storage)
+	opts, err := cfg.RPC.ServerOptions()
+	if err != nil {
+		logger.Fatal("invalid rpc server configuration", zap.Any("error", err))
+	}
+
+	// Queries served to other coordinators are subject to the same limits
+	// as the queries served to clients
+	limits := engineLimits(cfg)
+	server := tsdbRemote.CreateNewGrpcServerWithLimits(storage, executor, tsdbRemote.ServerLimits{
+		PerQuery: limits.PerQuery,
+		Global:   limits.Global,
+	}, opts...)
 	waitForStart := make(chan struct{})
 	go func() {
-		logger.Info("starting gRPC server on port", zap.Any("rpc", cfg.ListenAddress))
-		err := tsdbRemote.StartNewGrpcServer(server, cfg.ListenAddress, waitForStart)
+		logger.Info("starting gRPC server on port", zap.Any("rpc", cfg.RPC.ListenAddress))
+		err := tsdbRemote.StartNewGrpcServer(server, cfg.RPC.ListenAddress, waitForStart)
 		if err != nil {
 			logger.Fatal("unable to start gRPC server", zap.Any("error", err))
 		}
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/m3db/m3db/src/coordinator/models"
@@ -14,35 +15,74 @@ type Block interface {
 	SeriesIter() SeriesIter
 	SeriesMeta() []SeriesMeta
 	StepMeta() []StepMeta
+	// Close frees up any resources held by the block
+	Close() error
+}
+
+// UnconsolidatedBlock is a block which keeps the raw datapoints of its series
+// alongside their step values, for functions such as range functions which
+// are evaluated over the raw datapoints
+type UnconsolidatedBlock interface {
+	Block
+	// Unconsolidated returns the raw datapoints of each series, in the order
+	// of the series metadata
+	Unconsolidated() []ts.Values
 }
 
 // SeriesMeta is metadata data for the series
 type SeriesMeta struct {
 	Tags models.Tags
+	Name string
 }
 
 // StepMeta is metadata data for a single time step
 type StepMeta struct {
+	Time time.Time
 }
 
-// Bounds are the time bounds
-// nolint: structcheck, megacheck, unused
+// Bounds are the time bounds, start time is inclusive but end is exclusive
 type Bounds struct {
-	start    time.Time
-	end      time.Time
-	stepSize time.Duration
+	Start    time.Time
+	End      time.Time
+	StepSize time.Duration
+}
+
+// TimeForIndex returns the start time for a given index assuming a uniform step size
+func (b Bounds) TimeForIndex(idx int) (time.Time, error) {
+	step := b.StepSize
+	t := b.Start.Add(time.Duration(idx) * step)
+	if !t.Before(b.End) {
+		return time.Time{}, fmt.Errorf("out of bounds, %d", idx)
+	}
+
+	return t, nil
+}
+
+// Steps calculates the number of steps for the bounds
+func (b Bounds) Steps() int {
+	if b.StepSize <= 0 {
+		return 0
+	}
+
+	return int(b.End.Sub(b.Start) / b.StepSize)
+}
+
+func (b Bounds) String() string {
+	return fmt.Sprintf("start: %v, end: %v, stepSize: %v", b.Start, b.End, b.StepSize)
 }
 
 // SeriesIter iterates through a CompressedSeriesIterator horizontally
 type SeriesIter interface {
 	Next() bool
 	Current() ts.Series
+	SeriesCount() int
 }
 
 // StepIter iterates through a CompressedStepIterator vertically
 type StepIter interface {
 	Next() bool
 	Current() Step
+	StepCount() int
 }
 
 // Step can optionally implement iterator interface
@@ -56,3 +96,10 @@ type BlockMetadata struct {
 	Bounds Bounds
 	Tags   models.Tags // Common tags across different series
 }
+
+// Builder builds a new block
+type Builder interface {
+	AppendValue(idx int, value float64) error
+	AppendValues(idx int, values []float64) error
+	Build() Block
+}
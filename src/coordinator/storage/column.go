@@ -0,0 +1,196 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/ts"
+)
+
+type column struct {
+	Values []float64
+}
+
+// ColumnBlockBuilder builds a block optimized for column iteration
+type ColumnBlockBuilder struct {
+	block *columnBlock
+}
+
+type columnBlock struct {
+	columns    []column
+	meta       BlockMetadata
+	seriesMeta []SeriesMeta
+}
+
+func (c *columnBlock) Meta() BlockMetadata {
+	return c.meta
+}
+
+func (c *columnBlock) StepIter() StepIter {
+	return &colBlockIter{
+		columns: c.columns,
+		meta:    c.meta,
+		idx:     -1,
+	}
+}
+
+func (c *columnBlock) SeriesIter() SeriesIter {
+	return &colSeriesIter{
+		block: c,
+		idx:   -1,
+	}
+}
+
+func (c *columnBlock) SeriesMeta() []SeriesMeta {
+	return c.seriesMeta
+}
+
+func (c *columnBlock) StepMeta() []StepMeta {
+	metas := make([]StepMeta, len(c.columns))
+	for i := range metas {
+		metas[i].Time = c.meta.Bounds.Start.Add(time.Duration(i) * c.meta.Bounds.StepSize)
+	}
+
+	return metas
+}
+
+func (c *columnBlock) Close() error {
+	return nil
+}
+
+// colBlockIter is a StepIter backed by columns
+type colBlockIter struct {
+	columns []column
+	meta    BlockMetadata
+	idx     int
+}
+
+func (c *colBlockIter) Next() bool {
+	c.idx++
+	return c.idx < len(c.columns)
+}
+
+func (c *colBlockIter) Current() Step {
+	col := c.columns[c.idx]
+	t, _ := c.meta.Bounds.TimeForIndex(c.idx)
+	return ColStep{
+		time:   t,
+		values: col.Values,
+	}
+}
+
+func (c *colBlockIter) StepCount() int {
+	return len(c.columns)
+}
+
+// colSeriesIter is a SeriesIter which transposes the columns of a block
+type colSeriesIter struct {
+	block *columnBlock
+	idx   int
+}
+
+func (c *colSeriesIter) Next() bool {
+	c.idx++
+	return c.idx < len(c.block.seriesMeta)
+}
+
+func (c *colSeriesIter) Current() ts.Series {
+	bounds := c.block.meta.Bounds
+	values := ts.NewFixedStepValues(bounds.StepSize, len(c.block.columns), math.NaN(), bounds.Start)
+	for i, col := range c.block.columns {
+		values.SetValueAt(i, col.Values[c.idx])
+	}
+
+	meta := c.block.seriesMeta[c.idx]
+	return *ts.NewSeries(meta.Name, values, meta.Tags)
+}
+
+func (c *colSeriesIter) SeriesCount() int {
+	return len(c.block.seriesMeta)
+}
+
+// ColStep is a single column containing data from multiple series at a given time step
+type ColStep struct {
+	time   time.Time
+	values []float64
+}
+
+// Time for the step
+func (c ColStep) Time() time.Time {
+	return c.time
+}
+
+// Values for the column
+func (c ColStep) Values() []float64 {
+	return c.values
+}
+
+// NewColStep creates a new column step
+func NewColStep(t time.Time, values []float64) Step {
+	return ColStep{time: t, values: values}
+}
+
+// NewColumnBlockBuilder creates a new column block builder
+func NewColumnBlockBuilder(meta BlockMetadata, seriesMeta []SeriesMeta) Builder {
+	cols := make([]column, meta.Bounds.Steps())
+	for i := range cols {
+		cols[i].Values = make([]float64, 0, len(seriesMeta))
+	}
+
+	return ColumnBlockBuilder{
+		block: &columnBlock{
+			columns:    cols,
+			meta:       meta,
+			seriesMeta: seriesMeta,
+		},
+	}
+}
+
+// AppendValue adds a value to a column at index
+func (cb ColumnBlockBuilder) AppendValue(idx int, value float64) error {
+	columns := cb.block.columns
+	if len(columns) <= idx {
+		return fmt.Errorf("idx out of range for append: %d", idx)
+	}
+
+	columns[idx].Values = append(columns[idx].Values, value)
+	return nil
+}
+
+// AppendValues adds a slice of values to a column at index
+func (cb ColumnBlockBuilder) AppendValues(idx int, values []float64) error {
+	columns := cb.block.columns
+	if len(columns) <= idx {
+		return fmt.Errorf("idx out of range for append: %d", idx)
+	}
+
+	columns[idx].Values = append(columns[idx].Values, values...)
+	return nil
+}
+
+// Build extracts the block
+// TODO: Return an immutable copy
+func (cb ColumnBlockBuilder) Build() Block {
+	return cb.block
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"context"
+	"sort"
+)
+
+// CompleteTags completes the tags of the store from its index when it is a
+// TagCompleter, otherwise from the tags of the series matching the query.
+func CompleteTags(
+	ctx context.Context,
+	store Querier,
+	query *CompleteTagsQuery,
+	options *FetchOptions,
+) (*CompleteTagsResult, error) {
+	if completer, ok := store.(TagCompleter); ok {
+		return completer.CompleteTags(ctx, query, options)
+	}
+
+	results, err := store.FetchTags(ctx, &FetchQuery{
+		TagMatchers: query.TagMatchers,
+		Start:       query.Start,
+		End:         query.End,
+	}, options)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := make(map[string]struct{})
+	for _, m := range results.Metrics {
+		if query.TagName != "" {
+			if v, ok := m.Tags[query.TagName]; ok {
+				terms[v] = struct{}{}
+			}
+			continue
+		}
+
+		for name := range m.Tags {
+			terms[name] = struct{}{}
+		}
+	}
+
+	return &CompleteTagsResult{
+		Terms:    SortedTerms(terms),
+		Warnings: results.Warnings,
+	}, nil
+}
+
+// SortedTerms returns the sorted terms of the set
+func SortedTerms(set map[string]struct{}) []string {
+	terms := make([]string, 0, len(set))
+	for term := range set {
+		terms = append(terms, term)
+	}
+
+	sort.Strings(terms)
+	return terms
+}
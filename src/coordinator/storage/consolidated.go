@@ -0,0 +1,385 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/cost"
+	"github.com/m3db/m3db/src/coordinator/ts"
+	"github.com/m3db/m3db/src/dbnode/encoding"
+	"github.com/m3db/m3x/ident"
+	"github.com/m3db/m3x/pool"
+)
+
+const (
+	// DefaultLookbackDuration is the default duration to look back for a
+	// datapoint when consolidating sparse series, matching Prometheus
+	DefaultLookbackDuration = 5 * time.Minute
+)
+
+var (
+	errInvalidStepSize = errors.New("step size must be positive")
+	errInvalidBounds   = errors.New("block end must be after start")
+)
+
+// ConsolidationOptions describes how raw series are consolidated into blocks
+type ConsolidationOptions struct {
+	// LookbackDuration is how far back from each step to look for a
+	// datapoint; steps with no datapoint in the window are NaN. Defaults
+	// to DefaultLookbackDuration if unset
+	LookbackDuration time.Duration
+	// RangeLookbacks overrides the lookback from the start of each range
+	// onwards, for blocks stitched from namespaces of different resolutions.
+	// Ranges are in ascending order
+	RangeLookbacks []RangeLookback
+	// ValuesPool is an optional pool for the columns of the block
+	ValuesPool ValuesPool
+}
+
+// NewConsolidationOptions creates consolidation options with default values
+func NewConsolidationOptions() ConsolidationOptions {
+	return ConsolidationOptions{LookbackDuration: DefaultLookbackDuration}
+}
+
+// RangeLookback is the lookback of the steps from Start onwards
+type RangeLookback struct {
+	Start    time.Time
+	Lookback time.Duration
+}
+
+// stepLookbacks returns the lookback of each step of the bounds
+func stepLookbacks(bounds Bounds, opts ConsolidationOptions) []time.Duration {
+	lookback := opts.LookbackDuration
+	if lookback <= 0 {
+		lookback = DefaultLookbackDuration
+	}
+
+	var (
+		lookbacks = make([]time.Duration, bounds.Steps())
+		ranges    = opts.RangeLookbacks
+		stepTime  = bounds.Start
+	)
+
+	for i := range lookbacks {
+		for len(ranges) > 0 && !stepTime.Before(ranges[0].Start) {
+			lookback = ranges[0].Lookback
+			ranges = ranges[1:]
+		}
+
+		lookbacks[i] = lookback
+		stepTime = stepTime.Add(bounds.StepSize)
+	}
+
+	return lookbacks
+}
+
+// ValuesPool pools the float slices used as block columns
+type ValuesPool interface {
+	// Init initializes the pool
+	Init()
+	// Get returns a slice with the given length
+	Get(size int) []float64
+	// Put returns a slice to the pool
+	Put(values []float64)
+}
+
+type valuesPool struct {
+	pool     pool.ObjectPool
+	capacity int
+}
+
+// NewValuesPool creates a new pool of float slices with the given capacity
+func NewValuesPool(opts pool.ObjectPoolOptions, capacity int) ValuesPool {
+	return &valuesPool{pool: pool.NewObjectPool(opts), capacity: capacity}
+}
+
+func (p *valuesPool) Init() {
+	p.pool.Init(func() interface{} {
+		return make([]float64, 0, p.capacity)
+	})
+}
+
+func (p *valuesPool) Get(size int) []float64 {
+	values := p.pool.Get().([]float64)
+	if cap(values) < size {
+		// Too small to reuse, leave it for a smaller block
+		p.pool.Put(values)
+		return make([]float64, size)
+	}
+
+	return values[:size]
+}
+
+func (p *valuesPool) Put(values []float64) {
+	p.pool.Put(values[:0])
+}
+
+// consolidatedBlock is a column block built from M3DB series iterators,
+// which returns its columns to the pool on close
+type consolidatedBlock struct {
+	*columnBlock
+	pool ValuesPool
+}
+
+func (b *consolidatedBlock) Close() error {
+	b.release()
+	return nil
+}
+
+func (b *consolidatedBlock) release() {
+	if b.pool == nil {
+		return
+	}
+
+	for i, col := range b.columns {
+		b.pool.Put(col.Values)
+		b.columns[i].Values = nil
+	}
+}
+
+// NewM3Block consolidates the series iterators into a block with fixed step
+// columns over the given bounds. For each step the latest datapoint within the
+// lookback window (step - lookback, step] is used. The iterators are consumed
+// but not closed. Decoded series and datapoints are charged to the enforcer,
+// which may be nil.
+func NewM3Block(
+	namespace ident.ID,
+	iters encoding.SeriesIterators,
+	bounds Bounds,
+	opts ConsolidationOptions,
+	enforcer *cost.Enforcer,
+) (Block, error) {
+	return NewStitchedM3Block(namespace, StitchSeriesIterators(iters), bounds, opts, enforcer)
+}
+
+// NewStitchedM3Block consolidates series stitched together from consecutive
+// time ranges into a block, as NewM3Block does for a single range.
+func NewStitchedM3Block(
+	namespace ident.ID,
+	series []*StitchedSeries,
+	bounds Bounds,
+	opts ConsolidationOptions,
+	enforcer *cost.Enforcer,
+) (Block, error) {
+	if bounds.StepSize <= 0 {
+		return nil, errInvalidStepSize
+	}
+
+	if !bounds.End.After(bounds.Start) {
+		return nil, errInvalidBounds
+	}
+
+	lookbacks := stepLookbacks(bounds, opts)
+
+	var (
+		numSeries  = len(series)
+		columns    = make([]column, bounds.Steps())
+		seriesMeta = make([]SeriesMeta, numSeries)
+	)
+
+	if err := enforcer.AddSeries(numSeries); err != nil {
+		return nil, err
+	}
+
+	for i := range columns {
+		if opts.ValuesPool != nil {
+			columns[i].Values = opts.ValuesPool.Get(numSeries)
+		} else {
+			columns[i].Values = make([]float64, numSeries)
+		}
+	}
+
+	block := &consolidatedBlock{
+		columnBlock: &columnBlock{
+			columns:    columns,
+			meta:       BlockMetadata{Bounds: bounds},
+			seriesMeta: seriesMeta,
+		},
+		pool: opts.ValuesPool,
+	}
+
+	for i, iter := range series {
+		metric, err := FromM3IdentToMetric(namespace, iter.ID, iter.Tags)
+		if err != nil {
+			block.release()
+			return nil, err
+		}
+
+		seriesMeta[i] = SeriesMeta{Name: metric.ID, Tags: metric.Tags}
+		decoded, err := consolidateSeries(iter, i, columns, bounds, lookbacks)
+		if err == nil {
+			err = enforcer.AddDatapoints(decoded)
+		}
+
+		if err != nil {
+			block.release()
+			return nil, err
+		}
+	}
+
+	return block, nil
+}
+
+// NewSeriesBlock consolidates raw series into a block with fixed step columns
+// over the given bounds, as NewM3Block does for series iterators. The series
+// are expected to have been charged to an enforcer when fetched.
+func NewSeriesBlock(seriesList []*ts.Series, bounds Bounds, opts ConsolidationOptions) (Block, error) {
+	if bounds.StepSize <= 0 {
+		return nil, errInvalidStepSize
+	}
+
+	if !bounds.End.After(bounds.Start) {
+		return nil, errInvalidBounds
+	}
+
+	lookbacks := stepLookbacks(bounds, opts)
+
+	columns := make([]column, bounds.Steps())
+	for i := range columns {
+		columns[i].Values = make([]float64, len(seriesList))
+	}
+
+	seriesMeta := make([]SeriesMeta, len(seriesList))
+	for i, series := range seriesList {
+		seriesMeta[i] = SeriesMeta{Name: series.Name(), Tags: series.Tags}
+		values := series.Values()
+		next := 0
+		consolidateDatapoints(func() (time.Time, float64, bool) {
+			if next >= values.Len() {
+				return time.Time{}, 0, false
+			}
+
+			dp := values.DatapointAt(next)
+			next++
+			return dp.Timestamp, dp.Value, true
+		}, i, columns, bounds, lookbacks)
+	}
+
+	return &columnBlock{
+		columns:    columns,
+		meta:       BlockMetadata{Bounds: bounds},
+		seriesMeta: seriesMeta,
+	}, nil
+}
+
+// unconsolidatedBlock is a block built from raw series which keeps their
+// datapoints
+type unconsolidatedBlock struct {
+	Block
+	values []ts.Values
+}
+
+func (b *unconsolidatedBlock) Unconsolidated() []ts.Values {
+	return b.values
+}
+
+// NewUnconsolidatedBlock consolidates raw series into a block as
+// NewSeriesBlock does, keeping the raw datapoints of the series.
+func NewUnconsolidatedBlock(seriesList []*ts.Series, bounds Bounds, opts ConsolidationOptions) (UnconsolidatedBlock, error) {
+	block, err := NewSeriesBlock(seriesList, bounds, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]ts.Values, len(seriesList))
+	for i, series := range seriesList {
+		values[i] = series.Values()
+	}
+
+	return &unconsolidatedBlock{Block: block, values: values}, nil
+}
+
+// consolidateSeries fills in the values at the given series index for each
+// column from the datapoints of the iterator, returning the number of
+// datapoints decoded
+func consolidateSeries(
+	iter *StitchedSeries,
+	idx int,
+	columns []column,
+	bounds Bounds,
+	lookbacks []time.Duration,
+) (int, error) {
+	decoded := consolidateDatapoints(func() (time.Time, float64, bool) {
+		if !iter.Next() {
+			return time.Time{}, 0, false
+		}
+
+		dp, _, _ := iter.Current()
+		return dp.Timestamp, dp.Value, true
+	}, idx, columns, bounds, lookbacks)
+
+	return decoded, iter.Err()
+}
+
+// consolidateDatapoints fills in the values at the given series index for
+// each column from the time ordered datapoints returned by next, looking
+// back from each step by its lookback, returning the number of datapoints
+// consumed
+func consolidateDatapoints(
+	next func() (time.Time, float64, bool),
+	idx int,
+	columns []column,
+	bounds Bounds,
+	lookbacks []time.Duration,
+) int {
+	var (
+		consumed int
+		step     int
+		stepTime = bounds.Start
+		last     time.Time
+		lastVal  = math.NaN()
+		hasLast  bool
+	)
+
+	// fill sets the values of all steps before the given time
+	fill := func(before time.Time) {
+		for ; step < len(columns) && stepTime.Before(before); step++ {
+			value := math.NaN()
+			if hasLast && stepTime.Sub(last) < lookbacks[step] {
+				value = lastVal
+			}
+
+			columns[step].Values[idx] = value
+			stepTime = stepTime.Add(bounds.StepSize)
+		}
+	}
+
+	for {
+		timestamp, value, ok := next()
+		if !ok {
+			break
+		}
+
+		consumed++
+		fill(timestamp)
+		if step >= len(columns) {
+			return consumed
+		}
+
+		last, lastVal, hasLast = timestamp, value, true
+	}
+
+	fill(bounds.End)
+	return consumed
+}
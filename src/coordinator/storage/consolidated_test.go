@@ -0,0 +1,375 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/cost"
+	coordErrors "github.com/m3db/m3db/src/coordinator/errors"
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/ts"
+	"github.com/m3db/m3db/src/dbnode/encoding"
+	m3ts "github.com/m3db/m3db/src/dbnode/ts"
+	"github.com/m3db/m3x/ident"
+	"github.com/m3db/m3x/pool"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testStart  = time.Now().Truncate(time.Hour)
+	testBounds = Bounds{
+		Start:    testStart,
+		End:      testStart.Add(5 * time.Minute),
+		StepSize: time.Minute,
+	}
+)
+
+func newTestSeriesIter(
+	ctrl *gomock.Controller,
+	id string,
+	dps []m3ts.Datapoint,
+	err error,
+) encoding.SeriesIterator {
+	iter := encoding.NewMockSeriesIterator(ctrl)
+	iter.EXPECT().ID().Return(ident.StringID(id)).AnyTimes()
+	iter.EXPECT().Tags().Return(ident.NewTagsIterator(ident.NewTags(
+		ident.StringTag("id", id),
+	))).AnyTimes()
+
+	idx := -1
+	iter.EXPECT().Next().DoAndReturn(func() bool {
+		idx++
+		return idx < len(dps)
+	}).AnyTimes()
+	iter.EXPECT().Current().DoAndReturn(func() (m3ts.Datapoint, xtime.Unit, m3ts.Annotation) {
+		return dps[idx], xtime.Second, nil
+	}).AnyTimes()
+	iter.EXPECT().Err().Return(err).AnyTimes()
+
+	return iter
+}
+
+func newTestSeriesIters(ctrl *gomock.Controller, iters ...encoding.SeriesIterator) encoding.SeriesIterators {
+	seriesIters := encoding.NewMockSeriesIterators(ctrl)
+	seriesIters.EXPECT().Iters().Return(iters).AnyTimes()
+	seriesIters.EXPECT().Len().Return(len(iters)).AnyTimes()
+	return seriesIters
+}
+
+func dp(offset time.Duration, value float64) m3ts.Datapoint {
+	return m3ts.Datapoint{Timestamp: testStart.Add(offset), Value: value}
+}
+
+func stepValues(block Block) [][]float64 {
+	var values [][]float64
+	iter := block.StepIter()
+	for iter.Next() {
+		step := iter.Current()
+		values = append(values, append([]float64(nil), step.Values()...))
+	}
+
+	return values
+}
+
+func TestM3BlockConsolidation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	nan := math.NaN()
+	iters := newTestSeriesIters(ctrl,
+		// Dense series, the latest value at or before each step is used
+		newTestSeriesIter(ctrl, "dense", []m3ts.Datapoint{
+			dp(-30*time.Second, 1),
+			dp(30*time.Second, 2),
+			dp(time.Minute, 3),
+			dp(150*time.Second, 4),
+			dp(210*time.Second, 5),
+			dp(270*time.Second, 6),
+		}, nil),
+		// Sparse series, values expire after the lookback
+		newTestSeriesIter(ctrl, "sparse", []m3ts.Datapoint{
+			dp(-3*time.Minute, 10),
+			dp(2*time.Minute, 20),
+		}, nil),
+	)
+
+	block, err := NewM3Block(ident.StringID("ns"), iters, testBounds, ConsolidationOptions{
+		LookbackDuration: 2 * time.Minute,
+	}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, testBounds, block.Meta().Bounds)
+	expected := [][]float64{
+		{1, nan},
+		{3, nan},
+		{3, 20},
+		{4, 20},
+		{5, nan},
+	}
+
+	actual := stepValues(block)
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		for j := range expected[i] {
+			if math.IsNaN(expected[i][j]) {
+				assert.True(t, math.IsNaN(actual[i][j]), "step %d, series %d", i, j)
+			} else {
+				assert.Equal(t, expected[i][j], actual[i][j], "step %d, series %d", i, j)
+			}
+		}
+	}
+
+	metas := block.SeriesMeta()
+	require.Len(t, metas, 2)
+	assert.Equal(t, "dense", metas[0].Name)
+	assert.Equal(t, models.Tags{"id": "dense"}, metas[0].Tags)
+	assert.Equal(t, "sparse", metas[1].Name)
+
+	stepMetas := block.StepMeta()
+	require.Len(t, stepMetas, 5)
+	assert.Equal(t, testStart.Add(time.Minute), stepMetas[1].Time)
+
+	seriesIter := block.SeriesIter()
+	assert.Equal(t, 2, seriesIter.SeriesCount())
+	require.True(t, seriesIter.Next())
+	series := seriesIter.Current()
+	assert.Equal(t, "dense", series.Name())
+	assert.Equal(t, 5, series.Len())
+	assert.Equal(t, 4.0, series.Values().ValueAt(3))
+
+	assert.NoError(t, block.Close())
+}
+
+func TestStitchedM3Block(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	nan := math.NaN()
+	earlier := newTestSeriesIters(ctrl,
+		newTestSeriesIter(ctrl, "both", []m3ts.Datapoint{dp(0, 1), dp(time.Minute, 2)}, nil),
+	)
+	later := newTestSeriesIters(ctrl,
+		newTestSeriesIter(ctrl, "later", []m3ts.Datapoint{dp(4*time.Minute, 30)}, nil),
+		newTestSeriesIter(ctrl, "both", []m3ts.Datapoint{dp(3*time.Minute, 4)}, nil),
+	)
+
+	series := StitchSeriesIterators(earlier, later)
+	require.Len(t, series, 2)
+	block, err := NewStitchedM3Block(ident.StringID("ns"), series, testBounds, ConsolidationOptions{
+		LookbackDuration: time.Minute + time.Second,
+	}, nil)
+	require.NoError(t, err)
+
+	metas := block.SeriesMeta()
+	require.Len(t, metas, 2)
+	assert.Equal(t, "both", metas[0].Name)
+	assert.Equal(t, "later", metas[1].Name)
+
+	expected := [][]float64{
+		{1, nan},
+		{2, nan},
+		{2, nan},
+		{4, nan},
+		{4, 30},
+	}
+
+	actual := stepValues(block)
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		for j := range expected[i] {
+			if math.IsNaN(expected[i][j]) {
+				assert.True(t, math.IsNaN(actual[i][j]), "step %d, series %d", i, j)
+			} else {
+				assert.Equal(t, expected[i][j], actual[i][j], "step %d, series %d", i, j)
+			}
+		}
+	}
+}
+
+func TestSeriesBlock(t *testing.T) {
+	nan := math.NaN()
+	seriesList := []*ts.Series{
+		ts.NewSeries("dense", ts.Datapoints{
+			{Timestamp: testStart.Add(-30 * time.Second), Value: 1},
+			{Timestamp: testStart.Add(time.Minute), Value: 3},
+			{Timestamp: testStart.Add(150 * time.Second), Value: 4},
+		}, models.Tags{"id": "dense"}),
+		ts.NewSeries("sparse", ts.Datapoints{
+			{Timestamp: testStart.Add(2 * time.Minute), Value: 20},
+		}, models.Tags{"id": "sparse"}),
+	}
+
+	block, err := NewSeriesBlock(seriesList, testBounds, ConsolidationOptions{
+		LookbackDuration: 2 * time.Minute,
+	})
+	require.NoError(t, err)
+
+	metas := block.SeriesMeta()
+	require.Len(t, metas, 2)
+	assert.Equal(t, "dense", metas[0].Name)
+	assert.Equal(t, models.Tags{"id": "sparse"}, metas[1].Tags)
+
+	expected := [][]float64{
+		{1, nan},
+		{3, nan},
+		{3, 20},
+		{4, 20},
+		{4, nan},
+	}
+
+	actual := stepValues(block)
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		for j := range expected[i] {
+			if math.IsNaN(expected[i][j]) {
+				assert.True(t, math.IsNaN(actual[i][j]), "step %d, series %d", i, j)
+			} else {
+				assert.Equal(t, expected[i][j], actual[i][j], "step %d, series %d", i, j)
+			}
+		}
+	}
+
+	_, err = NewSeriesBlock(seriesList, Bounds{Start: testStart, End: testStart}, NewConsolidationOptions())
+	assert.Error(t, err)
+}
+
+func TestSeriesBlockRangeLookbacks(t *testing.T) {
+	seriesList := []*ts.Series{
+		ts.NewSeries("sparse", ts.Datapoints{
+			{Timestamp: testStart.Add(30 * time.Second), Value: 1},
+		}, models.Tags{"id": "sparse"}),
+	}
+
+	// The steps from the start of the coarser range look further back
+	block, err := NewSeriesBlock(seriesList, testBounds, ConsolidationOptions{
+		LookbackDuration: time.Minute,
+		RangeLookbacks: []RangeLookback{
+			{Start: testStart, Lookback: time.Minute},
+			{Start: testStart.Add(3 * time.Minute), Lookback: 3 * time.Minute},
+		},
+	})
+	require.NoError(t, err)
+
+	actual := stepValues(block)
+	require.Len(t, actual, 5)
+	assert.True(t, math.IsNaN(actual[0][0]))
+	assert.Equal(t, 1.0, actual[1][0])
+	assert.True(t, math.IsNaN(actual[2][0]))
+	assert.Equal(t, 1.0, actual[3][0])
+	assert.True(t, math.IsNaN(actual[4][0]))
+}
+
+func TestM3BlockInvalidBounds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	iters := newTestSeriesIters(ctrl)
+	_, err := NewM3Block(ident.StringID("ns"), iters, Bounds{
+		Start: testStart,
+		End:   testStart.Add(time.Minute),
+	}, NewConsolidationOptions(), nil)
+	assert.Error(t, err)
+
+	_, err = NewM3Block(ident.StringID("ns"), iters, Bounds{
+		Start:    testStart,
+		End:      testStart,
+		StepSize: time.Minute,
+	}, NewConsolidationOptions(), nil)
+	assert.Error(t, err)
+}
+
+func TestM3BlockIteratorError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	iters := newTestSeriesIters(ctrl,
+		newTestSeriesIter(ctrl, "foo", []m3ts.Datapoint{dp(0, 1)}, errors.New("bad iterator")),
+	)
+
+	_, err := NewM3Block(ident.StringID("ns"), iters, testBounds, NewConsolidationOptions(), nil)
+	assert.Error(t, err)
+}
+
+func TestM3BlockPooling(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	valuesPool := NewValuesPool(pool.NewObjectPoolOptions().SetSize(8), 4)
+	valuesPool.Init()
+
+	opts := NewConsolidationOptions()
+	opts.ValuesPool = valuesPool
+	iters := newTestSeriesIters(ctrl,
+		newTestSeriesIter(ctrl, "foo", []m3ts.Datapoint{dp(0, 1)}, nil),
+	)
+
+	block, err := NewM3Block(ident.StringID("ns"), iters, testBounds, opts, nil)
+	require.NoError(t, err)
+	values := stepValues(block)
+	require.Len(t, values, 5)
+	for _, step := range values {
+		assert.Equal(t, []float64{1}, step)
+	}
+
+	require.NoError(t, block.Close())
+	reused := valuesPool.Get(2)
+	assert.Len(t, reused, 2)
+	assert.Equal(t, 4, cap(reused))
+}
+
+func TestM3BlockEnforcesLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	newIters := func() encoding.SeriesIterators {
+		return newTestSeriesIters(ctrl,
+			newTestSeriesIter(ctrl, "foo", []m3ts.Datapoint{dp(0, 1), dp(time.Minute, 2)}, nil),
+			newTestSeriesIter(ctrl, "bar", []m3ts.Datapoint{dp(0, 1), dp(time.Minute, 2)}, nil),
+		)
+	}
+
+	global := cost.NewEnforcer(cost.Limits{})
+	enforcer := global.Child(cost.Limits{MaxFetchedSeries: 1})
+	_, err := NewM3Block(ident.StringID("ns"), newIters(), testBounds, NewConsolidationOptions(), enforcer)
+	require.Error(t, err)
+	assert.True(t, coordErrors.IsResourceLimitError(err))
+	enforcer.Release()
+
+	enforcer = global.Child(cost.Limits{MaxFetchedDatapoints: 3})
+	_, err = NewM3Block(ident.StringID("ns"), newIters(), testBounds, NewConsolidationOptions(), enforcer)
+	require.Error(t, err)
+	assert.True(t, coordErrors.IsResourceLimitError(err))
+	enforcer.Release()
+
+	enforcer = global.Child(cost.Limits{MaxFetchedSeries: 2, MaxFetchedDatapoints: 4})
+	block, err := NewM3Block(ident.StringID("ns"), newIters(), testBounds, NewConsolidationOptions(), enforcer)
+	require.NoError(t, err)
+	assert.NoError(t, block.Close())
+	enforcer.Release()
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package exhaustive
+
+import (
+	"context"
+
+	"github.com/m3db/m3db/src/coordinator/storage"
+)
+
+type exhaustiveStorage struct {
+	storage.Storage
+}
+
+// NewStorage wraps a storage so that fetches which would return partial
+// results, as described by the warnings of the result, fail instead.
+func NewStorage(store storage.Storage) storage.Storage {
+	return &exhaustiveStorage{Storage: store}
+}
+
+func (s *exhaustiveStorage) Fetch(ctx context.Context, query *storage.FetchQuery, options *storage.FetchOptions) (*storage.FetchResult, error) {
+	result, err := s.Storage.Fetch(ctx, query, options)
+	if err != nil || result == nil {
+		return result, err
+	}
+
+	if err := result.Warnings.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *exhaustiveStorage) FetchTags(ctx context.Context, query *storage.FetchQuery, options *storage.FetchOptions) (*storage.SearchResults, error) {
+	result, err := s.Storage.FetchTags(ctx, query, options)
+	if err != nil || result == nil {
+		return result, err
+	}
+
+	if err := result.Warnings.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *exhaustiveStorage) CompleteTags(
+	ctx context.Context, query *storage.CompleteTagsQuery, options *storage.FetchOptions) (*storage.CompleteTagsResult, error) {
+	result, err := storage.CompleteTags(ctx, s.Storage, query, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := result.Warnings.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *exhaustiveStorage) FetchBlocks(
+	ctx context.Context, query *storage.FetchQuery, options *storage.FetchOptions) (storage.BlockResult, error) {
+	result, err := s.Storage.FetchBlocks(ctx, query, options)
+	if err != nil {
+		return storage.BlockResult{}, err
+	}
+
+	return exhaustiveBlocks(result)
+}
+
+type exhaustiveSubPlanExecutor struct {
+	executor storage.SubPlanExecutor
+}
+
+// NewSubPlanExecutor wraps a sub plan executor so that sub plans whose
+// results would be partial fail instead.
+func NewSubPlanExecutor(executor storage.SubPlanExecutor) storage.SubPlanExecutor {
+	return &exhaustiveSubPlanExecutor{executor: executor}
+}
+
+func (e *exhaustiveSubPlanExecutor) ExecuteSubPlan(
+	ctx context.Context, query *storage.SubPlanQuery, options *storage.FetchOptions) (storage.BlockResult, error) {
+	result, err := e.executor.ExecuteSubPlan(ctx, query, options)
+	if err != nil {
+		return storage.BlockResult{}, err
+	}
+
+	return exhaustiveBlocks(result)
+}
+
+// exhaustiveBlocks fails block results with warnings, closing their blocks
+func exhaustiveBlocks(result storage.BlockResult) (storage.BlockResult, error) {
+	if err := result.Warnings.Err(); err != nil {
+		for _, block := range result.Blocks {
+			block.Close()
+		}
+
+		return storage.BlockResult{}, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package exhaustive
+
+import (
+	"context"
+	"testing"
+
+	"github.com/m3db/m3db/src/coordinator/errors"
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/storage/mock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type warningStorage struct {
+	storage.Storage
+	warnings storage.Warnings
+}
+
+func (s *warningStorage) Fetch(context.Context, *storage.FetchQuery, *storage.FetchOptions) (*storage.FetchResult, error) {
+	return &storage.FetchResult{Warnings: s.warnings}, nil
+}
+
+func (s *warningStorage) FetchTags(context.Context, *storage.FetchQuery, *storage.FetchOptions) (*storage.SearchResults, error) {
+	return &storage.SearchResults{Metrics: models.Metrics{}, Warnings: s.warnings}, nil
+}
+
+func (s *warningStorage) FetchBlocks(context.Context, *storage.FetchQuery, *storage.FetchOptions) (storage.BlockResult, error) {
+	return storage.BlockResult{Warnings: s.warnings}, nil
+}
+
+func (s *warningStorage) ExecuteSubPlan(context.Context, *storage.SubPlanQuery, *storage.FetchOptions) (storage.BlockResult, error) {
+	return storage.BlockResult{Warnings: s.warnings}, nil
+}
+
+func newWarningStorage(warnings storage.Warnings) storage.Storage {
+	return NewStorage(&warningStorage{Storage: mock.NewMockStorage(), warnings: warnings})
+}
+
+func TestExhaustiveStorageFailsPartialResults(t *testing.T) {
+	store := newWarningStorage(storage.Warnings{storage.NewNonExhaustiveWarning(10)})
+
+	_, err := store.Fetch(context.TODO(), &storage.FetchQuery{}, &storage.FetchOptions{})
+	require.Error(t, err)
+	assert.True(t, errors.IsPartialResultError(err))
+	assert.Equal(t, "partial results: non_exhaustive: series limit of 10 reached", err.Error())
+
+	_, err = store.FetchTags(context.TODO(), &storage.FetchQuery{}, &storage.FetchOptions{})
+	assert.True(t, errors.IsPartialResultError(err))
+
+	_, err = store.FetchBlocks(context.TODO(), &storage.FetchQuery{}, &storage.FetchOptions{})
+	assert.True(t, errors.IsPartialResultError(err))
+}
+
+func TestExhaustiveStorageReturnsCompleteResults(t *testing.T) {
+	store := newWarningStorage(nil)
+
+	fetchResult, err := store.Fetch(context.TODO(), &storage.FetchQuery{}, &storage.FetchOptions{})
+	require.NoError(t, err)
+	assert.NotNil(t, fetchResult)
+
+	searchResults, err := store.FetchTags(context.TODO(), &storage.FetchQuery{}, &storage.FetchOptions{})
+	require.NoError(t, err)
+	assert.NotNil(t, searchResults)
+
+	_, err = store.FetchBlocks(context.TODO(), &storage.FetchQuery{}, &storage.FetchOptions{})
+	require.NoError(t, err)
+}
+
+func TestExhaustiveSubPlanExecutor(t *testing.T) {
+	executor := NewSubPlanExecutor(&warningStorage{
+		Storage:  mock.NewMockStorage(),
+		warnings: storage.Warnings{storage.NewNonExhaustiveWarning(10)},
+	})
+	_, err := executor.ExecuteSubPlan(context.TODO(), &storage.SubPlanQuery{}, &storage.FetchOptions{})
+	assert.True(t, errors.IsPartialResultError(err))
+
+	executor = NewSubPlanExecutor(&warningStorage{Storage: mock.NewMockStorage()})
+	_, err = executor.ExecuteSubPlan(context.TODO(), &storage.SubPlanQuery{}, &storage.FetchOptions{})
+	assert.NoError(t, err)
+}
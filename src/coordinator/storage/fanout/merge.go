@@ -0,0 +1,215 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fanout
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/ts"
+)
+
+// ConflictResolution decides the datapoints returned for a series which
+// several stores return
+type ConflictResolution int
+
+const (
+	// PreferLocal returns the series of the local store, or of the first
+	// store returning it if no local store does
+	PreferLocal ConflictResolution = iota
+	// PreferHighestResolution returns the series with the smallest interval
+	// between its datapoints, preferring the local store on ties
+	PreferHighestResolution
+	// UnionDatapoints returns the datapoints of all the stores, preferring
+	// the local store for datapoints at the same timestamp
+	UnionDatapoints
+	// Disjoint asserts that each series is held by a single store, which is
+	// required to push down sub plans as their partial results can't be
+	// merged by series; series several stores return anyway are resolved
+	// as with PreferLocal
+	Disjoint
+)
+
+var conflictResolutionNames = map[string]ConflictResolution{
+	"prefer_local":              PreferLocal,
+	"prefer_highest_resolution": PreferHighestResolution,
+	"union":                     UnionDatapoints,
+	"disjoint":                  Disjoint,
+}
+
+// ParseConflictResolution parses a conflict resolution from its name
+func ParseConflictResolution(str string) (ConflictResolution, error) {
+	if r, ok := conflictResolutionNames[str]; ok {
+		return r, nil
+	}
+
+	return 0, fmt.Errorf("invalid conflict resolution: %s", str)
+}
+
+func (r ConflictResolution) String() string {
+	for name, resolution := range conflictResolutionNames {
+		if r == resolution {
+			return name
+		}
+	}
+
+	return "unknown"
+}
+
+// byPrecedence orders the stores so that the local stores come first,
+// keeping the configured order otherwise
+func byPrecedence(stores []storage.Storage) []storage.Storage {
+	ordered := make([]storage.Storage, 0, len(stores))
+	for _, store := range stores {
+		if store.Type() == storage.TypeLocalDC {
+			ordered = append(ordered, store)
+		}
+	}
+
+	for _, store := range stores {
+		if store.Type() != storage.TypeLocalDC {
+			ordered = append(ordered, store)
+		}
+	}
+
+	return ordered
+}
+
+// mergeSeries merges the series lists of the stores, given in order of
+// precedence, into a single series for each series identity
+func mergeSeries(seriesLists [][]*ts.Series, resolution ConflictResolution) []*ts.Series {
+	var (
+		merged []*ts.Series
+		byID   = make(map[string]int)
+	)
+
+	for _, seriesList := range seriesLists {
+		for _, series := range seriesList {
+			id := series.Tags.ID()
+			idx, ok := byID[id]
+			if !ok {
+				byID[id] = len(merged)
+				merged = append(merged, series)
+				continue
+			}
+
+			merged[idx] = resolveConflict(merged[idx], series, resolution)
+		}
+	}
+
+	return merged
+}
+
+// resolveConflict returns the series to keep out of the series already
+// merged and one of the same identity from a store of lower precedence
+func resolveConflict(existing, candidate *ts.Series, resolution ConflictResolution) *ts.Series {
+	switch resolution {
+	case PreferHighestResolution:
+		if seriesResolution(candidate) < seriesResolution(existing) {
+			return candidate
+		}
+
+		return existing
+	case UnionDatapoints:
+		return ts.NewSeries(existing.Name(), unionDatapoints(existing.Values(), candidate.Values()), existing.Tags)
+	default:
+		return existing
+	}
+}
+
+// seriesResolution returns the resolution of fixed resolution series, or the
+// smallest interval between datapoints of raw series
+func seriesResolution(series *ts.Series) time.Duration {
+	values := series.Values()
+	if fixed, ok := values.(ts.FixedResolutionMutableValues); ok {
+		return fixed.Resolution()
+	}
+
+	resolution := time.Duration(math.MaxInt64)
+	for i := 1; i < values.Len(); i++ {
+		interval := values.DatapointAt(i).Timestamp.Sub(values.DatapointAt(i - 1).Timestamp)
+		if interval > 0 && interval < resolution {
+			resolution = interval
+		}
+	}
+
+	return resolution
+}
+
+// unionDatapoints merges two time ordered values, taking the datapoint of
+// the preferred values when both have one at the same timestamp
+func unionDatapoints(preferred, other ts.Values) ts.Datapoints {
+	var (
+		merged = make(ts.Datapoints, 0, preferred.Len()+other.Len())
+		i, j   int
+	)
+
+	for i < preferred.Len() && j < other.Len() {
+		p, o := preferred.DatapointAt(i), other.DatapointAt(j)
+		switch {
+		case p.Timestamp.Before(o.Timestamp):
+			merged = append(merged, p)
+			i++
+		case o.Timestamp.Before(p.Timestamp):
+			merged = append(merged, o)
+			j++
+		default:
+			merged = append(merged, p)
+			i++
+			j++
+		}
+	}
+
+	for ; i < preferred.Len(); i++ {
+		merged = append(merged, preferred.DatapointAt(i))
+	}
+
+	for ; j < other.Len(); j++ {
+		merged = append(merged, other.DatapointAt(j))
+	}
+
+	return merged
+}
+
+// mergeMetrics merges the metrics of the stores, keeping the first metric of
+// each identity
+func mergeMetrics(metricsLists []models.Metrics) models.Metrics {
+	var (
+		merged models.Metrics
+		seen   = make(map[string]struct{})
+	)
+
+	for _, metrics := range metricsLists {
+		for _, metric := range metrics {
+			if _, ok := seen[metric.ID]; ok {
+				continue
+			}
+
+			seen[metric.ID] = struct{}{}
+			merged = append(merged, metric)
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fanout
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/ts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConflictResolution(t *testing.T) {
+	for _, resolution := range []ConflictResolution{PreferLocal, PreferHighestResolution, UnionDatapoints, Disjoint} {
+		parsed, err := ParseConflictResolution(resolution.String())
+		require.NoError(t, err)
+		assert.Equal(t, resolution, parsed)
+	}
+
+	_, err := ParseConflictResolution("newest")
+	assert.Error(t, err)
+}
+
+func TestMergeSeriesPreferHighestResolution(t *testing.T) {
+	var (
+		now    = time.Now().Truncate(time.Hour)
+		tags   = models.Tags{"__name__": "foo"}
+		coarse = ts.NewSeries("foo", ts.NewFixedStepValues(time.Minute, 10, 1, now), tags)
+		fine   = ts.NewSeries("foo", ts.Datapoints{
+			{Timestamp: now, Value: 1},
+			{Timestamp: now.Add(10 * time.Second), Value: 2},
+		}, tags)
+		single = ts.NewSeries("foo", ts.Datapoints{{Timestamp: now, Value: 1}}, tags)
+	)
+
+	merged := mergeSeries([][]*ts.Series{{coarse}, {fine}}, PreferHighestResolution)
+	require.Len(t, merged, 1)
+	assert.Equal(t, fine, merged[0])
+
+	// Series with a single datapoint have no resolution to compare
+	merged = mergeSeries([][]*ts.Series{{coarse}, {single}}, PreferHighestResolution)
+	require.Len(t, merged, 1)
+	assert.Equal(t, coarse, merged[0])
+
+	merged = mergeSeries([][]*ts.Series{{coarse}, {fine}}, PreferLocal)
+	require.Len(t, merged, 1)
+	assert.Equal(t, coarse, merged[0])
+}
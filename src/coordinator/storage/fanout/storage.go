@@ -22,6 +22,7 @@ package fanout
 
 import (
 	"context"
+	"time"
 
 	"github.com/m3db/m3db/src/coordinator/errors"
 	"github.com/m3db/m3db/src/coordinator/models"
@@ -34,22 +35,74 @@ import (
 	"go.uber.org/zap"
 )
 
+// Options are the options of a fanout storage
+type Options struct {
+	// ConflictResolution decides the datapoints returned for series which
+	// several stores return
+	ConflictResolution ConflictResolution
+	// StoreTimeouts are the timeouts of the requests to each type of store,
+	// requests to types of store without a timeout only end with the query
+	StoreTimeouts map[storage.Type]time.Duration
+	// AllowPartialResults returns the results of the other stores along with
+	// a warning when a non-local store fails, rather than failing the query
+	AllowPartialResults bool
+	// RemoteStores are remote stores added and removed at runtime, fanned
+	// out to in addition to the static stores
+	RemoteStores StoreSet
+	// Consolidation describes how the series merged from several stores are
+	// consolidated into blocks
+	Consolidation storage.ConsolidationOptions
+}
+
+// StoreSet is a set of stores which changes at runtime
+type StoreSet interface {
+	// Stores returns the current stores
+	Stores() []storage.Storage
+}
+
+// NewOptions returns the default options, preferring the local store on
+// conflicts, allowing partial results and consolidating with the default
+// lookback
+func NewOptions() Options {
+	return Options{
+		ConflictResolution:  PreferLocal,
+		AllowPartialResults: true,
+		Consolidation:       storage.NewConsolidationOptions(),
+	}
+}
+
 type fanoutStorage struct {
 	stores      []storage.Storage
 	fetchFilter filter.Storage
 	writeFilter filter.Storage
+	opts        Options
 }
 
 // NewStorage creates a new remote Storage instance.
 func NewStorage(stores []storage.Storage, fetchFilter filter.Storage, writeFilter filter.Storage) storage.Storage {
-	return &fanoutStorage{stores: stores, fetchFilter: fetchFilter, writeFilter: writeFilter}
+	return NewStorageWithOptions(stores, fetchFilter, writeFilter, NewOptions())
+}
+
+// NewStorageWithOptions creates a new fanout Storage instance with the given options.
+func NewStorageWithOptions(
+	stores []storage.Storage,
+	fetchFilter filter.Storage,
+	writeFilter filter.Storage,
+	opts Options,
+) storage.Storage {
+	return &fanoutStorage{
+		stores:      stores,
+		fetchFilter: fetchFilter,
+		writeFilter: writeFilter,
+		opts:        opts,
+	}
 }
 
 func (s *fanoutStorage) Fetch(ctx context.Context, query *storage.FetchQuery, options *storage.FetchOptions) (*storage.FetchResult, error) {
-	stores := filterStores(s.stores, s.fetchFilter, query)
+	stores := byPrecedence(filterStores(s.allStores(), s.fetchFilter, query))
 	requests := make([]execution.Request, len(stores))
 	for idx, store := range stores {
-		requests[idx] = newFetchRequest(store, query, options)
+		requests[idx] = newFetchRequest(store, query, options, s.opts)
 	}
 
 	err := execution.ExecuteParallel(ctx, requests)
@@ -57,12 +110,12 @@ func (s *fanoutStorage) Fetch(ctx context.Context, query *storage.FetchQuery, op
 		return nil, err
 	}
 
-	return handleFetchResponses(requests)
+	return handleFetchResponses(requests, s.opts.ConflictResolution)
 }
 
-func handleFetchResponses(requests []execution.Request) (*storage.FetchResult, error) {
-	seriesList := make([]*ts.Series, 0, len(requests))
-	result := &storage.FetchResult{SeriesList: seriesList, LocalOnly: true}
+func handleFetchResponses(requests []execution.Request, resolution ConflictResolution) (*storage.FetchResult, error) {
+	seriesLists := make([][]*ts.Series, 0, len(requests))
+	result := &storage.FetchResult{LocalOnly: true}
 	for _, req := range requests {
 		fetchreq, ok := req.(*fetchRequest)
 		if !ok {
@@ -77,31 +130,76 @@ func handleFetchResponses(requests []execution.Request) (*storage.FetchResult, e
 			result.LocalOnly = false
 		}
 
-		result.SeriesList = append(result.SeriesList, fetchreq.result.SeriesList...)
+		seriesLists = append(seriesLists, fetchreq.result.SeriesList)
+		result.Warnings = append(result.Warnings, fetchreq.result.Warnings...)
+	}
+
+	result.SeriesList = mergeSeries(seriesLists, resolution)
+	if result.SeriesList == nil {
+		result.SeriesList = make([]*ts.Series, 0)
 	}
 
 	return result, nil
 }
 
 func (s *fanoutStorage) FetchTags(ctx context.Context, query *storage.FetchQuery, options *storage.FetchOptions) (*storage.SearchResults, error) {
-	var metrics models.Metrics
+	stores := byPrecedence(filterStores(s.allStores(), s.fetchFilter, query))
+	requests := make([]execution.Request, len(stores))
+	for idx, store := range stores {
+		requests[idx] = newSearchRequest(store, query, options, s.opts)
+	}
 
-	stores := filterStores(s.stores, s.fetchFilter, query)
-	for _, store := range stores {
-		results, err := store.FetchTags(ctx, query, options)
-		if err != nil {
-			return nil, err
-		}
-		metrics = append(metrics, results.Metrics...)
+	if err := execution.ExecuteParallel(ctx, requests); err != nil {
+		return nil, err
+	}
+
+	var (
+		metricsLists = make([]models.Metrics, 0, len(requests))
+		warnings     storage.Warnings
+	)
+
+	for _, req := range requests {
+		searchreq := req.(*searchRequest)
+		metricsLists = append(metricsLists, searchreq.result.Metrics)
+		warnings = append(warnings, searchreq.result.Warnings...)
 	}
 
-	result := &storage.SearchResults{Metrics: metrics}
+	result := &storage.SearchResults{Metrics: mergeMetrics(metricsLists), Warnings: warnings}
 
 	return result, nil
 }
 
+// CompleteTags merges the tags completed by each store
+func (s *fanoutStorage) CompleteTags(
+	ctx context.Context, query *storage.CompleteTagsQuery, options *storage.FetchOptions) (*storage.CompleteTagsResult, error) {
+	stores := filterStores(s.allStores(), s.fetchFilter, query)
+	requests := make([]execution.Request, len(stores))
+	for idx, store := range stores {
+		requests[idx] = newCompleteTagsRequest(store, query, options, s.opts)
+	}
+
+	if err := execution.ExecuteParallel(ctx, requests); err != nil {
+		return nil, err
+	}
+
+	var (
+		terms    = make(map[string]struct{})
+		warnings storage.Warnings
+	)
+
+	for _, req := range requests {
+		completeReq := req.(*completeTagsRequest)
+		for _, term := range completeReq.result.Terms {
+			terms[term] = struct{}{}
+		}
+		warnings = append(warnings, completeReq.result.Warnings...)
+	}
+
+	return &storage.CompleteTagsResult{Terms: storage.SortedTerms(terms), Warnings: warnings}, nil
+}
+
 func (s *fanoutStorage) Write(ctx context.Context, query *storage.WriteQuery) error {
-	stores := filterStores(s.stores, s.writeFilter, query)
+	stores := filterStores(s.allStores(), s.writeFilter, query)
 	requests := make([]execution.Request, len(stores))
 	for idx, store := range stores {
 		requests[idx] = newWriteRequest(store, query)
@@ -116,10 +214,145 @@ func (s *fanoutStorage) Type() storage.Type {
 
 func (s *fanoutStorage) FetchBlocks(
 	ctx context.Context, query *storage.FetchQuery, options *storage.FetchOptions) (storage.BlockResult, error) {
-	return storage.BlockResult{}, errors.ErrNotImplemented
+	var stores []storage.Storage
+	for _, store := range byPrecedence(filterStores(s.allStores(), s.fetchFilter, query)) {
+		if options != nil && options.LocalOnly && executesSubPlans(store) {
+			continue
+		}
+
+		stores = append(stores, store)
+	}
+
+	switch len(stores) {
+	case 0:
+		return storage.BlockResult{}, nil
+	case 1:
+		return s.fetchStoreBlocks(ctx, stores[0], query, options)
+	}
+
+	// Series returned by several stores are merged before being consolidated,
+	// so the raw series are fetched along with the lookback window before the
+	// first step
+	lookback := s.opts.Consolidation.LookbackDuration
+	if lookback <= 0 {
+		lookback = storage.DefaultLookbackDuration
+	}
+
+	fetchQuery := *query
+	fetchQuery.Start = query.Start.Add(-lookback)
+	requests := make([]execution.Request, len(stores))
+	for idx, store := range stores {
+		requests[idx] = newFetchRequest(store, &fetchQuery, options, s.opts)
+	}
+
+	if err := execution.ExecuteParallel(ctx, requests); err != nil {
+		return storage.BlockResult{}, err
+	}
+
+	result, err := handleFetchResponses(requests, s.opts.ConflictResolution)
+	if err != nil {
+		return storage.BlockResult{}, err
+	}
+
+	bounds := storage.Bounds{
+		Start:    query.Start,
+		End:      query.End,
+		StepSize: query.Interval,
+	}
+
+	block, err := storage.NewSeriesBlock(result.SeriesList, bounds, s.opts.Consolidation)
+	if err != nil {
+		return storage.BlockResult{}, err
+	}
+
+	return storage.BlockResult{Blocks: []storage.Block{block}, Warnings: result.Warnings}, nil
+}
+
+// fetchStoreBlocks returns the blocks of a single store, which consolidates
+// its series itself
+func (s *fanoutStorage) fetchStoreBlocks(
+	ctx context.Context,
+	store storage.Storage,
+	query *storage.FetchQuery,
+	options *storage.FetchOptions,
+) (storage.BlockResult, error) {
+	storeCtx, cancel := storeContext(ctx, store, s.opts)
+	defer cancel()
+
+	result, err := store.FetchBlocks(storeCtx, query, options)
+	if err != nil {
+		warning, ok := storeFailedWarning(ctx, store, err, s.opts)
+		if !ok {
+			return storage.BlockResult{}, err
+		}
+
+		return storage.BlockResult{Warnings: storage.Warnings{warning}}, nil
+	}
+
+	return result, nil
+}
+
+// ExecuteSubPlan has the remote stores which support it execute the sub plan
+// over the series they hold, the local stores being left to the caller. The
+// partial results of each store can't be merged by series, so this is only
+// allowed with the Disjoint conflict resolution
+func (s *fanoutStorage) ExecuteSubPlan(
+	ctx context.Context, query *storage.SubPlanQuery, options *storage.FetchOptions) (storage.BlockResult, error) {
+	if s.opts.ConflictResolution != Disjoint {
+		return storage.BlockResult{}, errors.ErrSubPlanStoresNotDisjoint
+	}
+
+	var requests []execution.Request
+	for _, store := range filterStores(s.allStores(), s.fetchFilter, query) {
+		if executesSubPlans(store) {
+			executor := store.(storage.SubPlanExecutor)
+			requests = append(requests, newSubPlanRequest(store, executor, query, options, s.opts))
+		}
+	}
+
+	if err := execution.ExecuteParallel(ctx, requests); err != nil {
+		return storage.BlockResult{}, err
+	}
+
+	var result storage.BlockResult
+	for _, req := range requests {
+		subPlanReq := req.(*subPlanRequest)
+		result.Blocks = append(result.Blocks, subPlanReq.result.Blocks...)
+		result.Warnings = append(result.Warnings, subPlanReq.result.Warnings...)
+	}
+
+	return result, nil
+}
+
+// executesSubPlans returns whether the store is a remote store executing the
+// sub plans pushed down to it, rather than having its series fetched
+func executesSubPlans(store storage.Storage) bool {
+	if store.Type() == storage.TypeLocalDC {
+		return false
+	}
+
+	_, ok := store.(storage.SubPlanExecutor)
+	return ok
+}
+
+// allStores returns the static stores along with the current remote stores
+func (s *fanoutStorage) allStores() []storage.Storage {
+	if s.opts.RemoteStores == nil {
+		return s.stores
+	}
+
+	remotes := s.opts.RemoteStores.Stores()
+	if len(remotes) == 0 {
+		return s.stores
+	}
+
+	stores := make([]storage.Storage, 0, len(s.stores)+len(remotes))
+	stores = append(stores, s.stores...)
+	return append(stores, remotes...)
 }
 
 func (s *fanoutStorage) Close() error {
+	// The remote stores are closed by the owner of the store set
 	var lastErr error
 	for idx, store := range s.stores {
 		// Keep going on error to close all storages
@@ -142,25 +375,187 @@ func filterStores(stores []storage.Storage, filterPolicy filter.Storage, query s
 	return filtered
 }
 
+// storeContext returns the context of a request to a store, with the timeout
+// of its type of store if any
+func storeContext(ctx context.Context, store storage.Storage, opts Options) (context.Context, context.CancelFunc) {
+	if timeout, ok := opts.StoreTimeouts[store.Type()]; ok && timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+
+	return ctx, func() {}
+}
+
+// storeFailedWarning returns the warning to surface in place of the error of
+// a failed store. When partial results are allowed results from remote stores
+// are best effort, so a failing remote store degrades the result rather than
+// failing the whole query.
+func storeFailedWarning(ctx context.Context, store storage.Storage, err error, opts Options) (storage.Warning, bool) {
+	if !opts.AllowPartialResults || store.Type() == storage.TypeLocalDC || ctx.Err() != nil {
+		return storage.Warning{}, false
+	}
+
+	logging.WithContext(ctx).Warn("dropping results of failed store",
+		zap.String("store", store.Type().String()), zap.Any("error", err))
+	return storage.NewStoreFailedWarning(store.Type(), err), true
+}
+
 type fetchRequest struct {
 	store   storage.Storage
 	query   *storage.FetchQuery
 	options *storage.FetchOptions
+	opts    Options
 	result  *storage.FetchResult
 }
 
-func newFetchRequest(store storage.Storage, query *storage.FetchQuery, options *storage.FetchOptions) execution.Request {
+func newFetchRequest(
+	store storage.Storage,
+	query *storage.FetchQuery,
+	options *storage.FetchOptions,
+	opts Options,
+) execution.Request {
 	return &fetchRequest{
 		store:   store,
 		query:   query,
 		options: options,
+		opts:    opts,
 	}
 }
 
 func (f *fetchRequest) Process(ctx context.Context) error {
-	result, err := f.store.Fetch(ctx, f.query, f.options)
+	storeCtx, cancel := storeContext(ctx, f.store, f.opts)
+	defer cancel()
+
+	result, err := f.store.Fetch(storeCtx, f.query, f.options)
+	if err != nil {
+		warning, ok := storeFailedWarning(ctx, f.store, err, f.opts)
+		if !ok {
+			return err
+		}
+
+		result = &storage.FetchResult{Warnings: storage.Warnings{warning}}
+	}
+
+	f.result = result
+	return nil
+}
+
+type searchRequest struct {
+	store   storage.Storage
+	query   *storage.FetchQuery
+	options *storage.FetchOptions
+	opts    Options
+	result  *storage.SearchResults
+}
+
+func newSearchRequest(
+	store storage.Storage,
+	query *storage.FetchQuery,
+	options *storage.FetchOptions,
+	opts Options,
+) execution.Request {
+	return &searchRequest{
+		store:   store,
+		query:   query,
+		options: options,
+		opts:    opts,
+	}
+}
+
+func (f *searchRequest) Process(ctx context.Context) error {
+	storeCtx, cancel := storeContext(ctx, f.store, f.opts)
+	defer cancel()
+
+	result, err := f.store.FetchTags(storeCtx, f.query, f.options)
 	if err != nil {
-		return err
+		warning, ok := storeFailedWarning(ctx, f.store, err, f.opts)
+		if !ok {
+			return err
+		}
+
+		result = &storage.SearchResults{Warnings: storage.Warnings{warning}}
+	}
+
+	f.result = result
+	return nil
+}
+
+type completeTagsRequest struct {
+	store   storage.Storage
+	query   *storage.CompleteTagsQuery
+	options *storage.FetchOptions
+	opts    Options
+	result  *storage.CompleteTagsResult
+}
+
+func newCompleteTagsRequest(
+	store storage.Storage,
+	query *storage.CompleteTagsQuery,
+	options *storage.FetchOptions,
+	opts Options,
+) execution.Request {
+	return &completeTagsRequest{
+		store:   store,
+		query:   query,
+		options: options,
+		opts:    opts,
+	}
+}
+
+func (f *completeTagsRequest) Process(ctx context.Context) error {
+	storeCtx, cancel := storeContext(ctx, f.store, f.opts)
+	defer cancel()
+
+	result, err := storage.CompleteTags(storeCtx, f.store, f.query, f.options)
+	if err != nil {
+		warning, ok := storeFailedWarning(ctx, f.store, err, f.opts)
+		if !ok {
+			return err
+		}
+
+		result = &storage.CompleteTagsResult{Warnings: storage.Warnings{warning}}
+	}
+
+	f.result = result
+	return nil
+}
+
+type subPlanRequest struct {
+	store    storage.Storage
+	executor storage.SubPlanExecutor
+	query    *storage.SubPlanQuery
+	options  *storage.FetchOptions
+	opts     Options
+	result   storage.BlockResult
+}
+
+func newSubPlanRequest(
+	store storage.Storage,
+	executor storage.SubPlanExecutor,
+	query *storage.SubPlanQuery,
+	options *storage.FetchOptions,
+	opts Options,
+) execution.Request {
+	return &subPlanRequest{
+		store:    store,
+		executor: executor,
+		query:    query,
+		options:  options,
+		opts:     opts,
+	}
+}
+
+func (f *subPlanRequest) Process(ctx context.Context) error {
+	storeCtx, cancel := storeContext(ctx, f.store, f.opts)
+	defer cancel()
+
+	result, err := f.executor.ExecuteSubPlan(storeCtx, f.query, f.options)
+	if err != nil {
+		warning, ok := storeFailedWarning(ctx, f.store, err, f.opts)
+		if !ok {
+			return err
+		}
+
+		result = storage.BlockResult{Warnings: storage.Warnings{warning}}
 	}
 
 	f.result = result
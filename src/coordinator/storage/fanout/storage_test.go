@@ -27,13 +27,16 @@ import (
 	"time"
 
 	"github.com/m3db/m3db/src/coordinator/errors"
+	"github.com/m3db/m3db/src/coordinator/models"
 	"github.com/m3db/m3db/src/coordinator/policy/filter"
 	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/storage/mock"
 	"github.com/m3db/m3db/src/coordinator/test"
 	"github.com/m3db/m3db/src/coordinator/test/local"
 	"github.com/m3db/m3db/src/coordinator/ts"
 	"github.com/m3db/m3db/src/coordinator/util/logging"
 	"github.com/m3db/m3db/src/dbnode/encoding"
+	"github.com/m3db/m3db/src/dbnode/storage/index"
 	"github.com/m3db/m3x/ident"
 
 	"github.com/golang/mock/gomock"
@@ -76,10 +79,10 @@ func setupFanoutRead(t *testing.T, output bool, response ...*fetchResponse) stor
 	store1, session1 := local.NewStorageAndSession(ctrl)
 	store2, session2 := local.NewStorageAndSession(ctrl)
 
-	session1.EXPECT().FetchTagged(gomock.Any(), gomock.Any(), gomock.Any()).Return(response[0].result, true, response[0].err)
-	session2.EXPECT().FetchTagged(gomock.Any(), gomock.Any(), gomock.Any()).Return(response[len(response)-1].result, true, response[len(response)-1].err)
-	session1.EXPECT().FetchTaggedIDs(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, false, errors.ErrNotImplemented)
-	session2.EXPECT().FetchTaggedIDs(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, false, errors.ErrNotImplemented)
+	session1.EXPECT().FetchTagged(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(response[0].result, true, response[0].err)
+	session2.EXPECT().FetchTagged(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(response[len(response)-1].result, true, response[len(response)-1].err)
+	session1.EXPECT().FetchTaggedIDs(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, false, errors.ErrNotImplemented)
+	session2.EXPECT().FetchTaggedIDs(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, false, errors.ErrNotImplemented)
 	stores := []storage.Storage{
 		store1, store2,
 	}
@@ -124,6 +127,125 @@ func TestFanoutReadSuccess(t *testing.T) {
 	assert.NoError(t, store.Close())
 }
 
+type failingStorage struct {
+	storage.Storage
+	err error
+}
+
+func (s *failingStorage) Fetch(context.Context, *storage.FetchQuery, *storage.FetchOptions) (*storage.FetchResult, error) {
+	return nil, s.err
+}
+
+func (s *failingStorage) FetchTags(context.Context, *storage.FetchQuery, *storage.FetchOptions) (*storage.SearchResults, error) {
+	return nil, s.err
+}
+
+func TestFanoutReadRemoteError(t *testing.T) {
+	setup()
+	ctrl := gomock.NewController(t)
+	localStore, session := local.NewStorageAndSession(ctrl)
+	session.EXPECT().FetchTagged(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeIterator(t), true, nil)
+	session.EXPECT().FetchTaggedIDs(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, false, errors.ErrNotImplemented)
+
+	remoteErr := fmt.Errorf("remote unavailable")
+	remoteStore := &failingStorage{Storage: mock.NewMockStorageWithType(storage.TypeRemoteDC), err: remoteErr}
+	store := NewStorage([]storage.Storage{localStore, remoteStore}, filterFunc(true), filterFunc(true))
+
+	res, err := store.Fetch(context.TODO(), &storage.FetchQuery{}, &storage.FetchOptions{})
+	require.NoError(t, err)
+	assert.Len(t, res.SeriesList, 1)
+	assert.False(t, res.LocalOnly)
+	assert.Equal(t, storage.Warnings{storage.NewStoreFailedWarning(storage.TypeRemoteDC, remoteErr)}, res.Warnings)
+
+	// Failures of the local store still fail the search
+	_, err = store.FetchTags(context.TODO(), &storage.FetchQuery{}, &storage.FetchOptions{})
+	assert.Error(t, err)
+}
+
+// seriesStorage returns fixed series for all fetches
+type seriesStorage struct {
+	storage.Storage
+	seriesList []*ts.Series
+}
+
+func (s *seriesStorage) Fetch(context.Context, *storage.FetchQuery, *storage.FetchOptions) (*storage.FetchResult, error) {
+	return &storage.FetchResult{SeriesList: s.seriesList}, nil
+}
+
+// blockingStorage blocks fetches until their context is done
+type blockingStorage struct {
+	storage.Storage
+}
+
+func (s *blockingStorage) Fetch(ctx context.Context, _ *storage.FetchQuery, _ *storage.FetchOptions) (*storage.FetchResult, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestFanoutReadMergesSeries(t *testing.T) {
+	setup()
+	now := time.Now().Truncate(time.Minute)
+	tags := models.Tags{"__name__": "foo"}
+	localStore := &seriesStorage{
+		Storage: mock.NewMockStorageWithType(storage.TypeLocalDC),
+		seriesList: []*ts.Series{ts.NewSeries("foo", ts.Datapoints{
+			{Timestamp: now, Value: 1},
+			{Timestamp: now.Add(2 * time.Minute), Value: 3},
+		}, tags)},
+	}
+	remoteStore := &seriesStorage{
+		Storage: mock.NewMockStorageWithType(storage.TypeRemoteDC),
+		seriesList: []*ts.Series{
+			ts.NewSeries("foo", ts.Datapoints{
+				{Timestamp: now.Add(time.Minute), Value: 2},
+				{Timestamp: now.Add(2 * time.Minute), Value: 30},
+			}, tags),
+			ts.NewSeries("bar", ts.Datapoints{{Timestamp: now, Value: 1}}, models.Tags{"__name__": "bar"}),
+		},
+	}
+
+	// The remote store is listed first but the local store takes precedence
+	stores := []storage.Storage{remoteStore, localStore}
+	store := NewStorage(stores, filterFunc(true), filterFunc(true))
+	res, err := store.Fetch(context.TODO(), &storage.FetchQuery{}, &storage.FetchOptions{})
+	require.NoError(t, err)
+	require.Len(t, res.SeriesList, 2)
+	assert.Equal(t, localStore.seriesList[0], res.SeriesList[0])
+	assert.Equal(t, "bar", res.SeriesList[1].Name())
+
+	opts := NewOptions()
+	opts.ConflictResolution = UnionDatapoints
+	store = NewStorageWithOptions(stores, filterFunc(true), filterFunc(true), opts)
+	res, err = store.Fetch(context.TODO(), &storage.FetchQuery{}, &storage.FetchOptions{})
+	require.NoError(t, err)
+	require.Len(t, res.SeriesList, 2)
+	assert.Equal(t, ts.Datapoints{
+		{Timestamp: now, Value: 1},
+		{Timestamp: now.Add(time.Minute), Value: 2},
+		{Timestamp: now.Add(2 * time.Minute), Value: 3},
+	}, res.SeriesList[0].Values())
+}
+
+func TestFanoutReadStoreTimeout(t *testing.T) {
+	setup()
+	localStore := &seriesStorage{Storage: mock.NewMockStorageWithType(storage.TypeLocalDC)}
+	remoteStore := &blockingStorage{Storage: mock.NewMockStorageWithType(storage.TypeRemoteDC)}
+	stores := []storage.Storage{localStore, remoteStore}
+
+	opts := NewOptions()
+	opts.StoreTimeouts = map[storage.Type]time.Duration{storage.TypeRemoteDC: time.Millisecond}
+	store := NewStorageWithOptions(stores, filterFunc(true), filterFunc(true), opts)
+	res, err := store.Fetch(context.TODO(), &storage.FetchQuery{}, &storage.FetchOptions{})
+	require.NoError(t, err)
+	require.Len(t, res.Warnings, 1)
+	assert.Equal(t, storage.WarningStoreFailed, res.Warnings[0].Name)
+
+	opts.AllowPartialResults = false
+	store = NewStorageWithOptions(stores, filterFunc(true), filterFunc(true), opts)
+	_, err = store.Fetch(context.TODO(), &storage.FetchQuery{}, &storage.FetchOptions{})
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
 func TestFanoutSearchEmpty(t *testing.T) {
 	store := setupFanoutRead(t, false)
 	res, err := store.FetchTags(context.TODO(), nil, nil)
@@ -138,6 +260,45 @@ func TestFanoutSearchError(t *testing.T) {
 	assert.Error(t, err)
 }
 
+// metricsStorage returns fixed metrics for all searches
+type metricsStorage struct {
+	storage.Storage
+	metrics models.Metrics
+}
+
+func (s *metricsStorage) FetchTags(context.Context, *storage.FetchQuery, *storage.FetchOptions) (*storage.SearchResults, error) {
+	return &storage.SearchResults{Metrics: s.metrics}, nil
+}
+
+func TestFanoutCompleteTagsMergesStores(t *testing.T) {
+	setup()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	localStore, session := local.NewStorageAndSession(ctrl)
+	session.EXPECT().Aggregate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]index.AggregateTerm{
+			{Term: []byte("a"), Count: 1},
+			{Term: []byte("c"), Count: 1},
+		}, true, nil)
+
+	// The remote store cannot complete tags so the tags of its series are used
+	remoteStore := &metricsStorage{
+		Storage: mock.NewMockStorageWithType(storage.TypeRemoteDC),
+		metrics: models.Metrics{
+			{Tags: models.Tags{"__name__": "up", "job": "b"}},
+			{Tags: models.Tags{"__name__": "up", "job": "c"}},
+			{Tags: models.Tags{"__name__": "up"}},
+		},
+	}
+
+	store := NewStorage([]storage.Storage{localStore, remoteStore}, filterFunc(true), filterFunc(true))
+	res, err := store.(storage.TagCompleter).CompleteTags(context.TODO(),
+		&storage.CompleteTagsQuery{TagName: "job"}, &storage.FetchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, res.Terms)
+}
+
 func TestFanoutWriteEmpty(t *testing.T) {
 	store := setupFanoutWrite(t, false, fmt.Errorf("write error"))
 	err := store.Write(context.TODO(), nil)
@@ -163,3 +324,118 @@ func TestFanoutWriteSuccess(t *testing.T) {
 	})
 	assert.NoError(t, err)
 }
+
+func TestFanoutFetchBlocksEmpty(t *testing.T) {
+	store := setupFanoutRead(t, false)
+	res, err := store.FetchBlocks(context.TODO(), nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, res.Blocks, 0)
+}
+
+func TestFanoutFetchBlocksMergesSeries(t *testing.T) {
+	setup()
+	now := time.Now().Truncate(time.Minute)
+	tags := models.Tags{"__name__": "foo"}
+	localStore := &seriesStorage{
+		Storage: mock.NewMockStorageWithType(storage.TypeLocalDC),
+		seriesList: []*ts.Series{ts.NewSeries("foo", ts.Datapoints{
+			{Timestamp: now, Value: 1},
+			{Timestamp: now.Add(2 * time.Minute), Value: 3},
+		}, tags)},
+	}
+	remoteStore := &seriesStorage{
+		Storage: mock.NewMockStorageWithType(storage.TypeRemoteDC),
+		seriesList: []*ts.Series{
+			ts.NewSeries("foo", ts.Datapoints{
+				{Timestamp: now.Add(time.Minute), Value: 2},
+				{Timestamp: now.Add(2 * time.Minute), Value: 30},
+			}, tags),
+			ts.NewSeries("bar", ts.Datapoints{{Timestamp: now, Value: 1}}, models.Tags{"__name__": "bar"}),
+		},
+	}
+
+	query := &storage.FetchQuery{
+		Start:    now,
+		End:      now.Add(3 * time.Minute),
+		Interval: time.Minute,
+	}
+
+	stepValues := func(block storage.Block) [][]float64 {
+		var values [][]float64
+		iter := block.StepIter()
+		for iter.Next() {
+			values = append(values, iter.Current().Values())
+		}
+
+		return values
+	}
+
+	opts := NewOptions()
+	opts.ConflictResolution = UnionDatapoints
+	stores := []storage.Storage{remoteStore, localStore}
+	store := NewStorageWithOptions(stores, filterFunc(true), filterFunc(true), opts)
+	res, err := store.FetchBlocks(context.TODO(), query, &storage.FetchOptions{})
+	require.NoError(t, err)
+	require.Len(t, res.Blocks, 1)
+
+	metas := res.Blocks[0].SeriesMeta()
+	require.Len(t, metas, 2)
+	assert.Equal(t, tags, metas[0].Tags)
+	assert.Equal(t, "bar", metas[1].Name)
+	assert.Equal(t, [][]float64{{1, 1}, {2, 1}, {3, 1}}, stepValues(res.Blocks[0]))
+
+	// The series of the local store are preferred by default
+	store = NewStorage(stores, filterFunc(true), filterFunc(true))
+	res, err = store.FetchBlocks(context.TODO(), query, &storage.FetchOptions{})
+	require.NoError(t, err)
+	require.Len(t, res.Blocks, 1)
+	assert.Equal(t, [][]float64{{1, 1}, {1, 1}, {3, 1}}, stepValues(res.Blocks[0]))
+}
+
+func TestFanoutFetchBlocksError(t *testing.T) {
+	store := setupFanoutRead(t, true)
+	_, err := store.FetchBlocks(context.TODO(), &storage.FetchQuery{}, &storage.FetchOptions{})
+	assert.Error(t, err)
+}
+
+// subPlanStorage is a remote store executing sub plans over its series
+type subPlanStorage struct {
+	seriesStorage
+	result storage.BlockResult
+}
+
+func (s *subPlanStorage) ExecuteSubPlan(context.Context, *storage.SubPlanQuery, *storage.FetchOptions) (storage.BlockResult, error) {
+	return s.result, nil
+}
+
+func TestFanoutExecuteSubPlanRequiresDisjoint(t *testing.T) {
+	setup()
+	now := time.Now().Truncate(time.Minute)
+	tags := models.Tags{"__name__": "foo"}
+	localStore := &seriesStorage{
+		Storage:    mock.NewMockStorageWithType(storage.TypeLocalDC),
+		seriesList: []*ts.Series{ts.NewSeries("foo", ts.Datapoints{{Timestamp: now, Value: 1}}, tags)},
+	}
+
+	// The remote store also holds the series of the local store, so its
+	// partial result would count the series a second time
+	remoteStore := &subPlanStorage{
+		seriesStorage: seriesStorage{
+			Storage:    mock.NewMockStorageWithType(storage.TypeRemoteDC),
+			seriesList: localStore.seriesList,
+		},
+		result: storage.BlockResult{Warnings: storage.Warnings{{Name: "remote", Message: "executed"}}},
+	}
+
+	stores := []storage.Storage{localStore, remoteStore}
+	store := NewStorage(stores, filterFunc(true), filterFunc(true))
+	_, err := store.(storage.SubPlanExecutor).ExecuteSubPlan(context.TODO(), &storage.SubPlanQuery{}, &storage.FetchOptions{})
+	assert.Equal(t, errors.ErrSubPlanStoresNotDisjoint, err)
+
+	opts := NewOptions()
+	opts.ConflictResolution = Disjoint
+	store = NewStorageWithOptions(stores, filterFunc(true), filterFunc(true), opts)
+	res, err := store.(storage.SubPlanExecutor).ExecuteSubPlan(context.TODO(), &storage.SubPlanQuery{}, &storage.FetchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, remoteStore.result.Warnings, res.Warnings)
+}
@@ -75,8 +75,15 @@ func TagsToIdentTagIterator(tags models.Tags) ident.TagIterator {
 
 // FetchOptionsToM3Options converts a set of coordinator options to M3 options
 func FetchOptionsToM3Options(fetchOptions *FetchOptions, fetchQuery *FetchQuery) index.QueryOptions {
+	limit := fetchOptions.Limit
+	if max := int(fetchOptions.Enforcer.Limits().MaxFetchedSeries); max > 0 && (limit <= 0 || max < limit) {
+		// Fetch one series past the limit so that the enforcer can detect
+		// queries exceeding it rather than silently truncating the results
+		limit = max + 1
+	}
+
 	return index.QueryOptions{
-		Limit:          fetchOptions.Limit,
+		Limit:          limit,
 		StartInclusive: fetchQuery.Start,
 		EndExclusive:   fetchQuery.End,
 	}
@@ -103,9 +110,21 @@ func matcherToQuery(matcher *models.Matcher) (idx.Query, error) {
 	case models.MatchRegexp:
 		return idx.NewRegexpQuery([]byte(matcher.Name), []byte(matcher.Value))
 
+	case models.MatchNotRegexp:
+		q, err := idx.NewRegexpQuery([]byte(matcher.Name), []byte(matcher.Value))
+		if err != nil {
+			return idx.Query{}, err
+		}
+
+		return idx.NewNegationQuery(q), nil
+
 	case models.MatchEqual:
 		return idx.NewTermQuery([]byte(matcher.Name), []byte(matcher.Value)), nil
 
+	case models.MatchNotEqual:
+		q := idx.NewTermQuery([]byte(matcher.Name), []byte(matcher.Value))
+		return idx.NewNegationQuery(q), nil
+
 	default:
 		return idx.Query{}, fmt.Errorf("unsupported query type %v", matcher)
 
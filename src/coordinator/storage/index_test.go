@@ -0,0 +1,105 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/m3db/m3db/src/coordinator/cost"
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3ninx/idx"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchQueryToM3Query(t *testing.T) {
+	tests := []struct {
+		name     string
+		matcher  models.Matchers
+		expected idx.Query
+	}{
+		{
+			name: "exact match",
+			matcher: models.Matchers{
+				{Type: models.MatchEqual, Name: "t1", Value: "v1"},
+			},
+			expected: idx.NewTermQuery([]byte("t1"), []byte("v1")),
+		},
+		{
+			name: "exact match negated",
+			matcher: models.Matchers{
+				{Type: models.MatchNotEqual, Name: "t1", Value: "v1"},
+			},
+			expected: idx.NewNegationQuery(idx.NewTermQuery([]byte("t1"), []byte("v1"))),
+		},
+		{
+			name: "regex match",
+			matcher: models.Matchers{
+				{Type: models.MatchRegexp, Name: "t1", Value: "v1"},
+			},
+			expected: mustRegexpQuery(t, "t1", "v1"),
+		},
+		{
+			name: "regex match negated",
+			matcher: models.Matchers{
+				{Type: models.MatchNotRegexp, Name: "t1", Value: "v1"},
+			},
+			expected: idx.NewNegationQuery(mustRegexpQuery(t, "t1", "v1")),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fetchQuery := &FetchQuery{TagMatchers: test.matcher}
+			m3Query, err := FetchQueryToM3Query(fetchQuery)
+			require.NoError(t, err)
+			expected, err := idx.Marshal(idx.NewConjunctionQuery(test.expected))
+			require.NoError(t, err)
+			actual, err := idx.Marshal(m3Query.Query)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func mustRegexpQuery(t *testing.T, name, value string) idx.Query {
+	q, err := idx.NewRegexpQuery([]byte(name), []byte(value))
+	require.NoError(t, err)
+	return q
+}
+
+func TestFetchOptionsToM3OptionsLimit(t *testing.T) {
+	query := &FetchQuery{}
+	enforcer := cost.NewEnforcer(cost.Limits{}).Child(cost.Limits{MaxFetchedSeries: 10})
+
+	opts := FetchOptionsToM3Options(&FetchOptions{Limit: 5}, query)
+	assert.Equal(t, 5, opts.Limit)
+
+	opts = FetchOptionsToM3Options(&FetchOptions{Limit: 5, Enforcer: enforcer}, query)
+	assert.Equal(t, 5, opts.Limit)
+
+	opts = FetchOptionsToM3Options(&FetchOptions{Limit: 20, Enforcer: enforcer}, query)
+	assert.Equal(t, 11, opts.Limit)
+
+	opts = FetchOptionsToM3Options(&FetchOptions{Enforcer: enforcer}, query)
+	assert.Equal(t, 11, opts.Limit)
+}
@@ -0,0 +1,263 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingest
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+
+	xerrors "github.com/m3db/m3x/errors"
+	"github.com/uber-go/tally"
+)
+
+const (
+	reasonEmptyName       = "empty_name"
+	reasonDuplicateName   = "duplicate_name"
+	reasonMissingName     = "missing_name"
+	reasonTooManyTags     = "too_many_tags"
+	reasonNameTooLong     = "name_too_long"
+	reasonValueTooLong    = "value_too_long"
+	ruleNameTag           = "rule"
+	rejectReasonTag       = "reason"
+	defaultRuleNamePrefix = "rule-"
+)
+
+// Options are the options of a processor
+type Options struct {
+	// Rules are applied in order to the tags of each series, each rule
+	// seeing the tags as rewritten by the previous ones.
+	Rules []Rule
+	// Limits are enforced once the rules are applied.
+	Limits Limits
+	// NormalizeNames replaces the characters of tag names which are not
+	// valid in Prometheus label names with underscores.
+	NormalizeNames bool
+	// Scope is the scope of the metrics of the rule hits, dropped, rejected
+	// and truncated series.
+	Scope tally.Scope
+}
+
+// Processor validates, normalizes and rewrites the tags of written series
+// so that buggy clients cannot blow up the cardinality of the index
+type Processor struct {
+	opts    Options
+	metrics processorMetrics
+}
+
+type processorMetrics struct {
+	ruleHits  []tally.Counter
+	dropped   tally.Counter
+	rejected  map[string]tally.Counter
+	truncated tally.Counter
+}
+
+func newProcessorMetrics(scope tally.Scope, rules []Rule) processorMetrics {
+	ruleHits := make([]tally.Counter, 0, len(rules))
+	for i, rule := range rules {
+		name := rule.Name
+		if name == "" {
+			name = defaultRuleNamePrefix + strconv.Itoa(i)
+		}
+
+		ruleHits = append(ruleHits, scope.Tagged(map[string]string{ruleNameTag: name}).Counter("rule.hits"))
+	}
+
+	rejected := make(map[string]tally.Counter)
+	for _, reason := range []string{
+		reasonEmptyName, reasonDuplicateName, reasonMissingName,
+		reasonTooManyTags, reasonNameTooLong, reasonValueTooLong,
+	} {
+		rejected[reason] = scope.Tagged(map[string]string{rejectReasonTag: reason}).Counter("series.rejected")
+	}
+
+	return processorMetrics{
+		ruleHits:  ruleHits,
+		dropped:   scope.Counter("series.dropped"),
+		rejected:  rejected,
+		truncated: scope.Counter("tags.truncated"),
+	}
+}
+
+// NewProcessor returns a new processor, validating its rules.
+func NewProcessor(opts Options) (*Processor, error) {
+	for i, rule := range opts.Rules {
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid ingest rule %d: %v", i, err)
+		}
+	}
+
+	scope := opts.Scope
+	if scope == nil {
+		scope = tally.NoopScope
+	}
+
+	return &Processor{
+		opts:    opts,
+		metrics: newProcessorMetrics(scope, opts.Rules),
+	}, nil
+}
+
+// Process returns the tags which a series is written with, leaving the given
+// tags unmodified. It returns false if the series is dropped by a rule, and
+// an invalid params error if the series is rejected for exceeding a limit.
+func (p *Processor) Process(tags models.Tags) (models.Tags, bool, error) {
+	// Tags with empty values are the same as missing tags in Prometheus, so
+	// they are removed rather than written
+	processed := make(models.Tags, len(tags))
+	for name, value := range tags {
+		if value == "" {
+			continue
+		}
+
+		if p.opts.NormalizeNames {
+			name = normalizeName(name)
+		}
+
+		// Names normalized to the same name would otherwise keep the value of
+		// whichever tag happens to be iterated last
+		if _, ok := processed[name]; ok {
+			err := fmt.Errorf("series %s has several tags normalized to the name %s", tags.ID(), name)
+			return nil, false, p.reject(reasonDuplicateName, err)
+		}
+
+		processed[name] = value
+	}
+
+	if _, ok := processed[""]; ok {
+		return nil, false, p.reject(reasonEmptyName, fmt.Errorf("series %s has a tag with an empty name", tags.ID()))
+	}
+
+	_, hasName := processed[models.MetricName]
+	for i, rule := range p.opts.Rules {
+		if !rule.matches(processed) {
+			continue
+		}
+
+		p.metrics.ruleHits[i].Inc(1)
+		if !rule.apply(processed) {
+			p.metrics.dropped.Inc(1)
+			return nil, false, nil
+		}
+	}
+
+	// Rules dropping or renaming the name of a series would write it under
+	// the same empty name as every other such series
+	if _, ok := processed[models.MetricName]; hasName && !ok {
+		err := fmt.Errorf("series %s has no name once the ingest rules are applied", tags.ID())
+		return nil, false, p.reject(reasonMissingName, err)
+	}
+
+	processed, err := p.enforceLimits(processed)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return processed, true, nil
+}
+
+func (p *Processor) enforceLimits(tags models.Tags) (models.Tags, error) {
+	limits := p.opts.Limits
+	if limits.MaxTags > 0 && len(tags) > limits.MaxTags {
+		err := fmt.Errorf("series %s has %d tags, exceeding the limit of %d", tags.ID(), len(tags), limits.MaxTags)
+		return nil, p.reject(reasonTooManyTags, err)
+	}
+
+	// The truncated tags replace the original ones once all tags are checked,
+	// so that they are not checked again and the tags are left intact when
+	// the series is rejected
+	var (
+		truncated models.Tags
+		replaced  map[string]struct{}
+	)
+	for name, value := range tags {
+		nameTooLong := limits.MaxNameLength > 0 && len(name) > limits.MaxNameLength
+		valueTooLong := limits.MaxValueLength > 0 && len(value) > limits.MaxValueLength
+		if !nameTooLong && !valueTooLong {
+			continue
+		}
+
+		if !limits.Truncate {
+			if nameTooLong {
+				err := fmt.Errorf("series %s has a tag name exceeding the limit of %d bytes", tags.ID(), limits.MaxNameLength)
+				return nil, p.reject(reasonNameTooLong, err)
+			}
+
+			err := fmt.Errorf("series %s has a tag value exceeding the limit of %d bytes", tags.ID(), limits.MaxValueLength)
+			return nil, p.reject(reasonValueTooLong, err)
+		}
+
+		if truncated == nil {
+			truncated, replaced = make(models.Tags), make(map[string]struct{})
+		}
+
+		replaced[name] = struct{}{}
+		if nameTooLong {
+			name = truncate(name, limits.MaxNameLength)
+		}
+
+		if valueTooLong {
+			value = truncate(value, limits.MaxValueLength)
+		}
+
+		if _, ok := truncated[name]; ok {
+			return nil, p.rejectDuplicateTruncated(tags, name)
+		}
+
+		truncated[name] = value
+	}
+
+	if len(truncated) == 0 {
+		return tags, nil
+	}
+
+	for name := range truncated {
+		if _, ok := tags[name]; ok {
+			if _, ok := replaced[name]; !ok {
+				return nil, p.rejectDuplicateTruncated(tags, name)
+			}
+		}
+	}
+
+	p.metrics.truncated.Inc(int64(len(truncated)))
+	for name := range replaced {
+		delete(tags, name)
+	}
+
+	for name, value := range truncated {
+		tags[name] = value
+	}
+
+	return tags, nil
+}
+
+// rejectDuplicateTruncated rejects a series with several tags named the same
+// once truncated, rather than keeping whichever value is iterated last
+func (p *Processor) rejectDuplicateTruncated(tags models.Tags, name string) error {
+	err := fmt.Errorf("series %s has several tags truncated to the name %s", tags.ID(), name)
+	return p.reject(reasonDuplicateName, err)
+}
+
+func (p *Processor) reject(reason string, err error) error {
+	p.metrics.rejected[reason].Inc(1)
+	return xerrors.NewInvalidParamsError(err)
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingest
+
+import (
+	"testing"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+
+	xerrors "github.com/m3db/m3x/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func TestProcessorRules(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	p, err := NewProcessor(Options{
+		Rules: []Rule{
+			{
+				Name:   "drop-debug",
+				Filter: models.Matchers{mustMatcher(t, models.MatchRegexp, models.MetricName, "debug_.*")},
+				Action: ActionDrop,
+			},
+			{
+				Filter: models.Matchers{mustMatcher(t, models.MatchEqual, "job", "api")},
+				Action: ActionRenameTags,
+				Tags:   map[string]string{"host": "instance"},
+			},
+			{
+				// Sees the tags renamed by the previous rule
+				Filter: models.Matchers{mustMatcher(t, models.MatchRegexp, "instance", ".+")},
+				Action: ActionAddTags,
+				Tags:   map[string]string{"env": "prod"},
+			},
+		},
+		Scope: scope,
+	})
+	require.NoError(t, err)
+
+	input := models.Tags{models.MetricName: "requests", "job": "api", "host": "a", "empty": ""}
+	tags, ok, err := p.Process(input)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, models.Tags{models.MetricName: "requests", "job": "api", "instance": "a", "env": "prod"}, tags)
+
+	// The input tags are not modified
+	assert.Equal(t, models.Tags{models.MetricName: "requests", "job": "api", "host": "a", "empty": ""}, input)
+
+	_, ok, err = p.Process(models.Tags{models.MetricName: "debug_requests", "job": "api"})
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	counters := scope.Snapshot().Counters()
+	assert.Equal(t, int64(1), counters["rule.hits+rule=drop-debug"].Value())
+	assert.Equal(t, int64(1), counters["rule.hits+rule=rule-1"].Value())
+	assert.Equal(t, int64(1), counters["rule.hits+rule=rule-2"].Value())
+	assert.Equal(t, int64(1), counters["series.dropped+"].Value())
+}
+
+func TestProcessorInvalidRule(t *testing.T) {
+	_, err := NewProcessor(Options{Rules: []Rule{{Action: ActionDrop}}})
+	assert.Error(t, err)
+}
+
+func TestProcessorNormalizeNames(t *testing.T) {
+	p, err := NewProcessor(Options{NormalizeNames: true})
+	require.NoError(t, err)
+
+	tags, ok, err := p.Process(models.Tags{models.MetricName: "cpu.idle", "host-name": "a.b"})
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, models.Tags{models.MetricName: "cpu.idle", "host_name": "a.b"}, tags)
+}
+
+func TestProcessorRejectsOverLimits(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	p, err := NewProcessor(Options{
+		Limits: Limits{MaxTags: 2, MaxNameLength: 4, MaxValueLength: 3},
+		Scope:  scope,
+	})
+	require.NoError(t, err)
+
+	for _, tags := range []models.Tags{
+		{"a": "1", "b": "2", "c": "3"},
+		{"abcde": "1"},
+		{"a": "1234"},
+		{"": "1"},
+	} {
+		_, ok, err := p.Process(tags)
+		require.Error(t, err, tags.ID())
+		assert.False(t, ok)
+		assert.NotNil(t, xerrors.GetInnerInvalidParamsError(err), tags.ID())
+	}
+
+	tags, ok, err := p.Process(models.Tags{"abcd": "123", "b": "2"})
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, models.Tags{"abcd": "123", "b": "2"}, tags)
+
+	counters := scope.Snapshot().Counters()
+	for _, reason := range []string{reasonTooManyTags, reasonNameTooLong, reasonValueTooLong, reasonEmptyName} {
+		assert.Equal(t, int64(1), counters["series.rejected+reason="+reason].Value(), reason)
+	}
+}
+
+func TestProcessorTruncatesOverLimits(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	p, err := NewProcessor(Options{
+		Limits: Limits{MaxNameLength: 4, MaxValueLength: 3, Truncate: true},
+		Scope:  scope,
+	})
+	require.NoError(t, err)
+
+	tags, ok, err := p.Process(models.Tags{"abcdef": "1", "b": "23456", "c": "3"})
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, models.Tags{"abcd": "1", "b": "234", "c": "3"}, tags)
+	assert.Equal(t, int64(2), scope.Snapshot().Counters()["tags.truncated+"].Value())
+}
+
+func TestProcessorRejectsDuplicateNames(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	p, err := NewProcessor(Options{
+		NormalizeNames: true,
+		Limits:         Limits{MaxNameLength: 4, Truncate: true},
+		Scope:          scope,
+	})
+	require.NoError(t, err)
+
+	for _, tags := range []models.Tags{
+		// Normalized to the same name
+		{"a.b": "1", "a-b": "2"},
+		{"a.b": "1", "a_b": "2"},
+		// Truncated to the same name
+		{"abcde": "1", "abcdf": "2"},
+		{"abcde": "1", "abcd": "2"},
+	} {
+		for i := 0; i < 10; i++ {
+			_, ok, err := p.Process(tags)
+			require.Error(t, err, tags.ID())
+			assert.False(t, ok)
+			assert.NotNil(t, xerrors.GetInnerInvalidParamsError(err), tags.ID())
+		}
+	}
+
+	assert.Equal(t, int64(40), scope.Snapshot().Counters()["series.rejected+reason="+reasonDuplicateName].Value())
+}
+
+func TestProcessorRejectsSeriesLosingName(t *testing.T) {
+	p, err := NewProcessor(Options{
+		Rules: []Rule{
+			{
+				Filter: models.Matchers{mustMatcher(t, models.MatchEqual, "job", "api")},
+				Action: ActionDropTags,
+				Tags:   map[string]string{models.MetricName: ""},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	_, ok, err := p.Process(models.Tags{models.MetricName: "requests", "job": "api"})
+	require.Error(t, err)
+	assert.False(t, ok)
+	assert.NotNil(t, xerrors.GetInnerInvalidParamsError(err))
+
+	// Series written without a name are left to the limits
+	tags, ok, err := p.Process(models.Tags{"job": "api", "instance": "a"})
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, models.Tags{"job": "api", "instance": "a"}, tags)
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingest
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+)
+
+var (
+	errNoFilter      = errors.New("rule has no filter")
+	errNoRuleTags    = errors.New("rule has no tags")
+	errRenameToEmpty = errors.New("rule renames a tag to an empty name")
+	errAddEmptyTag   = errors.New("rule adds a tag with an empty name or value")
+)
+
+// Action is the action of a rule on the series it matches
+type Action int
+
+const (
+	// ActionDrop drops the series, which is not written
+	ActionDrop Action = iota
+	// ActionDropTags removes tags from the series
+	ActionDropTags
+	// ActionRenameTags renames tags of the series
+	ActionRenameTags
+	// ActionAddTags adds tags to the series, replacing existing values
+	ActionAddTags
+)
+
+var actionNames = map[Action]string{
+	ActionDrop:       "drop",
+	ActionDropTags:   "drop_tags",
+	ActionRenameTags: "rename_tags",
+	ActionAddTags:    "add_tags",
+}
+
+func (a Action) String() string {
+	if name, ok := actionNames[a]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("unknown(%d)", int(a))
+}
+
+// ParseAction parses an action from its name
+func ParseAction(str string) (Action, error) {
+	for action, name := range actionNames {
+		if name == str {
+			return action, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown ingest rule action: %s", str)
+}
+
+// Rule rewrites or drops the series matching its filter before they are
+// written, in the manner of a Prometheus relabel config
+type Rule struct {
+	// Name identifies the rule in the metrics of its hits
+	Name string
+	// Filter matches the series the rule applies to
+	Filter models.Matchers
+	// Action is what the rule does to the series it matches
+	Action Action
+	// Tags are the tags affected by the rule. ActionDropTags drops the tags
+	// with these names, ActionRenameTags renames the tags named by the keys
+	// to the values and ActionAddTags sets the tags to the values
+	Tags map[string]string
+}
+
+// Validate validates the rule
+func (r Rule) Validate() error {
+	if len(r.Filter) == 0 {
+		return errNoFilter
+	}
+
+	if _, ok := actionNames[r.Action]; !ok {
+		return fmt.Errorf("unknown ingest rule action: %v", r.Action)
+	}
+
+	if r.Action == ActionDrop {
+		return nil
+	}
+
+	if len(r.Tags) == 0 {
+		return errNoRuleTags
+	}
+
+	for name, value := range r.Tags {
+		switch {
+		case r.Action == ActionRenameTags && value == "":
+			return errRenameToEmpty
+		case r.Action == ActionAddTags && (name == "" || value == ""):
+			return errAddEmptyTag
+		}
+	}
+
+	return nil
+}
+
+func (r Rule) matches(tags models.Tags) bool {
+	for _, matcher := range r.Filter {
+		if !matcher.Matches(tags[matcher.Name]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// apply applies the rule to the tags, which it modifies in place, returning
+// false if the series is dropped
+func (r Rule) apply(tags models.Tags) bool {
+	switch r.Action {
+	case ActionDrop:
+		return false
+	case ActionDropTags:
+		for name := range r.Tags {
+			delete(tags, name)
+		}
+	case ActionRenameTags:
+		// Read all the renamed tags before writing any so that rules can
+		// swap the names of tags
+		renamed := make(models.Tags, len(r.Tags))
+		for from, to := range r.Tags {
+			if value, ok := tags[from]; ok {
+				renamed[to] = value
+				delete(tags, from)
+			}
+		}
+
+		for name, value := range renamed {
+			tags[name] = value
+		}
+	case ActionAddTags:
+		for name, value := range r.Tags {
+			tags[name] = value
+		}
+	}
+
+	return true
+}
+
+// Limits are the limits on the tags of written series, a zero value for any
+// limit disables it
+type Limits struct {
+	// MaxTags is the maximum number of tags of a series.
+	MaxTags int
+	// MaxNameLength is the maximum length in bytes of tag names.
+	MaxNameLength int
+	// MaxValueLength is the maximum length in bytes of tag values.
+	MaxValueLength int
+	// Truncate truncates names and values over their maximum length rather
+	// than rejecting the series.
+	Truncate bool
+}
+
+// isValidNameChar returns whether the character is valid in a tag name,
+// following the Prometheus label name syntax
+func isValidNameChar(c byte, first bool) bool {
+	return c == '_' ||
+		(c >= 'a' && c <= 'z') ||
+		(c >= 'A' && c <= 'Z') ||
+		(!first && c >= '0' && c <= '9')
+}
+
+// normalizeName replaces the characters of the name which are invalid in
+// Prometheus label names with underscores
+func normalizeName(name string) string {
+	valid := true
+	for i := 0; i < len(name) && valid; i++ {
+		valid = isValidNameChar(name[i], i == 0)
+	}
+
+	if valid {
+		return name
+	}
+
+	b := []byte(name)
+	for i := range b {
+		if !isValidNameChar(b[i], i == 0) {
+			b[i] = '_'
+		}
+	}
+
+	return string(b)
+}
+
+// truncate truncates the string to at most max bytes, without splitting a
+// multi byte character
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+
+	// Back off to the start of the character straddling the limit
+	end := max
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+
+	return s[:end]
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingest
+
+import (
+	"testing"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustMatcher(t *testing.T, matchType models.MatchType, name, value string) *models.Matcher {
+	m, err := models.NewMatcher(matchType, name, value)
+	require.NoError(t, err)
+	return m
+}
+
+func TestParseAction(t *testing.T) {
+	for _, action := range []Action{ActionDrop, ActionDropTags, ActionRenameTags, ActionAddTags} {
+		parsed, err := ParseAction(action.String())
+		require.NoError(t, err)
+		assert.Equal(t, action, parsed)
+	}
+
+	_, err := ParseAction("keep")
+	assert.Error(t, err)
+}
+
+func TestRuleValidate(t *testing.T) {
+	filter := models.Matchers{mustMatcher(t, models.MatchEqual, "job", "api")}
+
+	valid := []Rule{
+		{Filter: filter, Action: ActionDrop},
+		{Filter: filter, Action: ActionDropTags, Tags: map[string]string{"pod": ""}},
+		{Filter: filter, Action: ActionRenameTags, Tags: map[string]string{"host": "instance"}},
+		{Filter: filter, Action: ActionAddTags, Tags: map[string]string{"env": "prod"}},
+	}
+	for _, rule := range valid {
+		assert.NoError(t, rule.Validate(), rule.Action.String())
+	}
+
+	invalid := []Rule{
+		{Action: ActionDrop},
+		{Filter: filter, Action: Action(10)},
+		{Filter: filter, Action: ActionDropTags},
+		{Filter: filter, Action: ActionRenameTags, Tags: map[string]string{"host": ""}},
+		{Filter: filter, Action: ActionAddTags, Tags: map[string]string{"env": ""}},
+	}
+	for _, rule := range invalid {
+		assert.Error(t, rule.Validate(), rule.Action.String())
+	}
+}
+
+func TestRuleApply(t *testing.T) {
+	tags := models.Tags{"host": "a", "instance": "b", "pod": "c"}
+
+	assert.False(t, Rule{Action: ActionDrop}.apply(tags.Clone()))
+
+	dropped := tags.Clone()
+	require.True(t, Rule{Action: ActionDropTags, Tags: map[string]string{"pod": "", "missing": ""}}.apply(dropped))
+	assert.Equal(t, models.Tags{"host": "a", "instance": "b"}, dropped)
+
+	swapped := tags.Clone()
+	require.True(t, Rule{Action: ActionRenameTags, Tags: map[string]string{"host": "instance", "instance": "host"}}.apply(swapped))
+	assert.Equal(t, models.Tags{"host": "b", "instance": "a", "pod": "c"}, swapped)
+
+	added := tags.Clone()
+	require.True(t, Rule{Action: ActionAddTags, Tags: map[string]string{"pod": "d", "env": "prod"}}.apply(added))
+	assert.Equal(t, models.Tags{"host": "a", "instance": "b", "pod": "d", "env": "prod"}, added)
+}
+
+func TestNormalizeName(t *testing.T) {
+	assert.Equal(t, "http_requests", normalizeName("http_requests"))
+	assert.Equal(t, "__name__", normalizeName("__name__"))
+	assert.Equal(t, "http_requests_total", normalizeName("http.requests-total"))
+	assert.Equal(t, "_xx", normalizeName("0xx"))
+	assert.Equal(t, "a__b", normalizeName("a=,b"))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "abc", truncate("abc", 3))
+	assert.Equal(t, "ab", truncate("abc", 2))
+	// "é" is two bytes and is not split
+	assert.Equal(t, "ab", truncate("abé", 3))
+	assert.Equal(t, "abé", truncate("abé", 4))
+	assert.Equal(t, "", truncate("日本", 2))
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingest
+
+import (
+	"context"
+
+	"github.com/m3db/m3db/src/coordinator/storage"
+)
+
+type ingestStorage struct {
+	storage.Storage
+	processor *Processor
+}
+
+// NewStorage wraps a storage so that the tags of the series written to it
+// are processed first, dropped series are not written and rejected ones
+// fail with an invalid params error
+func NewStorage(store storage.Storage, processor *Processor) storage.Storage {
+	return &ingestStorage{
+		Storage:   store,
+		processor: processor,
+	}
+}
+
+func (s *ingestStorage) Write(ctx context.Context, query *storage.WriteQuery) error {
+	if query == nil {
+		return s.Storage.Write(ctx, query)
+	}
+
+	tags, ok, err := s.processor.Process(query.Tags)
+	if err != nil || !ok {
+		return err
+	}
+
+	processed := *query
+	processed.Tags = tags
+	return s.Storage.Write(ctx, &processed)
+}
+
+// CompleteTags completes the tags from the underlying storage, so that its
+// index is used rather than the tags of every matching series
+func (s *ingestStorage) CompleteTags(
+	ctx context.Context,
+	query *storage.CompleteTagsQuery,
+	options *storage.FetchOptions,
+) (*storage.CompleteTagsResult, error) {
+	return storage.CompleteTags(ctx, s.Storage, query, options)
+}
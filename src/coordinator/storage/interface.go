@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/m3db/m3db/src/coordinator/cost"
 	"github.com/m3db/m3db/src/coordinator/models"
 	"github.com/m3db/m3db/src/coordinator/ts"
 	xtime "github.com/m3db/m3x/time"
@@ -42,6 +43,19 @@ const (
 	TypeMultiDC
 )
 
+func (t Type) String() string {
+	switch t {
+	case TypeLocalDC:
+		return "local"
+	case TypeRemoteDC:
+		return "remote"
+	case TypeMultiDC:
+		return "multi"
+	default:
+		return "unknown"
+	}
+}
+
 // Storage provides an interface for reading and writing to the tsdb
 type Storage interface {
 	Querier
@@ -59,8 +73,10 @@ type Query interface {
 	query()
 }
 
-func (q *FetchQuery) query() {}
-func (q *WriteQuery) query() {}
+func (q *FetchQuery) query()        {}
+func (q *WriteQuery) query()        {}
+func (q *SubPlanQuery) query()      {}
+func (q *CompleteTagsQuery) query() {}
 
 // FetchQuery represents the input query which is fetched from M3DB
 type FetchQuery struct {
@@ -68,6 +84,7 @@ type FetchQuery struct {
 	TagMatchers models.Matchers `json:"matchers"`
 	Start       time.Time       `json:"start"`
 	End         time.Time       `json:"end"`
+	Interval    time.Duration   `json:"interval"`
 }
 
 func (q *FetchQuery) String() string {
@@ -78,6 +95,12 @@ func (q *FetchQuery) String() string {
 type FetchOptions struct {
 	Limit    int
 	KillChan chan struct{}
+	// Enforcer accounts the series and datapoints fetched against the
+	// resource limits of the query, a nil enforcer applies no limits.
+	Enforcer *cost.Enforcer
+	// LocalOnly leaves the remote stores which execute sub plans out of the
+	// fetch, as they are executing the part of the query needing the series.
+	LocalOnly bool
 }
 
 // Querier handles queries against a storage.
@@ -91,6 +114,61 @@ type Querier interface {
 		ctx context.Context, query *FetchQuery, options *FetchOptions) (BlockResult, error)
 }
 
+// SubPlanQuery is a part of a query which a remote store executes over the
+// series it holds, returning the blocks produced by the node of the query
+// identified by NodeID. Node IDs are assigned deterministically by the
+// parser so both sides agree on them when parsing the same query.
+type SubPlanQuery struct {
+	Query    string
+	NodeID   string
+	Start    time.Time
+	End      time.Time
+	Now      time.Time
+	Interval time.Duration
+}
+
+func (q *SubPlanQuery) String() string {
+	return q.Query
+}
+
+// SubPlanExecutor executes parts of queries on remote stores, so that only
+// their results rather than the raw series are sent back.
+type SubPlanExecutor interface {
+	ExecuteSubPlan(
+		ctx context.Context, query *SubPlanQuery, options *FetchOptions) (BlockResult, error)
+}
+
+// CompleteTagsQuery resolves the distinct tag names of the series matching
+// the tag matchers, or the distinct values of a tag when TagName is set.
+type CompleteTagsQuery struct {
+	TagMatchers models.Matchers `json:"matchers"`
+	TagName     string          `json:"tagName"`
+	Start       time.Time       `json:"start"`
+	End         time.Time       `json:"end"`
+}
+
+func (q *CompleteTagsQuery) String() string {
+	if q.TagName != "" {
+		return fmt.Sprintf("values of %s", q.TagName)
+	}
+
+	return "tag names"
+}
+
+// CompleteTagsResult is the result of a tag completion
+type CompleteTagsResult struct {
+	// Terms are the sorted tag names or values
+	Terms    []string
+	Warnings Warnings
+}
+
+// TagCompleter completes tag names and values from the index of a store,
+// without fetching the tags of every matching series.
+type TagCompleter interface {
+	CompleteTags(
+		ctx context.Context, query *CompleteTagsQuery, options *FetchOptions) (*CompleteTagsResult, error)
+}
+
 // WriteQuery represents the input timeseries that is written to M3DB
 type WriteQuery struct {
 	Raw        string
@@ -112,7 +190,8 @@ type Appender interface {
 
 // SearchResults is the result from a search
 type SearchResults struct {
-	Metrics models.Metrics
+	Metrics  models.Metrics
+	Warnings Warnings
 }
 
 // FetchResult provides a fetch result and meta information
@@ -120,6 +199,8 @@ type FetchResult struct {
 	SeriesList []*ts.Series // The aggregated list of results across all underlying storage calls
 	LocalOnly  bool
 	HasNext    bool
+	// Warnings describe why the result may be incomplete
+	Warnings Warnings
 }
 
 // QueryResult is the result from a query
@@ -130,5 +211,6 @@ type QueryResult struct {
 
 // BlockResult is the result from a block query
 type BlockResult struct {
-	Blocks []Block
+	Blocks   []Block
+	Warnings Warnings
 }
@@ -22,30 +22,107 @@ package local
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
-	"github.com/m3db/m3db/src/coordinator/errors"
+	coordErrors "github.com/m3db/m3db/src/coordinator/errors"
 	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/policy/resolver"
 	"github.com/m3db/m3db/src/coordinator/storage"
 	"github.com/m3db/m3db/src/coordinator/ts"
 	"github.com/m3db/m3db/src/coordinator/util/execution"
+	"github.com/m3db/m3db/src/coordinator/util/tracing"
 	"github.com/m3db/m3db/src/dbnode/client"
+	"github.com/m3db/m3db/src/dbnode/encoding"
+	"github.com/m3db/m3db/src/dbnode/storage/index"
+	"github.com/m3db/m3metrics/policy"
 	"github.com/m3db/m3x/ident"
 	xtime "github.com/m3db/m3x/time"
 )
 
 const (
 	initRawFetchAllocSize = 32
+	// maxSeriesWriteConcurrency bounds the datapoints of a single series
+	// written to the session at once
+	maxSeriesWriteConcurrency = 16
+)
+
+var (
+	errNoUnaggregatedNamespace = errors.New("no unaggregated namespace")
 )
 
 type localStorage struct {
 	session   client.Session
 	namespace ident.ID
+	// namespaces and resolver are only set when reads are split between
+	// namespaces with different storage policies
+	namespaces        map[policy.StoragePolicy]ident.ID
+	resolver          resolver.PolicyResolver
+	consolidationOpts storage.ConsolidationOptions
+}
+
+// Namespace is an M3DB namespace along with the storage policy of its data.
+type Namespace struct {
+	// Name is the name of the namespace.
+	Name string
+	// StoragePolicy is the resolution and retention of the namespace.
+	StoragePolicy policy.StoragePolicy
+	// Unaggregated is set for the namespace which raw writes go to.
+	Unaggregated bool
 }
 
 // NewStorage creates a new local Storage instance.
-func NewStorage(session client.Session, namespace string) storage.Storage {
-	return &localStorage{session: session, namespace: ident.StringID(namespace)}
+func NewStorage(session client.Session, namespace string, consolidationOpts storage.ConsolidationOptions) storage.Storage {
+	if consolidationOpts.LookbackDuration <= 0 {
+		consolidationOpts.LookbackDuration = storage.DefaultLookbackDuration
+	}
+
+	return &localStorage{
+		session:           session,
+		namespace:         ident.StringID(namespace),
+		consolidationOpts: consolidationOpts,
+	}
+}
+
+// NewMultiNamespaceStorage creates a new local Storage instance which writes
+// to the unaggregated namespace, and reads each part of the queried range
+// from the namespace whose storage policy the resolver picks for it.
+func NewMultiNamespaceStorage(
+	session client.Session,
+	namespaces []Namespace,
+	resolver resolver.PolicyResolver,
+	consolidationOpts storage.ConsolidationOptions,
+) (storage.Storage, error) {
+	var (
+		unaggregated string
+		byPolicy     = make(map[policy.StoragePolicy]ident.ID, len(namespaces))
+	)
+
+	for _, ns := range namespaces {
+		if ns.Unaggregated {
+			if unaggregated != "" {
+				return nil, fmt.Errorf("multiple unaggregated namespaces: %s, %s", unaggregated, ns.Name)
+			}
+
+			unaggregated = ns.Name
+		}
+
+		if _, ok := byPolicy[ns.StoragePolicy]; ok {
+			return nil, fmt.Errorf("multiple namespaces with storage policy %s", ns.StoragePolicy.String())
+		}
+
+		byPolicy[ns.StoragePolicy] = ident.StringID(ns.Name)
+	}
+
+	if unaggregated == "" {
+		return nil, errNoUnaggregatedNamespace
+	}
+
+	s := NewStorage(session, unaggregated, consolidationOpts).(*localStorage)
+	s.namespaces = byPolicy
+	s.resolver = resolver
+	return s, nil
 }
 
 func (s *localStorage) Fetch(ctx context.Context, query *storage.FetchQuery, options *storage.FetchOptions) (*storage.FetchResult, error) {
@@ -54,27 +131,26 @@ func (s *localStorage) Fetch(ctx context.Context, query *storage.FetchQuery, opt
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	case <-options.KillChan:
-		return nil, errors.ErrQueryInterrupted
+		return nil, coordErrors.ErrQueryInterrupted
 	default:
 	}
 
-	m3query, err := storage.FetchQueryToM3Query(query)
+	fetched, err := s.fetchTagged(ctx, query, options, 0)
 	if err != nil {
 		return nil, err
 	}
 
-	opts := storage.FetchOptionsToM3Options(options, query)
-	// TODO (nikunj): Handle second return param
-	iters, _, err := s.session.FetchTagged(s.namespace, m3query, opts)
-	if err != nil {
+	defer fetched.close()
+
+	stitched := storage.StitchSeriesIterators(fetched.iters...)
+	numSeries := len(stitched)
+	if err := options.Enforcer.AddSeries(numSeries); err != nil {
 		return nil, err
 	}
 
-	defer iters.Close()
-
-	seriesList := make([]*ts.Series, iters.Len())
-	for i, iter := range iters.Iters() {
-		metric, err := storage.FromM3IdentToMetric(s.namespace, iter.ID(), iter.Tags())
+	seriesList := make([]*ts.Series, numSeries)
+	for i, iter := range stitched {
+		metric, err := storage.FromM3IdentToMetric(s.namespace, iter.ID, iter.Tags)
 		if err != nil {
 			return nil, err
 		}
@@ -85,12 +161,17 @@ func (s *localStorage) Fetch(ctx context.Context, query *storage.FetchQuery, opt
 			datapoints = append(datapoints, ts.Datapoint{Timestamp: dp.Timestamp, Value: dp.Value})
 		}
 
+		if err := options.Enforcer.AddDatapoints(len(datapoints)); err != nil {
+			return nil, err
+		}
+
 		series := ts.NewSeries(metric.ID, datapoints, metric.Tags)
 		seriesList[i] = series
 	}
 
 	return &storage.FetchResult{
 		SeriesList: seriesList,
+		Warnings:   fetched.warnings(),
 	}, nil
 }
 
@@ -100,7 +181,7 @@ func (s *localStorage) FetchTags(ctx context.Context, query *storage.FetchQuery,
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	case <-options.KillChan:
-		return nil, errors.ErrQueryInterrupted
+		return nil, coordErrors.ErrQueryInterrupted
 	default:
 	}
 
@@ -109,25 +190,110 @@ func (s *localStorage) FetchTags(ctx context.Context, query *storage.FetchQuery,
 		return nil, err
 	}
 
-	opts := storage.FetchOptionsToM3Options(options, query)
-	// TODO (juchan): Handle second return param
-	iter, _, err := s.session.FetchTaggedIDs(s.namespace, m3query, opts)
+	ranges, err := s.resolveRanges(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 
-	var metrics models.Metrics
-	for iter.Next() {
-		m, err := storage.FromM3IdentToMetric(iter.Current())
+	var (
+		metrics models.Metrics
+		seen    = make(map[string]struct{})
+		fetched = fetchResult{exhaustive: true}
+	)
+
+	for _, rng := range ranges {
+		opts := storage.FetchOptionsToM3Options(options, rng.query)
+		iter, exhaustive, err := s.session.FetchTaggedIDs(ctx, rng.namespace, m3query, opts)
 		if err != nil {
 			return nil, err
 		}
 
-		metrics = append(metrics, m)
+		fetched.add(nil, exhaustive, opts)
+		for iter.Next() {
+			m, err := storage.FromM3IdentToMetric(iter.Current())
+			if err != nil {
+				return nil, err
+			}
+
+			// Series retained by several of the namespaces are only returned once
+			if _, ok := seen[m.ID]; ok {
+				continue
+			}
+
+			seen[m.ID] = struct{}{}
+			metrics = append(metrics, m)
+		}
+	}
+
+	if err := options.Enforcer.AddSeries(len(metrics)); err != nil {
+		return nil, err
 	}
 
 	return &storage.SearchResults{
-		Metrics: metrics,
+		Metrics:  metrics,
+		Warnings: fetched.warnings(),
+	}, nil
+}
+
+// CompleteTags completes the tag names or values from the term dictionaries
+// of the index, so that the tags of the matching series are never fetched
+func (s *localStorage) CompleteTags(
+	ctx context.Context,
+	query *storage.CompleteTagsQuery,
+	options *storage.FetchOptions,
+) (*storage.CompleteTagsResult, error) {
+	// Check if the query was interrupted.
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-options.KillChan:
+		return nil, coordErrors.ErrQueryInterrupted
+	default:
+	}
+
+	fetchQuery := &storage.FetchQuery{
+		TagMatchers: query.TagMatchers,
+		Start:       query.Start,
+		End:         query.End,
+	}
+
+	m3query, err := storage.FetchQueryToM3Query(fetchQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	ranges, err := s.resolveRanges(ctx, fetchQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		terms   = make(map[string]struct{})
+		fetched = fetchResult{exhaustive: true}
+	)
+
+	for _, rng := range ranges {
+		opts := index.AggregateQueryOptions{
+			QueryOptions: storage.FetchOptionsToM3Options(options, rng.query),
+		}
+		if query.TagName != "" {
+			opts.FieldName = []byte(query.TagName)
+		}
+
+		results, exhaustive, err := s.session.Aggregate(ctx, rng.namespace, m3query, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		fetched.add(nil, exhaustive, opts.QueryOptions)
+		for _, result := range results {
+			terms[string(result.Term)] = struct{}{}
+		}
+	}
+
+	return &storage.CompleteTagsResult{
+		Terms:    storage.SortedTerms(terms),
+		Warnings: fetched.warnings(),
 	}, nil
 }
 
@@ -140,7 +306,7 @@ func (s *localStorage) Write(ctx context.Context, query *storage.WriteQuery) err
 	}
 
 	if query == nil {
-		return errors.ErrNilWriteQuery
+		return coordErrors.ErrNilWriteQuery
 	}
 
 	id := query.Tags.ID()
@@ -156,7 +322,7 @@ func (s *localStorage) Write(ctx context.Context, query *storage.WriteQuery) err
 	for idx, datapoint := range query.Datapoints {
 		requests[idx] = newWriteRequest(common, datapoint.Timestamp, datapoint.Value)
 	}
-	return execution.ExecuteParallel(ctx, requests)
+	return execution.ExecuteParallelWithLimit(ctx, requests, maxSeriesWriteConcurrency)
 }
 
 func (s *localStorage) Type() storage.Type {
@@ -165,26 +331,222 @@ func (s *localStorage) Type() storage.Type {
 
 func (s *localStorage) FetchBlocks(
 	ctx context.Context, query *storage.FetchQuery, options *storage.FetchOptions) (storage.BlockResult, error) {
-	return storage.BlockResult{}, errors.ErrNotImplemented
+	// Check if the query was interrupted.
+	select {
+	case <-ctx.Done():
+		return storage.BlockResult{}, ctx.Err()
+	case <-options.KillChan:
+		return storage.BlockResult{}, coordErrors.ErrQueryInterrupted
+	default:
+	}
+
+	// Fetch the lookback window before the first step as well so that the
+	// first steps of sparse series can be filled in
+	fetched, err := s.fetchTagged(ctx, query, options, s.consolidationOpts.LookbackDuration)
+	if err != nil {
+		return storage.BlockResult{}, err
+	}
+
+	defer fetched.close()
+
+	bounds := storage.Bounds{
+		Start:    query.Start,
+		End:      query.End,
+		StepSize: query.Interval,
+	}
+
+	consolidationOpts := s.consolidationOpts
+	consolidationOpts.RangeLookbacks = fetched.lookbacks
+	series := storage.StitchSeriesIterators(fetched.iters...)
+	block, err := storage.NewStitchedM3Block(s.namespace, series, bounds, consolidationOpts, options.Enforcer)
+	if err != nil {
+		return storage.BlockResult{}, err
+	}
+
+	return storage.BlockResult{
+		Blocks:   []storage.Block{block},
+		Warnings: fetched.warnings(),
+	}, nil
+}
+
+// namespaceRange is the part of a query served by a namespace
+type namespaceRange struct {
+	namespace  ident.ID
+	query      *storage.FetchQuery
+	resolution time.Duration
+}
+
+// lookback returns the lookback of the range, datapoints of coarser
+// namespaces being further apart than the configured lookback
+func (r namespaceRange) lookback(lookback time.Duration) time.Duration {
+	if r.resolution > lookback {
+		return r.resolution
+	}
+
+	return lookback
+}
+
+// resolveRanges splits the query into the ranges served by each namespace, in
+// ascending order
+func (s *localStorage) resolveRanges(ctx context.Context, query *storage.FetchQuery) ([]namespaceRange, error) {
+	if s.resolver == nil {
+		return []namespaceRange{{namespace: s.namespace, query: query}}, nil
+	}
+
+	requests, err := s.resolver.Resolve(ctx, query.TagMatchers, query.Start, query.End)
+	if err != nil {
+		return nil, err
+	}
+
+	var ranges []namespaceRange
+	for _, request := range requests {
+		for _, rng := range request.Ranges {
+			namespace, ok := s.namespaces[rng.StoragePolicy]
+			if !ok {
+				return nil, fmt.Errorf("no namespace for storage policy %s", rng.StoragePolicy.String())
+			}
+
+			rangeQuery := *query
+			rangeQuery.Start = rng.Start
+			rangeQuery.End = rng.End
+			ranges = append(ranges, namespaceRange{
+				namespace:  namespace,
+				query:      &rangeQuery,
+				resolution: rng.StoragePolicy.Resolution().Window,
+			})
+		}
+	}
+
+	return ranges, nil
+}
+
+// fetchTagged fetches the series iterators for each of the ranges of the
+// query, extending the first range back by its lookback. The lookback of each
+// range is the larger of the given lookback and its resolution, no lookback
+// is fetched if the given lookback is zero
+func (s *localStorage) fetchTagged(
+	ctx context.Context,
+	query *storage.FetchQuery,
+	options *storage.FetchOptions,
+	lookback time.Duration,
+) (fetchResult, error) {
+	m3query, err := storage.FetchQueryToM3Query(query)
+	if err != nil {
+		return fetchResult{}, err
+	}
+
+	ranges, err := s.resolveRanges(ctx, query)
+	if err != nil {
+		return fetchResult{}, err
+	}
+
+	fetched := fetchResult{exhaustive: true}
+	for i, rng := range ranges {
+		fetchQuery := rng.query
+		if lookback > 0 {
+			rangeLookback := rng.lookback(lookback)
+			fetched.lookbacks = append(fetched.lookbacks, storage.RangeLookback{
+				Start:    fetchQuery.Start,
+				Lookback: rangeLookback,
+			})
+
+			if i == 0 {
+				extended := *fetchQuery
+				extended.Start = fetchQuery.Start.Add(-1 * rangeLookback)
+				fetchQuery = &extended
+			}
+		}
+
+		opts := storage.FetchOptionsToM3Options(options, fetchQuery)
+		iters, exhaustive, err := s.fetchTaggedRange(ctx, rng.namespace, m3query, opts)
+		if err != nil {
+			fetched.close()
+			return fetchResult{}, err
+		}
+
+		fetched.add(iters, exhaustive, opts)
+	}
+
+	return fetched, nil
+}
+
+// fetchTaggedRange fetches the series iterators from a namespace, tracing
+// the fetch as a child of the span of the context
+func (s *localStorage) fetchTaggedRange(
+	ctx context.Context,
+	namespace ident.ID,
+	m3query index.Query,
+	opts index.QueryOptions,
+) (encoding.SeriesIterators, bool, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx, "fetchTagged")
+	span.SetTag("namespace", namespace.String())
+	span.SetTag("start", opts.StartInclusive)
+	span.SetTag("end", opts.EndExclusive)
+	defer span.Finish()
+
+	iters, exhaustive, err := s.session.FetchTagged(ctx, namespace, m3query, opts)
+	if err != nil {
+		span.SetTag("error", err.Error())
+		return nil, false, err
+	}
+
+	span.SetTag("exhaustive", exhaustive)
+	return iters, exhaustive, nil
+}
+
+// fetchResult is the result of fetching each of the ranges of a query
+type fetchResult struct {
+	iters      []encoding.SeriesIterators
+	lookbacks  []storage.RangeLookback
+	exhaustive bool
+	limit      int
+}
+
+func (r *fetchResult) add(iters encoding.SeriesIterators, exhaustive bool, opts index.QueryOptions) {
+	if iters != nil {
+		r.iters = append(r.iters, iters)
+	}
+
+	r.exhaustive = r.exhaustive && exhaustive
+	r.limit = opts.Limit
+}
+
+// warnings returns the warnings for a fetch whose index results were not
+// exhaustive, typically because the series limit was reached
+func (r *fetchResult) warnings() storage.Warnings {
+	if r.exhaustive {
+		return nil
+	}
+
+	return storage.Warnings{storage.NewNonExhaustiveWarning(r.limit)}
+}
+
+func (r *fetchResult) close() {
+	for _, iters := range r.iters {
+		iters.Close()
+	}
 }
 
 func (w *writeRequest) Process(ctx context.Context) error {
 	common := w.writeRequestCommon
 	store := common.store
 	id := ident.StringID(common.id)
-	return store.session.WriteTagged(store.namespace, id, common.tagIterator, w.timestamp, w.value, common.unit, common.annotation)
+	return store.session.WriteTagged(store.namespace, id, w.tagIterator, w.timestamp, w.value, common.unit, common.annotation)
 }
 
 type writeRequestCommon struct {
-	store       *localStorage
-	annotation  []byte
-	unit        xtime.Unit
-	id          string
+	store      *localStorage
+	annotation []byte
+	unit       xtime.Unit
+	id         string
+	// tagIterator is duplicated by each write, since iterators cannot be
+	// shared between the writes running at once
 	tagIterator ident.TagIterator
 }
 
 type writeRequest struct {
 	writeRequestCommon *writeRequestCommon
+	tagIterator        ident.TagIterator
 	timestamp          time.Time
 	value              float64
 }
@@ -192,6 +554,7 @@ type writeRequest struct {
 func newWriteRequest(writeRequestCommon *writeRequestCommon, timestamp time.Time, value float64) execution.Request {
 	return &writeRequest{
 		writeRequestCommon: writeRequestCommon,
+		tagIterator:        writeRequestCommon.tagIterator.Duplicate(),
 		timestamp:          timestamp,
 		value:              value,
 	}
@@ -199,5 +562,9 @@ func newWriteRequest(writeRequestCommon *writeRequestCommon, timestamp time.Time
 
 func (s *localStorage) Close() error {
 	s.namespace.Finalize()
+	for _, namespace := range s.namespaces {
+		namespace.Finalize()
+	}
+
 	return nil
 }
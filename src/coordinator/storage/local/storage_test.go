@@ -22,20 +22,28 @@ package local
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/m3db/m3db/src/coordinator/cost"
 	"github.com/m3db/m3db/src/coordinator/errors"
 	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/policy/resolver"
 	"github.com/m3db/m3db/src/coordinator/storage"
 	"github.com/m3db/m3db/src/coordinator/test"
 	"github.com/m3db/m3db/src/coordinator/ts"
 	"github.com/m3db/m3db/src/coordinator/util/logging"
 	"github.com/m3db/m3db/src/dbnode/client"
+	"github.com/m3db/m3db/src/dbnode/encoding"
+	"github.com/m3db/m3db/src/dbnode/storage/index"
+	"github.com/m3db/m3metrics/policy"
+	"github.com/m3db/m3x/ident"
 	xtime "github.com/m3db/m3x/time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func setup(ctrl *gomock.Controller) (storage.Storage, *client.MockSession) {
@@ -43,7 +51,7 @@ func setup(ctrl *gomock.Controller) (storage.Storage, *client.MockSession) {
 	logger := logging.WithContext(context.TODO())
 	defer logger.Sync()
 	session := client.NewMockSession(ctrl)
-	storage := NewStorage(session, "metrics")
+	storage := NewStorage(session, "metrics", storage.NewConsolidationOptions())
 	return storage, session
 }
 
@@ -105,11 +113,39 @@ func TestLocalWriteSuccess(t *testing.T) {
 	assert.NoError(t, store.Close())
 }
 
+func TestLocalWriteDuplicatesTags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store, session := setup(ctrl)
+
+	var (
+		mu        sync.Mutex
+		iterators = make(map[ident.TagIterator]struct{})
+	)
+
+	session.EXPECT().WriteTagged(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_, _ ident.ID, tags ident.TagIterator, _ time.Time, _ float64, _ xtime.Unit, _ []byte) error {
+			// Each write iterates all of the tags of the series
+			numTags := 0
+			for tags.Next() {
+				numTags++
+			}
+			assert.Equal(t, 2, numTags)
+
+			mu.Lock()
+			iterators[tags] = struct{}{}
+			mu.Unlock()
+			return nil
+		}).Times(2)
+
+	require.NoError(t, store.Write(context.TODO(), newWriteQuery()))
+	assert.Len(t, iterators, 2)
+}
+
 func TestLocalRead(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	store, session := setup(ctrl)
 	testTags := test.GenerateTag()
-	session.EXPECT().FetchTagged(gomock.Any(), gomock.Any(), gomock.Any()).Return(test.NewMockSeriesIters(ctrl, testTags), true, nil)
+	session.EXPECT().FetchTagged(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(test.NewMockSeriesIters(ctrl, testTags), true, nil)
 	searchReq := newFetchReq()
 	results, err := store.Fetch(context.TODO(), searchReq, &storage.FetchOptions{Limit: 100})
 	assert.NoError(t, err)
@@ -118,10 +154,127 @@ func TestLocalRead(t *testing.T) {
 	assert.Equal(t, tags, results.SeriesList[0].Tags)
 }
 
+func TestLocalReadExceedsLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store, session := setup(ctrl)
+	session.EXPECT().FetchTagged(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(test.NewMockSeriesIters(ctrl, test.GenerateTag()), true, nil)
+	enforcer := cost.NewEnforcer(cost.Limits{}).Child(cost.Limits{MaxFetchedDatapoints: 1})
+	_, err := store.Fetch(context.TODO(), newFetchReq(), &storage.FetchOptions{Enforcer: enforcer})
+	require.Error(t, err)
+	assert.True(t, errors.IsResourceLimitError(err))
+}
+
+func TestLocalReadNonExhaustive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store, session := setup(ctrl)
+	session.EXPECT().FetchTagged(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(test.NewMockSeriesIters(ctrl, test.GenerateTag()), false, nil)
+	results, err := store.Fetch(context.TODO(), newFetchReq(), &storage.FetchOptions{Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, results.SeriesList, 1)
+	assert.Equal(t, storage.Warnings{storage.NewNonExhaustiveWarning(1)}, results.Warnings)
+}
+
+var (
+	rawPolicy = policy.NewStoragePolicy(0, xtime.Second, 48*time.Hour)
+	aggPolicy = policy.NewStoragePolicy(time.Minute, xtime.Second, 30*24*time.Hour)
+)
+
+func setupMultiNamespace(t *testing.T, ctrl *gomock.Controller, now time.Time) (storage.Storage, *client.MockSession) {
+	logging.InitWithCores(nil)
+	session := client.NewMockSession(ctrl)
+	policyResolver, err := resolver.NewResolutionResolver([]policy.StoragePolicy{rawPolicy, aggPolicy}, func() time.Time {
+		return now
+	})
+	require.NoError(t, err)
+
+	store, err := NewMultiNamespaceStorage(session, []Namespace{
+		{Name: "metrics", StoragePolicy: rawPolicy, Unaggregated: true},
+		{Name: "metrics_1m", StoragePolicy: aggPolicy},
+	}, policyResolver, storage.NewConsolidationOptions())
+	require.NoError(t, err)
+	return store, session
+}
+
+func TestNewMultiNamespaceStorageInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	session := client.NewMockSession(ctrl)
+
+	_, err := NewMultiNamespaceStorage(session, []Namespace{
+		{Name: "metrics_1m", StoragePolicy: aggPolicy},
+	}, nil, storage.NewConsolidationOptions())
+	assert.Error(t, err)
+
+	_, err = NewMultiNamespaceStorage(session, []Namespace{
+		{Name: "metrics", StoragePolicy: rawPolicy, Unaggregated: true},
+		{Name: "metrics_copy", StoragePolicy: rawPolicy},
+	}, nil, storage.NewConsolidationOptions())
+	assert.Error(t, err)
+}
+
+func TestLocalMultiNamespaceRead(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	now := time.Now()
+	store, session := setupMultiNamespace(t, ctrl, now)
+	testTags := test.GenerateTag()
+
+	// The week long query is split between the namespaces, and the datapoints
+	// of the series stitched back together
+	session.EXPECT().FetchTagged(gomock.Any(), ident.NewIDMatcher("metrics_1m"), gomock.Any(), gomock.Any()).
+		Return(test.NewMockSeriesIters(ctrl, testTags), true, nil)
+	session.EXPECT().FetchTagged(gomock.Any(), ident.NewIDMatcher("metrics"), gomock.Any(), gomock.Any()).
+		Return(test.NewMockSeriesIters(ctrl, testTags), true, nil)
+
+	query := newFetchReq()
+	query.Start = now.Add(-7 * 24 * time.Hour)
+	query.End = now
+	results, err := store.Fetch(context.TODO(), query, &storage.FetchOptions{})
+	require.NoError(t, err)
+	require.Len(t, results.SeriesList, 1)
+	assert.Equal(t, 4, results.SeriesList[0].Len())
+	assert.Len(t, results.Warnings, 0)
+}
+
+func TestLocalMultiNamespaceFetchBlocksLookback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	logging.InitWithCores(nil)
+	now := time.Now()
+	session := client.NewMockSession(ctrl)
+	policyResolver, err := resolver.NewResolutionResolver([]policy.StoragePolicy{rawPolicy, aggPolicy}, func() time.Time {
+		return now
+	})
+	require.NoError(t, err)
+
+	store, err := NewMultiNamespaceStorage(session, []Namespace{
+		{Name: "metrics", StoragePolicy: rawPolicy, Unaggregated: true},
+		{Name: "metrics_1m", StoragePolicy: aggPolicy},
+	}, policyResolver, storage.ConsolidationOptions{LookbackDuration: 30 * time.Second})
+	require.NoError(t, err)
+
+	query := newFetchReq()
+	query.Start = now.Add(-7 * 24 * time.Hour)
+	query.End = now
+	query.Interval = time.Hour
+
+	// The aggregated namespace is looked back by its resolution rather than
+	// the shorter configured lookback
+	session.EXPECT().FetchTagged(gomock.Any(), ident.NewIDMatcher("metrics_1m"), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ ident.ID, _ index.Query, opts index.QueryOptions) (encoding.SeriesIterators, bool, error) {
+			assert.Equal(t, query.Start.Add(-time.Minute), opts.StartInclusive)
+			return test.NewMockSeriesIters(ctrl, test.GenerateTag()), true, nil
+		})
+	session.EXPECT().FetchTagged(gomock.Any(), ident.NewIDMatcher("metrics"), gomock.Any(), gomock.Any()).
+		Return(test.NewMockSeriesIters(ctrl, test.GenerateTag()), true, nil)
+
+	result, err := store.FetchBlocks(context.TODO(), query, &storage.FetchOptions{})
+	require.NoError(t, err)
+	require.Len(t, result.Blocks, 1)
+	assert.NoError(t, result.Blocks[0].Close())
+}
+
 func setupLocalSearch(t *testing.T) storage.Storage {
 	ctrl := gomock.NewController(t)
 	store, session := setup(ctrl)
-	session.EXPECT().FetchTaggedIDs(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, false, errors.ErrNotImplemented)
+	session.EXPECT().FetchTaggedIDs(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, false, errors.ErrNotImplemented)
 	return store
 }
 
@@ -131,3 +284,31 @@ func TestLocalSearchExpectedFail(t *testing.T) {
 	_, err := store.FetchTags(context.TODO(), searchReq, &storage.FetchOptions{Limit: 100})
 	assert.Error(t, err)
 }
+
+func TestLocalFetchBlocks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store, session := setup(ctrl)
+	testTags := test.GenerateTag()
+	session.EXPECT().FetchTagged(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(test.NewMockSeriesIters(ctrl, testTags), true, nil)
+	searchReq := newFetchReq()
+	searchReq.Interval = time.Minute
+	result, err := store.FetchBlocks(context.TODO(), searchReq, &storage.FetchOptions{Limit: 100})
+	require.NoError(t, err)
+	require.Len(t, result.Blocks, 1)
+
+	block := result.Blocks[0]
+	assert.Equal(t, searchReq.Start, block.Meta().Bounds.Start)
+	assert.Equal(t, time.Minute, block.Meta().Bounds.StepSize)
+	require.Len(t, block.SeriesMeta(), 1)
+	assert.Equal(t, models.Tags{testTags.Name.String(): testTags.Value.String()}, block.SeriesMeta()[0].Tags)
+	assert.Equal(t, 10, block.StepIter().StepCount())
+	assert.NoError(t, block.Close())
+}
+
+func TestLocalFetchBlocksInvalidInterval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store, session := setup(ctrl)
+	session.EXPECT().FetchTagged(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(test.NewMockSeriesIters(ctrl, test.GenerateTag()), true, nil)
+	_, err := store.FetchBlocks(context.TODO(), newFetchReq(), &storage.FetchOptions{Limit: 100})
+	assert.Error(t, err)
+}
@@ -0,0 +1,380 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remote
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	clusterclient "github.com/m3db/m3cluster/client"
+	"github.com/m3db/m3cluster/services"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/tsdb/remote"
+	"github.com/m3db/m3db/src/coordinator/util/logging"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRetryInterval    = 5 * time.Second
+	defaultMaxRetryInterval = time.Minute
+)
+
+var (
+	errWatchClosed     = errors.New("watch closed")
+	errNoClusterClient = errors.New("no cluster client")
+	errNoServiceID     = errors.New("no service id")
+	errNoNewClientFn   = errors.New("no new client function")
+)
+
+// NewClientFn creates a client of the coordinators at the given addresses
+type NewClientFn func(addresses []string) (remote.Client, error)
+
+// DiscoveryOptions are the options of the discovery of remote zones
+type DiscoveryOptions struct {
+	// ClusterClient is the client of the cluster services the coordinators
+	// are registered with
+	ClusterClient clusterclient.Client
+	// ServiceID is the service the coordinators of all zones are instances of
+	ServiceID services.ServiceID
+	// QueryOptions are the options querying the instances of the service,
+	// which exclude instances without a recent heartbeat unless unhealthy
+	// instances are included
+	QueryOptions services.QueryOptions
+	// LocalZone is the zone of this coordinator, which is not a remote zone
+	LocalZone string
+	// Advertisement advertises this coordinator as an instance of the
+	// service if set
+	Advertisement services.Advertisement
+	// NewClientFn creates the clients of the coordinators of each zone
+	NewClientFn NewClientFn
+	// RetryInterval is how long to wait before retrying to watch the service
+	// when the cluster services are unavailable or the watch is closed
+	RetryInterval time.Duration
+	// MaxRetryInterval is the longest wait between retries, the wait doubles
+	// with each consecutive failure up to it
+	MaxRetryInterval time.Duration
+}
+
+// Validate validates the discovery options
+func (o DiscoveryOptions) Validate() error {
+	if o.ClusterClient == nil {
+		return errNoClusterClient
+	}
+
+	if o.ServiceID == nil {
+		return errNoServiceID
+	}
+
+	if o.NewClientFn == nil {
+		return errNoNewClientFn
+	}
+
+	return nil
+}
+
+// ZoneDiscovery watches the coordinators of the remote zones through the
+// cluster services, maintaining a remote store for each zone as zones are
+// added and removed
+type ZoneDiscovery struct {
+	sync.RWMutex
+
+	opts       DiscoveryOptions
+	services   services.Services
+	advertised bool
+	zones      map[string]*zoneStore
+	stores     []storage.Storage
+	closeCh    chan struct{}
+	doneCh     chan struct{}
+}
+
+type zoneStore struct {
+	endpoints []string
+	client    remote.Client
+	store     storage.Storage
+}
+
+// NewZoneDiscovery creates a new discovery of remote zones, which watches the
+// service in the background until closed
+func NewZoneDiscovery(opts DiscoveryOptions) (*ZoneDiscovery, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	if opts.QueryOptions == nil {
+		opts.QueryOptions = services.NewQueryOptions()
+	}
+
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = defaultRetryInterval
+	}
+
+	if opts.MaxRetryInterval <= 0 {
+		opts.MaxRetryInterval = defaultMaxRetryInterval
+	}
+
+	if opts.MaxRetryInterval < opts.RetryInterval {
+		opts.MaxRetryInterval = opts.RetryInterval
+	}
+
+	d := &ZoneDiscovery{
+		opts:    opts,
+		zones:   make(map[string]*zoneStore),
+		closeCh: make(chan struct{}),
+		doneCh:  make(chan struct{}),
+	}
+
+	go d.run()
+	return d, nil
+}
+
+// Stores returns the remote stores of the zones currently discovered
+func (d *ZoneDiscovery) Stores() []storage.Storage {
+	d.RLock()
+	defer d.RUnlock()
+	return d.stores
+}
+
+// run watches the service until the discovery is closed, watching it again
+// whenever the cluster services are unavailable or the watch is closed
+func (d *ZoneDiscovery) run() {
+	defer close(d.doneCh)
+
+	var (
+		logger = logging.WithContext(context.Background())
+		delay  = d.opts.RetryInterval
+	)
+
+	for {
+		watch, err := d.newWatch()
+		if err == nil {
+			updated, closed := d.watchUpdates(watch)
+			watch.Close()
+			if closed {
+				return
+			}
+
+			if updated {
+				delay = d.opts.RetryInterval
+			}
+
+			err = errWatchClosed
+		}
+
+		logger.Warn("unable to watch remote coordinators, retrying",
+			zap.String("service", d.opts.ServiceID.String()), zap.Duration("retryIn", delay),
+			zap.Any("error", err))
+		select {
+		case <-d.closeCh:
+			return
+		case <-time.After(delay):
+		}
+
+		if delay *= 2; delay > d.opts.MaxRetryInterval {
+			delay = d.opts.MaxRetryInterval
+		}
+	}
+}
+
+// watchUpdates updates the zones from the watch until it is closed, returning
+// whether any update was received and whether the discovery was closed
+func (d *ZoneDiscovery) watchUpdates(watch services.Watch) (bool, bool) {
+	updated := false
+	for {
+		select {
+		case <-d.closeCh:
+			return updated, true
+		case _, ok := <-watch.C():
+			if !ok {
+				return updated, false
+			}
+
+			updated = true
+			d.update(watch.Get())
+		}
+	}
+}
+
+// newWatch watches the service, advertising this coordinator the first time
+// the cluster services are available
+func (d *ZoneDiscovery) newWatch() (services.Watch, error) {
+	d.RLock()
+	svcs, advertised := d.services, d.advertised
+	d.RUnlock()
+
+	if svcs == nil {
+		var err error
+		svcs, err = d.opts.ClusterClient.Services(services.NewOverrideOptions())
+		if err != nil {
+			return nil, err
+		}
+
+		d.Lock()
+		d.services = svcs
+		d.Unlock()
+	}
+
+	if d.opts.Advertisement != nil && !advertised {
+		if err := svcs.Advertise(d.opts.Advertisement); err != nil {
+			return nil, err
+		}
+
+		d.Lock()
+		d.advertised = true
+		d.Unlock()
+	}
+
+	return svcs.Watch(d.opts.ServiceID, d.opts.QueryOptions)
+}
+
+// update replaces the stores of the zones whose coordinators changed
+func (d *ZoneDiscovery) update(service services.Service) {
+	if service == nil {
+		return
+	}
+
+	var (
+		logger    = logging.WithContext(context.Background())
+		endpoints = zoneEndpoints(service.Instances(), d.opts.LocalZone)
+		toClose   []remote.Client
+	)
+
+	d.Lock()
+	for zone, existing := range d.zones {
+		if equalEndpoints(existing.endpoints, endpoints[zone]) {
+			continue
+		}
+
+		delete(d.zones, zone)
+		toClose = append(toClose, existing.client)
+		logger.Info("removing remote zone", zap.String("zone", zone))
+	}
+
+	for zone, addresses := range endpoints {
+		if _, ok := d.zones[zone]; ok {
+			continue
+		}
+
+		client, err := d.opts.NewClientFn(addresses)
+		if err != nil {
+			logger.Error("unable to create remote zone client",
+				zap.String("zone", zone), zap.Any("error", err))
+			continue
+		}
+
+		d.zones[zone] = &zoneStore{
+			endpoints: addresses,
+			client:    client,
+			store:     NewStorage(client),
+		}
+		logger.Info("discovered remote zone",
+			zap.String("zone", zone), zap.Strings("endpoints", addresses))
+	}
+
+	zones := make([]string, 0, len(d.zones))
+	for zone := range d.zones {
+		zones = append(zones, zone)
+	}
+
+	sort.Strings(zones)
+	stores := make([]storage.Storage, 0, len(zones))
+	for _, zone := range zones {
+		stores = append(stores, d.zones[zone].store)
+	}
+
+	d.stores = stores
+	d.Unlock()
+
+	// Requests in flight to the removed zones fail, which the fanout returns
+	// as partial results
+	closeClients(toClose)
+}
+
+// zoneEndpoints returns the sorted endpoints of the instances of each remote zone
+func zoneEndpoints(instances []services.ServiceInstance, localZone string) map[string][]string {
+	endpoints := make(map[string][]string)
+	for _, instance := range instances {
+		zone := instance.Zone()
+		if zone == localZone || instance.Endpoint() == "" {
+			continue
+		}
+
+		endpoints[zone] = append(endpoints[zone], instance.Endpoint())
+	}
+
+	for _, addresses := range endpoints {
+		sort.Strings(addresses)
+	}
+
+	return endpoints
+}
+
+func equalEndpoints(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func closeClients(clients []remote.Client) {
+	for _, client := range clients {
+		if err := client.Close(); err != nil {
+			logging.WithContext(context.Background()).Error("unable to close remote zone client",
+				zap.Any("error", err))
+		}
+	}
+}
+
+// Close stops watching the service, unadvertising this coordinator and
+// closing the clients of the remote zones
+func (d *ZoneDiscovery) Close() error {
+	close(d.closeCh)
+	<-d.doneCh
+
+	d.Lock()
+	svcs, advertised := d.services, d.advertised
+	clients := make([]remote.Client, 0, len(d.zones))
+	for _, zone := range d.zones {
+		clients = append(clients, zone.client)
+	}
+
+	d.zones = make(map[string]*zoneStore)
+	d.stores = nil
+	d.Unlock()
+
+	closeClients(clients)
+	if advertised {
+		ad := d.opts.Advertisement
+		return svcs.Unadvertise(ad.ServiceID(), ad.PlacementInstance().ID())
+	}
+
+	return nil
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remote
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	clusterclient "github.com/m3db/m3cluster/client"
+	"github.com/m3db/m3cluster/placement"
+	"github.com/m3db/m3cluster/services"
+	"github.com/m3db/m3db/src/coordinator/storage"
+	"github.com/m3db/m3db/src/coordinator/tsdb/remote"
+	"github.com/m3db/m3db/src/coordinator/util/logging"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testWatch struct {
+	sync.Mutex
+	ch      chan struct{}
+	service services.Service
+}
+
+func (w *testWatch) C() <-chan struct{} {
+	return w.ch
+}
+
+func (w *testWatch) Get() services.Service {
+	w.Lock()
+	defer w.Unlock()
+	return w.service
+}
+
+func (w *testWatch) Close() {}
+
+func (w *testWatch) update(service services.Service) {
+	w.Lock()
+	w.service = service
+	w.Unlock()
+	w.ch <- struct{}{}
+}
+
+type testClient struct {
+	remote.Client
+	addresses []string
+	closed    bool
+}
+
+func (c *testClient) Close() error {
+	c.closed = true
+	return nil
+}
+
+func newTestService(ctrl *gomock.Controller, instances ...services.ServiceInstance) services.Service {
+	service := services.NewMockService(ctrl)
+	service.EXPECT().Instances().Return(instances).AnyTimes()
+	return service
+}
+
+func newTestInstance(id, zone, endpoint string) services.ServiceInstance {
+	return services.NewServiceInstance().
+		SetInstanceID(id).
+		SetZone(zone).
+		SetEndpoint(endpoint)
+}
+
+func waitForStores(t *testing.T, d *ZoneDiscovery, n int) []storage.Storage {
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(time.Millisecond) {
+		if stores := d.Stores(); len(stores) == n {
+			return stores
+		}
+	}
+
+	require.FailNow(t, "timed out waiting for stores")
+	return nil
+}
+
+func TestZoneDiscovery(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+
+	sid := services.NewServiceID().SetName("m3coordinator").SetZone("east")
+	watch := &testWatch{ch: make(chan struct{})}
+	svcs := services.NewMockServices(ctrl)
+	svcs.EXPECT().Watch(sid, gomock.Any()).Return(watch, nil)
+	clusterClient := clusterclient.NewMockClient(ctrl)
+	clusterClient.EXPECT().Services(gomock.Any()).Return(svcs, nil)
+
+	var (
+		mu      sync.Mutex
+		clients []*testClient
+	)
+
+	d, err := NewZoneDiscovery(DiscoveryOptions{
+		ClusterClient: clusterClient,
+		ServiceID:     sid,
+		LocalZone:     "east",
+		NewClientFn: func(addresses []string) (remote.Client, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			client := &testClient{addresses: addresses}
+			clients = append(clients, client)
+			return client, nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Len(t, d.Stores(), 0)
+
+	// Instances in the local zone are not remote stores
+	watch.update(newTestService(ctrl,
+		newTestInstance("a", "east", "a:7203"),
+		newTestInstance("b", "west", "b2:7203"),
+		newTestInstance("c", "west", "b1:7203"),
+		newTestInstance("d", "north", "d:7203"),
+	))
+
+	stores := waitForStores(t, d, 2)
+	for _, store := range stores {
+		assert.Equal(t, storage.TypeRemoteDC, store.Type())
+	}
+
+	mu.Lock()
+	require.Len(t, clients, 2)
+	west := clients[0]
+	if west.addresses[0] != "b1:7203" {
+		west = clients[1]
+	}
+
+	assert.Equal(t, []string{"b1:7203", "b2:7203"}, west.addresses)
+	mu.Unlock()
+
+	// Removing a zone closes its client, unchanged zones keep theirs
+	watch.update(newTestService(ctrl,
+		newTestInstance("b", "west", "b2:7203"),
+		newTestInstance("c", "west", "b1:7203"),
+	))
+
+	waitForStores(t, d, 1)
+	mu.Lock()
+	assert.Len(t, clients, 2)
+	assert.False(t, west.closed)
+	mu.Unlock()
+
+	require.NoError(t, d.Close())
+	assert.True(t, west.closed)
+	assert.Len(t, d.Stores(), 0)
+}
+
+func TestZoneDiscoveryWatchClosed(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+
+	var (
+		sid    = services.NewServiceID().SetName("m3coordinator").SetZone("east")
+		ad     = services.NewAdvertisement().SetServiceID(sid).SetPlacementInstance(placement.NewInstance().SetID("a"))
+		first  = &testWatch{ch: make(chan struct{})}
+		second = &testWatch{ch: make(chan struct{})}
+	)
+
+	// The coordinator is advertised once, however many times the service is
+	// watched
+	svcs := services.NewMockServices(ctrl)
+	svcs.EXPECT().Advertise(ad).Return(nil)
+	svcs.EXPECT().Watch(sid, gomock.Any()).Return(first, nil)
+	svcs.EXPECT().Watch(sid, gomock.Any()).Return(second, nil)
+	svcs.EXPECT().Unadvertise(sid, "a").Return(nil)
+	clusterClient := clusterclient.NewMockClient(ctrl)
+	clusterClient.EXPECT().Services(gomock.Any()).Return(svcs, nil)
+
+	d, err := NewZoneDiscovery(DiscoveryOptions{
+		ClusterClient: clusterClient,
+		ServiceID:     sid,
+		LocalZone:     "east",
+		Advertisement: ad,
+		RetryInterval: time.Millisecond,
+		NewClientFn: func(addresses []string) (remote.Client, error) {
+			return &testClient{addresses: addresses}, nil
+		},
+	})
+	require.NoError(t, err)
+
+	first.update(newTestService(ctrl, newTestInstance("b", "west", "b:7203")))
+	waitForStores(t, d, 1)
+
+	// The zones are kept while the service is watched again
+	close(first.ch)
+	second.update(newTestService(ctrl,
+		newTestInstance("b", "west", "b:7203"),
+		newTestInstance("c", "north", "c:7203"),
+	))
+	waitForStores(t, d, 2)
+
+	require.NoError(t, d.Close())
+	ctrl.Finish()
+}
+
+func TestZoneDiscoveryInvalidOptions(t *testing.T) {
+	_, err := NewZoneDiscovery(DiscoveryOptions{})
+	assert.Error(t, err)
+}
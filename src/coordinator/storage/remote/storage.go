@@ -42,8 +42,7 @@ func (s *remoteStorage) Fetch(ctx context.Context, query *storage.FetchQuery, op
 }
 
 func (s *remoteStorage) FetchTags(ctx context.Context, query *storage.FetchQuery, options *storage.FetchOptions) (*storage.SearchResults, error) {
-	// todo (braskin): implement remote FetchTags
-	return nil, errors.ErrNotImplemented
+	return s.client.FetchTags(ctx, query, options)
 }
 
 func (s *remoteStorage) Write(ctx context.Context, query *storage.WriteQuery) error {
@@ -54,6 +53,11 @@ func (s *remoteStorage) Type() storage.Type {
 	return storage.TypeRemoteDC
 }
 
+func (s *remoteStorage) ExecuteSubPlan(
+	ctx context.Context, query *storage.SubPlanQuery, options *storage.FetchOptions) (storage.BlockResult, error) {
+	return s.client.ExecuteSubPlan(ctx, query, options)
+}
+
 func (s *remoteStorage) Close() error {
 	return nil
 }
@@ -0,0 +1,111 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"github.com/m3db/m3db/src/dbnode/encoding"
+	m3ts "github.com/m3db/m3db/src/dbnode/ts"
+	"github.com/m3db/m3x/ident"
+	xtime "github.com/m3db/m3x/time"
+)
+
+// StitchedSeries iterates over the datapoints of a series fetched for one or
+// more consecutive time ranges, reading each range in order
+type StitchedSeries struct {
+	ID   ident.ID
+	Tags ident.TagIterator
+
+	iters []encoding.SeriesIterator
+	idx   int
+}
+
+// StitchSeriesIterators groups the series iterators fetched for consecutive
+// time ranges, given in ascending order, by series ID. The series are returned
+// in the order they first appear. The iterators are not closed.
+func StitchSeriesIterators(ranges ...encoding.SeriesIterators) []*StitchedSeries {
+	if len(ranges) == 1 {
+		iters := ranges[0].Iters()
+		stitched := make([]*StitchedSeries, 0, len(iters))
+		for _, iter := range iters {
+			stitched = append(stitched, newStitchedSeries(iter))
+		}
+
+		return stitched
+	}
+
+	var (
+		stitched []*StitchedSeries
+		byID     = make(map[string]*StitchedSeries)
+	)
+
+	for _, iters := range ranges {
+		for _, iter := range iters.Iters() {
+			series := newStitchedSeries(iter)
+			if existing, ok := byID[series.ID.String()]; ok {
+				existing.iters = append(existing.iters, iter)
+				continue
+			}
+
+			byID[series.ID.String()] = series
+			stitched = append(stitched, series)
+		}
+	}
+
+	return stitched
+}
+
+func newStitchedSeries(iter encoding.SeriesIterator) *StitchedSeries {
+	return &StitchedSeries{
+		ID:    iter.ID(),
+		Tags:  iter.Tags(),
+		iters: []encoding.SeriesIterator{iter},
+	}
+}
+
+// Next moves to the next datapoint
+func (s *StitchedSeries) Next() bool {
+	for ; s.idx < len(s.iters); s.idx++ {
+		iter := s.iters[s.idx]
+		if iter.Next() {
+			return true
+		}
+
+		if iter.Err() != nil {
+			return false
+		}
+	}
+
+	return false
+}
+
+// Current returns the current datapoint
+func (s *StitchedSeries) Current() (m3ts.Datapoint, xtime.Unit, m3ts.Annotation) {
+	return s.iters[s.idx].Current()
+}
+
+// Err returns the error encountered
+func (s *StitchedSeries) Err() error {
+	if s.idx < len(s.iters) {
+		return s.iters[s.idx].Err()
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/m3db/m3db/src/coordinator/errors"
+)
+
+const (
+	// WarningNonExhaustive is the name of warnings for index results which
+	// were truncated before all matching series were returned
+	WarningNonExhaustive = "non_exhaustive"
+	// WarningStoreFailed is the name of warnings for stores whose results
+	// were dropped because they failed
+	WarningStoreFailed = "store_failed"
+)
+
+// Warning describes why a result may be incomplete
+type Warning struct {
+	Name    string
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s", w.Name, w.Message)
+}
+
+// NewNonExhaustiveWarning returns the warning for a fetch whose index results
+// were truncated, limit is the series limit of the fetch or zero if unknown
+func NewNonExhaustiveWarning(limit int) Warning {
+	if limit > 0 {
+		return Warning{
+			Name:    WarningNonExhaustive,
+			Message: fmt.Sprintf("series limit of %d reached", limit),
+		}
+	}
+
+	return Warning{
+		Name:    WarningNonExhaustive,
+		Message: "index results are not exhaustive",
+	}
+}
+
+// NewStoreFailedWarning returns the warning for a store whose results were
+// dropped because it failed
+func NewStoreFailedWarning(store Type, err error) Warning {
+	return Warning{
+		Name:    WarningStoreFailed,
+		Message: fmt.Sprintf("%s store failed: %v", store, err),
+	}
+}
+
+// Warnings is the list of warnings attached to a result
+type Warnings []Warning
+
+// Strings returns the warnings as strings
+func (w Warnings) Strings() []string {
+	if len(w) == 0 {
+		return nil
+	}
+
+	strs := make([]string, 0, len(w))
+	for _, warning := range w {
+		strs = append(strs, warning.String())
+	}
+
+	return strs
+}
+
+// Err returns a partial result error for the warnings, or nil if there are none
+func (w Warnings) Err() error {
+	if len(w) == 0 {
+		return nil
+	}
+
+	return errors.NewPartialResultError(w.Strings())
+}
+
+// WarningsCollector collects the warnings of all fetches made by a query, it
+// is safe for concurrent use and a nil collector discards all warnings
+type WarningsCollector struct {
+	sync.Mutex
+	warnings Warnings
+}
+
+// NewWarningsCollector creates a new warnings collector
+func NewWarningsCollector() *WarningsCollector {
+	return &WarningsCollector{}
+}
+
+// Add adds warnings to the collector
+func (c *WarningsCollector) Add(warnings Warnings) {
+	if c == nil || len(warnings) == 0 {
+		return
+	}
+
+	c.Lock()
+	c.warnings = append(c.warnings, warnings...)
+	c.Unlock()
+}
+
+// Warnings returns the collected warnings
+func (c *WarningsCollector) Warnings() Warnings {
+	if c == nil {
+		return nil
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	return append(Warnings(nil), c.warnings...)
+}
@@ -21,6 +21,7 @@
 package m3db
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sync"
@@ -137,25 +138,37 @@ func (s *AsyncSession) FetchIDs(namespace ident.ID, ids ident.Iterator, startInc
 }
 
 // FetchTagged resolves the provided query to known IDs, and fetches the data for them
-func (s *AsyncSession) FetchTagged(namespace ident.ID, q index.Query, opts index.QueryOptions) (results encoding.SeriesIterators, exhaustive bool, err error) {
+func (s *AsyncSession) FetchTagged(ctx context.Context, namespace ident.ID, q index.Query, opts index.QueryOptions) (results encoding.SeriesIterators, exhaustive bool, err error) {
 	s.RLock()
 	defer s.RUnlock()
 	if s.err != nil {
 		return nil, false, s.err
 	}
 
-	return s.session.FetchTagged(namespace, q, opts)
+	return s.session.FetchTagged(ctx, namespace, q, opts)
 }
 
 // FetchTaggedIDs resolves the provided query to known IDs.
-func (s *AsyncSession) FetchTaggedIDs(namespace ident.ID, q index.Query, opts index.QueryOptions) (client.TaggedIDsIterator, bool, error) {
+func (s *AsyncSession) FetchTaggedIDs(ctx context.Context, namespace ident.ID, q index.Query, opts index.QueryOptions) (client.TaggedIDsIterator, bool, error) {
 	s.RLock()
 	defer s.RUnlock()
 	if s.err != nil {
 		return nil, false, s.err
 	}
 
-	return s.session.FetchTaggedIDs(namespace, q, opts)
+	return s.session.FetchTaggedIDs(ctx, namespace, q, opts)
+}
+
+// Aggregate resolves the provided query to the distinct tag names or values
+// of the matching series.
+func (s *AsyncSession) Aggregate(ctx context.Context, namespace ident.ID, q index.Query, opts index.AggregateQueryOptions) ([]index.AggregateTerm, bool, error) {
+	s.RLock()
+	defer s.RUnlock()
+	if s.err != nil {
+		return nil, false, s.err
+	}
+
+	return s.session.Aggregate(ctx, namespace, q, opts)
 }
 
 // ShardID returns the given shard for an ID for callers
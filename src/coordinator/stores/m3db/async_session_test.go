@@ -21,6 +21,7 @@
 package m3db
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
@@ -98,12 +99,12 @@ func TestAsyncSessionUninitialized(t *testing.T) {
 	}, nil)
 	require.NotNil(t, asyncSession)
 
-	results, exhaustive, err := asyncSession.FetchTagged(namespace, index.Query{}, index.QueryOptions{})
+	results, exhaustive, err := asyncSession.FetchTagged(context.Background(), namespace, index.Query{}, index.QueryOptions{})
 	assert.Nil(t, results)
 	assert.Equal(t, false, exhaustive)
 	assert.Equal(t, err, errSessionUninitialized)
 
-	_, _, err = asyncSession.FetchTaggedIDs(namespace, index.Query{}, index.QueryOptions{})
+	_, _, err = asyncSession.FetchTaggedIDs(context.Background(), namespace, index.Query{}, index.QueryOptions{})
 	assert.Equal(t, err, errSessionUninitialized)
 
 	id, err := asyncSession.ShardID(nil)
@@ -146,12 +147,12 @@ func TestAsyncSessionInitialized(t *testing.T) {
 	_, err = asyncSession.FetchIDs(nil, nil, time.Now(), time.Now())
 	assert.NoError(t, err)
 
-	mockSession.EXPECT().FetchTagged(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, false, nil)
-	_, _, err = asyncSession.FetchTagged(namespace, index.Query{}, index.QueryOptions{})
+	mockSession.EXPECT().FetchTagged(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, false, nil)
+	_, _, err = asyncSession.FetchTagged(context.Background(), namespace, index.Query{}, index.QueryOptions{})
 	assert.NoError(t, err)
 
-	mockSession.EXPECT().FetchTaggedIDs(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, false, nil)
-	_, _, err = asyncSession.FetchTaggedIDs(namespace, index.Query{}, index.QueryOptions{})
+	mockSession.EXPECT().FetchTaggedIDs(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, false, nil)
+	_, _, err = asyncSession.FetchTaggedIDs(context.Background(), namespace, index.Query{}, index.QueryOptions{})
 	assert.NoError(t, err)
 
 	mockSession.EXPECT().ShardID(gomock.Any()).Return(uint32(0), nil)
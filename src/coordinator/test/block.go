@@ -0,0 +1,74 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/m3db/m3db/src/coordinator/models"
+	"github.com/m3db/m3db/src/coordinator/storage"
+)
+
+// NewBlockFromValues creates a new block using the provided values
+func NewBlockFromValues(bounds storage.Bounds, seriesValues [][]float64) storage.Block {
+	meta := newSeriesMeta("dummy", len(seriesValues))
+	return NewBlockFromValuesWithSeriesMeta(bounds, meta, seriesValues)
+}
+
+// NewBlockFromValuesWithSeriesMeta creates a new block using the provided values
+func NewBlockFromValuesWithSeriesMeta(
+	bounds storage.Bounds,
+	seriesMeta []storage.SeriesMeta,
+	seriesValues [][]float64,
+) storage.Block {
+	columnBuilder := storage.NewColumnBlockBuilder(storage.BlockMetadata{Bounds: bounds}, seriesMeta)
+	for _, seriesVal := range seriesValues {
+		for idx, val := range seriesVal {
+			columnBuilder.AppendValue(idx, val)
+		}
+	}
+
+	return columnBuilder.Build()
+}
+
+func newSeriesMeta(tagPrefix string, count int) []storage.SeriesMeta {
+	seriesMeta := make([]storage.SeriesMeta, count)
+	for i := range seriesMeta {
+		tags := make(models.Tags)
+		tags[models.MetricName] = fmt.Sprintf("%s_%d", tagPrefix, i)
+		seriesMeta[i] = storage.SeriesMeta{
+			Name: tags.ID(),
+			Tags: tags,
+		}
+	}
+
+	return seriesMeta
+}
+
+// NewBounds creates bounds starting at the given time with the given number of steps
+func NewBounds(start time.Time, stepSize time.Duration, steps int) storage.Bounds {
+	return storage.Bounds{
+		Start:    start,
+		End:      start.Add(time.Duration(steps) * stepSize),
+		StepSize: stepSize,
+	}
+}
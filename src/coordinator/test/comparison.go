@@ -0,0 +1,68 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// EqualsWithNans helps compare float slices which have NaNs in them
+func EqualsWithNans(t *testing.T, expected interface{}, actual interface{}) {
+	EqualsWithNansWithDelta(t, expected, actual, 0)
+}
+
+// EqualsWithNansWithDelta helps compare float slices which have NaNs in them,
+// allowing a delta for float comparisons
+func EqualsWithNansWithDelta(t *testing.T, expected interface{}, actual interface{}, delta float64) {
+	switch v := expected.(type) {
+	case [][]float64:
+		actualV, ok := actual.([][]float64)
+		require.True(t, ok, "actual should be of type [][]float64, found: %T", actual)
+		require.Len(t, actualV, len(v))
+		for i, vals := range v {
+			equalsWithNans(t, vals, actualV[i], delta)
+		}
+
+	case []float64:
+		actualV, ok := actual.([]float64)
+		require.True(t, ok, "actual should be of type []float64, found: %T", actual)
+		equalsWithNans(t, v, actualV, delta)
+
+	default:
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func equalsWithNans(t *testing.T, expected []float64, actual []float64, delta float64) {
+	require.Len(t, actual, len(expected))
+	for i, v := range expected {
+		if math.IsNaN(v) {
+			assert.True(t, math.IsNaN(actual[i]), "expected NaN at %d, found: %v", i, actual[i])
+			continue
+		}
+
+		assert.InDelta(t, v, actual[i], delta, "mismatch at %d", i)
+	}
+}
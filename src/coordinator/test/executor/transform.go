@@ -18,62 +18,47 @@
 // OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
 // THE SOFTWARE.
 
-package functions
+package executor
 
 import (
-	"fmt"
-
 	"github.com/m3db/m3db/src/coordinator/executor/transform"
 	"github.com/m3db/m3db/src/coordinator/parser"
 	"github.com/m3db/m3db/src/coordinator/storage"
 )
 
-// CountType counts number of elements in the vector
-const CountType = "count"
-
-// CountOp stores required properties for count
-type CountOp struct {
-}
-
-// OpType for the operator
-func (o CountOp) OpType() string {
-	return CountType
-}
-
-// String representation
-func (o CountOp) String() string {
-	return fmt.Sprintf("type: %s", o.OpType())
-}
+// NewControllerWithSink creates a new controller which has a sink useful for comparison in tests
+func NewControllerWithSink(ID parser.NodeID) (*transform.Controller, *SinkNode) {
+	c := &transform.Controller{
+		ID: ID,
+	}
 
-// Node creates an execution node
-func (o CountOp) Node(controller *transform.Controller) transform.OpNode {
-	return &CountNode{op: o, controller: controller}
+	node := &SinkNode{}
+	c.AddTransform(node)
+	return c, node
 }
 
-// CountNode is an execution node
-type CountNode struct {
-	op         CountOp
-	controller *transform.Controller
+// SinkNode is a test node useful for comparisons
+type SinkNode struct {
+	Values [][]float64
+	Meta   storage.BlockMetadata
+	Metas  []storage.SeriesMeta
 }
 
-// Process the block
-func (c *CountNode) Process(ID parser.NodeID, block storage.Block) error {
-	builder, err := c.controller.BlockBuilder(block.Meta())
-	if err != nil {
-		return err
-	}
-
-	stepIter := block.StepIter()
-	for index := 0; stepIter.Next(); index++ {
-		step := stepIter.Current()
-		values := step.Values()
-		sum := 0.0
-		for _, value := range values {
-			sum += value
+// Process processes and stores the last block output in the sink node
+func (s *SinkNode) Process(ID parser.NodeID, block storage.Block) error {
+	iter := block.SeriesIter()
+	s.Meta = block.Meta()
+	s.Metas = block.SeriesMeta()
+	s.Values = make([][]float64, 0, iter.SeriesCount())
+	for iter.Next() {
+		series := iter.Current()
+		values := make([]float64, series.Len())
+		for i := range values {
+			values[i] = series.Values().ValueAt(i)
 		}
 
-		builder.AppendValue(index, sum)
+		s.Values = append(s.Values, values)
 	}
 
-	return c.controller.Process(builder.Build())
+	return nil
 }
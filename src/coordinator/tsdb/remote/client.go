@@ -22,38 +22,66 @@ package remote
 
 import (
 	"context"
+	"crypto/tls"
 	"io"
+	"time"
 
 	"github.com/m3db/m3db/src/coordinator/errors"
 	"github.com/m3db/m3db/src/coordinator/generated/proto/rpc"
+	"github.com/m3db/m3db/src/coordinator/models"
 	"github.com/m3db/m3db/src/coordinator/storage"
 	"github.com/m3db/m3db/src/coordinator/ts"
 	"github.com/m3db/m3db/src/coordinator/util/logging"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // Client is an interface
 type Client interface {
 	storage.Querier
 	storage.Appender
+	storage.SubPlanExecutor
 	Close() error
 }
 
 type grpcClient struct {
 	client     rpc.QueryClient
 	connection *grpc.ClientConn
+	timeout    time.Duration
+}
+
+// ClientOptions are the options of a grpc client
+type ClientOptions struct {
+	// Timeout is the deadline of each request, zero for no deadline other
+	// than that of the query
+	Timeout time.Duration
+	// TLS secures the connections with TLS when set, otherwise they are
+	// insecure
+	TLS *tls.Config
+	// DialOptions are additional dial options such as per-RPC credentials
+	DialOptions []grpc.DialOption
 }
 
 // NewGrpcClient creates grpc client
 func NewGrpcClient(addresses []string, additionalDialOpts ...grpc.DialOption) (Client, error) {
+	return NewGrpcClientWithOptions(addresses, ClientOptions{DialOptions: additionalDialOpts})
+}
+
+// NewGrpcClientWithOptions creates grpc client with the given options
+func NewGrpcClientWithOptions(addresses []string, opts ClientOptions) (Client, error) {
 	if len(addresses) == 0 {
 		return nil, errors.ErrNoClientAddresses
 	}
 	resolver := newStaticResolver(addresses)
 	balancer := grpc.RoundRobin(resolver)
-	dialOptions := []grpc.DialOption{grpc.WithBalancer(balancer), grpc.WithInsecure()}
-	dialOptions = append(dialOptions, additionalDialOpts...)
+	dialOptions := []grpc.DialOption{grpc.WithBalancer(balancer)}
+	if opts.TLS != nil {
+		dialOptions = append(dialOptions, grpc.WithTransportCredentials(credentials.NewTLS(opts.TLS)))
+	} else {
+		dialOptions = append(dialOptions, grpc.WithInsecure())
+	}
+	dialOptions = append(dialOptions, opts.DialOptions...)
 
 	cc, err := grpc.Dial("", dialOptions...)
 	if err != nil {
@@ -64,13 +92,26 @@ func NewGrpcClient(addresses []string, additionalDialOpts ...grpc.DialOption) (C
 	return &grpcClient{
 		client:     client,
 		connection: cc,
+		timeout:    opts.Timeout,
 	}, nil
 }
 
+// requestContext returns the context of a request, with the client timeout
+func (c *grpcClient) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout > 0 {
+		return context.WithTimeout(ctx, c.timeout)
+	}
+
+	return context.WithCancel(ctx)
+}
+
 // Fetch reads from remote client storage
 func (c *grpcClient) Fetch(ctx context.Context, query *storage.FetchQuery, options *storage.FetchOptions) (*storage.FetchResult, error) {
 	// Send the id from the client to the remote server so that provides logging
 	id := logging.ReadContextID(ctx)
+	ctx, cancel := c.requestContext(ctx)
+	defer cancel()
+
 	fetchClient, err := c.client.Fetch(ctx, EncodeFetchMessage(query, id))
 	if err != nil {
 		return nil, err
@@ -104,13 +145,31 @@ func (c *grpcClient) Fetch(ctx context.Context, query *storage.FetchQuery, optio
 	return &storage.FetchResult{LocalOnly: false, SeriesList: tsSeries}, nil
 }
 
+// FetchTags returns the tags of the series matching the query, which are
+// fetched along with their datapoints as the server has no call for tags only
 func (c *grpcClient) FetchTags(ctx context.Context, query *storage.FetchQuery, options *storage.FetchOptions) (*storage.SearchResults, error) {
-	return nil, nil
+	// Fetch applies the timeout of the client
+	result, err := c.Fetch(ctx, query, options)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := make(models.Metrics, 0, len(result.SeriesList))
+	for _, series := range result.SeriesList {
+		metrics = append(metrics, &models.Metric{
+			ID:   series.Name(),
+			Tags: series.Tags,
+		})
+	}
+
+	return &storage.SearchResults{Metrics: metrics, Warnings: result.Warnings}, nil
 }
 
 // Write writes to remote client storage
 func (c *grpcClient) Write(ctx context.Context, query *storage.WriteQuery) error {
 	client := c.client
+	ctx, cancel := c.requestContext(ctx)
+	defer cancel()
 
 	writeClient, err := client.Write(ctx)
 	if err != nil {
@@ -137,6 +196,45 @@ func (c *grpcClient) FetchBlocks(
 	return storage.BlockResult{}, errors.ErrNotImplemented
 }
 
+// ExecuteSubPlan has the remote server execute part of a query
+func (c *grpcClient) ExecuteSubPlan(
+	ctx context.Context, query *storage.SubPlanQuery, options *storage.FetchOptions) (storage.BlockResult, error) {
+	id := logging.ReadContextID(ctx)
+	ctx, cancel := c.requestContext(ctx)
+	defer cancel()
+
+	executeClient, err := c.client.Execute(ctx, EncodeExecuteMessage(query, id))
+	if err != nil {
+		return storage.BlockResult{}, err
+	}
+
+	defer executeClient.CloseSend()
+
+	var result storage.BlockResult
+	for {
+		rpcResult, err := executeClient.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return storage.BlockResult{}, err
+		}
+
+		for _, rpcBlock := range rpcResult.GetBlocks() {
+			block, err := DecodeBlock(rpcBlock)
+			if err != nil {
+				return storage.BlockResult{}, err
+			}
+
+			result.Blocks = append(result.Blocks, block)
+		}
+
+		result.Warnings = append(result.Warnings, DecodeWarnings(rpcResult.GetWarnings())...)
+	}
+
+	return result, nil
+}
+
 // Close closes the underlying connection
 func (c *grpcClient) Close() error {
 	return c.connection.Close()
@@ -269,3 +269,131 @@ func millisPerStep(dps *rpc.Datapoints) (time.Duration, bool) {
 
 	return time.Duration(points[1].Timestamp-points[0].Timestamp) * time.Millisecond, true
 }
+
+// EncodeExecuteMessage encodes a sub plan query into an rpc ExecuteMessage,
+// with nanosecond precision times as blocks are aligned to the query times
+func EncodeExecuteMessage(query *storage.SubPlanQuery, queryID string) *rpc.ExecuteMessage {
+	return &rpc.ExecuteMessage{
+		Query: &rpc.ExecuteQuery{
+			Query:  query.Query,
+			NodeID: query.NodeID,
+			Start:  query.Start.UnixNano(),
+			End:    query.End.UnixNano(),
+			Now:    query.Now.UnixNano(),
+			Step:   int64(query.Interval),
+		},
+		Options: encodeFetchOptions(queryID),
+	}
+}
+
+// DecodeExecuteMessage decodes an rpc ExecuteMessage to a sub plan query and
+// the query id
+func DecodeExecuteMessage(message *rpc.ExecuteMessage) (*storage.SubPlanQuery, string) {
+	query := message.GetQuery()
+	return &storage.SubPlanQuery{
+		Query:    query.GetQuery(),
+		NodeID:   query.GetNodeID(),
+		Start:    time.Unix(0, query.GetStart()),
+		End:      time.Unix(0, query.GetEnd()),
+		Now:      time.Unix(0, query.GetNow()),
+		Interval: time.Duration(query.GetStep()),
+	}, message.GetOptions().GetId()
+}
+
+// EncodeBlock encodes a block into an rpc Block, storing the values of
+// each series rather than of each step
+func EncodeBlock(block storage.Block) *rpc.Block {
+	meta := block.Meta()
+	seriesMeta := block.SeriesMeta()
+	series := make([]*rpc.BlockSeries, len(seriesMeta))
+	for i, m := range seriesMeta {
+		series[i] = &rpc.BlockSeries{
+			Name:   m.Name,
+			Tags:   m.Tags,
+			Values: make([]float64, 0, meta.Bounds.Steps()),
+		}
+	}
+
+	stepIter := block.StepIter()
+	for stepIter.Next() {
+		for i, value := range stepIter.Current().Values() {
+			series[i].Values = append(series[i].Values, value)
+		}
+	}
+
+	return &rpc.Block{
+		Start:  meta.Bounds.Start.UnixNano(),
+		End:    meta.Bounds.End.UnixNano(),
+		Step:   int64(meta.Bounds.StepSize),
+		Tags:   meta.Tags,
+		Series: series,
+	}
+}
+
+// DecodeBlock decodes an rpc Block to a block
+func DecodeBlock(rpcBlock *rpc.Block) (storage.Block, error) {
+	meta := storage.BlockMetadata{
+		Bounds: storage.Bounds{
+			Start:    time.Unix(0, rpcBlock.GetStart()),
+			End:      time.Unix(0, rpcBlock.GetEnd()),
+			StepSize: time.Duration(rpcBlock.GetStep()),
+		},
+		Tags: rpcBlock.GetTags(),
+	}
+
+	rpcSeries := rpcBlock.GetSeries()
+	seriesMeta := make([]storage.SeriesMeta, len(rpcSeries))
+	for i, series := range rpcSeries {
+		seriesMeta[i] = storage.SeriesMeta{
+			Name: series.GetName(),
+			Tags: series.GetTags(),
+		}
+	}
+
+	steps := meta.Bounds.Steps()
+	builder := storage.NewColumnBlockBuilder(meta, seriesMeta)
+	for _, series := range rpcSeries {
+		values := series.GetValues()
+		if len(values) != steps {
+			return nil, fmt.Errorf("series %s has %d values for %d steps", series.GetName(), len(values), steps)
+		}
+
+		for idx, value := range values {
+			if err := builder.AppendValue(idx, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return builder.Build(), nil
+}
+
+// EncodeWarnings encodes warnings into rpc Warnings
+func EncodeWarnings(warnings storage.Warnings) []*rpc.Warning {
+	rpcWarnings := make([]*rpc.Warning, len(warnings))
+	for i, warning := range warnings {
+		rpcWarnings[i] = &rpc.Warning{
+			Name:    warning.Name,
+			Message: warning.Message,
+		}
+	}
+
+	return rpcWarnings
+}
+
+// DecodeWarnings decodes rpc Warnings to warnings
+func DecodeWarnings(rpcWarnings []*rpc.Warning) storage.Warnings {
+	if len(rpcWarnings) == 0 {
+		return nil
+	}
+
+	warnings := make(storage.Warnings, len(rpcWarnings))
+	for i, warning := range rpcWarnings {
+		warnings[i] = storage.Warning{
+			Name:    warning.GetName(),
+			Message: warning.GetMessage(),
+		}
+	}
+
+	return warnings
+}
@@ -254,3 +254,79 @@ func TestEncodeDecodeWriteQuery(t *testing.T) {
 	reencw := EncodeWriteMessage(rev, decodeID)
 	assert.Equal(t, encw, reencw)
 }
+
+func makeBlock(t *testing.T, start, end time.Time, step time.Duration) storage.Block {
+	meta := storage.BlockMetadata{
+		Bounds: storage.Bounds{Start: start, End: end, StepSize: step},
+		Tags:   models.Tags{"common": "tag"},
+	}
+	seriesMeta := []storage.SeriesMeta{
+		{Name: "a", Tags: models.Tags{"series": "a"}},
+		{Name: "b", Tags: models.Tags{"series": "b"}},
+	}
+
+	builder := storage.NewColumnBlockBuilder(meta, seriesMeta)
+	for i := 0; i < meta.Bounds.Steps(); i++ {
+		require.NoError(t, builder.AppendValues(i, []float64{float64(i), float64(-i)}))
+	}
+
+	return builder.Build()
+}
+
+func blockValues(block storage.Block) [][]float64 {
+	var values [][]float64
+	stepIter := block.StepIter()
+	for stepIter.Next() {
+		values = append(values, stepIter.Current().Values())
+	}
+
+	return values
+}
+
+func assertBlocksEqual(t *testing.T, expected, actual storage.Block) {
+	assert.True(t, expected.Meta().Bounds.Start.Equal(actual.Meta().Bounds.Start))
+	assert.True(t, expected.Meta().Bounds.End.Equal(actual.Meta().Bounds.End))
+	assert.Equal(t, expected.Meta().Bounds.StepSize, actual.Meta().Bounds.StepSize)
+	assert.Equal(t, expected.Meta().Tags, actual.Meta().Tags)
+	assert.Equal(t, expected.SeriesMeta(), actual.SeriesMeta())
+	assert.Equal(t, blockValues(expected), blockValues(actual))
+}
+
+func TestEncodeDecodeBlock(t *testing.T) {
+	block := makeBlock(t, now.Add(-time.Minute), now, 10*time.Second)
+	rpcBlock := EncodeBlock(block)
+	require.Len(t, rpcBlock.Series, 2)
+	assert.Equal(t, []float64{0, 1, 2, 3, 4, 5}, rpcBlock.Series[0].Values)
+	assert.Equal(t, []float64{0, -1, -2, -3, -4, -5}, rpcBlock.Series[1].Values)
+
+	decoded, err := DecodeBlock(rpcBlock)
+	require.NoError(t, err)
+	assertBlocksEqual(t, block, decoded)
+}
+
+func TestDecodeBlockMissingValues(t *testing.T) {
+	rpcBlock := EncodeBlock(makeBlock(t, now.Add(-time.Minute), now, 10*time.Second))
+	rpcBlock.Series[1].Values = rpcBlock.Series[1].Values[1:]
+	_, err := DecodeBlock(rpcBlock)
+	assert.Error(t, err)
+}
+
+func TestEncodeDecodeExecuteMessage(t *testing.T) {
+	query := &storage.SubPlanQuery{
+		Query:    "sum(rate(foo[1m]))",
+		NodeID:   "2",
+		Start:    now.Add(-time.Hour),
+		End:      now,
+		Now:      now,
+		Interval: time.Minute,
+	}
+
+	decoded, id := DecodeExecuteMessage(EncodeExecuteMessage(query, "id"))
+	assert.Equal(t, "id", id)
+	assert.Equal(t, query.Query, decoded.Query)
+	assert.Equal(t, query.NodeID, decoded.NodeID)
+	assert.True(t, query.Start.Equal(decoded.Start))
+	assert.True(t, query.End.Equal(decoded.End))
+	assert.True(t, query.Now.Equal(decoded.Now))
+	assert.Equal(t, query.Interval, decoded.Interval)
+}
@@ -0,0 +1,282 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remote
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationHeader = "authorization"
+	bearerPrefix        = "Bearer "
+)
+
+var (
+	errMissingCertificate = errors.New("tls requires both a certificate and key file")
+	errNoClientCAs        = errors.New("requiring client certificates requires a CA file")
+	errNoAllowedTokens    = errors.New("no allowed bearer tokens")
+)
+
+// TLSConfiguration is the TLS configuration of the remote gRPC server and
+// clients. The same certificate is presented as the server certificate and
+// as the client certificate for mutual TLS.
+type TLSConfiguration struct {
+	// CertFile is the PEM encoded certificate file.
+	CertFile string `yaml:"certFile"`
+
+	// KeyFile is the PEM encoded private key file of the certificate.
+	KeyFile string `yaml:"keyFile"`
+
+	// CAFile is the PEM encoded certificate authorities file, verifying the
+	// certificates of remote servers and, for mutual TLS, of clients.
+	CAFile string `yaml:"caFile"`
+
+	// ServerName is the name the certificates of remote servers are verified
+	// against.
+	ServerName string `yaml:"serverName"`
+
+	// RequireClientCert requires clients to present a certificate signed by
+	// the certificate authorities of CAFile.
+	RequireClientCert bool `yaml:"requireClientCert"`
+}
+
+// ServerTLSConfig returns the TLS config of the server.
+func (c TLSConfiguration) ServerTLSConfig() (*tls.Config, error) {
+	if c.CertFile == "" || c.KeyFile == "" {
+		return nil, errMissingCertificate
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if c.RequireClientCert {
+		if c.CAFile == "" {
+			return nil, errNoClientCAs
+		}
+
+		pool, err := loadCertPool(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return config, nil
+}
+
+// ClientTLSConfig returns the TLS config of the clients.
+func (c TLSConfiguration) ClientTLSConfig() (*tls.Config, error) {
+	config := &tls.Config{ServerName: c.ServerName}
+	if c.CAFile != "" {
+		pool, err := loadCertPool(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		config.RootCAs = pool
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", file)
+	}
+
+	return pool, nil
+}
+
+// KeepaliveConfiguration is the configuration of the keepalive pings of the
+// remote gRPC connections.
+type KeepaliveConfiguration struct {
+	// Time is the interval of inactivity after which the connection is pinged.
+	Time time.Duration `yaml:"time"`
+
+	// Timeout is how long to wait for a ping to be acknowledged before
+	// closing the connection.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// ClientParameters returns the client keepalive parameters.
+func (c KeepaliveConfiguration) ClientParameters() keepalive.ClientParameters {
+	return keepalive.ClientParameters{
+		Time:                c.Time,
+		Timeout:             c.Timeout,
+		PermitWithoutStream: true,
+	}
+}
+
+// ServerParameters returns the server keepalive parameters.
+func (c KeepaliveConfiguration) ServerParameters() keepalive.ServerParameters {
+	return keepalive.ServerParameters{
+		Time:    c.Time,
+		Timeout: c.Timeout,
+	}
+}
+
+// EnforcementPolicy returns the server keepalive enforcement policy, which
+// allows the clients to ping as often as they are configured to.
+func (c KeepaliveConfiguration) EnforcementPolicy() keepalive.EnforcementPolicy {
+	return keepalive.EnforcementPolicy{
+		MinTime:             c.Time,
+		PermitWithoutStream: true,
+	}
+}
+
+// AuthConfiguration is the bearer token authentication configuration of the
+// remote gRPC server and clients.
+type AuthConfiguration struct {
+	// Token is the bearer token sent to remote servers.
+	Token string `yaml:"token"`
+
+	// AllowedTokens are the bearer tokens the server accepts, if any then
+	// requests without one of them are rejected.
+	AllowedTokens []string `yaml:"allowedTokens"`
+
+	// Insecure allows the tokens to be sent over connections without TLS,
+	// where they can be read by anyone observing the network.
+	Insecure bool `yaml:"insecure"`
+}
+
+type bearerTokenCredentials struct {
+	token      string
+	requireTLS bool
+}
+
+// NewBearerTokenCredentials returns per-RPC credentials sending the token as
+// a bearer token in the authorization header of each request.
+func NewBearerTokenCredentials(token string, requireTLS bool) credentials.PerRPCCredentials {
+	return &bearerTokenCredentials{token: token, requireTLS: requireTLS}
+}
+
+func (c *bearerTokenCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{authorizationHeader: bearerPrefix + c.token}, nil
+}
+
+func (c *bearerTokenCredentials) RequireTransportSecurity() bool {
+	return c.requireTLS
+}
+
+type bearerTokenAuthenticator struct {
+	tokens [][]byte
+}
+
+// NewBearerTokenServerOptions returns server options rejecting requests which
+// do not carry one of the allowed tokens as a bearer token.
+func NewBearerTokenServerOptions(allowedTokens []string) ([]grpc.ServerOption, error) {
+	if len(allowedTokens) == 0 {
+		return nil, errNoAllowedTokens
+	}
+
+	a := &bearerTokenAuthenticator{tokens: make([][]byte, 0, len(allowedTokens))}
+	for _, token := range allowedTokens {
+		a.tokens = append(a.tokens, []byte(token))
+	}
+
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(a.unaryInterceptor),
+		grpc.StreamInterceptor(a.streamInterceptor),
+	}, nil
+}
+
+func (a *bearerTokenAuthenticator) authenticate(ctx context.Context) error {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "missing metadata")
+	}
+
+	for _, header := range md[authorizationHeader] {
+		if !strings.HasPrefix(header, bearerPrefix) {
+			continue
+		}
+
+		token := []byte(strings.TrimPrefix(header, bearerPrefix))
+		for _, allowed := range a.tokens {
+			if subtle.ConstantTimeCompare(token, allowed) == 1 {
+				return nil
+			}
+		}
+	}
+
+	return status.Error(codes.Unauthenticated, "invalid bearer token")
+}
+
+func (a *bearerTokenAuthenticator) unaryInterceptor(
+	ctx context.Context,
+	req interface{},
+	_ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	if err := a.authenticate(ctx); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (a *bearerTokenAuthenticator) streamInterceptor(
+	srv interface{},
+	stream grpc.ServerStream,
+	_ *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	if err := a.authenticate(stream.Context()); err != nil {
+		return err
+	}
+
+	return handler(srv, stream)
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package remote
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+)
+
+type testCertificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// writeTestCertificate generates a certificate signed by the parent, or self
+// signed without one, and writes it and its key to the directory
+func writeTestCertificate(t *testing.T, dir, name string, parent *testCertificate) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name+".crt"), certPEM, 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name+".key"), keyPEM, 0600))
+
+	return &testCertificate{cert: cert, key: key}
+}
+
+func newTestTLSConfiguration(t *testing.T, dir, name string) TLSConfiguration {
+	return TLSConfiguration{
+		CertFile:          filepath.Join(dir, name+".crt"),
+		KeyFile:           filepath.Join(dir, name+".key"),
+		CAFile:            filepath.Join(dir, "ca.crt"),
+		ServerName:        "localhost",
+		RequireClientCert: true,
+	}
+}
+
+func TestRpcTLSAndBearerToken(t *testing.T) {
+	dir, err := ioutil.TempDir("", "remote-tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ca := writeTestCertificate(t, dir, "ca", nil)
+	writeTestCertificate(t, dir, "server", ca)
+	writeTestCertificate(t, dir, "client", ca)
+
+	serverTLS, err := newTestTLSConfiguration(t, dir, "server").ServerTLSConfig()
+	require.NoError(t, err)
+	authOpts, err := NewBearerTokenServerOptions([]string{"secret"})
+	require.NoError(t, err)
+
+	ctx, read, write, readOpts, host := createCtxReadWriteOpts(t)
+	store := &mockStorage{t: t, read: read, write: write}
+	startServer(t, host, store, append(authOpts, grpc.Creds(credentials.NewTLS(serverTLS)))...)
+
+	clientTLS, err := newTestTLSConfiguration(t, dir, "client").ClientTLSConfig()
+	require.NoError(t, err)
+
+	newClient := func(tlsCfg TLSConfiguration, token string) Client {
+		clientTLS, err := tlsCfg.ClientTLSConfig()
+		require.NoError(t, err)
+		client, err := NewGrpcClientWithOptions([]string{host}, ClientOptions{
+			Timeout:     time.Second,
+			TLS:         clientTLS,
+			DialOptions: []grpc.DialOption{grpc.WithPerRPCCredentials(NewBearerTokenCredentials(token, true))},
+		})
+		require.NoError(t, err)
+		return client
+	}
+
+	client, err := NewGrpcClientWithOptions([]string{host}, ClientOptions{
+		TLS: clientTLS,
+		DialOptions: []grpc.DialOption{
+			grpc.WithBlock(),
+			grpc.WithPerRPCCredentials(NewBearerTokenCredentials("secret", true)),
+		},
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	checkWrite(ctx, t, client, write)
+	checkFetch(ctx, t, client, read, readOpts)
+
+	// Requests with an invalid token are rejected
+	client = newClient(newTestTLSConfiguration(t, dir, "client"), "wrong")
+	defer client.Close()
+	_, err = client.Fetch(ctx, read, readOpts)
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, grpc.Code(err))
+
+	// Clients without a certificate cannot connect
+	noCert := newTestTLSConfiguration(t, dir, "client")
+	noCert.CertFile, noCert.KeyFile = "", ""
+	client = newClient(noCert, "secret")
+	defer client.Close()
+	_, err = client.Fetch(ctx, read, readOpts)
+	assert.Error(t, err)
+}
+
+func TestTLSConfigurationInvalid(t *testing.T) {
+	_, err := TLSConfiguration{}.ServerTLSConfig()
+	assert.Error(t, err)
+
+	_, err = NewBearerTokenServerOptions(nil)
+	assert.Error(t, err)
+}
@@ -24,28 +24,70 @@ import (
 	"io"
 	"net"
 
+	"github.com/m3db/m3db/src/coordinator/cost"
 	"github.com/m3db/m3db/src/coordinator/generated/proto/rpc"
 	"github.com/m3db/m3db/src/coordinator/storage"
 	"github.com/m3db/m3db/src/coordinator/util/logging"
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// ServerLimits are the resource limits of the queries served to other
+// coordinators
+type ServerLimits struct {
+	// PerQuery are the limits applied to each query.
+	PerQuery cost.Limits
+	// Global are the limits applied across all queries being served.
+	Global cost.Limits
+}
+
 type grpcServer struct {
-	storage storage.Storage
+	storage  storage.Storage
+	executor storage.SubPlanExecutor
+	enforcer *cost.Enforcer
+	perQuery cost.Limits
 }
 
-func newServer(store storage.Storage) *grpcServer {
+func newServer(store storage.Storage, executor storage.SubPlanExecutor, limits ServerLimits) *grpcServer {
 	return &grpcServer{
-		storage: store,
+		storage:  store,
+		executor: executor,
+		enforcer: cost.NewEnforcer(limits.Global),
+		perQuery: limits.PerQuery,
 	}
 }
 
-// CreateNewGrpcServer creates server, given context local storage
-func CreateNewGrpcServer(store storage.Storage) *grpc.Server {
-	server := grpc.NewServer()
-	grpcServer := newServer(store)
+// CreateNewGrpcServer creates server, given context local storage and
+// options such as the transport credentials
+func CreateNewGrpcServer(store storage.Storage, opts ...grpc.ServerOption) *grpc.Server {
+	return CreateNewGrpcServerWithExecutor(store, nil, opts...)
+}
+
+// CreateNewGrpcServerWithExecutor creates server, given context local storage
+// and the executor of the parts of queries pushed down by other coordinators,
+// which may be nil to reject them
+func CreateNewGrpcServerWithExecutor(
+	store storage.Storage,
+	executor storage.SubPlanExecutor,
+	opts ...grpc.ServerOption,
+) *grpc.Server {
+	return CreateNewGrpcServerWithLimits(store, executor, ServerLimits{}, opts...)
+}
+
+// CreateNewGrpcServerWithLimits creates server, given context local storage,
+// the executor of the parts of queries pushed down by other coordinators and
+// the resource limits of the queries served
+func CreateNewGrpcServerWithLimits(
+	store storage.Storage,
+	executor storage.SubPlanExecutor,
+	limits ServerLimits,
+	opts ...grpc.ServerOption,
+) *grpc.Server {
+	server := grpc.NewServer(opts...)
+	grpcServer := newServer(store, executor, limits)
 	rpc.RegisterQueryServer(server, grpcServer)
 
 	return server
@@ -61,6 +103,14 @@ func StartNewGrpcServer(server *grpc.Server, address string, waitForStart chan<-
 	return server.Serve(lis)
 }
 
+// fetchOptions returns the options of a query being served, which charge its
+// resources to a new per query enforcer to release once it completes
+func (s *grpcServer) fetchOptions() *storage.FetchOptions {
+	return &storage.FetchOptions{
+		Enforcer: s.enforcer.Child(s.perQuery),
+	}
+}
+
 // Fetch reads from local storage
 func (s *grpcServer) Fetch(message *rpc.FetchMessage, stream rpc.Query_FetchServer) error {
 	storeQuery, id, err := DecodeFetchMessage(message)
@@ -72,9 +122,12 @@ func (s *grpcServer) Fetch(message *rpc.FetchMessage, stream rpc.Query_FetchServ
 		return err
 	}
 
+	options := s.fetchOptions()
+	defer options.Enforcer.Release()
+
 	// Iterate while there are more results
 	for {
-		result, err := s.storage.Fetch(ctx, storeQuery, nil)
+		result, err := s.storage.Fetch(ctx, storeQuery, options)
 
 		if err != nil {
 			logger.Error("unable to fetch local query", zap.Any("error", err))
@@ -118,3 +171,39 @@ func (s *grpcServer) Write(stream rpc.Query_WriteServer) error {
 		}
 	}
 }
+
+// Execute executes part of a query over local storage
+func (s *grpcServer) Execute(message *rpc.ExecuteMessage, stream rpc.Query_ExecuteServer) error {
+	query, id := DecodeExecuteMessage(message)
+	ctx := logging.NewContextWithID(stream.Context(), id)
+	logger := logging.WithContext(ctx)
+
+	if s.executor == nil {
+		return status.Error(codes.Unimplemented, "query pushdown is not enabled")
+	}
+
+	options := s.fetchOptions()
+	defer options.Enforcer.Release()
+
+	result, err := s.executor.ExecuteSubPlan(ctx, query, options)
+	if err != nil {
+		logger.Error("unable to execute local sub plan", zap.Any("error", err))
+		return err
+	}
+
+	// Send each block separately to bound the size of messages
+	for _, block := range result.Blocks {
+		err := stream.Send(&rpc.ExecuteResult{Blocks: []*rpc.Block{EncodeBlock(block)}})
+		block.Close()
+		if err != nil {
+			logger.Error("unable to send execute result", zap.Any("error", err))
+			return err
+		}
+	}
+
+	if len(result.Warnings) == 0 {
+		return nil
+	}
+
+	return stream.Send(&rpc.ExecuteResult{Warnings: EncodeWarnings(result.Warnings)})
+}
@@ -28,6 +28,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3db/src/coordinator/cost"
 	m3err "github.com/m3db/m3db/src/coordinator/errors"
 	"github.com/m3db/m3db/src/coordinator/models"
 	"github.com/m3db/m3db/src/coordinator/storage"
@@ -37,6 +38,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 )
 
 const (
@@ -138,8 +140,8 @@ func checkRemoteFetch(t *testing.T, res *storage.FetchResult) {
 	checkMultipleRemoteFetch(t, res, 1)
 }
 
-func startServer(t *testing.T, host string, store storage.Storage) {
-	server := CreateNewGrpcServer(store)
+func startServer(t *testing.T, host string, store storage.Storage, opts ...grpc.ServerOption) {
+	server := CreateNewGrpcServer(store, opts...)
 	waitForStart := make(chan struct{})
 	go func() {
 		err := StartNewGrpcServer(server, host, waitForStart)
@@ -231,6 +233,46 @@ func TestRpcMultipleRead(t *testing.T) {
 	checkWrite(ctx, t, client, write)
 }
 
+func TestRpcFetchTags(t *testing.T) {
+	ctx, read, write, readOpts, host := createCtxReadWriteOpts(t)
+	store := &mockStorage{
+		t:     t,
+		read:  read,
+		write: write,
+	}
+	startServer(t, host, store)
+	client, err := NewGrpcClient([]string{host}, grpc.WithBlock())
+	require.NoError(t, err)
+	defer client.Close()
+
+	result, err := client.FetchTags(ctx, read, readOpts)
+	require.NoError(t, err)
+	require.Len(t, result.Metrics, 1)
+	assert.Equal(t, name, result.Metrics[0].ID)
+	assert.Equal(t, tags, result.Metrics[0].Tags)
+}
+
+func TestRpcFetchTagsTimeout(t *testing.T) {
+	ctx, read, write, readOpts, host := createCtxReadWriteOpts(t)
+	store := &mockStorage{
+		t:           t,
+		read:        read,
+		write:       write,
+		sleepMillis: 500,
+	}
+	startServer(t, host, store)
+	client, err := NewGrpcClientWithOptions([]string{host}, ClientOptions{
+		Timeout:     50 * time.Millisecond,
+		DialOptions: []grpc.DialOption{grpc.WithBlock()},
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.FetchTags(ctx, read, readOpts)
+	require.Error(t, err)
+	assert.Equal(t, codes.DeadlineExceeded, grpc.Code(err))
+}
+
 func TestRpcStopsStreamingWhenFetchKilledOnClient(t *testing.T) {
 	ctx, read, write, readOpts, host := createCtxReadWriteOpts(t)
 	sleepMillis, numPages := 100, 10
@@ -418,3 +460,99 @@ func TestRoundRobinClientRpc(t *testing.T) {
 	assert.True(t, hitHost, "round robin did not write to host")
 	assert.True(t, hitErrHost, "round robin did not write to error host")
 }
+
+type subPlanExecutor struct {
+	t      *testing.T
+	query  *storage.SubPlanQuery
+	block  storage.Block
+	series int
+}
+
+func (e *subPlanExecutor) ExecuteSubPlan(
+	ctx context.Context, query *storage.SubPlanQuery, options *storage.FetchOptions) (storage.BlockResult, error) {
+	assert.Equal(e.t, e.query, query)
+	if err := options.Enforcer.AddSeries(e.series); err != nil {
+		return storage.BlockResult{}, err
+	}
+
+	return storage.BlockResult{
+		Blocks:   []storage.Block{e.block},
+		Warnings: storage.Warnings{storage.NewNonExhaustiveWarning(10)},
+	}, nil
+}
+
+func TestRpcExecuteSubPlan(t *testing.T) {
+	ctx, read, write, _, host := createCtxReadWriteOpts(t)
+	store := &mockStorage{t: t, read: read, write: write}
+	query := &storage.SubPlanQuery{
+		Query:    "sum(rate(foo[1m]))",
+		NodeID:   "2",
+		Start:    now.Add(-time.Minute),
+		End:      now,
+		Now:      now,
+		Interval: 20 * time.Second,
+	}
+	executor := &subPlanExecutor{t: t, query: query, block: makeBlock(t, query.Start, query.End, query.Interval)}
+	server := CreateNewGrpcServerWithExecutor(store, executor)
+	waitForStart := make(chan struct{})
+	go func() {
+		assert.NoError(t, StartNewGrpcServer(server, host, waitForStart))
+	}()
+	<-waitForStart
+
+	client, err := NewGrpcClient([]string{host}, grpc.WithBlock())
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, client.Close())
+	}()
+
+	result, err := client.ExecuteSubPlan(ctx, query, &storage.FetchOptions{})
+	require.NoError(t, err)
+	require.Len(t, result.Blocks, 1)
+	assertBlocksEqual(t, executor.block, result.Blocks[0])
+	assert.Equal(t, storage.Warnings{storage.NewNonExhaustiveWarning(10)}, result.Warnings)
+}
+
+func TestRpcExecuteSubPlanLimits(t *testing.T) {
+	ctx, read, write, _, host := createCtxReadWriteOpts(t)
+	store := &mockStorage{t: t, read: read, write: write}
+	query := &storage.SubPlanQuery{
+		Query:    "foo",
+		NodeID:   "0",
+		Start:    now.Add(-time.Minute),
+		End:      now,
+		Now:      now,
+		Interval: 20 * time.Second,
+	}
+	executor := &subPlanExecutor{t: t, query: query, block: makeBlock(t, query.Start, query.End, query.Interval), series: 2}
+	server := CreateNewGrpcServerWithLimits(store, executor, ServerLimits{
+		PerQuery: cost.Limits{MaxFetchedSeries: 1},
+	})
+	waitForStart := make(chan struct{})
+	go func() {
+		assert.NoError(t, StartNewGrpcServer(server, host, waitForStart))
+	}()
+	<-waitForStart
+
+	client, err := NewGrpcClient([]string{host}, grpc.WithBlock())
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, client.Close())
+	}()
+
+	_, err = client.ExecuteSubPlan(ctx, query, &storage.FetchOptions{})
+	assert.Error(t, err)
+}
+
+func TestRpcExecuteSubPlanDisabled(t *testing.T) {
+	ctx, read, write, _, host := createCtxReadWriteOpts(t)
+	startServer(t, host, &mockStorage{t: t, read: read, write: write})
+	client, err := NewGrpcClient([]string{host}, grpc.WithBlock())
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, client.Close())
+	}()
+
+	_, err = client.ExecuteSubPlan(ctx, &storage.SubPlanQuery{}, &storage.FetchOptions{})
+	assert.Equal(t, codes.Unimplemented, grpc.Code(err))
+}
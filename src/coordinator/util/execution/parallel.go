@@ -48,6 +48,43 @@ func ExecuteParallel(ctx context.Context, requests []Request) error {
 	return processParallel(ctx, requests)
 }
 
+// ExecuteParallelWithLimit executes a slice of requests in parallel with at
+// most limit of them running at once, stopping on first error. A limit of
+// zero or less runs all the requests at once.
+func ExecuteParallelWithLimit(ctx context.Context, requests []Request, limit int) error {
+	if limit <= 0 || limit >= len(requests) {
+		return processParallel(ctx, requests)
+	}
+
+	g, groupCtx := errgroup.WithContext(ctx)
+	running := make(chan struct{}, limit)
+	for _, req := range requests {
+		select {
+		case running <- struct{}{}:
+		case <-groupCtx.Done():
+		}
+
+		// Skip the remaining requests once a request failed or the parent
+		// context is done
+		if groupCtx.Err() != nil {
+			break
+		}
+
+		func(req Request) {
+			g.Go(func() error {
+				defer func() { <-running }()
+				return req.Process(groupCtx)
+			})
+		}(req)
+	}
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	return ctx.Err()
+}
+
 // Process the requests in parallel and stop on first error
 func processParallel(ctx context.Context, requests []Request) error {
 	g, ctx := errgroup.WithContext(ctx)
@@ -23,6 +23,7 @@ package execution
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -79,3 +80,45 @@ func TestSingleError(t *testing.T) {
 	assert.Error(t, err, "error in second request")
 	assert.False(t, requests[0].(*request).processed, "skip request on error")
 }
+
+type concurrentRequest struct {
+	running *int32
+	max     *int32
+}
+
+func (r concurrentRequest) Process(ctx context.Context) error {
+	running := atomic.AddInt32(r.running, 1)
+	defer atomic.AddInt32(r.running, -1)
+	for {
+		max := atomic.LoadInt32(r.max)
+		if running <= max || atomic.CompareAndSwapInt32(r.max, max, running) {
+			break
+		}
+	}
+
+	time.Sleep(time.Millisecond)
+	return nil
+}
+
+func TestParallelWithLimit(t *testing.T) {
+	var running, max int32
+	requests := make([]Request, 10)
+	for i := range requests {
+		requests[i] = concurrentRequest{running: &running, max: &max}
+	}
+
+	require.NoError(t, ExecuteParallelWithLimit(context.Background(), requests, 2))
+	assert.True(t, max <= 2, "at most 2 requests running at once, got %d", max)
+}
+
+func TestParallelWithLimitError(t *testing.T) {
+	requests := make([]Request, 5)
+	requests[0] = &request{order: 1, err: fmt.Errorf("problem executing")}
+	for i := 1; i < len(requests); i++ {
+		requests[i] = &request{order: 1}
+	}
+
+	err := ExecuteParallelWithLimit(context.Background(), requests, 1)
+	assert.Error(t, err)
+	assert.False(t, requests[len(requests)-1].(*request).processed, "skip requests after error")
+}
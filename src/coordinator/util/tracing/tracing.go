@@ -0,0 +1,283 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package tracing records the spans of queries, both to return them to the
+// caller and to export them to the global OpenTracing tracer.
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+// StartSpanFromContext starts a span as a child of the span of the context
+// with the same tracer, or with the global tracer if the context has no
+// span, and returns it along with a context holding it.
+func StartSpanFromContext(
+	ctx context.Context,
+	operation string,
+	opts ...opentracing.StartSpanOption,
+) (opentracing.Span, context.Context) {
+	tracer := opentracing.GlobalTracer()
+	if parent := opentracing.SpanFromContext(ctx); parent != nil {
+		tracer = parent.Tracer()
+	}
+
+	return StartSpanWithTracer(ctx, tracer, operation, opts...)
+}
+
+// StartSpanWithTracer starts a span with the given tracer, as a child of the
+// span of the context if any, and returns it along with a context holding it.
+func StartSpanWithTracer(
+	ctx context.Context,
+	tracer opentracing.Tracer,
+	operation string,
+	opts ...opentracing.StartSpanOption,
+) (opentracing.Span, context.Context) {
+	if parent := opentracing.SpanFromContext(ctx); parent != nil {
+		opts = append(opts, opentracing.ChildOf(parent.Context()))
+	}
+
+	span := tracer.StartSpan(operation, opts...)
+	return span, opentracing.ContextWithSpan(ctx, span)
+}
+
+// SpanRecord is a finished span.
+type SpanRecord struct {
+	Operation string                 `json:"operation"`
+	Start     time.Time              `json:"start"`
+	Duration  Duration               `json:"duration"`
+	Tags      map[string]interface{} `json:"tags,omitempty"`
+	Logs      []string               `json:"logs,omitempty"`
+	Children  []*SpanRecord          `json:"children,omitempty"`
+}
+
+// Duration is a duration marshalled as a Go duration string.
+type Duration time.Duration
+
+// MarshalText marshals the duration as a Go duration string.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// Recorder is a tracer which records the spans started with it, along with
+// passing them on to another tracer.
+type Recorder struct {
+	sync.Mutex
+	tracer opentracing.Tracer
+	roots  []*SpanRecord
+}
+
+// NewRecorder returns a new recorder passing spans on to the given tracer.
+func NewRecorder(tracer opentracing.Tracer) *Recorder {
+	return &Recorder{tracer: tracer}
+}
+
+// Spans returns copies of the records of the spans without a parent
+// recorded by the recorder, holding the records of their children. Spans are
+// recorded once finished.
+func (r *Recorder) Spans() []*SpanRecord {
+	r.Lock()
+	defer r.Unlock()
+	return copyRecords(r.roots)
+}
+
+// copyRecords copies records so that they can be read while spans which
+// outlive their parent are still being recorded
+func copyRecords(records []*SpanRecord) []*SpanRecord {
+	if len(records) == 0 {
+		return nil
+	}
+
+	copied := make([]*SpanRecord, 0, len(records))
+	for _, record := range records {
+		c := *record
+		c.Tags = make(map[string]interface{}, len(record.Tags))
+		for k, v := range record.Tags {
+			c.Tags[k] = v
+		}
+
+		c.Logs = append([]string(nil), record.Logs...)
+		c.Children = copyRecords(record.Children)
+		copied = append(copied, &c)
+	}
+
+	return copied
+}
+
+// StartSpan starts a span, recorded as a child of the first recorded span it
+// references if any.
+func (r *Recorder) StartSpan(
+	operation string,
+	opts ...opentracing.StartSpanOption,
+) opentracing.Span {
+	var options opentracing.StartSpanOptions
+	for _, opt := range opts {
+		opt.Apply(&options)
+	}
+
+	if options.StartTime.IsZero() {
+		options.StartTime = time.Now()
+	}
+
+	// References to recorded spans are passed on as references to the spans
+	// of the other tracer
+	var (
+		parent *recordedSpan
+		inner  = []opentracing.StartSpanOption{opentracing.StartTime(options.StartTime)}
+	)
+
+	for _, ref := range options.References {
+		if recorded, ok := ref.ReferencedContext.(recordedSpanContext); ok {
+			if parent == nil {
+				parent = recorded.span
+			}
+
+			ref.ReferencedContext = recorded.SpanContext
+		}
+
+		inner = append(inner, ref)
+	}
+
+	record := &SpanRecord{
+		Operation: operation,
+		Start:     options.StartTime,
+		Tags:      make(map[string]interface{}, len(options.Tags)),
+	}
+
+	for k, v := range options.Tags {
+		record.Tags[k] = v
+	}
+
+	inner = append(inner, opentracing.Tags(options.Tags))
+	return &recordedSpan{
+		Span:     r.tracer.StartSpan(operation, inner...),
+		recorder: r,
+		parent:   parent,
+		record:   record,
+	}
+}
+
+// Inject injects the context of the span of the other tracer.
+func (r *Recorder) Inject(sm opentracing.SpanContext, format interface{}, carrier interface{}) error {
+	if recorded, ok := sm.(recordedSpanContext); ok {
+		sm = recorded.SpanContext
+	}
+
+	return r.tracer.Inject(sm, format, carrier)
+}
+
+// Extract extracts a span context with the other tracer.
+func (r *Recorder) Extract(format interface{}, carrier interface{}) (opentracing.SpanContext, error) {
+	return r.tracer.Extract(format, carrier)
+}
+
+func (r *Recorder) finish(span *recordedSpan) {
+	r.Lock()
+	defer r.Unlock()
+
+	if span.parent == nil {
+		r.roots = append(r.roots, span.record)
+		return
+	}
+
+	// Children finishing after their parent are still recorded under it
+	span.parent.record.Children = append(span.parent.record.Children, span.record)
+}
+
+// recordedSpanContext is the context of a recorded span, wrapping the
+// context of the span of the other tracer
+type recordedSpanContext struct {
+	opentracing.SpanContext
+	span *recordedSpan
+}
+
+// recordedSpan is a recorded span, wrapping the span of the other tracer
+type recordedSpan struct {
+	opentracing.Span
+	recorder *Recorder
+	parent   *recordedSpan
+	record   *SpanRecord
+}
+
+func (s *recordedSpan) Context() opentracing.SpanContext {
+	return recordedSpanContext{SpanContext: s.Span.Context(), span: s}
+}
+
+func (s *recordedSpan) Tracer() opentracing.Tracer {
+	return s.recorder
+}
+
+func (s *recordedSpan) SetOperationName(operation string) opentracing.Span {
+	s.recorder.Lock()
+	s.record.Operation = operation
+	s.recorder.Unlock()
+	s.Span.SetOperationName(operation)
+	return s
+}
+
+func (s *recordedSpan) SetTag(key string, value interface{}) opentracing.Span {
+	s.recorder.Lock()
+	s.record.Tags[key] = value
+	s.recorder.Unlock()
+	s.Span.SetTag(key, value)
+	return s
+}
+
+func (s *recordedSpan) LogFields(fields ...log.Field) {
+	s.recorder.Lock()
+	for _, field := range fields {
+		s.record.Logs = append(s.record.Logs, field.String())
+	}
+	s.recorder.Unlock()
+	s.Span.LogFields(fields...)
+}
+
+func (s *recordedSpan) LogKV(keyValues ...interface{}) {
+	fields, err := log.InterleavedKVToFields(keyValues...)
+	if err != nil {
+		s.Span.LogKV(keyValues...)
+		return
+	}
+
+	s.LogFields(fields...)
+}
+
+func (s *recordedSpan) Finish() {
+	s.FinishWithOptions(opentracing.FinishOptions{})
+}
+
+func (s *recordedSpan) FinishWithOptions(opts opentracing.FinishOptions) {
+	finish := opts.FinishTime
+	if finish.IsZero() {
+		finish = time.Now()
+	}
+
+	s.recorder.Lock()
+	s.record.Duration = Duration(finish.Sub(s.record.Start))
+	s.recorder.Unlock()
+
+	s.recorder.finish(s)
+	s.Span.FinishWithOptions(opts)
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartSpanFromContext(t *testing.T) {
+	// Without a span the global tracer is used
+	span, ctx := StartSpanFromContext(context.Background(), "foo")
+	assert.Equal(t, opentracing.GlobalTracer(), span.Tracer())
+	assert.Equal(t, span, opentracing.SpanFromContext(ctx))
+	span.Finish()
+
+	// With a span its tracer is used
+	recorder := NewRecorder(opentracing.NoopTracer{})
+	root := recorder.StartSpan("root")
+	ctx = opentracing.ContextWithSpan(context.Background(), root)
+
+	span, ctx = StartSpanFromContext(ctx, "child", opentracing.Tag{Key: "a", Value: 1})
+	assert.Equal(t, recorder, span.Tracer())
+	assert.Equal(t, span, opentracing.SpanFromContext(ctx))
+	span.Finish()
+	root.Finish()
+
+	spans := recorder.Spans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "root", spans[0].Operation)
+	require.Len(t, spans[0].Children, 1)
+	assert.Equal(t, "child", spans[0].Children[0].Operation)
+	assert.Equal(t, map[string]interface{}{"a": 1}, spans[0].Children[0].Tags)
+}
+
+func TestRecorder(t *testing.T) {
+	recorder := NewRecorder(opentracing.NoopTracer{})
+	start := time.Unix(100, 0)
+
+	root := recorder.StartSpan("root", opentracing.StartTime(start))
+	root.SetTag("query", "foo")
+
+	child := recorder.StartSpan("child", opentracing.ChildOf(root.Context()), opentracing.StartTime(start))
+	child.SetOperationName("renamed")
+	child.LogKV("event", "fetched")
+	child.FinishWithOptions(opentracing.FinishOptions{FinishTime: start.Add(time.Second)})
+
+	// A child finishing after its parent is still recorded under it
+	late := recorder.StartSpan("late", opentracing.ChildOf(root.Context()), opentracing.StartTime(start))
+	root.FinishWithOptions(opentracing.FinishOptions{FinishTime: start.Add(2 * time.Second)})
+	assert.Len(t, recorder.Spans()[0].Children, 1)
+	late.FinishWithOptions(opentracing.FinishOptions{FinishTime: start.Add(3 * time.Second)})
+
+	spans := recorder.Spans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, &SpanRecord{
+		Operation: "root",
+		Start:     start,
+		Duration:  Duration(2 * time.Second),
+		Tags:      map[string]interface{}{"query": "foo"},
+		Children: []*SpanRecord{
+			{
+				Operation: "renamed",
+				Start:     start,
+				Duration:  Duration(time.Second),
+				Tags:      map[string]interface{}{},
+				Logs:      []string{"event:fetched"},
+			},
+			{
+				Operation: "late",
+				Start:     start,
+				Duration:  Duration(3 * time.Second),
+				Tags:      map[string]interface{}{},
+			},
+		},
+	}, spans[0])
+
+	data, err := json.Marshal(spans[0].Children[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"duration":"1s"`)
+}
+
+func TestRecorderPropagation(t *testing.T) {
+	recorder := NewRecorder(opentracing.NoopTracer{})
+	span := recorder.StartSpan("root")
+	defer span.Finish()
+
+	carrier := opentracing.HTTPHeadersCarrier{}
+	require.NoError(t, recorder.Inject(span.Context(), opentracing.HTTPHeaders, carrier))
+
+	_, err := recorder.Extract(opentracing.HTTPHeaders, carrier)
+	assert.Equal(t, opentracing.ErrSpanContextNotFound, err)
+}
@@ -0,0 +1,221 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/m3db/m3db/src/dbnode/generated/thrift/rpc"
+	"github.com/m3db/m3db/src/dbnode/storage/index"
+	"github.com/m3db/m3db/src/dbnode/topology"
+	xerrors "github.com/m3db/m3x/errors"
+)
+
+type aggregateOp struct {
+	ctx          context.Context
+	request      rpc.AggregateQueryRequest
+	completionFn completionFn
+}
+
+func (a *aggregateOp) Size() int {
+	// Aggregate is always a single op
+	return 1
+}
+
+func (a *aggregateOp) CompletionFn() completionFn {
+	return a.completionFn
+}
+
+type aggregateResultAccumulatorOpts struct {
+	host     topology.Host
+	response *rpc.AggregateQueryResult_
+}
+
+// aggregateHostResult is the response of a single host, the counts of which
+// cover every shard the host owns.
+type aggregateHostResult struct {
+	shards []uint32
+	terms  []*rpc.AggregateQueryResultTerm
+}
+
+type aggregateResultAccumulator struct {
+	// NB: like a fetchTagged request, an aggregate request fans out to each
+	// shard in the topology so the response consistency is tracked per shard.
+	shardConsistencyResults fetchTaggedShardConsistencyResults
+	numHostsPending         int32
+	numShardsPending        int32
+
+	errors     xerrors.Errors
+	responses  []aggregateHostResult
+	exhaustive bool
+
+	majority         int
+	consistencyLevel topology.ReadConsistencyLevel
+	topoMap          topology.Map
+}
+
+func (accum *aggregateResultAccumulator) Reset(
+	topoMap topology.Map,
+	majority int,
+	consistencyLevel topology.ReadConsistencyLevel,
+) {
+	accum.errors = accum.errors[:0]
+	accum.responses = accum.responses[:0]
+	accum.exhaustive = true
+	accum.topoMap = topoMap
+	accum.majority = majority
+	accum.consistencyLevel = consistencyLevel
+	accum.numHostsPending = int32(topoMap.HostsLen())
+	accum.numShardsPending = int32(len(topoMap.ShardSet().All()))
+	accum.shardConsistencyResults = accum.shardConsistencyResults.enqueue(topoMap)
+}
+
+func (accum *aggregateResultAccumulator) Add(
+	opts aggregateResultAccumulatorOpts,
+	resultErr error,
+) (bool, error) {
+	host := opts.host
+	if host == nil {
+		// should never happen, guarding against incompatible changes to the `client` package.
+		doneAccumulating := true
+		err := fmt.Errorf("[invariant violated] nil host in aggregate completionFn")
+		return doneAccumulating, xerrors.NewNonRetryableError(err)
+	}
+
+	hostShardSet, ok := accum.topoMap.LookupHostShardSet(host.ID())
+	if !ok {
+		// should never happen, as we've taken a reference to the
+		// topology when beginning the request, and the var is immutable.
+		doneAccumulating := true
+		err := fmt.Errorf(
+			"[invariant violated] missing host shard in aggregate completionFn: %s", host.ID())
+		return doneAccumulating, xerrors.NewNonRetryableError(err)
+	}
+
+	accum.numHostsPending--
+	if resultErr != nil {
+		accum.errors = append(accum.errors, xerrors.NewRenamedError(resultErr,
+			fmt.Errorf("error aggregating from host %s: %v", host.ID(), resultErr)))
+	} else {
+		accum.exhaustive = accum.exhaustive && opts.response.Exhaustive
+		accum.responses = append(accum.responses, aggregateHostResult{
+			shards: hostShardSet.ShardSet().AllIDs(),
+			terms:  opts.response.Results,
+		})
+	}
+
+	accum.numShardsPending -= accum.shardConsistencyResults.update(
+		hostShardSet, resultErr, accum.consistencyLevel, accum.majority)
+
+	// success case, sufficient responses for each shard
+	if accum.numShardsPending == 0 {
+		doneAccumulating := true
+		return doneAccumulating, nil
+	}
+
+	// failure case - we've received all responses but still weren't able to satisfy
+	// all shards, so we need to fail
+	if accum.numHostsPending == 0 && accum.numShardsPending != 0 {
+		doneAccumulating := true
+		return doneAccumulating, fmt.Errorf(
+			"unable to satisfy consistency requirements for %d shards [ err = %s ]",
+			accum.numShardsPending, accum.errors.Error())
+	}
+
+	doneAccumulating := false
+	return doneAccumulating, nil
+}
+
+// Terms returns the union of the terms of every response, sorted by term.
+func (accum *aggregateResultAccumulator) Terms(
+	limit int,
+) ([]index.AggregateTerm, bool) {
+	var (
+		exhaustive = accum.exhaustive
+		numShards  = len(accum.topoMap.ShardSet().All())
+		cover      = accum.shardCover(numShards)
+		counts     = make(map[string]int64)
+		numCounted int
+	)
+	// NB: each series is indexed on every replica that owns its shard, so a
+	// series is only counted once when the counts are summed over hosts owning
+	// disjoint shards that between them cover every shard. When no such hosts
+	// responded, e.g. during a shard move, the count is scaled down from the
+	// shards counted by every host that responded to the number of shards.
+	for i, response := range accum.responses {
+		if cover != nil && !cover[i] {
+			continue
+		}
+		numCounted += len(response.shards)
+		for _, term := range response.terms {
+			counts[string(term.Term)] += term.Count
+		}
+	}
+
+	terms := make([]index.AggregateTerm, 0, len(counts))
+	for term, count := range counts {
+		if cover == nil && numCounted > 0 {
+			count = (count*int64(numShards) + int64(numCounted) - 1) / int64(numCounted)
+		}
+		terms = append(terms, index.AggregateTerm{Term: []byte(term), Count: count})
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		return bytes.Compare(terms[i].Term, terms[j].Term) < 0
+	})
+	if limit > 0 && len(terms) > limit {
+		terms = terms[:limit]
+		exhaustive = false
+	}
+
+	return terms, exhaustive
+}
+
+// shardCover returns which responses to count so that every shard is counted
+// exactly once, or nil if the responses cannot be picked to do so.
+func (accum *aggregateResultAccumulator) shardCover(numShards int) []bool {
+	var (
+		cover   = make([]bool, len(accum.responses))
+		covered = make(map[uint32]struct{}, numShards)
+	)
+	for i, response := range accum.responses {
+		disjoint := true
+		for _, shard := range response.shards {
+			if _, ok := covered[shard]; ok {
+				disjoint = false
+				break
+			}
+		}
+		if !disjoint {
+			continue
+		}
+		cover[i] = true
+		for _, shard := range response.shards {
+			covered[shard] = struct{}{}
+		}
+	}
+	if len(covered) != numShards {
+		return nil
+	}
+	return cover
+}
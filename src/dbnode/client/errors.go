@@ -54,6 +54,28 @@ func IsBadRequestError(err error) bool {
 	return false
 }
 
+// overloadedErrorMessages are the messages of the internal errors returned by
+// nodes rejecting requests because they are overloaded or because their
+// commit log queue is full
+var overloadedErrorMessages = map[string]struct{}{
+	"server is overloaded":     {},
+	"commit log queue is full": {},
+}
+
+// IsOverloadedError determines if the error is the error of a node rejecting
+// a request because it is overloaded, the request may succeed once retried
+func IsOverloadedError(err error) bool {
+	for err != nil {
+		if e, ok := err.(*rpc.Error); ok && tterrors.IsInternalError(e) {
+			if _, ok := overloadedErrorMessages[e.Message]; ok {
+				return true
+			}
+		}
+		err = xerrors.InnerError(err)
+	}
+	return false
+}
+
 // NumResponded returns how many nodes responded for a given error
 func NumResponded(err error) int {
 	for err != nil {
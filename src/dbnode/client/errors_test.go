@@ -54,3 +54,29 @@ func TestConsistencyResultError(t *testing.T) {
 	assert.Equal(t, 1, NumSuccess(err))
 	assert.Equal(t, 2, NumError(err))
 }
+
+func TestIsOverloadedError(t *testing.T) {
+	overloaded := &rpc.Error{
+		Type:    rpc.ErrorType_INTERNAL_ERROR,
+		Message: "commit log queue is full",
+	}
+
+	err := consistencyResultErr{
+		level:       topology.ConsistencyLevelMajority,
+		enqueued:    3,
+		responded:   3,
+		topLevelErr: overloaded,
+		errs:        []error{overloaded},
+	}
+
+	assert.True(t, IsOverloadedError(err))
+	assert.True(t, IsOverloadedError(&rpc.Error{
+		Type:    rpc.ErrorType_INTERNAL_ERROR,
+		Message: "server is overloaded",
+	}))
+	assert.False(t, IsOverloadedError(&rpc.Error{
+		Type:    rpc.ErrorType_INTERNAL_ERROR,
+		Message: "unknown",
+	}))
+	assert.False(t, IsOverloadedError(fmt.Errorf("server is overloaded")))
+}
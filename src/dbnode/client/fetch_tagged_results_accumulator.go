@@ -109,43 +109,8 @@ func (accum *fetchTaggedResultAccumulator) Add(
 		}
 	}
 
-	// FOLLOWUP(prateek): once we transmit the shards successfully satisfied by a response, the
-	// for loop below needs to be updated to filter the `hostShardSet` to only include those
-	// in the response. More details in https://github.com/m3db/m3db/src/dbnode/issues/550.
-	for _, hs := range hostShardSet.ShardSet().All() {
-		shardID := int(hs.ID())
-		shardResult := accum.shardConsistencyResults[shardID]
-		if shardResult.done {
-			continue // already been marked done, don't need to do anything for this shard
-		}
-
-		if hs.State() != shard.Available {
-			// Currently, we only accept responses from shard's which are available
-			// NB: as a possible enhancement, we could accept a response from
-			// a shard that's not available if we tracked response pairs from
-			// a LEAVING+INITIALIZING shard; this would help during node replaces.
-			shardResult.errors++
-		} else if resultErr == nil {
-			shardResult.success++
-		} else {
-			shardResult.errors++
-		}
-
-		pending := shardResult.pending()
-		if topology.ReadConsistencyTermination(accum.consistencyLevel, int32(accum.majority), pending, int32(shardResult.success)) {
-			shardResult.done = true
-			if topology.ReadConsistencyAchieved(accum.consistencyLevel, accum.majority, int(shardResult.enqueued), int(shardResult.success)) {
-				accum.numShardsPending--
-			}
-			// NB(prateek): if !ReadConsistencyAchieved, we have sufficient information to fail the entire request, because we
-			// will never be able to satisfy the consistency requirement on the current shard. We explicitly chose not to,
-			// instead waiting till all the hosts return a response. This is to reduce the load we would put on the cluster
-			// due to retries.
-		}
-
-		// update value in slice
-		accum.shardConsistencyResults[shardID] = shardResult
-	}
+	accum.numShardsPending -= fetchTaggedShardConsistencyResults(accum.shardConsistencyResults).
+		update(hostShardSet, resultErr, accum.consistencyLevel, accum.majority)
 
 	// success case, sufficient responses for each shard
 	if accum.numShardsPending == 0 {
@@ -199,17 +164,8 @@ func (accum *fetchTaggedResultAccumulator) Reset(
 	accum.numHostsPending = int32(topoMap.HostsLen())
 	accum.numShardsPending = int32(len(topoMap.ShardSet().All()))
 
-	// expand shardResults as much as necessary
-	targetLen := 1 + int(topoMap.ShardSet().Max())
 	accum.shardConsistencyResults = fetchTaggedShardConsistencyResults(
-		accum.shardConsistencyResults).initialize(targetLen)
-	// initialize shardResults based on current topology
-	for _, hss := range topoMap.HostShardSets() {
-		for _, hShard := range hss.ShardSet().All() {
-			id := int(hShard.ID())
-			accum.shardConsistencyResults[id].enqueued++
-		}
-	}
+		accum.shardConsistencyResults).enqueue(topoMap)
 }
 
 func (accum *fetchTaggedResultAccumulator) sliceResponsesAsSeriesIter(
@@ -311,6 +267,71 @@ func (res fetchTaggedShardConsistencyResults) initialize(length int) fetchTagged
 	return res
 }
 
+// enqueue expands the results as much as necessary and initializes them for a
+// request enqueued to every host of the topology
+func (res fetchTaggedShardConsistencyResults) enqueue(topoMap topology.Map) fetchTaggedShardConsistencyResults {
+	targetLen := 1 + int(topoMap.ShardSet().Max())
+	res = res.initialize(targetLen)
+	// initialize shardResults based on current topology
+	for _, hss := range topoMap.HostShardSets() {
+		for _, hShard := range hss.ShardSet().All() {
+			id := int(hShard.ID())
+			res[id].enqueued++
+		}
+	}
+	return res
+}
+
+// update updates the consistency of each shard of the host with its response,
+// returning the number of shards which achieved the consistency level
+func (res fetchTaggedShardConsistencyResults) update(
+	hostShardSet topology.HostShardSet,
+	resultErr error,
+	level topology.ReadConsistencyLevel,
+	majority int,
+) int32 {
+	var achieved int32
+	// FOLLOWUP(prateek): once we transmit the shards successfully satisfied by a response, the
+	// for loop below needs to be updated to filter the `hostShardSet` to only include those
+	// in the response. More details in https://github.com/m3db/m3db/src/dbnode/issues/550.
+	for _, hs := range hostShardSet.ShardSet().All() {
+		shardID := int(hs.ID())
+		shardResult := res[shardID]
+		if shardResult.done {
+			continue // already been marked done, don't need to do anything for this shard
+		}
+
+		if hs.State() != shard.Available {
+			// Currently, we only accept responses from shard's which are available
+			// NB: as a possible enhancement, we could accept a response from
+			// a shard that's not available if we tracked response pairs from
+			// a LEAVING+INITIALIZING shard; this would help during node replaces.
+			shardResult.errors++
+		} else if resultErr == nil {
+			shardResult.success++
+		} else {
+			shardResult.errors++
+		}
+
+		pending := shardResult.pending()
+		if topology.ReadConsistencyTermination(level, int32(majority), pending, int32(shardResult.success)) {
+			shardResult.done = true
+			if topology.ReadConsistencyAchieved(level, majority, int(shardResult.enqueued), int(shardResult.success)) {
+				achieved++
+			}
+			// NB(prateek): if !ReadConsistencyAchieved, we have sufficient information to fail the entire request, because we
+			// will never be able to satisfy the consistency requirement on the current shard. We explicitly chose not to,
+			// instead waiting till all the hosts return a response. This is to reduce the load we would put on the cluster
+			// due to retries.
+		}
+
+		// update value in slice
+		res[shardID] = shardResult
+	}
+
+	return achieved
+}
+
 type fetchTaggedIDResults []*rpc.FetchTaggedIDResult_
 
 // lambda to iterate over fetchTagged responses a single id at a time, `hasMore` indicates
@@ -21,6 +21,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"sync"
@@ -32,6 +33,7 @@ import (
 	"github.com/m3db/m3x/ident"
 	"github.com/m3db/m3x/pool"
 
+	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/uber/tchannel-go/thrift"
 )
 
@@ -237,6 +239,8 @@ func (q *queue) drain() {
 				q.asyncFetchTagged(v)
 			case *truncateOp:
 				q.asyncTruncate(v)
+			case *aggregateOp:
+				q.asyncAggregate(v)
 			default:
 				completionFn := ops[i].CompletionFn()
 				completionFn(nil, errQueueUnknownOperation(q.host.ID()))
@@ -462,6 +466,13 @@ func (q *queue) asyncFetchTagged(op *fetchTaggedOp) {
 			q.Done()
 		}
 
+		// Skip the request if the caller abandoned the fetch while it was queued
+		if err := op.ctx.Err(); err != nil {
+			op.CompletionFn()(fetchTaggedResultAccumulatorOpts{host: q.host}, err)
+			cleanup()
+			return
+		}
+
 		client, err := q.connPool.NextClient()
 		if err != nil {
 			// No client available
@@ -470,8 +481,20 @@ func (q *queue) asyncFetchTagged(op *fetchTaggedOp) {
 			return
 		}
 
-		ctx, _ := thrift.NewContext(q.opts.FetchRequestTimeout())
-		result, err := client.FetchTagged(ctx, &op.request)
+		// Derive the request context from the caller's so that the request is
+		// abandoned as soon as the caller times out or is cancelled
+		ctx, cancel := context.WithTimeout(op.ctx, q.opts.FetchRequestTimeout())
+		span := q.startFetchTaggedSpan(op.ctx)
+		result, err := client.FetchTagged(thrift.Wrap(ctx), &op.request)
+		cancel()
+		if span != nil {
+			if err != nil {
+				span.SetTag("error", err.Error())
+			} else {
+				span.SetTag("series", len(result.Elements))
+			}
+			span.Finish()
+		}
 		if err != nil {
 			op.CompletionFn()(fetchTaggedResultAccumulatorOpts{host: q.host}, err)
 			cleanup()
@@ -486,6 +509,19 @@ func (q *queue) asyncFetchTagged(op *fetchTaggedOp) {
 	}()
 }
 
+// startFetchTaggedSpan starts the span of a fetch from the host when the
+// caller traces its fetches, returning nil otherwise
+func (q *queue) startFetchTaggedSpan(ctx context.Context) opentracing.Span {
+	parent := opentracing.SpanFromContext(ctx)
+	if parent == nil {
+		return nil
+	}
+
+	return parent.Tracer().StartSpan("fetchTagged.host",
+		opentracing.ChildOf(parent.Context()),
+		opentracing.Tag{Key: "host", Value: q.host.ID()})
+}
+
 func (q *queue) asyncTruncate(op *truncateOp) {
 	q.Add(1)
 
@@ -511,6 +547,39 @@ func (q *queue) asyncTruncate(op *truncateOp) {
 	}()
 }
 
+func (q *queue) asyncAggregate(op *aggregateOp) {
+	q.Add(1)
+
+	go func() {
+		cleanup := q.Done
+
+		// Skip the request if the caller abandoned the aggregate while it was queued
+		if err := op.ctx.Err(); err != nil {
+			op.completionFn(aggregateResultAccumulatorOpts{host: q.host}, err)
+			cleanup()
+			return
+		}
+
+		client, err := q.connPool.NextClient()
+		if err != nil {
+			// No client available
+			op.completionFn(aggregateResultAccumulatorOpts{host: q.host}, err)
+			cleanup()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(op.ctx, q.opts.FetchRequestTimeout())
+		res, err := client.AggregateQuery(thrift.Wrap(ctx), &op.request)
+		cancel()
+		op.completionFn(aggregateResultAccumulatorOpts{
+			host:     q.host,
+			response: res,
+		}, err)
+
+		cleanup()
+	}()
+}
+
 func (q *queue) Len() int {
 	q.RLock()
 	v := q.opsSumSize
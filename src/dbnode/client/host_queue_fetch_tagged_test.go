@@ -21,6 +21,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"testing"
@@ -157,9 +158,26 @@ func TestHostQueueFetchTaggedErrorOnFetchTaggedError(t *testing.T) {
 	})
 }
 
+func TestHostQueueFetchTaggedAbandoned(t *testing.T) {
+	namespace := "testNs"
+	expectedResults := []hostQueueResult{
+		hostQueueResult{
+			result: fetchTaggedResultAccumulatorOpts{host: h},
+			err:    context.Canceled,
+		},
+	}
+	opts := &testHostQueueFetchTaggedOptions{
+		cancelled: true,
+	}
+	testHostQueueFetchTagged(t, namespace, nil, expectedResults, opts, func(results []hostQueueResult) {
+		assert.Equal(t, expectedResults, results)
+	})
+}
+
 type testHostQueueFetchTaggedOptions struct {
 	nextClientErr  error
 	fetchTaggedErr error
+	cancelled      bool
 }
 
 func testHostQueueFetchTagged(
@@ -201,7 +219,12 @@ func testHostQueueFetchTagged(
 
 	// Prepare mocks for flush
 	mockClient := rpc.NewMockTChanNode(ctrl)
-	if testOpts != nil && testOpts.nextClientErr != nil {
+	if testOpts != nil && testOpts.cancelled {
+		// The fetch is abandoned before a client is requested
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		fetchTagged.ctx = ctx
+	} else if testOpts != nil && testOpts.nextClientErr != nil {
 		mockConnPool.EXPECT().NextClient().Return(nil, testOpts.nextClientErr)
 	} else if testOpts != nil && testOpts.fetchTaggedErr != nil {
 		fetchTaggedExec := func(ctx thrift.Context, req *rpc.FetchTaggedRequest) {
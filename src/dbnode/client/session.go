@@ -22,6 +22,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"math"
@@ -50,7 +51,7 @@ import (
 	"github.com/m3db/m3db/src/dbnode/x/xpool"
 	"github.com/m3db/m3x/checked"
 	xclose "github.com/m3db/m3x/close"
-	"github.com/m3db/m3x/context"
+	xcontext "github.com/m3db/m3x/context"
 	xerrors "github.com/m3db/m3x/errors"
 	"github.com/m3db/m3x/ident"
 	"github.com/m3db/m3x/instrument"
@@ -1080,21 +1081,42 @@ func (s *session) FetchIDs(
 }
 
 func (s *session) FetchTagged(
-	ns ident.ID, q index.Query, opts index.QueryOptions,
+	ctx context.Context, ns ident.ID, q index.Query, opts index.QueryOptions,
 ) (encoding.SeriesIterators, bool, error) {
 	f := s.pools.fetchTaggedAttempt.Get()
+	f.args.ctx = ctx
 	f.args.ns = ns
 	f.args.query = q
 	f.args.opts = opts
 	err := s.fetchRetrier.Attempt(f.dataAttemptFn)
 	iters, exhaustive := f.dataResultIters, f.dataResultExhaustive
 	s.pools.fetchTaggedAttempt.Put(f)
-	return iters, exhaustive, err
+	return iters, exhaustive, fetchTaggedContextErr(ctx, err)
+}
+
+// fetchTaggedContextErr returns the context error in place of the error of a
+// fetch that was abandoned, so callers can tell timeouts and cancellations
+// apart from failed requests.
+func fetchTaggedContextErr(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+
+	return err
 }
 
 func (s *session) fetchTaggedAttempt(
-	ns ident.ID, q index.Query, opts index.QueryOptions,
+	ctx context.Context, ns ident.ID, q index.Query, opts index.QueryOptions,
 ) (encoding.SeriesIterators, bool, error) {
+	// Do not retry once the caller has abandoned the fetch
+	if err := ctx.Err(); err != nil {
+		return nil, false, xerrors.NewNonRetryableError(err)
+	}
+
 	s.state.RLock()
 	if s.state.status != statusOpen {
 		s.state.RUnlock()
@@ -1102,7 +1124,7 @@ func (s *session) fetchTaggedAttempt(
 	}
 
 	const fetchData = true
-	fetchState, err := s.fetchTaggedAttemptWithRLock(ns, q, opts, fetchData)
+	fetchState, err := s.fetchTaggedAttemptWithRLock(ctx, ns, q, opts, fetchData)
 	s.state.RUnlock()
 
 	if err != nil {
@@ -1126,21 +1148,26 @@ func (s *session) fetchTaggedAttempt(
 }
 
 func (s *session) FetchTaggedIDs(
-	ns ident.ID, q index.Query, opts index.QueryOptions,
+	ctx context.Context, ns ident.ID, q index.Query, opts index.QueryOptions,
 ) (TaggedIDsIterator, bool, error) {
 	f := s.pools.fetchTaggedAttempt.Get()
+	f.args.ctx = ctx
 	f.args.ns = ns
 	f.args.query = q
 	f.args.opts = opts
 	err := s.fetchRetrier.Attempt(f.idsAttemptFn)
 	iter, exhaustive := f.idsResultIter, f.idsResultExhaustive
 	s.pools.fetchTaggedAttempt.Put(f)
-	return iter, exhaustive, err
+	return iter, exhaustive, fetchTaggedContextErr(ctx, err)
 }
 
 func (s *session) fetchTaggedIDsAttempt(
-	ns ident.ID, q index.Query, opts index.QueryOptions,
+	ctx context.Context, ns ident.ID, q index.Query, opts index.QueryOptions,
 ) (TaggedIDsIterator, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, xerrors.NewNonRetryableError(err)
+	}
+
 	s.state.RLock()
 	if s.state.status != statusOpen {
 		s.state.RUnlock()
@@ -1148,7 +1175,7 @@ func (s *session) fetchTaggedIDsAttempt(
 	}
 
 	const fetchData = false
-	fetchState, err := s.fetchTaggedAttemptWithRLock(ns, q, opts, fetchData)
+	fetchState, err := s.fetchTaggedAttemptWithRLock(ctx, ns, q, opts, fetchData)
 	s.state.RUnlock()
 
 	if err != nil {
@@ -1175,6 +1202,7 @@ func (s *session) fetchTaggedIDsAttempt(
 // is transferred to the calling function, and is expected to manage the lifecycle of
 // of the object (including releasing the lock/decRef'ing it).
 func (s *session) fetchTaggedAttemptWithRLock(
+	ctx context.Context,
 	ns ident.ID,
 	q index.Query,
 	opts index.QueryOptions,
@@ -1203,7 +1231,7 @@ func (s *session) fetchTaggedAttemptWithRLock(
 	fetchState.nsID = nsClone // transfer ownership to `fetchState`
 	fetchState.incRef()       // indicate current go-routine has a reference to the fetchState
 	op.incRef()               // indicate current go-routine has a reference to the op
-	op.update(req, fetchState.completionFn)
+	op.update(ctx, req, fetchState.completionFn)
 
 	fetchState.Reset(opts.StartInclusive, opts.EndExclusive, op, topoMap, s.state.majority, s.state.readLevel)
 	fetchState.Lock()
@@ -1633,6 +1661,80 @@ func (s *session) Truncate(namespace ident.ID) (int64, error) {
 	return truncated, resultErr.FinalError()
 }
 
+func (s *session) Aggregate(
+	ctx context.Context, ns ident.ID, q index.Query, opts index.AggregateQueryOptions,
+) ([]index.AggregateTerm, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	req, err := convert.ToRPCAggregateQueryRequest(ns, q, opts)
+	if err != nil {
+		return nil, false, xerrors.NewNonRetryableError(err)
+	}
+
+	var (
+		resultLock sync.Mutex
+		accum      aggregateResultAccumulator
+		done       = make(chan struct{})
+		finished   bool
+		resultErr  error
+		a          = &aggregateOp{ctx: ctx, request: req}
+	)
+	a.completionFn = func(result interface{}, err error) {
+		resultLock.Lock()
+		defer resultLock.Unlock()
+		if finished {
+			// already have sufficient responses, ignore the rest
+			return
+		}
+		var doneAccumulating bool
+		doneAccumulating, resultErr = accum.Add(
+			result.(aggregateResultAccumulatorOpts), err)
+		if doneAccumulating {
+			finished = true
+			close(done)
+		}
+	}
+
+	s.state.RLock()
+	if s.state.status != statusOpen {
+		s.state.RUnlock()
+		return nil, false, errSessionStatusNotOpen
+	}
+	resultLock.Lock()
+	accum.Reset(s.state.topoMap, s.state.majority, s.state.readLevel)
+	resultLock.Unlock()
+	for _, hq := range s.state.queues {
+		if err := hq.Enqueue(a); err != nil {
+			s.state.RUnlock()
+			resultLock.Lock()
+			finished = true
+			resultLock.Unlock()
+
+			// NB: if this happens we have a bug, once we are in the read
+			// lock the current queues should never be closed
+			wrappedErr := fmt.Errorf("[invariant violated] failed to enqueue aggregate: %v", err)
+			s.log.Errorf(wrappedErr.Error())
+			return nil, false, wrappedErr
+		}
+	}
+	s.state.RUnlock()
+
+	// Wait until every shard satisfies the read consistency level, or can no
+	// longer satisfy it
+	<-done
+
+	resultLock.Lock()
+	defer resultLock.Unlock()
+	if resultErr != nil {
+		return nil, false, fetchTaggedContextErr(ctx, resultErr)
+	}
+
+	terms, exhaustive := accum.Terms(opts.Limit)
+	return terms, exhaustive, nil
+}
+
 // NB(r): Excluding maligned struct check here as we can
 // live with a few extra bytes since this struct is only
 // ever passed by stack, its much more readable not optimized
@@ -3125,7 +3227,7 @@ type blocksResult interface {
 type baseBlocksResult struct {
 	blockOpts               block.Options
 	blockAllocSize          int
-	contextPool             context.Pool
+	contextPool             xcontext.Pool
 	encoderPool             encoding.EncoderPool
 	multiReaderIteratorPool encoding.MultiReaderIteratorPool
 }
@@ -0,0 +1,206 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/src/dbnode/generated/thrift/rpc"
+	"github.com/m3db/m3db/src/dbnode/storage/index"
+	"github.com/m3db/m3ninx/idx"
+	"github.com/m3db/m3x/ident"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSessionAggregateQueryOpts(limit int) index.AggregateQueryOptions {
+	now := time.Now()
+	return index.AggregateQueryOptions{
+		QueryOptions: index.QueryOptions{
+			StartInclusive: now.Add(-time.Hour),
+			EndExclusive:   now,
+			Limit:          limit,
+		},
+		FieldName: []byte("city"),
+	}
+}
+
+func TestSessionAggregateNotOpenError(t *testing.T) {
+	s := newDefaultTestSession(t)
+	_, _, err := s.Aggregate(context.Background(), ident.StringID("metrics"),
+		index.Query{idx.NewTermQuery([]byte("a"), []byte("b"))},
+		testSessionAggregateQueryOpts(0))
+	require.Error(t, err)
+	require.Equal(t, errSessionStatusNotOpen, err)
+}
+
+func TestSessionAggregateMergesReplicas(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSessionTestOptions()
+	s, err := newSession(opts)
+	assert.NoError(t, err)
+	session := s.(*session)
+
+	topoWatch, err := opts.TopologyInitializer().Init()
+	require.NoError(t, err)
+	topoMap := topoWatch.Get()
+
+	mockHostQueues(ctrl, session, sessionTestReplicas, []testEnqueueFn{
+		func(idx int, op op) {
+			aggregate, ok := op.(*aggregateOp)
+			require.True(t, ok)
+			assert.Equal(t, []byte("metrics"), aggregate.request.NameSpace)
+			assert.Equal(t, []byte("city"), aggregate.request.TagName)
+
+			aggregate.completionFn(aggregateResultAccumulatorOpts{
+				host: topoMap.Hosts()[idx],
+				response: &rpc.AggregateQueryResult_{
+					Results: []*rpc.AggregateQueryResultTerm{
+						{Term: []byte("sf"), Count: 2},
+						{Term: []byte("nyc"), Count: 1},
+					},
+					Exhaustive: idx != 0,
+				},
+			}, nil)
+		},
+	})
+
+	require.NoError(t, session.Open())
+
+	// NB: every host owns every shard, so the counts of a single host count
+	// each series exactly once
+	terms, exhaustive, err := s.Aggregate(context.Background(), ident.StringID("metrics"),
+		index.Query{idx.NewTermQuery([]byte("a"), []byte("b"))},
+		testSessionAggregateQueryOpts(0))
+	require.NoError(t, err)
+	assert.False(t, exhaustive)
+	assert.Equal(t, []index.AggregateTerm{
+		{Term: []byte("nyc"), Count: 1},
+		{Term: []byte("sf"), Count: 2},
+	}, terms)
+
+	assert.NoError(t, session.Close())
+}
+
+func TestSessionAggregateHostErrorConsistencyAchieved(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSessionTestOptions()
+	s, err := newSession(opts)
+	assert.NoError(t, err)
+	session := s.(*session)
+
+	topoWatch, err := opts.TopologyInitializer().Init()
+	require.NoError(t, err)
+	topoMap := topoWatch.Get()
+
+	mockHostQueues(ctrl, session, sessionTestReplicas, []testEnqueueFn{
+		func(idx int, op op) {
+			aggregate := op.(*aggregateOp)
+			host := topoMap.Hosts()[idx]
+			if idx == 0 {
+				aggregate.completionFn(aggregateResultAccumulatorOpts{host: host},
+					errors.New("an error"))
+				return
+			}
+			aggregate.completionFn(aggregateResultAccumulatorOpts{
+				host: host,
+				response: &rpc.AggregateQueryResult_{
+					Results: []*rpc.AggregateQueryResultTerm{
+						{Term: []byte("sf"), Count: 3},
+					},
+					Exhaustive: true,
+				},
+			}, nil)
+		},
+	})
+
+	require.NoError(t, session.Open())
+
+	terms, exhaustive, err := s.Aggregate(context.Background(), ident.StringID("metrics"),
+		index.Query{idx.NewTermQuery([]byte("a"), []byte("b"))},
+		testSessionAggregateQueryOpts(0))
+	require.NoError(t, err)
+	assert.True(t, exhaustive)
+	assert.Equal(t, []index.AggregateTerm{
+		{Term: []byte("sf"), Count: 3},
+	}, terms)
+
+	assert.NoError(t, session.Close())
+}
+
+func TestSessionAggregateHostErrorConsistencyNotAchieved(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSessionTestOptions()
+	s, err := newSession(opts)
+	assert.NoError(t, err)
+	session := s.(*session)
+
+	topoWatch, err := opts.TopologyInitializer().Init()
+	require.NoError(t, err)
+	topoMap := topoWatch.Get()
+
+	mockHostQueues(ctrl, session, sessionTestReplicas, []testEnqueueFn{
+		func(idx int, op op) {
+			aggregate := op.(*aggregateOp)
+			host := topoMap.Hosts()[idx]
+			if idx != 0 {
+				aggregate.completionFn(aggregateResultAccumulatorOpts{host: host},
+					errors.New("an error"))
+				return
+			}
+			aggregate.completionFn(aggregateResultAccumulatorOpts{
+				host:     host,
+				response: &rpc.AggregateQueryResult_{Exhaustive: true},
+			}, nil)
+		},
+	})
+
+	require.NoError(t, session.Open())
+
+	_, _, err = s.Aggregate(context.Background(), ident.StringID("metrics"),
+		index.Query{idx.NewTermQuery([]byte("a"), []byte("b"))},
+		testSessionAggregateQueryOpts(0))
+	require.Error(t, err)
+
+	assert.NoError(t, session.Close())
+}
+
+func TestSessionAggregateContextCancelled(t *testing.T) {
+	s := newDefaultTestSession(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := s.Aggregate(ctx, ident.StringID("metrics"),
+		index.Query{idx.NewTermQuery([]byte("a"), []byte("b"))},
+		testSessionAggregateQueryOpts(0))
+	require.Equal(t, context.Canceled, err)
+}
@@ -21,6 +21,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
@@ -64,7 +65,7 @@ func TestSessionFetchTaggedUnsupportedQuery(t *testing.T) {
 	assert.NoError(t, session.Open())
 
 	leakPool := injectLeakcheckFetchTaggedAttempPool(session)
-	_, _, err = s.FetchTagged(
+	_, _, err = s.FetchTagged(context.Background(),
 		ident.StringID("namespace"),
 		index.Query{},
 		index.QueryOptions{},
@@ -73,7 +74,7 @@ func TestSessionFetchTaggedUnsupportedQuery(t *testing.T) {
 	assert.True(t, xerrors.IsNonRetryableError(err))
 	leakPool.Check(t)
 
-	_, _, err = s.FetchTaggedIDs(
+	_, _, err = s.FetchTaggedIDs(context.Background(),
 		ident.StringID("namespace"),
 		index.Query{},
 		index.QueryOptions{},
@@ -94,17 +95,45 @@ func TestSessionFetchTaggedNotOpenError(t *testing.T) {
 	assert.NoError(t, err)
 	t0 := time.Now()
 
-	_, _, err = s.FetchTagged(ident.StringID("namespace"),
+	_, _, err = s.FetchTagged(context.Background(), ident.StringID("namespace"),
 		testSessionFetchTaggedQuery, testSessionFetchTaggedQueryOpts(t0, t0))
 	assert.Error(t, err)
 	assert.Equal(t, errSessionStatusNotOpen, err)
 
-	_, _, err = s.FetchTaggedIDs(ident.StringID("namespace"),
+	_, _, err = s.FetchTaggedIDs(context.Background(), ident.StringID("namespace"),
 		testSessionFetchTaggedQuery, testSessionFetchTaggedQueryOpts(t0, t0))
 	assert.Error(t, err)
 	assert.Equal(t, errSessionStatusNotOpen, err)
 }
 
+func TestSessionFetchTaggedContextCancelled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSessionTestOptions()
+	s, err := newSession(opts)
+	assert.NoError(t, err)
+	session := s.(*session)
+
+	// No ops are expected to be enqueued for an abandoned fetch
+	mockHostQueues(ctrl, session, sessionTestReplicas, nil)
+	assert.NoError(t, session.Open())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	t0 := time.Now()
+	_, _, err = s.FetchTagged(ctx, ident.StringID("namespace"),
+		testSessionFetchTaggedQuery, testSessionFetchTaggedQueryOpts(t0, t0))
+	assert.Equal(t, context.Canceled, err)
+
+	_, _, err = s.FetchTaggedIDs(ctx, ident.StringID("namespace"),
+		testSessionFetchTaggedQuery, testSessionFetchTaggedQueryOpts(t0, t0))
+	assert.Equal(t, context.Canceled, err)
+
+	assert.NoError(t, session.Close())
+}
+
 func TestSessionFetchTaggedIDsGuardAgainstInvalidCall(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -127,7 +156,7 @@ func TestSessionFetchTaggedIDsGuardAgainstInvalidCall(t *testing.T) {
 
 	assert.NoError(t, session.Open())
 
-	_, _, err = session.FetchTaggedIDs(ident.StringID("namespace"),
+	_, _, err = session.FetchTaggedIDs(context.Background(), ident.StringID("namespace"),
 		testSessionFetchTaggedQuery, testSessionFetchTaggedQueryOpts(start, end))
 	assert.Error(t, err)
 	assert.True(t, strings.Contains(err.Error(), "[invariant violated]"))
@@ -156,7 +185,7 @@ func TestSessionFetchTaggedIDsGuardAgainstNilHost(t *testing.T) {
 
 	assert.NoError(t, session.Open())
 
-	_, _, err = session.FetchTaggedIDs(ident.StringID("namespace"),
+	_, _, err = session.FetchTaggedIDs(context.Background(), ident.StringID("namespace"),
 		testSessionFetchTaggedQuery, testSessionFetchTaggedQueryOpts(start, end))
 	assert.Error(t, err)
 	assert.True(t, strings.Contains(err.Error(), "[invariant violated]"))
@@ -186,7 +215,7 @@ func TestSessionFetchTaggedIDsGuardAgainstInvalidHost(t *testing.T) {
 
 	assert.NoError(t, session.Open())
 
-	_, _, err = session.FetchTaggedIDs(ident.StringID("namespace"),
+	_, _, err = session.FetchTaggedIDs(context.Background(), ident.StringID("namespace"),
 		testSessionFetchTaggedQuery, testSessionFetchTaggedQueryOpts(start, end))
 	assert.Error(t, err)
 	assert.True(t, strings.Contains(err.Error(), "[invariant violated]"))
@@ -228,7 +257,7 @@ func TestSessionFetchTaggedIDsBadRequestErrorIsNonRetryable(t *testing.T) {
 	leakStatePool := injectLeakcheckFetchStatePool(session)
 	leakOpPool := injectLeakcheckFetchTaggedOpPool(session)
 
-	_, _, err = session.FetchTaggedIDs(ident.StringID("namespace"),
+	_, _, err = session.FetchTaggedIDs(context.Background(), ident.StringID("namespace"),
 		testSessionFetchTaggedQuery, testSessionFetchTaggedQueryOpts(start, end))
 	assert.Error(t, err)
 	assert.NoError(t, session.Close())
@@ -289,7 +318,7 @@ func TestSessionFetchTaggedIDsEnqueueErr(t *testing.T) {
 
 	assert.NoError(t, session.Open())
 
-	_, _, err = session.FetchTaggedIDs(ident.StringID("namespace"),
+	_, _, err = session.FetchTaggedIDs(context.Background(), ident.StringID("namespace"),
 		testSessionFetchTaggedQuery, testSessionFetchTaggedQueryOpts(start, end))
 	assert.Error(t, err)
 	assert.True(t, strings.Contains(err.Error(), "[invariant violated]"))
@@ -378,7 +407,7 @@ func TestSessionFetchTaggedMergeTest(t *testing.T) {
 	leakStatePool := injectLeakcheckFetchStatePool(session)
 	leakOpPool := injectLeakcheckFetchTaggedOpPool(session)
 
-	iters, exhaust, err := session.FetchTagged(ident.StringID("namespace"),
+	iters, exhaust, err := session.FetchTagged(context.Background(), ident.StringID("namespace"),
 		testSessionFetchTaggedQuery, testSessionFetchTaggedQueryOpts(start, end))
 	assert.NoError(t, err)
 	assert.False(t, exhaust)
@@ -513,7 +542,7 @@ func TestSessionFetchTaggedMergeWithRetriesTest(t *testing.T) {
 	// NB: stubbing needs to be done after session.Open
 	leakStatePool := injectLeakcheckFetchStatePool(session)
 	leakOpPool := injectLeakcheckFetchTaggedOpPool(session)
-	iters, exhaust, err := session.FetchTagged(ident.StringID("namespace"),
+	iters, exhaust, err := session.FetchTagged(context.Background(), ident.StringID("namespace"),
 		testSessionFetchTaggedQuery, testSessionFetchTaggedQueryOpts(start, end))
 	assert.NoError(t, err)
 	assert.False(t, exhaust)
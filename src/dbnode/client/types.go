@@ -21,6 +21,7 @@
 package client
 
 import (
+	"context"
 	"time"
 
 	"github.com/m3db/m3db/src/dbnode/clock"
@@ -33,7 +34,7 @@ import (
 	"github.com/m3db/m3db/src/dbnode/storage/index"
 	"github.com/m3db/m3db/src/dbnode/storage/namespace"
 	"github.com/m3db/m3db/src/dbnode/topology"
-	"github.com/m3db/m3x/context"
+	xcontext "github.com/m3db/m3x/context"
 	"github.com/m3db/m3x/ident"
 	"github.com/m3db/m3x/instrument"
 	"github.com/m3db/m3x/pool"
@@ -73,10 +74,18 @@ type Session interface {
 	FetchIDs(namespace ident.ID, ids ident.Iterator, startInclusive, endExclusive time.Time) (encoding.SeriesIterators, error)
 
 	// FetchTagged resolves the provided query to known IDs, and fetches the data for them.
-	FetchTagged(namespace ident.ID, q index.Query, opts index.QueryOptions) (results encoding.SeriesIterators, exhaustive bool, err error)
+	// Requests still in flight when the context is done are abandoned.
+	FetchTagged(ctx context.Context, namespace ident.ID, q index.Query, opts index.QueryOptions) (results encoding.SeriesIterators, exhaustive bool, err error)
 
 	// FetchTaggedIDs resolves the provided query to known IDs.
-	FetchTaggedIDs(namespace ident.ID, q index.Query, opts index.QueryOptions) (iter TaggedIDsIterator, exhaustive bool, err error)
+	// Requests still in flight when the context is done are abandoned.
+	FetchTaggedIDs(ctx context.Context, namespace ident.ID, q index.Query, opts index.QueryOptions) (iter TaggedIDsIterator, exhaustive bool, err error)
+
+	// Aggregate resolves the provided query and returns the distinct tag names, or
+	// the distinct values of opts.FieldName if set, along with the number of series
+	// each term appears in. Requests still in flight when the context is done
+	// are abandoned.
+	Aggregate(ctx context.Context, namespace ident.ID, q index.Query, opts index.AggregateQueryOptions) (terms []index.AggregateTerm, exhaustive bool, err error)
 
 	// ShardID returns the given shard for an ID for callers
 	// to easily discern what shard is failing when operations
@@ -439,10 +448,10 @@ type Options interface {
 	HostQueueOpsFlushInterval() time.Duration
 
 	// SetContextPool sets the contextPool
-	SetContextPool(value context.Pool) Options
+	SetContextPool(value xcontext.Pool) Options
 
 	// ContextPool returns the contextPool
-	ContextPool() context.Pool
+	ContextPool() xcontext.Pool
 
 	// SetIdentifierPool sets the identifier pool
 	SetIdentifierPool(value ident.Pool) Options
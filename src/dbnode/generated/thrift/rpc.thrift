@@ -46,6 +46,7 @@ service Node {
 	QueryResult query(1: QueryRequest req) throws (1: Error err)
 	FetchResult fetch(1: FetchRequest req) throws (1: Error err)
 	FetchTaggedResult fetchTagged(1: FetchTaggedRequest req) throws (1: Error err)
+	AggregateQueryResult aggregateQuery(1: AggregateQueryRequest req) throws (1: Error err)
 	void write(1: WriteRequest req) throws (1: Error err)
 	void writeTagged(1: WriteTaggedRequest req) throws (1: Error err)
 
@@ -158,6 +159,26 @@ struct FetchTaggedIDResult {
 	5: optional Error err
 }
 
+struct AggregateQueryRequest {
+	1: required binary nameSpace
+	2: required binary query
+	3: required i64 rangeStart
+	4: required i64 rangeEnd
+	5: optional binary tagName
+	6: optional i64 limit
+	7: optional TimeType rangeTimeType = TimeType.UNIX_SECONDS
+}
+
+struct AggregateQueryResult {
+	1: required list<AggregateQueryResultTerm> results
+	2: required bool exhaustive
+}
+
+struct AggregateQueryResultTerm {
+	1: required binary term
+	2: required i64 count
+}
+
 struct FetchBlocksRawRequest {
 	1: required binary nameSpace
 	2: required i32 shard
@@ -3288,6 +3288,657 @@ func (p *FetchTaggedIDResult_) String() string {
 	return fmt.Sprintf("FetchTaggedIDResult_(%+v)", *p)
 }
 
+// Attributes:
+//  - NameSpace
+//  - Query
+//  - RangeStart
+//  - RangeEnd
+//  - TagName
+//  - Limit
+//  - RangeTimeType
+type AggregateQueryRequest struct {
+	NameSpace     []byte   `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
+	Query         []byte   `thrift:"query,2,required" db:"query" json:"query"`
+	RangeStart    int64    `thrift:"rangeStart,3,required" db:"rangeStart" json:"rangeStart"`
+	RangeEnd      int64    `thrift:"rangeEnd,4,required" db:"rangeEnd" json:"rangeEnd"`
+	TagName       []byte   `thrift:"tagName,5" db:"tagName" json:"tagName,omitempty"`
+	Limit         *int64   `thrift:"limit,6" db:"limit" json:"limit,omitempty"`
+	RangeTimeType TimeType `thrift:"rangeTimeType,7" db:"rangeTimeType" json:"rangeTimeType,omitempty"`
+}
+
+func NewAggregateQueryRequest() *AggregateQueryRequest {
+	return &AggregateQueryRequest{
+		RangeTimeType: 0,
+	}
+}
+
+func (p *AggregateQueryRequest) GetNameSpace() []byte {
+	return p.NameSpace
+}
+
+func (p *AggregateQueryRequest) GetQuery() []byte {
+	return p.Query
+}
+
+func (p *AggregateQueryRequest) GetRangeStart() int64 {
+	return p.RangeStart
+}
+
+func (p *AggregateQueryRequest) GetRangeEnd() int64 {
+	return p.RangeEnd
+}
+
+var AggregateQueryRequest_TagName_DEFAULT []byte
+
+func (p *AggregateQueryRequest) GetTagName() []byte {
+	return p.TagName
+}
+
+var AggregateQueryRequest_Limit_DEFAULT int64
+
+func (p *AggregateQueryRequest) GetLimit() int64 {
+	if !p.IsSetLimit() {
+		return AggregateQueryRequest_Limit_DEFAULT
+	}
+	return *p.Limit
+}
+
+var AggregateQueryRequest_RangeTimeType_DEFAULT TimeType = 0
+
+func (p *AggregateQueryRequest) GetRangeTimeType() TimeType {
+	return p.RangeTimeType
+}
+func (p *AggregateQueryRequest) IsSetTagName() bool {
+	return p.TagName != nil
+}
+
+func (p *AggregateQueryRequest) IsSetLimit() bool {
+	return p.Limit != nil
+}
+
+func (p *AggregateQueryRequest) IsSetRangeTimeType() bool {
+	return p.RangeTimeType != AggregateQueryRequest_RangeTimeType_DEFAULT
+}
+
+func (p *AggregateQueryRequest) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	var issetNameSpace bool = false
+	var issetQuery bool = false
+	var issetRangeStart bool = false
+	var issetRangeEnd bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetNameSpace = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+			issetQuery = true
+		case 3:
+			if err := p.ReadField3(iprot); err != nil {
+				return err
+			}
+			issetRangeStart = true
+		case 4:
+			if err := p.ReadField4(iprot); err != nil {
+				return err
+			}
+			issetRangeEnd = true
+		case 5:
+			if err := p.ReadField5(iprot); err != nil {
+				return err
+			}
+		case 6:
+			if err := p.ReadField6(iprot); err != nil {
+				return err
+			}
+		case 7:
+			if err := p.ReadField7(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	if !issetNameSpace {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NameSpace is not set"))
+	}
+	if !issetQuery {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Query is not set"))
+	}
+	if !issetRangeStart {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field RangeStart is not set"))
+	}
+	if !issetRangeEnd {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field RangeEnd is not set"))
+	}
+	return nil
+}
+
+func (p *AggregateQueryRequest) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.NameSpace = v
+	}
+	return nil
+}
+
+func (p *AggregateQueryRequest) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.Query = v
+	}
+	return nil
+}
+
+func (p *AggregateQueryRequest) ReadField3(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 3: ", err)
+	} else {
+		p.RangeStart = v
+	}
+	return nil
+}
+
+func (p *AggregateQueryRequest) ReadField4(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 4: ", err)
+	} else {
+		p.RangeEnd = v
+	}
+	return nil
+}
+
+func (p *AggregateQueryRequest) ReadField5(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
+		return thrift.PrependError("error reading field 5: ", err)
+	} else {
+		p.TagName = v
+	}
+	return nil
+}
+
+func (p *AggregateQueryRequest) ReadField6(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 6: ", err)
+	} else {
+		p.Limit = &v
+	}
+	return nil
+}
+
+func (p *AggregateQueryRequest) ReadField7(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
+		return thrift.PrependError("error reading field 7: ", err)
+	} else {
+		temp := TimeType(v)
+		p.RangeTimeType = temp
+	}
+	return nil
+}
+
+func (p *AggregateQueryRequest) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("AggregateQueryRequest"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField2(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField3(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField4(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField5(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField6(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField7(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *AggregateQueryRequest) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("nameSpace", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:nameSpace: ", p), err)
+	}
+	if err := oprot.WriteBinary(p.NameSpace); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.nameSpace (1) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:nameSpace: ", p), err)
+	}
+	return err
+}
+
+func (p *AggregateQueryRequest) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("query", thrift.STRING, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:query: ", p), err)
+	}
+	if err := oprot.WriteBinary(p.Query); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.query (2) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:query: ", p), err)
+	}
+	return err
+}
+
+func (p *AggregateQueryRequest) writeField3(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("rangeStart", thrift.I64, 3); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:rangeStart: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.RangeStart)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.rangeStart (3) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:rangeStart: ", p), err)
+	}
+	return err
+}
+
+func (p *AggregateQueryRequest) writeField4(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("rangeEnd", thrift.I64, 4); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:rangeEnd: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.RangeEnd)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.rangeEnd (4) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 4:rangeEnd: ", p), err)
+	}
+	return err
+}
+
+func (p *AggregateQueryRequest) writeField5(oprot thrift.TProtocol) (err error) {
+	if p.IsSetTagName() {
+		if err := oprot.WriteFieldBegin("tagName", thrift.STRING, 5); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:tagName: ", p), err)
+		}
+		if err := oprot.WriteBinary(p.TagName); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.tagName (5) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 5:tagName: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *AggregateQueryRequest) writeField6(oprot thrift.TProtocol) (err error) {
+	if p.IsSetLimit() {
+		if err := oprot.WriteFieldBegin("limit", thrift.I64, 6); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 6:limit: ", p), err)
+		}
+		if err := oprot.WriteI64(int64(*p.Limit)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.limit (6) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 6:limit: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *AggregateQueryRequest) writeField7(oprot thrift.TProtocol) (err error) {
+	if p.IsSetRangeTimeType() {
+		if err := oprot.WriteFieldBegin("rangeTimeType", thrift.I32, 7); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 7:rangeTimeType: ", p), err)
+		}
+		if err := oprot.WriteI32(int32(p.RangeTimeType)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.rangeTimeType (7) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 7:rangeTimeType: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *AggregateQueryRequest) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("AggregateQueryRequest(%+v)", *p)
+}
+
+// Attributes:
+//  - Results
+//  - Exhaustive
+type AggregateQueryResult_ struct {
+	Results    []*AggregateQueryResultTerm `thrift:"results,1,required" db:"results" json:"results"`
+	Exhaustive bool                        `thrift:"exhaustive,2,required" db:"exhaustive" json:"exhaustive"`
+}
+
+func NewAggregateQueryResult_() *AggregateQueryResult_ {
+	return &AggregateQueryResult_{}
+}
+
+func (p *AggregateQueryResult_) GetResults() []*AggregateQueryResultTerm {
+	return p.Results
+}
+
+func (p *AggregateQueryResult_) GetExhaustive() bool {
+	return p.Exhaustive
+}
+func (p *AggregateQueryResult_) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	var issetResults bool = false
+	var issetExhaustive bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetResults = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+			issetExhaustive = true
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	if !issetResults {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Results is not set"))
+	}
+	if !issetExhaustive {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Exhaustive is not set"))
+	}
+	return nil
+}
+
+func (p *AggregateQueryResult_) ReadField1(iprot thrift.TProtocol) error {
+	_, size, err := iprot.ReadListBegin()
+	if err != nil {
+		return thrift.PrependError("error reading list begin: ", err)
+	}
+	tSlice := make([]*AggregateQueryResultTerm, 0, size)
+	p.Results = tSlice
+	for i := 0; i < size; i++ {
+		_elem7 := &AggregateQueryResultTerm{}
+		if err := _elem7.Read(iprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem7), err)
+		}
+		p.Results = append(p.Results, _elem7)
+	}
+	if err := iprot.ReadListEnd(); err != nil {
+		return thrift.PrependError("error reading list end: ", err)
+	}
+	return nil
+}
+
+func (p *AggregateQueryResult_) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.Exhaustive = v
+	}
+	return nil
+}
+
+func (p *AggregateQueryResult_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("AggregateQueryResult"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField2(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *AggregateQueryResult_) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("results", thrift.LIST, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:results: ", p), err)
+	}
+	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Results)); err != nil {
+		return thrift.PrependError("error writing list begin: ", err)
+	}
+	for _, v := range p.Results {
+		if err := v.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
+		}
+	}
+	if err := oprot.WriteListEnd(); err != nil {
+		return thrift.PrependError("error writing list end: ", err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:results: ", p), err)
+	}
+	return err
+}
+
+func (p *AggregateQueryResult_) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("exhaustive", thrift.BOOL, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:exhaustive: ", p), err)
+	}
+	if err := oprot.WriteBool(bool(p.Exhaustive)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.exhaustive (2) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:exhaustive: ", p), err)
+	}
+	return err
+}
+
+func (p *AggregateQueryResult_) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("AggregateQueryResult_(%+v)", *p)
+}
+
+// Attributes:
+//  - Term
+//  - Count
+type AggregateQueryResultTerm struct {
+	Term  []byte `thrift:"term,1,required" db:"term" json:"term"`
+	Count int64  `thrift:"count,2,required" db:"count" json:"count"`
+}
+
+func NewAggregateQueryResultTerm() *AggregateQueryResultTerm {
+	return &AggregateQueryResultTerm{}
+}
+
+func (p *AggregateQueryResultTerm) GetTerm() []byte {
+	return p.Term
+}
+
+func (p *AggregateQueryResultTerm) GetCount() int64 {
+	return p.Count
+}
+func (p *AggregateQueryResultTerm) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	var issetTerm bool = false
+	var issetCount bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetTerm = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+			issetCount = true
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	if !issetTerm {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Term is not set"))
+	}
+	if !issetCount {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Count is not set"))
+	}
+	return nil
+}
+
+func (p *AggregateQueryResultTerm) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.Term = v
+	}
+	return nil
+}
+
+func (p *AggregateQueryResultTerm) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.Count = v
+	}
+	return nil
+}
+
+func (p *AggregateQueryResultTerm) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("AggregateQueryResultTerm"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField2(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *AggregateQueryResultTerm) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("term", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:term: ", p), err)
+	}
+	if err := oprot.WriteBinary(p.Term); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.term (1) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:term: ", p), err)
+	}
+	return err
+}
+
+func (p *AggregateQueryResultTerm) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("count", thrift.I64, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:count: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.Count)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.count (2) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:count: ", p), err)
+	}
+	return err
+}
+
+func (p *AggregateQueryResultTerm) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("AggregateQueryResultTerm(%+v)", *p)
+}
+
 // Attributes:
 //  - NameSpace
 //  - Shard
@@ -10490,6 +11141,9 @@ type Node interface {
 	FetchTagged(req *FetchTaggedRequest) (r *FetchTaggedResult_, err error)
 	// Parameters:
 	//  - Req
+	AggregateQuery(req *AggregateQueryRequest) (r *AggregateQueryResult_, err error)
+	// Parameters:
+	//  - Req
 	Write(req *WriteRequest) (err error)
 	// Parameters:
 	//  - Req
@@ -10804,6 +11458,87 @@ func (p *NodeClient) recvFetchTagged() (value *FetchTaggedResult_, err error) {
 	return
 }
 
+// Parameters:
+//  - Req
+func (p *NodeClient) AggregateQuery(req *AggregateQueryRequest) (r *AggregateQueryResult_, err error) {
+	if err = p.sendAggregateQuery(req); err != nil {
+		return
+	}
+	return p.recvAggregateQuery()
+}
+
+func (p *NodeClient) sendAggregateQuery(req *AggregateQueryRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("aggregateQuery", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeAggregateQueryArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvAggregateQuery() (value *AggregateQueryResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "aggregateQuery" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "aggregateQuery failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "aggregateQuery failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error27 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error28 error
+		error28, err = error27.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error28
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "aggregateQuery failed: invalid message type")
+		return
+	}
+	result := NodeAggregateQueryResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
 // Parameters:
 //  - Req
 func (p *NodeClient) Write(req *WriteRequest) (err error) {
@@ -12338,6 +13073,7 @@ func NewNodeProcessor(handler Node) *NodeProcessor {
 	self67.processorMap["query"] = &nodeProcessorQuery{handler: handler}
 	self67.processorMap["fetch"] = &nodeProcessorFetch{handler: handler}
 	self67.processorMap["fetchTagged"] = &nodeProcessorFetchTagged{handler: handler}
+	self67.processorMap["aggregateQuery"] = &nodeProcessorAggregateQuery{handler: handler}
 	self67.processorMap["write"] = &nodeProcessorWrite{handler: handler}
 	self67.processorMap["writeTagged"] = &nodeProcessorWriteTagged{handler: handler}
 	self67.processorMap["fetchBatchRaw"] = &nodeProcessorFetchBatchRaw{handler: handler}
@@ -12399,13 +13135,66 @@ func (p *nodeProcessorQuery) Process(seqId int32, iprot, oprot thrift.TProtocol)
 	result := NodeQueryResult{}
 	var retval *QueryResult_
 	var err2 error
-	if retval, err2 = p.handler.Query(args.Req); err2 != nil {
+	if retval, err2 = p.handler.Query(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing query: "+err2.Error())
+			oprot.WriteMessageBegin("query", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	} else {
+		result.Success = retval
+	}
+	if err2 = oprot.WriteMessageBegin("query", thrift.REPLY, seqId); err2 != nil {
+		err = err2
+	}
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
+		return
+	}
+	return true, err
+}
+
+type nodeProcessorFetch struct {
+	handler Node
+}
+
+func (p *nodeProcessorFetch) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeFetchArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("fetch", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
+	}
+
+	iprot.ReadMessageEnd()
+	result := NodeFetchResult{}
+	var retval *FetchResult_
+	var err2 error
+	if retval, err2 = p.handler.Fetch(args.Req); err2 != nil {
 		switch v := err2.(type) {
 		case *Error:
 			result.Err = v
 		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing query: "+err2.Error())
-			oprot.WriteMessageBegin("query", thrift.EXCEPTION, seqId)
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetch: "+err2.Error())
+			oprot.WriteMessageBegin("fetch", thrift.EXCEPTION, seqId)
 			x.Write(oprot)
 			oprot.WriteMessageEnd()
 			oprot.Flush()
@@ -12414,7 +13203,7 @@ func (p *nodeProcessorQuery) Process(seqId int32, iprot, oprot thrift.TProtocol)
 	} else {
 		result.Success = retval
 	}
-	if err2 = oprot.WriteMessageBegin("query", thrift.REPLY, seqId); err2 != nil {
+	if err2 = oprot.WriteMessageBegin("fetch", thrift.REPLY, seqId); err2 != nil {
 		err = err2
 	}
 	if err2 = result.Write(oprot); err == nil && err2 != nil {
@@ -12432,16 +13221,16 @@ func (p *nodeProcessorQuery) Process(seqId int32, iprot, oprot thrift.TProtocol)
 	return true, err
 }
 
-type nodeProcessorFetch struct {
+type nodeProcessorFetchTagged struct {
 	handler Node
 }
 
-func (p *nodeProcessorFetch) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeFetchArgs{}
+func (p *nodeProcessorFetchTagged) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeFetchTaggedArgs{}
 	if err = args.Read(iprot); err != nil {
 		iprot.ReadMessageEnd()
 		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("fetch", thrift.EXCEPTION, seqId)
+		oprot.WriteMessageBegin("fetchTagged", thrift.EXCEPTION, seqId)
 		x.Write(oprot)
 		oprot.WriteMessageEnd()
 		oprot.Flush()
@@ -12449,16 +13238,16 @@ func (p *nodeProcessorFetch) Process(seqId int32, iprot, oprot thrift.TProtocol)
 	}
 
 	iprot.ReadMessageEnd()
-	result := NodeFetchResult{}
-	var retval *FetchResult_
+	result := NodeFetchTaggedResult{}
+	var retval *FetchTaggedResult_
 	var err2 error
-	if retval, err2 = p.handler.Fetch(args.Req); err2 != nil {
+	if retval, err2 = p.handler.FetchTagged(args.Req); err2 != nil {
 		switch v := err2.(type) {
 		case *Error:
 			result.Err = v
 		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetch: "+err2.Error())
-			oprot.WriteMessageBegin("fetch", thrift.EXCEPTION, seqId)
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetchTagged: "+err2.Error())
+			oprot.WriteMessageBegin("fetchTagged", thrift.EXCEPTION, seqId)
 			x.Write(oprot)
 			oprot.WriteMessageEnd()
 			oprot.Flush()
@@ -12467,7 +13256,7 @@ func (p *nodeProcessorFetch) Process(seqId int32, iprot, oprot thrift.TProtocol)
 	} else {
 		result.Success = retval
 	}
-	if err2 = oprot.WriteMessageBegin("fetch", thrift.REPLY, seqId); err2 != nil {
+	if err2 = oprot.WriteMessageBegin("fetchTagged", thrift.REPLY, seqId); err2 != nil {
 		err = err2
 	}
 	if err2 = result.Write(oprot); err == nil && err2 != nil {
@@ -12485,16 +13274,16 @@ func (p *nodeProcessorFetch) Process(seqId int32, iprot, oprot thrift.TProtocol)
 	return true, err
 }
 
-type nodeProcessorFetchTagged struct {
+type nodeProcessorAggregateQuery struct {
 	handler Node
 }
 
-func (p *nodeProcessorFetchTagged) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeFetchTaggedArgs{}
+func (p *nodeProcessorAggregateQuery) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeAggregateQueryArgs{}
 	if err = args.Read(iprot); err != nil {
 		iprot.ReadMessageEnd()
 		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("fetchTagged", thrift.EXCEPTION, seqId)
+		oprot.WriteMessageBegin("aggregateQuery", thrift.EXCEPTION, seqId)
 		x.Write(oprot)
 		oprot.WriteMessageEnd()
 		oprot.Flush()
@@ -12502,16 +13291,16 @@ func (p *nodeProcessorFetchTagged) Process(seqId int32, iprot, oprot thrift.TPro
 	}
 
 	iprot.ReadMessageEnd()
-	result := NodeFetchTaggedResult{}
-	var retval *FetchTaggedResult_
+	result := NodeAggregateQueryResult{}
+	var retval *AggregateQueryResult_
 	var err2 error
-	if retval, err2 = p.handler.FetchTagged(args.Req); err2 != nil {
+	if retval, err2 = p.handler.AggregateQuery(args.Req); err2 != nil {
 		switch v := err2.(type) {
 		case *Error:
 			result.Err = v
 		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetchTagged: "+err2.Error())
-			oprot.WriteMessageBegin("fetchTagged", thrift.EXCEPTION, seqId)
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing aggregateQuery: "+err2.Error())
+			oprot.WriteMessageBegin("aggregateQuery", thrift.EXCEPTION, seqId)
 			x.Write(oprot)
 			oprot.WriteMessageEnd()
 			oprot.Flush()
@@ -12520,7 +13309,7 @@ func (p *nodeProcessorFetchTagged) Process(seqId int32, iprot, oprot thrift.TPro
 	} else {
 		result.Success = retval
 	}
-	if err2 = oprot.WriteMessageBegin("fetchTagged", thrift.REPLY, seqId); err2 != nil {
+	if err2 = oprot.WriteMessageBegin("aggregateQuery", thrift.REPLY, seqId); err2 != nil {
 		err = err2
 	}
 	if err2 = result.Write(oprot); err == nil && err2 != nil {
@@ -14295,6 +15084,259 @@ func (p *NodeFetchTaggedResult) String() string {
 	return fmt.Sprintf("NodeFetchTaggedResult(%+v)", *p)
 }
 
+// Attributes:
+//  - Req
+type NodeAggregateQueryArgs struct {
+	Req *AggregateQueryRequest `thrift:"req,1" db:"req" json:"req"`
+}
+
+func NewNodeAggregateQueryArgs() *NodeAggregateQueryArgs {
+	return &NodeAggregateQueryArgs{}
+}
+
+var NodeAggregateQueryArgs_Req_DEFAULT *AggregateQueryRequest
+
+func (p *NodeAggregateQueryArgs) GetReq() *AggregateQueryRequest {
+	if !p.IsSetReq() {
+		return NodeAggregateQueryArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *NodeAggregateQueryArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *NodeAggregateQueryArgs) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	return nil
+}
+
+func (p *NodeAggregateQueryArgs) ReadField1(iprot thrift.TProtocol) error {
+	p.Req = &AggregateQueryRequest{
+		RangeTimeType: 0,
+	}
+	if err := p.Req.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
+	}
+	return nil
+}
+
+func (p *NodeAggregateQueryArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("aggregateQuery_args"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *NodeAggregateQueryArgs) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
+	}
+	if err := p.Req.Write(oprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Req), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:req: ", p), err)
+	}
+	return err
+}
+
+func (p *NodeAggregateQueryArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodeAggregateQueryArgs(%+v)", *p)
+}
+
+// Attributes:
+//  - Success
+//  - Err
+type NodeAggregateQueryResult struct {
+	Success *AggregateQueryResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
+	Err     *Error              `thrift:"err,1" db:"err" json:"err,omitempty"`
+}
+
+func NewNodeAggregateQueryResult() *NodeAggregateQueryResult {
+	return &NodeAggregateQueryResult{}
+}
+
+var NodeAggregateQueryResult_Success_DEFAULT *AggregateQueryResult_
+
+func (p *NodeAggregateQueryResult) GetSuccess() *AggregateQueryResult_ {
+	if !p.IsSetSuccess() {
+		return NodeAggregateQueryResult_Success_DEFAULT
+	}
+	return p.Success
+}
+
+var NodeAggregateQueryResult_Err_DEFAULT *Error
+
+func (p *NodeAggregateQueryResult) GetErr() *Error {
+	if !p.IsSetErr() {
+		return NodeAggregateQueryResult_Err_DEFAULT
+	}
+	return p.Err
+}
+func (p *NodeAggregateQueryResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *NodeAggregateQueryResult) IsSetErr() bool {
+	return p.Err != nil
+}
+
+func (p *NodeAggregateQueryResult) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 0:
+			if err := p.ReadField0(iprot); err != nil {
+				return err
+			}
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	return nil
+}
+
+func (p *NodeAggregateQueryResult) ReadField0(iprot thrift.TProtocol) error {
+	p.Success = &AggregateQueryResult_{}
+	if err := p.Success.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
+	}
+	return nil
+}
+
+func (p *NodeAggregateQueryResult) ReadField1(iprot thrift.TProtocol) error {
+	p.Err = &Error{
+		Type: 0,
+	}
+	if err := p.Err.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Err), err)
+	}
+	return nil
+}
+
+func (p *NodeAggregateQueryResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("aggregateQuery_result"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField0(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *NodeAggregateQueryResult) writeField0(oprot thrift.TProtocol) (err error) {
+	if p.IsSetSuccess() {
+		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
+		}
+		if err := p.Success.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Success), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 0:success: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *NodeAggregateQueryResult) writeField1(oprot thrift.TProtocol) (err error) {
+	if p.IsSetErr() {
+		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
+		}
+		if err := p.Err.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Err), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 1:err: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *NodeAggregateQueryResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodeAggregateQueryResult(%+v)", *p)
+}
+
 // Attributes:
 //  - Req
 type NodeWriteArgs struct {
@@ -45,6 +45,7 @@ type TChanCluster interface {
 
 // TChanNode is the interface that defines the server handler and client interface.
 type TChanNode interface {
+	AggregateQuery(ctx thrift.Context, req *AggregateQueryRequest) (*AggregateQueryResult_, error)
 	Fetch(ctx thrift.Context, req *FetchRequest) (*FetchResult_, error)
 	FetchBatchRaw(ctx thrift.Context, req *FetchBatchRawRequest) (*FetchBatchRawResult_, error)
 	FetchBlocksMetadataRaw(ctx thrift.Context, req *FetchBlocksMetadataRawRequest) (*FetchBlocksMetadataRawResult_, error)
@@ -473,6 +474,24 @@ func NewTChanNodeClient(client thrift.TChanClient) TChanNode {
 	return NewTChanNodeInheritedClient("Node", client)
 }
 
+func (c *tchanNodeClient) AggregateQuery(ctx thrift.Context, req *AggregateQueryRequest) (*AggregateQueryResult_, error) {
+	var resp NodeAggregateQueryResult
+	args := NodeAggregateQueryArgs{
+		Req: req,
+	}
+	success, err := c.client.Call(ctx, c.thriftService, "aggregateQuery", &args, &resp)
+	if err == nil && !success {
+		switch {
+		case resp.Err != nil:
+			err = resp.Err
+		default:
+			err = fmt.Errorf("received no result or unknown exception for aggregateQuery")
+		}
+	}
+
+	return resp.GetSuccess(), err
+}
+
 func (c *tchanNodeClient) Fetch(ctx thrift.Context, req *FetchRequest) (*FetchResult_, error) {
 	var resp NodeFetchResult
 	args := NodeFetchArgs{
@@ -875,6 +894,7 @@ func (s *tchanNodeServer) Service() string {
 
 func (s *tchanNodeServer) Methods() []string {
 	return []string{
+		"aggregateQuery",
 		"fetch",
 		"fetchBatchRaw",
 		"fetchBlocksMetadataRaw",
@@ -902,6 +922,8 @@ func (s *tchanNodeServer) Methods() []string {
 
 func (s *tchanNodeServer) Handle(ctx thrift.Context, methodName string, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	switch methodName {
+	case "aggregateQuery":
+		return s.handleAggregateQuery(ctx, protocol)
 	case "fetch":
 		return s.handleFetch(ctx, protocol)
 	case "fetchBatchRaw":
@@ -952,6 +974,34 @@ func (s *tchanNodeServer) Handle(ctx thrift.Context, methodName string, protocol
 	}
 }
 
+func (s *tchanNodeServer) handleAggregateQuery(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
+	var req NodeAggregateQueryArgs
+	var res NodeAggregateQueryResult
+
+	if err := req.Read(protocol); err != nil {
+		return false, nil, err
+	}
+
+	r, err :=
+		s.handler.AggregateQuery(ctx, req.Req)
+
+	if err != nil {
+		switch v := err.(type) {
+		case *Error:
+			if v == nil {
+				return false, nil, fmt.Errorf("Handler for err returned non-nil error type *Error but nil value")
+			}
+			res.Err = v
+		default:
+			return false, nil, err
+		}
+	} else {
+		res.Success = r
+	}
+
+	return err == nil, &res, nil
+}
+
 func (s *tchanNodeServer) handleFetch(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req NodeFetchArgs
 	var res NodeFetchResult
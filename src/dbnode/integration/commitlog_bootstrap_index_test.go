@@ -23,6 +23,7 @@
 package integration
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -177,7 +178,7 @@ func TestCommitLogIndexBootstrap(t *testing.T) {
 	// Match all new_*r*
 	regexpQuery, err := idx.NewRegexpQuery([]byte("city"), []byte("^new_.*r.*$"))
 	require.NoError(t, err)
-	iter, exhausitive, err := session.FetchTaggedIDs(ns1.ID(),
+	iter, exhausitive, err := session.FetchTaggedIDs(context.Background(), ns1.ID(),
 		index.Query{regexpQuery}, queryOpts)
 	require.NoError(t, err)
 	defer iter.Finalize()
@@ -191,7 +192,7 @@ func TestCommitLogIndexBootstrap(t *testing.T) {
 	// Match all *e*e*
 	regexpQuery, err = idx.NewRegexpQuery([]byte("city"), []byte("^.*e.*e.*$"))
 	require.NoError(t, err)
-	iter, exhausitive, err = session.FetchTaggedIDs(ns1.ID(),
+	iter, exhausitive, err = session.FetchTaggedIDs(context.Background(), ns1.ID(),
 		index.Query{regexpQuery}, queryOpts)
 	require.NoError(t, err)
 	defer iter.Finalize()
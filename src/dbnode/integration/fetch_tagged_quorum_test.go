@@ -23,6 +23,7 @@
 package integration
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -34,7 +35,7 @@ import (
 	"github.com/m3db/m3db/src/dbnode/storage/namespace"
 	"github.com/m3db/m3db/src/dbnode/topology"
 	"github.com/m3db/m3ninx/idx"
-	"github.com/m3db/m3x/context"
+	xcontext "github.com/m3db/m3x/context"
 	"github.com/m3db/m3x/ident"
 	xtime "github.com/m3db/m3x/time"
 
@@ -274,7 +275,7 @@ func makeTestFetchTagged(
 		require.NoError(t, err)
 
 		startTime := nodes[0].getNowFn()
-		return s.FetchTagged(testNamespaces[0],
+		return s.FetchTagged(context.Background(), testNamespaces[0],
 			index.Query{q},
 			index.QueryOptions{
 				StartInclusive: startTime.Add(-time.Minute),
@@ -290,7 +291,7 @@ func startAndWriteTagged(
 	t *testing.T,
 	nodes ...*testSetup,
 ) {
-	ctx := context.NewContext()
+	ctx := xcontext.NewContext()
 	defer ctx.BlockingClose()
 	for _, n := range nodes {
 		require.NoError(t, n.startServer())
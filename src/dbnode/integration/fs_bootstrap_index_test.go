@@ -23,6 +23,7 @@
 package integration
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -163,7 +164,7 @@ func TestFilesystemBootstrapIndexWithIndexingEnabled(t *testing.T) {
 	// Match all new_*r*
 	regexpQuery, err := idx.NewRegexpQuery([]byte("city"), []byte("^new_.*r.*$"))
 	require.NoError(t, err)
-	iter, exhausitive, err := session.FetchTaggedIDs(ns1.ID(),
+	iter, exhausitive, err := session.FetchTaggedIDs(context.Background(), ns1.ID(),
 		index.Query{regexpQuery}, queryOpts)
 	require.NoError(t, err)
 	defer iter.Finalize()
@@ -177,7 +178,7 @@ func TestFilesystemBootstrapIndexWithIndexingEnabled(t *testing.T) {
 	// Match all *e*e*
 	regexpQuery, err = idx.NewRegexpQuery([]byte("city"), []byte("^.*e.*e.*$"))
 	require.NoError(t, err)
-	iter, exhausitive, err = session.FetchTaggedIDs(ns1.ID(),
+	iter, exhausitive, err = session.FetchTaggedIDs(context.Background(), ns1.ID(),
 		index.Query{regexpQuery}, queryOpts)
 	require.NoError(t, err)
 	defer iter.Finalize()
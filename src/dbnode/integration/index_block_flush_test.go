@@ -23,6 +23,7 @@
 package integration
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -131,7 +132,7 @@ func TestIndexBlockFlush(t *testing.T) {
 
 	// ensure all data is present
 	log.Infof("querying period0 results")
-	period0Results, _, err := session.FetchTagged(
+	period0Results, _, err := session.FetchTagged(context.Background(),
 		md.ID(), query, index.QueryOptions{StartInclusive: t0, EndExclusive: t1})
 	require.NoError(t, err)
 	writesPeriod0.matchesSeriesIters(t, period0Results)
@@ -162,7 +163,7 @@ func TestIndexBlockFlush(t *testing.T) {
 
 	// ensure all data is still present
 	log.Infof("querying period0 results after flush")
-	period0Results, _, err = session.FetchTagged(
+	period0Results, _, err = session.FetchTagged(context.Background(),
 		md.ID(), query, index.QueryOptions{StartInclusive: t0, EndExclusive: t1})
 	require.NoError(t, err)
 	writesPeriod0.matchesSeriesIters(t, period0Results)
@@ -23,6 +23,7 @@
 package integration
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -118,7 +119,7 @@ func TestIndexBlockRotation(t *testing.T) {
 
 	// ensure all data is present
 	log.Infof("querying period0 results")
-	period0Results, _, err := session.FetchTagged(
+	period0Results, _, err := session.FetchTagged(context.Background(),
 		md.ID(), query, index.QueryOptions{StartInclusive: t0, EndExclusive: t1})
 	require.NoError(t, err)
 	writesPeriod0.matchesSeriesIters(t, period0Results)
@@ -132,7 +133,7 @@ func TestIndexBlockRotation(t *testing.T) {
 
 	// ensure all data is absent
 	log.Infof("querying period0 results after expiry")
-	period0Results, _, err = session.FetchTagged(
+	period0Results, _, err = session.FetchTagged(context.Background(),
 		md.ID(), query, index.QueryOptions{StartInclusive: t0, EndExclusive: t1})
 	require.NoError(t, err)
 	require.Equal(t, 0, period0Results.Len())
@@ -23,6 +23,7 @@
 package integration
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -122,21 +123,21 @@ func TestIndexMultipleBlockQuery(t *testing.T) {
 		idx.NewTermQuery([]byte("shared"), []byte("shared"))}
 
 	log.Infof("querying period0 results")
-	period0Results, _, err := session.FetchTagged(
+	period0Results, _, err := session.FetchTagged(context.Background(),
 		md.ID(), query, index.QueryOptions{StartInclusive: t0, EndExclusive: t1})
 	require.NoError(t, err)
 	writesPeriod0.matchesSeriesIters(t, period0Results)
 	log.Infof("found period0 results")
 
 	log.Infof("querying period1 results")
-	period1Results, _, err := session.FetchTagged(
+	period1Results, _, err := session.FetchTagged(context.Background(),
 		md.ID(), query, index.QueryOptions{StartInclusive: t1, EndExclusive: t2})
 	require.NoError(t, err)
 	writesPeriod1.matchesSeriesIters(t, period1Results)
 	log.Infof("found period1 results")
 
 	log.Infof("querying period 0+1 results")
-	period01Results, _, err := session.FetchTagged(
+	period01Results, _, err := session.FetchTagged(context.Background(),
 		md.ID(), query, index.QueryOptions{StartInclusive: t0, EndExclusive: t2})
 	require.NoError(t, err)
 	writes := append(writesPeriod0, writesPeriod1...)
@@ -23,6 +23,7 @@
 package integration
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -151,7 +152,7 @@ func TestPeersBootstrapIndexWithIndexingEnabled(t *testing.T) {
 	// Match all new_*r*
 	regexpQuery, err := idx.NewRegexpQuery([]byte("city"), []byte("new_.*r.*"))
 	require.NoError(t, err)
-	iter, exhausitive, err := session.FetchTaggedIDs(ns1.ID(),
+	iter, exhausitive, err := session.FetchTaggedIDs(context.Background(), ns1.ID(),
 		index.Query{regexpQuery}, queryOpts)
 	require.NoError(t, err)
 	defer iter.Finalize()
@@ -165,7 +166,7 @@ func TestPeersBootstrapIndexWithIndexingEnabled(t *testing.T) {
 	// Match all *e*e*
 	regexpQuery, err = idx.NewRegexpQuery([]byte("city"), []byte(".*e.*e.*"))
 	require.NoError(t, err)
-	iter, exhausitive, err = session.FetchTaggedIDs(ns1.ID(),
+	iter, exhausitive, err = session.FetchTaggedIDs(context.Background(), ns1.ID(),
 		index.Query{regexpQuery}, queryOpts)
 	require.NoError(t, err)
 	defer iter.Finalize()
@@ -135,7 +135,7 @@ func (s *service) Query(tctx thrift.Context, req *rpc.QueryRequest) (*rpc.QueryR
 	}
 
 	if req.NoData != nil && *req.NoData {
-		results, exhaustive, err := session.FetchTaggedIDs(nsID,
+		results, exhaustive, err := session.FetchTaggedIDs(tctx, nsID,
 			index.Query{Query: q}, opts)
 		if err != nil {
 			return nil, convert.ToRPCError(err)
@@ -170,7 +170,7 @@ func (s *service) Query(tctx thrift.Context, req *rpc.QueryRequest) (*rpc.QueryR
 		return result, nil
 	}
 
-	results, exhaustive, err := session.FetchTagged(nsID,
+	results, exhaustive, err := session.FetchTagged(tctx, nsID,
 		index.Query{Query: q}, opts)
 	if err != nil {
 		return nil, convert.ToRPCError(err)
@@ -277,6 +277,86 @@ func ToRPCFetchTaggedRequest(
 	return request, nil
 }
 
+// FromRPCAggregateQueryRequest converts the rpc request type for AggregateQueryRequest into corresponding Go API types.
+func FromRPCAggregateQueryRequest(
+	req *rpc.AggregateQueryRequest, pools FetchTaggedConversionPools,
+) (ident.ID, index.Query, index.AggregateQueryOptions, error) {
+	start, rangeStartErr := ToTime(req.RangeStart, fetchTaggedTimeType)
+	if rangeStartErr != nil {
+		return nil, index.Query{}, index.AggregateQueryOptions{}, rangeStartErr
+	}
+
+	end, rangeEndErr := ToTime(req.RangeEnd, fetchTaggedTimeType)
+	if rangeEndErr != nil {
+		return nil, index.Query{}, index.AggregateQueryOptions{}, rangeEndErr
+	}
+
+	opts := index.AggregateQueryOptions{
+		QueryOptions: index.QueryOptions{
+			StartInclusive: start,
+			EndExclusive:   end,
+		},
+		FieldName: req.TagName,
+	}
+	if l := req.Limit; l != nil {
+		opts.Limit = int(*l)
+	}
+
+	q, err := idx.Unmarshal(req.Query)
+	if err != nil {
+		return nil, index.Query{}, index.AggregateQueryOptions{}, err
+	}
+
+	var ns ident.ID
+	if pools != nil {
+		nsBytes := pools.CheckedBytesWrapper().Get(req.NameSpace)
+		ns = pools.ID().BinaryID(nsBytes)
+	} else {
+		ns = ident.StringID(string(req.NameSpace))
+	}
+	return ns, index.Query{Query: q}, opts, nil
+}
+
+// ToRPCAggregateQueryRequest converts the Go `client/` types into rpc request type for AggregateQueryRequest.
+func ToRPCAggregateQueryRequest(
+	ns ident.ID,
+	q index.Query,
+	opts index.AggregateQueryOptions,
+) (rpc.AggregateQueryRequest, error) {
+	rangeStart, tsErr := ToValue(opts.StartInclusive, fetchTaggedTimeType)
+	if tsErr != nil {
+		return rpc.AggregateQueryRequest{}, tsErr
+	}
+
+	rangeEnd, tsErr := ToValue(opts.EndExclusive, fetchTaggedTimeType)
+	if tsErr != nil {
+		return rpc.AggregateQueryRequest{}, tsErr
+	}
+
+	query, queryErr := idx.Marshal(q.Query)
+	if queryErr != nil {
+		return rpc.AggregateQueryRequest{}, queryErr
+	}
+
+	request := rpc.AggregateQueryRequest{
+		NameSpace:  ns.Bytes(),
+		RangeStart: rangeStart,
+		RangeEnd:   rangeEnd,
+		Query:      query,
+	}
+
+	if len(opts.FieldName) > 0 {
+		request.TagName = opts.FieldName
+	}
+
+	if opts.Limit > 0 {
+		l := int64(opts.Limit)
+		request.Limit = &l
+	}
+
+	return request, nil
+}
+
 // ToTagsIter returns a tag iterator over the given request.
 func ToTagsIter(r *rpc.WriteTaggedRequest) (ident.TagIterator, error) {
 	if r == nil {
@@ -150,6 +150,40 @@ func TestConvertFetchTaggedRequest(t *testing.T) {
 	}
 }
 
+func TestConvertAggregateQueryRequest(t *testing.T) {
+	ns := ident.StringID("abc")
+	opts := index.AggregateQueryOptions{
+		QueryOptions: index.QueryOptions{
+			StartInclusive: time.Now().Add(-900 * time.Hour),
+			EndExclusive:   time.Now(),
+			Limit:          10,
+		},
+		FieldName: []byte("foo"),
+	}
+	var limit int64 = 10
+	q, rpcQ := conjunctionQueryATestCase(t)
+	expectedReq := &rpc.AggregateQueryRequest{
+		NameSpace:  ns.Bytes(),
+		Query:      rpcQ,
+		RangeStart: mustToRpcTime(t, opts.StartInclusive),
+		RangeEnd:   mustToRpcTime(t, opts.EndExclusive),
+		TagName:    []byte("foo"),
+		Limit:      &limit,
+	}
+
+	observedReq, err := convert.ToRPCAggregateQueryRequest(ns, index.Query{Query: q}, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "", cmp.Diff(expectedReq, &observedReq))
+
+	for _, pools := range []convert.FetchTaggedConversionPools{nil, newTestPools()} {
+		id, observedQuery, observedOpts, err := convert.FromRPCAggregateQueryRequest(expectedReq, pools)
+		require.NoError(t, err)
+		require.Equal(t, ns.String(), id.String())
+		require.True(t, index.NewQueryMatcher(index.Query{Query: q}).Matches(observedQuery))
+		assert.Equal(t, "", cmp.Diff(opts, observedOpts))
+	}
+}
+
 type testPools struct {
 	id      ident.Pool
 	wrapper xpool.CheckedBytesWrapperPool
@@ -40,6 +40,7 @@ import (
 	"github.com/m3db/m3db/src/dbnode/storage/namespace"
 	"github.com/m3db/m3db/src/dbnode/ts"
 	"github.com/m3db/m3db/src/dbnode/x/xio"
+	"github.com/m3db/m3ninx/doc"
 	"github.com/m3db/m3ninx/idx"
 	"github.com/m3db/m3x/checked"
 	"github.com/m3db/m3x/ident"
@@ -1291,6 +1292,93 @@ func TestServiceFetchTaggedErrs(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestServiceAggregateQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false)
+
+	service := NewService(mockDB, nil).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	end := start.Add(2 * time.Hour)
+	nsID := "metrics"
+
+	req, err := idx.NewRegexpQuery([]byte("foo"), []byte("b.*"))
+	require.NoError(t, err)
+	qry := index.Query{Query: req}
+
+	results := index.NewAggregateResults([]byte("foo"))
+	for _, d := range []doc.Document{
+		{ID: []byte("a"), Fields: []doc.Field{{Name: []byte("foo"), Value: []byte("bar")}}},
+		{ID: []byte("b"), Fields: []doc.Field{{Name: []byte("foo"), Value: []byte("baz")}}},
+		{ID: []byte("c"), Fields: []doc.Field{{Name: []byte("foo"), Value: []byte("bar")}}},
+	} {
+		_, err := results.Add(d)
+		require.NoError(t, err)
+	}
+
+	mockDB.EXPECT().AggregateQuery(
+		ctx,
+		ident.NewIDMatcher(nsID),
+		index.NewQueryMatcher(qry),
+		index.AggregateQueryOptions{
+			QueryOptions: index.QueryOptions{
+				StartInclusive: start,
+				EndExclusive:   end,
+				Limit:          10,
+			},
+			FieldName: []byte("foo"),
+		}).Return(index.AggregateQueryResults{Results: results, Exhaustive: true}, nil)
+
+	startNanos, err := convert.ToValue(start, rpc.TimeType_UNIX_NANOSECONDS)
+	require.NoError(t, err)
+	endNanos, err := convert.ToValue(end, rpc.TimeType_UNIX_NANOSECONDS)
+	require.NoError(t, err)
+	var limit int64 = 10
+	data, err := idx.Marshal(req)
+	require.NoError(t, err)
+	r, err := service.AggregateQuery(tctx, &rpc.AggregateQueryRequest{
+		NameSpace:  []byte(nsID),
+		Query:      data,
+		RangeStart: startNanos,
+		RangeEnd:   endNanos,
+		TagName:    []byte("foo"),
+		Limit:      &limit,
+	})
+	require.NoError(t, err)
+
+	assert.True(t, r.Exhaustive)
+	assert.Equal(t, []*rpc.AggregateQueryResultTerm{
+		{Term: []byte("bar"), Count: 2},
+		{Term: []byte("baz"), Count: 1},
+	}, r.Results)
+}
+
+func TestServiceAggregateQueryIsOverloaded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(true)
+
+	service := NewService(mockDB, nil).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	_, err := service.AggregateQuery(tctx, &rpc.AggregateQueryRequest{})
+	require.Equal(t, tterrors.NewInternalError(errServerIsOverloaded), err)
+}
+
 func TestServiceWrite(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
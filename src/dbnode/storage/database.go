@@ -104,6 +104,7 @@ type databaseMetrics struct {
 	unknownNamespaceFetchBlocks         tally.Counter
 	unknownNamespaceFetchBlocksMetadata tally.Counter
 	unknownNamespaceQueryIDs            tally.Counter
+	unknownNamespaceAggregateQuery      tally.Counter
 	errQueryIDsIndexDisabled            tally.Counter
 	errWriteTaggedIndexDisabled         tally.Counter
 }
@@ -118,6 +119,7 @@ func newDatabaseMetrics(scope tally.Scope) databaseMetrics {
 		unknownNamespaceFetchBlocks:         unknownNamespaceScope.Counter("fetch-blocks"),
 		unknownNamespaceFetchBlocksMetadata: unknownNamespaceScope.Counter("fetch-blocks-metadata"),
 		unknownNamespaceQueryIDs:            unknownNamespaceScope.Counter("query-ids"),
+		unknownNamespaceAggregateQuery:      unknownNamespaceScope.Counter("aggregate-query"),
 		errQueryIDsIndexDisabled:            indexDisabledScope.Counter("err-query-ids"),
 		errWriteTaggedIndexDisabled:         indexDisabledScope.Counter("err-write-tagged"),
 	}
@@ -542,6 +544,31 @@ func (d *db) QueryIDs(
 	return queryResults, err
 }
 
+func (d *db) AggregateQuery(
+	ctx context.Context,
+	namespace ident.ID,
+	query index.Query,
+	opts index.AggregateQueryOptions,
+) (index.AggregateQueryResults, error) {
+	n, err := d.namespaceFor(namespace)
+	if err != nil {
+		d.metrics.unknownNamespaceAggregateQuery.Inc(1)
+		return index.AggregateQueryResults{}, err
+	}
+
+	var (
+		wg           = sync.WaitGroup{}
+		queryResults index.AggregateQueryResults
+	)
+	wg.Add(1)
+	d.opts.QueryIDsWorkerPool().Go(func() {
+		queryResults, err = n.AggregateQuery(ctx, query, opts)
+		wg.Done()
+	})
+	wg.Wait()
+	return queryResults, err
+}
+
 func (d *db) ReadEncoded(
 	ctx context.Context,
 	namespace ident.ID,
@@ -721,13 +721,7 @@ func (i *nsIndex) Query(
 		return index.QueryResults{}, errDbIndexUnableToQueryClosed
 	}
 
-	// override query response limit if needed.
-	if i.state.runtimeOpts.maxQueryLimit > 0 && (opts.Limit == 0 ||
-		int64(opts.Limit) > i.state.runtimeOpts.maxQueryLimit) {
-		i.logger.Debugf("overriding query response limit, requested: %d, max-allowed: %d",
-			opts.Limit, i.state.runtimeOpts.maxQueryLimit) // FOLLOWUP(prateek): log query too once it's serializable.
-		opts.Limit = int(i.state.runtimeOpts.maxQueryLimit)
-	}
+	opts = i.overrideQueryLimitWithRLock(opts)
 
 	var (
 		exhaustive = true
@@ -789,6 +783,76 @@ func (i *nsIndex) Query(
 	}, nil
 }
 
+func (i *nsIndex) AggregateQuery(
+	ctx context.Context,
+	query index.Query,
+	opts index.AggregateQueryOptions,
+) (index.AggregateQueryResults, error) {
+	i.state.RLock()
+	defer i.state.RUnlock()
+	if !i.isOpenWithRLock() {
+		return index.AggregateQueryResults{}, errDbIndexUnableToQueryClosed
+	}
+
+	opts.QueryOptions = i.overrideQueryLimitWithRLock(opts.QueryOptions)
+
+	var (
+		exhaustive = true
+		results    = index.NewAggregateResults(opts.FieldName)
+		err        error
+	)
+
+	queryRange := xtime.NewRanges(xtime.Range{
+		Start: opts.StartInclusive, End: opts.EndExclusive})
+
+	// iterate known blocks newest first, same as Query, so the terms returned
+	// when hitting the limit favour recent data.
+	for _, start := range i.state.blockStartsDescOrder {
+		block, ok := i.state.blocksByTime[start]
+		if !ok { // should never happen
+			return index.AggregateQueryResults{}, i.missingBlockInvariantError(start)
+		}
+
+		blockRange := xtime.Range{Start: block.StartTime(), End: block.EndTime()}
+		if !queryRange.Overlaps(blockRange) {
+			continue
+		}
+
+		// NB: the limit only stops new terms from being added, the blocks are
+		// aggregated while the terms found still occur in them.
+		exhaustive, err = block.Aggregate(query, opts, results)
+		if err != nil {
+			return index.AggregateQueryResults{}, err
+		}
+
+		if !exhaustive {
+			break
+		}
+
+		queryRange = queryRange.RemoveRange(blockRange)
+		if queryRange.IsEmpty() {
+			break
+		}
+	}
+
+	return index.AggregateQueryResults{
+		Exhaustive: exhaustive,
+		Results:    results,
+	}, nil
+}
+
+// overrideQueryLimitWithRLock caps the limit of the query options at the
+// runtime configured max query limit.
+func (i *nsIndex) overrideQueryLimitWithRLock(opts index.QueryOptions) index.QueryOptions {
+	if i.state.runtimeOpts.maxQueryLimit > 0 && (opts.Limit == 0 ||
+		int64(opts.Limit) > i.state.runtimeOpts.maxQueryLimit) {
+		i.logger.Debugf("overriding query response limit, requested: %d, max-allowed: %d",
+			opts.Limit, i.state.runtimeOpts.maxQueryLimit) // FOLLOWUP(prateek): log query too once it's serializable.
+		opts.Limit = int(i.state.runtimeOpts.maxQueryLimit)
+	}
+	return opts
+}
+
 // ensureBlockPresentWithRLock guarantees an index.Block exists for the specified
 // blockStart, allocating one if it does not. It returns the desired block, or
 // error if it's unable to do so.
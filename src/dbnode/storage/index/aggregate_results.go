@@ -0,0 +1,81 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package index
+
+import (
+	"bytes"
+	"sort"
+)
+
+type aggregateResults struct {
+	fieldName []byte
+	counts    map[string]int64
+}
+
+// NewAggregateResults returns a new aggregate results object, aggregating the
+// values of the given field or the field names if fieldName is empty.
+func NewAggregateResults(fieldName []byte) AggregateResults {
+	if len(fieldName) == 0 {
+		fieldName = nil
+	} else {
+		fieldName = append([]byte(nil), fieldName...)
+	}
+
+	return &aggregateResults{
+		fieldName: fieldName,
+		counts:    make(map[string]int64),
+	}
+}
+
+func (r *aggregateResults) FieldName() []byte {
+	return r.fieldName
+}
+
+func (r *aggregateResults) Size() int {
+	return len(r.counts)
+}
+
+func (r *aggregateResults) Contains(term []byte) bool {
+	_, ok := r.counts[string(term)]
+	return ok
+}
+
+func (r *aggregateResults) AddTerm(term []byte, count int64) int {
+	// NB: the same series may be indexed by several segments, so the counts
+	// of a term are not summed across segments.
+	if existing, ok := r.counts[string(term)]; !ok || count > existing {
+		r.counts[string(term)] = count
+	}
+
+	return len(r.counts)
+}
+
+func (r *aggregateResults) Terms() []AggregateTerm {
+	terms := make([]AggregateTerm, 0, len(r.counts))
+	for term, count := range r.counts {
+		terms = append(terms, AggregateTerm{Term: []byte(term), Count: count})
+	}
+
+	sort.Slice(terms, func(i, j int) bool {
+		return bytes.Compare(terms[i].Term, terms[j].Term) < 0
+	})
+	return terms
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateResultsFieldNames(t *testing.T) {
+	res := NewAggregateResults(nil)
+	require.Nil(t, res.FieldName())
+
+	require.Equal(t, 1, res.AddTerm([]byte("some"), 1))
+	require.Equal(t, 2, res.AddTerm([]byte("bar"), 2))
+
+	require.Equal(t, []AggregateTerm{
+		{Term: []byte("bar"), Count: 2},
+		{Term: []byte("some"), Count: 1},
+	}, res.Terms())
+}
+
+func TestAggregateResultsFieldValues(t *testing.T) {
+	res := NewAggregateResults([]byte("some"))
+	require.Equal(t, []byte("some"), res.FieldName())
+
+	require.Equal(t, 1, res.AddTerm([]byte("more"), 1))
+	require.Equal(t, []AggregateTerm{{Term: []byte("more"), Count: 1}}, res.Terms())
+	require.True(t, res.Contains([]byte("more")))
+	require.False(t, res.Contains([]byte("less")))
+}
+
+func TestAggregateResultsLargestCount(t *testing.T) {
+	res := NewAggregateResults(nil)
+
+	// The same series may be counted by several segments, so a term keeps
+	// the largest count it is added with rather than their sum.
+	require.Equal(t, 1, res.AddTerm([]byte("bar"), 2))
+	require.Equal(t, 1, res.AddTerm([]byte("bar"), 3))
+	require.Equal(t, 1, res.AddTerm([]byte("bar"), 1))
+	require.Equal(t, []AggregateTerm{{Term: []byte("bar"), Count: 3}}, res.Terms())
+}
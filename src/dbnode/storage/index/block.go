@@ -21,6 +21,7 @@
 package index
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"sync"
@@ -28,6 +29,7 @@ import (
 
 	"github.com/m3db/m3db/src/dbnode/storage/bootstrap/result"
 	"github.com/m3db/m3db/src/dbnode/storage/namespace"
+	"github.com/m3db/m3ninx/doc"
 	m3ninxindex "github.com/m3db/m3ninx/index"
 	"github.com/m3db/m3ninx/index/segment"
 	"github.com/m3db/m3ninx/index/segment/mem"
@@ -292,6 +294,162 @@ func (b *block) Query(
 	return exhaustive, nil
 }
 
+func (b *block) Aggregate(
+	query Query,
+	opts AggregateQueryOptions,
+	results AggregateResults,
+) (bool, error) {
+	b.RLock()
+	defer b.RUnlock()
+	if b.state == blockStateClosed {
+		return false, errUnableToQueryBlockClosed
+	}
+
+	searcher, err := query.Query.SearchQuery().Searcher()
+	if err != nil {
+		return false, err
+	}
+
+	// NB: the terms are read from the term dictionaries of the segments rather
+	// than from the matching documents, so that no document is retrieved.
+	// Every segment is read even once the limit is reached, since the terms
+	// already tracked may occur in the later segments.
+	exhaustive := true
+	if b.activeSegment != nil {
+		segExhaustive, err := aggregateSegment(b.activeSegment, searcher, opts, results)
+		if err != nil {
+			return false, err
+		}
+
+		exhaustive = exhaustive && segExhaustive
+	}
+
+	for _, group := range b.shardRangesSegments {
+		for _, seg := range group.segments {
+			segExhaustive, err := aggregateSegment(seg, searcher, opts, results)
+			if err != nil {
+				return false, err
+			}
+
+			exhaustive = exhaustive && segExhaustive
+		}
+	}
+
+	return exhaustive, nil
+}
+
+// aggregateSegment adds the field names, or the values of the field of the
+// results, of the segment's documents matching the searcher to the results
+// along with the number of matching documents they occur in. It returns
+// whether no new term was left out for the limit of the query.
+func aggregateSegment(
+	seg segment.Segment,
+	searcher search.Searcher,
+	opts AggregateQueryOptions,
+	results AggregateResults,
+) (bool, error) {
+	reader, err := seg.Reader()
+	if err != nil {
+		return false, err
+	}
+
+	defer reader.Close()
+
+	matched, err := searcher.Search(reader)
+	if err != nil {
+		return false, err
+	}
+
+	if matched.IsEmpty() {
+		return true, nil
+	}
+
+	var (
+		exhaustive = true
+		fieldName  = results.FieldName()
+		fields     = [][]byte{fieldName}
+	)
+	if fieldName == nil {
+		fields, err = seg.Fields()
+		if err != nil {
+			return false, err
+		}
+	}
+
+	for _, field := range fields {
+		if fieldName == nil && bytes.Equal(field, doc.IDReservedFieldName) {
+			continue
+		}
+
+		terms, err := seg.Terms(field)
+		if err != nil {
+			return false, err
+		}
+
+		// A document has a single value for each of its fields, so the
+		// documents with a field are the sum of those with each of its values
+		var fieldCount int64
+		for _, term := range terms {
+			termPostings, err := reader.MatchTerm(field, term)
+			if err != nil {
+				return false, err
+			}
+
+			count, err := intersectionLen(termPostings, matched)
+			if err != nil {
+				return false, err
+			}
+
+			if count == 0 {
+				continue
+			}
+
+			if fieldName == nil {
+				fieldCount += count
+				continue
+			}
+
+			if !addAggregateTerm(opts, results, term, count) {
+				exhaustive = false
+			}
+		}
+
+		if fieldName == nil && fieldCount > 0 {
+			if !addAggregateTerm(opts, results, field, fieldCount) {
+				exhaustive = false
+			}
+		}
+	}
+
+	return exhaustive, nil
+}
+
+// addAggregateTerm adds the term to the results unless it is a new term and
+// the results are at their limit, returning whether it was added.
+func addAggregateTerm(
+	opts AggregateQueryOptions,
+	results AggregateResults,
+	term []byte,
+	count int64,
+) bool {
+	if opts.Limit > 0 && results.Size() >= opts.Limit && !results.Contains(term) {
+		return false
+	}
+
+	results.AddTerm(term, count)
+	return true
+}
+
+// intersectionLen returns the number of documents in both postings lists.
+func intersectionLen(a, b postings.List) (int64, error) {
+	intersection := a.Clone()
+	if err := intersection.Intersect(b); err != nil {
+		return 0, err
+	}
+
+	return int64(intersection.Len()), nil
+}
+
 func (b *block) AddResults(
 	results result.IndexBlock,
 ) error {
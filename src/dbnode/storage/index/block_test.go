@@ -1249,6 +1249,140 @@ func TestBlockE2EInsertQuery(t *testing.T) {
 		ident.NewTagsIterator(t2)))
 }
 
+func TestBlockAggregateAfterClose(t *testing.T) {
+	testMD := newTestNSMetadata(t)
+	start := time.Now().Truncate(time.Hour)
+	b, err := NewBlock(start, testMD, testOpts)
+	require.NoError(t, err)
+
+	require.NoError(t, b.Close())
+
+	_, err = b.Aggregate(Query{}, AggregateQueryOptions{}, NewAggregateResults(nil))
+	require.Error(t, err)
+}
+
+func TestBlockE2EInsertAggregate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blockSize := time.Hour
+
+	testMD := newTestNSMetadata(t)
+	now := time.Now()
+	blockStart := now.Truncate(blockSize)
+
+	nowNotBlockStartAligned := now.
+		Truncate(blockSize).
+		Add(time.Minute)
+
+	blk, err := NewBlock(blockStart, testMD, testOpts)
+	require.NoError(t, err)
+
+	h1 := NewMockOnIndexSeries(ctrl)
+	h1.EXPECT().OnIndexFinalize(xtime.ToUnixNano(blockStart))
+	h1.EXPECT().OnIndexSuccess(xtime.ToUnixNano(blockStart))
+
+	h2 := NewMockOnIndexSeries(ctrl)
+	h2.EXPECT().OnIndexFinalize(xtime.ToUnixNano(blockStart))
+	h2.EXPECT().OnIndexSuccess(xtime.ToUnixNano(blockStart))
+
+	batch := NewWriteBatch(WriteBatchOptions{
+		IndexBlockSize: blockSize,
+	})
+	batch.Append(WriteBatchEntry{
+		Timestamp:     nowNotBlockStartAligned,
+		OnIndexSeries: h1,
+	}, testDoc1())
+	batch.Append(WriteBatchEntry{
+		Timestamp:     nowNotBlockStartAligned,
+		OnIndexSeries: h2,
+	}, testDoc2())
+
+	res, err := blk.WriteBatch(batch)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), res.NumSuccess)
+
+	q, err := idx.NewRegexpQuery([]byte("bar"), []byte("b.*"))
+	require.NoError(t, err)
+
+	names := NewAggregateResults(nil)
+	exhaustive, err := blk.Aggregate(Query{q}, AggregateQueryOptions{}, names)
+	require.NoError(t, err)
+	require.True(t, exhaustive)
+	require.Equal(t, []AggregateTerm{
+		{Term: []byte("bar"), Count: 2},
+		{Term: []byte("some"), Count: 1},
+	}, names.Terms())
+
+	values := NewAggregateResults([]byte("some"))
+	exhaustive, err = blk.Aggregate(Query{q}, AggregateQueryOptions{
+		FieldName: []byte("some"),
+	}, values)
+	require.NoError(t, err)
+	require.True(t, exhaustive)
+	require.Equal(t, []AggregateTerm{{Term: []byte("more"), Count: 1}}, values.Terms())
+
+	limited := NewAggregateResults(nil)
+	exhaustive, err = blk.Aggregate(Query{q}, AggregateQueryOptions{
+		QueryOptions: QueryOptions{Limit: 1},
+	}, limited)
+	require.NoError(t, err)
+	require.False(t, exhaustive)
+	require.Len(t, limited.Terms(), 1)
+}
+
+func TestBlockAggregateLimitAcrossSegments(t *testing.T) {
+	testMD := newTestNSMetadata(t)
+	start := time.Now().Truncate(time.Hour)
+	blk, err := NewBlock(start, testMD, testOpts)
+	require.NoError(t, err)
+
+	qux := doc.Document{
+		ID:     []byte("other"),
+		Fields: []doc.Field{{Name: []byte("bar"), Value: []byte("qux")}},
+	}
+
+	// The terms of the first segment reach the limit, the second segment
+	// only has terms already found
+	require.NoError(t, blk.AddResults(
+		result.NewIndexBlock(start, []segment.Segment{testSegment(t, testDoc2())},
+			result.NewShardTimeRanges(start, start.Add(time.Hour), 1))))
+	require.NoError(t, blk.AddResults(
+		result.NewIndexBlock(start, []segment.Segment{testSegment(t, testDoc1(), testDoc2())},
+			result.NewShardTimeRanges(start, start.Add(time.Hour), 2))))
+
+	q, err := idx.NewRegexpQuery([]byte("bar"), []byte("b.*"))
+	require.NoError(t, err)
+
+	names := NewAggregateResults(nil)
+	exhaustive, err := blk.Aggregate(Query{q}, AggregateQueryOptions{
+		QueryOptions: QueryOptions{Limit: 2},
+	}, names)
+	require.NoError(t, err)
+	require.True(t, exhaustive)
+	require.Equal(t, []AggregateTerm{
+		{Term: []byte("bar"), Count: 2},
+		{Term: []byte("some"), Count: 1},
+	}, names.Terms())
+
+	// A new term past the limit is left out
+	require.NoError(t, blk.AddResults(
+		result.NewIndexBlock(start, []segment.Segment{testSegment(t, qux)},
+			result.NewShardTimeRanges(start, start.Add(time.Hour), 3))))
+
+	q, err = idx.NewRegexpQuery([]byte("bar"), []byte("baz|qux"))
+	require.NoError(t, err)
+
+	values := NewAggregateResults([]byte("bar"))
+	exhaustive, err = blk.Aggregate(Query{q}, AggregateQueryOptions{
+		QueryOptions: QueryOptions{Limit: 1},
+		FieldName:    []byte("bar"),
+	}, values)
+	require.NoError(t, err)
+	require.False(t, exhaustive)
+	require.Equal(t, []AggregateTerm{{Term: []byte("baz"), Count: 2}}, values.Terms())
+}
+
 func TestBlockE2EInsertQueryLimit(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
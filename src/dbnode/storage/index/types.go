@@ -70,6 +70,51 @@ type QueryResults struct {
 	Exhaustive bool
 }
 
+// AggregateQueryOptions enables users to specify constraints on aggregate
+// query execution.
+type AggregateQueryOptions struct {
+	QueryOptions
+
+	// FieldName is the field whose distinct values are aggregated, if empty
+	// the distinct field names are aggregated instead.
+	FieldName []byte
+}
+
+// AggregateQueryResults is the collection of results for an aggregate query.
+type AggregateQueryResults struct {
+	Results    AggregateResults
+	Exhaustive bool
+}
+
+// AggregateTerm is a distinct field name or value along with the number of
+// series it occurs in, as counted by the index segment with the most of them.
+type AggregateTerm struct {
+	Term  []byte
+	Count int64
+}
+
+// AggregateResults is a collection of distinct terms for an aggregate query.
+type AggregateResults interface {
+	// FieldName returns the field whose values are aggregated, or nil if
+	// field names are aggregated.
+	FieldName() []byte
+
+	// Size returns the number of distinct terms tracked.
+	Size() int
+
+	// Contains returns whether the term is tracked.
+	Contains(term []byte) bool
+
+	// AddTerm adds a term along with the number of matching documents of a
+	// segment it occurs in. Documents may be indexed by several segments, so
+	// the count of a term is the largest it is added with. It returns the
+	// number of distinct terms tracked.
+	AddTerm(term []byte, count int64) (size int)
+
+	// Terms returns the tracked terms sorted in ascending order.
+	Terms() []AggregateTerm
+}
+
 // Results is a collection of results for a query.
 type Results interface {
 	// Namespace returns the namespace associated with the result.
@@ -145,6 +190,14 @@ type Block interface {
 		results Results,
 	) (exhaustive bool, err error)
 
+	// Aggregate resolves the given query into the distinct field names, or
+	// values of a field, of the matching documents.
+	Aggregate(
+		query Query,
+		opts AggregateQueryOptions,
+		results AggregateResults,
+	) (exhaustive bool, err error)
+
 	// AddResults adds bootstrap results to the block, if c.
 	AddResults(results result.IndexBlock) error
 
@@ -323,3 +323,55 @@ func TestNamespaceIndexInsertQuery(t *testing.T) {
 		ident.MustNewTagStringsIterator("name", "value")).Matches(
 		ident.NewTagsIterator(tags)))
 }
+
+func TestNamespaceIndexInsertAggregateQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	defer leaktest.CheckTimeout(t, 2*time.Second)()
+
+	md, err := namespace.NewMetadata(defaultTestNs1ID, defaultTestNs1Opts)
+	require.NoError(t, err)
+	idx, err := newNamespaceIndex(md, testDatabaseOptions().
+		SetIndexOptions(testNamespaceIndexOptions().SetInsertMode(index.InsertSync)))
+	assert.NoError(t, err)
+	defer idx.Close()
+
+	var (
+		blockSize  = idx.(*nsIndex).blockSize
+		indexState = idx.(*nsIndex).state
+		ts         = indexState.latestBlock.StartTime()
+		now        = time.Now()
+		ctx        = context.NewContext()
+	)
+
+	for _, id := range []string{"foo", "bar"} {
+		lifecycleFns := index.NewMockOnIndexSeries(ctrl)
+		lifecycleFns.EXPECT().OnIndexFinalize(xtime.ToUnixNano(ts))
+		lifecycleFns.EXPECT().OnIndexSuccess(xtime.ToUnixNano(ts))
+
+		tags := ident.NewTags(
+			ident.StringTag("name", "value"),
+			ident.StringTag("id", id),
+		)
+		entry, doc := testWriteBatchEntry(ident.StringID(id), tags, now, lifecycleFns)
+		batch := testWriteBatch(entry, doc, testWriteBatchBlockSizeOption(blockSize))
+		assert.NoError(t, idx.WriteBatch(batch))
+	}
+
+	reQuery, err := m3ninxidx.NewRegexpQuery([]byte("name"), []byte("val.*"))
+	assert.NoError(t, err)
+	res, err := idx.AggregateQuery(ctx, index.Query{reQuery}, index.AggregateQueryOptions{
+		QueryOptions: index.QueryOptions{
+			StartInclusive: now.Add(-1 * time.Minute),
+			EndExclusive:   now.Add(1 * time.Minute),
+		},
+		FieldName: []byte("id"),
+	})
+	assert.NoError(t, err)
+
+	assert.True(t, res.Exhaustive)
+	assert.Equal(t, []index.AggregateTerm{
+		{Term: []byte("bar"), Count: 1},
+		{Term: []byte("foo"), Count: 1},
+	}, res.Results.Terms())
+}
@@ -150,6 +150,7 @@ type databaseNamespaceMetrics struct {
 	fetchBlocks         instrument.MethodMetrics
 	fetchBlocksMetadata instrument.MethodMetrics
 	queryIDs            instrument.MethodMetrics
+	aggregateQuery      instrument.MethodMetrics
 	unfulfilled         tally.Counter
 	bootstrapStart      tally.Counter
 	bootstrapEnd        tally.Counter
@@ -219,6 +220,7 @@ func newDatabaseNamespaceMetrics(scope tally.Scope, samplingRate float64) databa
 		fetchBlocks:         instrument.NewMethodMetrics(scope, "fetchBlocks", samplingRate),
 		fetchBlocksMetadata: instrument.NewMethodMetrics(scope, "fetchBlocksMetadata", samplingRate),
 		queryIDs:            instrument.NewMethodMetrics(scope, "queryIDs", samplingRate),
+		aggregateQuery:      instrument.NewMethodMetrics(scope, "aggregateQuery", samplingRate),
 		unfulfilled:         scope.Counter("bootstrap.unfulfilled"),
 		bootstrapStart:      scope.Counter("bootstrap.start"),
 		bootstrapEnd:        scope.Counter("bootstrap.end"),
@@ -595,6 +597,21 @@ func (n *dbNamespace) QueryIDs(
 	return res, err
 }
 
+func (n *dbNamespace) AggregateQuery(
+	ctx context.Context,
+	query index.Query,
+	opts index.AggregateQueryOptions,
+) (index.AggregateQueryResults, error) {
+	callStart := n.nowFn()
+	if n.reverseIndex == nil { // only happens if indexing is enabled.
+		n.metrics.aggregateQuery.ReportError(n.nowFn().Sub(callStart))
+		return index.AggregateQueryResults{}, errNamespaceIndexingDisabled
+	}
+	res, err := n.reverseIndex.AggregateQuery(ctx, query, opts)
+	n.metrics.aggregateQuery.ReportSuccessOrError(err, n.nowFn().Sub(callStart))
+	return res, err
+}
+
 func (n *dbNamespace) ReadEncoded(
 	ctx context.Context,
 	id ident.ID,
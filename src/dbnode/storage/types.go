@@ -111,6 +111,15 @@ type Database interface {
 		opts index.QueryOptions,
 	) (index.QueryResults, error)
 
+	// AggregateQuery resolves the given query into the distinct tag names,
+	// or values of a tag, of the matching series.
+	AggregateQuery(
+		ctx context.Context,
+		namespace ident.ID,
+		query index.Query,
+		opts index.AggregateQueryOptions,
+	) (index.AggregateQueryResults, error)
+
 	// ReadEncoded retrieves encoded segments for an ID
 	ReadEncoded(
 		ctx context.Context,
@@ -254,6 +263,14 @@ type databaseNamespace interface {
 		opts index.QueryOptions,
 	) (index.QueryResults, error)
 
+	// AggregateQuery resolves the given query into the distinct tag names,
+	// or values of a tag, of the matching series.
+	AggregateQuery(
+		ctx context.Context,
+		query index.Query,
+		opts index.AggregateQueryOptions,
+	) (index.AggregateQueryResults, error)
+
 	// ReadEncoded reads data for given id within [start, end)
 	ReadEncoded(
 		ctx context.Context,
@@ -456,6 +473,14 @@ type namespaceIndex interface {
 		opts index.QueryOptions,
 	) (index.QueryResults, error)
 
+	// AggregateQuery resolves the given query into the distinct field
+	// names, or values of a field, of the matching documents.
+	AggregateQuery(
+		ctx context.Context,
+		query index.Query,
+		opts index.AggregateQueryOptions,
+	) (index.AggregateQueryResults, error)
+
 	// Bootstrap bootstraps the index the provided segments.
 	Bootstrap(
 		bootstrapResults result.IndexResults,